@@ -17,12 +17,21 @@
 package commands
 
 import (
+    "bytes"
+    "encoding/json"
     "encoding/base64"
     "errors"
     "fmt"
+    "net/http"
+    "net/url"
     "path/filepath"
     "io"
+    "io/ioutil"
+    "os"
+    "os/signal"
     "strings"
+    "syscall"
+    "time"
 
     "../../go-whisk/whisk"
     "../wski18n"
@@ -35,6 +44,19 @@ import (
 const MEMORY_LIMIT = 256
 const TIMEOUT_LIMIT = 60000
 const LOGSIZE_LIMIT = 10
+
+// How often action watch checks the source file for changes
+const ACTION_WATCH_INTERVAL = time.Second
+
+// Largest action code accepted by the server, after base64 encoding of archives
+const MAX_CODE_SIZE = 48 * 1024 * 1024
+
+const MIN_MEMORY_LIMIT = 128
+const MAX_MEMORY_LIMIT = 512
+const MIN_TIMEOUT_LIMIT = 100
+const MAX_TIMEOUT_LIMIT = 300000
+const MIN_LOGSIZE_LIMIT = 0
+const MAX_LOGSIZE_LIMIT = 10
 const ACTIVATION_ID = "activationId"
 const WEB_EXPORT_ANNOT = "web-export"
 const RAW_HTTP_ANNOT = "raw-http"
@@ -68,12 +90,17 @@ var actionCreateCmd = &cobra.Command{
             return actionParseError(cmd, args, err)
         }
 
-        if _, _, err = client.Actions.Insert(action, false); err != nil {
+        var inserted *whisk.Action
+        if inserted, _, err = client.Actions.Insert(action, false); err != nil {
             return actionInsertError(action, err)
         }
 
         printActionCreated(action.Name)
 
+        if isWebMode(flags.action.web) {
+            printWebActionURL(inserted, action.Name)
+        }
+
         return nil
     },
 }
@@ -86,6 +113,9 @@ var actionUpdateCmd = &cobra.Command{
     PreRunE:       setupClientConfig,
     RunE: func(cmd *cobra.Command, args []string) error {
         var action *whisk.Action
+        var existingAction *whisk.Action
+        var etag string
+        var resp *http.Response
         var err error
 
         if whiskErr := checkArgs(
@@ -97,93 +127,920 @@ var actionUpdateCmd = &cobra.Command{
                 return whiskErr
         }
 
+        if err = checkDeletedKeys(flags.common.param, getActionDeletedParams(), "--del-param"); err != nil {
+            return err
+        }
+
+        if err = checkDeletedKeys(flags.common.annotation, flags.common.delAnnotation, "--del-annotation"); err != nil {
+            return err
+        }
+
         if action, err = parseAction(cmd, args, true); err != nil {
             return actionParseError(cmd, args, err)
         }
 
-        if _, _, err = client.Actions.Insert(action, true); err != nil {
-            return actionInsertError(action, err)
+        // The update carries the ETag of the action it is based on, so that a change made by another client in the
+        // meantime is reported instead of silently overwritten. --if-match gives the ETag of an earlier read instead.
+        ifMatch := flags.action.ifMatch
+        if existingAction, etag, _, err = client.Actions.GetWithETag(action.Name); err != nil {
+            if getHttpErrorStatus(err) != http.StatusNotFound {
+                return actionGetError(action.Name, err)
+            }
+            whisk.Debug(whisk.DbgInfo, "client.Actions.GetWithETag(%s) found no action; creating it\n", action.Name)
+        } else {
+            // The server replaces all parameters and annotations of an action with any that are sent, so the
+            // existing ones are sent along with the changes when they are to be kept
+            if isActionKeyValueMerge() {
+                mergeActionKeyValues(action, existingAction)
+            }
+            if len(ifMatch) == 0 {
+                ifMatch = etag
+            }
+        }
+
+        var inserted *whisk.Action
+        if inserted, resp, err = client.Actions.InsertIfMatch(action, true, ifMatch); err != nil {
+            if resp != nil && resp.StatusCode == http.StatusPreconditionFailed {
+                return actionModifiedError(action, err)
+            }
+
+            return actionInsertError(action, err)
+        }
+
+        printActionUpdated(action.Name)
+
+        if isWebMode(flags.action.web) {
+            printWebActionURL(inserted, action.Name)
+        }
+
+        return nil
+    },
+}
+
+var actionCopyCmd = &cobra.Command{
+    Use:           "copy SOURCE_ACTION_NAME TARGET_ACTION_NAME",
+    Short:         wski18n.T("copy an existing action to a new action"),
+    SilenceUsage:  true,
+    SilenceErrors: true,
+    PreRunE:       setupClientConfig,
+    RunE: func(cmd *cobra.Command, args []string) error {
+        var action *whisk.Action
+        var sourceName QualifiedName
+        var targetName QualifiedName
+        var err error
+
+        if whiskErr := checkArgs(
+            args,
+            2,
+            2,
+            "Action copy",
+            wski18n.T("A source and target action name are required.")); whiskErr != nil {
+                return whiskErr
+        }
+
+        if sourceName, err = parseQualifiedName(args[0]); err != nil {
+            return parseQualifiedNameError(args[0], err)
+        }
+
+        if targetName, err = parseQualifiedName(args[1]); err != nil {
+            return parseQualifiedNameError(args[1], err)
+        }
+
+        client.Namespace = sourceName.namespace
+
+        if action, _, err = client.Actions.Get(sourceName.entityName); err != nil {
+            return actionGetError(sourceName.entityName, err)
+        }
+
+        client.Namespace = targetName.namespace
+        action.Namespace = ""
+        action.Version = ""
+        action.Name = targetName.entityName
+
+        if _, _, err = client.Actions.Insert(action, flags.action.overwrite); err != nil {
+            return actionInsertError(action, err)
+        }
+
+        printActionCopied(sourceName.entityName, targetName.entityName)
+
+        return nil
+    },
+}
+
+var actionExportCmd = &cobra.Command{
+    Use:           "export ACTION_NAME [FILE]",
+    Short:         wski18n.T("export the definition of an action as JSON"),
+    SilenceUsage:  true,
+    SilenceErrors: true,
+    PreRunE:       setupClientConfig,
+    RunE: func(cmd *cobra.Command, args []string) error {
+        var action *whisk.Action
+        var qualifiedName QualifiedName
+        var err error
+
+        if whiskErr := checkArgs(args, 1, 2, "Action export", wski18n.T("An action name is required. An output file is optional.")); whiskErr != nil {
+            return whiskErr
+        }
+
+        exportFile := flags.action.exportFile
+        if len(args) == 2 {
+            exportFile = args[1]
+        }
+
+        if qualifiedName, err = parseQualifiedName(args[0]); err != nil {
+            return parseQualifiedNameError(args[0], err)
+        }
+
+        client.Namespace = qualifiedName.namespace
+
+        if action, _, err = client.Actions.Get(qualifiedName.entityName); err != nil {
+            return actionGetError(qualifiedName.entityName, err)
+        }
+
+        // The namespace and version are assigned by the server, so they are left out to keep the file portable. The
+        // code of binary actions is already base64 encoded by the server.
+        action.Namespace = ""
+        action.Version = ""
+
+        if len(exportFile) == 0 {
+            printJSON(action)
+            return nil
+        }
+
+        if err = writeJSONFile(action, exportFile, flags.action.force); err != nil {
+            return err
+        }
+
+        printActionExported(qualifiedName.entityName, exportFile)
+
+        return nil
+    },
+}
+
+var actionDiffCmd = &cobra.Command{
+    Use:           "diff ACTION_NAME FILE",
+    Short:         wski18n.T("compare the code of a deployed action with a local file; exits with 1 when they differ and 2 when they cannot be compared"),
+    SilenceUsage:  true,
+    SilenceErrors: true,
+    PreRunE:       setupClientConfig,
+    RunE: func(cmd *cobra.Command, args []string) error {
+        if whiskErr := checkArgs(args, 2, 2, "Action diff", wski18n.T("An action name and a file are required.")); whiskErr != nil {
+            return whiskErr
+        }
+
+        differ, err := diffActionCode(args[0], args[1])
+        if err != nil {
+            return actionDiffTroubleError(err)
+        }
+
+        if differ {
+            return actionDiffFoundError()
+        }
+
+        return nil
+    },
+}
+
+// diffActionCode prints the differences between the code of an action and a file, and reports whether there are any
+func diffActionCode(actionName string, filename string) (bool, error) {
+    var action *whisk.Action
+    var qualifiedName QualifiedName
+    var code string
+    var err error
+
+    if qualifiedName, err = parseQualifiedName(actionName); err != nil {
+        return false, parseQualifiedNameError(actionName, err)
+    }
+
+    if code, err = readFile(filename); err != nil {
+        return false, err
+    }
+
+    client.Namespace = qualifiedName.namespace
+
+    if action, _, err = client.Actions.Get(qualifiedName.entityName); err != nil {
+        return false, actionGetError(qualifiedName.entityName, err)
+    }
+
+    if action.Exec == nil || action.Exec.Code == nil || action.Exec.Kind == "sequence" {
+        return false, noCodeDiffError(qualifiedName.entityName)
+    }
+
+    if _, isBinary := decodeBinaryCode(*action.Exec.Code); isBinary || bytes.HasPrefix([]byte(code), []byte("PK\x03\x04")) {
+        return false, binaryDiffError()
+    }
+
+    diff := getLineDiff(splitLines(*action.Exec.Code), splitLines(code))
+
+    return printUnifiedDiff(qualifiedName.entityName, filename, diff), nil
+}
+
+var actionWatchCmd = &cobra.Command{
+    Use:           "watch ACTION_NAME FILE",
+    Short:         wski18n.T("update an action with the code of a file whenever the file changes"),
+    SilenceUsage:  true,
+    SilenceErrors: true,
+    PreRunE:       setupClientConfig,
+    RunE: func(cmd *cobra.Command, args []string) error {
+        var qualifiedName QualifiedName
+        var fileInfo os.FileInfo
+        var err error
+
+        if whiskErr := checkArgs(args, 2, 2, "Action watch", wski18n.T("An action name and a file are required.")); whiskErr != nil {
+            return whiskErr
+        }
+
+        if qualifiedName, err = parseQualifiedName(args[0]); err != nil {
+            return parseQualifiedNameError(args[0], err)
+        }
+
+        // The file is read once up front so that a missing file or an unknown kind is reported before watching
+        if fileInfo, err = os.Stat(args[1]); err == nil {
+            _, err = getExec(args, flags.action)
+        }
+        if err != nil {
+            return actionWatchFileError(args[1], err)
+        }
+
+        client.Namespace = qualifiedName.namespace
+
+        c := make(chan os.Signal, 1)
+        signal.Notify(c, os.Interrupt)
+        signal.Notify(c, syscall.SIGTERM)
+
+        fmt.Println(wski18n.T("Watching {{.file}} for changes to action {{.name}}. Enter Ctrl-c to exit.",
+            map[string]interface{}{"file": args[1], "name": qualifiedName.entityName}))
+
+        ticker := time.NewTicker(ACTION_WATCH_INTERVAL)
+        defer ticker.Stop()
+
+        for {
+            select {
+            case <-c:
+                fmt.Println(wski18n.T("Watch terminated"))
+                return nil
+            case <-ticker.C:
+            }
+
+            // Editors often replace a file when saving it, so it may briefly not exist
+            latestInfo, err := os.Stat(args[1])
+            if err != nil || (latestInfo.ModTime().Equal(fileInfo.ModTime()) && latestInfo.Size() == fileInfo.Size()) {
+                continue
+            }
+
+            fileInfo = latestInfo
+            if watchDeployAction(qualifiedName, args) && flags.action.invokeAfter {
+                watchInvokeAction(qualifiedName)
+            }
+        }
+    },
+}
+
+// watchDeployAction updates an action with the code of the file in args, printing a timestamped line with the
+// outcome. It returns true when the action was updated.
+func watchDeployAction(qualifiedName QualifiedName, args []string) (bool) {
+    var exec *whisk.Exec
+    var err error
+
+    timestamp := time.Now().Format("15:04:05")
+
+    if exec, err = getExec(args, flags.action); err == nil {
+        action := &whisk.Action{Name: qualifiedName.entityName, Namespace: qualifiedName.namespace, Exec: exec}
+        _, _, err = client.Actions.Insert(action, true)
+    }
+
+    if err != nil {
+        whisk.Debug(whisk.DbgError, "Updating action '%s' from '%s' failed: %s\n", qualifiedName.entityName, args[1], err)
+        fmt.Fprintf(colorable.NewColorableStderr(),
+            wski18n.T("{{.time}} {{.error}} unable to update action {{.name}}: {{.err}}\n",
+                map[string]interface{}{
+                    "time": timestamp,
+                    "error": color.RedString(wski18n.T("error:")),
+                    "name": boldString(qualifiedName.entityName),
+                    "err": err,
+                }))
+        return false
+    }
+
+    fmt.Fprintf(color.Output,
+        wski18n.T("{{.time}} {{.ok}} updated action {{.name}}\n",
+            map[string]interface{}{
+                "time": timestamp,
+                "ok": color.GreenString("ok:"),
+                "name": boldString(qualifiedName.entityName),
+            }))
+
+    return true
+}
+
+// watchInvokeAction invokes an action without parameters and without blocking, printing the activation ID
+func watchInvokeAction(qualifiedName QualifiedName) {
+    _, activationID, _, err := client.Actions.Invoke(qualifiedName.entityName, nil, false, false)
+    timestamp := time.Now().Format("15:04:05")
+
+    if err != nil {
+        whisk.Debug(whisk.DbgError, "client.Actions.Invoke(%s) failed: %s\n", qualifiedName.entityName, err)
+        fmt.Fprintf(colorable.NewColorableStderr(),
+            wski18n.T("{{.time}} {{.error}} unable to invoke action {{.name}}: {{.err}}\n",
+                map[string]interface{}{
+                    "time": timestamp,
+                    "error": color.RedString(wski18n.T("error:")),
+                    "name": boldString(qualifiedName.entityName),
+                    "err": err,
+                }))
+        return
+    }
+
+    fmt.Fprintf(color.Output,
+        wski18n.T("{{.time}} {{.ok}} invoked {{.name}} with id {{.id}}\n",
+            map[string]interface{}{
+                "time": timestamp,
+                "ok": color.GreenString("ok:"),
+                "name": boldString(qualifiedName.entityName),
+                "id": boldString(activationID),
+            }))
+}
+
+var actionImportCmd = &cobra.Command{
+    Use:           "import FILE [ACTION_NAME]",
+    Short:         wski18n.T("create or update an action from a JSON file written by action export"),
+    SilenceUsage:  true,
+    SilenceErrors: true,
+    PreRunE:       setupClientConfig,
+    RunE: func(cmd *cobra.Command, args []string) error {
+        var action *whisk.Action
+        var qualifiedName QualifiedName
+        var content string
+        var err error
+
+        if whiskErr := checkArgs(args, 1, 2, "Action import", wski18n.T("An action definition file is required. An action name is optional.")); whiskErr != nil {
+            return whiskErr
+        }
+
+        if content, err = readJSONObjectFile(args[0]); err != nil {
+            return err
+        }
+
+        if err = json.Unmarshal([]byte(content), &action); err != nil {
+            return actionImportError(args[0], err)
+        }
+
+        if len(args) == 2 {
+            action.Name = args[1]
+        } else if len(flags.action.name) > 0 {
+            action.Name = flags.action.name
+        }
+
+        if len(action.Name) == 0 {
+            return actionImportMissingFieldError(args[0], "name")
+        }
+
+        if action.Exec == nil || len(action.Exec.Kind) == 0 {
+            return actionImportMissingFieldError(args[0], "exec.kind")
+        }
+
+        // The file may come from a deployment with other runtimes, so an unknown kind is only a warning and the
+        // server decides whether to accept it
+        if !whisk.IsSupportedRuntime(action.Exec.Kind) {
+            fmt.Fprintf(colorable.NewColorableStderr(),
+                wski18n.T("{{.warning}} the kind '{{.kind}}' of action {{.name}} is not available on the target deployment\n",
+                    map[string]interface{}{
+                        "warning": color.YellowString("warning:"),
+                        "kind": action.Exec.Kind,
+                        "name": boldString(action.Name),
+                    }))
+        }
+
+        // The namespace and version in the file belong to the exported action, not the imported one
+        if qualifiedName, err = parseQualifiedName(action.Name); err != nil {
+            return parseQualifiedNameError(action.Name, err)
+        }
+
+        client.Namespace = qualifiedName.namespace
+        action.Namespace = ""
+        action.Version = ""
+        action.Name = qualifiedName.entityName
+
+        if _, _, err = client.Actions.InsertAnyKind(action, flags.action.overwrite || flags.action.update); err != nil {
+            return actionInsertError(action, err)
+        }
+
+        printActionImported(qualifiedName.entityName, args[0])
+
+        return nil
+    },
+}
+
+var actionSequenceCmd = &cobra.Command{
+    Use:   "sequence",
+    Short: wski18n.T("add or remove the components of a sequence action"),
+}
+
+var actionSequenceAddCmd = &cobra.Command{
+    Use:           "add SEQUENCE_NAME ACTION_NAME",
+    Short:         wski18n.T("add an action to a sequence"),
+    SilenceUsage:  true,
+    SilenceErrors: true,
+    PreRunE:       setupClientConfig,
+    RunE: func(cmd *cobra.Command, args []string) error {
+        var action *whisk.Action
+        var etag string
+        var err error
+
+        if whiskErr := checkArgs(
+            args,
+            2,
+            2,
+            "Action sequence add",
+            wski18n.T("A sequence name and an action name are required.")); whiskErr != nil {
+                return whiskErr
+        }
+
+        if action, etag, err = getSequence(args[0]); err != nil {
+            return err
+        }
+
+        component := getQualifiedName(args[1], Properties.Namespace)
+        components := action.Exec.Components
+        position := flags.action.position
+
+        if position < 0 {
+            position = len(components)
+        } else if position > len(components) {
+            return sequencePositionError(position, len(components))
+        }
+
+        action.Exec.Components = append(append(append([]string{}, components[:position]...), component),
+            components[position:]...)
+
+        if err = updateSequence(action, etag); err != nil {
+            return err
+        }
+
+        printSequenceComponentAdded(component, action.Name)
+
+        return nil
+    },
+}
+
+var actionSequenceRemoveCmd = &cobra.Command{
+    Use:           "remove SEQUENCE_NAME ACTION_NAME",
+    Short:         wski18n.T("remove the first occurrence of an action from a sequence"),
+    SilenceUsage:  true,
+    SilenceErrors: true,
+    PreRunE:       setupClientConfig,
+    RunE: func(cmd *cobra.Command, args []string) error {
+        var action *whisk.Action
+        var etag string
+        var err error
+
+        if whiskErr := checkArgs(
+            args,
+            2,
+            2,
+            "Action sequence remove",
+            wski18n.T("A sequence name and an action name are required.")); whiskErr != nil {
+                return whiskErr
+        }
+
+        if action, etag, err = getSequence(args[0]); err != nil {
+            return err
+        }
+
+        component := getQualifiedName(args[1], Properties.Namespace)
+        components := action.Exec.Components
+        position := -1
+
+        for i := range components {
+            if isSameComponent(components[i], component) {
+                position = i
+                break
+            }
+        }
+
+        if position < 0 {
+            return nonNestedError(wski18n.T(
+                "Action '{{.component}}' is not a component of sequence '{{.name}}'",
+                map[string]interface{}{
+                    "component": component,
+                    "name": action.Name,
+                }))
+        }
+
+        action.Exec.Components = append(append([]string{}, components[:position]...), components[position + 1:]...)
+
+        if err = updateSequence(action, etag); err != nil {
+            return err
+        }
+
+        printSequenceComponentRemoved(component, action.Name)
+
+        return nil
+    },
+}
+
+var actionLimitsCmd = &cobra.Command{
+    Use:   "limits",
+    Short: wski18n.T("work with the limits of an action"),
+}
+
+var actionLimitsUpdateCmd = &cobra.Command{
+    Use:           "update ACTION_NAME",
+    Short:         wski18n.T("change only the given limits of an action, keeping its code and other limits"),
+    SilenceUsage:  true,
+    SilenceErrors: true,
+    PreRunE:       setupClientConfig,
+    RunE: func(cmd *cobra.Command, args []string) error {
+        var qualifiedName QualifiedName
+        var action *whisk.Action
+        var etag string
+        var resp *http.Response
+        var err error
+
+        if whiskErr := checkArgs(
+            args,
+            1,
+            1,
+            "Action limits update",
+            wski18n.T("An action name is required.")); whiskErr != nil {
+                return whiskErr
+        }
+
+        limits := getLimits(
+            cmd.LocalFlags().Changed(MEMORY_FLAG),
+            cmd.LocalFlags().Changed(LOG_SIZE_FLAG) || cmd.LocalFlags().Changed("logs"),
+            cmd.LocalFlags().Changed(TIMEOUT_FLAG),
+            flags.action.memory,
+            flags.action.logsize,
+            flags.action.timeout)
+
+        if limits == nil {
+            return nonNestedError(wski18n.T("At least one of --timeout, --memory or --logsize is required."))
+        }
+
+        if err = validateLimits(limits); err != nil {
+            return err
+        }
+
+        if qualifiedName, err = parseQualifiedName(args[0]); err != nil {
+            return parseQualifiedNameError(args[0], err)
+        }
+
+        client.Namespace = qualifiedName.namespace
+
+        if action, etag, _, err = client.Actions.GetWithETag(qualifiedName.entityName); err != nil {
+            return actionGetError(qualifiedName.entityName, err)
+        }
+
+        action.Name = qualifiedName.entityName
+        action.Namespace = ""
+        action.Version = ""
+        action.Limits = mergeLimits(action.Limits, limits)
+
+        if _, resp, err = client.Actions.InsertIfMatch(action, true, etag); err != nil {
+            if resp != nil && resp.StatusCode == http.StatusPreconditionFailed {
+                return actionModifiedError(action, err)
+            }
+
+            return actionInsertError(action, err)
+        }
+
+        printActionUpdated(qualifiedName.entityName)
+
+        return nil
+    },
+}
+
+var actionInvokeCmd = &cobra.Command{
+    Use:           "invoke ACTION_NAME",
+    Short:         wski18n.T("invoke action"),
+    SilenceUsage:  true,
+    SilenceErrors: true,
+    PreRunE:       setupClientConfig,
+    RunE: func(cmd *cobra.Command, args []string) error {
+        var err error
+        var parameters interface{}
+        var qualifiedName QualifiedName
+        var paramArgs []string
+
+        if whiskErr := checkArgs(
+            args,
+            1,
+            1,
+            "Action invoke",
+            wski18n.T("An action name is required.")); whiskErr != nil {
+                return whiskErr
+        }
+
+        if qualifiedName, err = parseQualifiedName(args[0]); err != nil {
+            return parseQualifiedNameError(args[0], err)
+        }
+
+        client.Namespace = qualifiedName.namespace
+        paramArgs = flags.common.param
+
+        if len(flags.action.paramJSON) > 0 {
+            paramArgs = append([]string{flags.action.paramJSON}, paramArgs...)
+        }
+
+        // Trigger feeds are configured by calling this function without a command
+        if cmd != nil && cmd.LocalFlags().Changed("data") {
+            if len(paramArgs) > 0 {
+                return invokeDataParamError()
+            }
+
+            if parameters, err = getRawJSONPayload(flags.action.data); err != nil {
+                return err
+            }
+        } else if len(paramArgs) > 0 {
+            if parameters, err = getJSONFromStrings(paramArgs, false); err != nil {
+                return getJSONFromStringsParamError(paramArgs, false, err)
+            }
+        }
+
+        if flags.action.webInvoke && flags.action.streamResult {
+            return nonNestedError(wski18n.T("The --web and --stream-result options cannot be used together."))
+        }
+
+        if flags.action.webInvoke {
+            return invokeWebAction(qualifiedName, parameters)
+        }
+
+        if flags.action.streamResult {
+            return invokeAndStreamResult(qualifiedName, parameters)
+        }
+
+        if flags.action.wait > 0 {
+            return invokeAndWait(qualifiedName, parameters)
+        }
+
+        if flags.action.result {flags.common.blocking = true}
+
+        // A --result invocation also asks for the whole activation, so that the activation id printed below the result
+        // is known when the server sends no activation id header
+        options := &whisk.InvokeOptions{
+            Blocking: flags.common.blocking,
+            ActionTimeout: time.Duration(flags.action.invokeTimeout) * time.Millisecond,
+        }
+
+        activation, res, _, err := client.Actions.InvokeWithActivation(
+            qualifiedName.entityName,
+            parameters,
+            options)
+
+        var activationID interface{}
+        if activation != nil {
+            activationID = activation.ActivationID
+        }
+        if flags.action.result {
+            // Only the result is printed, which holds the error of a failed activation
+            status := getActivationResponseStatus(res)
+            res = getActivationResult(res)
+            if errResult, found := res["error"]; found && err == nil {
+                err = errorResultError(status, errResult)
+            }
+        }
+
+        return handleInvocationResponse(qualifiedName, parameters, activationID, res, err)
+    },
+}
+
+// getRawJSONPayload decodes the --data value so it is sent as the request body exactly as given, whether it is an
+// object, array, string, number, boolean or null
+func getRawJSONPayload(data string) (interface{}, error) {
+    var payload interface{}
+
+    dc := json.NewDecoder(strings.NewReader(data))
+    dc.UseNumber()
+    if err := dc.Decode(&payload); err != nil {
+        whisk.Debug(whisk.DbgError, "Invalid JSON detected for --data '%s': %s\n", data, err)
+        errMsg := wski18n.T("The --data value is not valid JSON: {{.err}}", map[string]interface{}{"err": err})
+        return nil, whisk.MakeWskError(errors.New(errMsg), whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG,
+            whisk.DISPLAY_USAGE)
+    }
+
+    if dc.More() {
+        whisk.Debug(whisk.DbgError, "Trailing data after the JSON value in --data '%s'\n", data)
+        errMsg := wski18n.T("The --data value must be a single JSON value")
+        return nil, whisk.MakeWskError(errors.New(errMsg), whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG,
+            whisk.DISPLAY_USAGE)
+    }
+
+    return payload, nil
+}
+
+// invokeAndWait invokes an action without blocking and then polls for its result for up to --wait seconds, which
+// avoids the time limit on blocking invocations
+func invokeAndWait(qualifiedName QualifiedName, parameters interface{}) (error) {
+    deadline := time.Now().Add(time.Duration(flags.action.wait) * time.Second)
+    options := &whisk.InvokeOptions{}
+
+    result, _, err := client.Actions.InvokeWithOptions(
+        qualifiedName.entityName,
+        parameters,
+        options)
+    if err != nil {
+        return handleInvocationError(err, qualifiedName.entityName, parameters)
+    }
+
+    activationID := fmt.Sprintf("%v", getValueFromJSONResponse(ACTIVATION_ID, result))
+    printInvocationMsg(qualifiedName.namespace, qualifiedName.entityName, activationID, result, options.Blocking,
+        color.Output)
+
+    response, err := waitForActivationResult(activationID, deadline)
+    if err != nil {
+        return err
+    }
+
+    if flags.action.result {
+        printJSON(response.Result)
+    } else {
+        printJSON(response)
+    }
+
+    return nil
+}
+
+// invokeAndStreamResult invokes an action without blocking, so that its activation id is known at once, and prints
+// the logs of the activation as the server makes them available until it completes. Servers that only record the logs
+// of a completed activation return them all at once, at the end. The completed activation holds the last log lines,
+// which are printed before its result. The activation is waited for until --wait seconds have passed, or otherwise
+// for as long as a blocking invocation.
+func invokeAndStreamResult(qualifiedName QualifiedName, parameters interface{}) (error) {
+    timeout := whisk.MaxActionTimeout
+    if flags.action.invokeTimeout > 0 {
+        timeout = time.Duration(flags.action.invokeTimeout) * time.Millisecond
+    }
+    deadline := time.Now().Add(timeout + whisk.BlockingInvokeMargin)
+    if flags.action.wait > 0 {
+        deadline = time.Now().Add(time.Duration(flags.action.wait) * time.Second)
+    }
+
+    options := &whisk.InvokeOptions{}
+    result, _, err := client.Actions.InvokeWithOptions(
+        qualifiedName.entityName,
+        parameters,
+        options)
+    if err != nil {
+        return handleInvocationError(err, qualifiedName.entityName, parameters)
+    }
+
+    activationID := fmt.Sprintf("%v", getValueFromJSONResponse(ACTIVATION_ID, result))
+    printInvocationMsg(qualifiedName.namespace, qualifiedName.entityName, activationID, result, options.Blocking,
+        color.Output)
+
+    poller := client.Activations.NewLogsPoller()
+    lines := poller.Start(activationID)
+    printed := 0
+    printerDone := make(chan struct{})
+
+    go func() {
+        defer close(printerDone)
+        for line := range lines {
+            printActivationLogs([]string{line})
+            printed++
+        }
+    }()
+
+    activation, err := waitForActivation(activationID, deadline)
+    poller.Stop()
+    <-printerDone
+
+    if err != nil {
+        return err
+    }
+
+    if printed < len(activation.Logs) {
+        printActivationLogs(activation.Logs[printed:])
+    }
+
+    if flags.action.result {
+        printJSON(activation.Response.Result)
+    } else {
+        printJSON(activation.Response)
+    }
+
+    if !activation.Response.Success {
+        var errResult interface{} = activation.Response.Status
+        if activation.Response.Result != nil {
+            if resultErr, found := (*activation.Response.Result)["error"]; found {
+                errResult = resultErr
+            }
+        }
+        return errorResultError(activation.Response.Status, errResult)
+    }
+
+    return nil
+}
+
+// invokeWebAction invokes a web action through its web URL and prints the response body: JSON is indented, other
+// content is printed as is. The parameters are sent as the query of a GET or DELETE request, and as the body of a
+// POST or PUT request.
+func invokeWebAction(qualifiedName QualifiedName, parameters interface{}) (error) {
+    options := &whisk.WebInvokeOptions{
+        Method:    strings.ToUpper(flags.action.webMethod),
+        AuthToken: flags.action.webAuthToken,
+    }
+
+    switch options.Method {
+    case "POST", "PUT":
+        options.Body = parameters
+    case "GET", "DELETE":
+        query, err := getWebQuery(parameters)
+        if err != nil {
+            return err
         }
+        options.Query = query
+    default:
+        return webMethodError(flags.action.webMethod)
+    }
 
-        printActionUpdated(action.Name)
+    // The web URL holds the namespace, so the default namespace cannot be resolved by the server
+    if qualifiedName.namespace == "_" {
+        return webNamespaceError(qualifiedName.entityName)
+    }
 
-        return nil
-    },
-}
+    namespace := qualifiedName.namespace
+    if len(qualifiedName.packageName) > 0 {
+        namespace = namespace + "/" + qualifiedName.packageName
+    }
 
-var actionInvokeCmd = &cobra.Command{
-    Use:           "invoke ACTION_NAME",
-    Short:         wski18n.T("invoke action"),
-    SilenceUsage:  true,
-    SilenceErrors: true,
-    PreRunE:       setupClientConfig,
-    RunE: func(cmd *cobra.Command, args []string) error {
-        var err error
-        var parameters interface{}
-        var qualifiedName QualifiedName
-        var paramArgs []string
+    body, resp, err := client.Actions.InvokeWeb(namespace, qualifiedName.entity, options)
+    if err != nil {
+        return handleInvocationError(err, qualifiedName.entityName, parameters)
+    }
 
-        if whiskErr := checkArgs(
-            args,
-            1,
-            1,
-            "Action invoke",
-            wski18n.T("An action name is required.")); whiskErr != nil {
-                return whiskErr
+    if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+        var decoded interface{}
+        dc := json.NewDecoder(bytes.NewReader(body))
+        dc.UseNumber()
+        if dc.Decode(&decoded) == nil {
+            printJSON(decoded)
+            return nil
         }
+    }
 
-        if qualifiedName, err = parseQualifiedName(args[0]); err != nil {
-            return parseQualifiedNameError(args[0], err)
-        }
+    os.Stdout.Write(body)
 
-        client.Namespace = qualifiedName.namespace
-        paramArgs = flags.common.param
+    return nil
+}
 
-        if len(paramArgs) > 0 {
-            if parameters, err = getJSONFromStrings(paramArgs, false); err != nil {
-                return getJSONFromStringsParamError(paramArgs, false, err)
-            }
-        }
-        if flags.action.result {flags.common.blocking = true}
+// getWebQuery converts parameters into the query of a web action request. String values are sent as is, and other
+// values as JSON.
+func getWebQuery(parameters interface{}) (url.Values, error) {
+    query := url.Values{}
 
-        res, _, err := client.Actions.Invoke(
-            qualifiedName.entityName,
-            parameters,
-            flags.common.blocking,
-            flags.action.result)
+    if parameters == nil {
+        return query, nil
+    }
 
-        return handleInvocationResponse(qualifiedName, parameters, res, err)
-    },
+    params, ok := parameters.(map[string]interface{})
+    if !ok {
+        return nil, webQueryError()
+    }
+
+    for key, value := range params {
+        if str, isString := value.(string); isString {
+            query.Set(key, str)
+        } else {
+            data, _ := json.Marshal(value)
+            query.Set(key, string(data))
+        }
+    }
+
+    return query, nil
 }
 
 func handleInvocationResponse(
     qualifiedName QualifiedName,
     parameters interface{},
+    activationID interface{},
     result map[string]interface{},
     err error) (error) {
         if err == nil {
             printInvocationMsg(
                 qualifiedName.namespace,
                 qualifiedName.entityName,
-                getValueFromJSONResponse(ACTIVATION_ID, result),
+                activationID,
                 result,
+                flags.common.blocking,
                 color.Output)
+            printResultActivationID(activationID)
         } else {
             if !flags.common.blocking {
                 return handleInvocationError(err, qualifiedName.entityName, parameters)
             } else {
                 if isBlockingTimeout(err) {
-                    printBlockingTimeoutMsg(
-                        qualifiedName.namespace,
-                        qualifiedName.entityName,
-                        getValueFromJSONResponse(ACTIVATION_ID, result))
+                    printBlockingTimeoutMsg(activationID)
                 } else if isApplicationError(err) {
                     printInvocationMsg(
                         qualifiedName.namespace,
                         qualifiedName.entityName,
-                        getValueFromJSONResponse(ACTIVATION_ID, result),
+                        activationID,
                         result,
+                        flags.common.blocking,
                         colorable.NewColorableStderr())
+                    printResultActivationID(activationID)
                 } else {
                     return handleInvocationError(err, qualifiedName.entityName, parameters)
                 }
@@ -212,8 +1069,8 @@ var actionGetCmd = &cobra.Command{
         if len(args) > 1 {
             field = args[1]
 
-            if !fieldExists(&whisk.Action{}, field) {
-                return invalidFieldFilterError(field)
+            if missingField := getMissingField(&whisk.Action{}, field); len(missingField) > 0 {
+                return invalidFieldFilterError(field, missingField)
             }
         }
 
@@ -223,10 +1080,22 @@ var actionGetCmd = &cobra.Command{
 
         client.Namespace = qualifiedName.namespace
 
+        if flags.action.raw {
+            return printRawAction(qualifiedName.entityName)
+        }
+
         if action, _, err = client.Actions.Get(qualifiedName.entityName); err != nil {
             return actionGetError(qualifiedName.entityName, err)
         }
 
+        if flags.action.save || len(flags.action.saveAs) > 0 {
+            return saveCode(action, flags.action.saveAs)
+        }
+
+        if len(flags.global.output) > 0 {
+            return printFormatted(action, flags.global.output)
+        }
+
         if flags.common.summary {
             printSummary(action)
         } else {
@@ -285,6 +1154,7 @@ var actionListCmd = &cobra.Command{
     RunE: func(cmd *cobra.Command, args []string) error {
         var qualifiedName QualifiedName
         var actions []whisk.Action
+        var total int
         var err error
 
         if whiskErr := checkArgs(
@@ -309,16 +1179,124 @@ var actionListCmd = &cobra.Command{
             Limit: flags.common.limit,
         }
 
-        if actions, _, err = client.Actions.List(qualifiedName.entityName, options); err != nil {
+        kinds := getActionListKinds()
+        filtered := len(flags.action.name) > 0 || len(kinds) > 0
+
+        // The server may not filter actions by name or kind, so every action is fetched and filtered here. --all lists
+        // every action, ignoring --skip and --limit.
+        if flags.common.all || filtered {
+            actions, err = client.Actions.ListAllWithOptions(qualifiedName.entityName,
+                &whisk.ActionListOptions{Kind: kinds, Name: flags.action.name})
+            err = getListAllPagesError(len(actions), err)
+        } else {
+            actions, total, _, err = client.Actions.List(qualifiedName.entityName, options)
+        }
+
+        if err != nil {
             return actionListError(qualifiedName.entityName, options, err)
         }
 
-        printList(actions)
+        if len(kinds) > 0 {
+            actions = filterActionsByKind(actions, kinds)
+        }
+
+        if len(flags.action.name) > 0 {
+            actions = filterActionsByName(actions, flags.action.name)
+        }
+
+        // --skip and --limit apply to the actions that match the filters, and the total is the number that match
+        if !flags.common.all && filtered {
+            total = len(actions)
+            actions = limitActions(skipActions(actions, flags.common.skip), flags.common.limit)
+        }
+
+        if err = printList(actions); err != nil {
+            return err
+        }
+
+        printListTotal(len(actions), total, "actions")
 
         return nil
     },
 }
 
+// getActionListKinds returns the kinds given to --kind and to its deprecated alias --filter-kind, comma separated
+func getActionListKinds() string {
+    var kinds []string
+    for _, flagKinds := range []string{flags.action.kind, flags.action.filterKind} {
+        if len(flagKinds) > 0 {
+            kinds = append(kinds, flagKinds)
+        }
+    }
+
+    return strings.Join(kinds, ",")
+}
+
+// filterActionsByKind keeps only the actions whose "exec" annotation matches one of the comma separated kinds. A kind
+// without a version, such as "python", also matches every version of that runtime, such as "python:3"
+func filterActionsByKind(actions []whisk.Action, kinds string) []whisk.Action {
+    var filtered []whisk.Action
+    var wanted []string
+
+    for _, kind := range strings.Split(kinds, ",") {
+        if kind = strings.TrimSpace(kind); len(kind) > 0 {
+            wanted = append(wanted, kind)
+        }
+    }
+
+    for _, action := range actions {
+        if isMatchingKind(getValueString(action.Annotations, "exec"), wanted) {
+            filtered = append(filtered, action)
+        }
+    }
+
+    return filtered
+}
+
+// skipActions returns actions without the first skip actions
+func skipActions(actions []whisk.Action, skip int) []whisk.Action {
+    if skip >= len(actions) {
+        return nil
+    }
+    if skip > 0 {
+        return actions[skip:]
+    }
+
+    return actions
+}
+
+// limitActions returns the first limit actions, or every action when limit is not positive
+func limitActions(actions []whisk.Action, limit int) []whisk.Action {
+    if limit > 0 && limit < len(actions) {
+        return actions[:limit]
+    }
+
+    return actions
+}
+
+// filterActionsByName keeps only the actions whose name starts with prefix
+func filterActionsByName(actions []whisk.Action, prefix string) []whisk.Action {
+    var filtered []whisk.Action
+
+    for _, action := range actions {
+        if strings.HasPrefix(action.Name, prefix) {
+            filtered = append(filtered, action)
+        }
+    }
+
+    return filtered
+}
+
+func isMatchingKind(kind string, wanted []string) bool {
+    for _, want := range wanted {
+        if kind == want || strings.HasPrefix(kind, want + ":") {
+            return true
+        }
+    }
+
+    return false
+}
+
 func parseAction(cmd *cobra.Command, args []string, update bool) (*whisk.Action, error) {
     var err error
     var existingAction *whisk.Action
@@ -345,6 +1323,10 @@ func parseAction(cmd *cobra.Command, args []string, update bool) (*whisk.Action,
         flags.action.logsize,
         flags.action.timeout)
 
+    if err = validateLimits(action.Limits); err != nil {
+        return nil, err
+    }
+
     paramArgs = flags.common.param
     annotArgs = flags.common.annotation
 
@@ -364,6 +1346,10 @@ func parseAction(cmd *cobra.Command, args []string, update bool) (*whisk.Action,
         action.Annotations = annotations.(whisk.KeyValueArr)
     }
 
+    if err = validateDockerFlags(args, flags.action); err != nil {
+        return nil, err
+    }
+
     if flags.action.copy {
         copiedQualifiedName := QualifiedName{}
 
@@ -385,7 +1371,16 @@ func parseAction(cmd *cobra.Command, args []string, update bool) (*whisk.Action,
         if len(args) == 2 {
             action.Exec = new(whisk.Exec)
             action.Exec.Kind = "sequence"
-            action.Exec.Components = csvToQualifiedActions(args[1])
+            if action.Exec.Components, err = csvToQualifiedActions(args[1]); err != nil {
+                return nil, err
+            }
+
+            sequenceName := getQualifiedName(args[0], Properties.Namespace)
+            for _, component := range action.Exec.Components {
+                if isSameComponent(component, sequenceName) {
+                    return nil, recursiveSequenceError(sequenceName)
+                }
+            }
         } else {
             return nil, noArtifactError()
         }
@@ -407,6 +1402,36 @@ func parseAction(cmd *cobra.Command, args []string, update bool) (*whisk.Action,
     return action, err
 }
 
+// validateDockerFlags rejects flags and artifacts that cannot be combined with a docker image. A code artifact is
+// allowed, as it is run by a docker skeleton image, except for a Java archive.
+func validateDockerFlags(args []string, params ActionFlags) (error) {
+    if len(params.docker) == 0 {
+        return nil
+    }
+
+    if params.sequence {
+        return dockerFlagError("--sequence")
+    }
+
+    if params.copy {
+        return dockerFlagError("--copy")
+    }
+
+    if len(params.kind) > 0 {
+        return dockerFlagError("--kind")
+    }
+
+    if params.native {
+        return dockerFlagError("--native")
+    }
+
+    if len(args) == 2 && filepath.Ext(args[1]) == ".jar" {
+        return nonNestedError(wski18n.T("A .jar file cannot be used with a docker image"))
+    }
+
+    return nil
+}
+
 func getExec(args []string, params ActionFlags) (*whisk.Exec, error) {
     var err error
     var code string
@@ -422,16 +1447,41 @@ func getExec(args []string, params ActionFlags) (*whisk.Exec, error) {
     if len(args) == 2 {
         artifact := args[1]
         ext = filepath.Ext(artifact)
-        code, err = readFile(artifact)
 
-        if err != nil {
-            whisk.Debug(whisk.DbgError, "readFile(%s) error: %s\n", artifact, err)
-            return nil, err
+        if fileInfo, statErr := os.Stat(artifact); statErr == nil && fileInfo.IsDir() {
+            var archive []byte
+
+            if archive, err = zipDirectory(artifact); err != nil {
+                return nil, err
+            }
+
+            // A Node.js action is the only kind whose runtime can be told from the contents of the directory
+            if _, statErr = os.Stat(filepath.Join(artifact, "package.json")); statErr == nil && len(kind) == 0 {
+                kind = "nodejs:default"
+            }
+
+            if len(kind) == 0 {
+                return nil, directoryKindError()
+            }
+
+            ext = ".zip"
+            code = base64.StdEncoding.EncodeToString(archive)
+        } else {
+            code, err = readFile(artifact)
+
+            if err != nil {
+                whisk.Debug(whisk.DbgError, "readFile(%s) error: %s\n", artifact, err)
+                return nil, err
+            }
+
+            if ext == ".zip" || ext == ".jar" {
+                // Base64 encode the file
+                code = base64.StdEncoding.EncodeToString([]byte(code))
+            }
         }
 
-        if ext == ".zip" || ext == ".jar" {
-            // Base64 encode the file
-            code = base64.StdEncoding.EncodeToString([]byte(code))
+        if len(code) > MAX_CODE_SIZE {
+            return nil, codeSizeError(artifact, len(code))
         }
 
         exec.Code = &code
@@ -478,6 +1528,200 @@ func getExec(args []string, params ActionFlags) (*whisk.Exec, error) {
     return exec, nil
 }
 
+// getSequence gets a sequence action, along with its ETag, to change its components
+func getSequence(name string) (*whisk.Action, string, error) {
+    var qualifiedName QualifiedName
+    var action *whisk.Action
+    var etag string
+    var err error
+
+    if qualifiedName, err = parseQualifiedName(name); err != nil {
+        return nil, "", parseQualifiedNameError(name, err)
+    }
+
+    client.Namespace = qualifiedName.namespace
+
+    if action, etag, _, err = client.Actions.GetWithETag(qualifiedName.entityName); err != nil {
+        return nil, "", actionGetError(qualifiedName.entityName, err)
+    }
+
+    if action.Exec == nil || action.Exec.Kind != "sequence" {
+        return nil, "", nonNestedError(wski18n.T(
+            "Action '{{.name}}' is not a sequence",
+            map[string]interface{}{
+                "name": qualifiedName.entityName,
+            }))
+    }
+
+    action.Name = qualifiedName.entityName
+    action.Namespace = ""
+    action.Version = ""
+
+    return action, etag, nil
+}
+
+// updateSequence replaces a sequence with its changed components, failing if it was changed in the meantime
+func updateSequence(action *whisk.Action, etag string) (error) {
+    var resp *http.Response
+    var err error
+
+    if _, resp, err = client.Actions.InsertIfMatch(action, true, etag); err != nil {
+        if resp != nil && resp.StatusCode == http.StatusPreconditionFailed {
+            return actionModifiedError(action, err)
+        }
+
+        return actionInsertError(action, err)
+    }
+
+    return nil
+}
+
+// isSameComponent reports whether two fully qualified action names refer to the same action. The default namespace
+// "_" matches any namespace.
+func isSameComponent(name string, otherName string) (bool) {
+    qualifiedName, err := parseQualifiedName(name)
+    if err != nil {
+        return name == otherName
+    }
+
+    otherQualifiedName, err := parseQualifiedName(otherName)
+    if err != nil {
+        return name == otherName
+    }
+
+    return qualifiedName.entityName == otherQualifiedName.entityName &&
+        (qualifiedName.namespace == otherQualifiedName.namespace ||
+            qualifiedName.namespace == "_" || otherQualifiedName.namespace == "_")
+}
+
+// printRawAction writes the response body of an action get request to standard output as it was received
+func printRawAction(entityName string) (error) {
+    data, _, err := client.Actions.GetRaw(entityName)
+    if err != nil {
+        return actionGetError(entityName, err)
+    }
+
+    if _, err = os.Stdout.Write(data); err != nil {
+        whisk.Debug(whisk.DbgError, "os.Stdout.Write() failed: %s\n", err)
+        errMsg := wski18n.T("Unable to write the action to standard output: {{.err}}", map[string]interface{}{"err": err})
+        return whisk.MakeWskError(errors.New(errMsg), whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG,
+            whisk.NO_DISPLAY_USAGE)
+    }
+
+    return nil
+}
+
+// saveCode writes the code of an action to filename, or to a file named after the action with an extension
+// matching its kind when filename is empty. Existing files are never overwritten.
+func saveCode(action *whisk.Action, filename string) (error) {
+    var code []byte
+
+    if action.Exec == nil || action.Exec.Kind == "sequence" {
+        return sequenceSaveError(action.Name)
+    }
+
+    if action.Exec.Code == nil || len(*action.Exec.Code) == 0 {
+        return noCodeSaveError(action.Name)
+    }
+
+    code = []byte(*action.Exec.Code)
+    decoded, isBinary := decodeBinaryCode(*action.Exec.Code)
+
+    if isBinary {
+        code = decoded
+    }
+
+    if len(filename) == 0 {
+        filename = filepath.Base(action.Name) + getKindExtension(action.Exec.Kind, isBinary)
+    }
+
+    if _, err := os.Stat(filename); err == nil {
+        return fileExistsError(filename)
+    }
+
+    if err := ioutil.WriteFile(filename, code, 0644); err != nil {
+        whisk.Debug(whisk.DbgError, "ioutil.WriteFile(%s) error: %s\n", filename, err)
+        errMsg := wski18n.T(
+            "Unable to save code of action '{{.name}}' to '{{.file}}': {{.err}}",
+            map[string]interface{}{
+                "name": action.Name,
+                "file": filename,
+                "err": err,
+            })
+
+        return nestedError(errMsg, err)
+    }
+
+    printActionSaved(action.Name, filename)
+
+    return nil
+}
+
+// decodeBinaryCode returns the decoded content of code when it is a base64 encoded zip or jar archive, as the server
+// stores the code of binary actions
+func decodeBinaryCode(code string) ([]byte, bool) {
+    decoded, err := base64.StdEncoding.DecodeString(code)
+    isBinary := err == nil && bytes.HasPrefix(decoded, []byte("PK\x03\x04"))
+
+    return decoded, isBinary
+}
+
+// getKindExtension returns the file extension used for the code of an action of the given kind
+func getKindExtension(kind string, isBinary bool) (string) {
+    runtime := strings.Split(kind, ":")[0]
+
+    switch {
+    case runtime == "java":
+        return ".jar"
+    case isBinary || runtime == "blackbox":
+        return ".zip"
+    case runtime == "nodejs":
+        return ".js"
+    case runtime == "python":
+        return ".py"
+    case runtime == "swift":
+        return ".swift"
+    }
+
+    return ""
+}
+
+// printSequenceComponents lists the components of a sequence in order. When describe is set, each component is
+// fetched to show its description; components that are sequences themselves are not expanded any further.
+func printSequenceComponents(components []string, describe bool) {
+    fmt.Fprintf(color.Output, "   (%s:)\n", boldString(wski18n.T("components")))
+
+    namespace := client.Namespace
+    defer func() { client.Namespace = namespace }()
+
+    for i, component := range components {
+        var description string
+
+        if describe {
+            if qualifiedName, err := parseQualifiedName(component); err != nil {
+                whisk.Debug(whisk.DbgWarn, "parseQualifiedName(%s) error: %s\n", component, err)
+            } else {
+                client.Namespace = qualifiedName.namespace
+                if action, _, err := client.Actions.Get(qualifiedName.entityName); err != nil {
+                    whisk.Debug(whisk.DbgWarn, "client.Actions.Get(%s) error: %s\n", component, err)
+                    description = wski18n.T("unavailable")
+                } else {
+                    description = getValueString(action.Annotations, "description")
+                    if action.Exec != nil && action.Exec.Kind == "sequence" {
+                        description = strings.TrimSpace(fmt.Sprintf("(%s) %s", wski18n.T("sequence"), description))
+                    }
+                }
+            }
+        }
+
+        if len(description) > 0 {
+            fmt.Fprintf(color.Output, "     %d. %s: %s\n", i + 1, component, description)
+        } else {
+            fmt.Fprintf(color.Output, "     %d. %s\n", i + 1, component)
+        }
+    }
+}
+
 func webAction(webMode string, annotations whisk.KeyValueArr, entityName string, fetch bool) (whisk.KeyValueArr, error){
     switch strings.ToLower(webMode) {
     case "yes":
@@ -495,6 +1739,52 @@ func webAction(webMode string, annotations whisk.KeyValueArr, entityName string,
     }
 }
 
+// isWebMode reports whether a --web value makes an action a web action
+func isWebMode(webMode string) bool {
+    switch strings.ToLower(webMode) {
+    case "yes", "true", "raw":
+        return true
+    }
+
+    return false
+}
+
+// getWebActionURL returns the URL of a web action. The namespace of an action in a package, as returned by the
+// server, ends with the package name; actions outside a package are served from the "default" package.
+func getWebActionURL(namespace string, name string) (string, error) {
+    packageName := "default"
+    if parts := strings.SplitN(namespace, "/", 2); len(parts) == 2 {
+        namespace, packageName = parts[0], parts[1]
+    }
+
+    baseURL, err := getURLBase(Properties.APIHost, "/api/v1/web")
+    if err != nil {
+        whisk.Debug(whisk.DbgError, "getURLBase(%s, /api/v1/web) error: %s\n", Properties.APIHost, err)
+        return "", err
+    }
+
+    return fmt.Sprintf("%s/%s/%s/%s", baseURL, namespace, packageName, name), nil
+}
+
+// printWebActionURL prints the URL of a web action that was just created or updated. The namespace and name
+// returned by the server are used; when they are missing, the requested name is used instead.
+func printWebActionURL(inserted *whisk.Action, entityName string) {
+    var namespace, name string
+
+    if inserted != nil && len(inserted.Namespace) > 0 && len(inserted.Name) > 0 {
+        namespace, name = inserted.Namespace, inserted.Name
+    } else {
+        namespace, name = client.Namespace, entityName
+        if i := strings.LastIndex(name, "/"); i >= 0 {
+            namespace, name = namespace + "/" + name[:i], name[i + 1:]
+        }
+    }
+
+    if url, err := getWebActionURL(namespace, name); err == nil {
+        fmt.Println(url)
+    }
+}
+
 type WebActionAnnotationMethod func(annotations whisk.KeyValueArr) (whisk.KeyValueArr)
 
 func webActionAnnotations(
@@ -515,40 +1805,92 @@ func webActionAnnotations(
             }
         }
 
-        return annotations, nil
+        return annotations, nil
+}
+
+func addWebAnnotations(annotations whisk.KeyValueArr) (whisk.KeyValueArr) {
+    return setWebAnnotations(annotations, true, false, true)
+}
+
+// deleteWebAnnotations removes the web action annotations, rather than setting them to false
+func deleteWebAnnotations(annotations whisk.KeyValueArr) (whisk.KeyValueArr) {
+    annotations = annotations.Delete(WEB_EXPORT_ANNOT)
+    annotations = annotations.Delete(RAW_HTTP_ANNOT)
+    annotations = annotations.Delete(FINAL_ANNOT)
+
+    return annotations
+}
+
+func addRawAnnotations(annotations whisk.KeyValueArr) (whisk.KeyValueArr) {
+    return setWebAnnotations(annotations, true, true, true)
 }
 
-func addWebAnnotations(annotations whisk.KeyValueArr) (whisk.KeyValueArr) {
-    annotations = deleteWebAnnotationKeys(annotations)
-    annotations = addKeyValue(WEB_EXPORT_ANNOT, true, annotations)
-    annotations = addKeyValue(RAW_HTTP_ANNOT, false, annotations)
-    annotations = addKeyValue(FINAL_ANNOT, true, annotations)
+// isActionKeyValueMerge reports whether an update keeps some of the existing parameters or annotations of the action
+func isActionKeyValueMerge() bool {
+    return flags.action.mergeParams || flags.action.mergeAnnots || len(getActionDeletedParams()) > 0 ||
+        len(flags.common.delAnnotation) > 0
+}
 
-    return annotations
+// getActionDeletedParams returns the keys given to --del-param and to its older alias --delete-param
+func getActionDeletedParams() ([]string) {
+    return append(append([]string{}, flags.common.delParam...), flags.action.deleteParam...)
 }
 
-func deleteWebAnnotations(annotations whisk.KeyValueArr) (whisk.KeyValueArr) {
-    annotations = deleteWebAnnotationKeys(annotations)
-    annotations = addKeyValue(WEB_EXPORT_ANNOT, false, annotations)
-    annotations = addKeyValue(RAW_HTTP_ANNOT, false, annotations)
-    annotations = addKeyValue(FINAL_ANNOT, false, annotations)
+// mergeActionKeyValues merges the parameters and annotations of action over those of existingAction, as selected by
+// --merge-params, --merge-annotations, --del-param and --del-annotation. The values of action win when both have the
+// same key.
+func mergeActionKeyValues(action *whisk.Action, existingAction *whisk.Action) {
+    if deletedParams := getActionDeletedParams(); flags.action.mergeParams || len(deletedParams) > 0 {
+        action.Parameters = mergeAndDeleteKeyValues(existingAction.Parameters, action.Parameters, deletedParams,
+            "parameter", action.Name)
+    }
 
-    return annotations
+    if flags.action.mergeAnnots || len(flags.common.delAnnotation) > 0 {
+        action.Annotations = mergeAndDeleteKeyValues(existingAction.Annotations, action.Annotations,
+            flags.common.delAnnotation, "annotation", action.Name)
+    }
 }
 
-func addRawAnnotations(annotations whisk.KeyValueArr) (whisk.KeyValueArr) {
-    annotations = deleteWebAnnotationKeys(annotations)
-    annotations = addKeyValue(WEB_EXPORT_ANNOT, true, annotations)
-    annotations = addKeyValue(RAW_HTTP_ANNOT, true, annotations)
-    annotations = addKeyValue(FINAL_ANNOT, true, annotations)
+// mergeKeyValues returns current with each key value of changed replacing the value of the same key, or appended
+func mergeKeyValues(current whisk.KeyValueArr, changed whisk.KeyValueArr) (whisk.KeyValueArr) {
+    merged := append(whisk.KeyValueArr{}, current...)
 
-    return annotations
+    for _, keyValue := range changed {
+        merged = merged.AddOrReplace(keyValue)
+    }
+
+    return merged
+}
+
+// mergeLimits returns the current limits of an action with the explicitly set limits replacing their counterparts
+func mergeLimits(current *whisk.Limits, changed *whisk.Limits) (*whisk.Limits) {
+    limits := new(whisk.Limits)
+
+    if current != nil {
+        *limits = *current
+    }
+
+    if changed.Timeout != nil {
+        limits.Timeout = changed.Timeout
+    }
+
+    if changed.Memory != nil {
+        limits.Memory = changed.Memory
+    }
+
+    if changed.Logsize != nil {
+        limits.Logsize = changed.Logsize
+    }
+
+    return limits
 }
 
-func deleteWebAnnotationKeys(annotations whisk.KeyValueArr) (whisk.KeyValueArr) {
-    annotations = deleteKey(WEB_EXPORT_ANNOT, annotations)
-    annotations = deleteKey(RAW_HTTP_ANNOT, annotations)
-    annotations = deleteKey(FINAL_ANNOT, annotations)
+// setWebAnnotations sets the web action annotations, which always come last and in this order
+func setWebAnnotations(annotations whisk.KeyValueArr, webExport bool, rawHTTP bool, final bool) (whisk.KeyValueArr) {
+    annotations = deleteWebAnnotations(annotations)
+    annotations = append(annotations, whisk.KeyValue{Key: WEB_EXPORT_ANNOT, Value: webExport})
+    annotations = append(annotations, whisk.KeyValue{Key: RAW_HTTP_ANNOT, Value: rawHTTP})
+    annotations = append(annotations, whisk.KeyValue{Key: FINAL_ANNOT, Value: final})
 
     return annotations
 }
@@ -575,6 +1917,45 @@ func getLimits(memorySet bool, logSizeSet bool, timeoutSet bool, memory int, log
     return limits
 }
 
+// validateLimits checks the explicitly set limits against the ranges accepted by the server
+func validateLimits(limits *whisk.Limits) (error) {
+    if limits == nil {
+        return nil
+    }
+
+    if limits.Timeout != nil && (*limits.Timeout < MIN_TIMEOUT_LIMIT || *limits.Timeout > MAX_TIMEOUT_LIMIT) {
+        return nonNestedError(wski18n.T(
+            "Invalid timeout limit {{.value}}; the timeout must be between {{.min}} and {{.max}} milliseconds.",
+            map[string]interface{}{
+                "value": *limits.Timeout,
+                "min": MIN_TIMEOUT_LIMIT,
+                "max": MAX_TIMEOUT_LIMIT,
+            }))
+    }
+
+    if limits.Memory != nil && (*limits.Memory < MIN_MEMORY_LIMIT || *limits.Memory > MAX_MEMORY_LIMIT) {
+        return nonNestedError(wski18n.T(
+            "Invalid memory limit {{.value}}; the memory must be between {{.min}} and {{.max}} MB.",
+            map[string]interface{}{
+                "value": *limits.Memory,
+                "min": MIN_MEMORY_LIMIT,
+                "max": MAX_MEMORY_LIMIT,
+            }))
+    }
+
+    if limits.Logsize != nil && (*limits.Logsize < MIN_LOGSIZE_LIMIT || *limits.Logsize > MAX_LOGSIZE_LIMIT) {
+        return nonNestedError(wski18n.T(
+            "Invalid log size limit {{.value}}; the log size must be between {{.min}} and {{.max}} MB.",
+            map[string]interface{}{
+                "value": *limits.Logsize,
+                "min": MIN_LOGSIZE_LIMIT,
+                "max": MAX_LOGSIZE_LIMIT,
+            }))
+    }
+
+    return nil
+}
+
 func nestedError(errorMessage string, err error) (error) {
     return whisk.MakeWskErrorFromWskError(
         errors.New(errorMessage),
@@ -617,6 +1998,47 @@ func actionInsertError(action *whisk.Action, err error) (error) {
     return nestedError(errMsg, err)
 }
 
+func actionModifiedError(action *whisk.Action, err error) (error) {
+    whisk.Debug(whisk.DbgError, "client.Actions.InsertIfMatch(%#v) precondition failed: %s\n", action, err)
+
+    errMsg := wski18n.T(
+        "Unable to update action '{{.name}}': the action was changed by another client; retry the update: {{.err}}",
+        map[string]interface{}{
+            "name": action.Name,
+            "err": err,
+        })
+
+    return whisk.MakeWskErrorFromWskError(errors.New(errMsg), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG,
+        whisk.NO_DISPLAY_USAGE)
+}
+
+func actionImportError(file string, err error) (error) {
+    whisk.Debug(whisk.DbgError, "json.Unmarshal() of '%s' into an action failed: %s\n", file, err)
+
+    errMsg := wski18n.T(
+        "File '{{.name}}' does not contain a valid action definition: {{.err}}",
+        map[string]interface{}{
+            "name": file,
+            "err": err,
+        })
+
+    return nestedError(errMsg, err)
+}
+
+func actionImportMissingFieldError(file string, field string) (error) {
+    whisk.Debug(whisk.DbgError, "Action definition in '%s' is missing '%s'\n", file, field)
+
+    errMsg := wski18n.T(
+        "The action definition in '{{.name}}' is missing the required field '{{.field}}'",
+        map[string]interface{}{
+            "name": file,
+            "field": field,
+        })
+
+    return whisk.MakeWskError(errors.New(errMsg), whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG,
+        whisk.NO_DISPLAY_USAGE)
+}
+
 func getJSONFromStringsParamError(params []string, keyValueFormat bool, err error) (error) {
     whisk.Debug(whisk.DbgError, "getJSONFromStrings(%#v, %t) failed: %s\n", params, keyValueFormat, err)
 
@@ -630,6 +2052,13 @@ func getJSONFromStringsParamError(params []string, keyValueFormat bool, err erro
     return nestedError(errMsg, err)
 }
 
+func invokeDataParamError() (error) {
+    whisk.Debug(whisk.DbgError, "--data was combined with parameter arguments\n")
+    errMsg := wski18n.T("The --data flag cannot be combined with --param, --param-file or --param-json.")
+
+    return nonNestedError(errMsg)
+}
+
 func getJSONFromStringsAnnotError(annots []string, keyValueFormat bool, err error) (error) {
     whisk.Debug(whisk.DbgError, "getJSONFromStrings(%#v, %t) failed: %s\n", annots, keyValueFormat, err)
 
@@ -643,19 +2072,39 @@ func getJSONFromStringsAnnotError(annots []string, keyValueFormat bool, err erro
     return nestedError(errMsg, err)
 }
 
-func invalidFieldFilterError(field string) (error) {
+func invalidFieldFilterError(field string, missingField string) (error) {
     errMsg := wski18n.T(
         "Invalid field filter '{{.arg}}'.",
         map[string]interface{}{
             "arg": field,
         })
 
+    if field != missingField {
+        errMsg = wski18n.T(
+            "Invalid field filter '{{.arg}}'; there is no field '{{.field}}'.",
+            map[string]interface{}{
+                "arg": field,
+                "field": missingField,
+            })
+    }
+
     return nonNestedError(errMsg)
 }
 
 func actionDeleteError(entityName string, err error) (error) {
     whisk.Debug(whisk.DbgError, "client.Actions.Delete(%s) error: %s\n", entityName, err)
 
+    if errorResponse := whisk.GetHttpErrorResponse(err); errorResponse != nil &&
+            errorResponse.StatusCode == http.StatusNotFound {
+        errMsg := wski18n.T(
+            "Unable to delete action '{{.name}}': not found",
+            map[string]interface{}{
+                "name": entityName,
+            })
+
+        return nestedError(errMsg, err)
+    }
+
     errMsg := wski18n.T(
         "Unable to delete action '{{.name}}': {{.err}}",
         map[string]interface{}{
@@ -679,6 +2128,42 @@ func actionGetError(entityName string, err error) (error) {
     return nestedError(errMsg, err)
 }
 
+// errorResultError is the application error of an invocation whose result holds an error. The activation status tells
+// an action developer error and a whisk internal error apart from an error returned by the action. The result is
+// printed instead of the message.
+func errorResultError(status interface{}, errResult interface{}) (error) {
+    errMsg := whisk.GetApplicationErrorMessage(status, errResult)
+
+    return whisk.MakeWskError(errors.New(errMsg), whisk.EXITCODE_ERR_GENERAL, whisk.NO_DISPLAY_MSG,
+        whisk.NO_DISPLAY_USAGE, whisk.NO_MSG_DISPLAYED, whisk.DISPLAY_PREFIX, whisk.APPLICATION_ERR)
+}
+
+func webMethodError(method string) (error) {
+    errMsg := wski18n.T(
+        "Invalid method '{{.method}}'; the method of a web action invocation must be GET, POST, PUT or DELETE",
+        map[string]interface{}{
+            "method": method,
+        })
+
+    return nonNestedError(errMsg)
+}
+
+func webNamespaceError(entityName string) (error) {
+    errMsg := wski18n.T(
+        "A namespace is required to invoke web action '{{.name}}'; use /NAMESPACE/{{.name}} or set the namespace property",
+        map[string]interface{}{
+            "name": entityName,
+        })
+
+    return nonNestedError(errMsg)
+}
+
+func webQueryError() (error) {
+    errMsg := wski18n.T("The parameters of a GET or DELETE web action invocation must be a JSON object")
+
+    return nonNestedError(errMsg)
+}
+
 func handleInvocationError(err error, entityName string, parameters interface{}) (error) {
     whisk.Debug(
         whisk.DbgError,
@@ -726,12 +2211,71 @@ func zipKindError() (error) {
     return nonNestedError(errMsg)
 }
 
+func directoryKindError() (error) {
+    errMsg := wski18n.T("creating an action from a directory without a package.json file requires specifying the action kind explicitly")
+
+    return nonNestedError(errMsg)
+}
+
+func sequencePositionError(position int, length int) (error) {
+    errMsg := wski18n.T(
+        "Invalid position {{.position}}; the sequence has {{.length}} components",
+        map[string]interface{}{
+            "position": position,
+            "length": length,
+        })
+
+    return nonNestedError(errMsg)
+}
+
+func emptySequenceComponentError(components string) (error) {
+    errMsg := wski18n.T(
+        "Sequence components '{{.components}}' contain an empty action name",
+        map[string]interface{}{
+            "components": components,
+        })
+
+    return nonNestedError(errMsg)
+}
+
+func recursiveSequenceError(name string) (error) {
+    errMsg := wski18n.T(
+        "Sequence '{{.name}}' cannot include itself as a component",
+        map[string]interface{}{
+            "name": name,
+        })
+
+    return nonNestedError(errMsg)
+}
+
+func dockerFlagError(flag string) (error) {
+    errMsg := wski18n.T(
+        "The --docker flag cannot be combined with {{.flag}}",
+        map[string]interface{}{
+            "flag": flag,
+        })
+
+    return nonNestedError(errMsg)
+}
+
 func noArtifactError() (error) {
     errMsg := wski18n.T("An action name and code artifact are required.")
 
     return nonNestedError(errMsg)
 }
 
+func codeSizeError(artifact string, size int) (error) {
+    errMsg := wski18n.T(
+        "The code of '{{.name}}' is {{.size}} bytes, which is larger than the limit of {{.limit}} bytes",
+        map[string]interface{}{
+            "name": artifact,
+            "size": size,
+            "limit": MAX_CODE_SIZE,
+        })
+
+    return nonNestedError(errMsg)
+}
+
 func extensionError(extension string) (error) {
     errMsg := wski18n.T(
         "'{{.name}}' is not a supported action runtime",
@@ -742,6 +2286,87 @@ func extensionError(extension string) (error) {
     return nonNestedError(errMsg)
 }
 
+func sequenceSaveError(entityName string) (error) {
+    errMsg := wski18n.T(
+        "Action '{{.name}}' is a sequence and has no code to save",
+        map[string]interface{}{
+            "name": entityName,
+        })
+
+    return nonNestedError(errMsg)
+}
+
+func actionWatchFileError(file string, err error) (error) {
+    whisk.Debug(whisk.DbgError, "Unable to watch '%s': %s\n", file, err)
+
+    if _, isWhiskErr := err.(*whisk.WskError); isWhiskErr {
+        return err
+    }
+
+    errMsg := wski18n.T("File '{{.name}}' is not a valid file or it does not exist: {{.err}}",
+        map[string]interface{}{"name": file, "err": err})
+    whiskErr := whisk.MakeWskError(errors.New(errMsg), whisk.EXITCODE_ERR_USAGE, whisk.DISPLAY_MSG,
+        whisk.DISPLAY_USAGE)
+
+    return whiskErr
+}
+
+func noCodeDiffError(entityName string) (error) {
+    errMsg := wski18n.T("Action '{{.name}}' has no code to compare.", map[string]interface{}{"name": entityName})
+    whiskErr := whisk.MakeWskError(errors.New(errMsg), whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG,
+        whisk.NO_DISPLAY_USAGE)
+
+    return whiskErr
+}
+
+func binaryDiffError() (error) {
+    errMsg := wski18n.T("binary action; cannot diff")
+    whiskErr := whisk.MakeWskError(errors.New(errMsg), whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG,
+        whisk.NO_DISPLAY_USAGE)
+
+    return whiskErr
+}
+
+// actionDiffTroubleError exits with 2 when an action and a file cannot be compared, as diff does, so that trouble is
+// told apart from differences
+func actionDiffTroubleError(err error) (error) {
+    if whiskErr, ok := err.(*whisk.WskError); ok {
+        whiskErr.ExitCode = whisk.EXITCODE_ERR_USAGE
+        return whiskErr
+    }
+
+    return whisk.MakeWskError(err, whisk.EXITCODE_ERR_USAGE, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+}
+
+// actionDiffFoundError exits with 1 without a message, as diff does, since the differences are already printed
+func actionDiffFoundError() (error) {
+    errMsg := wski18n.T("The action and the file differ.")
+    whiskErr := whisk.MakeWskError(errors.New(errMsg), whisk.EXITCODE_ERR_GENERAL, whisk.NO_DISPLAY_MSG,
+        whisk.NO_DISPLAY_USAGE)
+
+    return whiskErr
+}
+
+func noCodeSaveError(entityName string) (error) {
+    errMsg := wski18n.T(
+        "Action '{{.name}}' has no code to save",
+        map[string]interface{}{
+            "name": entityName,
+        })
+
+    return nonNestedError(errMsg)
+}
+
+func fileExistsError(file string) (error) {
+    errMsg := wski18n.T(
+        "The file '{{.file}}' already exists",
+        map[string]interface{}{
+            "file": file,
+        })
+
+    return nonNestedError(errMsg)
+}
+
 func javaEntryError() (error) {
     errMsg := wski18n.T("Java actions require --main to specify the fully-qualified name of the main class")
 
@@ -770,16 +2395,27 @@ func printActionUpdated(entityName string) {
             }))
 }
 
-func printBlockingTimeoutMsg(namespace string, entityName string, activationID interface{}) {
+func printActionCopied(sourceName string, targetName string) {
     fmt.Fprintf(
-        colorable.NewColorableStderr(),
+        color.Output,
         wski18n.T(
-            "{{.ok}} invoked /{{.namespace}}/{{.name}}, but the request has not yet finished, with id {{.id}}\n",
+            "{{.ok}} copied action {{.source}} to {{.target}}\n",
             map[string]interface{}{
                 "ok": color.GreenString("ok:"),
-                "namespace": boldString(namespace),
-                "name": boldString(entityName),
-                "id": boldString(activationID),
+                "source": boldString(sourceName),
+                "target": boldString(targetName),
+            }))
+}
+
+// printBlockingTimeoutMsg reports a blocking invocation that timed out before the action finished, in place of the
+// result, with the activation to poll for it
+func printBlockingTimeoutMsg(activationID interface{}) {
+    fmt.Fprintf(
+        colorable.NewColorableStderr(),
+        wski18n.T(
+            "activation id: {{.id}}, result not yet available, poll with wsk activation get {{.id}}\n",
+            map[string]interface{}{
+                "id": activationID,
             }))
 }
 
@@ -788,6 +2424,7 @@ func printInvocationMsg(
     entityName string,
     activationID interface{},
     response map[string]interface{},
+    blocking bool,
     outputStream io.Writer) {
         if !flags.action.result {
             fmt.Fprintf(
@@ -802,11 +2439,36 @@ func printInvocationMsg(
                     }))
         }
 
-        if flags.common.blocking {
+        if blocking {
             printJSON(response, outputStream)
         }
 }
 
+// getActivationResult returns the result held by the activation that a blocking invocation returned, or nil when the
+// invocation did not return a completed activation
+func getActivationResult(activation map[string]interface{}) map[string]interface{} {
+    response, _ := activation["response"].(map[string]interface{})
+    result, _ := response["result"].(map[string]interface{})
+
+    return result
+}
+
+// getActivationResponseStatus returns the status of an activation decoded as a map, such as "action developer error", or nil
+func getActivationResponseStatus(activation map[string]interface{}) interface{} {
+    response, _ := activation["response"].(map[string]interface{})
+
+    return response["status"]
+}
+
+// printResultActivationID prints the activation id below the result of a blocking --result invocation, whose output
+// otherwise has no id. It goes to stderr so that the result on stdout remains valid JSON.
+func printResultActivationID(activationID interface{}) {
+    if flags.action.result && activationID != nil {
+        fmt.Fprintf(colorable.NewColorableStderr(),
+            wski18n.T("activation id: {{.id}}\n", map[string]interface{}{"id": activationID}))
+    }
+}
+
 func printActionGetWithField(entityName string, field string, action *whisk.Action) {
     fmt.Fprintf(
         color.Output,
@@ -833,6 +2495,66 @@ func printActionGet(entityName string, action *whisk.Action) {
     printJSON(action)
 }
 
+func printActionSaved(entityName string, file string) {
+    fmt.Fprintf(
+        color.Output,
+        wski18n.T(
+            "{{.ok}} saved action code of {{.name}} to {{.file}}\n",
+            map[string]interface{}{
+                "ok": color.GreenString("ok:"),
+                "name": boldString(entityName),
+                "file": file,
+            }))
+}
+
+func printActionExported(entityName string, file string) {
+    fmt.Fprintf(
+        color.Output,
+        wski18n.T(
+            "{{.ok}} exported action {{.name}} to {{.file}}\n",
+            map[string]interface{}{
+                "ok": color.GreenString("ok:"),
+                "name": boldString(entityName),
+                "file": file,
+            }))
+}
+
+func printActionImported(entityName string, file string) {
+    fmt.Fprintf(
+        color.Output,
+        wski18n.T(
+            "{{.ok}} imported action {{.name}} from {{.file}}\n",
+            map[string]interface{}{
+                "ok": color.GreenString("ok:"),
+                "name": boldString(entityName),
+                "file": file,
+            }))
+}
+
+func printSequenceComponentAdded(component string, sequenceName string) {
+    fmt.Fprintf(
+        color.Output,
+        wski18n.T(
+            "{{.ok}} added {{.component}} to sequence {{.name}}\n",
+            map[string]interface{}{
+                "ok": color.GreenString("ok:"),
+                "component": boldString(component),
+                "name": boldString(sequenceName),
+            }))
+}
+
+func printSequenceComponentRemoved(component string, sequenceName string) {
+    fmt.Fprintf(
+        color.Output,
+        wski18n.T(
+            "{{.ok}} removed {{.component}} from sequence {{.name}}\n",
+            map[string]interface{}{
+                "ok": color.GreenString("ok:"),
+                "component": boldString(component),
+                "name": boldString(sequenceName),
+            }))
+}
+
 func printActionDeleted(entityName string) {
     fmt.Fprintf(
         color.Output,
@@ -893,6 +2615,7 @@ func init() {
     actionCreateCmd.Flags().StringSliceVarP(&flags.common.annotation, "annotation", "a", nil, wski18n.T("annotation values in `KEY VALUE` format"))
     actionCreateCmd.Flags().StringVarP(&flags.common.annotFile, "annotation-file", "A", "", wski18n.T("`FILE` containing annotation values in JSON format"))
     actionCreateCmd.Flags().StringSliceVarP(&flags.common.param, "param", "p", nil, wski18n.T("parameter values in `KEY VALUE` format"))
+    actionCreateCmd.Flags().StringSliceVar(&flags.common.param, "param-string", nil, wski18n.T("parameter values in `KEY VALUE` format, sent as strings even when they are valid JSON"))
     actionCreateCmd.Flags().StringVarP(&flags.common.paramFile, "param-file", "P", "", wski18n.T("`FILE` containing parameter values in JSON format"))
     actionCreateCmd.Flags().StringVar(&flags.action.web, "web", "", wski18n.T("treat ACTION as a web action, a raw HTTP web action, or as a standard action; yes | true = web action, raw = raw HTTP web action, no | false = standard action"))
 
@@ -908,22 +2631,87 @@ func init() {
     actionUpdateCmd.Flags().StringSliceVarP(&flags.common.annotation, "annotation", "a", []string{}, wski18n.T("annotation values in `KEY VALUE` format"))
     actionUpdateCmd.Flags().StringVarP(&flags.common.annotFile, "annotation-file", "A", "", wski18n.T("`FILE` containing annotation values in JSON format"))
     actionUpdateCmd.Flags().StringSliceVarP(&flags.common.param, "param", "p", []string{}, wski18n.T("parameter values in `KEY VALUE` format"))
+    actionUpdateCmd.Flags().StringSliceVar(&flags.common.param, "param-string", []string{}, wski18n.T("parameter values in `KEY VALUE` format, sent as strings even when they are valid JSON"))
     actionUpdateCmd.Flags().StringVarP(&flags.common.paramFile, "param-file", "P", "", wski18n.T("`FILE` containing parameter values in JSON format"))
+    actionUpdateCmd.Flags().BoolVar(&flags.action.mergeParams, "merge-params", false, wski18n.T("keep the existing parameters of the action, replacing only those that are given"))
+    actionUpdateCmd.Flags().BoolVar(&flags.action.mergeAnnots, "merge-annotations", false, wski18n.T("keep the existing annotations of the action, replacing only those that are given"))
+    actionUpdateCmd.Flags().StringSliceVar(&flags.common.delParam, "del-param", []string{}, wski18n.T("remove the parameter `KEY` from the existing parameters of the action; implies --merge-params"))
+    actionUpdateCmd.Flags().StringSliceVar(&flags.action.deleteParam, "delete-param", []string{}, wski18n.T("same as --del-param"))
+    actionUpdateCmd.Flags().MarkHidden("delete-param")
+    actionUpdateCmd.Flags().StringSliceVar(&flags.common.delAnnotation, "del-annotation", []string{}, wski18n.T("remove the annotation `KEY` from the existing annotations of the action; implies --merge-annotations"))
+    actionUpdateCmd.Flags().StringVar(&flags.action.ifMatch, "if-match", "", wski18n.T("only update the action if its ETag is still `ETAG` instead of the ETag read just before the update"))
     actionUpdateCmd.Flags().StringVar(&flags.action.web, "web", "", wski18n.T("treat ACTION as a web action, a raw HTTP web action, or as a standard action; yes | true = web action, raw = raw HTTP web action, no | false = standard action"))
 
+    actionCopyCmd.Flags().BoolVar(&flags.action.overwrite, "overwrite", false, wski18n.T("replace the target action if it already exists"))
+
+    actionExportCmd.Flags().StringVar(&flags.action.exportFile, "file", "", wski18n.T("write the action definition to `FILE` instead of standard output"))
+    actionExportCmd.Flags().BoolVar(&flags.action.force, "force", false, wski18n.T("replace the output file if it already exists"))
+
+    actionImportCmd.Flags().BoolVar(&flags.action.overwrite, "overwrite", false, wski18n.T("replace the action if it already exists"))
+    actionImportCmd.Flags().BoolVar(&flags.action.update, "update", false, wski18n.T("update the action if it already exists; the same as --overwrite"))
+    actionImportCmd.Flags().StringVar(&flags.action.name, "name", "", wski18n.T("create the action as `ACTION_NAME` instead of the name in the file"))
+
+    actionSequenceAddCmd.Flags().IntVar(&flags.action.position, "position", -1, wski18n.T("insert the action at the zero based `POSITION` instead of appending it"))
+
+    actionSequenceCmd.AddCommand(
+        actionSequenceAddCmd,
+        actionSequenceRemoveCmd,
+    )
+
+    actionLimitsUpdateCmd.Flags().IntVarP(&flags.action.timeout, "timeout", "t", TIMEOUT_LIMIT, wski18n.T("the timeout `LIMIT` in milliseconds after which the action is terminated"))
+    actionLimitsUpdateCmd.Flags().IntVarP(&flags.action.memory, "memory", "m", MEMORY_LIMIT, wski18n.T("the maximum memory `LIMIT` in MB for the action"))
+    actionLimitsUpdateCmd.Flags().IntVarP(&flags.action.logsize, "logsize", "l", LOGSIZE_LIMIT, wski18n.T("the maximum log size `LIMIT` in MB for the action"))
+    actionLimitsUpdateCmd.Flags().IntVar(&flags.action.logsize, "logs", LOGSIZE_LIMIT, wski18n.T("the maximum log size `LIMIT` in MB for the action; the same as --logsize"))
+
+    actionLimitsCmd.AddCommand(
+        actionLimitsUpdateCmd,
+    )
+
+    actionWatchCmd.Flags().StringVar(&flags.action.kind, "kind", "", wski18n.T("the `KIND` of the action runtime (example: swift:default, nodejs:default)"))
+    actionWatchCmd.Flags().StringVar(&flags.action.main, "main", "", wski18n.T("the name of the action entry point (function or fully-qualified method name when applicable)"))
+    actionWatchCmd.Flags().BoolVar(&flags.action.invokeAfter, "invoke-after", false, wski18n.T("invoke the action without parameters after each update"))
+
     actionInvokeCmd.Flags().StringSliceVarP(&flags.common.param, "param", "p", []string{}, wski18n.T("parameter values in `KEY VALUE` format"))
-    actionInvokeCmd.Flags().StringVarP(&flags.common.paramFile, "param-file", "P", "", wski18n.T("`FILE` containing parameter values in JSON format"))
+    actionInvokeCmd.Flags().StringSliceVar(&flags.common.param, "param-string", []string{}, wski18n.T("parameter values in `KEY VALUE` format, sent as strings even when they are valid JSON"))
+    actionInvokeCmd.Flags().StringVarP(&flags.common.paramFile, "param-file", "P", "", wski18n.T("`FILE` containing parameter values in JSON format; use - to read from standard input"))
+    actionInvokeCmd.Flags().StringSliceVar(&flags.action.namedParam, "named-param", []string{}, wski18n.T("parameter value in `KEY=VALUE` format; the value is parsed as JSON when it is valid JSON"))
+    actionInvokeCmd.Flags().StringVar(&flags.action.paramJSON, "param-json", "", wski18n.T("parameter values as an inline `JSON` object"))
+    actionInvokeCmd.Flags().StringVar(&flags.action.data, "data", "", wski18n.T("send `JSON` as the request body as is; it may be any JSON value and cannot be combined with parameters"))
     actionInvokeCmd.Flags().BoolVarP(&flags.common.blocking, "blocking", "b", false, wski18n.T("blocking invoke"))
+    actionInvokeCmd.Flags().IntVar(&flags.action.invokeTimeout, "timeout", 0, wski18n.T("the timeout `LIMIT` in milliseconds of the action, used to decide how long to wait for a blocking invoke; the largest action timeout limit when not set"))
+    actionInvokeCmd.Flags().IntVar(&flags.action.wait, "wait", 0, wski18n.T("invoke without blocking, then wait up to `SECONDS` for the activation to complete and show its result"))
+    actionInvokeCmd.Flags().BoolVar(&flags.action.streamResult, "stream-result", false, wski18n.T("print the logs of the activation as the server makes them available, and then its result; servers that only record the logs of completed activations show them all at the end"))
+    actionInvokeCmd.Flags().BoolVar(&flags.action.webInvoke, "web", false, wski18n.T("invoke the action through its web action URL, without the authorization key, and print the response body"))
+    actionInvokeCmd.Flags().StringVar(&flags.action.webMethod, "method", "GET", wski18n.T("the HTTP `METHOD` of a --web invocation; GET | POST | PUT | DELETE"))
+    actionInvokeCmd.Flags().StringVar(&flags.action.webAuthToken, "auth-token", "", wski18n.T("the `SECRET` of a --web invocation of a web action that requires authentication"))
     actionInvokeCmd.Flags().BoolVarP(&flags.action.result, "result", "r", false, wski18n.T("blocking invoke; show only activation result (unless there is a failure)"))
 
     actionGetCmd.Flags().BoolVarP(&flags.common.summary, "summary", "s", false, wski18n.T("summarize action details"))
+    actionGetCmd.Flags().BoolVarP(&flags.common.full, "full", "f", false, wski18n.T("with --summary, fetch and describe each component of a sequence"))
+    actionGetCmd.Flags().BoolVar(&flags.action.save, "save", false, wski18n.T("save action code to a file named after the action"))
+    actionGetCmd.Flags().StringVar(&flags.action.saveAs, "save-as", "", wski18n.T("save action code to the file `FILENAME`"))
+    actionGetCmd.Flags().BoolVar(&flags.action.raw, "raw", false, wski18n.T("print the response body from the server as is, without formatting; other display flags are ignored"))
 
     actionListCmd.Flags().IntVarP(&flags.common.skip, "skip", "s", 0, wski18n.T("exclude the first `SKIP` number of actions from the result"))
     actionListCmd.Flags().IntVarP(&flags.common.limit, "limit", "l", 30, wski18n.T("only return `LIMIT` number of actions from the collection"))
+    actionListCmd.Flags().BoolVar(&flags.common.all, "all", false, wski18n.T("fetch every page of actions, ignoring --limit and --skip"))
+    actionListCmd.Flags().StringVar(&flags.action.name, "name", "", wski18n.T("only list actions whose name starts with `PREFIX`; every page of actions is fetched"))
+    actionListCmd.Flags().BoolVar(&flags.action.showVersion, "show-version", false, wski18n.T("include the version of each action"))
+    actionListCmd.Flags().BoolVar(&flags.action.skipNamespace, "skip-namespace", false, wski18n.T("show action names without the namespace prefix"))
+    actionListCmd.Flags().StringVar(&flags.action.kind, "kind", "", wski18n.T("only list actions whose runtime matches one of the comma separated `KINDS`, such as python:3, python, sequence or blackbox; every page of actions is fetched"))
+    actionListCmd.Flags().StringVar(&flags.action.filterKind, "filter-kind", "", wski18n.T("same as --kind"))
+    actionListCmd.Flags().MarkDeprecated("filter-kind", wski18n.T("use --kind instead"))
 
     actionCmd.AddCommand(
         actionCreateCmd,
         actionUpdateCmd,
+        actionCopyCmd,
+        actionExportCmd,
+        actionImportCmd,
+        actionDiffCmd,
+        actionWatchCmd,
+        actionSequenceCmd,
+        actionLimitsCmd,
         actionInvokeCmd,
         actionGetCmd,
         actionDeleteCmd,
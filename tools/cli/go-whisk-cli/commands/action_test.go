@@ -0,0 +1,2907 @@
+/*
+ * Copyright 2015-2016 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+    "encoding/base64"
+    "encoding/json"
+    "fmt"
+    "io"
+    "io/ioutil"
+    "math/rand"
+    "net/http"
+    "os"
+    "os/signal"
+    "path/filepath"
+    "reflect"
+    "regexp"
+    "strconv"
+    "strings"
+    "sync"
+    "syscall"
+    "testing"
+    "time"
+
+    "github.com/spf13/cobra"
+
+    "../../go-whisk/whisk"
+)
+
+// actionListHandler answers action list requests with actions, paged by the limit and skip query parameters. Pages
+// that start at failSkip fail.
+func actionListHandler(actions []whisk.Action, failSkip int) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+        skip, _ := strconv.Atoi(r.URL.Query().Get("skip"))
+        if skip == failSkip {
+            writeJSON(w, http.StatusInternalServerError, map[string]interface{}{"error": "list failed", "code": 1})
+            return
+        }
+
+        page := []whisk.Action{}
+        for i := skip; i < len(actions) && (limit == 0 || i < skip + limit); i++ {
+            page = append(page, actions[i])
+        }
+        w.Header().Set(whisk.TotalCountHeader, strconv.Itoa(len(actions)))
+        writeJSON(w, http.StatusOK, page)
+    }
+}
+
+// getTestActions returns count actions named a0, a1, ...
+func getTestActions(count int) []whisk.Action {
+    var actions []whisk.Action
+    for i := 0; i < count; i++ {
+        actions = append(actions, whisk.Action{Namespace: "ns", Name: fmt.Sprintf("a%d", i)})
+    }
+
+    return actions
+}
+
+// getTestKindActions returns actions of mixed kinds, each named after its kind
+func getTestKindActions() []whisk.Action {
+    var actions []whisk.Action
+    for _, kind := range []string{"nodejs:6", "python:3", "nodejs:default", "python", "swift:3", "java", "nodejsx"} {
+        actions = append(actions, whisk.Action{
+            Namespace: "ns",
+            Name: strings.Replace(kind, ":", "-", -1),
+            Annotations: whisk.KeyValueArr{{Key: "exec", Value: kind}},
+        })
+    }
+
+    return actions
+}
+
+func TestActionListAll(t *testing.T) {
+    server := newTestServer(t, actionListHandler(getTestActions(450), -1))
+    defer server.Close()
+    flags.common.all = true
+    flags.common.skip = 10
+    flags.common.limit = 30
+
+    var err error
+    output := captureOutput(t, func() { err = actionListCmd.RunE(actionListCmd, []string{}) })
+    if err != nil {
+        t.Fatalf("action list --all failed: %s", err)
+    }
+
+    // --all ignores --skip and --limit
+    checkRequests(t, server, "GET ns/actions", "GET ns/actions", "GET ns/actions")
+    var skips []string
+    for _, request := range server.requests {
+        skips = append(skips, request.Query.Get("skip") + "/" + request.Query.Get("limit"))
+    }
+    if want := "0/200 200/200 400/200"; strings.Join(skips, " ") != want {
+        t.Errorf("skip/limit of the requests = %s, want %s", strings.Join(skips, " "), want)
+    }
+
+    if lines := strings.Count(output, "/ns/a"); lines != 450 {
+        t.Errorf("action list --all printed %d actions, want 450", lines)
+    }
+    if !strings.Contains(output, "/ns/a0 ") || !strings.Contains(output, "/ns/a449 ") {
+        t.Errorf("action list --all did not print actions a0 to a449:\n%s", output)
+    }
+}
+
+func TestActionListAllKeepsEarlierPages(t *testing.T) {
+    server := newTestServer(t, actionListHandler(getTestActions(450), 200))
+    defer server.Close()
+    flags.common.all = true
+
+    var err error
+    output := captureOutput(t, func() { err = actionListCmd.RunE(actionListCmd, []string{}) })
+    if err != nil {
+        t.Fatalf("action list --all failed: %s", err)
+    }
+
+    if lines := strings.Count(output, "/ns/a"); lines != 200 {
+        t.Errorf("action list --all printed %d actions, want the 200 of the first page", lines)
+    }
+}
+
+func TestActionListFilterKind(t *testing.T) {
+    server := newTestServer(t, actionListHandler(getTestKindActions(), -1))
+    defer server.Close()
+    flags.action.kind = "nodejs, swift:3"
+    flags.common.limit = 30
+
+    var err error
+    output := captureOutput(t, func() { err = actionListCmd.RunE(actionListCmd, []string{}) })
+    if err != nil {
+        t.Fatalf("action list --kind failed: %s", err)
+    }
+
+    var listed []string
+    for _, line := range strings.Split(strings.TrimSpace(output), "\n")[1:] {
+        fields := strings.Fields(line)
+        listed = append(listed, fields[0] + " " + fields[len(fields) - 1])
+    }
+
+    want := []string{"/ns/nodejs-6 nodejs:6", "/ns/nodejs-default nodejs:default", "/ns/swift-3 swift:3"}
+    if strings.Join(listed, ", ") != strings.Join(want, ", ") {
+        t.Errorf("action list --kind %q listed %q, want %q", flags.action.kind, listed, want)
+    }
+}
+
+func TestFilterActionsByKind(t *testing.T) {
+    var actions []whisk.Action
+    for _, kind := range []string{"nodejs:6", "python:3", "python:2", "python", "pythonic:1", "sequence", "blackbox",
+            "swift:3", ""} {
+        action := whisk.Action{Name: kind}
+        if len(kind) > 0 {
+            action.Annotations = whisk.KeyValueArr{{Key: "exec", Value: kind}}
+        }
+        actions = append(actions, action)
+    }
+
+    tests := []struct {
+        kinds   string
+        want    string
+    }{
+        {"python:3", "python:3"},
+        // A kind without a version matches every version, but not other runtimes with the same prefix
+        {"python", "python:3 python:2 python"},
+        {"sequence", "sequence"},
+        {"blackbox", "blackbox"},
+        {"sequence,blackbox", "sequence blackbox"},
+        {" nodejs , swift:3 ", "nodejs:6 swift:3"},
+        {"python:", ""},
+        {"java", ""},
+        {",", ""},
+    }
+
+    for _, test := range tests {
+        var names []string
+        for _, action := range filterActionsByKind(actions, test.kinds) {
+            names = append(names, action.Name)
+        }
+
+        if strings.Join(names, " ") != test.want {
+            t.Errorf("filterActionsByKind(%q) = %q, want %q", test.kinds, names, test.want)
+        }
+    }
+}
+
+func TestActionListFilterKindCount(t *testing.T) {
+    tests := []struct {
+        skip    int
+        limit   int
+        want    string
+    }{
+        // --skip and --limit apply to the matching actions, and the total counts every matching action
+        {0, 5, "actions\n/ns/python-3 private python:3\n/ns/python private python\n"},
+        {0, 1, "actions\n/ns/python-3 private python:3\nshowing 1 of 2 actions\n"},
+        {1, 1, "actions\n/ns/python private python\nshowing 1 of 2 actions\n"},
+        {2, 5, "actions\n"},
+    }
+
+    for _, test := range tests {
+        server := newTestServer(t, actionListHandler(append(getTestActions(30), getTestKindActions()...), -1))
+        flags.action.kind = "python"
+        flags.common.skip, flags.common.limit = test.skip, test.limit
+
+        var err error
+        output := captureOutput(t, func() { err = actionListCmd.RunE(actionListCmd, []string{}) })
+        server.Close()
+
+        if err != nil {
+            t.Errorf("action list --kind python --skip %d --limit %d failed: %s", test.skip, test.limit, err)
+            continue
+        }
+        if output = regexp.MustCompile(` +`).ReplaceAllString(output, " "); output != test.want {
+            t.Errorf("action list --kind python --skip %d --limit %d printed %q, want %q", test.skip, test.limit,
+                output, test.want)
+        }
+    }
+}
+
+func TestActionListFilterKindAlias(t *testing.T) {
+    flag := actionListCmd.Flags().Lookup("filter-kind")
+    if flag == nil || !flag.Hidden || len(flag.Deprecated) == 0 {
+        t.Errorf("action list has no hidden, deprecated --filter-kind flag")
+    }
+
+    server := newTestServer(t, actionListHandler(getTestKindActions(), -1))
+    defer server.Close()
+    flags.action.filterKind = "swift"
+    flags.common.limit = 30
+
+    var err error
+    output := captureOutput(t, func() { err = actionListCmd.RunE(actionListCmd, []string{}) })
+    if err != nil {
+        t.Fatalf("action list --filter-kind failed: %s", err)
+    }
+    if strings.Count(output, "/ns/") != 1 || !strings.Contains(output, "/ns/swift-3 ") {
+        t.Errorf("action list --filter-kind swift printed:\n%s\nwant the swift action only", output)
+    }
+
+    // The kinds are also sent to the server, which may filter on them
+    for _, request := range server.requests {
+        if kind := request.Query.Get("kind"); kind != "swift" {
+            t.Errorf("action list --filter-kind swift sent the kind %q", kind)
+        }
+    }
+}
+
+func TestGetActionListKinds(t *testing.T) {
+    tests := []struct {
+        kind        string
+        filterKind  string
+        want        string
+    }{
+        {"", "", ""},
+        {"python", "", "python"},
+        {"", "nodejs:6,sequence", "nodejs:6,sequence"},
+        {"python", "sequence", "python,sequence"},
+    }
+
+    for _, test := range tests {
+        flags.action.kind, flags.action.filterKind = test.kind, test.filterKind
+        if kinds := getActionListKinds(); kinds != test.want {
+            t.Errorf("kinds of --kind %q --filter-kind %q = %q, want %q", test.kind, test.filterKind, kinds, test.want)
+        }
+    }
+    flags.action.kind, flags.action.filterKind = "", ""
+}
+
+func TestActionListName(t *testing.T) {
+    tests := []struct {
+        name    string
+        want    int
+    }{
+        {"a1", 11},     // a1 and a10 to a19
+        {"a", 20},
+        {"b", 0},
+        {"", 20},
+    }
+
+    for _, test := range tests {
+        server := newTestServer(t, actionListHandler(getTestActions(20), -1))
+        flags.action.name = test.name
+
+        var err error
+        output := captureOutput(t, func() { err = actionListCmd.RunE(actionListCmd, []string{}) })
+        server.Close()
+
+        if err != nil {
+            t.Errorf("action list --name %q failed: %s", test.name, err)
+            continue
+        }
+        if count := strings.Count(output, "/ns/" + test.name); count != test.want || strings.Count(output, "/ns/") != test.want {
+            t.Errorf("action list --name %q listed %d actions, want %d:\n%s", test.name, count, test.want, output)
+        }
+
+        // The name is sent to servers that filter on it, and left out when empty
+        for _, request := range server.requests {
+            if name, found := request.Query["name"]; found != (len(test.name) > 0) || found && name[0] != test.name {
+                t.Errorf("action list --name %q sent the query %s", test.name, request.Query.Encode())
+            }
+        }
+    }
+}
+
+// activationHandler answers invocations with an activation that has the given status and result, and the HTTP status
+// the server uses for it
+func activationHandler(httpStatus int, status string, result map[string]interface{}) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        writeJSON(w, httpStatus, map[string]interface{}{
+            "namespace": "ns",
+            "name": "a",
+            "activationId": "12345",
+            "response": map[string]interface{}{
+                "status": status,
+                "success": status == "success",
+                "result": result,
+            },
+        })
+    }
+}
+
+func TestActionInvokeApplicationErrors(t *testing.T) {
+    tests := []struct {
+        httpStatus  int
+        status      string
+        result      bool
+        want        string
+    }{
+        {http.StatusBadGateway, "application error", false, "The following application error was received"},
+        {http.StatusBadGateway, "action developer error", false, "The action failed with a developer error"},
+        {http.StatusBadGateway, "whisk internal error", false, "The action failed with a whisk internal error"},
+        {http.StatusOK, "application error", true, "The following application error was received"},
+        {http.StatusOK, "action developer error", true, "The action failed with a developer error"},
+        {http.StatusOK, "whisk internal error", true, "The action failed with a whisk internal error"},
+    }
+
+    for _, test := range tests {
+        server := newTestServer(t, activationHandler(test.httpStatus, test.status, map[string]interface{}{"error": "boom"}))
+        flags.common.blocking = true
+        flags.action.result = test.result
+
+        var err error
+        captureOutput(t, func() { err = actionInvokeCmd.RunE(actionInvokeCmd, []string{"a"}) })
+        server.Close()
+
+        if err == nil {
+            t.Errorf("invoke (HTTP %d, %s, --result %t) succeeded", test.httpStatus, test.status, test.result)
+        } else if !isApplicationError(err) || !strings.HasPrefix(err.Error(), test.want) {
+            t.Errorf("invoke (HTTP %d, %s, --result %t) error = %q (application error %t), want %q",
+                test.httpStatus, test.status, test.result, err, isApplicationError(err), test.want)
+        }
+    }
+}
+
+func TestGetKindExtension(t *testing.T) {
+    tests := []struct {
+        kind        string
+        isBinary    bool
+        want        string
+    }{
+        {"nodejs", false, ".js"},
+        {"nodejs:6", false, ".js"},
+        {"nodejs:6", true, ".zip"},
+        {"python:3", false, ".py"},
+        {"python", true, ".zip"},
+        {"swift:3", false, ".swift"},
+        {"java", true, ".jar"},
+        {"java", false, ".jar"},
+        {"blackbox", false, ".zip"},
+        {"php:7.1", false, ""},
+        {"", false, ""},
+    }
+
+    for _, test := range tests {
+        if ext := getKindExtension(test.kind, test.isBinary); ext != test.want {
+            t.Errorf("getKindExtension(%q, %t) = %q, want %q", test.kind, test.isBinary, ext, test.want)
+        }
+    }
+}
+
+func TestDecodeBinaryCode(t *testing.T) {
+    zip := "PK\x03\x04 zip content"
+    encoded := base64.StdEncoding.EncodeToString([]byte(zip))
+
+    if decoded, isBinary := decodeBinaryCode(encoded); !isBinary || string(decoded) != zip {
+        t.Errorf("decodeBinaryCode(%q) = %q, %t, want the zip file", encoded, decoded, isBinary)
+    }
+
+    // Text that happens to be valid base64 is not an archive
+    for _, code := range []string{"function main() {}", "abcd", base64.StdEncoding.EncodeToString([]byte("text"))} {
+        if _, isBinary := decodeBinaryCode(code); isBinary {
+            t.Errorf("decodeBinaryCode(%q) reports binary code", code)
+        }
+    }
+}
+
+// inTempDir runs f, which is given the path of the directory, in a new temporary directory that is removed afterwards
+func inTempDir(t *testing.T, f func(dir string)) {
+    dir, err := ioutil.TempDir("", "wsk")
+    if err != nil {
+        t.Fatalf("ioutil.TempDir() failed: %s", err)
+    }
+    defer os.RemoveAll(dir)
+
+    origDir, err := os.Getwd()
+    if err != nil {
+        t.Fatalf("os.Getwd() failed: %s", err)
+    }
+    if err = os.Chdir(dir); err != nil {
+        t.Fatalf("os.Chdir(%s) failed: %s", dir, err)
+    }
+    defer os.Chdir(origDir)
+
+    f(dir)
+}
+
+func TestSaveCode(t *testing.T) {
+    code := "function main() {}"
+    zip := "PK\x03\x04 zip content"
+    encoded := base64.StdEncoding.EncodeToString([]byte(zip))
+
+    inTempDir(t, func(dir string) {
+        tests := []struct {
+            action      *whisk.Action
+            filename    string
+            wantFile    string
+            want        string
+        }{
+            {&whisk.Action{Name: "hello", Exec: &whisk.Exec{Kind: "nodejs:6", Code: &code}}, "", "hello.js", code},
+            {&whisk.Action{Name: "pkg/hello", Exec: &whisk.Exec{Kind: "python:3", Code: &code}}, "", "hello.py", code},
+            {&whisk.Action{Name: "zipped", Exec: &whisk.Exec{Kind: "nodejs:6", Code: &encoded}}, "", "zipped.zip", zip},
+            {&whisk.Action{Name: "hello", Exec: &whisk.Exec{Kind: "nodejs:6", Code: &code}}, "other.txt", "other.txt", code},
+        }
+
+        for _, test := range tests {
+            var err error
+            captureOutput(t, func() { err = saveCode(test.action, test.filename) })
+            if err != nil {
+                t.Errorf("saveCode(%s, %q) failed: %s", test.action.Name, test.filename, err)
+                continue
+            }
+            if data, err := ioutil.ReadFile(test.wantFile); err != nil || string(data) != test.want {
+                t.Errorf("saveCode(%s, %q) wrote %q to %s (%v), want %q", test.action.Name, test.filename, data,
+                    test.wantFile, err, test.want)
+            }
+        }
+
+        // Existing files are never overwritten
+        other := "other code"
+        err := saveCode(&whisk.Action{Name: "hello", Exec: &whisk.Exec{Kind: "nodejs:6", Code: &other}}, "")
+        if err == nil {
+            t.Errorf("saveCode() replaced the existing hello.js")
+        }
+        if data, _ := ioutil.ReadFile("hello.js"); string(data) != code {
+            t.Errorf("saveCode() changed the existing hello.js to %q", data)
+        }
+
+        // Sequences and actions without code have nothing to save
+        for _, action := range []*whisk.Action{
+            {Name: "seq", Exec: &whisk.Exec{Kind: "sequence", Components: []string{"/ns/a"}}},
+            {Name: "docker", Exec: &whisk.Exec{Kind: "blackbox", Image: "me/image"}},
+        } {
+            if err = saveCode(action, ""); err == nil {
+                t.Errorf("saveCode(%s) succeeded for an action without code", action.Name)
+            }
+        }
+        if files, _ := ioutil.ReadDir(dir); len(files) != 4 {
+            t.Errorf("saveCode() left %d files, want 4", len(files))
+        }
+    })
+}
+
+// withStdin runs f with standard input reading input from a pipe
+func withStdin(t *testing.T, input string, f func()) {
+    reader, writer, err := os.Pipe()
+    if err != nil {
+        t.Fatalf("os.Pipe() failed: %s", err)
+    }
+    defer reader.Close()
+
+    go func() {
+        writer.Write([]byte(input))
+        writer.Close()
+    }()
+
+    origStdin := os.Stdin
+    os.Stdin = reader
+    defer func() { os.Stdin = origStdin }()
+
+    f()
+}
+
+func TestActionInvokeParamFileFromStdin(t *testing.T) {
+    server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+        writeJSON(w, http.StatusAccepted, map[string]interface{}{"activationId": "12345"})
+    })
+    defer server.Close()
+
+    var err error
+    withStdin(t, `{"name": "stdin", "count": 2, "nested": {"a": [1, 2]}}`, func() {
+        _, flags.common.param, _, err = parseArgs([]string{"wsk", "action", "invoke", "a", "-P", "-", "-p", "count", "3"})
+    })
+    if err != nil {
+        t.Fatalf("parseArgs(-P -) failed: %s", err)
+    }
+
+    captureOutput(t, func() { err = actionInvokeCmd.RunE(actionInvokeCmd, []string{"/ns/a"}) })
+    if err != nil {
+        t.Fatalf("action invoke -P - failed: %s", err)
+    }
+
+    request := server.getRequest("POST", "ns/actions/a")
+    if request == nil {
+        t.Fatalf("no invocation was received; requests: %q", server.getRequests())
+    }
+
+    var body, want interface{}
+    json.Unmarshal([]byte(request.Body), &body)
+    json.Unmarshal([]byte(`{"name": "stdin", "count": 3, "nested": {"a": [1, 2]}}`), &want)
+    if !reflect.DeepEqual(body, want) {
+        t.Errorf("invocation body = %s, want the parameters from standard input with count 3", request.Body)
+    }
+}
+
+func TestActionInvokeNamedParam(t *testing.T) {
+    server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+        writeJSON(w, http.StatusAccepted, map[string]interface{}{"activationId": "12345"})
+    })
+    defer server.Close()
+
+    var err error
+    _, flags.common.param, _, err = parseArgs([]string{"wsk", "action", "invoke", "a", "--named-param", "n=5",
+        "-p", "s", "x", "--named-param", `obj={"a":true}`})
+    if err != nil {
+        t.Fatalf("parseArgs(--named-param) failed: %s", err)
+    }
+
+    captureOutput(t, func() { err = actionInvokeCmd.RunE(actionInvokeCmd, []string{"/ns/a"}) })
+    if err != nil {
+        t.Fatalf("action invoke --named-param failed: %s", err)
+    }
+
+    request := server.getRequest("POST", "ns/actions/a")
+    if request == nil {
+        t.Fatalf("no invocation was received; requests: %q", server.getRequests())
+    }
+
+    var body, want interface{}
+    json.Unmarshal([]byte(request.Body), &body)
+    json.Unmarshal([]byte(`{"n": 5, "s": "x", "obj": {"a": true}}`), &want)
+    if !reflect.DeepEqual(body, want) {
+        t.Errorf("invocation body = %s, want the named and KEY VALUE parameters", request.Body)
+    }
+}
+
+func TestActionInvokeParamFileFromStdinErrors(t *testing.T) {
+    for input, want := range map[string]string{
+        "": "is not valid JSON",
+        "not json": "is not valid JSON",
+        `["a"]`: "must be a JSON object",
+    } {
+        var err error
+        withStdin(t, input, func() {
+            _, _, _, err = parseArgs([]string{"wsk", "action", "invoke", "a", "--param-file", "-"})
+        })
+        if err == nil || !strings.Contains(err.Error(), want) {
+            t.Errorf("parseArgs(-P -) with input %q error = %v, want an error containing %q", input, err, want)
+        }
+    }
+}
+
+// sequenceComponentHandler answers action gets for the components of a test sequence; /ns/missing does not exist
+func sequenceComponentHandler(w http.ResponseWriter, r *http.Request) {
+    actions := map[string]whisk.Action{
+        "ns/actions/first": {Namespace: "ns", Name: "first", Exec: &whisk.Exec{Kind: "nodejs:6"},
+            Annotations: whisk.KeyValueArr{{Key: "description", Value: "the first step"}}},
+        "other/actions/pkg/nested": {Namespace: "other/pkg", Name: "nested",
+            Exec: &whisk.Exec{Kind: "sequence", Components: []string{"/ns/deep"}},
+            Annotations: whisk.KeyValueArr{{Key: "description", Value: "a nested sequence"}}},
+        "ns/actions/plain": {Namespace: "ns", Name: "plain", Exec: &whisk.Exec{Kind: "python:3"}},
+    }
+
+    path := strings.TrimPrefix(r.URL.Path, "/api/v1/namespaces/")
+    if action, found := actions[path]; found {
+        writeJSON(w, http.StatusOK, action)
+    } else {
+        writeJSON(w, http.StatusNotFound, map[string]interface{}{"error": "The requested resource does not exist.", "code": 1})
+    }
+}
+
+func TestPrintActionSummarySequence(t *testing.T) {
+    sequence := &whisk.Action{
+        Namespace: "ns",
+        Name: "seq",
+        Exec: &whisk.Exec{
+            Kind: "sequence",
+            Components: []string{"/ns/first", "/other/pkg/nested", "/ns/plain", "/ns/missing"},
+        },
+    }
+
+    tests := []struct {
+        full        bool
+        requests    []string
+        want        string
+    }{
+        {false, nil, `action /ns/seq
+   (components:)
+     1. /ns/first
+     2. /other/pkg/nested
+     3. /ns/plain
+     4. /ns/missing
+`},
+        // The nested sequence is described, but its own components are not fetched
+        {true, []string{"GET ns/actions/first", "GET other/actions/pkg/nested", "GET ns/actions/plain",
+            "GET ns/actions/missing"}, `action /ns/seq
+   (components:)
+     1. /ns/first: the first step
+     2. /other/pkg/nested: (sequence) a nested sequence
+     3. /ns/plain
+     4. /ns/missing: unavailable
+`},
+    }
+
+    for _, test := range tests {
+        server := newTestServer(t, sequenceComponentHandler)
+        flags.common.full = test.full
+
+        output := captureOutput(t, func() { printActionSummary(sequence) })
+        checkRequests(t, server, test.requests...)
+        namespace := client.Namespace
+        server.Close()
+
+        if output != test.want {
+            t.Errorf("printActionSummary() with --full %t printed:\n%s\nwant:\n%s", test.full, output, test.want)
+        }
+        if namespace != "ns" {
+            t.Errorf("printActionSummary() with --full %t left the client namespace %q", test.full, namespace)
+        }
+    }
+}
+
+// getExportTestAction returns the action served to the export tests, which has binary code
+func getExportTestAction() whisk.Action {
+    code := base64.StdEncoding.EncodeToString([]byte("PK\x03\x04 zip content"))
+    return whisk.Action{
+        Namespace: "ns",
+        Name: "hello",
+        Version: "0.0.3",
+        Exec: &whisk.Exec{Kind: "nodejs:6", Code: &code},
+        Parameters: whisk.KeyValueArr{{Key: "name", Value: "Bob"}},
+        Annotations: whisk.KeyValueArr{{Key: "description", Value: "says hello"}},
+    }
+}
+
+// checkExportedAction fails the test unless data is the JSON of the export test action without its namespace and
+// version
+func checkExportedAction(t *testing.T, data []byte) {
+    var exported whisk.Action
+    if err := json.Unmarshal(data, &exported); err != nil {
+        t.Errorf("the exported action is not valid JSON: %s\n%s", err, data)
+        return
+    }
+
+    want := getExportTestAction()
+    want.Namespace, want.Version = "", ""
+    if !reflect.DeepEqual(exported, want) {
+        t.Errorf("exported action = %#v, want %#v", exported, want)
+    }
+}
+
+func runActionExport(t *testing.T, args ...string) (string, error) {
+    server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+        writeJSON(w, http.StatusOK, getExportTestAction())
+    })
+    defer server.Close()
+
+    if err := actionExportCmd.ParseFlags(args); err != nil {
+        t.Fatalf("actionExportCmd.ParseFlags(%q) failed: %s", args, err)
+    }
+
+    var err error
+    output := captureOutput(t, func() { err = actionExportCmd.RunE(actionExportCmd, actionExportCmd.Flags().Args()) })
+    checkRequests(t, server, "GET ns/actions/hello")
+
+    return output, err
+}
+
+func TestActionCopy(t *testing.T) {
+    code := "function main() {}"
+    source := whisk.Action{Namespace: "ns", Name: "a", Version: "0.0.3", Exec: &whisk.Exec{Kind: "nodejs:6", Code: &code},
+        Parameters: whisk.KeyValueArr{{Key: "p", Value: "v"}}}
+
+    tests := []struct {
+        args        []string
+        overwrite   bool
+        getStatus   int
+        putStatus   int
+        requests    []string
+        output      string
+        exitCode    int     // 0 when the copy succeeds
+    }{
+        {[]string{"/ns/a", "/other/b"}, false, http.StatusOK, http.StatusOK,
+            []string{"GET ns/actions/a", "PUT other/actions/b"}, "ok: copied action a to b\n", 0},
+        {[]string{"/ns/a", "/ns/b"}, true, http.StatusOK, http.StatusOK,
+            []string{"GET ns/actions/a", "PUT ns/actions/b"}, "ok: copied action a to b\n", 0},
+        {[]string{"/ns/a", "/other/b"}, false, http.StatusNotFound, http.StatusOK, []string{"GET ns/actions/a"}, "",
+            whisk.EXITCODE_ERR_NOT_FOUND},
+        // The target is only replaced with --overwrite
+        {[]string{"/ns/a", "/other/b"}, false, http.StatusOK, http.StatusConflict,
+            []string{"GET ns/actions/a", "PUT other/actions/b"}, "", http.StatusConflict - 256},
+    }
+
+    for _, test := range tests {
+        server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+            if r.Method == "GET" && test.getStatus == http.StatusOK {
+                writeJSON(w, http.StatusOK, source)
+            } else if r.Method == "GET" {
+                writeJSON(w, test.getStatus, map[string]interface{}{"error": "The requested resource does not exist.", "code": 1})
+            } else if test.putStatus == http.StatusOK {
+                io.Copy(w, r.Body)
+            } else {
+                writeJSON(w, test.putStatus, map[string]interface{}{"error": "resource already exists", "code": 1})
+            }
+        })
+        flags.action.overwrite = test.overwrite
+
+        var err error
+        output := captureOutput(t, func() { err = actionCopyCmd.RunE(actionCopyCmd, test.args) })
+        checkRequests(t, server, test.requests...)
+        server.Close()
+
+        description := fmt.Sprintf("action copy %q --overwrite=%t", test.args, test.overwrite)
+        if test.exitCode == 0 && err != nil {
+            t.Errorf("%s failed: %s", description, err)
+        } else if whiskErr, ok := err.(*whisk.WskError); test.exitCode != 0 && (!ok || whiskErr.ExitCode != test.exitCode) {
+            t.Errorf("%s error = %#v, want exit code %d", description, err, test.exitCode)
+        }
+        if output != test.output {
+            t.Errorf("%s printed %q, want %q", description, output, test.output)
+        }
+
+        if len(test.requests) < 2 {
+            continue
+        }
+        request := server.requests[1]
+        if overwrite := request.Query.Get("overwrite"); overwrite != strconv.FormatBool(test.overwrite) {
+            t.Errorf("%s sent overwrite=%s", description, overwrite)
+        }
+
+        // The copy is the source action under the target name, without the namespace and version of the source
+        var copied whisk.Action
+        if err = json.Unmarshal([]byte(request.Body), &copied); err != nil {
+            t.Fatalf("%s sent an action that is not valid JSON: %s\n%s", description, err, request.Body)
+        }
+        want := source
+        want.Namespace, want.Version, want.Name = "", "", "b"
+        if !reflect.DeepEqual(copied, want) {
+            t.Errorf("%s sent the action %#v, want %#v", description, copied, want)
+        }
+    }
+}
+
+func TestActionExportToStdout(t *testing.T) {
+    output, err := runActionExport(t, "/ns/hello")
+    if err != nil {
+        t.Fatalf("action export failed: %s", err)
+    }
+
+    checkExportedAction(t, []byte(output))
+    if strings.Contains(output, `"namespace"`) || strings.Contains(output, `"version"`) {
+        t.Errorf("action export printed the namespace or version:\n%s", output)
+    }
+}
+
+func TestActionExportToFile(t *testing.T) {
+    inTempDir(t, func(dir string) {
+        for _, args := range [][]string{{"/ns/hello", "--file", "flag.json"}, {"/ns/hello", "arg.json"}} {
+            file := args[len(args) - 1]
+            output, err := runActionExport(t, args...)
+            flags.action.exportFile = ""
+
+            if err != nil {
+                t.Errorf("action export %q failed: %s", args, err)
+                continue
+            }
+            if !strings.Contains(output, "exported action hello to " + file) {
+                t.Errorf("action export %q printed %q", args, output)
+            }
+
+            data, err := ioutil.ReadFile(file)
+            if err != nil {
+                t.Errorf("action export %q did not write %s: %s", args, file, err)
+                continue
+            }
+            checkExportedAction(t, data)
+        }
+    })
+}
+
+func TestActionExportOverwriteGuard(t *testing.T) {
+    inTempDir(t, func(dir string) {
+        if err := ioutil.WriteFile("hello.json", []byte("keep"), 0644); err != nil {
+            t.Fatalf("ioutil.WriteFile() failed: %s", err)
+        }
+
+        _, err := runActionExport(t, "/ns/hello", "hello.json")
+        if err == nil || !strings.Contains(err.Error(), "already exists") {
+            t.Errorf("action export to an existing file error = %v, want an error that it already exists", err)
+        }
+        if data, _ := ioutil.ReadFile("hello.json"); string(data) != "keep" {
+            t.Errorf("action export changed the existing file to %q", data)
+        }
+
+        _, err = runActionExport(t, "/ns/hello", "hello.json", "--force")
+        flags.action.force = false
+        if err != nil {
+            t.Fatalf("action export --force failed: %s", err)
+        }
+        data, _ := ioutil.ReadFile("hello.json")
+        checkExportedAction(t, data)
+    })
+}
+
+func TestActionExportImportRoundTrip(t *testing.T) {
+    timeout, memory, logsize := 3000, 512, 5
+    exported := getExportTestAction()
+    exported.Limits = &whisk.Limits{Timeout: &timeout, Memory: &memory, Logsize: &logsize}
+    exported.Parameters = append(exported.Parameters, whisk.KeyValue{Key: "count", Value: 3})
+    exported.Annotations = append(exported.Annotations, whisk.KeyValue{Key: "web-export", Value: true})
+
+    server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+        if r.Method == "PUT" {
+            io.Copy(w, r.Body)
+        } else {
+            writeJSON(w, http.StatusOK, exported)
+        }
+    })
+    defer server.Close()
+
+    inTempDir(t, func(dir string) {
+        var err error
+        captureOutput(t, func() { err = actionExportCmd.RunE(actionExportCmd, []string{"/ns/hello", "hello.json"}) })
+        if err != nil {
+            t.Fatalf("action export failed: %s", err)
+        }
+
+        flags.action.overwrite = true
+        captureOutput(t, func() { err = actionImportCmd.RunE(actionImportCmd, []string{"hello.json", "/other/copy"}) })
+        if err != nil {
+            t.Fatalf("action import failed: %s", err)
+        }
+    })
+
+    request := server.getRequest("PUT", "other/actions/copy")
+    if request == nil {
+        t.Fatalf("no action was inserted; requests: %q", server.getRequests())
+    }
+    if request.Query.Get("overwrite") != "true" {
+        t.Errorf("action import --overwrite sent the query %s", request.Query.Encode())
+    }
+
+    // The imported action is the exported one under its new name, and with the namespace and version of the server
+    var imported, want interface{}
+    exported.Namespace, exported.Version, exported.Name = "", "", "copy"
+    data, _ := json.Marshal(exported)
+    json.Unmarshal(data, &want)
+    if err := json.Unmarshal([]byte(request.Body), &imported); err != nil {
+        t.Fatalf("the inserted action is not valid JSON: %s\n%s", err, request.Body)
+    }
+    if !reflect.DeepEqual(imported, want) {
+        t.Errorf("inserted action:\n%s\nwant:\n%s", request.Body, data)
+    }
+}
+
+func TestActionImportMissingKind(t *testing.T) {
+    server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+        writeJSON(w, http.StatusOK, map[string]interface{}{})
+    })
+    defer server.Close()
+
+    for _, content := range []string{`{"name": "a"}`, `{"name": "a", "exec": {"code": "x"}}`, `{"name": "a", "exec": {"kind": ""}}`} {
+        file := writeTestFile(t, "action.json", content)
+        err := actionImportCmd.RunE(actionImportCmd, []string{file})
+        os.RemoveAll(filepath.Dir(file))
+
+        if whiskErr, ok := err.(*whisk.WskError); !ok || !strings.Contains(whiskErr.Error(), "exec.kind") {
+            t.Errorf("action import of %s error = %#v, want a WskError about exec.kind", content, err)
+        }
+    }
+
+    checkRequests(t, server)
+}
+
+func TestActionImportExportedFile(t *testing.T) {
+    server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+        writeJSON(w, http.StatusOK, map[string]interface{}{})
+    })
+    defer server.Close()
+
+    // The file of another deployment may hold the namespace and version of the exported action
+    exported := getExportTestAction()
+    exported.Namespace = "/elsewhere/pkg"
+    data, _ := json.Marshal(exported)
+    file := writeTestFile(t, "hello.json", string(data))
+    defer os.RemoveAll(filepath.Dir(file))
+
+    var err error
+    output := captureOutput(t, func() { err = actionImportCmd.RunE(actionImportCmd, []string{file}) })
+    if err != nil {
+        t.Fatalf("action import failed: %s", err)
+    }
+    if !strings.Contains(output, "hello") {
+        t.Errorf("action import printed %q, want the action name", output)
+    }
+
+    // The action keeps its name in the default namespace, and is not replaced without --overwrite
+    request := server.getRequest("PUT", "_/actions/hello")
+    if request == nil {
+        t.Fatalf("no action was inserted; requests: %q", server.getRequests())
+    }
+    if request.Query.Get("overwrite") != "false" {
+        t.Errorf("action import sent the query %s", request.Query.Encode())
+    }
+
+    var imported whisk.Action
+    if err = json.Unmarshal([]byte(request.Body), &imported); err != nil {
+        t.Fatalf("the inserted action is not valid JSON: %s\n%s", err, request.Body)
+    }
+    want := getExportTestAction()
+    want.Namespace, want.Version = "", ""
+    if !reflect.DeepEqual(imported, want) {
+        t.Errorf("inserted action = %#v, want %#v", imported, want)
+    }
+
+    // The binary code is sent as the base64 it was exported as
+    if code, _ := base64.StdEncoding.DecodeString(*imported.Exec.Code); string(code) != "PK\x03\x04 zip content" {
+        t.Errorf("inserted action code decodes to %q", code)
+    }
+}
+
+func TestActionImportUnsupportedKind(t *testing.T) {
+    server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+        writeJSON(w, http.StatusOK, map[string]interface{}{})
+    })
+    defer server.Close()
+
+    tests := []struct {
+        kind    string
+        warning string
+    }{
+        {"nodejs:6", ""},
+        {"cobol:1", "the kind 'cobol:1' of action a is not available on the target deployment"},
+    }
+
+    for _, test := range tests {
+        file := writeTestFile(t, "action.json", fmt.Sprintf(`{"name": "a", "exec": {"kind": "%s", "code": "x"}}`, test.kind))
+        server.requests = nil
+
+        // A kind that this client does not know is a warning, and the action is still sent for the server to decide
+        var err error
+        stderr := captureStderr(t, func() {
+            captureOutput(t, func() { err = actionImportCmd.RunE(actionImportCmd, []string{file}) })
+        })
+        os.RemoveAll(filepath.Dir(file))
+
+        if err != nil {
+            t.Errorf("action import of kind %s failed: %s", test.kind, err)
+        }
+        if len(test.warning) == 0 && len(stderr) > 0 {
+            t.Errorf("action import of kind %s warned %q", test.kind, stderr)
+        } else if !strings.Contains(stderr, test.warning) {
+            t.Errorf("action import of kind %s warned %q, want %q", test.kind, stderr, test.warning)
+        }
+        checkRequests(t, server, "PUT _/actions/a")
+    }
+}
+
+func TestActionImportUpdate(t *testing.T) {
+    server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+        writeJSON(w, http.StatusOK, map[string]interface{}{})
+    })
+    defer server.Close()
+
+    file := writeTestFile(t, "action.json", `{"name": "a", "exec": {"kind": "nodejs:6", "code": "x"}}`)
+    defer os.RemoveAll(filepath.Dir(file))
+
+    tests := []struct {
+        args      []string
+        overwrite string
+    }{
+        {[]string{}, "false"},
+        {[]string{"--overwrite"}, "true"},
+        {[]string{"--update"}, "true"},
+    }
+
+    for _, test := range tests {
+        flags = Flags{}
+        server.requests = nil
+        actionImportCmd.Flags().Set("overwrite", "false")
+        actionImportCmd.Flags().Set("update", "false")
+        if err := actionImportCmd.ParseFlags(test.args); err != nil {
+            t.Fatalf("action import %v: %s", test.args, err)
+        }
+
+        var err error
+        captureOutput(t, func() { err = actionImportCmd.RunE(actionImportCmd, []string{file}) })
+        if err != nil {
+            t.Errorf("action import %v failed: %s", test.args, err)
+        }
+        if request := server.getRequest("PUT", "_/actions/a"); request == nil {
+            t.Errorf("action import %v sent no insert; requests: %q", test.args, server.getRequests())
+        } else if request.Query.Get("overwrite") != test.overwrite {
+            t.Errorf("action import %v sent the query %s, want overwrite=%s", test.args, request.Query.Encode(), test.overwrite)
+        }
+    }
+}
+
+func TestGetLimits(t *testing.T) {
+    if limits := getLimits(false, false, false, 256, 10, 60000); limits != nil {
+        t.Errorf("getLimits() without any limit set = %#v, want nil", limits)
+    }
+
+    // Only the limits that are set are sent, so an update keeps the others
+    limits := getLimits(false, true, true, 256, 0, 60000)
+    if limits == nil || limits.Memory != nil || limits.Logsize == nil || *limits.Logsize != 0 ||
+            limits.Timeout == nil || *limits.Timeout != 60000 {
+        t.Errorf("getLimits() with the log size and timeout set = %#v", limits)
+    }
+}
+
+func TestValidateLimits(t *testing.T) {
+    tests := []struct {
+        timeout, memory, logsize    int
+        set                         string
+        want                        string
+    }{
+        {100, 128, 0, "tml", ""},
+        {300000, 512, 10, "tml", ""},
+        {0, 0, 0, "", ""},
+        {0, 0, 5, "l", ""},
+        {99, 256, 5, "t", "Invalid timeout limit 99; the timeout must be between 100 and 300000 milliseconds."},
+        {300001, 256, 5, "tml", "Invalid timeout limit 300001; the timeout must be between 100 and 300000 milliseconds."},
+        {1000, 127, 5, "m", "Invalid memory limit 127; the memory must be between 128 and 512 MB."},
+        {1000, 513, 5, "tml", "Invalid memory limit 513; the memory must be between 128 and 512 MB."},
+        {1000, 256, -1, "l", "Invalid log size limit -1; the log size must be between 0 and 10 MB."},
+        {1000, 256, 11, "tml", "Invalid log size limit 11; the log size must be between 0 and 10 MB."},
+    }
+
+    for _, test := range tests {
+        limits := getLimits(strings.Contains(test.set, "m"), strings.Contains(test.set, "l"),
+            strings.Contains(test.set, "t"), test.memory, test.logsize, test.timeout)
+        err := validateLimits(limits)
+
+        if len(test.want) == 0 && err != nil {
+            t.Errorf("validateLimits(%#v) failed: %s", limits, err)
+        } else if len(test.want) > 0 {
+            if whiskErr, ok := err.(*whisk.WskError); !ok || whiskErr.Error() != test.want {
+                t.Errorf("validateLimits(timeout %d, memory %d, log size %d) error = %v, want %q", test.timeout,
+                    test.memory, test.logsize, err, test.want)
+            }
+        }
+    }
+}
+
+func TestActionUpdateIfMatch(t *testing.T) {
+    tests := []struct {
+        ifMatch     string  // the --if-match flag
+        getStatus   int     // the status of the GET of the existing action, whose ETag is "v2"
+        requests    []string
+        sent        string  // the If-Match header of the update
+        exitCode    int     // 0 when the update succeeds
+    }{
+        // The ETag of the existing action is sent without --if-match
+        {"", http.StatusOK, []string{"GET ns/actions/a", "PUT ns/actions/a"}, `"v2"`, 0},
+        {`"v2"`, http.StatusOK, []string{"GET ns/actions/a", "PUT ns/actions/a"}, `"v2"`, 0},
+        // The ETag of an earlier read no longer matches, so the update is refused
+        {`"v1"`, http.StatusOK, []string{"GET ns/actions/a", "PUT ns/actions/a"}, `"v1"`,
+            http.StatusPreconditionFailed - 256},
+        // A new action is created without a precondition
+        {"", http.StatusNotFound, []string{"GET ns/actions/a", "PUT ns/actions/a"}, "", 0},
+        // Without the ETag, the update is not sent
+        {"", http.StatusInternalServerError, []string{"GET ns/actions/a"}, "", http.StatusInternalServerError - 256},
+    }
+
+    for _, test := range tests {
+        var sent string
+        server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+            if r.Method == "GET" {
+                w.Header().Set("ETag", `"v2"`)
+                writeJSON(w, test.getStatus, map[string]interface{}{"namespace": "ns", "name": "a", "error": "get failed", "code": 1})
+                return
+            }
+            sent = r.Header.Get("If-Match")
+            if len(sent) > 0 && sent != `"v2"` {
+                writeJSON(w, http.StatusPreconditionFailed, map[string]interface{}{"error": "The resource has been modified.", "code": 1})
+                return
+            }
+            writeJSON(w, http.StatusOK, map[string]interface{}{"namespace": "ns", "name": "a"})
+        })
+        flags.action.ifMatch = test.ifMatch
+
+        var err error
+        captureOutput(t, func() { err = actionUpdateCmd.RunE(actionUpdateCmd, []string{"/ns/a"}) })
+        checkRequests(t, server, test.requests...)
+        server.Close()
+
+        description := fmt.Sprintf("action update --if-match %q with a %d GET", test.ifMatch, test.getStatus)
+        if test.exitCode == 0 && err != nil {
+            t.Errorf("%s failed: %s", description, err)
+        } else if whiskErr, ok := err.(*whisk.WskError); test.exitCode != 0 && (!ok || whiskErr.ExitCode != test.exitCode) {
+            t.Errorf("%s error = %#v, want exit code %d", description, err, test.exitCode)
+        }
+        if test.exitCode == http.StatusPreconditionFailed - 256 && !strings.Contains(err.Error(), "the action was changed by another client") {
+            t.Errorf("%s error = %q, want an error that the action changed", description, err)
+        }
+        if sent != test.sent {
+            t.Errorf("%s sent the If-Match header %q, want %q", description, sent, test.sent)
+        }
+    }
+}
+
+func TestParseActionDocker(t *testing.T) {
+    server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+        writeJSON(w, http.StatusOK, map[string]interface{}{})
+    })
+    defer server.Close()
+
+    inTempDir(t, func(dir string) {
+        ioutil.WriteFile("exec", []byte("#!/bin/bash\necho hello"), 0644)
+        ioutil.WriteFile("action.zip", []byte("PK\x03\x04 zip content"), 0644)
+        ioutil.WriteFile("action.jar", []byte("PK\x03\x04 jar content"), 0644)
+
+        tests := []struct {
+            flags   ActionFlags
+            args    []string
+            want    string      // the exec as JSON, or the error
+        }{
+            {ActionFlags{docker: "me/image"}, []string{"a"}, `{"kind":"blackbox","image":"me/image"}`},
+            {ActionFlags{docker: "me/image"}, []string{"a", "exec"},
+                `{"kind":"blackbox","code":"#!/bin/bash\necho hello","image":"me/image"}`},
+            {ActionFlags{docker: "me/image"}, []string{"a", "action.zip"},
+                `{"kind":"blackbox","code":"UEsDBCB6aXAgY29udGVudA==","image":"me/image"}`},
+            {ActionFlags{native: true}, []string{"a", "exec"},
+                `{"kind":"blackbox","code":"#!/bin/bash\necho hello","image":"openwhisk/dockerskeleton"}`},
+            {ActionFlags{docker: "me/image", sequence: true}, []string{"a", "/ns/b"},
+                "The --docker flag cannot be combined with --sequence"},
+            {ActionFlags{docker: "me/image", copy: true}, []string{"a", "/ns/b"},
+                "The --docker flag cannot be combined with --copy"},
+            {ActionFlags{docker: "me/image", kind: "nodejs:6"}, []string{"a", "exec"},
+                "The --docker flag cannot be combined with --kind"},
+            {ActionFlags{docker: "me/image", native: true}, []string{"a"},
+                "The --docker flag cannot be combined with --native"},
+            {ActionFlags{docker: "me/image"}, []string{"a", "action.jar"}, "A .jar file cannot be used with a docker image"},
+        }
+
+        for _, test := range tests {
+            flags.action = test.flags
+            action, err := parseAction(actionCreateCmd, test.args, false)
+
+            var result string
+            if err != nil {
+                result = err.Error()
+            } else {
+                data, _ := json.Marshal(action.Exec)
+                result = string(data)
+            }
+
+            if result != test.want {
+                t.Errorf("parseAction(%q) with the flags %+v = %s, want %s", test.args, test.flags, result, test.want)
+            }
+        }
+    })
+}
+
+func TestActionListShowVersion(t *testing.T) {
+    origColumns := os.Getenv("COLUMNS")
+    os.Unsetenv("COLUMNS")
+    defer os.Setenv("COLUMNS", origColumns)
+
+    actions := []whisk.Action{
+        {Namespace: "ns", Name: "b", Version: "0.0.12", Annotations: whisk.KeyValueArr{{Key: "exec", Value: "nodejs:6"}}},
+        {Namespace: "ns", Name: "a", Version: "1.0.0", Annotations: whisk.KeyValueArr{{Key: "exec", Value: "python:3"}}},
+    }
+
+    tests := []struct {
+        showVersion bool
+        want        string
+    }{
+        {false, "actions\n" +
+            fmt.Sprintf("%-70s %s %s\n", "/ns/b", "private", "nodejs:6") +
+            fmt.Sprintf("%-70s %s %s\n", "/ns/a", "private", "python:3")},
+        // The version comes after the name, and the rows stay in the order of the server
+        {true, "actions\n" +
+            fmt.Sprintf("%-70s %-10s %s %s\n", "/ns/b", "0.0.12", "private", "nodejs:6") +
+            fmt.Sprintf("%-70s %-10s %s %s\n", "/ns/a", "1.0.0", "private", "python:3")},
+    }
+
+    for _, test := range tests {
+        flags.action.showVersion = test.showVersion
+        if output := captureOutput(t, func() { printActionList(actions) }); output != test.want {
+            t.Errorf("action list --show-version=%t printed:\n%q\nwant:\n%q", test.showVersion, output, test.want)
+        }
+    }
+    flags.action.showVersion = false
+}
+
+// sequenceHandler serves the sequence ns/seq of the actions a, b and a, and the action ns/plain; puts are answered
+// with their body
+func sequenceHandler(w http.ResponseWriter, r *http.Request) {
+    if r.Method == "PUT" {
+        w.Header().Set("Content-Type", "application/json")
+        io.Copy(w, r.Body)
+        return
+    }
+
+    switch strings.TrimPrefix(r.URL.Path, "/api/v1/namespaces/") {
+    case "ns/actions/seq":
+        w.Header().Set("ETag", `"v1"`)
+        writeJSON(w, http.StatusOK, whisk.Action{Namespace: "ns", Name: "seq", Version: "0.0.1",
+            Exec: &whisk.Exec{Kind: "sequence", Components: []string{"/ns/a", "/ns/b", "/ns/a"}}})
+    case "ns/actions/plain":
+        writeJSON(w, http.StatusOK, whisk.Action{Namespace: "ns", Name: "plain", Exec: &whisk.Exec{Kind: "nodejs:6"}})
+    default:
+        writeJSON(w, http.StatusNotFound, map[string]interface{}{"error": "The requested resource does not exist.", "code": 1})
+    }
+}
+
+// runSequenceCmd runs a sequence command against sequenceHandler, and returns the components of the updated sequence
+func runSequenceCmd(t *testing.T, cmd *cobra.Command, position int, args ...string) ([]string, error) {
+    server := newTestServer(t, sequenceHandler)
+    defer server.Close()
+    flags.action.position = position
+
+    var err error
+    captureOutput(t, func() { err = cmd.RunE(cmd, args) })
+
+    request := server.getRequest("PUT", "ns/actions/seq")
+    if request == nil {
+        return nil, err
+    }
+    if ifMatch := request.Header.Get("If-Match"); ifMatch != `"v1"` {
+        t.Errorf("sequence %s %q sent the If-Match header %q, want the ETag of the sequence", cmd.Name(), args, ifMatch)
+    }
+
+    var action whisk.Action
+    if err := json.Unmarshal([]byte(request.Body), &action); err != nil || action.Exec == nil {
+        t.Fatalf("sequence %s %q sent the invalid action %s", cmd.Name(), args, request.Body)
+    }
+
+    return action.Exec.Components, err
+}
+
+func TestActionSequenceAdd(t *testing.T) {
+    tests := []struct {
+        position    int
+        want        string
+    }{
+        {0, "/ns/c /ns/a /ns/b /ns/a"},
+        {1, "/ns/a /ns/c /ns/b /ns/a"},
+        {3, "/ns/a /ns/b /ns/a /ns/c"},
+        {-1, "/ns/a /ns/b /ns/a /ns/c"},
+    }
+
+    for _, test := range tests {
+        components, err := runSequenceCmd(t, actionSequenceAddCmd, test.position, "/ns/seq", "/ns/c")
+        if err != nil {
+            t.Errorf("sequence add --position %d failed: %s", test.position, err)
+        } else if strings.Join(components, " ") != test.want {
+            t.Errorf("sequence add --position %d changed the components to %q, want %s", test.position, components,
+                test.want)
+        }
+    }
+
+    components, err := runSequenceCmd(t, actionSequenceAddCmd, 4, "/ns/seq", "/ns/c")
+    if err == nil || components != nil {
+        t.Errorf("sequence add --position 4 to a sequence of 3 components = %q, %v, want an error", components, err)
+    }
+}
+
+func TestActionSequenceRemove(t *testing.T) {
+    // Only the first occurrence of a component is removed
+    for component, want := range map[string]string{"/ns/a": "/ns/b /ns/a", "/ns/b": "/ns/a /ns/a"} {
+        components, err := runSequenceCmd(t, actionSequenceRemoveCmd, -1, "/ns/seq", component)
+        if err != nil {
+            t.Errorf("sequence remove %s failed: %s", component, err)
+        } else if strings.Join(components, " ") != want {
+            t.Errorf("sequence remove %s changed the components to %q, want %s", component, components, want)
+        }
+    }
+
+    components, err := runSequenceCmd(t, actionSequenceRemoveCmd, -1, "/ns/seq", "/ns/missing")
+    if err == nil || !strings.Contains(err.Error(), "Action '/ns/missing' is not a component of sequence 'seq'") ||
+            components != nil {
+        t.Errorf("sequence remove of a missing component = %q, %v, want an error", components, err)
+    }
+}
+
+func TestActionSequenceNotSequence(t *testing.T) {
+    for _, cmd := range []*cobra.Command{actionSequenceAddCmd, actionSequenceRemoveCmd} {
+        components, err := runSequenceCmd(t, cmd, -1, "/ns/plain", "/ns/a")
+        if err == nil || err.Error() != "Action 'plain' is not a sequence" || components != nil {
+            t.Errorf("sequence %s on an action that is not a sequence = %q, %v, want an error", cmd.Name(),
+                components, err)
+        }
+    }
+}
+
+func TestCsvToQualifiedActions(t *testing.T) {
+    origNamespace := Properties.Namespace
+    Properties.Namespace = "ns"
+    defer func() { Properties.Namespace = origNamespace }()
+
+    tests := []struct {
+        components  string
+        want        string      // the components separated by spaces, or the error
+    }{
+        {"justname", "/ns/justname"},
+        {"/other/pkg/act", "/other/pkg/act"},
+        {"pkg/act", "/ns/pkg/act"},
+        {"a, /other/b ,pkg/c", "/ns/a /other/b /ns/pkg/c"},
+        // Duplicates are kept in order
+        {"a,b,a", "/ns/a /ns/b /ns/a"},
+        {"a,,b", "Sequence components 'a,,b' contain an empty action name"},
+        {"a,b,", "Sequence components 'a,b,' contain an empty action name"},
+        {",a", "Sequence components ',a' contain an empty action name"},
+        {"a, ,b", "Sequence components 'a, ,b' contain an empty action name"},
+        {"", "Sequence components '' contain an empty action name"},
+    }
+
+    for _, test := range tests {
+        var result string
+        if components, err := csvToQualifiedActions(test.components); err != nil {
+            result = err.Error()
+        } else {
+            result = strings.Join(components, " ")
+        }
+
+        if result != test.want {
+            t.Errorf("csvToQualifiedActions(%q) = %s, want %s", test.components, result, test.want)
+        }
+    }
+}
+
+func TestParseActionSequence(t *testing.T) {
+    server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+        writeJSON(w, http.StatusOK, map[string]interface{}{})
+    })
+    defer server.Close()
+
+    origNamespace := Properties.Namespace
+    Properties.Namespace = "ns"
+    defer func() { Properties.Namespace = origNamespace }()
+
+    tests := []struct {
+        args    []string
+        want    string      // the components separated by spaces, or the error
+    }{
+        {[]string{"seq", "a,/other/pkg/act"}, "/ns/a /other/pkg/act"},
+        {[]string{"seq", "a,seq"}, "Sequence '/ns/seq' cannot include itself as a component"},
+        {[]string{"/ns/seq", "a,/ns/seq"}, "Sequence '/ns/seq' cannot include itself as a component"},
+        {[]string{"seq", "/other/seq"}, "/other/seq"},
+        {[]string{"seq", "a,,b"}, "Sequence components 'a,,b' contain an empty action name"},
+    }
+
+    for _, test := range tests {
+        flags.action = ActionFlags{sequence: true}
+
+        var result string
+        if action, err := parseAction(actionCreateCmd, test.args, false); err != nil {
+            result = err.Error()
+        } else if action.Exec.Kind != "sequence" {
+            result = "kind " + action.Exec.Kind
+        } else {
+            result = strings.Join(action.Exec.Components, " ")
+        }
+
+        if result != test.want {
+            t.Errorf("action create --sequence %q = %s, want %s", test.args, result, test.want)
+        }
+    }
+}
+
+func TestActionDeleteNotFound(t *testing.T) {
+    server := newTestServer(t, deleteErrorHandler(http.StatusNotFound, "The requested resource does not exist."))
+    defer server.Close()
+
+    err := actionDeleteCmd.RunE(actionDeleteCmd, []string{"/ns/a"})
+    checkRequests(t, server, "DELETE ns/actions/a")
+
+    want := "Unable to delete action 'a': not found"
+    if whiskErr, ok := err.(*whisk.WskError); !ok || whiskErr.Error() != want ||
+            whiskErr.ExitCode != whisk.EXITCODE_ERR_NOT_FOUND {
+        t.Errorf("action delete error = %#v, want %q with exit code %d", err, want, whisk.EXITCODE_ERR_NOT_FOUND)
+    }
+}
+
+// waitHandler answers an action invoke with an activation id, and the first notFound gets of the activation with a
+// 404 as the server does until the activation has completed. The times of the gets are recorded.
+func waitHandler(notFound int, gets *[]time.Time) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        if r.Method == "POST" {
+            writeJSON(w, http.StatusAccepted, map[string]interface{}{"activationId": "12345"})
+            return
+        }
+
+        *gets = append(*gets, time.Now())
+        if len(*gets) <= notFound {
+            writeJSON(w, http.StatusNotFound, map[string]interface{}{"error": "The requested resource does not exist.", "code": 1})
+            return
+        }
+
+        writeJSON(w, http.StatusOK, whisk.Activation{ActivationID: "12345",
+            Response: whisk.Response{Status: "success", Success: true, Result: &whisk.Result{"greeting": "hello"}}})
+    }
+}
+
+func TestActionInvokeWait(t *testing.T) {
+    var gets []time.Time
+    server := newTestServer(t, waitHandler(2, &gets))
+    defer server.Close()
+    flags.action.wait = 10
+    flags.action.result = true
+
+    var err error
+    output := captureOutput(t, func() { err = actionInvokeCmd.RunE(actionInvokeCmd, []string{"/ns/a"}) })
+    if err != nil {
+        t.Fatalf("action invoke --wait failed: %s", err)
+    }
+
+    checkRequests(t, server, "POST ns/actions/a", "GET _/activations/12345", "GET _/activations/12345",
+        "GET _/activations/12345")
+    // With --result only the result is printed, without the activation id
+    if want := "{\n    \"greeting\": \"hello\"\n}\n"; output != want {
+        t.Errorf("action invoke --wait --result printed %q, want %q", output, want)
+    }
+
+    // The delay between polls starts at half a second and doubles after each 404
+    for i, want := range []time.Duration{500 * time.Millisecond, time.Second} {
+        if delay := gets[i + 1].Sub(gets[i]); delay < want || delay > want + 400 * time.Millisecond {
+            t.Errorf("delay before poll %d = %s, want %s", i + 2, delay, want)
+        }
+    }
+}
+
+func TestActionInvokeWaitTimeout(t *testing.T) {
+    var gets []time.Time
+    server := newTestServer(t, waitHandler(100, &gets))
+    defer server.Close()
+    flags.action.wait = 1
+
+    var err error
+    start := time.Now()
+    captureOutput(t, func() { err = actionInvokeCmd.RunE(actionInvokeCmd, []string{"/ns/a"}) })
+
+    // A second poll would come after the deadline, so the wait ends after the first retry
+    want := "Activation '12345' did not complete in time"
+    if err == nil || err.Error() != want {
+        t.Errorf("action invoke --wait 1 error = %v, want %q", err, want)
+    }
+    checkRequests(t, server, "POST ns/actions/a", "GET _/activations/12345", "GET _/activations/12345")
+    if elapsed := time.Since(start); elapsed > time.Second {
+        t.Errorf("action invoke --wait 1 took %s, want less than the wait", elapsed)
+    }
+}
+
+// streamHandler answers the invocation of ns/a with activation 12345, whose logs grow by one line on each request
+// for them until they reach l3. Getting the activation fails with a 404 until its logs are complete.
+func streamHandler(success bool) http.HandlerFunc {
+    var mutex sync.Mutex
+    logs := []string{}
+
+    return func(w http.ResponseWriter, r *http.Request) {
+        mutex.Lock()
+        defer mutex.Unlock()
+
+        switch {
+        case r.Method == "POST":
+            writeJSON(w, http.StatusAccepted, map[string]interface{}{"activationId": "12345"})
+        case strings.HasSuffix(r.URL.Path, "/logs"):
+            if len(logs) < 3 {
+                logs = append(logs, fmt.Sprintf("l%d", len(logs) + 1))
+            }
+            writeJSON(w, http.StatusOK, map[string]interface{}{"logs": logs})
+        case len(logs) < 3:
+            writeJSON(w, http.StatusNotFound, map[string]interface{}{"error": "The requested resource does not exist.", "code": 1})
+        default:
+            result := &whisk.Result{"greeting": "hello"}
+            if !success {
+                result = &whisk.Result{"error": "failed"}
+            }
+            writeJSON(w, http.StatusOK, whisk.Activation{ActivationID: "12345", Logs: logs,
+                Response: whisk.Response{Status: "application error", Success: success, Result: result}})
+        }
+    }
+}
+
+func TestActionInvokeStreamResult(t *testing.T) {
+    server := newTestServer(t, streamHandler(true))
+    defer server.Close()
+    flags.action.streamResult = true
+    flags.action.result = true
+
+    var err error
+    output := captureOutput(t, func() { err = actionInvokeCmd.RunE(actionInvokeCmd, []string{"/ns/a"}) })
+    if err != nil {
+        t.Fatalf("action invoke --stream-result failed: %s", err)
+    }
+
+    // Each log line is printed once, in order, as it appears, and the result last; --result leaves out the activation id
+    want := "l1\n" +
+        "l2\n" +
+        "l3\n" +
+        "{\n    \"greeting\": \"hello\"\n}\n"
+    if output != want {
+        t.Errorf("action invoke --stream-result printed:\n%s\nwant:\n%s", output, want)
+    }
+
+    requests := server.getRequests()
+    if len(requests) == 0 || requests[0] != "POST ns/actions/a" {
+        t.Fatalf("action invoke --stream-result sent the requests %q, want a POST of ns/actions/a first", requests)
+    }
+    if invoke := server.getRequest("POST", "ns/actions/a"); invoke.Query.Get("blocking") == "true" {
+        t.Errorf("action invoke --stream-result made a blocking invocation, want it to return the activation id at once")
+    }
+}
+
+func TestActionInvokeStreamResultErrors(t *testing.T) {
+    server := newTestServer(t, streamHandler(false))
+    defer server.Close()
+    flags.action.streamResult = true
+    flags.action.result = true
+
+    var err error
+    output := captureOutput(t, func() { err = actionInvokeCmd.RunE(actionInvokeCmd, []string{"/ns/a"}) })
+    if err == nil {
+        t.Errorf("action invoke --stream-result of a failed activation succeeded, want an error")
+    }
+    if want := "l3\n{\n    \"error\": \"failed\"\n}\n"; !strings.HasSuffix(output, want) {
+        t.Errorf("action invoke --stream-result of a failed activation printed:\n%s\nwant it to end with:\n%s", output, want)
+    }
+
+    flags.action.webInvoke = true
+    err = actionInvokeCmd.RunE(actionInvokeCmd, []string{"/ns/a"})
+    if err == nil || !strings.Contains(err.Error(), "--web and --stream-result options cannot be used together") {
+        t.Errorf("action invoke --web --stream-result error = %v, want a conflict error", err)
+    }
+}
+
+func TestActionGetField(t *testing.T) {
+    code := "function main() {}"
+    timeout := 60000
+    action := whisk.Action{Namespace: "ns", Name: "a", Exec: &whisk.Exec{Kind: "nodejs:6", Code: &code},
+        Limits: &whisk.Limits{Timeout: &timeout}}
+
+    tests := []struct {
+        field   string
+        want    string
+    }{
+        {"exec.code", `"function main() {}"`},
+        {"exec.kind", `"nodejs:6"`},
+        {"limits.timeout", "60000"},
+        // Fields are matched ignoring case, and a path through a nil value is null
+        {"Exec.Kind", `"nodejs:6"`},
+        {"limits.memory", "null"},
+        {"name", `"a"`},
+    }
+
+    for _, test := range tests {
+        server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+            writeJSON(w, http.StatusOK, action)
+        })
+
+        var err error
+        output := captureOutput(t, func() { err = actionGetCmd.RunE(actionGetCmd, []string{"/ns/a", test.field}) })
+        server.Close()
+
+        want := "ok: got action a, displaying field " + test.field + "\n" + test.want + "\n"
+        if err != nil {
+            t.Errorf("action get a %s failed: %s", test.field, err)
+        } else if output != want {
+            t.Errorf("action get a %s printed %q, want %q", test.field, output, want)
+        }
+    }
+}
+
+func TestActionGetInvalidField(t *testing.T) {
+    tests := []struct {
+        field   string
+        want    string
+    }{
+        {"exec.nonexistent", "Invalid field filter 'exec.nonexistent'; there is no field 'nonexistent'."},
+        {"nonexistent", "Invalid field filter 'nonexistent'."},
+        {"exec.code.length", "Invalid field filter 'exec.code.length'; there is no field 'length'."},
+        {"limits.timeout.value", "Invalid field filter 'limits.timeout.value'; there is no field 'value'."},
+    }
+
+    for _, test := range tests {
+        server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+            writeJSON(w, http.StatusOK, whisk.Action{})
+        })
+
+        err := actionGetCmd.RunE(actionGetCmd, []string{"/ns/a", test.field})
+        // The field is checked before the action is fetched
+        checkRequests(t, server)
+        server.Close()
+
+        if whiskErr, ok := err.(*whisk.WskError); !ok || whiskErr.Error() != test.want {
+            t.Errorf("action get a %s error = %v, want %q", test.field, err, test.want)
+        }
+    }
+}
+
+// runActionInvokeData invokes the action a with the --data value data, when it is not nil, and the param-json
+// value, and returns the body of the invoke request and the error
+func runActionInvokeData(t *testing.T, data *string, paramJSON string) (string, error) {
+    server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+        writeJSON(w, http.StatusAccepted, map[string]interface{}{"activationId": "12345"})
+    })
+    defer server.Close()
+    flags.action.paramJSON = paramJSON
+
+    if data != nil {
+        if err := actionInvokeCmd.Flags().Set("data", *data); err != nil {
+            t.Fatalf("setting --data failed: %s", err)
+        }
+        defer func() {
+            actionInvokeCmd.Flags().Set("data", "")
+            actionInvokeCmd.Flags().Lookup("data").Changed = false
+        }()
+    }
+
+    var err error
+    captureOutput(t, func() { err = actionInvokeCmd.RunE(actionInvokeCmd, []string{"/ns/a"}) })
+
+    if request := server.getRequest("POST", "ns/actions/a"); request != nil {
+        return request.Body, err
+    }
+
+    return "", err
+}
+
+func TestActionInvokeDataBody(t *testing.T) {
+    tests := []struct {
+        data    string
+        want    string
+    }{
+        {`{"a": [1, 2]}`, `{"a":[1,2]}`},
+        {`[1, 2, 3]`, `[1,2,3]`},
+        {` "text" `, `"text"`},
+        // Numbers keep their exact form
+        {`1.50`, `1.50`},
+        {`12345678901234567890`, `12345678901234567890`},
+        {`true`, `true`},
+        {`[]`, `[]`},
+        {`[{"nested": ["x", null]}, "y"]`, `[{"nested":["x",null]},"y"]`},
+    }
+
+    for _, test := range tests {
+        body, err := runActionInvokeData(t, &test.data, "")
+        if err != nil {
+            t.Errorf("action invoke --data %q failed: %s", test.data, err)
+        } else if body != test.want + "\n" {
+            t.Errorf("action invoke --data %q sent %q, want %q", test.data, body, test.want + "\n")
+        }
+    }
+}
+
+func TestActionInvokeParamJSONBody(t *testing.T) {
+    body, err := runActionInvokeData(t, nil, `{"a": [1, 2], "b": {"c": "d"}}`)
+    if want := `{"a":[1,2],"b":{"c":"d"}}` + "\n"; err != nil || body != want {
+        t.Errorf("action invoke --param-json sent %q, error %v, want %q", body, err, want)
+    }
+}
+
+func TestActionInvokeDataErrors(t *testing.T) {
+    tests := []struct {
+        data        string
+        paramJSON   string
+        want        string
+    }{
+        {`[1, 2`, "", "The --data value is not valid JSON"},
+        {`[1] [2]`, "", "The --data value must be a single JSON value"},
+        {`[1, 2]`, `{"a": 1}`, "The --data flag cannot be combined with --param, --param-file or --param-json."},
+    }
+
+    for _, test := range tests {
+        body, err := runActionInvokeData(t, &test.data, test.paramJSON)
+        if err == nil || !strings.HasPrefix(err.Error(), test.want) {
+            t.Errorf("action invoke --data %q --param-json %q error = %v, want %q", test.data, test.paramJSON, err,
+                test.want)
+        }
+        if len(body) > 0 {
+            t.Errorf("action invoke --data %q --param-json %q sent %q", test.data, test.paramJSON, body)
+        }
+    }
+}
+
+func TestActionListSkipNamespace(t *testing.T) {
+    actions := []whisk.Action{
+        {Namespace: "ns", Name: "b", Annotations: whisk.KeyValueArr{{Key: "exec", Value: "nodejs:6"}}},
+        {Namespace: "ns/pkg", Name: "a", Annotations: whisk.KeyValueArr{{Key: "exec", Value: "python:3"}}},
+        {Namespace: "ns", Name: "C", Annotations: whisk.KeyValueArr{{Key: "exec", Value: "sequence"}}},
+    }
+
+    outputs := map[bool]string{}
+    for _, skipNamespace := range []bool{false, true} {
+        server := newTestServer(t, actionListHandler(actions, -1))
+        flags.action.skipNamespace = skipNamespace
+        flags.common.limit = 30
+
+        var err error
+        outputs[skipNamespace] = captureOutput(t, func() { err = actionListCmd.RunE(actionListCmd, []string{}) })
+        server.Close()
+
+        if err != nil {
+            t.Fatalf("action list --skip-namespace=%t failed: %s", skipNamespace, err)
+        }
+    }
+
+    // Only the names change: the actions keep the order of the server, and the other columns stay aligned
+    want := map[bool][]string{
+        false: {"/ns/b nodejs:6", "/ns/pkg/a python:3", "/ns/C sequence"},
+        true: {"b nodejs:6", "a python:3", "C sequence"},
+    }
+    for skipNamespace, output := range outputs {
+        lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+        if lines[0] != "actions" {
+            t.Errorf("action list --skip-namespace=%t printed %q, want the actions heading", skipNamespace, output)
+            continue
+        }
+
+        var listed []string
+        for _, line := range lines[1:] {
+            fields := strings.Fields(line)
+            listed = append(listed, fields[0] + " " + fields[len(fields) - 1])
+            if column := strings.Index(line, "private"); column != listNameWidth + 1 {
+                t.Errorf("action list --skip-namespace=%t printed the publish state at column %d in %q, want %d",
+                    skipNamespace, column, line, listNameWidth + 1)
+            }
+        }
+
+        if strings.Join(listed, ", ") != strings.Join(want[skipNamespace], ", ") {
+            t.Errorf("action list --skip-namespace=%t listed %q, want %q", skipNamespace, listed, want[skipNamespace])
+        }
+    }
+}
+
+func TestActionListSkipNamespaceJSON(t *testing.T) {
+    server := newTestServer(t, actionListHandler(getTestActions(2), -1))
+    defer server.Close()
+    flags.action.skipNamespace = true
+    flags.global.output = "json"
+
+    var err error
+    output := captureOutput(t, func() { err = actionListCmd.RunE(actionListCmd, []string{}) })
+    if err != nil {
+        t.Fatalf("action list --skip-namespace --output json failed: %s", err)
+    }
+
+    // Machine readable output keeps the namespace of each action
+    var listed []whisk.Action
+    if err = json.Unmarshal([]byte(output), &listed); err != nil || len(listed) != 2 || listed[0].Namespace != "ns" {
+        t.Errorf("action list --skip-namespace --output json printed %q, want the actions with their namespace", output)
+    }
+}
+
+func TestWebActionAnnotations(t *testing.T) {
+    existing := whisk.KeyValueArr{
+        {Key: "owner", Value: "me"},
+        {Key: WEB_EXPORT_ANNOT, Value: false},
+        {Key: FINAL_ANNOT, Value: "false"},
+    }
+    web := func(webExport bool, rawHTTP bool) whisk.KeyValueArr {
+        return whisk.KeyValueArr{
+            {Key: "owner", Value: "me"},
+            {Key: WEB_EXPORT_ANNOT, Value: webExport},
+            {Key: RAW_HTTP_ANNOT, Value: rawHTTP},
+            {Key: FINAL_ANNOT, Value: true},
+        }
+    }
+
+    tests := []struct {
+        mode    string
+        want    whisk.KeyValueArr
+    }{
+        {"true", web(true, false)},
+        {"Yes", web(true, false)},
+        // raw implies web-export
+        {"raw", web(true, true)},
+        // false removes the annotations rather than setting them to false
+        {"false", whisk.KeyValueArr{{Key: "owner", Value: "me"}}},
+        {"no", whisk.KeyValueArr{{Key: "owner", Value: "me"}}},
+    }
+
+    for _, test := range tests {
+        annotations := append(whisk.KeyValueArr{}, existing...)
+        merged, err := webAction(test.mode, annotations, "a", false)
+        if err != nil {
+            t.Errorf("webAction(%s) failed: %s", test.mode, err)
+        } else if !reflect.DeepEqual(merged, test.want) {
+            t.Errorf("webAction(%s) = %#v, want %#v", test.mode, merged, test.want)
+        }
+    }
+
+    if _, err := webAction("maybe", existing, "a", false); err == nil {
+        t.Errorf("webAction(maybe) succeeded, want an error")
+    }
+}
+
+func TestWebActionFetchesAnnotations(t *testing.T) {
+    server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+        writeJSON(w, http.StatusOK, whisk.Action{Namespace: "ns", Name: "a", Annotations: whisk.KeyValueArr{
+            {Key: WEB_EXPORT_ANNOT, Value: true},
+            {Key: "owner", Value: "me"},
+            {Key: RAW_HTTP_ANNOT, Value: true},
+            {Key: FINAL_ANNOT, Value: true},
+        }})
+    })
+    defer server.Close()
+
+    // Without annotations on the command line, an update keeps those of the action
+    annotations, err := webAction("false", nil, "a", true)
+    if err != nil {
+        t.Fatalf("webAction(false) of an existing action failed: %s", err)
+    }
+    if want := (whisk.KeyValueArr{{Key: "owner", Value: "me"}}); !reflect.DeepEqual(annotations, want) {
+        t.Errorf("webAction(false) of an existing action = %#v, want %#v", annotations, want)
+    }
+    checkRequests(t, server, "GET ns/actions/a")
+}
+
+func TestGetWebActionURL(t *testing.T) {
+    origAPIHost := Properties.APIHost
+    defer func() { Properties.APIHost = origAPIHost }()
+
+    tests := []struct {
+        apiHost     string
+        namespace   string
+        name        string
+        want        string
+    }{
+        {"example.com", "ns", "a", "https://example.com/api/v1/web/ns/default/a"},
+        {"https://example.com", "ns/pkg", "a", "https://example.com/api/v1/web/ns/pkg/a"},
+        {"http://localhost:8080", "guest", "hello", "http://localhost:8080/api/v1/web/guest/default/hello"},
+    }
+
+    for _, test := range tests {
+        Properties.APIHost = test.apiHost
+        if url, err := getWebActionURL(test.namespace, test.name); err != nil {
+            t.Errorf("getWebActionURL(%s, %s) with API host %s failed: %s", test.namespace, test.name, test.apiHost, err)
+        } else if url != test.want {
+            t.Errorf("getWebActionURL(%s, %s) with API host %s = %s, want %s", test.namespace, test.name,
+                test.apiHost, url, test.want)
+        }
+    }
+
+    Properties.APIHost = ""
+    if _, err := getWebActionURL("ns", "a"); err == nil {
+        t.Errorf("getWebActionURL() without an API host succeeded, want an error")
+    }
+}
+
+// runActionUpdateWeb updates the action name with --web mode, and returns the output and the error
+func runActionUpdateWeb(t *testing.T, name string, mode string) (string, error) {
+    if err := actionUpdateCmd.Flags().Set(WEB_FLAG, mode); err != nil {
+        t.Fatalf("setting --web failed: %s", err)
+    }
+    defer func() {
+        actionUpdateCmd.Flags().Set(WEB_FLAG, "")
+        actionUpdateCmd.Flags().Lookup(WEB_FLAG).Changed = false
+    }()
+
+    var err error
+    output := captureOutput(t, func() { err = actionUpdateCmd.RunE(actionUpdateCmd, []string{name}) })
+
+    return output, err
+}
+
+func TestActionUpdateWeb(t *testing.T) {
+    origAPIHost := Properties.APIHost
+    Properties.APIHost = "example.com"
+    defer func() { Properties.APIHost = origAPIHost }()
+
+    tests := []struct {
+        name        string
+        mode        string
+        response    whisk.Action
+        url         string
+        annotations whisk.KeyValueArr
+    }{
+        {"/ns/a", "true", whisk.Action{Namespace: "ns", Name: "a"}, "https://example.com/api/v1/web/ns/default/a",
+            whisk.KeyValueArr{{Key: WEB_EXPORT_ANNOT, Value: true}, {Key: RAW_HTTP_ANNOT, Value: false},
+                {Key: FINAL_ANNOT, Value: true}}},
+        {"/ns/pkg/a", "raw", whisk.Action{Namespace: "ns/pkg", Name: "a"}, "https://example.com/api/v1/web/ns/pkg/a",
+            whisk.KeyValueArr{{Key: WEB_EXPORT_ANNOT, Value: true}, {Key: RAW_HTTP_ANNOT, Value: true},
+                {Key: FINAL_ANNOT, Value: true}}},
+        // Without a namespace and name in the response, those requested are used
+        {"/ns/pkg/a", "true", whisk.Action{}, "https://example.com/api/v1/web/ns/pkg/a",
+            whisk.KeyValueArr{{Key: WEB_EXPORT_ANNOT, Value: true}, {Key: RAW_HTTP_ANNOT, Value: false},
+                {Key: FINAL_ANNOT, Value: true}}},
+        {"/ns/a", "false", whisk.Action{Namespace: "ns", Name: "a"}, "", whisk.KeyValueArr{}},
+    }
+
+    for _, test := range tests {
+        server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+            if r.Method == "GET" {
+                writeJSON(w, http.StatusOK, whisk.Action{Namespace: "ns", Name: "a",
+                    Annotations: whisk.KeyValueArr{{Key: WEB_EXPORT_ANNOT, Value: true}}})
+                return
+            }
+            writeJSON(w, http.StatusOK, test.response)
+        })
+
+        output, err := runActionUpdateWeb(t, test.name, test.mode)
+        path := "ns/actions/" + strings.TrimPrefix(test.name, "/ns/")
+        request := server.getRequest("PUT", path)
+        server.Close()
+
+        if err != nil {
+            t.Errorf("action update %s --web %s failed: %s", test.name, test.mode, err)
+            continue
+        }
+        if request == nil {
+            t.Errorf("action update %s --web %s sent no PUT %s", test.name, test.mode, path)
+            continue
+        }
+
+        var sent whisk.Action
+        if err = json.Unmarshal([]byte(request.Body), &sent); err != nil {
+            t.Errorf("action update %s --web %s sent invalid JSON %s: %s", test.name, test.mode, request.Body, err)
+        } else if !reflect.DeepEqual(sent.Annotations, test.annotations) {
+            t.Errorf("action update %s --web %s sent annotations %#v, want %#v", test.name, test.mode,
+                sent.Annotations, test.annotations)
+        }
+
+        if hasURL := strings.Contains(output, "/api/v1/web/"); len(test.url) == 0 && hasURL {
+            t.Errorf("action update %s --web %s printed a web URL: %q", test.name, test.mode, output)
+        } else if len(test.url) > 0 && !strings.Contains(output, test.url + "\n") {
+            t.Errorf("action update %s --web %s printed %q, want the URL %s", test.name, test.mode, output, test.url)
+        }
+    }
+}
+
+func TestActionListTotal(t *testing.T) {
+    tests := []struct {
+        limit   int
+        output  string
+        want    string
+    }{
+        {2, "", "showing 2 of 5 actions\n"},
+        // Nothing is printed when every action was listed, or the output is machine readable
+        {5, "", ""},
+        {30, "", ""},
+        {2, "json", ""},
+    }
+
+    for _, test := range tests {
+        server := newTestServer(t, actionListHandler(getTestActions(5), -1))
+        flags.common.limit = test.limit
+        flags.global.output = test.output
+
+        var err error
+        output := captureOutput(t, func() { err = actionListCmd.RunE(actionListCmd, []string{}) })
+        server.Close()
+
+        if err != nil {
+            t.Errorf("action list --limit %d failed: %s", test.limit, err)
+            continue
+        }
+        if strings.Contains(output, "showing") != (len(test.want) > 0) ||
+                len(test.want) > 0 && !strings.HasSuffix(output, test.want) {
+            t.Errorf("action list --limit %d --output %q printed:\n%s\nwant it to end with %q", test.limit,
+                test.output, output, test.want)
+        }
+    }
+}
+
+func TestPrintListTotal(t *testing.T) {
+    origFlags := flags
+    defer func() { flags = origFlags }()
+
+    tests := []struct {
+        count       int
+        total       int
+        collection  string
+        all         bool
+        want        string
+    }{
+        {30, 100, "rules", false, "showing 30 of 100 rules\n"},
+        {1, 31, "triggers", false, "showing 1 of 31 triggers\n"},
+        {30, 30, "rules", false, ""},
+        // The server did not report a total
+        {30, -1, "triggers", false, ""},
+        {100, 100, "actions", true, ""},
+    }
+
+    for _, test := range tests {
+        flags = Flags{}
+        flags.common.limit = 30
+        flags.common.all = test.all
+
+        output := captureOutput(t, func() { printListTotal(test.count, test.total, test.collection) })
+        if output != test.want {
+            t.Errorf("printListTotal(%d, %d, %s) printed %q, want %q", test.count, test.total, test.collection,
+                output, test.want)
+        }
+    }
+}
+
+// limitsTestHandler answers the GET of the action a, which has code, parameters and all limits, with the ETag "v1",
+// and answers its PUT with the action sent
+func limitsTestHandler(w http.ResponseWriter, r *http.Request) {
+    if r.Method == "GET" {
+        timeout, memory, logsize, code := 60000, 256, 10, "function main() {}"
+        w.Header().Set("ETag", `"v1"`)
+        writeJSON(w, http.StatusOK, whisk.Action{Namespace: "ns", Name: "a", Version: "0.0.3",
+            Exec: &whisk.Exec{Kind: "nodejs:6", Code: &code},
+            Parameters: whisk.KeyValueArr{{Key: "p", Value: "v"}},
+            Limits: &whisk.Limits{Timeout: &timeout, Memory: &memory, Logsize: &logsize}})
+        return
+    }
+
+    body, _ := ioutil.ReadAll(r.Body)
+    w.Header().Set("Content-Type", "application/json")
+    w.Write(body)
+}
+
+// runActionLimitsUpdate runs action limits update with args, and returns the action it sent and the error. The
+// limit flags are reset afterwards.
+func runActionLimitsUpdate(t *testing.T, server *testServer, args ...string) (*whisk.Action, error) {
+    if err := actionLimitsUpdateCmd.ParseFlags(args); err != nil {
+        t.Fatalf("actionLimitsUpdateCmd.ParseFlags(%q) failed: %s", args, err)
+    }
+    defer func() {
+        for _, name := range []string{TIMEOUT_FLAG, MEMORY_FLAG, LOG_SIZE_FLAG, "logs"} {
+            flag := actionLimitsUpdateCmd.Flags().Lookup(name)
+            flag.Value.Set(flag.DefValue)
+            flag.Changed = false
+        }
+    }()
+
+    var err error
+    captureOutput(t, func() {
+        err = actionLimitsUpdateCmd.RunE(actionLimitsUpdateCmd, actionLimitsUpdateCmd.Flags().Args())
+    })
+
+    request := server.getRequest("PUT", "ns/actions/a")
+    if request == nil {
+        return nil, err
+    }
+
+    var action whisk.Action
+    if jsonErr := json.Unmarshal([]byte(request.Body), &action); jsonErr != nil {
+        t.Fatalf("action limits update sent invalid JSON %s: %s", request.Body, jsonErr)
+    }
+
+    return &action, err
+}
+
+func TestActionLimitsUpdate(t *testing.T) {
+    tests := []struct {
+        args                        []string
+        timeout, memory, logsize    int
+    }{
+        // Only the limits that are given change
+        {[]string{"--timeout", "5000"}, 5000, 256, 10},
+        {[]string{"-m", "512"}, 60000, 512, 10},
+        // A limit of 0 is set, not ignored
+        {[]string{"--logs", "0"}, 60000, 256, 0},
+        {[]string{"--logsize", "2", "-t", "1000", "--memory", "128"}, 1000, 128, 2},
+    }
+
+    for _, test := range tests {
+        server := newTestServer(t, limitsTestHandler)
+        action, err := runActionLimitsUpdate(t, server, append([]string{"/ns/a"}, test.args...)...)
+        server.Close()
+
+        if err != nil {
+            t.Errorf("action limits update %q failed: %s", test.args, err)
+            continue
+        }
+        checkRequests(t, server, "GET ns/actions/a", "PUT ns/actions/a")
+        if request := server.getRequest("PUT", "ns/actions/a"); request.Header.Get("If-Match") != `"v1"` ||
+                request.Query.Get("overwrite") != "true" {
+            t.Errorf("action limits update %q sent If-Match %s and overwrite %s, want \"v1\" and true", test.args,
+                request.Header.Get("If-Match"), request.Query.Get("overwrite"))
+        }
+
+        limits := action.Limits
+        if limits == nil || limits.Timeout == nil || limits.Memory == nil || limits.Logsize == nil ||
+                *limits.Timeout != test.timeout || *limits.Memory != test.memory || *limits.Logsize != test.logsize {
+            t.Errorf("action limits update %q sent limits %#v, want timeout %d, memory %d and log size %d", test.args,
+                limits, test.timeout, test.memory, test.logsize)
+        }
+
+        // Everything else is kept, except the version and namespace that the server assigns
+        if action.Exec == nil || action.Exec.Kind != "nodejs:6" || action.Exec.Code == nil ||
+                *action.Exec.Code != "function main() {}" || len(action.Parameters) != 1 || len(action.Version) > 0 ||
+                len(action.Namespace) > 0 {
+            t.Errorf("action limits update %q sent %#v", test.args, action)
+        }
+    }
+}
+
+func TestActionLimitsUpdateErrors(t *testing.T) {
+    tests := []struct {
+        args    []string
+        want    string
+    }{
+        {[]string{"/ns/a"}, "At least one of --timeout, --memory or --logsize is required."},
+        {[]string{"/ns/a", "--timeout", "99"}, "Invalid timeout limit 99"},
+        {[]string{"/ns/a", "b", "--memory", "256"}, "An action name is required."},
+    }
+
+    for _, test := range tests {
+        server := newTestServer(t, limitsTestHandler)
+        _, err := runActionLimitsUpdate(t, server, test.args...)
+        server.Close()
+
+        if err == nil || !strings.Contains(err.Error(), test.want) {
+            t.Errorf("action limits update %q error = %v, want an error containing %q", test.args, err, test.want)
+        }
+        checkRequests(t, server)
+    }
+}
+
+// runActionGetRaw runs action get --raw with args against a server that answers with status and body, and returns
+// the output and the error
+func runActionGetRaw(t *testing.T, status int, body []byte, args ...string) (string, error) {
+    server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+        w.Header().Set("Content-Type", "application/json")
+        w.WriteHeader(status)
+        w.Write(body)
+    })
+    defer server.Close()
+    flags.action.raw = true
+
+    var err error
+    output := captureOutput(t, func() { err = actionGetCmd.RunE(actionGetCmd, args) })
+    checkRequests(t, server, "GET ns/actions/hello")
+
+    return output, err
+}
+
+func TestActionGetRaw(t *testing.T) {
+    fixture, err := ioutil.ReadFile(filepath.Join("testdata", "action-raw.json"))
+    if err != nil {
+        t.Fatalf("ioutil.ReadFile() failed: %s", err)
+    }
+
+    // The body is printed byte for byte, rather than decoded and indented by printJSON; display flags are ignored
+    for _, args := range [][]string{{"/ns/hello"}, {"/ns/hello", "exec"}} {
+        output, err := runActionGetRaw(t, http.StatusOK, fixture, args...)
+        if err != nil {
+            t.Errorf("action get --raw %q failed: %s", args, err)
+        } else if output != string(fixture) {
+            t.Errorf("action get --raw %q printed:\n%s\nwant the content of testdata/action-raw.json:\n%s", args,
+                output, fixture)
+        }
+    }
+}
+
+func TestActionGetRawNotFound(t *testing.T) {
+    body := []byte(`{"error":"The requested resource does not exist.","code":1}`)
+    output, err := runActionGetRaw(t, http.StatusNotFound, body, "/ns/hello")
+
+    if whiskErr, ok := err.(*whisk.WskError); !ok || whiskErr.ExitCode != whisk.EXITCODE_ERR_NOT_FOUND {
+        t.Errorf("action get --raw of a missing action error = %#v, want a not found error", err)
+    }
+    if len(output) > 0 {
+        t.Errorf("action get --raw of a missing action printed %q", output)
+    }
+}
+
+// activationIDHandler answers invocations with the activation 12345, whole when blocking, and with the id header in
+// the activation id header when it is not empty
+func activationIDHandler(result map[string]interface{}, header string) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        if len(header) > 0 {
+            w.Header().Set(whisk.ActivationIDHeader, header)
+        }
+        if r.URL.Query().Get("blocking") != "true" {
+            writeJSON(w, http.StatusAccepted, map[string]interface{}{"activationId": "12345"})
+            return
+        }
+
+        writeJSON(w, http.StatusOK, map[string]interface{}{
+            "namespace": "ns",
+            "name": "a",
+            "activationId": "12345",
+            "response": map[string]interface{}{"status": "success", "success": true, "result": result},
+        })
+    }
+}
+
+func TestActionInvokeActivationID(t *testing.T) {
+    tests := []struct {
+        blocking    bool
+        result      bool
+        header      string
+        stdout      string      // the beginning of standard output
+        stderr      string
+        query       string
+    }{
+        // The id of the header is preferred to the one of the body
+        {false, false, "header-id", "ok: invoked /ns/a with id header-id\n", "", "blocking=false&result=false"},
+        {false, false, "", "ok: invoked /ns/a with id 12345\n", "", "blocking=false&result=false"},
+        {true, false, "", "ok: invoked /ns/a with id 12345\n{\n", "", "blocking=true&result=false"},
+        // Only the result is printed, so the id goes to standard error; the whole activation is requested for servers
+        // that send no id header
+        {false, true, "header-id", "{\n", "activation id: header-id\n", "blocking=true&result=false"},
+        {false, true, "", "{\n", "activation id: 12345\n", "blocking=true&result=false"},
+    }
+
+    for _, test := range tests {
+        server := newTestServer(t, activationIDHandler(map[string]interface{}{"msg": "hi"}, test.header))
+        flags.common.blocking = test.blocking
+        flags.action.result = test.result
+
+        var err error
+        var stdout string
+        stderr := captureStderr(t, func() {
+            stdout = captureOutput(t, func() { err = actionInvokeCmd.RunE(actionInvokeCmd, []string{"/ns/a"}) })
+        })
+        request := server.getRequest("POST", "ns/actions/a")
+        server.Close()
+
+        if err != nil {
+            t.Errorf("action invoke (blocking %t, result %t) failed: %s", test.blocking, test.result, err)
+            continue
+        }
+        if request == nil || request.Query.Encode() != test.query {
+            t.Errorf("action invoke (blocking %t, result %t) did not send the query %s", test.blocking, test.result,
+                test.query)
+        }
+        if !strings.HasPrefix(stdout, test.stdout) || stderr != test.stderr {
+            t.Errorf("action invoke (blocking %t, result %t, header %q) printed %q and %q to stderr; want %q and %q",
+                test.blocking, test.result, test.header, stdout, stderr, test.stdout, test.stderr)
+        }
+
+        // The result alone is valid JSON
+        if test.result {
+            var result map[string]interface{}
+            if err = json.Unmarshal([]byte(stdout), &result); err != nil || result["msg"] != "hi" {
+                t.Errorf("action invoke --result printed %q, want the result", stdout)
+            }
+        }
+    }
+}
+
+func TestActionInvokeBlockingTimeout(t *testing.T) {
+    for _, result := range []bool{false, true} {
+        server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+            writeJSON(w, http.StatusAccepted, map[string]interface{}{"activationId": "12345"})
+        })
+        flags.common.blocking = true
+        flags.action.result = result
+
+        var err error
+        var stdout string
+        stderr := captureStderr(t, func() {
+            stdout = captureOutput(t, func() { err = actionInvokeCmd.RunE(actionInvokeCmd, []string{"/ns/a"}) })
+        })
+        server.Close()
+
+        // The activation to poll is reported instead of the accepted response
+        if whiskErr, ok := err.(*whisk.WskError); !ok || !whiskErr.TimedOut {
+            t.Errorf("action invoke --blocking (result %t) of a timed out activation error = %#v, want a timeout",
+                result, err)
+        }
+        if want := "activation id: 12345, result not yet available, poll with wsk activation get 12345\n";
+                stderr != want || len(stdout) > 0 {
+            t.Errorf("action invoke --blocking (result %t) of a timed out activation printed %q and %q to stderr, want %q",
+                result, stdout, stderr, want)
+        }
+    }
+}
+
+func TestActionInvokeResultErrorActivationID(t *testing.T) {
+    server := newTestServer(t, activationIDHandler(map[string]interface{}{"error": "boom"}, ""))
+    defer server.Close()
+    flags.action.result = true
+
+    var err error
+    stderr := captureStderr(t, func() {
+        captureOutput(t, func() { err = actionInvokeCmd.RunE(actionInvokeCmd, []string{"/ns/a"}) })
+    })
+
+    if err == nil {
+        t.Errorf("action invoke --result of a failed activation succeeded")
+    }
+    if !strings.Contains(stderr, "activation id: 12345\n") {
+        t.Errorf("action invoke --result of a failed activation printed %q to stderr, want the activation id", stderr)
+    }
+}
+
+// mergeTestHandler answers the GET of the action a with its existing parameters and annotations, or with 404 when
+// exists is false, and answers its PUT with the action sent
+func mergeTestHandler(exists bool) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        if r.Method == "PUT" {
+            body, _ := ioutil.ReadAll(r.Body)
+            w.Header().Set("Content-Type", "application/json")
+            w.Write(body)
+        } else if exists {
+            writeJSON(w, http.StatusOK, whisk.Action{Namespace: "ns", Name: "a",
+                Parameters: whisk.KeyValueArr{{Key: "a", Value: 1}, {Key: "b", Value: "old"}, {Key: "c", Value: true}},
+                Annotations: whisk.KeyValueArr{{Key: "owner", Value: "me"}, {Key: "exec", Value: "nodejs:6"}}})
+        } else {
+            writeJSON(w, http.StatusNotFound, map[string]interface{}{"error": "The requested resource does not exist.", "code": 1})
+        }
+    }
+}
+
+func TestActionUpdateMerge(t *testing.T) {
+    tests := []struct {
+        args            []string
+        merge           string  // "p" for --merge-params, "a" for --merge-annotations
+        deleteParams    []string
+        delAnnotations  []string
+        exists          bool
+        parameters      string  // the parameters sent, as JSON
+        annotations     string
+    }{
+        // Given values replace existing ones of the same key, in place, and new ones are appended
+        {[]string{"-p", "b", "new"}, "p", nil, nil, true, `[{"key":"a","value":1},{"key":"b","value":"new"},{"key":"c","value":true}]`, `null`},
+        {[]string{"-p", "d", `{"x":[1,2]}`}, "p", nil, nil, true,
+            `[{"key":"a","value":1},{"key":"b","value":"old"},{"key":"c","value":true},{"key":"d","value":{"x":[1,2]}}]`, `null`},
+        // Without merging, the given values replace all existing ones
+        {[]string{"-p", "b", "new"}, "", nil, nil, true, `[{"key":"b","value":"new"}]`, `null`},
+        // Deleting keys implies merging
+        {nil, "", []string{"c"}, nil, true, `[{"key":"a","value":1},{"key":"b","value":"old"}]`, `null`},
+        {[]string{"-p", "b", "new"}, "", []string{"a", "missing"}, nil, true,
+            `[{"key":"b","value":"new"},{"key":"c","value":true}]`, `null`},
+        // Deleting every key sends an empty array, so that the server removes them
+        {nil, "p", []string{"a", "b", "c"}, nil, true, `[]`, `null`},
+        {[]string{"-a", "owner", "you"}, "a", nil, nil, true, `null`,
+            `[{"key":"owner","value":"you"},{"key":"exec","value":"nodejs:6"}]`},
+        {[]string{"-p", "b", "new", "-a", "team", "x"}, "pa", nil, []string{"owner"}, true,
+            `[{"key":"a","value":1},{"key":"b","value":"new"},{"key":"c","value":true}]`,
+            `[{"key":"exec","value":"nodejs:6"},{"key":"team","value":"x"}]`},
+        // Without an existing action there is nothing to merge
+        {[]string{"-p", "b", "new"}, "p", nil, nil, false, `[{"key":"b","value":"new"}]`, `null`},
+    }
+
+    for _, test := range tests {
+        server := newTestServer(t, mergeTestHandler(test.exists))
+
+        var err error
+        _, flags.common.param, flags.common.annotation, err = parseArgs(append([]string{"wsk", "action", "update", "/ns/a"}, test.args...))
+        if err != nil {
+            t.Fatalf("parseArgs(%q) failed: %s", test.args, err)
+        }
+        flags.action.mergeParams = strings.Contains(test.merge, "p")
+        flags.action.mergeAnnots = strings.Contains(test.merge, "a")
+        flags.common.delParam, flags.common.delAnnotation = test.deleteParams, test.delAnnotations
+
+        captureOutput(t, func() { err = actionUpdateCmd.RunE(actionUpdateCmd, []string{"/ns/a"}) })
+        request := server.getRequest("PUT", "ns/actions/a")
+        fetched := server.getRequest("GET", "ns/actions/a") != nil
+        server.Close()
+
+        description := fmt.Sprintf("action update %q (merge %q, delete params %q, annotations %q)", test.args,
+            test.merge, test.deleteParams, test.delAnnotations)
+        if err != nil || request == nil {
+            t.Errorf("%s failed: %v", description, err)
+            continue
+        }
+        if !fetched {
+            t.Errorf("%s did not fetch the existing action", description)
+        }
+
+        var sent struct {
+            Parameters  json.RawMessage `json:"parameters"`
+            Annotations json.RawMessage `json:"annotations"`
+        }
+        json.Unmarshal([]byte(request.Body), &sent)
+        if parameters := string(sent.Parameters); parameters != test.parameters && !(len(parameters) == 0 && test.parameters == "null") {
+            t.Errorf("%s sent the parameters %s, want %s", description, parameters, test.parameters)
+        }
+        if annotations := string(sent.Annotations); annotations != test.annotations && !(len(annotations) == 0 && test.annotations == "null") {
+            t.Errorf("%s sent the annotations %s, want %s", description, annotations, test.annotations)
+        }
+    }
+}
+
+func TestActionUpdateSetAndDeleteKey(t *testing.T) {
+    tests := []struct {
+        args            []string
+        deleteParams    []string
+        delAnnotations  []string
+        want            string
+    }{
+        {[]string{"-p", "b", "new"}, []string{"b"}, nil, "The key 'b' cannot be both set and deleted with --del-param"},
+        {[]string{"-a", "owner", "you"}, nil, []string{"owner"}, "The key 'owner' cannot be both set and deleted with --del-annotation"},
+    }
+
+    for _, test := range tests {
+        server := newTestServer(t, mergeTestHandler(true))
+
+        var err error
+        _, flags.common.param, flags.common.annotation, err = parseArgs(append([]string{"wsk", "action", "update", "/ns/a"}, test.args...))
+        if err != nil {
+            t.Fatalf("parseArgs(%q) failed: %s", test.args, err)
+        }
+        flags.common.delParam, flags.common.delAnnotation = test.deleteParams, test.delAnnotations
+
+        err = actionUpdateCmd.RunE(actionUpdateCmd, []string{"/ns/a"})
+        checkRequests(t, server)
+        server.Close()
+
+        if err == nil || !strings.Contains(err.Error(), test.want) {
+            t.Errorf("action update %q error = %v, want %q", test.args, err, test.want)
+        }
+    }
+}
+
+func TestActionUpdateDeleteParamAlias(t *testing.T) {
+    if flag := actionUpdateCmd.Flags().Lookup("del-param"); flag == nil || flag.Hidden {
+        t.Errorf("action update has no --del-param flag")
+    }
+    if flag := actionUpdateCmd.Flags().Lookup("delete-param"); flag == nil || !flag.Hidden {
+        t.Errorf("action update has no hidden --delete-param alias")
+    }
+
+    server := newTestServer(t, mergeTestHandler(true))
+    defer server.Close()
+    flags.common.delParam, flags.action.deleteParam = []string{"a"}, []string{"c"}
+
+    var err error
+    captureOutput(t, func() { err = actionUpdateCmd.RunE(actionUpdateCmd, []string{"/ns/a"}) })
+    if err != nil {
+        t.Fatalf("action update --del-param a --delete-param c failed: %s", err)
+    }
+
+    // The keys of both flags are deleted
+    var sent struct {
+        Parameters  json.RawMessage     `json:"parameters"`
+    }
+    json.Unmarshal([]byte(server.getRequest("PUT", "ns/actions/a").Body), &sent)
+    if want := `[{"key":"b","value":"old"}]`; string(sent.Parameters) != want {
+        t.Errorf("action update --del-param a --delete-param c sent the parameters %s, want %s", sent.Parameters, want)
+    }
+}
+
+func TestActionInvokeTimeout(t *testing.T) {
+    server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+        select {
+        case <-time.After(200 * time.Millisecond):
+            writeJSON(w, http.StatusOK, map[string]interface{}{"activationId": "12345",
+                "response": map[string]interface{}{"status": "success", "success": true, "result": map[string]interface{}{}}})
+        case <-r.Context().Done():
+        }
+    })
+    defer server.Close()
+    client.Config.RequestTimeout = 50 * time.Millisecond
+    client.Config.MaxRetries = 0
+
+    // A blocking invocation is waited for as long as the action may run, whatever the request timeout
+    flags.common.blocking, flags.action.invokeTimeout = true, 1000
+    var err error
+    stdout := captureOutput(t, func() { err = actionInvokeCmd.RunE(actionInvokeCmd, []string{"/ns/a"}) })
+    if err != nil || !strings.Contains(stdout, "12345") {
+        t.Errorf("blocking action invoke --timeout 1000 of a slow server = %q, %v; want the activation 12345", stdout, err)
+    }
+
+    flags.common.blocking = false
+    captureOutput(t, func() { err = actionInvokeCmd.RunE(actionInvokeCmd, []string{"/ns/a"}) })
+    if whiskErr, ok := err.(*whisk.WskError); !ok || whiskErr.ExitCode != whisk.EXITCODE_ERR_NETWORK ||
+            !strings.Contains(whiskErr.Error(), "timed out") {
+        t.Errorf("action invoke of a slow server error = %#v, want a timed out WskError with exit code %d", err,
+            whisk.EXITCODE_ERR_NETWORK)
+    }
+}
+
+// diffTestHandler serves the action ns/a with code, ns/seq as a sequence and ns/bin as a binary action
+func diffTestHandler(code string) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        binary := base64.StdEncoding.EncodeToString([]byte("PK\x03\x04zipped"))
+        actions := map[string]whisk.Action{
+            "ns/actions/a": {Namespace: "ns", Name: "a", Exec: &whisk.Exec{Kind: "nodejs:6", Code: &code}},
+            "ns/actions/seq": {Namespace: "ns", Name: "seq", Exec: &whisk.Exec{Kind: "sequence", Components: []string{"/ns/a"}}},
+            "ns/actions/bin": {Namespace: "ns", Name: "bin", Exec: &whisk.Exec{Kind: "java", Code: &binary}},
+        }
+
+        if action, found := actions[strings.TrimPrefix(r.URL.Path, "/api/v1/namespaces/")]; found {
+            writeJSON(w, http.StatusOK, action)
+        } else {
+            writeJSON(w, http.StatusNotFound, map[string]interface{}{"error": "The requested resource does not exist.", "code": 1})
+        }
+    }
+}
+
+func TestActionDiff(t *testing.T) {
+    file := writeTestFile(t, "a.js", "function main() {\n    return {};\n}\n")
+    defer os.RemoveAll(filepath.Dir(file))
+
+    tests := []struct {
+        deployed    string
+        stdout      string
+        exitCode    int
+    }{
+        {"function main() {\n    return {};\n}\n", "", 0},
+        {"function main() {\n    return {};\n}", "", 0},
+        {"function main() {\n    return {a: 1};\n}\n",
+            "--- a\n+++ " + file + "\n@@ -1,3 +1,3 @@\n function main() {\n-    return {a: 1};\n+    return {};\n }\n",
+            whisk.EXITCODE_ERR_GENERAL},
+    }
+
+    for _, test := range tests {
+        server := newTestServer(t, diffTestHandler(test.deployed))
+
+        var err error
+        stdout := captureOutput(t, func() { err = actionDiffCmd.RunE(actionDiffCmd, []string{"/ns/a", file}) })
+        server.Close()
+
+        if stdout != test.stdout {
+            t.Errorf("action diff of %q printed:\n%s\nwant:\n%s", test.deployed, stdout, test.stdout)
+        }
+        if test.exitCode == 0 && err != nil {
+            t.Errorf("action diff of %q failed: %s", test.deployed, err)
+        } else if whiskErr, ok := err.(*whisk.WskError); test.exitCode != 0 && (!ok || whiskErr.ExitCode != test.exitCode ||
+                whiskErr.DisplayMsg) {
+            t.Errorf("action diff of %q error = %#v, want a WskError with exit code %d and no message", test.deployed,
+                err, test.exitCode)
+        }
+    }
+}
+
+func TestActionDiffErrors(t *testing.T) {
+    file := writeTestFile(t, "a.js", "function main() {}\n")
+    defer os.RemoveAll(filepath.Dir(file))
+    zip := writeTestFile(t, "a.zip", "PK\x03\x04zipped")
+    defer os.RemoveAll(filepath.Dir(zip))
+
+    tests := []struct {
+        action  string
+        file    string
+        want    string
+    }{
+        {"/ns/bin", file, "binary action; cannot diff"},
+        {"/ns/a", zip, "binary action; cannot diff"},
+        {"/ns/seq", file, "Action 'seq' has no code to compare."},
+        {"/ns/missing", file, "Unable to get action 'missing'"},
+        {"/ns/a", file + ".missing", "a.js.missing"},
+    }
+
+    for _, test := range tests {
+        server := newTestServer(t, diffTestHandler("function main() {}\n"))
+
+        var err error
+        stdout := captureOutput(t, func() { err = actionDiffCmd.RunE(actionDiffCmd, []string{test.action, test.file}) })
+        server.Close()
+
+        // Trouble exits with 2, as it does for diff
+        if whiskErr, ok := err.(*whisk.WskError); !ok || whiskErr.ExitCode != whisk.EXITCODE_ERR_USAGE ||
+                !strings.Contains(whiskErr.Error(), test.want) {
+            t.Errorf("action diff %s %s error = %#v, want a WskError with exit code %d containing %q", test.action,
+                test.file, err, whisk.EXITCODE_ERR_USAGE, test.want)
+        }
+        if len(stdout) > 0 {
+            t.Errorf("action diff %s %s printed %q, want nothing", test.action, test.file, stdout)
+        }
+    }
+}
+
+// watchTestHandler answers action updates with the action sent, or with 500 when fail is set, and invocations with
+// the activation 12345
+func watchTestHandler(fail bool) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        if r.Method == "PUT" && fail {
+            writeJSON(w, http.StatusInternalServerError, map[string]interface{}{"error": "out of disk", "code": 1})
+        } else if r.Method == "PUT" {
+            w.Header().Set("Content-Type", "application/json")
+            io.Copy(w, r.Body)
+        } else {
+            writeJSON(w, http.StatusAccepted, map[string]interface{}{"activationId": "12345"})
+        }
+    }
+}
+
+// waitForRequests waits until server has received count requests, for up to 5 seconds
+func waitForRequests(t *testing.T, server *testServer, count int) {
+    for deadline := time.Now().Add(5 * time.Second); len(server.getRequests()) < count; {
+        if time.Now().After(deadline) {
+            t.Fatalf("server received the requests %q, want %d", server.getRequests(), count)
+        }
+        time.Sleep(20 * time.Millisecond)
+    }
+}
+
+func TestActionWatch(t *testing.T) {
+    file := writeTestFile(t, "a.js", "function main() { return {v: 1}; }\n")
+    defer os.RemoveAll(filepath.Dir(file))
+
+    server := newTestServer(t, watchTestHandler(false))
+    defer server.Close()
+    flags.action.invokeAfter = true
+
+    // The interrupt that ends the watch must not end the test
+    interrupts := make(chan os.Signal, 1)
+    signal.Notify(interrupts, os.Interrupt)
+    defer signal.Stop(interrupts)
+
+    var err error
+    stdout := captureOutput(t, func() {
+        done := make(chan error, 1)
+        go func() { done <- actionWatchCmd.RunE(actionWatchCmd, []string{"/ns/a", file}) }()
+
+        // Each change to the file updates the action and then invokes it
+        time.Sleep(100 * time.Millisecond)
+        ioutil.WriteFile(file, []byte("function main() { return {v: 2}; }\n"), 0644)
+        waitForRequests(t, server, 2)
+        ioutil.WriteFile(file, []byte("function main() { return {v: 333}; }\n"), 0644)
+        waitForRequests(t, server, 4)
+
+        syscall.Kill(os.Getpid(), syscall.SIGINT)
+        select {
+        case err = <-done:
+        case <-time.After(5 * time.Second):
+            t.Fatalf("action watch did not end when interrupted")
+        }
+    })
+
+    if err != nil {
+        t.Fatalf("action watch failed: %s", err)
+    }
+    checkRequests(t, server, "PUT ns/actions/a", "POST ns/actions/a", "PUT ns/actions/a", "POST ns/actions/a")
+
+    for i, want := range []string{"{v: 2}", "{v: 333}"} {
+        var action whisk.Action
+        json.Unmarshal([]byte(server.requests[2 * i].Body), &action)
+        if action.Exec == nil || action.Exec.Kind != "nodejs:default" || action.Exec.Code == nil ||
+                !strings.Contains(*action.Exec.Code, want) {
+            t.Errorf("update %d sent %s, want the code with %s", i + 1, server.requests[2 * i].Body, want)
+        }
+        if query := server.requests[2 * i].Query.Get("overwrite"); query != "true" {
+            t.Errorf("update %d had overwrite=%q, want true", i + 1, query)
+        }
+    }
+
+    pattern := "^Watching " + regexp.QuoteMeta(file) + " for changes to action a. Enter Ctrl-c to exit.\n" +
+        "(\\d\\d:\\d\\d:\\d\\d ok: updated action a\n\\d\\d:\\d\\d:\\d\\d ok: invoked a with id 12345\n){2}" +
+        "Watch terminated\n$"
+    if !regexp.MustCompile(pattern).MatchString(stdout) {
+        t.Errorf("action watch printed:\n%s\nwant it to match:\n%s", stdout, pattern)
+    }
+}
+
+func TestWatchDeployActionFailure(t *testing.T) {
+    file := writeTestFile(t, "a.js", "function main() {}\n")
+    defer os.RemoveAll(filepath.Dir(file))
+
+    server := newTestServer(t, watchTestHandler(true))
+    defer server.Close()
+    client.Config.MaxRetries = 0
+
+    var deployed bool
+    var stdout string
+    stderr := captureStderr(t, func() {
+        stdout = captureOutput(t, func() {
+            deployed = watchDeployAction(QualifiedName{namespace: "ns", entityName: "a"}, []string{"/ns/a", file})
+        })
+    })
+
+    // A failed update is reported, and the action is not invoked
+    if deployed || len(stdout) > 0 {
+        t.Errorf("watchDeployAction() with a failing server = %t, printed %q; want false and nothing", deployed, stdout)
+    }
+    if !regexp.MustCompile(`^\d\d:\d\d:\d\d error: unable to update action a: .*out of disk`).MatchString(stderr) {
+        t.Errorf("watchDeployAction() with a failing server printed %q to standard error", stderr)
+    }
+    checkRequests(t, server, "PUT ns/actions/a")
+}
+
+func TestActionWatchMissingFile(t *testing.T) {
+    server := newTestServer(t, watchTestHandler(false))
+    defer server.Close()
+
+    err := actionWatchCmd.RunE(actionWatchCmd, []string{"/ns/a", "missing.js"})
+    if err == nil || !strings.Contains(err.Error(), "missing.js") {
+        t.Errorf("action watch of a missing file error = %v, want an error about missing.js", err)
+    }
+    checkRequests(t, server)
+}
+
+func TestActionCreateFromDirectory(t *testing.T) {
+    tests := []struct {
+        files   map[string]string
+        kind    string
+        want    string
+        entries []string
+    }{
+        // The kind of a directory with a package.json is Node.js
+        {map[string]string{"package.json": `{"main": "index.js"}`, "index.js": "x\n", "node_modules/dep/index.js": "y\n"},
+            "", "nodejs:default", []string{"index.js", "node_modules/dep/index.js", "package.json"}},
+        {map[string]string{"package.json": `{"main": "index.js"}`, "index.js": "x\n"},
+            "nodejs:6", "nodejs:6", []string{"index.js", "package.json"}},
+        {map[string]string{"__main__.py": "def main(args): return args\n", ".git/config": "[core]\n"},
+            "python:3", "python:3", []string{"__main__.py"}},
+    }
+
+    for _, test := range tests {
+        dir := makeTestTree(t, test.files)
+        server := newTestServer(t, watchTestHandler(false))
+        flags.action.kind = test.kind
+
+        var err error
+        captureOutput(t, func() { err = actionCreateCmd.RunE(actionCreateCmd, []string{"/ns/a", dir}) })
+        request := server.getRequest("PUT", "ns/actions/a")
+        server.Close()
+        os.RemoveAll(dir)
+
+        if err != nil || request == nil {
+            t.Errorf("action create of a directory with --kind %q failed: %v", test.kind, err)
+            continue
+        }
+
+        var action whisk.Action
+        json.Unmarshal([]byte(request.Body), &action)
+        if action.Exec == nil || action.Exec.Kind != test.want || action.Exec.Code == nil {
+            t.Errorf("action create of a directory with --kind %q sent %s, want the kind %s", test.kind, request.Body,
+                test.want)
+            continue
+        }
+        archive, err := base64.StdEncoding.DecodeString(*action.Exec.Code)
+        if err != nil {
+            t.Errorf("action create of a directory sent code that is not base64: %s", err)
+            continue
+        }
+        if entries, _ := getTestZipEntries(t, archive); !reflect.DeepEqual(entries, test.entries) {
+            t.Errorf("action create of a directory with --kind %q sent the entries %q, want %q", test.kind, entries,
+                test.entries)
+        }
+    }
+}
+
+func TestActionCreateFromDirectoryErrors(t *testing.T) {
+    // Random data does not compress, so its archive is larger than the limit once base64 encoded
+    large := make([]byte, MAX_CODE_SIZE * 3 / 4 + 1024)
+    rand.New(rand.NewSource(1)).Read(large)
+
+    tests := []struct {
+        files   map[string]string
+        kind    string
+        want    string
+    }{
+        {map[string]string{"index.js": "x\n"}, "",
+            "creating an action from a directory without a package.json file requires specifying the action kind explicitly"},
+        {map[string]string{"index.js": "x\n", "data.bin": string(large)}, "nodejs:6", "which is larger than the limit of"},
+    }
+
+    for _, test := range tests {
+        dir := makeTestTree(t, test.files)
+        server := newTestServer(t, watchTestHandler(false))
+        flags.action.kind = test.kind
+
+        err := actionCreateCmd.RunE(actionCreateCmd, []string{"/ns/a", dir})
+        checkRequests(t, server)
+        server.Close()
+        os.RemoveAll(dir)
+
+        if err == nil || !strings.Contains(err.Error(), test.want) {
+            t.Errorf("action create of a directory with --kind %q error = %v, want %q", test.kind, err, test.want)
+        }
+    }
+}
+
+func TestActionInvokeWeb(t *testing.T) {
+    tests := []struct {
+        name        string
+        method      string
+        authToken   string
+        args        []string    // parameter arguments
+        contentType string
+        response    string
+        request     string      // METHOD PATH of the request
+        query       string
+        body        string
+        output      string
+    }{
+        // JSON responses are indented, and other content is printed as is
+        {"/ns/a", "get", "", []string{"-p", "name", "x", "-p", "n", "1"}, "application/json", `{"greeting":"hi"}`,
+            "GET /api/v1/web/ns/default/a", "n=1&name=x", "", "{\n    \"greeting\": \"hi\"\n}\n"},
+        {"/ns/pkg/a", "POST", "secret", []string{"-p", "name", "x", "-p", "n", "1"}, "text/html; charset=utf-8", "<b>hi</b>",
+            "POST /api/v1/web/ns/pkg/a", "", `{"n":1,"name":"x"}`, "<b>hi</b>"},
+        {"/ns/a", "PUT", "", []string{"-p", "obj", `{"a":true}`}, "application/json", `[1, 2]`,
+            "PUT /api/v1/web/ns/default/a", "", `{"obj":{"a":true}}`, "[\n    1,\n    2\n]\n"},
+        {"/ns/a", "delete", "secret", []string{"-p", "obj", `{"a":true}`}, "text/plain", "gone",
+            "DELETE /api/v1/web/ns/default/a", "obj=%7B%22a%22%3Atrue%7D", "", "gone"},
+        // Invalid JSON is printed as is
+        {"/ns/a", "GET", "", nil, "application/json", "{", "GET /api/v1/web/ns/default/a", "", "", "{"},
+    }
+
+    for _, test := range tests {
+        server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+            w.Header().Set("Content-Type", test.contentType)
+            w.Write([]byte(test.response))
+        })
+        flags.action.webInvoke = true
+        flags.action.webMethod = test.method
+        flags.action.webAuthToken = test.authToken
+
+        var err error
+        _, flags.common.param, _, err = parseArgs(append([]string{"wsk", "action", "invoke", "a"}, test.args...))
+        if err != nil {
+            t.Fatalf("parseArgs(%q) failed: %s", test.args, err)
+        }
+
+        output := captureOutput(t, func() { err = actionInvokeCmd.RunE(actionInvokeCmd, []string{test.name}) })
+        requests := server.requests
+        server.Close()
+
+        if err != nil {
+            t.Errorf("action invoke %s --web --method %s failed: %s", test.name, test.method, err)
+            continue
+        }
+        if len(requests) != 1 {
+            t.Errorf("action invoke %s --web --method %s sent %d requests, want 1", test.name, test.method, len(requests))
+            continue
+        }
+
+        request := requests[0]
+        if have := request.Method + " " + request.Path; have != test.request || request.Query.Encode() != test.query ||
+                strings.TrimSpace(request.Body) != test.body {
+            t.Errorf("action invoke %s --web --method %s sent %s?%s with the body %q, want %s?%s with the body %q",
+                test.name, test.method, have, request.Query.Encode(), request.Body, test.request, test.query, test.body)
+        }
+        if auth := request.Header.Get("Authorization"); len(auth) > 0 {
+            t.Errorf("action invoke %s --web sent the Authorization header %q", test.name, auth)
+        }
+        if token := request.Header.Get(whisk.RequireWhiskAuthHeader); token != test.authToken {
+            t.Errorf("action invoke %s --web --auth-token %q sent the %s header %q", test.name, test.authToken,
+                whisk.RequireWhiskAuthHeader, token)
+        }
+        if output != test.output {
+            t.Errorf("action invoke %s --web --method %s printed %q, want %q", test.name, test.method, output,
+                test.output)
+        }
+    }
+
+    if flag := actionInvokeCmd.Flags().Lookup("method"); flag == nil || flag.DefValue != "GET" {
+        t.Errorf("action invoke --method = %#v, want a flag with the default GET", flag)
+    }
+}
+
+func TestActionInvokeWebErrors(t *testing.T) {
+    tests := []struct {
+        name    string
+        method  string
+        want    string
+    }{
+        {"/ns/a", "PATCH",
+            "Invalid method 'PATCH'; the method of a web action invocation must be GET, POST, PUT or DELETE"},
+        {"/ns/a", "",
+            "Invalid method ''; the method of a web action invocation must be GET, POST, PUT or DELETE"},
+        {"a", "GET",
+            "A namespace is required to invoke web action 'a'; use /NAMESPACE/a or set the namespace property"},
+    }
+
+    for _, test := range tests {
+        server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+            writeJSON(w, http.StatusOK, map[string]interface{}{})
+        })
+        flags.action.webInvoke = true
+        flags.action.webMethod = test.method
+
+        err := actionInvokeCmd.RunE(actionInvokeCmd, []string{test.name})
+        checkRequests(t, server)
+        server.Close()
+
+        if err == nil || err.Error() != test.want {
+            t.Errorf("action invoke %s --web --method %s error = %v, want %q", test.name, test.method, err, test.want)
+        }
+    }
+
+    // A GET or DELETE request sends the parameters as its query, which has no room for a JSON array
+    if _, err := getWebQuery([]interface{}{"a"}); err == nil ||
+            err.Error() != "The parameters of a GET or DELETE web action invocation must be a JSON object" {
+        t.Errorf("getWebQuery() of a JSON array error = %v, want an error", err)
+    }
+
+    // A web action that requires authentication rejects a wrong secret
+    server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+        writeJSON(w, http.StatusUnauthorized, map[string]interface{}{"error": "Authentication is possible but has failed or not yet been provided.", "code": 1})
+    })
+    defer server.Close()
+    flags.action.webInvoke = true
+    flags.action.webMethod = "GET"
+    flags.action.webAuthToken = "wrong"
+
+    err := actionInvokeCmd.RunE(actionInvokeCmd, []string{"/ns/a"})
+    if whiskErr, ok := err.(*whisk.WskError); !ok || !strings.Contains(whiskErr.Error(), "Authentication is possible") {
+        t.Errorf("action invoke --web with a wrong secret error = %#v, want the server error", err)
+    }
+}
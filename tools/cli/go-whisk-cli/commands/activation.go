@@ -19,8 +19,13 @@ package commands
 import (
     "errors"
     "fmt"
+    "net/http"
     "os"
     "os/signal"
+    "path"
+    "strconv"
+    "sync"
+    "sync/atomic"
     "syscall"
     "time"
 
@@ -68,13 +73,30 @@ var activationListCmd = &cobra.Command{
             client.Namespace = qualifiedName.namespace
         }
 
+        since, err := parseActivationTime("since", flags.activation.since)
+        if err != nil {
+            return err
+        }
+
+        upto, err := parseActivationTime("upto", flags.activation.upto)
+        if err != nil {
+            return err
+        }
+
+        if since > 0 && upto > 0 && since > upto {
+            whisk.Debug(whisk.DbgError, "--since %d is later than --upto %d\n", since, upto)
+            errStr := wski18n.T("The --since time must not be later than the --upto time.")
+            werr := whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_USAGE, whisk.DISPLAY_MSG, whisk.DISPLAY_USAGE)
+            return werr
+        }
+
         options := &whisk.ActivationListOptions{
             Name:  qualifiedName.entityName,
             Limit: flags.common.limit,
             Skip:  flags.common.skip,
-            Upto:  flags.activation.upto,
-            Since: flags.activation.since,
-            Docs:  flags.common.full,
+            Upto:  upto,
+            Since: since,
+            Docs:  flags.common.full || flags.common.summary,
         }
         activations, _, err := client.Activations.List(options)
         if err != nil {
@@ -86,14 +108,23 @@ var activationListCmd = &cobra.Command{
             return werr
         }
 
+        if since > 0 && upto > 0 && len(flags.global.output) == 0 {
+            fmt.Printf(wski18n.T("activations from {{.since}} to {{.upto}}\n",
+                map[string]interface{}{"since": formatActivationTime(since), "upto": formatActivationTime(upto)}))
+        }
+
+        if flags.common.summary && len(flags.global.output) == 0 {
+            printActivationSummaryList(activations)
+            return nil
+        }
+
         // When the --full (URL contains "?docs=true") option is specified, display the entire activation details
-        if options.Docs == true {
+        if flags.common.full && len(flags.global.output) == 0 {
             printFullActivationList(activations)
-        } else {
-            printList(activations)
+            return nil
         }
 
-        return nil
+        return printList(activations)
     },
 }
 
@@ -106,9 +137,26 @@ var activationGetCmd = &cobra.Command{
     RunE: func(cmd *cobra.Command, args []string) error {
         var field string
 
-        if whiskErr := checkArgs(args, 1, 2, "Activation get",
+        if flags.activation.last {
+            if whiskErr := checkArgs(args, 0, 1, "Activation get",
+                    wski18n.T("An optional field filter is the only valid argument with --last.")); whiskErr != nil {
+                return whiskErr
+            }
+
+            if len(args) == 1 && !fieldExists(&whisk.Activation{}, args[0]) {
+                return lastActivationIDError()
+            }
+
+            id, err := getLastActivationID()
+            if err != nil {
+                return err
+            }
+            args = append([]string{id}, args...)
+        } else if whiskErr := checkArgs(args, 1, 2, "Activation get",
                 wski18n.T("An activation ID is required.")); whiskErr != nil {
             return whiskErr
+        } else if len(flags.activation.action) > 0 {
+            return actionWithoutLastError()
         }
 
         if len(args) > 1 {
@@ -168,12 +216,15 @@ var activationLogsCmd = &cobra.Command{
     PreRunE: setupClientConfig,
     RunE: func(cmd *cobra.Command, args []string) error {
 
-        if whiskErr := checkArgs(args, 1, 1, "Activation logs",
-                wski18n.T("An activation ID is required.")); whiskErr != nil {
-            return whiskErr
+        id, err := getActivationIDArg(args, "Activation logs")
+        if err != nil {
+            return err
+        }
+
+        if flags.activation.follow {
+            return followActivationLogs(id)
         }
 
-        id := args[0]
         activation, _, err := client.Activations.Logs(id)
         if err != nil {
             whisk.Debug(whisk.DbgError, "client.Activations.Logs(%s) failed: %s\n", id, err)
@@ -196,12 +247,11 @@ var activationResultCmd = &cobra.Command{
     PreRunE: setupClientConfig,
     RunE: func(cmd *cobra.Command, args []string) error {
 
-        if whiskErr := checkArgs(args, 1, 1, "Activation result",
-                wski18n.T("An activation ID is required.")); whiskErr != nil {
-            return whiskErr
+        id, err := getActivationIDArg(args, "Activation result")
+        if err != nil {
+            return err
         }
 
-        id := args[0]
         result, _, err := client.Activations.Result(id)
         if err != nil {
             whisk.Debug(whisk.DbgError, "client.Activations.result(%s) failed: %s\n", id, err)
@@ -225,6 +275,8 @@ var activationPollCmd = &cobra.Command{
     RunE: func(cmd *cobra.Command, args []string) error {
         var name string
         var pollSince int64 // Represents an instant in time (in milliseconds since Jan 1 1970)
+        var lastSeen int64  // Start time of the most recent activation displayed; shared with the signal handler
+        var err error
 
         if len(args) == 1 {
             name = args[0]
@@ -233,12 +285,20 @@ var activationPollCmd = &cobra.Command{
             return whiskErr
         }
 
+        if len(flags.activation.action) > 0 {
+            name = flags.activation.action
+        }
+
         c := make(chan os.Signal, 1)
         signal.Notify(c, os.Interrupt)
         signal.Notify(c, syscall.SIGTERM)
         go func() {
             <-c
             fmt.Println(wski18n.T("Poll terminated"))
+            if since := atomic.LoadInt64(&lastSeen); since > 0 {
+                fmt.Println(wski18n.T("Resume polling with --since {{.since}}",
+                    map[string]interface{}{"since": since}))
+            }
             os.Exit(1)
         }()
         fmt.Println(wski18n.T("Enter Ctrl-c to exit."))
@@ -246,7 +306,11 @@ var activationPollCmd = &cobra.Command{
         // Map used to track activation records already displayed to the console
         reported := make(map[string]bool)
 
-        if flags.activation.sinceSeconds+
+        if len(flags.activation.pollSince) > 0 {
+            if pollSince, err = parsePollSince(flags.activation.pollSince); err != nil {
+                return err
+            }
+        } else if flags.activation.sinceSeconds+
         flags.activation.sinceMinutes+
         flags.activation.sinceHours+
         flags.activation.sinceDays ==
@@ -315,7 +379,15 @@ var activationPollCmd = &cobra.Command{
             for _, activation := range activations {
                 if reported[activation.ActivationID] == true {
                     continue
+                } else if len(name) > 0 && activation.Name != path.Base(name) {
+                    continue
                 } else {
+                    // Remember the most recent activation so that polling can be resumed from it
+                    if activation.Start > pollSince {
+                        pollSince = activation.Start
+                        atomic.StoreInt64(&lastSeen, activation.Start)
+                    }
+
                     fmt.Printf(
                         wski18n.T("\nActivation: {{.name}} ({{.id}})\n",
                             map[string]interface{}{"name": activation.Name, "id": activation.ActivationID}))
@@ -329,20 +401,376 @@ var activationPollCmd = &cobra.Command{
     },
 }
 
+var activationWatchCmd = &cobra.Command{
+    Use:   "watch",
+    Short: wski18n.T("print new activations as they start"),
+    SilenceUsage:   true,
+    SilenceErrors:  true,
+    PreRunE: setupClientConfig,
+    RunE: func(cmd *cobra.Command, args []string) error {
+        var printLock sync.Mutex    // Held while an activation is printed so Ctrl-c does not cut it short
+        var formatErr error
+        var count int
+        var err error
+
+        if whiskErr := checkArgs(args, 0, 0, "Activation watch", wski18n.T("No arguments are required.")); whiskErr != nil {
+            return whiskErr
+        }
+
+        options := &whisk.ActivationWatchOptions{
+            Name:  flags.activation.action,
+            Since: time.Now().UnixNano() / int64(time.Millisecond),
+        }
+
+        if len(flags.activation.pollSince) > 0 {
+            if options.Since, err = parsePollSince(flags.activation.pollSince); err != nil {
+                return err
+            }
+        }
+
+        c := make(chan os.Signal, 1)
+        signal.Notify(c, os.Interrupt)
+        signal.Notify(c, syscall.SIGTERM)
+        go func() {
+            <-c
+            printLock.Lock()
+            if len(flags.global.output) == 0 {
+                fmt.Println(wski18n.T("Watch terminated"))
+            }
+            os.Exit(0)
+        }()
+
+        if len(flags.global.output) == 0 {
+            fmt.Println(wski18n.T("Enter Ctrl-c to exit."))
+        }
+
+        _, err = client.Activations.Watch(options, func(activation whisk.Activation) bool {
+            printLock.Lock()
+            defer printLock.Unlock()
+
+            if len(flags.global.output) > 0 {
+                if formatErr = printFormatted(activation, flags.global.output); formatErr != nil {
+                    return false
+                }
+            } else {
+                fmt.Printf("%s %-20s %s\n", activation.ActivationID, activation.Name, activation.Response.Status)
+            }
+
+            count++
+            return flags.activation.exitAfter <= 0 || count < flags.activation.exitAfter
+        })
+
+        if err != nil {
+            whisk.Debug(whisk.DbgError, "client.Activations.Watch(%#v) error: %s\n", options, err)
+            errStr := wski18n.T("Unable to watch activations: {{.err}}", map[string]interface{}{"err": err})
+            werr := whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL,
+                whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+            return werr
+        }
+
+        return formatErr
+    },
+}
+
+// waitForActivation gets an activation, polling until it is recorded or the deadline passes. Polling starts every
+// 500ms and backs off exponentially up to 5s between attempts.
+func waitForActivation(activationID string, deadline time.Time) (*whisk.Activation, error) {
+    var activation *whisk.Activation
+
+    err := pollActivation(activationID, deadline, func() (*http.Response, error) {
+        var resp *http.Response
+        var err error
+
+        activation, resp, err = client.Activations.Get(activationID)
+        return resp, err
+    })
+
+    return activation, err
+}
+
+// waitForActivationResult gets the response of an activation, polling like waitForActivation until the
+// activation has completed or the deadline passes
+func waitForActivationResult(activationID string, deadline time.Time) (*whisk.Response, error) {
+    var response *whisk.Response
+
+    err := pollActivation(activationID, deadline, func() (*http.Response, error) {
+        var resp *http.Response
+        var err error
+
+        response, resp, err = client.Activations.GetResult(activationID)
+        return resp, err
+    })
+
+    return response, err
+}
+
+// pollActivation calls get until it succeeds or the deadline passes. Only a 404 response, which means the
+// activation has not completed, is retried.
+func pollActivation(activationID string, deadline time.Time, get func() (*http.Response, error)) error {
+    interval := 500 * time.Millisecond
+    maxInterval := 5 * time.Second
+
+    for {
+        resp, err := get()
+        if err == nil {
+            return nil
+        }
+
+        // The activation is not found until it has completed
+        if resp == nil || resp.StatusCode != http.StatusNotFound {
+            whisk.Debug(whisk.DbgError, "client.Activations.Get(%s) failed: %s\n", activationID, err)
+            errStr := wski18n.T("Unable to get activation '{{.id}}': {{.err}}",
+                    map[string]interface{}{"id": activationID, "err": err})
+            werr := whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL,
+                whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+            return werr
+        }
+
+        if time.Now().Add(interval).After(deadline) {
+            break
+        }
+
+        whisk.Debug(whisk.DbgInfo, "Activation '%s' has not completed; retrying in %s\n", activationID, interval)
+        time.Sleep(interval)
+        if interval *= 2; interval > maxInterval {
+            interval = maxInterval
+        }
+    }
+
+    errStr := wski18n.T("Activation '{{.id}}' did not complete in time",
+            map[string]interface{}{"id": activationID})
+    werr := whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+    return werr
+}
+
+// followActivationLogs prints the logs of an activation as they are recorded, polling every --interval seconds until
+// the activation has ended or --timeout seconds have passed. Ctrl-c stops following without an error.
+func followActivationLogs(activationID string) error {
+    if flags.activation.interval < 1 {
+        errStr := wski18n.T("The polling interval must be at least 1 second, not {{.interval}}",
+            map[string]interface{}{"interval": flags.activation.interval})
+        return whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_USAGE, whisk.DISPLAY_MSG, whisk.DISPLAY_USAGE)
+    }
+
+    stop := make(chan struct{})
+    stopped := make(chan error, 1)     // Why following was stopped: nil when interrupted, or the timeout error
+    done := make(chan struct{})
+    defer close(done)
+
+    c := make(chan os.Signal, 1)
+    signal.Notify(c, os.Interrupt)
+    signal.Notify(c, syscall.SIGTERM)
+    defer signal.Stop(c)
+
+    timeout := time.NewTimer(time.Duration(flags.activation.timeout) * time.Second)
+    defer timeout.Stop()
+
+    go func() {
+        select {
+        case <-c:
+            stopped <- nil
+        case <-timeout.C:
+            errStr := wski18n.T("Activation '{{.id}}' did not end within {{.timeout}} seconds",
+                map[string]interface{}{"id": activationID, "timeout": flags.activation.timeout})
+            stopped <- whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG,
+                whisk.NO_DISPLAY_USAGE)
+        case <-done:
+            return
+        }
+        close(stop)
+    }()
+
+    interval := time.Duration(flags.activation.interval) * time.Second
+    lines, errs, err := client.Activations.FollowActivationLogs(activationID, interval, stop)
+    if err != nil {
+        whisk.Debug(whisk.DbgError, "client.Activations.FollowActivationLogs(%s) failed: %s\n", activationID, err)
+        errStr := wski18n.T("Unable to get logs for activation '{{.id}}': {{.err}}",
+            map[string]interface{}{"id": activationID, "err": err})
+        werr := whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL,
+            whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+        return werr
+    }
+
+    for line := range lines {
+        printActivationLogs([]string{line})
+    }
+
+    if err = <-errs; err != nil {
+        whisk.Debug(whisk.DbgError, "Following the logs of activation '%s' failed: %s\n", activationID, err)
+        errStr := wski18n.T("Unable to get logs for activation '{{.id}}': {{.err}}",
+            map[string]interface{}{"id": activationID, "err": err})
+        werr := whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL,
+            whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+        return werr
+    }
+
+    select {
+    case err = <-stopped:
+        return err
+    default:
+        return nil
+    }
+}
+
+// parsePollSince converts a --since value, either milliseconds since Jan 1 1970 or a duration such as "5m" or
+// "2h" before now, into milliseconds since Jan 1 1970
+func parsePollSince(since string) (int64, error) {
+    if millis, err := strconv.ParseInt(since, 10, 64); err == nil {
+        return millis, nil
+    }
+
+    duration, err := time.ParseDuration(since)
+    if err != nil {
+        whisk.Debug(whisk.DbgError, "time.ParseDuration(%s) failure: %s\n", since, err)
+        errStr := wski18n.T("Invalid --since value '{{.since}}'; use milliseconds since Jan 1 1970 or a duration such as 5m or 2h",
+                map[string]interface{}{"since": since})
+        werr := whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_USAGE, whisk.DISPLAY_MSG, whisk.DISPLAY_USAGE)
+        return 0, werr
+    }
+
+    return time.Now().Add(-duration).UnixNano() / int64(time.Millisecond), nil
+}
+
+// parseActivationTime converts the value of an activation list time flag, given either in milliseconds since Jan 1 1970
+// or as an RFC3339 timestamp, to milliseconds since Jan 1 1970. An empty value yields 0, meaning no bound.
+func parseActivationTime(flag string, value string) (int64, error) {
+    if len(value) == 0 {
+        return 0, nil
+    }
+
+    if millis, err := strconv.ParseInt(value, 10, 64); err == nil && millis >= 0 {
+        return millis, nil
+    }
+
+    timestamp, err := time.Parse(time.RFC3339, value)
+    if err != nil {
+        whisk.Debug(whisk.DbgError, "time.Parse(%s) failure: %s\n", value, err)
+        errStr := wski18n.T("Invalid --{{.flag}} value '{{.value}}'; use milliseconds since Jan 1 1970 or an RFC3339 timestamp such as 2017-01-02T15:04:05Z",
+                map[string]interface{}{"flag": flag, "value": value})
+        werr := whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.DISPLAY_USAGE)
+        return 0, werr
+    }
+
+    return timestamp.UnixNano() / int64(time.Millisecond), nil
+}
+
+// getActivationIDArg returns the activation ID given as the only argument, or the ID of the most recent activation
+// when --last is set
+func getActivationIDArg(args []string, commandName string) (string, error) {
+    if !flags.activation.last {
+        if whiskErr := checkArgs(args, 1, 1, commandName, wski18n.T("An activation ID is required.")); whiskErr != nil {
+            return "", whiskErr
+        }
+
+        if len(flags.activation.action) > 0 {
+            return "", actionWithoutLastError()
+        }
+
+        return args[0], nil
+    }
+
+    if len(args) > 0 {
+        return "", lastActivationIDError()
+    }
+
+    return getLastActivationID()
+}
+
+// getLastActivationID returns the ID of the most recent activation in the namespace, or of the action named by
+// --action when it is set
+func getLastActivationID() (string, error) {
+    var qualifiedName QualifiedName
+    var err error
+
+    if len(flags.activation.action) > 0 {
+        if qualifiedName, err = parseQualifiedName(flags.activation.action); err != nil {
+            return "", parseQualifiedNameError(flags.activation.action, err)
+        }
+
+        client.Namespace = qualifiedName.namespace
+    }
+
+    options := &whisk.ActivationListOptions{
+        Name:  qualifiedName.entityName,
+        Limit: 1,
+    }
+
+    activations, _, err := client.Activations.List(options)
+    if err != nil {
+        whisk.Debug(whisk.DbgError, "client.Activations.List(%#v) error: %s\n", options, err)
+        errStr := wski18n.T("Unable to get the most recent activation: {{.err}}", map[string]interface{}{"err": err})
+        werr := whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_NETWORK, whisk.DISPLAY_MSG,
+            whisk.NO_DISPLAY_USAGE)
+        return "", werr
+    }
+
+    if len(activations) == 0 {
+        var errStr string
+
+        if len(qualifiedName.entityName) > 0 {
+            errStr = wski18n.T("no activations found for action {{.name}}",
+                map[string]interface{}{"name": qualifiedName.entityName})
+        } else {
+            errStr = wski18n.T("no activations found")
+        }
+        werr := whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG,
+            whisk.NO_DISPLAY_USAGE)
+        return "", werr
+    }
+
+    whisk.Debug(whisk.DbgInfo, "Most recent activation: %s\n", activations[0].ActivationID)
+
+    return activations[0].ActivationID, nil
+}
+
+func lastActivationIDError() (error) {
+    errStr := wski18n.T("An activation ID cannot be combined with --last.")
+    return whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_USAGE, whisk.DISPLAY_MSG, whisk.DISPLAY_USAGE)
+}
+
+func actionWithoutLastError() (error) {
+    errStr := wski18n.T("The --action flag requires --last.")
+    return whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_USAGE, whisk.DISPLAY_MSG, whisk.DISPLAY_USAGE)
+}
+
+// formatActivationTime formats milliseconds since Jan 1 1970 as an RFC3339 timestamp in UTC
+func formatActivationTime(millis int64) string {
+    return time.Unix(0, millis * int64(time.Millisecond)).UTC().Format(time.RFC3339)
+}
+
 func init() {
     activationListCmd.Flags().IntVarP(&flags.common.skip, "skip", "s", 0, wski18n.T("exclude the first `SKIP` number of activations from the result"))
     activationListCmd.Flags().IntVarP(&flags.common.limit, "limit", "l", 30, wski18n.T("only return `LIMIT` number of activations from the collection"))
     activationListCmd.Flags().BoolVarP(&flags.common.full, "full", "f", false, wski18n.T("include full activation description"))
-    activationListCmd.Flags().Int64Var(&flags.activation.upto, "upto", 0, wski18n.T("return activations with timestamps earlier than `UPTO`; measured in milliseconds since Th, 01, Jan 1970"))
-    activationListCmd.Flags().Int64Var(&flags.activation.since, "since", 0, wski18n.T("return activations with timestamps later than `SINCE`; measured in milliseconds since Th, 01, Jan 1970"))
+    activationListCmd.Flags().BoolVar(&flags.common.summary, "summary", false, wski18n.T("list each activation with its duration and result status"))
+    activationListCmd.Flags().StringVar(&flags.activation.upto, "upto", "", wski18n.T("return activations with timestamps earlier than `UPTO`; milliseconds since Jan 1 1970 or an RFC3339 timestamp"))
+    activationListCmd.Flags().StringVar(&flags.activation.since, "since", "", wski18n.T("return activations with timestamps later than `SINCE`; milliseconds since Jan 1 1970 or an RFC3339 timestamp"))
+
+    activationLogsCmd.Flags().BoolVarP(&flags.activation.follow, "follow", "f", false, wski18n.T("print new log lines until the activation ends"))
+    activationLogsCmd.Flags().IntVar(&flags.activation.interval, "interval", 1, wski18n.T("poll for new log lines every `SECONDS` seconds when following"))
+    activationLogsCmd.Flags().IntVar(&flags.activation.timeout, "timeout", 300, wski18n.T("stop following after `SECONDS` seconds"))
+
+    activationLogsCmd.Flags().BoolVarP(&flags.activation.last, "last", "l", false, wski18n.T("get the logs of the most recent activation"))
+    activationLogsCmd.Flags().StringVar(&flags.activation.action, "action", "", wski18n.T("with --last, only consider activations of the action `NAME`"))
 
     activationGetCmd.Flags().BoolVarP(&flags.common.summary, "summary", "s", false, wski18n.T("summarize activation details"))
+    activationGetCmd.Flags().BoolVarP(&flags.activation.last, "last", "l", false, wski18n.T("get the most recent activation"))
+    activationGetCmd.Flags().StringVar(&flags.activation.action, "action", "", wski18n.T("with --last, only consider activations of the action `NAME`"))
+
+    activationResultCmd.Flags().BoolVarP(&flags.activation.last, "last", "l", false, wski18n.T("get the result of the most recent activation"))
+    activationResultCmd.Flags().StringVar(&flags.activation.action, "action", "", wski18n.T("with --last, only consider activations of the action `NAME`"))
 
     activationPollCmd.Flags().IntVarP(&flags.activation.exit, "exit", "e", 0, wski18n.T("stop polling after `SECONDS` seconds"))
     activationPollCmd.Flags().IntVar(&flags.activation.sinceSeconds, "since-seconds", 0, wski18n.T("start polling for activations `SECONDS` seconds ago"))
     activationPollCmd.Flags().IntVar(&flags.activation.sinceMinutes, "since-minutes", 0, wski18n.T("start polling for activations `MINUTES` minutes ago"))
     activationPollCmd.Flags().IntVar(&flags.activation.sinceHours, "since-hours", 0, wski18n.T("start polling for activations `HOURS` hours ago"))
     activationPollCmd.Flags().IntVar(&flags.activation.sinceDays, "since-days", 0, wski18n.T("start polling for activations `DAYS` days ago"))
+    activationPollCmd.Flags().StringVar(&flags.activation.action, "action", "", wski18n.T("only poll for activations of the action `NAME`"))
+    activationPollCmd.Flags().StringVar(&flags.activation.pollSince, "since", "", wski18n.T("start polling for activations since `SINCE`; milliseconds since Jan 1 1970 or a duration such as 5m or 2h"))
+
+    activationWatchCmd.Flags().StringVar(&flags.activation.action, "action", "", wski18n.T("only watch for activations of the action `NAME`"))
+    activationWatchCmd.Flags().StringVar(&flags.activation.pollSince, "since", "", wski18n.T("report activations since `SINCE`; milliseconds since Jan 1 1970 or a duration such as 5m or 2h"))
+    activationWatchCmd.Flags().IntVar(&flags.activation.exitAfter, "exit-after", 0, wski18n.T("stop watching after `COUNT` activations"))
 
     activationCmd.AddCommand(
         activationListCmd,
@@ -350,5 +778,6 @@ func init() {
         activationLogsCmd,
         activationResultCmd,
         activationPollCmd,
+        activationWatchCmd,
     )
 }
@@ -0,0 +1,542 @@
+/*
+ * Copyright 2015-2016 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+    "fmt"
+    "net/http"
+    "strconv"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/spf13/cobra"
+
+    "../../go-whisk/whisk"
+)
+
+func getMillis(t time.Time) int64 {
+    return t.UnixNano() / int64(time.Millisecond)
+}
+
+func TestParsePollSince(t *testing.T) {
+    tests := []struct {
+        since   string
+        ago     time.Duration   // how long before now the result is, for durations
+        want    int64           // the result, for milliseconds
+    }{
+        {"1485212345678", 0, 1485212345678},
+        {"0", 0, 0},
+        {"5m", 5 * time.Minute, 0},
+        {"2h", 2 * time.Hour, 0},
+        {"1h30m10s", time.Hour + 30 * time.Minute + 10 * time.Second, 0},
+        {"90s", 90 * time.Second, 0},
+    }
+
+    for _, test := range tests {
+        before := getMillis(time.Now().Add(-test.ago))
+        since, err := parsePollSince(test.since)
+        after := getMillis(time.Now().Add(-test.ago))
+
+        if err != nil {
+            t.Errorf("parsePollSince(%q) failed: %s", test.since, err)
+        } else if test.ago == 0 && since != test.want {
+            t.Errorf("parsePollSince(%q) = %d, want %d", test.since, since, test.want)
+        } else if test.ago > 0 && (since < before || since > after) {
+            t.Errorf("parsePollSince(%q) = %d, want %s before now, between %d and %d", test.since, since, test.ago,
+                before, after)
+        }
+    }
+}
+
+func TestParsePollSinceInvalid(t *testing.T) {
+    for _, since := range []string{"", "yesterday", "5 minutes", "2017-01-02T15:04:05Z", "1.5"} {
+        _, err := parsePollSince(since)
+        if whiskErr, ok := err.(*whisk.WskError); !ok || whiskErr.ExitCode != whisk.EXITCODE_ERR_USAGE {
+            t.Errorf("parsePollSince(%q) error = %#v, want a usage error", since, err)
+        }
+    }
+}
+
+func TestActivationLogsFollow(t *testing.T) {
+    var requests int
+    server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+        // The activation is not recorded yet at the first request, and ends with more logs at the second
+        requests++
+        if requests == 1 {
+            writeJSON(w, http.StatusNotFound, map[string]interface{}{"error": "The requested resource does not exist.", "code": 1})
+        } else {
+            writeJSON(w, http.StatusOK, whisk.Activation{ActivationID: "12345", Start: 1, End: 2,
+                Logs: []string{"2017-01-02T15:04:05.000Z stdout: first", "2017-01-02T15:04:05.100Z stdout: second"}})
+        }
+    })
+    defer server.Close()
+    flags.activation.follow = true
+    flags.activation.interval = 1
+    flags.activation.timeout = 10
+
+    var err error
+    output := captureOutput(t, func() { err = activationLogsCmd.RunE(activationLogsCmd, []string{"12345"}) })
+    if err != nil {
+        t.Fatalf("activation logs --follow failed: %s", err)
+    }
+
+    if want := "2017-01-02T15:04:05.000Z stdout: first\n2017-01-02T15:04:05.100Z stdout: second\n"; output != want {
+        t.Errorf("activation logs --follow printed %q, want %q", output, want)
+    }
+    checkRequests(t, server, "GET _/activations/12345", "GET _/activations/12345")
+}
+
+func TestActivationLogsFollowError(t *testing.T) {
+    var requests int
+    server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+        // The activation is running at the first request, and the second request fails
+        requests++
+        if requests == 1 {
+            writeJSON(w, http.StatusOK, whisk.Activation{ActivationID: "12345", Start: 1,
+                Logs: []string{"2017-01-02T15:04:05.000Z stdout: first"}})
+        } else {
+            writeJSON(w, http.StatusInternalServerError, map[string]interface{}{"error": "get failed", "code": 1})
+        }
+    })
+    defer server.Close()
+    flags.activation.follow = true
+    flags.activation.interval = 1
+    flags.activation.timeout = 10
+
+    var err error
+    output := captureOutput(t, func() { err = activationLogsCmd.RunE(activationLogsCmd, []string{"12345"}) })
+
+    // The logs printed before the failure are kept, and the failure is not hidden behind a successful exit
+    if want := "2017-01-02T15:04:05.000Z stdout: first\n"; output != want {
+        t.Errorf("activation logs --follow printed %q, want %q", output, want)
+    }
+    want := "Unable to get logs for activation '12345': get failed (code 1)"
+    if whiskErr, ok := err.(*whisk.WskError); !ok || whiskErr.Error() != want {
+        t.Errorf("activation logs --follow with a failed request error = %#v, want %q", err, want)
+    }
+}
+
+func TestActivationLogsFollowInvalidInterval(t *testing.T) {
+    server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+        writeJSON(w, http.StatusOK, map[string]interface{}{})
+    })
+    defer server.Close()
+    flags.activation.follow = true
+
+    for _, interval := range []int{0, -1} {
+        flags.activation.interval = interval
+        err := activationLogsCmd.RunE(activationLogsCmd, []string{"12345"})
+        if whiskErr, ok := err.(*whisk.WskError); !ok || whiskErr.ExitCode != whisk.EXITCODE_ERR_USAGE {
+            t.Errorf("activation logs --follow --interval %d error = %#v, want a usage error", interval, err)
+        }
+    }
+    checkRequests(t, server)
+}
+
+func TestParseActivationTime(t *testing.T) {
+    tests := []struct {
+        value   string
+        want    int64
+    }{
+        {"", 0},
+        {"1485212345678", 1485212345678},
+        {"0", 0},
+        {"2017-01-23T23:19:05Z", 1485213545000},
+        {"2017-01-23T23:19:05.678Z", 1485213545678},
+        // The offset is applied
+        {"2017-01-24T00:19:05+01:00", 1485213545000},
+    }
+
+    for _, test := range tests {
+        if millis, err := parseActivationTime("since", test.value); err != nil {
+            t.Errorf("parseActivationTime(%q) failed: %s", test.value, err)
+        } else if millis != test.want {
+            t.Errorf("parseActivationTime(%q) = %d, want %d", test.value, millis, test.want)
+        }
+    }
+}
+
+func TestParseActivationTimeInvalid(t *testing.T) {
+    for _, value := range []string{"yesterday", "-5", "2017-01-23", "2017-01-23 23:19:05", "5m"} {
+        _, err := parseActivationTime("upto", value)
+        if err == nil || !strings.Contains(err.Error(), "--upto") {
+            t.Errorf("parseActivationTime(%q) error = %v, want an error about --upto", value, err)
+        }
+        if whiskErr, ok := err.(*whisk.WskError); !ok || whiskErr.ExitCode != whisk.EXITCODE_ERR_GENERAL {
+            t.Errorf("parseActivationTime(%q) error = %#v, want a WskError with exit code %d", value, err,
+                whisk.EXITCODE_ERR_GENERAL)
+        }
+    }
+}
+
+func TestParseActivationTimeZones(t *testing.T) {
+    // The same instant in any time zone is the same number of milliseconds
+    for _, value := range []string{"2017-01-23T23:19:05Z", "2017-01-23T23:19:05+00:00", "2017-01-23T18:19:05-05:00",
+            "2017-01-24T04:49:05+05:30", "2017-01-24T08:19:05.000+09:00"} {
+        if millis, err := parseActivationTime("since", value); err != nil || millis != 1485213545000 {
+            t.Errorf("parseActivationTime(%q) = %d, %v; want 1485213545000", value, millis, err)
+        }
+    }
+}
+
+func TestFormatActivationTime(t *testing.T) {
+    tests := []struct {
+        millis  int64
+        want    string
+    }{
+        {0, "1970-01-01T00:00:00Z"},
+        {1485213545000, "2017-01-23T23:19:05Z"},
+        // Milliseconds are left out
+        {1485213545678, "2017-01-23T23:19:05Z"},
+    }
+
+    for _, test := range tests {
+        if value := formatActivationTime(test.millis); value != test.want {
+            t.Errorf("formatActivationTime(%d) = %q, want %q", test.millis, value, test.want)
+        }
+    }
+}
+
+func TestActivationListTimeBounds(t *testing.T) {
+    tests := []struct {
+        since   string
+        upto    string
+        full    bool
+        query   string
+    }{
+        {"1485212345678", "2017-01-23T23:19:05Z", false, "limit=30&since=1485212345678&skip=0&upto=1485213545000"},
+        {"2017-01-23T23:19:05Z", "", true, "docs=true&limit=30&since=1485213545000&skip=0"},
+        {"", "", false, "limit=30&skip=0"},
+    }
+
+    for _, test := range tests {
+        server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+            writeJSON(w, http.StatusOK, []whisk.Activation{})
+        })
+        flags.common.limit = 30
+        flags.common.full = test.full
+        flags.activation.since, flags.activation.upto = test.since, test.upto
+
+        var err error
+        captureOutput(t, func() { err = activationListCmd.RunE(activationListCmd, []string{}) })
+        server.Close()
+
+        if err != nil {
+            t.Errorf("activation list --since %q --upto %q failed: %s", test.since, test.upto, err)
+            continue
+        }
+        checkRequests(t, server, "GET _/activations")
+        if query := server.requests[0].Query.Encode(); query != test.query {
+            t.Errorf("activation list --since %q --upto %q sent the query %s, want %s", test.since, test.upto,
+                query, test.query)
+        }
+    }
+}
+
+func TestActivationListSinceAfterUpto(t *testing.T) {
+    server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+        writeJSON(w, http.StatusOK, []whisk.Activation{})
+    })
+    defer server.Close()
+    flags.activation.since, flags.activation.upto = "2017-01-23T23:19:05Z", "1485212345678"
+
+    err := activationListCmd.RunE(activationListCmd, []string{})
+    if whiskErr, ok := err.(*whisk.WskError); !ok || whiskErr.ExitCode != whisk.EXITCODE_ERR_USAGE {
+        t.Errorf("activation list with --since later than --upto error = %#v, want a usage error", err)
+    }
+    checkRequests(t, server)
+}
+
+func TestActivationListSummary(t *testing.T) {
+    server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+        activations := []whisk.Activation{
+            {ActivationID: "1", Name: "a", Start: 1000, End: 1250},
+            {ActivationID: "2", Name: "b", Duration: 40},
+        }
+        activations[0].Response.Status = "success"
+        activations[1].Response.Status = "application error"
+        writeJSON(w, http.StatusOK, activations)
+    })
+    defer server.Close()
+    flags.common.summary = true
+
+    var err error
+    output := captureOutput(t, func() { err = activationListCmd.RunE(activationListCmd, []string{}) })
+    if err != nil {
+        t.Fatalf("activation list --summary failed: %s", err)
+    }
+    if request := server.getRequest("GET", "_/activations"); request == nil || request.Query.Get("docs") != "true" {
+        t.Errorf("activation list --summary did not request full documents")
+    }
+
+    want := "activations\n" +
+        "1 a                         250ms success\n" +
+        "2 b                          40ms application error\n"
+    if output != want {
+        t.Errorf("activation list --summary printed:\n%s\nwant:\n%s", output, want)
+    }
+}
+
+func TestActivationListTimeRange(t *testing.T) {
+    tests := []struct {
+        since   string
+        upto    string
+        output  string
+        want    string
+    }{
+        // The range is shown in UTC when both ends are set
+        {"2017-01-23T18:19:05-05:00", "2017-01-24T01:00:00+01:00", "",
+            "activations from 2017-01-23T23:19:05Z to 2017-01-24T00:00:00Z\nactivations\n"},
+        {"1485212345678", "1485213545000", "", "activations from 2017-01-23T22:59:05Z to 2017-01-23T23:19:05Z\nactivations\n"},
+        {"2017-01-23T23:19:05Z", "", "", "activations\n"},
+        {"", "2017-01-23T23:19:05Z", "", "activations\n"},
+        // Formatted output is left as it is
+        {"2017-01-23T18:19:05-05:00", "2017-01-24T01:00:00+01:00", "json", "[]\n"},
+    }
+
+    for _, test := range tests {
+        server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+            writeJSON(w, http.StatusOK, []whisk.Activation{})
+        })
+        flags.common.limit = 30
+        flags.global.output = test.output
+        flags.activation.since, flags.activation.upto = test.since, test.upto
+
+        var err error
+        stdout := captureOutput(t, func() { err = activationListCmd.RunE(activationListCmd, []string{}) })
+        server.Close()
+
+        if err != nil {
+            t.Errorf("activation list --since %q --upto %q failed: %s", test.since, test.upto, err)
+        } else if stdout != test.want {
+            t.Errorf("activation list --since %q --upto %q --output %q printed %q, want %q", test.since, test.upto,
+                test.output, stdout, test.want)
+        }
+    }
+}
+
+func TestActivationListInvalidTime(t *testing.T) {
+    for _, bounds := range [][]string{{"yesterday", ""}, {"", "2017-01-23"}, {"1485212345678", "noon"}} {
+        server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+            writeJSON(w, http.StatusOK, []whisk.Activation{})
+        })
+        flags.activation.since, flags.activation.upto = bounds[0], bounds[1]
+
+        err := activationListCmd.RunE(activationListCmd, []string{})
+        checkRequests(t, server)
+        server.Close()
+
+        if whiskErr, ok := err.(*whisk.WskError); !ok || whiskErr.ExitCode != whisk.EXITCODE_ERR_GENERAL {
+            t.Errorf("activation list --since %q --upto %q error = %#v, want a WskError with exit code %d", bounds[0],
+                bounds[1], err, whisk.EXITCODE_ERR_GENERAL)
+        }
+    }
+}
+
+// lastActivationHandler answers activation lists in any namespace with the most recent of activations, filtered by the
+// name query parameter, and gets of an activation, its result and its logs
+func lastActivationHandler(activations []whisk.Activation) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        path := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v1/namespaces/"), "/")
+
+        if len(path) == 2 {
+            matched := []whisk.Activation{}
+            for _, activation := range activations {
+                if name := r.URL.Query().Get("name"); len(name) == 0 || activation.Name == name {
+                    matched = append(matched, activation)
+                }
+            }
+            if limit, _ := strconv.Atoi(r.URL.Query().Get("limit")); limit > 0 && len(matched) > limit {
+                matched = matched[:limit]
+            }
+            writeJSON(w, http.StatusOK, matched)
+        } else if len(path) == 4 && path[3] == "result" {
+            writeJSON(w, http.StatusOK, map[string]interface{}{"result": map[string]interface{}{"id": path[2]}})
+        } else if len(path) == 4 && path[3] == "logs" {
+            writeJSON(w, http.StatusOK, map[string]interface{}{"logs": []string{"log of " + path[2]}})
+        } else {
+            writeJSON(w, http.StatusOK, whisk.Activation{ActivationID: path[2], Name: "a"})
+        }
+    }
+}
+
+func TestActivationLast(t *testing.T) {
+    // Activations are listed most recent first
+    activations := []whisk.Activation{{ActivationID: "3", Name: "b"}, {ActivationID: "2", Name: "a"}, {ActivationID: "1", Name: "a"}}
+
+    tests := []struct {
+        cmd         *cobra.Command
+        args        []string
+        action      string
+        requests    []string
+        stdout      string
+    }{
+        {activationResultCmd, nil, "", []string{"GET _/activations", "GET _/activations/3/result"}, "{\n    \"id\": \"3\"\n}\n"},
+        {activationLogsCmd, nil, "", []string{"GET _/activations", "GET _/activations/3/logs"}, "log of 3\n"},
+        {activationGetCmd, nil, "", []string{"GET _/activations", "GET _/activations/3"}, "ok: got activation 3\n"},
+        {activationGetCmd, []string{"name"}, "", []string{"GET _/activations", "GET _/activations/3"},
+            "ok: got activation 3, displaying field name\n"},
+        // --action picks the most recent activation of an action; activations are in the namespace of the credentials
+        {activationResultCmd, nil, "/ns/a", []string{"GET _/activations", "GET _/activations/2/result"}, "{\n    \"id\": \"2\"\n}\n"},
+        {activationLogsCmd, nil, "a", []string{"GET _/activations", "GET _/activations/2/logs"}, "log of 2\n"},
+    }
+
+    for _, test := range tests {
+        server := newTestServer(t, lastActivationHandler(activations))
+        flags.activation.last, flags.activation.action = true, test.action
+
+        var err error
+        stdout := captureOutput(t, func() { err = test.cmd.RunE(test.cmd, test.args) })
+        checkRequests(t, server, test.requests...)
+        list := server.requests[0].Query
+        server.Close()
+
+        description := fmt.Sprintf("%s --last %q --action %q", test.cmd.CommandPath(), test.args, test.action)
+        if err != nil {
+            t.Errorf("%s failed: %s", description, err)
+            continue
+        }
+        if !strings.HasPrefix(stdout, test.stdout) {
+            t.Errorf("%s printed %q, want it to start with %q", description, stdout, test.stdout)
+        }
+        if list.Get("limit") != "1" || list.Get("name") != strings.TrimPrefix(test.action, "/ns/") {
+            t.Errorf("%s listed the activations with the query %s, want limit=1 and the name of the action",
+                description, list.Encode())
+        }
+    }
+}
+
+func TestActivationLastNotFound(t *testing.T) {
+    tests := []struct {
+        activations []whisk.Activation
+        action      string
+        want        string
+    }{
+        {nil, "", "no activations found"},
+        {nil, "/ns/a", "no activations found for action a"},
+        {[]whisk.Activation{{ActivationID: "3", Name: "b"}}, "a", "no activations found for action a"},
+    }
+
+    for _, test := range tests {
+        for _, cmd := range []*cobra.Command{activationGetCmd, activationResultCmd, activationLogsCmd} {
+            server := newTestServer(t, lastActivationHandler(test.activations))
+            flags.activation.last, flags.activation.action = true, test.action
+
+            err := cmd.RunE(cmd, []string{})
+            if requests := server.getRequests(); len(requests) != 1 || !strings.HasSuffix(requests[0], "/activations") {
+                t.Errorf("%s --last sent the requests %q, want only the activation list", cmd.CommandPath(), requests)
+            }
+            server.Close()
+
+            if whiskErr, ok := err.(*whisk.WskError); !ok || whiskErr.Error() != test.want ||
+                    whiskErr.ExitCode != whisk.EXITCODE_ERR_GENERAL {
+                t.Errorf("%s --last --action %q of %d activations error = %#v, want %q", cmd.CommandPath(), test.action,
+                    len(test.activations), err, test.want)
+            }
+        }
+    }
+}
+
+func TestActivationLastConflicts(t *testing.T) {
+    tests := []struct {
+        cmd     *cobra.Command
+        args    []string
+        last    bool
+        action  string
+        want    string
+    }{
+        {activationResultCmd, []string{"12345"}, true, "", "An activation ID cannot be combined with --last."},
+        {activationLogsCmd, []string{"12345"}, true, "", "An activation ID cannot be combined with --last."},
+        {activationGetCmd, []string{"12345"}, true, "", "An activation ID cannot be combined with --last."},
+        {activationResultCmd, []string{"12345"}, false, "a", "The --action flag requires --last."},
+        {activationGetCmd, []string{"12345"}, false, "a", "The --action flag requires --last."},
+    }
+
+    for _, test := range tests {
+        server := newTestServer(t, lastActivationHandler(nil))
+        flags.activation.last, flags.activation.action = test.last, test.action
+
+        err := test.cmd.RunE(test.cmd, test.args)
+        checkRequests(t, server)
+        server.Close()
+
+        if whiskErr, ok := err.(*whisk.WskError); !ok || whiskErr.Error() != test.want ||
+                whiskErr.ExitCode != whisk.EXITCODE_ERR_USAGE {
+            t.Errorf("%s %q --last=%t --action %q error = %#v, want the usage error %q", test.cmd.CommandPath(),
+                test.args, test.last, test.action, err, test.want)
+        }
+    }
+}
+
+func TestActivationWatch(t *testing.T) {
+    activations := []whisk.Activation{
+        {Namespace: "ns", Name: "b", ActivationID: "id3", Start: 3000, Response: whisk.Response{Status: "success"}},
+        {Namespace: "ns", Name: "a", ActivationID: "id2", Start: 2000, Response: whisk.Response{Status: "application error"}},
+        {Namespace: "ns", Name: "a", ActivationID: "id1", Start: 1000, Response: whisk.Response{Status: "success"}},
+    }
+    tests := []struct {
+        action      string
+        exitAfter   int
+        want        string
+    }{
+        // Activations are printed oldest first
+        {"", 2, "id1 a                    success\nid2 a                    application error\n"},
+        {"b", 1, "id3 b                    success\n"},
+    }
+
+    for _, test := range tests {
+        server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+            writeJSON(w, http.StatusOK, activations)
+        })
+        flags.activation.action = test.action
+        flags.activation.exitAfter = test.exitAfter
+        flags.activation.pollSince = "1000"
+
+        var err error
+        output := captureOutput(t, func() { err = activationWatchCmd.RunE(activationWatchCmd, []string{}) })
+        request := server.getRequest("GET", "_/activations")
+        server.Close()
+
+        if err != nil {
+            t.Errorf("activation watch --action %q failed: %s", test.action, err)
+            continue
+        }
+        if want := "Enter Ctrl-c to exit.\n" + test.want; output != want {
+            t.Errorf("activation watch --action %q printed %q, want %q", test.action, output, want)
+        }
+        if request == nil || request.Query.Get("since") != "1000" || request.Query.Get("name") != test.action {
+            t.Errorf("activation watch --action %q sent the request %#v, want since 1000 and the action name",
+                test.action, request)
+        }
+    }
+}
+
+func TestActivationWatchError(t *testing.T) {
+    server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+        writeJSON(w, http.StatusForbidden, map[string]interface{}{"error": "not allowed", "code": 1})
+    })
+    defer server.Close()
+    flags.activation.pollSince = "1000"
+
+    var err error
+    captureOutput(t, func() { err = activationWatchCmd.RunE(activationWatchCmd, []string{}) })
+
+    if want := "Unable to watch activations: not allowed (code 1)"; err == nil || err.Error() != want {
+        t.Errorf("activation watch with a forbidden list error = %v, want %q", err, want)
+    }
+}
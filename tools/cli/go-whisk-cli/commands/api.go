@@ -22,6 +22,7 @@ import (
     "errors"
     "fmt"
     "reflect"
+    "sort"
     "strconv"
     "strings"
 
@@ -796,6 +797,14 @@ var apiCreateCmdV2 = &cobra.Command{
                     whisk.DISPLAY_MSG, whisk.DISPLAY_USAGE)
                 return whiskErr
             }
+
+            // Confirm that every action referenced by the swagger file is a web-action
+            err = verifySwaggerWebActions(api.Swagger)
+            if err != nil {
+                whisk.Debug(whisk.DbgError, "verifySwaggerWebActions() error: %s\n", err)
+                whiskErr := whisk.MakeWskError(err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.DISPLAY_USAGE)
+                return whiskErr
+            }
         } else {
             if whiskErr := checkArgs(args, 3, 4, "Api create",
                 wski18n.T("Specify a swagger file or specify an API base path with an API path, an API verb, and an action name.")); whiskErr != nil {
@@ -1500,6 +1509,51 @@ func parseSwaggerApiV2() (*whisk.Api, error) {
     return api, nil
 }
 
+// verifySwaggerWebActions checks that the action of every swagger operation with an x-openwhisk extension is a
+// web-action. The paths and operations are checked in sorted order, so the same error is always reported first.
+func verifySwaggerWebActions(swagger string) error {
+    swaggerObj := new(whisk.ApiSwaggerV2)
+    if err := json.Unmarshal([]byte(swagger), swaggerObj); err != nil {
+        whisk.Debug(whisk.DbgError, "JSON parse of swagger error: %s\n", err)
+        return err
+    }
+
+    var paths []string
+    for path := range swaggerObj.Paths {
+        paths = append(paths, path)
+    }
+    sort.Strings(paths)
+
+    for _, path := range paths {
+        var ops []string
+        for op := range swaggerObj.Paths[path] {
+            ops = append(ops, op)
+        }
+        sort.Strings(ops)
+
+        for _, op := range ops {
+            opv := swaggerObj.Paths[path][op]
+            if opv == nil || opv.XOpenWhisk == nil {
+                continue
+            }
+
+            qname := QualifiedName{namespace: opv.XOpenWhisk.Namespace, entityName: opv.XOpenWhisk.ActionName}
+            if len(qname.namespace) == 0 {
+                qname.namespace = client.Config.Namespace
+            }
+            if len(opv.XOpenWhisk.Package) > 0 {
+                qname.entityName = opv.XOpenWhisk.Package + "/" + opv.XOpenWhisk.ActionName
+            }
+
+            if err := isWebAction(client, qname); err != nil {
+                return err
+            }
+        }
+    }
+
+    return nil
+}
+
 func getAccessToken() (string, error) {
     var token string = "DUMMY TOKEN"
     var err error
@@ -0,0 +1,363 @@
+/*
+ * Copyright 2015-2016 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+
+package commands
+
+import (
+    "encoding/json"
+    "net/http"
+    "net/url"
+    "os"
+    "path/filepath"
+    "reflect"
+    "sort"
+    "strings"
+    "testing"
+
+    "../../go-whisk/whisk"
+)
+
+const apiTestRoute = "/api/v1/web/whisk.system/apimgmt/"
+
+// setApiTestProps points the properties file at a new one with an authorization key of the space guid and an API
+// gateway access token, and returns a function that restores the properties file and removes the new one
+func setApiTestProps(t *testing.T) func() {
+    file := writeTestFile(t, "wskprops", "AUTH=guid:key\nAPIGW_ACCESS_TOKEN=token\n")
+    origPropsFile := Properties.PropsFile
+    Properties.PropsFile = file
+
+    return func() {
+        Properties.PropsFile = origPropsFile
+        os.RemoveAll(filepath.Dir(file))
+    }
+}
+
+// getTestApiV2 returns an API at base path /base with a get and a post operation on /path, and a get on /other
+func getTestApiV2() *whisk.RetApiV2 {
+    operation := func(id string, action string) *whisk.ApiSwaggerOperationV2 {
+        return &whisk.ApiSwaggerOperationV2{OperationId: id,
+            XOpenWhisk: &whisk.ApiSwaggerOpXOpenWhiskV2{ActionName: action, Namespace: "ns", Package: "pkg",
+                ApiUrl: "https://host/api/v1/web/ns/pkg/" + action + ".http"}}
+    }
+
+    return &whisk.RetApiV2{
+        Namespace: "ns",
+        BaseUrl:   "https://gw/api/guid/base",
+        Swagger: &whisk.ApiSwaggerV2{
+            SwaggerName: "2.0",
+            BasePath:    "/base",
+            Info:        &whisk.ApiSwaggerInfo{Title: "base", Version: "1.0"},
+            Paths: map[string]map[string]*whisk.ApiSwaggerOperationV2{
+                "/path":  {"get": operation("getPath", "a"), "post": operation("postPath", "b")},
+                "/other": {"get": operation("getOther", "c")},
+            },
+        },
+    }
+}
+
+// apiHandler answers the API gateway routes with the given API, and action gets with an action whose web-export
+// annotation has the value in webExport, or 404 for actions not in webExport. A nil value leaves the annotation out.
+func apiHandler(api *whisk.RetApiV2, webExport map[string]interface{}) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        switch r.URL.Path {
+        case apiTestRoute + "createApi.http":
+            writeJSON(w, http.StatusOK, api)
+        case apiTestRoute + "getApi.http":
+            writeJSON(w, http.StatusOK, whisk.RetApiArrayV2{Apis: []whisk.ApiItemV2{{ApiId: "API:ns:/base",
+                ApiValue: api}}})
+        case apiTestRoute + "deleteApi.http":
+            writeJSON(w, http.StatusOK, map[string]interface{}{})
+        default:
+            name := strings.TrimPrefix(r.URL.Path, "/api/v1/namespaces/")
+            value, found := webExport[name]
+            if !found {
+                writeJSON(w, http.StatusNotFound, map[string]interface{}{"error": "The requested resource does not exist.", "code": 1})
+                return
+            }
+
+            action := whisk.Action{Namespace: "ns", Name: "a"}
+            if value != nil {
+                action.Annotations = whisk.KeyValueArr{{Key: "web-export", Value: value}}
+            }
+            writeJSON(w, http.StatusOK, action)
+        }
+    }
+}
+
+func TestApiCreate(t *testing.T) {
+    defer setApiTestProps(t)()
+
+    created := getTestApiV2()
+    delete(created.Swagger.Paths, "/other")
+    delete(created.Swagger.Paths["/path"], "post")
+    server := newTestServer(t, apiHandler(created, map[string]interface{}{"ns/actions/pkg/a": true}))
+    defer server.Close()
+    flags.api.resptype = "http"
+
+    var err error
+    output := captureOutput(t, func() {
+        err = apiCreateCmdV2.RunE(apiCreateCmdV2, []string{"/base", "/path", "get", "/ns/pkg/a"})
+    })
+    if err != nil {
+        t.Fatalf("api create failed: %s", err)
+    }
+    checkRequests(t, server, "GET ns/actions/pkg/a", "POST " + apiTestRoute + "createApi.http")
+
+    if want := "ok: created API /base/path GET for action /ns/pkg/a\nhttps://gw/api/guid/base/path\n"; output != want {
+        t.Errorf("api create printed %q, want %q", output, want)
+    }
+
+    request := server.getRequest("POST", apiTestRoute + "createApi.http")
+    wantQuery := url.Values{"spaceguid": {"guid"}, "accesstoken": {"token"}, "responsetype": {"http"}}
+    if !reflect.DeepEqual(request.Query, wantQuery) {
+        t.Errorf("api create sent the query %s, want %s", request.Query.Encode(), wantQuery.Encode())
+    }
+
+    var body whisk.ApiCreateRequest
+    json.Unmarshal([]byte(request.Body), &body)
+    api := body.ApiDoc
+    if api == nil || api.GatewayBasePath != "/base" || api.GatewayRelPath != "/path" || api.GatewayMethod != "GET" ||
+            api.Id != "API:ns:/base" || api.Action == nil || api.Action.Name != "pkg/a" || api.Action.Namespace != "ns" ||
+            api.Action.BackendMethod != "GET" || !strings.HasSuffix(api.Action.BackendUrl, "/api/v1/web/ns/pkg/a.http") {
+        t.Errorf("api create sent %s", request.Body)
+    }
+}
+
+func TestApiCreateNotWebAction(t *testing.T) {
+    defer setApiTestProps(t)()
+
+    webExport := map[string]interface{}{"ns/actions/pkg/a": true, "ns/actions/off": false, "ns/actions/plain": nil,
+        "ns/actions/text": "true"}
+    tests := []struct {
+        action  string
+        want    string
+    }{
+        {"/ns/off", "API action '/ns/off' is not a web action. Issue 'wsk action update /ns/off --web true' to convert the action to a web action."},
+        {"/ns/plain", "API action '/ns/plain' is not a web action. Issue 'wsk action update /ns/plain --web true' to convert the action to a web action."},
+        {"/ns/text", "API action '/ns/text' is not a web action. Issue 'wsk action update /ns/text --web true' to convert the action to a web action."},
+        {"/ns/missing", "API action '/ns/missing' does not exist"},
+    }
+
+    for _, test := range tests {
+        server := newTestServer(t, apiHandler(getTestApiV2(), webExport))
+
+        err := apiCreateCmdV2.RunE(apiCreateCmdV2, []string{"/base", "/path", "get", test.action})
+        checkRequests(t, server, "GET " + strings.Replace(test.action[1:], "/", "/actions/", 1))
+        server.Close()
+
+        if whiskErr, ok := err.(*whisk.WskError); !ok || whiskErr.Error() != test.want ||
+                whiskErr.ExitCode != whisk.EXITCODE_ERR_GENERAL {
+            t.Errorf("api create for action %s error = %#v, want %q", test.action, err, test.want)
+        }
+    }
+}
+
+func TestApiCreateSwaggerNotWebAction(t *testing.T) {
+    defer setApiTestProps(t)()
+
+    api := getTestApiV2()
+    swagger, _ := json.Marshal(api.Swagger)
+    file := writeTestFile(t, "swagger.json", string(swagger))
+    defer os.RemoveAll(filepath.Dir(file))
+
+    tests := []struct {
+        webExport   map[string]interface{}
+        want        string
+        requests    []string
+    }{
+        // The operations are checked in order of their path and verb
+        {map[string]interface{}{"ns/actions/pkg/a": true, "ns/actions/pkg/b": false, "ns/actions/pkg/c": true},
+            "API action '/ns/pkg/b' is not a web action. Issue 'wsk action update /ns/pkg/b --web true' to convert the action to a web action.",
+            []string{"GET ns/actions/pkg/c", "GET ns/actions/pkg/a", "GET ns/actions/pkg/b"}},
+        {map[string]interface{}{"ns/actions/pkg/a": true, "ns/actions/pkg/b": true},
+            "API action '/ns/pkg/c' does not exist",
+            []string{"GET ns/actions/pkg/c"}},
+        {map[string]interface{}{"ns/actions/pkg/a": true, "ns/actions/pkg/b": true, "ns/actions/pkg/c": true}, "",
+            []string{"GET ns/actions/pkg/c", "GET ns/actions/pkg/a", "GET ns/actions/pkg/b",
+                "POST " + apiTestRoute + "createApi.http"}},
+    }
+
+    for _, test := range tests {
+        server := newTestServer(t, apiHandler(api, test.webExport))
+        flags.api.configfile = file
+
+        var err error
+        output := captureOutput(t, func() { err = apiCreateCmdV2.RunE(apiCreateCmdV2, []string{}) })
+        checkRequests(t, server, test.requests...)
+        server.Close()
+
+        if len(test.want) == 0 && err != nil {
+            t.Errorf("api create --config-file failed: %s", err)
+        } else if len(test.want) == 0 && strings.Count(output, "ok: created API") != 3 {
+            t.Errorf("api create --config-file printed:\n%s\nwant three created operations", output)
+        } else if len(test.want) > 0 && (err == nil || err.Error() != test.want) {
+            t.Errorf("api create --config-file error = %v, want %q", err, test.want)
+        }
+    }
+}
+
+func TestApiGet(t *testing.T) {
+    defer setApiTestProps(t)()
+
+    for _, full := range []bool{false, true} {
+        server := newTestServer(t, apiHandler(getTestApiV2(), nil))
+        flags.common.detail = full
+
+        var err error
+        output := captureOutput(t, func() { err = apiGetCmdV2.RunE(apiGetCmdV2, []string{"/base"}) })
+        request := server.getRequest("GET", apiTestRoute + "getApi.http")
+        server.Close()
+
+        if err != nil {
+            t.Errorf("api get --full=%t failed: %s", full, err)
+            continue
+        }
+        if request == nil || request.Query.Get("basepath") != "/base" {
+            t.Errorf("api get --full=%t sent %#v, want a get of base path /base", full, request)
+        }
+
+        // Only the full API has the gateway URL
+        var got map[string]interface{}
+        if json.Unmarshal([]byte(output), &got); full && got["gwApiUrl"] != "https://gw/api/guid/base" {
+            t.Errorf("api get --full printed:\n%s\nwant the whole API", output)
+        } else if !full && got["basePath"] != "/base" {
+            t.Errorf("api get printed:\n%s\nwant the swagger document", output)
+        }
+    }
+}
+
+// getApiListRows returns the action, verb and URL of each row printed by api list, in sorted order
+func getApiListRows(output string) []string {
+    var rows []string
+    for _, line := range strings.Split(strings.TrimSpace(output), "\n")[2:] {
+        fields := strings.Fields(line)
+        rows = append(rows, strings.Join([]string{fields[0], fields[1], fields[len(fields) - 1]}, " "))
+    }
+    sort.Strings(rows)
+
+    return rows
+}
+
+func TestApiListFilter(t *testing.T) {
+    defer setApiTestProps(t)()
+
+    tests := []struct {
+        args    []string
+        query   url.Values
+        rows    []string
+    }{
+        {[]string{},
+            url.Values{"limit": {"30"}, "skip": {"0"}, "spaceguid": {"guid"}, "accesstoken": {"token"}},
+            []string{"/ns/pkg/a get https://gw/api/guid/base/path", "/ns/pkg/b post https://gw/api/guid/base/path",
+                "/ns/pkg/c get https://gw/api/guid/base/other"}},
+        {[]string{"/base"},
+            url.Values{"basepath": {"/base"}, "spaceguid": {"guid"}, "accesstoken": {"token"}},
+            []string{"/ns/pkg/a get https://gw/api/guid/base/path", "/ns/pkg/b post https://gw/api/guid/base/path",
+                "/ns/pkg/c get https://gw/api/guid/base/other"}},
+        // Only the operations of the given path are listed, even when the gateway answers with the whole API
+        {[]string{"/base", "/path"},
+            url.Values{"basepath": {"/base"}, "relpath": {"/path"}, "spaceguid": {"guid"}, "accesstoken": {"token"}},
+            []string{"/ns/pkg/a get https://gw/api/guid/base/path", "/ns/pkg/b post https://gw/api/guid/base/path"}},
+        {[]string{"/base", "/path", "post"},
+            url.Values{"basepath": {"/base"}, "relpath": {"/path"}, "operation": {"POST"}, "spaceguid": {"guid"},
+                "accesstoken": {"token"}},
+            []string{"/ns/pkg/b post https://gw/api/guid/base/path"}},
+        {[]string{"/base", "/other", "post"},
+            url.Values{"basepath": {"/base"}, "relpath": {"/other"}, "operation": {"POST"}, "spaceguid": {"guid"},
+                "accesstoken": {"token"}},
+            nil},
+    }
+
+    for _, test := range tests {
+        server := newTestServer(t, apiHandler(getTestApiV2(), nil))
+        flags.common.limit = 30
+
+        var err error
+        output := captureOutput(t, func() { err = apiListCmdV2.RunE(apiListCmdV2, test.args) })
+        request := server.getRequest("GET", apiTestRoute + "getApi.http")
+        server.Close()
+
+        if err != nil {
+            t.Errorf("api list %v failed: %s", test.args, err)
+            continue
+        }
+        if request == nil || !reflect.DeepEqual(request.Query, test.query) {
+            t.Errorf("api list %v sent %#v, want a get with the query %s", test.args, request, test.query.Encode())
+        }
+        if !strings.HasPrefix(output, "ok: APIs\nAction ") {
+            t.Errorf("api list %v printed:\n%s\nwant a table", test.args, output)
+        } else if rows := getApiListRows(output); !reflect.DeepEqual(rows, test.rows) {
+            t.Errorf("api list %v listed %q, want %q", test.args, rows, test.rows)
+        }
+    }
+}
+
+func TestApiListInvalidFilter(t *testing.T) {
+    defer setApiTestProps(t)()
+
+    for _, args := range [][]string{{"/base", "path"}, {"/base", "/path", "fetch"}, {"/base", "/path", "get", "x"}} {
+        server := newTestServer(t, apiHandler(getTestApiV2(), nil))
+        err := apiListCmdV2.RunE(apiListCmdV2, args)
+        checkRequests(t, server)
+        server.Close()
+
+        if err == nil {
+            t.Errorf("api list %v succeeded, want an error", args)
+        }
+    }
+}
+
+func TestApiDelete(t *testing.T) {
+    defer setApiTestProps(t)()
+
+    tests := []struct {
+        args    []string
+        query   url.Values
+        output  string
+    }{
+        {[]string{"/base"}, url.Values{"basepath": {"/base"}}, "ok: deleted API /base\n"},
+        {[]string{"base"}, url.Values{"basepath": {"base"}}, "ok: deleted API base\n"},
+        {[]string{"/base", "/path"}, url.Values{"basepath": {"/base"}, "relpath": {"/path"}},
+            "ok: deleted /path from /base\n"},
+        {[]string{"/base", "/path", "post"}, url.Values{"basepath": {"/base"}, "relpath": {"/path"}, "operation": {"POST"}},
+            "ok: deleted /path POST from /base\n"},
+    }
+
+    for _, test := range tests {
+        server := newTestServer(t, apiHandler(getTestApiV2(), nil))
+
+        var err error
+        output := captureOutput(t, func() { err = apiDeleteCmdV2.RunE(apiDeleteCmdV2, test.args) })
+        checkRequests(t, server, "DELETE " + apiTestRoute + "deleteApi.http")
+        request := server.getRequest("DELETE", apiTestRoute + "deleteApi.http")
+        server.Close()
+
+        if err != nil {
+            t.Errorf("api delete %v failed: %s", test.args, err)
+            continue
+        }
+
+        test.query.Set("spaceguid", "guid")
+        test.query.Set("accesstoken", "token")
+        if !reflect.DeepEqual(request.Query, test.query) {
+            t.Errorf("api delete %v sent the query %s, want %s", test.args, request.Query.Encode(), test.query.Encode())
+        }
+        if output != test.output {
+            t.Errorf("api delete %v printed %q, want %q", test.args, output, test.output)
+        }
+    }
+}
@@ -17,14 +17,18 @@
 package commands
 
 import (
+    "crypto/tls"
     "errors"
     "fmt"
-    "net/http"
     "os"
+    "strings"
+    "time"
 
     "../../go-whisk/whisk"
     "../wski18n"
 
+    "github.com/fatih/color"
+    "github.com/mattn/go-colorable"
     "github.com/spf13/cobra"
 )
 
@@ -39,7 +43,8 @@ func setupClientConfig(cmd *cobra.Command, args []string) (error){
       flags.property.apihost || flags.property.namespace || flags.property.apiversion || flags.property.cliversion)) ||
       (cmd.Parent().Name() == "property" && cmd.Name() == "set" && (len(flags.property.apihostSet) > 0 ||
         len(flags.property.apiversionSet) > 0 || len(flags.global.auth) > 0)) ||
-      (cmd.Parent().Name() == "sdk" && cmd.Name() == "install" && len(args) > 0 && args[0] == "bashauto")
+      (cmd.Parent().Name() == "sdk" && cmd.Name() == "install" && len(args) > 0 && args[0] == "bashauto") ||
+      (cmd.Parent().Name() == "property" && cmd.Name() == "get" && flags.property.profiles)
 
     // Display an error if the parent command requires an API host to be set, and the current API host is not valid
     if err != nil && !apiHostRequired {
@@ -50,6 +55,24 @@ func setupClientConfig(cmd *cobra.Command, args []string) (error){
         return whiskErr
     }
 
+    tlsConfig, err := getTLSConfig()
+    if err != nil {
+        return err
+    }
+
+    // --tls-ca and the CERT property both name certificate authorities trusted along with the system ones; a file
+    // named with --tls-ca is used instead of the CERT property
+    caFile := Properties.Cert
+    if len(flags.global.tlsCA) > 0 {
+        caFile = flags.global.tlsCA
+    }
+
+    if flags.global.insecure && baseURL != nil && baseURL.Scheme == "https" {
+        fmt.Fprintf(colorable.NewColorableStderr(),
+            wski18n.T("{{.warning}} certificate checking is disabled; the identity of {{.host}} is not verified\n",
+                map[string]interface{}{"warning": color.YellowString("warning:"), "host": baseURL.Host}))
+    }
+
     clientConfig := &whisk.Config{
         AuthToken:  Properties.Auth,
         Namespace:  Properties.Namespace,
@@ -57,22 +80,77 @@ func setupClientConfig(cmd *cobra.Command, args []string) (error){
         Version:    Properties.APIVersion,
         Insecure:   flags.global.insecure,
         Host:       Properties.APIHost,
+        RetryOptions: whisk.RetryOptions{
+            MaxRetries: flags.global.retries,
+        },
+        ProxyURL:   Properties.Proxy,
+        CAFile:     caFile,
+        TLSConfig:  tlsConfig,
+        DialTimeout:    getFlagTimeout(flags.global.connectTimeout),
+        RequestTimeout: getFlagTimeout(flags.global.requestTimeout),
     }
 
     // Setup client
-    client, err = whisk.NewClient(http.DefaultClient, clientConfig)
+    client, err = whisk.NewClient(nil, clientConfig)
 
     if err != nil {
-        whisk.Debug(whisk.DbgError, "whisk.NewClient(%#v, %#v) error: %s\n", http.DefaultClient, clientConfig, err)
+        whisk.Debug(whisk.DbgError, "whisk.NewClient(%#v) error: %s\n", clientConfig, err)
         errMsg := wski18n.T("Unable to initialize server connection: {{.err}}", map[string]interface{}{"err": err})
         whiskErr := whisk.MakeWskErrorFromWskError(errors.New(errMsg), err, whisk.EXITCODE_ERR_GENERAL,
         whisk.DISPLAY_MSG, whisk.DISPLAY_USAGE)
         return whiskErr
     }
 
+    // The runtime kinds accepted for actions default to those built into the client, unless a server's runtimes
+    // manifest or list of kinds is named in WHISK_RUNTIMES_FILE
+    if runtimesFile := os.Getenv("WHISK_RUNTIMES_FILE"); len(runtimesFile) > 0 {
+        if err = whisk.LoadSupportedRuntimes(runtimesFile); err != nil {
+            return err
+        }
+    }
+
     return nil
 }
 
+// getTLSConfig returns the TLS settings for the client certificate and key named with --tls-cert and --tls-key, or nil
+// when neither is set. The certificate authorities of --tls-ca are loaded by the client, like those of the CERT
+// property.
+func getTLSConfig() (*tls.Config, error) {
+    certFile := flags.global.tlsCert
+    keyFile := flags.global.tlsKey
+
+    if len(certFile) == 0 && len(keyFile) == 0 {
+        return nil, nil
+    }
+
+    if len(certFile) == 0 || len(keyFile) == 0 {
+        errStr := wski18n.T("The --tls-cert and --tls-key flags must be used together.")
+        return nil, whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_USAGE, whisk.DISPLAY_MSG,
+            whisk.DISPLAY_USAGE)
+    }
+
+    certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+    if err != nil {
+        whisk.Debug(whisk.DbgError, "tls.LoadX509KeyPair(%s, %s) error: %s\n", certFile, keyFile, err)
+        errStr := wski18n.T("Unable to load the client certificate '{{.cert}}' and key '{{.key}}': {{.err}}",
+            map[string]interface{}{"cert": certFile, "key": keyFile, "err": err})
+        return nil, whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG,
+            whisk.NO_DISPLAY_USAGE)
+    }
+
+    return &tls.Config{Certificates: []tls.Certificate{certificate}}, nil
+}
+
+// getFlagTimeout converts a timeout flag in seconds to a client timeout; zero, which means no time limit, becomes a
+// negative client timeout
+func getFlagTimeout(seconds int) time.Duration {
+    if seconds <= 0 {
+        return -1
+    }
+
+    return time.Duration(seconds) * time.Second
+}
+
 func init() {
     var err error
 
@@ -84,7 +162,9 @@ func init() {
     }
 }
 
-func getKeyValueArgs(args []string, argIndex int, parsedArgs []string) ([]string, []string, error) {
+// getKeyValueArgs converts the KEY VALUE arguments of the flag at argIndex into a JSON object that is appended to
+// parsedArgs, and removes the flag from args. The value keeps its JSON type when inferType is set; see getKeyValueJSON.
+func getKeyValueArgs(args []string, argIndex int, parsedArgs []string, inferType bool) ([]string, []string, error) {
     var whiskErr error
     var key string
     var value string
@@ -92,7 +172,7 @@ func getKeyValueArgs(args []string, argIndex int, parsedArgs []string) ([]string
     if len(args) - 1 >= argIndex + 2 {
         key = args[argIndex + 1]
         value = args[argIndex + 2]
-        parsedArgs = append(parsedArgs, getFormattedJSON(key, value))
+        parsedArgs = append(parsedArgs, getKeyValueJSON(key, value, inferType))
         args = append(args[:argIndex], args[argIndex + 3:]...)
     } else {
         whisk.Debug(whisk.DbgError, "Arguments for '%s' must be a key/value pair; args: %s", args[argIndex], args)
@@ -105,6 +185,28 @@ func getKeyValueArgs(args []string, argIndex int, parsedArgs []string) ([]string
     return parsedArgs, args, whiskErr
 }
 
+// getNamedParamArgs converts the KEY=VALUE argument of --named-param into a JSON object that is appended to
+// parsedArgs, and removes the flag from args
+func getNamedParamArgs(args []string, argIndex int, parsedArgs []string) ([]string, []string, error) {
+    var namedParam []string
+    var whiskErr error
+
+    // The value may be given in the same argument as the flag, after an '='
+    if strings.HasPrefix(args[argIndex], "--named-param=") {
+        namedParam = []string{strings.TrimPrefix(args[argIndex], "--named-param=")}
+        args = append(args[:argIndex], args[argIndex + 1:]...)
+    } else if namedParam, args, whiskErr = getValueFromArgs(args, argIndex, namedParam); whiskErr != nil {
+        return parsedArgs, args, whiskErr
+    }
+
+    key, value, err := parseNamedParam(namedParam[0])
+    if err != nil {
+        return parsedArgs, args, err
+    }
+
+    return append(parsedArgs, getFormattedJSON(key, value)), args, nil
+}
+
 func getValueFromArgs(args []string, argIndex int, parsedArgs []string) ([]string, []string, error) {
     var whiskErr error
 
@@ -125,13 +227,15 @@ func getValueFromArgs(args []string, argIndex int, parsedArgs []string) ([]strin
 func parseArgs(args []string) ([]string, []string, []string, error) {
     var paramArgs []string
     var annotArgs []string
+    var paramFileArgs []string
+    var annotFileArgs []string
     var whiskErr error
 
     i := 0
 
     for i < len(args) {
         if args[i] == "-P" || args[i] == "--param-file" {
-            paramArgs, args, whiskErr = getValueFromArgs(args, i, paramArgs)
+            paramFileArgs, args, whiskErr = getValueFromArgs(args, i, paramFileArgs)
             if whiskErr != nil {
                 whisk.Debug(whisk.DbgError, "getValueFromArgs(%#v, %d) failed: %s\n", args, i, whiskErr)
                 errMsg := wski18n.T("The parameter arguments are invalid: {{.err}}",
@@ -141,14 +245,14 @@ func parseArgs(args []string) ([]string, []string, []string, error) {
                 return nil, nil, nil, whiskErr
             }
 
-            filename := paramArgs[len(paramArgs) - 1]
-            paramArgs[len(paramArgs) - 1], whiskErr = readFile(filename)
+            filename := paramFileArgs[len(paramFileArgs) - 1]
+            paramFileArgs[len(paramFileArgs) - 1], whiskErr = readJSONObjectFile(filename)
             if whiskErr != nil {
-                whisk.Debug(whisk.DbgError, "readFile(%s) error: %s\n", filename, whiskErr)
+                whisk.Debug(whisk.DbgError, "readJSONObjectFile(%s) error: %s\n", filename, whiskErr)
                 return nil, nil, nil, whiskErr
             }
         } else if args[i] == "-A" || args[i] == "--annotation-file" {
-            annotArgs, args, whiskErr = getValueFromArgs(args, i, annotArgs)
+            annotFileArgs, args, whiskErr = getValueFromArgs(args, i, annotFileArgs)
             if whiskErr != nil {
                 whisk.Debug(whisk.DbgError, "getValueFromArgs(%#v, %d) failed: %s\n", args, i, whiskErr)
                 errMsg := wski18n.T("The annotation arguments are invalid: {{.err}}",
@@ -158,14 +262,15 @@ func parseArgs(args []string) ([]string, []string, []string, error) {
                 return nil, nil, nil, whiskErr
             }
 
-            filename := annotArgs[len(annotArgs) - 1]
-            annotArgs[len(annotArgs) - 1], whiskErr = readFile(filename)
+            filename := annotFileArgs[len(annotFileArgs) - 1]
+            annotFileArgs[len(annotFileArgs) - 1], whiskErr = readJSONObjectFile(filename)
             if whiskErr != nil {
-                whisk.Debug(whisk.DbgError, "readFile(%s) error: %s\n", filename, whiskErr)
+                whisk.Debug(whisk.DbgError, "readJSONObjectFile(%s) error: %s\n", filename, whiskErr)
                 return nil, nil, nil, whiskErr
             }
-        } else if args[i] == "-p" || args[i] == "--param" {
-            paramArgs, args, whiskErr = getKeyValueArgs(args, i, paramArgs)
+        } else if args[i] == "-p" || args[i] == "--param" || args[i] == "--param-string" {
+            // --param-string values are always strings, for literal values such as "5" or "true"
+            paramArgs, args, whiskErr = getKeyValueArgs(args, i, paramArgs, args[i] != "--param-string")
             if whiskErr != nil {
                 whisk.Debug(whisk.DbgError, "getKeyValueArgs(%#v, %d) failed: %s\n", args, i, whiskErr)
                 errMsg := wski18n.T("The parameter arguments are invalid: {{.err}}",
@@ -174,8 +279,18 @@ func parseArgs(args []string) ([]string, []string, []string, error) {
                     whisk.DISPLAY_USAGE)
                 return nil, nil, nil, whiskErr
             }
+        } else if args[i] == "--named-param" || strings.HasPrefix(args[i], "--named-param=") {
+            paramArgs, args, whiskErr = getNamedParamArgs(args, i, paramArgs)
+            if whiskErr != nil {
+                whisk.Debug(whisk.DbgError, "getNamedParamArgs(%#v, %d) failed: %s\n", args, i, whiskErr)
+                errMsg := wski18n.T("The parameter arguments are invalid: {{.err}}",
+                    map[string]interface{}{"err": whiskErr})
+                whiskErr = whisk.MakeWskError(errors.New(errMsg), whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG,
+                    whisk.DISPLAY_USAGE)
+                return nil, nil, nil, whiskErr
+            }
         } else if args[i] == "-a" || args[i] == "--annotation"{
-            annotArgs, args, whiskErr = getKeyValueArgs(args, i, annotArgs)
+            annotArgs, args, whiskErr = getKeyValueArgs(args, i, annotArgs, true)
             if whiskErr != nil {
                 whisk.Debug(whisk.DbgError, "getKeyValueArgs(%#v, %d) failed: %s\n", args, i, whiskErr)
                 errMsg := wski18n.T("The annotation arguments are invalid: {{.err}}",
@@ -189,6 +304,10 @@ func parseArgs(args []string) ([]string, []string, []string, error) {
         }
     }
 
+    // File contents come first so that individual key/value arguments override the keys they define
+    paramArgs = append(paramFileArgs, paramArgs...)
+    annotArgs = append(annotFileArgs, annotArgs...)
+
     whisk.Debug(whisk.DbgInfo, "Found param args '%s'.\n", paramArgs)
     whisk.Debug(whisk.DbgInfo, "Found annotations args '%s'.\n", annotArgs)
     whisk.Debug(whisk.DbgInfo, "Arguments with param args removed '%s'.\n", args)
@@ -0,0 +1,579 @@
+/*
+ * Copyright 2015-2016 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+    "bytes"
+    "crypto/ecdsa"
+    "crypto/elliptic"
+    "crypto/rand"
+    "crypto/x509"
+    "crypto/x509/pkix"
+    "encoding/json"
+    "encoding/pem"
+    "fmt"
+    "io"
+    "io/ioutil"
+    "log"
+    "math/big"
+    "net/http"
+    "net/http/httptest"
+    "net/url"
+    "os"
+    "path/filepath"
+    "reflect"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/fatih/color"
+    "github.com/spf13/cobra"
+
+    "../../go-whisk/whisk"
+)
+
+// testRequest is a request received by a testServer
+type testRequest struct {
+    Method  string
+    Path    string      // the path below /api/v1/namespaces/
+    Query   url.Values
+    Header  http.Header
+    Body    string
+}
+
+// testServer is an API host that answers requests with a handler and records them
+type testServer struct {
+    *httptest.Server
+    mutex       sync.Mutex
+    requests    []testRequest
+    origClient  *whisk.Client
+    origFlags   Flags
+}
+
+// newTestServer starts an API host that answers requests with handler, and points client at it with the namespace
+// ns. The flags are reset; Close restores the client and flags.
+func newTestServer(t *testing.T, handler http.HandlerFunc) *testServer {
+    return startTestServer(t, handler, false)
+}
+
+// newTLSTestServer is newTestServer with an HTTPS API host, which the client trusts
+func newTLSTestServer(t *testing.T, handler http.HandlerFunc) *testServer {
+    return startTestServer(t, handler, true)
+}
+
+func startTestServer(t *testing.T, handler http.HandlerFunc, useTLS bool) *testServer {
+    server := &testServer{}
+    server.Server = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        body, _ := ioutil.ReadAll(r.Body)
+        r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+        server.mutex.Lock()
+        server.requests = append(server.requests, testRequest{
+            Method: r.Method,
+            Path:   strings.TrimPrefix(r.URL.Path, "/api/v1/namespaces/"),
+            Query:  r.URL.Query(),
+            Header: r.Header,
+            Body:   string(body),
+        })
+        server.mutex.Unlock()
+
+        handler(w, r)
+    }))
+
+    if useTLS {
+        server.StartTLS()
+    } else {
+        server.Start()
+    }
+
+    baseURL, _ := url.Parse(server.URL + "/api")
+    testClient, err := whisk.NewClient(server.Client(), &whisk.Config{
+        Namespace:  "ns",
+        AuthToken:  "user:key",
+        BaseURL:    baseURL,
+        Version:    "v1",
+    })
+    if err != nil {
+        server.Server.Close()
+        t.Fatalf("whisk.NewClient() failed: %s", err)
+    }
+
+    server.origClient, server.origFlags = client, flags
+    client, flags = testClient, Flags{}
+
+    return server
+}
+
+// Close shuts the server down and restores the client and flags
+func (server *testServer) Close() {
+    server.Server.Close()
+    client, flags = server.origClient, server.origFlags
+}
+
+// getRequests returns the requests received so far, as METHOD PATH strings
+func (server *testServer) getRequests() []string {
+    server.mutex.Lock()
+    defer server.mutex.Unlock()
+
+    var requests []string
+    for _, request := range server.requests {
+        requests = append(requests, request.Method + " " + request.Path)
+    }
+
+    return requests
+}
+
+// getRequest returns the first request received with the given method and path, or nil
+func (server *testServer) getRequest(method string, path string) *testRequest {
+    server.mutex.Lock()
+    defer server.mutex.Unlock()
+
+    for i := range server.requests {
+        if server.requests[i].Method == method && server.requests[i].Path == path {
+            return &server.requests[i]
+        }
+    }
+
+    return nil
+}
+
+// writeJSON answers a test request with the given status and v encoded as JSON
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(status)
+    json.NewEncoder(w).Encode(v)
+}
+
+// captureOutput runs f and returns what it wrote to standard output, directly or through color.Output
+func captureOutput(t *testing.T, f func()) string {
+    reader, writer, err := os.Pipe()
+    if err != nil {
+        t.Fatalf("os.Pipe() failed: %s", err)
+    }
+
+    origStdout, origOutput, origNoColor := os.Stdout, color.Output, color.NoColor
+    os.Stdout, color.Output, color.NoColor = writer, writer, true
+
+    output := make(chan string)
+    go func() {
+        var buffer bytes.Buffer
+        io.Copy(&buffer, reader)
+        output <- buffer.String()
+    }()
+
+    defer func() {
+        os.Stdout, color.Output, color.NoColor = origStdout, origOutput, origNoColor
+    }()
+
+    f()
+    writer.Close()
+
+    return <-output
+}
+
+// checkRequests fails the test unless server received exactly the wanted METHOD PATH requests, in order
+func checkRequests(t *testing.T, server *testServer, want ...string) {
+    if have := server.getRequests(); strings.Join(have, "\n") != strings.Join(want, "\n") {
+        t.Errorf("requests:\n%s\nwant:\n%s", strings.Join(have, "\n"), strings.Join(want, "\n"))
+    }
+}
+
+// getTestParams returns the parameters that parseArgs finds in args, with their JSON values
+func getTestParams(t *testing.T, args ...string) map[string]interface{} {
+    _, paramArgs, _, err := parseArgs(append([]string{"wsk", "action", "invoke", "a"}, args...))
+    if err != nil {
+        t.Fatalf("parseArgs(%q) failed: %s", args, err)
+    }
+
+    params, err := getJSONFromStrings(paramArgs, false)
+    if err != nil {
+        t.Fatalf("getJSONFromStrings(%q) failed: %s", paramArgs, err)
+    }
+
+    return params.(map[string]interface{})
+}
+
+func TestParseArgsParamTypes(t *testing.T) {
+    tests := []struct {
+        args    []string
+        want    map[string]interface{}
+    }{
+        // Values that are valid JSON keep their type with -p, but not with --param-string
+        {[]string{"-p", "flag", "true"}, map[string]interface{}{"flag": true}},
+        {[]string{"-p", "n", "5", "--param-string", "s", "5"},
+            map[string]interface{}{"n": json.Number("5"), "s": "5"}},
+        {[]string{"--param", "obj", `{"a":1}`, "--param-string", "str", `{"a":1}`},
+            map[string]interface{}{"obj": map[string]interface{}{"a": json.Number("1")}, "str": `{"a":1}`}},
+        {[]string{"-p", "name", "Bob"}, map[string]interface{}{"name": "Bob"}},
+        // Values that used to be sent as strings need --param-string or JSON quotes to stay strings
+        {[]string{"-p", "none", "null", "-p", "list", "[1,2]", "-p", "quoted", `"true"`},
+            map[string]interface{}{"none": nil, "list": []interface{}{json.Number("1"), json.Number("2")}, "quoted": "true"}},
+        {[]string{"--param-string", "none", "null", "--param-string", "list", "[1,2]", "--param-string", "flag", "true"},
+            map[string]interface{}{"none": "null", "list": "[1,2]", "flag": "true"}},
+        // When -p and --param-string set the same key, the last one wins
+        {[]string{"-p", "n", "5", "--param-string", "n", "5"}, map[string]interface{}{"n": "5"}},
+        {[]string{"--param-string", "n", "5", "-p", "n", "5"}, map[string]interface{}{"n": json.Number("5")}},
+        {[]string{"--named-param", "n=5", "--named-param=s=a=b"},
+            map[string]interface{}{"n": json.Number("5"), "s": "a=b"}},
+        {[]string{"--named-param", `k"ey=v`, "-p", `back\slash`, "true"},
+            map[string]interface{}{`k"ey`: "v", `back\slash`: true}},
+    }
+
+    for _, test := range tests {
+        if params := getTestParams(t, test.args...); !reflect.DeepEqual(params, test.want) {
+            t.Errorf("parameters of %q = %#v, want %#v", test.args, params, test.want)
+        }
+    }
+}
+
+func TestParseArgsNamedParam(t *testing.T) {
+    tests := []struct {
+        args    []string
+        want    map[string]interface{}
+    }{
+        {[]string{"--named-param", "n=-1.5", "--named-param", "flag=false", "--named-param", "none=null"},
+            map[string]interface{}{"n": json.Number("-1.5"), "flag": false, "none": nil}},
+        {[]string{"--named-param", `obj={"a":[1,"b"]}`, "--named-param=list=[true]"},
+            map[string]interface{}{"obj": map[string]interface{}{"a": []interface{}{json.Number("1"), "b"}},
+                "list": []interface{}{true}}},
+        // Values that are not valid JSON are strings
+        {[]string{"--named-param", "s=hello world", "--named-param", `q="5"`, "--named-param", "bad={a",
+            "--named-param", "list=a,b", "--named-param", "empty="},
+            map[string]interface{}{"s": "hello world", "q": "5", "bad": "{a", "list": "a,b", "empty": ""}},
+        // --named-param and -p are merged, and the one given last wins
+        {[]string{"-p", "a", "1", "--named-param", "a=2", "-p", "b", "x"},
+            map[string]interface{}{"a": json.Number("2"), "b": "x"}},
+        {[]string{"--named-param", "a=2", "-p", "a", "1"}, map[string]interface{}{"a": json.Number("1")}},
+    }
+
+    for _, test := range tests {
+        if params := getTestParams(t, test.args...); !reflect.DeepEqual(params, test.want) {
+            t.Errorf("parameters of %q = %#v, want %#v", test.args, params, test.want)
+        }
+    }
+}
+
+func TestParseArgsNamedParamErrors(t *testing.T) {
+    for _, args := range [][]string{
+        {"--named-param", "novalue"},
+        {"--named-param=novalue"},
+        {"--named-param", "a=1", "--named-param", "b"},
+    } {
+        _, _, _, err := parseArgs(append([]string{"wsk", "action", "invoke", "a"}, args...))
+        if err == nil || !strings.Contains(err.Error(), "the format is KEY=VALUE") {
+            t.Errorf("parseArgs(%q) error = %v, want an invalid named parameter error", args, err)
+            continue
+        }
+        if whiskErr, ok := err.(*whisk.WskError); !ok || whiskErr.ExitCode != whisk.EXITCODE_ERR_GENERAL {
+            t.Errorf("parseArgs(%q) error = %#v, want a WskError with exit code %d", args, err, whisk.EXITCODE_ERR_GENERAL)
+        }
+    }
+
+    if _, _, _, err := parseArgs([]string{"wsk", "action", "invoke", "a", "--named-param"}); err == nil {
+        t.Errorf("parseArgs() of --named-param without a value succeeded, want an error")
+    }
+}
+
+func TestGetFlagTimeout(t *testing.T) {
+    tests := []struct {
+        seconds int
+        want    time.Duration
+    }{
+        {30, 30 * time.Second},
+        {1, time.Second},
+        // 0 means there is no time limit, which the client takes as a negative timeout
+        {0, -1},
+        {-5, -1},
+    }
+
+    for _, test := range tests {
+        if timeout := getFlagTimeout(test.seconds); timeout != test.want {
+            t.Errorf("getFlagTimeout(%d) = %s, want %s", test.seconds, timeout, test.want)
+        }
+    }
+
+    for name, want := range map[string]time.Duration{
+        "connect-timeout": whisk.DefaultDialTimeout,
+        "request-timeout": whisk.DefaultRequestTimeout,
+    } {
+        if flag := WskCmd.PersistentFlags().Lookup(name); flag == nil || flag.DefValue != fmt.Sprint(int(want / time.Second)) {
+            t.Errorf("--%s = %#v, want a flag with the default %s", name, flag, want)
+        }
+    }
+}
+
+// writeTestFile writes content to a file in a new temporary directory and returns its path
+func writeTestFile(t *testing.T, name string, content string) string {
+    dir, err := ioutil.TempDir("", "wsk")
+    if err != nil {
+        t.Fatalf("ioutil.TempDir() failed: %s", err)
+    }
+
+    path := filepath.Join(dir, name)
+    if err = ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+        os.RemoveAll(dir)
+        t.Fatalf("ioutil.WriteFile(%s) failed: %s", path, err)
+    }
+
+    return path
+}
+
+func TestParseArgsParamFilePrecedence(t *testing.T) {
+    file := writeTestFile(t, "params.json", `{"a": 1, "b": "file", "c": {"d": true}}`)
+    defer os.RemoveAll(filepath.Dir(file))
+    other := writeTestFile(t, "other.json", `{"b": "other", "e": null}`)
+    defer os.RemoveAll(filepath.Dir(other))
+
+    tests := []struct {
+        args    []string
+        want    map[string]interface{}
+    }{
+        {[]string{"-P", file},
+            map[string]interface{}{"a": json.Number("1"), "b": "file", "c": map[string]interface{}{"d": true}}},
+        // -p overrides the keys of the file, whether it comes before or after it
+        {[]string{"-P", file, "-p", "b", "cli"},
+            map[string]interface{}{"a": json.Number("1"), "b": "cli", "c": map[string]interface{}{"d": true}}},
+        {[]string{"-p", "b", "cli", "--param-file", file, "-p", "a", "2"},
+            map[string]interface{}{"a": json.Number("2"), "b": "cli", "c": map[string]interface{}{"d": true}}},
+        // A later file overrides the keys of an earlier one
+        {[]string{"-P", file, "-P", other},
+            map[string]interface{}{"a": json.Number("1"), "b": "other", "c": map[string]interface{}{"d": true}, "e": nil}},
+    }
+
+    for _, test := range tests {
+        if params := getTestParams(t, test.args...); !reflect.DeepEqual(params, test.want) {
+            t.Errorf("parameters of %q = %#v, want %#v", test.args, params, test.want)
+        }
+    }
+}
+
+func TestParseArgsParamFileErrors(t *testing.T) {
+    invalid := writeTestFile(t, "invalid.json", `{"a": `)
+    defer os.RemoveAll(filepath.Dir(invalid))
+    array := writeTestFile(t, "array.json", `[1, 2]`)
+    defer os.RemoveAll(filepath.Dir(array))
+
+    tests := []struct {
+        file    string
+        want    string
+    }{
+        {filepath.Join(filepath.Dir(invalid), "missing.json"), "is not a valid file or it does not exist"},
+        {invalid, "is not valid JSON"},
+        {array, "must be a JSON object"},
+    }
+
+    for _, test := range tests {
+        _, _, _, err := parseArgs([]string{"wsk", "action", "invoke", "a", "-P", test.file})
+        if err == nil || !strings.Contains(err.Error(), test.want) {
+            t.Errorf("parseArgs(-P %s) error = %v, want an error containing %q", test.file, err, test.want)
+        }
+    }
+}
+
+func TestErrorExitCodes(t *testing.T) {
+    commands := []struct {
+        cmd     *cobra.Command
+        args    []string
+    }{
+        {actionGetCmd, []string{"/ns/a"}},
+        {actionDeleteCmd, []string{"/ns/a"}},
+        {actionInvokeCmd, []string{"/ns/a"}},
+        {ruleGetCmd, []string{"/ns/r"}},
+        {ruleDeleteCmd, []string{"/ns/r"}},
+        {ruleEnableCmd, []string{"/ns/r"}},
+        {ruleDisableCmd, []string{"/ns/r"}},
+        {triggerGetCmd, []string{"/ns/t"}},
+        {triggerDeleteCmd, []string{"/ns/t"}},
+        {triggerFireCmd, []string{"/ns/t"}},
+        {packageGetCmd, []string{"/ns/p"}},
+        {packageDeleteCmd, []string{"/ns/p"}},
+        {activationGetCmd, []string{"12345"}},
+        {activationResultCmd, []string{"12345"}},
+        {activationLogsCmd, []string{"12345"}},
+    }
+
+    // A 404 exits with EXITCODE_ERR_NOT_FOUND; other HTTP errors exit with their status less 256
+    statuses := map[int]int{
+        http.StatusNotFound: whisk.EXITCODE_ERR_NOT_FOUND,
+        http.StatusInternalServerError: http.StatusInternalServerError - 256,
+    }
+
+    for status, exitCode := range statuses {
+        for _, command := range commands {
+            server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+                writeJSON(w, status, map[string]interface{}{"error": http.StatusText(status), "code": 1})
+            })
+            client.Config.MaxRetries = 0
+
+            var err error
+            captureOutput(t, func() { err = command.cmd.RunE(command.cmd, command.args) })
+            server.Close()
+
+            if whiskErr, ok := err.(*whisk.WskError); !ok || whiskErr.ExitCode != exitCode {
+                t.Errorf("%s %s with a %d response error = %#v, want a WskError with exit code %d",
+                    command.cmd.CommandPath(), command.args[0], status, err, exitCode)
+            }
+        }
+    }
+}
+
+// writeTestClientCertificate writes a self-signed client certificate and its key to cert.pem and key.pem in dir
+func writeTestClientCertificate(t *testing.T, dir string) (string, string) {
+    key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+    if err != nil {
+        t.Fatalf("ecdsa.GenerateKey() failed: %s", err)
+    }
+
+    template := &x509.Certificate{
+        SerialNumber: big.NewInt(1),
+        Subject: pkix.Name{CommonName: "wsk test client"},
+        NotBefore: time.Now().Add(-time.Hour),
+        NotAfter: time.Now().Add(time.Hour),
+        ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+    }
+    der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+    if err != nil {
+        t.Fatalf("x509.CreateCertificate() failed: %s", err)
+    }
+    keyDER, err := x509.MarshalECPrivateKey(key)
+    if err != nil {
+        t.Fatalf("x509.MarshalECPrivateKey() failed: %s", err)
+    }
+
+    certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+    ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644)
+    ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+
+    return certFile, keyFile
+}
+
+func TestGetTLSConfig(t *testing.T) {
+    dir, err := ioutil.TempDir("", "wsk")
+    if err != nil {
+        t.Fatalf("ioutil.TempDir() failed: %s", err)
+    }
+    defer os.RemoveAll(dir)
+
+    certFile, keyFile := writeTestClientCertificate(t, dir)
+
+    origFlags := flags
+    defer func() { flags = origFlags }()
+
+    // Without either flag, the client keeps its default TLS settings
+    flags = Flags{}
+    if tlsConfig, err := getTLSConfig(); tlsConfig != nil || err != nil {
+        t.Errorf("getTLSConfig() without TLS flags = %#v, %v; want nil", tlsConfig, err)
+    }
+
+    // The certificate authorities of --tls-ca are left to the client
+    flags.global.tlsCert, flags.global.tlsKey, flags.global.tlsCA = certFile, keyFile, certFile
+    if tlsConfig, err := getTLSConfig(); err != nil || len(tlsConfig.Certificates) != 1 || tlsConfig.RootCAs != nil {
+        t.Errorf("getTLSConfig() with --tls-cert, --tls-key and --tls-ca = %#v, %v; want a certificate only",
+            tlsConfig, err)
+    }
+
+    tests := []struct {
+        cert        string
+        key         string
+        exitCode    int
+        want        string
+    }{
+        {certFile, "", whisk.EXITCODE_ERR_USAGE, "The --tls-cert and --tls-key flags must be used together."},
+        {"", keyFile, whisk.EXITCODE_ERR_USAGE, "The --tls-cert and --tls-key flags must be used together."},
+        {certFile, certFile, whisk.EXITCODE_ERR_GENERAL, "Unable to load the client certificate"},
+    }
+
+    for _, test := range tests {
+        flags.global.tlsCert, flags.global.tlsKey = test.cert, test.key
+        _, err := getTLSConfig()
+        if whiskErr, ok := err.(*whisk.WskError); !ok || whiskErr.ExitCode != test.exitCode ||
+                !strings.Contains(whiskErr.Error(), test.want) {
+            t.Errorf("getTLSConfig() with --tls-cert %q --tls-key %q error = %#v, want %q with exit code %d",
+                test.cert, test.key, err, test.want, test.exitCode)
+        }
+    }
+}
+
+func TestSetupClientConfigTLS(t *testing.T) {
+    server := newTLSTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+        writeJSON(w, http.StatusOK, whisk.Action{Namespace: "ns", Name: "a"})
+    })
+    defer server.Close()
+    // The handshake that is expected to fail is not logged
+    server.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+
+    caFile := writeTestFile(t, "ca.pem", string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE",
+        Bytes: server.Certificate().Raw})))
+    defer os.RemoveAll(filepath.Dir(caFile))
+
+    origProperties := Properties
+    defer func() { Properties = origProperties }()
+    Properties.APIHost, Properties.Auth, Properties.Namespace, Properties.Cert = server.URL, "user:key", "ns", ""
+
+    missing := filepath.Join(filepath.Dir(caFile), "missing.pem")
+    notPEM := writeTestFile(t, "ca.txt", "not a certificate\n")
+    defer os.RemoveAll(filepath.Dir(notPEM))
+
+    tests := []struct {
+        cert        string      // the CERT property
+        caFile      string      // --tls-ca
+        insecure    bool
+        connects    bool
+        err         string
+    }{
+        {"", "", false, false, ""},
+        // The CERT property and --tls-ca both add certificate authorities, and --tls-ca is used instead of CERT
+        {caFile, "", false, true, ""},
+        {"", caFile, false, true, ""},
+        {missing, caFile, false, true, ""},
+        {"", "", true, true, ""},
+        // A CA file that cannot be used fails before any request
+        {missing, "", false, false, "Unable to read the CA certificate file '" + missing + "'"},
+        {"", notPEM, false, false, "does not contain any PEM encoded certificates"},
+    }
+
+    for _, test := range tests {
+        Properties.Cert, flags.global.tlsCA, flags.global.insecure = test.cert, test.caFile, test.insecure
+
+        var err error
+        stderr := captureStderr(t, func() { err = setupClientConfig(actionGetCmd, []string{"/ns/a"}) })
+        if len(test.err) > 0 {
+            if err == nil || !strings.Contains(err.Error(), test.err) {
+                t.Errorf("setupClientConfig() with CERT %q --tls-ca %q error = %v, want %q", test.cert, test.caFile,
+                    err, test.err)
+            }
+            continue
+        }
+        if err != nil {
+            t.Errorf("setupClientConfig() with CERT %q --tls-ca %q --insecure=%t failed: %s", test.cert,
+                test.caFile, test.insecure, err)
+            continue
+        }
+
+        // Disabling certificate checking is warned about
+        if warned := strings.Contains(stderr, "warning: certificate checking is disabled"); warned != test.insecure {
+            t.Errorf("setupClientConfig() with --insecure=%t printed %q to standard error", test.insecure, stderr)
+        }
+
+        client.Config.MaxRetries = 0
+        if _, _, err = client.Actions.Get("a"); (err == nil) != test.connects {
+            t.Errorf("action get with CERT %q --tls-ca %q --insecure=%t error = %v, want a connection: %t", test.cert,
+                test.caFile, test.insecure, err, test.connects)
+        }
+    }
+}
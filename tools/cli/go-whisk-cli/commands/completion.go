@@ -0,0 +1,326 @@
+/*
+ * Copyright 2015-2016 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+    "errors"
+    "fmt"
+    "io"
+    "os"
+    "strings"
+
+    "github.com/spf13/cobra"
+    "github.com/spf13/pflag"
+
+    "../../go-whisk/whisk"
+    "../wski18n"
+)
+
+// Number of entity names fetched for completion
+const completionNameLimit = 200
+
+// Commands whose first argument is the name of an entity of the given collection
+var completionNameCommands = map[string][]string{
+    "actions": {"action get", "action update", "action delete", "action invoke", "action export"},
+    "packages": {"package get", "package update", "package delete"},
+    "rules": {"rule get", "rule update", "rule delete", "rule enable", "rule disable", "rule status", "rule rename"},
+    "triggers": {"trigger get", "trigger update", "trigger delete", "trigger fire"},
+}
+
+// Bash functions that complete entity names; a case for each command in completionNameCommands is added to
+// __custom_func. The names are kept in shell variables for 30 seconds, so repeated TAB presses do not each list the
+// collection from the server.
+const bashCompletionFunction = `
+__wsk_complete_names()
+{
+    local collection=$1
+    local names_var="__wsk_names_${collection}"
+    local time_var="__wsk_names_time_${collection}"
+
+    if [[ -z ${!time_var} || $(( SECONDS - ${!time_var} )) -ge 30 ]]; then
+        printf -v "${names_var}" '%%s' "$(wsk completion names ${collection} 2>/dev/null)"
+        printf -v "${time_var}" '%%s' "${SECONDS}"
+    fi
+
+    COMPREPLY=( $(compgen -W "${!names_var}" -- "$cur") )
+}
+
+__custom_func()
+{
+    if [[ ${#nouns[@]} -ne 0 ]]; then
+        return
+    fi
+
+    case ${last_command} in
+%s    esac
+}
+`
+
+const fishCompletionFunctions = `# fish completion for wsk
+
+function __wsk_using_command
+    set -l args
+    for word in (commandline -opc)[2..-1]
+        switch $word
+            case '-*'
+            case '*'
+                set args $args $word
+        end
+    end
+    test "$args" = "$argv"
+end
+
+function __wsk_complete_names
+    set -l names_var __wsk_names_$argv[1]
+    set -l time_var __wsk_names_time_$argv[1]
+    set -l now (date +%s)
+
+    if not set -q $time_var; or test (math $now - $$time_var) -ge 30
+        set -g $names_var (wsk completion names $argv[1] 2>/dev/null)
+        set -g $time_var $now
+    end
+
+    printf '%s\n' $$names_var
+end
+
+`
+
+var completionCmd = &cobra.Command{
+    Use:   "completion",
+    Short: wski18n.T("print a shell completion script"),
+}
+
+var completionBashCmd = &cobra.Command{
+    Use:           "bash",
+    Short:         wski18n.T("print the bash completion script; load it with: source <(wsk completion bash)"),
+    SilenceUsage:  true,
+    SilenceErrors: true,
+    RunE: func(cmd *cobra.Command, args []string) error {
+        WskCmd.BashCompletionFunction = getBashCompletionFunction()
+
+        return completionScriptError(WskCmd.GenBashCompletion(os.Stdout))
+    },
+}
+
+var completionZshCmd = &cobra.Command{
+    Use:           "zsh",
+    Short:         wski18n.T("print the zsh completion script"),
+    SilenceUsage:  true,
+    SilenceErrors: true,
+    RunE: func(cmd *cobra.Command, args []string) error {
+        return completionScriptError(WskCmd.GenZshCompletion(os.Stdout))
+    },
+}
+
+var completionFishCmd = &cobra.Command{
+    Use:           "fish",
+    Short:         wski18n.T("print the fish completion script; load it with: wsk completion fish | source"),
+    SilenceUsage:  true,
+    SilenceErrors: true,
+    RunE: func(cmd *cobra.Command, args []string) error {
+        return completionScriptError(genFishCompletion(os.Stdout, WskCmd))
+    },
+}
+
+var completionNamesCmd = &cobra.Command{
+    Use:           "names COLLECTION",
+    Short:         wski18n.T("print the entity names of a collection, for use by completion scripts"),
+    Hidden:        true,
+    SilenceUsage:  true,
+    SilenceErrors: true,
+    PreRunE:       setupClientConfig,
+    RunE: func(cmd *cobra.Command, args []string) error {
+        var names []string
+        var err error
+
+        if whiskErr := checkArgs(args, 1, 1, "Completion names",
+                wski18n.T("A collection name is required.")); whiskErr != nil {
+            return whiskErr
+        }
+
+        if names, err = getCompletionNames(args[0]); err != nil {
+            return err
+        }
+
+        for _, name := range names {
+            fmt.Println(name)
+        }
+
+        return nil
+    },
+}
+
+// getCompletionNames lists the names of the actions, packages, rules or triggers in the current namespace
+func getCompletionNames(collection string) ([]string, error) {
+    var names []string
+    var err error
+
+    switch collection {
+    case "actions":
+        var actions []whisk.Action
+        if actions, _, _, err = client.Actions.List("", &whisk.ActionListOptions{Limit: completionNameLimit}); err == nil {
+            for _, action := range actions {
+                names = append(names, action.Name)
+            }
+        }
+    case "packages":
+        var packages []whisk.Package
+        if packages, _, err = client.Packages.List(&whisk.PackageListOptions{Limit: completionNameLimit}); err == nil {
+            for _, xPackage := range packages {
+                names = append(names, xPackage.Name)
+            }
+        }
+    case "rules":
+        var rules []whisk.Rule
+        if rules, _, _, err = client.Rules.List(&whisk.RuleListOptions{Limit: completionNameLimit}); err == nil {
+            for _, rule := range rules {
+                names = append(names, rule.Name)
+            }
+        }
+    case "triggers":
+        var triggers []whisk.Trigger
+        if triggers, _, _, err = client.Triggers.List(&whisk.TriggerListOptions{Limit: completionNameLimit}); err == nil {
+            for _, trigger := range triggers {
+                names = append(names, trigger.Name)
+            }
+        }
+    default:
+        errStr := wski18n.T("Invalid collection '{{.collection}}'. Valid collections are actions, packages, rules and triggers.",
+                map[string]interface{}{"collection": collection})
+        return nil, whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_USAGE, whisk.DISPLAY_MSG, whisk.DISPLAY_USAGE)
+    }
+
+    if err != nil {
+        whisk.Debug(whisk.DbgError, "Listing %s failed: %s\n", collection, err)
+        errStr := wski18n.T("Unable to list {{.collection}}: {{.err}}",
+                map[string]interface{}{"collection": collection, "err": err})
+        return nil, whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL,
+            whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+    }
+
+    return names, nil
+}
+
+// getBashCompletionFunction returns the bash functions that complete entity names, with a case for each command
+// that takes an entity name. Cobra calls __custom_func when it has no completion of its own.
+func getBashCompletionFunction() string {
+    cases := ""
+
+    for _, collection := range []string{"actions", "packages", "rules", "triggers"} {
+        var lastCommands []string
+        for _, command := range completionNameCommands[collection] {
+            lastCommands = append(lastCommands, "wsk_" + strings.Replace(command, " ", "_", -1))
+        }
+        cases += fmt.Sprintf("        %s)\n            __wsk_complete_names %s\n            ;;\n",
+            strings.Join(lastCommands, " | "), collection)
+    }
+
+    return fmt.Sprintf(bashCompletionFunction, cases)
+}
+
+// genFishCompletion writes a fish completion script for the subcommands and flags of root, and for the entity
+// names taken by the commands in completionNameCommands
+func genFishCompletion(w io.Writer, root *cobra.Command) error {
+    script := fishCompletionFunctions
+
+    root.PersistentFlags().VisitAll(func(flag *pflag.Flag) {
+        script += getFishFlagCompletion(root.Name(), "", flag)
+    })
+    script += getFishCommandCompletions(root, nil)
+
+    for _, collection := range []string{"actions", "packages", "rules", "triggers"} {
+        for _, command := range completionNameCommands[collection] {
+            script += fmt.Sprintf("complete -c %s -f -n '__wsk_using_command %s' -a '(__wsk_complete_names %s)'\n",
+                root.Name(), command, collection)
+        }
+    }
+
+    _, err := io.WriteString(w, script)
+    return err
+}
+
+func getFishCommandCompletions(cmd *cobra.Command, path []string) string {
+    script := ""
+    condition := strings.TrimSpace("__wsk_using_command " + strings.Join(path, " "))
+
+    for _, subcommand := range cmd.Commands() {
+        if !subcommand.IsAvailableCommand() || subcommand.Name() == "help" {
+            continue
+        }
+
+        script += fmt.Sprintf("complete -c %s -f -n '%s' -a %s -d %s\n", cmd.Root().Name(), condition,
+            subcommand.Name(), quoteFishString(subcommand.Short))
+    }
+
+    if len(path) > 0 {
+        cmd.LocalNonPersistentFlags().VisitAll(func(flag *pflag.Flag) {
+            script += getFishFlagCompletion(cmd.Root().Name(), condition, flag)
+        })
+    }
+
+    for _, subcommand := range cmd.Commands() {
+        if subcommand.IsAvailableCommand() && subcommand.Name() != "help" {
+            script += getFishCommandCompletions(subcommand, append(append([]string{}, path...), subcommand.Name()))
+        }
+    }
+
+    return script
+}
+
+func getFishFlagCompletion(rootName string, condition string, flag *pflag.Flag) string {
+    if flag.Hidden {
+        return ""
+    }
+
+    line := "complete -c " + rootName
+    if len(condition) > 0 {
+        line += " -n '" + condition + "'"
+    }
+    line += " -l " + flag.Name
+    if len(flag.Shorthand) > 0 {
+        line += " -s " + flag.Shorthand
+    }
+
+    return line + " -d " + quoteFishString(flag.Usage) + "\n"
+}
+
+func quoteFishString(value string) string {
+    value = strings.Replace(value, "\\", "\\\\", -1)
+    value = strings.Replace(value, "'", "\\'", -1)
+    value = strings.Replace(value, "`", "", -1)
+
+    return "'" + value + "'"
+}
+
+func completionScriptError(err error) (error) {
+    if err == nil {
+        return nil
+    }
+
+    whisk.Debug(whisk.DbgError, "Completion script generation failed: %s\n", err)
+    errStr := wski18n.T("Unable to print the completion script: {{.err}}", map[string]interface{}{"err": err})
+    return whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+}
+
+func init() {
+    completionCmd.AddCommand(
+        completionBashCmd,
+        completionZshCmd,
+        completionFishCmd,
+        completionNamesCmd,
+    )
+}
@@ -0,0 +1,141 @@
+/*
+ * Copyright 2015-2016 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+    "net/http"
+    "reflect"
+    "strings"
+    "testing"
+
+    "github.com/spf13/cobra"
+
+    "../../go-whisk/whisk"
+)
+
+func TestCompletionScripts(t *testing.T) {
+    tests := []struct {
+        cmd     *cobra.Command
+        want    []string
+    }{
+        {completionBashCmd, []string{"_wsk_action_invoke()", "_wsk_rule_enable()", "_wsk_trigger_fire()",
+            "__wsk_complete_names()", "wsk_action_get | wsk_action_update", "__wsk_complete_names triggers",
+            "--apihost"}},
+        {completionZshCmd, []string{"action", "invoke", "rule", "trigger", "completion"}},
+        {completionFishCmd, []string{"function __wsk_using_command",
+            "complete -c wsk -f -n '__wsk_using_command' -a action -d 'work with actions'",
+            "complete -c wsk -f -n '__wsk_using_command action' -a invoke -d 'invoke action'",
+            "complete -c wsk -f -n '__wsk_using_command rule get' -a '(__wsk_complete_names rules)'",
+            "complete -c wsk -l apihost"}},
+    }
+
+    for _, test := range tests {
+        var err error
+        output := captureOutput(t, func() { err = test.cmd.RunE(test.cmd, []string{}) })
+        if err != nil {
+            t.Errorf("completion %s failed: %s", test.cmd.Name(), err)
+            continue
+        }
+        if len(output) == 0 {
+            t.Errorf("completion %s printed nothing", test.cmd.Name())
+        }
+        for _, want := range test.want {
+            if !strings.Contains(output, want) {
+                t.Errorf("completion %s script does not contain %q", test.cmd.Name(), want)
+            }
+        }
+        // Hidden commands are not offered
+        if strings.Contains(output, "-a names -d") || strings.Contains(output, "_wsk_completion_names()") {
+            t.Errorf("completion %s script offers the hidden names command", test.cmd.Name())
+        }
+    }
+}
+
+func TestCompletionNameCommands(t *testing.T) {
+    for collection, commands := range completionNameCommands {
+        for _, command := range commands {
+            if cmd, _, err := WskCmd.Find(strings.Fields(command)); err != nil || cmd.CommandPath() != "wsk " + command {
+                t.Errorf("%s completion command %q is not a wsk command", collection, command)
+            }
+        }
+    }
+}
+
+func TestGetCompletionNames(t *testing.T) {
+    server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+        collection := strings.TrimPrefix(r.URL.Path, "/api/v1/namespaces/ns/")
+        writeJSON(w, http.StatusOK, []map[string]interface{}{
+            {"namespace": "ns", "name": collection[:1] + "1"},
+            {"namespace": "ns", "name": collection[:1] + "2"},
+        })
+    })
+    defer server.Close()
+
+    for _, collection := range []string{"actions", "packages", "rules", "triggers"} {
+        names, err := getCompletionNames(collection)
+        if err != nil {
+            t.Errorf("getCompletionNames(%s) failed: %s", collection, err)
+            continue
+        }
+        if want := []string{collection[:1] + "1", collection[:1] + "2"}; !reflect.DeepEqual(names, want) {
+            t.Errorf("getCompletionNames(%s) = %q, want %q", collection, names, want)
+        }
+    }
+
+    checkRequests(t, server, "GET ns/actions", "GET ns/packages", "GET ns/rules", "GET ns/triggers")
+    for _, request := range server.requests {
+        if limit := request.Query.Get("limit"); limit != "200" {
+            t.Errorf("%s %s sent limit %s, want 200", request.Method, request.Path, limit)
+        }
+    }
+}
+
+func TestGetCompletionNamesErrors(t *testing.T) {
+    server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+        writeJSON(w, http.StatusUnauthorized, map[string]interface{}{"error": "The supplied authentication is invalid", "code": 1})
+    })
+    defer server.Close()
+
+    _, err := getCompletionNames("activations")
+    if whiskErr, ok := err.(*whisk.WskError); !ok || whiskErr.ExitCode != whisk.EXITCODE_ERR_USAGE {
+        t.Errorf("getCompletionNames(activations) error = %#v, want a usage error", err)
+    }
+
+    _, err = getCompletionNames("actions")
+    if err == nil || !strings.Contains(err.Error(), "Unable to list actions") {
+        t.Errorf("getCompletionNames(actions) with a failing server error = %v, want an error listing actions", err)
+    }
+}
+
+func TestQuoteFishString(t *testing.T) {
+    tests := []struct {
+        value   string
+        want    string
+    }{
+        {"list actions", "'list actions'"},
+        {"the action's name", `'the action\'s name'`},
+        {`a\b`, `'a\\b'`},
+        // Backquotes mark flag value names in usage strings
+        {"the `LIMIT` in MB", "'the LIMIT in MB'"},
+    }
+
+    for _, test := range tests {
+        if quoted := quoteFishString(test.value); quoted != test.want {
+            t.Errorf("quoteFishString(%q) = %s, want %s", test.value, quoted, test.want)
+        }
+    }
+}
@@ -0,0 +1,205 @@
+/*
+ * Copyright 2015-2016 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+    "errors"
+    "fmt"
+    "strings"
+
+    "github.com/spf13/cobra"
+    "github.com/fatih/color"
+
+    "../../go-whisk/whisk"
+    "../wski18n"
+)
+
+// configKeys lists, in display order, the keys that may be stored in the properties file
+var configKeys = []string{
+    "AUTH",
+    "APIHOST",
+    "APIVERSION",
+    "NAMESPACE",
+    "PROXY",
+    "CERT",
+}
+
+var configCmd = &cobra.Command{
+    Use:   "config",
+    Short: wski18n.T("manage the whisk properties file"),
+}
+
+var configSetCmd = &cobra.Command{
+    Use:            "set KEY VALUE",
+    Short:          wski18n.T("store a value in the properties file"),
+    SilenceUsage:   true,
+    SilenceErrors:  true,
+    RunE: func(cmd *cobra.Command, args []string) error {
+        if whiskErr := checkArgs(args, 2, 2, "Config set",
+                wski18n.T("A key and a value are required.")); whiskErr != nil {
+            return whiskErr
+        }
+
+        key, err := getConfigKey(args[0])
+        if err != nil {
+            return err
+        }
+
+        props, err := readConfigProps()
+        if err != nil {
+            return err
+        }
+
+        props[key] = args[1]
+        if err = writeConfigProps(props); err != nil {
+            return err
+        }
+
+        fmt.Fprintf(color.Output,
+            wski18n.T("{{.ok}} {{.key}} set to {{.value}}\n",
+                map[string]interface{}{"ok": color.GreenString("ok:"), "key": key, "value": boldString(args[1])}))
+        return nil
+    },
+}
+
+var configGetCmd = &cobra.Command{
+    Use:            "get [KEY]",
+    Short:          wski18n.T("print a value, or all values, stored in the properties file"),
+    SilenceUsage:   true,
+    SilenceErrors:  true,
+    RunE: func(cmd *cobra.Command, args []string) error {
+        if whiskErr := checkArgs(args, 0, 1, "Config get",
+                wski18n.T("An optional key is the only valid argument.")); whiskErr != nil {
+            return whiskErr
+        }
+
+        var key string
+        var err error
+
+        if len(args) == 1 {
+            if key, err = getConfigKey(args[0]); err != nil {
+                return err
+            }
+        }
+
+        props, err := readConfigProps()
+        if err != nil {
+            return err
+        }
+
+        // A single value is printed bare so that it can be used in command substitution
+        if len(key) > 0 {
+            fmt.Println(props[key])
+            return nil
+        }
+
+        for _, key := range configKeys {
+            if value, ok := props[key]; ok {
+                if key == "AUTH" {
+                    value = maskAuthKey(value)
+                }
+                fmt.Printf("%s=%s\n", key, value)
+            }
+        }
+
+        return nil
+    },
+}
+
+var configUnsetCmd = &cobra.Command{
+    Use:            "unset KEY",
+    Short:          wski18n.T("remove a value from the properties file"),
+    SilenceUsage:   true,
+    SilenceErrors:  true,
+    RunE: func(cmd *cobra.Command, args []string) error {
+        if whiskErr := checkArgs(args, 1, 1, "Config unset",
+                wski18n.T("A key is required.")); whiskErr != nil {
+            return whiskErr
+        }
+
+        key, err := getConfigKey(args[0])
+        if err != nil {
+            return err
+        }
+
+        props, err := readConfigProps()
+        if err != nil {
+            return err
+        }
+
+        delete(props, key)
+        if err = writeConfigProps(props); err != nil {
+            return err
+        }
+
+        fmt.Fprintf(color.Output,
+            wski18n.T("{{.ok}} {{.key}} unset.\n",
+                map[string]interface{}{"ok": color.GreenString("ok:"), "key": key}))
+        return nil
+    },
+}
+
+// getConfigKey returns the properties file key named by name, or a usage error when the key is not supported
+func getConfigKey(name string) (string, error) {
+    key := strings.ToUpper(name)
+
+    for _, configKey := range configKeys {
+        if key == configKey {
+            return key, nil
+        }
+    }
+
+    whisk.Debug(whisk.DbgError, "Unsupported properties file key '%s'\n", name)
+    errStr := wski18n.T("Invalid key '{{.key}}'. Valid keys are: {{.keys}}.",
+            map[string]interface{}{"key": name, "keys": strings.Join(configKeys, ", ")})
+    werr := whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_USAGE, whisk.DISPLAY_MSG, whisk.DISPLAY_USAGE)
+    return "", werr
+}
+
+func readConfigProps() (map[string]string, error) {
+    props, err := readProps(Properties.PropsFile)
+    if err != nil {
+        whisk.Debug(whisk.DbgError, "readProps(%s) failed: %s\n", Properties.PropsFile, err)
+        errStr := wski18n.T("Unable to read the properties file '{{.filename}}': {{.err}}",
+                map[string]interface{}{"filename": Properties.PropsFile, "err": err})
+        werr := whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+        return nil, werr
+    }
+
+    return props, nil
+}
+
+func writeConfigProps(props map[string]string) error {
+    if err := writeProps(Properties.PropsFile, props); err != nil {
+        whisk.Debug(whisk.DbgError, "writeProps(%s, %#v) failed: %s\n", Properties.PropsFile, props, err)
+        errStr := wski18n.T("Unable to write the properties file '{{.filename}}': {{.err}}",
+                map[string]interface{}{"filename": Properties.PropsFile, "err": err})
+        werr := whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL,
+            whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+        return werr
+    }
+
+    return nil
+}
+
+func init() {
+    configCmd.AddCommand(
+        configSetCmd,
+        configGetCmd,
+        configUnsetCmd,
+    )
+}
@@ -0,0 +1,172 @@
+/*
+ * Copyright 2015-2016 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+    "io/ioutil"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+    "testing"
+
+    "github.com/spf13/cobra"
+
+    "../../go-whisk/whisk"
+)
+
+// withConfigFile runs f with the properties file at a path in a new temporary directory, which does not exist yet
+func withConfigFile(t *testing.T, f func(path string)) {
+    dir, err := ioutil.TempDir("", "wsk")
+    if err != nil {
+        t.Fatalf("ioutil.TempDir() failed: %s", err)
+    }
+    defer os.RemoveAll(dir)
+
+    origPropsFile := Properties.PropsFile
+    defer func() { Properties.PropsFile = origPropsFile }()
+    Properties.PropsFile = filepath.Join(dir, ".wskprops")
+
+    f(Properties.PropsFile)
+}
+
+// runConfigCmd runs a config command with args, and returns its output and error
+func runConfigCmd(t *testing.T, cmd *cobra.Command, args ...string) (string, error) {
+    var err error
+    output := captureOutput(t, func() { err = cmd.RunE(cmd, args) })
+
+    return output, err
+}
+
+// getConfigFileLines returns the sorted lines of the properties file at path
+func getConfigFileLines(t *testing.T, path string) []string {
+    data, err := ioutil.ReadFile(path)
+    if err != nil {
+        t.Fatalf("ioutil.ReadFile(%s) failed: %s", path, err)
+    }
+
+    lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+    sort.Strings(lines)
+
+    return lines
+}
+
+func TestConfigSetGetUnset(t *testing.T) {
+    withConfigFile(t, func(path string) {
+        steps := []struct {
+            cmd     *cobra.Command
+            args    []string
+            output  string
+            lines   []string    // the lines of the file afterwards, sorted
+        }{
+            {configSetCmd, []string{"APIHOST", "example.com"}, "ok: APIHOST set to example.com\n",
+                []string{"APIHOST=example.com"}},
+            // Keys are not case sensitive
+            {configSetCmd, []string{"auth", "user:secretkey1234"}, "ok: AUTH set to user:secretkey1234\n",
+                []string{"APIHOST=example.com", "AUTH=user:secretkey1234"}},
+            {configSetCmd, []string{"Namespace", "ns"}, "ok: NAMESPACE set to ns\n",
+                []string{"APIHOST=example.com", "AUTH=user:secretkey1234", "NAMESPACE=ns"}},
+            {configGetCmd, []string{"APIHOST"}, "example.com\n", nil},
+            // A single value is not masked
+            {configGetCmd, []string{"auth"}, "user:secretkey1234\n", nil},
+            // All values are printed in the order of configKeys, with the auth key masked
+            {configGetCmd, []string{}, "AUTH=xxxx…1234\nAPIHOST=example.com\nNAMESPACE=ns\n", nil},
+            {configSetCmd, []string{"APIHOST", "other.example.com"}, "ok: APIHOST set to other.example.com\n",
+                []string{"APIHOST=other.example.com", "AUTH=user:secretkey1234", "NAMESPACE=ns"}},
+            {configUnsetCmd, []string{"apihost"}, "ok: APIHOST unset.\n",
+                []string{"AUTH=user:secretkey1234", "NAMESPACE=ns"}},
+            {configGetCmd, []string{"APIHOST"}, "\n", nil},
+            {configGetCmd, []string{}, "AUTH=xxxx…1234\nNAMESPACE=ns\n", nil},
+        }
+
+        for _, step := range steps {
+            output, err := runConfigCmd(t, step.cmd, step.args...)
+            if err != nil {
+                t.Fatalf("config %s %q failed: %s", step.cmd.Name(), step.args, err)
+            }
+            if output != step.output {
+                t.Errorf("config %s %q printed %q, want %q", step.cmd.Name(), step.args, output, step.output)
+            }
+            if step.lines != nil {
+                if lines := getConfigFileLines(t, path); strings.Join(lines, "\n") != strings.Join(step.lines, "\n") {
+                    t.Errorf("after config %s %q, the properties file holds %q, want %q", step.cmd.Name(), step.args,
+                        lines, step.lines)
+                }
+            }
+        }
+    })
+}
+
+func TestConfigGetWithoutFile(t *testing.T) {
+    withConfigFile(t, func(path string) {
+        if output, err := runConfigCmd(t, configGetCmd); err != nil || output != "" {
+            t.Errorf("config get without a properties file = %q, %v; want no output", output, err)
+        }
+        if _, err := os.Stat(path); !os.IsNotExist(err) {
+            t.Errorf("config get created the properties file")
+        }
+    })
+}
+
+func TestConfigInvalidKey(t *testing.T) {
+    withConfigFile(t, func(path string) {
+        tests := []struct {
+            cmd     *cobra.Command
+            args    []string
+        }{
+            {configSetCmd, []string{"FOO", "bar"}},
+            {configSetCmd, []string{"", "bar"}},
+            {configGetCmd, []string{"foo"}},
+            {configUnsetCmd, []string{"APIHOSTS"}},
+        }
+
+        for _, test := range tests {
+            _, err := runConfigCmd(t, test.cmd, test.args...)
+            whiskErr, ok := err.(*whisk.WskError)
+            if !ok || whiskErr.ExitCode != whisk.EXITCODE_ERR_USAGE || !whiskErr.DisplayUsage ||
+                    !strings.Contains(err.Error(), "Valid keys are: AUTH, APIHOST") {
+                t.Errorf("config %s %q error = %#v, want a usage error listing the valid keys", test.cmd.Name(),
+                    test.args, err)
+            }
+        }
+
+        if _, err := os.Stat(path); !os.IsNotExist(err) {
+            t.Errorf("config commands with invalid keys created the properties file")
+        }
+    })
+}
+
+func TestConfigArgs(t *testing.T) {
+    withConfigFile(t, func(path string) {
+        tests := []struct {
+            cmd     *cobra.Command
+            args    []string
+        }{
+            {configSetCmd, []string{"APIHOST"}},
+            {configSetCmd, []string{"APIHOST", "a", "b"}},
+            {configGetCmd, []string{"APIHOST", "AUTH"}},
+            {configUnsetCmd, []string{}},
+        }
+
+        for _, test := range tests {
+            _, err := runConfigCmd(t, test.cmd, test.args...)
+            if whiskErr, ok := err.(*whisk.WskError); !ok || !whiskErr.DisplayUsage {
+                t.Errorf("config %s %q error = %#v, want an error with usage", test.cmd.Name(), test.args, err)
+            }
+        }
+    })
+}
@@ -0,0 +1,607 @@
+/*
+ * Copyright 2015-2016 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "net/http"
+    "reflect"
+    "sort"
+    "strings"
+
+    "github.com/spf13/cobra"
+    "github.com/fatih/color"
+    "github.com/mattn/go-colorable"
+
+    "../../go-whisk/whisk"
+    "../wski18n"
+)
+
+// Manifest file read by deploy when --manifest is not given
+const DEFAULT_MANIFEST_FILE = "manifest.yaml"
+
+// deployment is an entity of a manifest, along with how it differs from the deployed entity of the same name
+type deployment struct {
+    collection      string          // package, action, trigger or rule
+    name            string          // the name given in the manifest
+    qualifiedName   QualifiedName
+    entity          interface{}     // the manifest entity
+    exists          bool            // whether the entity is already deployed
+    changes         []string        // the fields of the deployed entity that differ from the manifest
+    feed            string          // the feed of the deployed trigger, if any
+}
+
+// changed reports whether the entity needs to be created or updated to match the manifest
+func (d *deployment) changed() bool {
+    return !d.exists || len(d.changes) > 0
+}
+
+// deployCmd represents the deploy command
+var deployCmd = &cobra.Command{
+    Use:   "deploy [--manifest FILE]",
+    Short: wski18n.T("create the packages, actions, triggers and rules described by a manifest"),
+    SilenceUsage:   true,
+    SilenceErrors:  true,
+    PreRunE: setupClientConfig,
+    RunE: func(cmd *cobra.Command, args []string) error {
+        var err error
+        var manifest *Manifest
+        var deployments []*deployment
+
+        if whiskErr := checkArgs(args, 0, 0, "Deploy", wski18n.T("No arguments are required.")); whiskErr != nil {
+            return whiskErr
+        }
+
+        if flags.deploy.dryRun && flags.deploy.diff {
+            return nonNestedError(wski18n.T("The --dry-run and --diff options cannot be used together."))
+        }
+
+        if manifest, err = readManifest(flags.deploy.manifest); err != nil {
+            return err
+        }
+
+        if deployments, err = getDeployments(manifest); err != nil {
+            return err
+        }
+
+        if flags.deploy.dryRun {
+            for _, d := range deployments {
+                fmt.Fprintf(color.Output, wski18n.T("would deploy {{.collection}} {{.name}}\n",
+                    map[string]interface{}{"collection": d.collection, "name": boldString(d.name)}))
+            }
+            return nil
+        }
+
+        for _, d := range deployments {
+            if err = getDeploymentChanges(d); err != nil {
+                return err
+            }
+        }
+
+        if flags.deploy.diff {
+            printDeploymentChanges(deployments)
+            return nil
+        }
+
+        unchanged := 0
+        for _, d := range deployments {
+            if !d.changed() {
+                unchanged++
+                continue
+            }
+
+            if err = deployEntity(d); err != nil {
+                return err
+            }
+
+            if d.exists {
+                fmt.Fprintf(color.Output, wski18n.T("{{.ok}} updated {{.collection}} {{.name}}\n",
+                    map[string]interface{}{"ok": color.GreenString("ok:"), "collection": d.collection,
+                        "name": boldString(d.name)}))
+            } else {
+                fmt.Fprintf(color.Output, wski18n.T("{{.ok}} created {{.collection}} {{.name}}\n",
+                    map[string]interface{}{"ok": color.GreenString("ok:"), "collection": d.collection,
+                        "name": boldString(d.name)}))
+            }
+        }
+
+        fmt.Fprintf(color.Output, wski18n.T("{{.ok}} deployed {{.changed}} entities, {{.unchanged}} unchanged\n",
+            map[string]interface{}{"ok": color.GreenString("ok:"), "changed": len(deployments) - unchanged,
+                "unchanged": unchanged}))
+        return nil
+    },
+}
+
+// undeployCmd represents the undeploy command
+var undeployCmd = &cobra.Command{
+    Use:   "undeploy [--manifest FILE]",
+    Short: wski18n.T("delete the packages, actions, triggers and rules described by a manifest"),
+    SilenceUsage:   true,
+    SilenceErrors:  true,
+    PreRunE: setupClientConfig,
+    RunE: func(cmd *cobra.Command, args []string) error {
+        var err error
+        var manifest *Manifest
+        var deployments []*deployment
+
+        if whiskErr := checkArgs(args, 0, 0, "Undeploy", wski18n.T("No arguments are required.")); whiskErr != nil {
+            return whiskErr
+        }
+
+        if manifest, err = readManifest(flags.deploy.manifest); err != nil {
+            return err
+        }
+
+        if deployments, err = getDeployments(manifest); err != nil {
+            return err
+        }
+
+        // Entities are deleted in the reverse of the order they are deployed, so that nothing refers to a deleted
+        // entity: rules, triggers, actions and then packages
+        for i, j := 0, len(deployments) - 1; i < j; i, j = i + 1, j - 1 {
+            deployments[i], deployments[j] = deployments[j], deployments[i]
+        }
+
+        if flags.deploy.dryRun {
+            for _, d := range deployments {
+                fmt.Fprintf(color.Output, wski18n.T("would undeploy {{.collection}} {{.name}}\n",
+                    map[string]interface{}{"collection": d.collection, "name": boldString(d.name)}))
+            }
+            return nil
+        }
+
+        missing, failed := 0, 0
+        for _, d := range deployments {
+            err = undeployEntity(d)
+            if getHttpErrorStatus(err) == http.StatusNotFound {
+                missing++
+                fmt.Fprintf(colorable.NewColorableStderr(),
+                    wski18n.T("{{.warning}} {{.collection}} {{.name}} does not exist\n",
+                        map[string]interface{}{"warning": color.YellowString("warning:"), "collection": d.collection,
+                            "name": boldString(d.name)}))
+            } else if err != nil {
+                failed++
+                whisk.Debug(whisk.DbgError, "Unable to delete %s '%s': %s\n", d.collection, d.name, err)
+                fmt.Fprintf(colorable.NewColorableStderr(),
+                    wski18n.T("{{.error}} Unable to delete {{.collection}} '{{.name}}': {{.err}}\n",
+                        map[string]interface{}{"error": color.RedString("error:"), "collection": d.collection,
+                            "name": d.name, "err": err}))
+            } else {
+                fmt.Fprintf(color.Output, wski18n.T("{{.ok}} deleted {{.collection}} {{.name}}\n",
+                    map[string]interface{}{"ok": color.GreenString("ok:"), "collection": d.collection,
+                        "name": boldString(d.name)}))
+            }
+        }
+
+        fmt.Fprintf(color.Output,
+            wski18n.T("{{.ok}} undeployed {{.count}} entities, {{.missing}} not found, failed {{.failed}}\n",
+                map[string]interface{}{"ok": color.GreenString("ok:"), "count": len(deployments) - missing - failed,
+                    "missing": missing, "failed": failed}))
+
+        if failed > 0 {
+            errStr := wski18n.T("Unable to undeploy {{.failed}} of {{.total}} entities",
+                map[string]interface{}{"failed": failed, "total": len(deployments)})
+            return whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG,
+                whisk.NO_DISPLAY_USAGE)
+        }
+
+        return nil
+    },
+}
+
+// getDeployments returns the entities of manifest in the order they must be created: packages, actions, triggers and
+// then rules, so that every entity is created after the entities it refers to
+func getDeployments(manifest *Manifest) ([]*deployment, error) {
+    var deployments []*deployment
+
+    add := func(collection string, name string, entity interface{}) error {
+        if len(name) == 0 {
+            errMsg := wski18n.T("The manifest contains a {{.collection}} without a name",
+                map[string]interface{}{"collection": collection})
+            return whisk.MakeWskError(errors.New(errMsg), whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG,
+                whisk.NO_DISPLAY_USAGE)
+        }
+
+        qualifiedName, err := parseQualifiedName(name)
+        if err != nil {
+            return parseQualifiedNameError(name, err)
+        }
+
+        deployments = append(deployments, &deployment{collection: collection, name: name,
+            qualifiedName: qualifiedName, entity: entity})
+        return nil
+    }
+
+    for _, xPackage := range manifest.Packages {
+        if err := add("package", xPackage.Name, xPackage); err != nil {
+            return nil, err
+        }
+    }
+
+    for _, action := range manifest.Actions {
+        if err := add("action", action.Name, action); err != nil {
+            return nil, err
+        }
+    }
+
+    for _, trigger := range manifest.Triggers {
+        if err := add("trigger", trigger.Name, trigger); err != nil {
+            return nil, err
+        }
+    }
+
+    for _, rule := range manifest.Rules {
+        status := strings.ToLower(rule.Status)
+        if len(whisk.GetRuleEntityName(rule.Trigger)) == 0 || len(whisk.GetRuleEntityName(rule.Action)) == 0 ||
+                (len(status) > 0 && status != "active" && status != "inactive") {
+            errMsg := wski18n.T("Rule '{{.name}}' in the manifest needs a trigger, an action and a status of active or inactive",
+                map[string]interface{}{"name": rule.Name})
+            return nil, whisk.MakeWskError(errors.New(errMsg), whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG,
+                whisk.NO_DISPLAY_USAGE)
+        }
+
+        if err := add("rule", rule.Name, rule); err != nil {
+            return nil, err
+        }
+    }
+
+    return deployments, nil
+}
+
+// getDeploymentChanges fetches the deployed entity named by d, and records whether it exists and which of its fields
+// differ from the manifest
+func getDeploymentChanges(d *deployment) error {
+    var want, have interface{}
+    var err error
+
+    client.Namespace = d.qualifiedName.namespace
+    entityName := d.qualifiedName.entityName
+
+    switch d.collection {
+    case "package":
+        want = d.entity
+        have, _, err = client.Packages.Get(entityName)
+    case "action":
+        var action *whisk.Action
+        want = d.entity
+        if action, _, err = client.Actions.Get(entityName); err == nil {
+            // The server annotates actions with the kind of their code
+            action.Annotations = action.Annotations.Delete("exec")
+            have = action
+        }
+    case "trigger":
+        var trigger *whisk.Trigger
+        if trigger, _, err = client.Triggers.Get(entityName); err == nil {
+            d.feed = getValueString(trigger.Annotations, "feed")
+            have = trigger
+        }
+
+        // The parameters of a trigger with a feed are passed to the feed action rather than stored
+        manifestTrigger := *d.entity.(*whisk.Trigger)
+        if len(getValueString(manifestTrigger.Annotations, "feed")) > 0 {
+            manifestTrigger.Parameters = nil
+        }
+        want = &manifestTrigger
+    case "rule":
+        var rule *whisk.Rule
+        if rule, _, err = client.Rules.Get(entityName); err == nil {
+            // Rules are compared as manifests, so that their trigger and action names have the same form
+            manifestRule := d.entity.(*whisk.Rule)
+            want = &RuleManifest{
+                Trigger:        getRelativeEntityName(whisk.GetRuleEntityName(manifestRule.Trigger), rule.Namespace),
+                Action:         getRelativeEntityName(whisk.GetRuleEntityName(manifestRule.Action), rule.Namespace),
+                Status:         getManifestRuleStatus(manifestRule),
+                Annotations:    manifestRule.Annotations,
+            }
+            have = newRuleManifest(rule)
+        }
+    }
+
+    if getHttpErrorStatus(err) == http.StatusNotFound {
+        d.exists = false
+        return nil
+    } else if err != nil {
+        whisk.Debug(whisk.DbgError, "Unable to get %s '%s': %s\n", d.collection, entityName, err)
+        errStr := wski18n.T("Unable to get {{.collection}} '{{.name}}': {{.err}}",
+            map[string]interface{}{"collection": d.collection, "name": d.name, "err": err})
+        return whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG,
+            whisk.NO_DISPLAY_USAGE)
+    }
+
+    d.exists = true
+    d.changes = getChangedFields(want, have)
+    return nil
+}
+
+// getChangedFields returns the sorted names of the top level fields of want that have does not contain. The server
+// adds defaults, such as limits and annotations, to the entities it stores, so fields only need to contain the values
+// given in want. Names, namespaces and versions are not compared.
+func getChangedFields(want interface{}, have interface{}) []string {
+    var changes []string
+    wantFields, haveFields := getJSONObject(want), getJSONObject(have)
+
+    for key, value := range wantFields {
+        if key == "name" || key == "namespace" || key == "version" {
+            continue
+        }
+
+        if !containsJSON(value, haveFields[key]) {
+            changes = append(changes, key)
+        }
+    }
+
+    sort.Strings(changes)
+    return changes
+}
+
+// getJSONObject returns v as the generic JSON object it marshals to, or an empty object when v is not a JSON object
+func getJSONObject(v interface{}) map[string]interface{} {
+    object := make(map[string]interface{})
+
+    if jsonBytes, err := json.Marshal(v); err == nil {
+        json.Unmarshal(jsonBytes, &object)
+    }
+
+    return object
+}
+
+// containsJSON reports whether the generic JSON value have contains want. Objects contain the fields of want, as the
+// server adds defaults to them. Key value arrays must have the same keys in both, with equal values, and all other
+// values must be equal.
+func containsJSON(want interface{}, have interface{}) bool {
+    switch want := want.(type) {
+    case map[string]interface{}:
+        haveObject, ok := have.(map[string]interface{})
+        if !ok {
+            return false
+        }
+
+        for key, value := range want {
+            if !containsJSON(value, haveObject[key]) {
+                return false
+            }
+        }
+        return true
+    case []interface{}:
+        haveArray, ok := have.([]interface{})
+        if !ok {
+            return false
+        }
+
+        if isKeyValueJSONArray(want) && isKeyValueJSONArray(haveArray) {
+            return hasKeyValuesOf(want, haveArray) && hasKeyValuesOf(haveArray, want)
+        }
+
+        if len(want) != len(haveArray) {
+            return false
+        }
+
+        for i := range want {
+            if !containsJSON(want[i], haveArray[i]) {
+                return false
+            }
+        }
+        return true
+    }
+
+    return reflect.DeepEqual(want, have)
+}
+
+// hasKeyValuesOf reports whether every key of the generic JSON key value array want is in have, with an equal value
+func hasKeyValuesOf(want []interface{}, have []interface{}) bool {
+    for _, wantItem := range want {
+        found := false
+        for _, haveItem := range have {
+            wantKeyValue, haveKeyValue := wantItem.(map[string]interface{}), haveItem.(map[string]interface{})
+            if wantKeyValue["key"] == haveKeyValue["key"] {
+                found = reflect.DeepEqual(wantKeyValue["value"], haveKeyValue["value"])
+                break
+            }
+        }
+        if !found {
+            return false
+        }
+    }
+
+    return true
+}
+
+// isKeyValueJSONArray reports whether every item of a generic JSON array is a key value object, as used for
+// parameters and annotations
+func isKeyValueJSONArray(array []interface{}) bool {
+    for _, item := range array {
+        keyValue, ok := item.(map[string]interface{})
+        if !ok {
+            return false
+        }
+
+        if _, ok = keyValue["key"].(string); !ok {
+            return false
+        }
+    }
+
+    return true
+}
+
+// printDeploymentChanges prints the entities that deploy would create, marked with +, and those it would update,
+// marked with ~ and followed by the fields that differ from the manifest
+func printDeploymentChanges(deployments []*deployment) {
+    changed := 0
+
+    for _, d := range deployments {
+        if !d.exists {
+            fmt.Fprintf(color.Output, "%s %s %s\n", color.GreenString("+"), d.collection, boldString(d.name))
+            changed++
+        } else if len(d.changes) > 0 {
+            fmt.Fprintf(color.Output, "%s %s %s (%s)\n", color.YellowString("~"), d.collection, boldString(d.name),
+                strings.Join(d.changes, ", "))
+            changed++
+        }
+    }
+
+    if changed == 0 {
+        fmt.Fprintf(color.Output, wski18n.T("The deployed entities match the manifest\n"))
+    }
+}
+
+// deployEntity creates the entity of d, or replaces the deployed entity of the same name
+func deployEntity(d *deployment) error {
+    var err error
+
+    client.Namespace = d.qualifiedName.namespace
+    entityName := d.qualifiedName.entityName
+
+    switch d.collection {
+    case "package":
+        xPackage := *d.entity.(*whisk.Package)
+        xPackage.Name, xPackage.Namespace = entityName, ""
+        _, _, err = client.Packages.Insert(&xPackage, true)
+    case "action":
+        action := *d.entity.(*whisk.Action)
+        action.Name, action.Namespace = entityName, ""
+        _, _, err = client.Actions.Insert(&action, true)
+    case "trigger":
+        err = deployTrigger(d)
+    case "rule":
+        rule := *d.entity.(*whisk.Rule)
+        status := getManifestRuleStatus(&rule)
+        rule.Name, rule.Namespace, rule.Status = entityName, "", ""
+        rule.Trigger = getQualifiedName(whisk.GetRuleEntityName(rule.Trigger), d.qualifiedName.namespace)
+        rule.Action = getQualifiedName(whisk.GetRuleEntityName(rule.Action), d.qualifiedName.namespace)
+
+        // The server creates rules active, and makes a rule it replaces inactive
+        if _, _, err = client.Rules.Insert(&rule, true); err == nil && (d.exists || status != "active") {
+            // A conflict means the rule is already in that state
+            if _, _, err = client.Rules.SetState(entityName, status); getHttpErrorStatus(err) == http.StatusConflict {
+                err = nil
+            }
+        }
+    }
+
+    if err != nil {
+        whisk.Debug(whisk.DbgError, "Unable to deploy %s '%s': %s\n", d.collection, entityName, err)
+        errStr := wski18n.T("Unable to deploy {{.collection}} '{{.name}}': {{.err}}",
+            map[string]interface{}{"collection": d.collection, "name": d.name, "err": err})
+        return whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG,
+            whisk.NO_DISPLAY_USAGE)
+    }
+
+    return nil
+}
+
+// deployTrigger creates or replaces the trigger of d. A trigger with a feed annotation has its feed configured the
+// same way trigger create and update do, with the parameters of the manifest passed to the feed action.
+func deployTrigger(d *deployment) error {
+    var err error
+
+    trigger := *d.entity.(*whisk.Trigger)
+    trigger.Name, trigger.Namespace = d.qualifiedName.entityName, ""
+
+    feedName := getValueString(trigger.Annotations, "feed")
+    if len(feedName) == 0 {
+        _, _, err = client.Triggers.Insert(&trigger, true)
+        return err
+    }
+
+    var fullFeedName string
+    if fullFeedName, err = getFullFeedName(feedName); err != nil {
+        return parseQualifiedNameError(feedName, err)
+    }
+
+    feedParams, err := getParamArgs(trigger.Parameters)
+    if err != nil {
+        return err
+    }
+
+    trigger.Parameters = nil
+    if _, _, err = client.Triggers.Insert(&trigger, true); err != nil {
+        return err
+    }
+
+    if !d.exists {
+        _, err = createTriggerFeed(d.qualifiedName, fullFeedName, feedParams)
+        return err
+    }
+
+    return updateTriggerFeed(d.qualifiedName, d.feed, fullFeedName, feedParams)
+}
+
+// getParamArgs returns key values as the JSON objects of --param arguments, one for each key
+func getParamArgs(keyValues whisk.KeyValueArr) ([]string, error) {
+    var args []string
+
+    for _, keyValue := range keyValues {
+        jsonBytes, err := json.Marshal(map[string]interface{}{keyValue.Key: keyValue.Value})
+        if err != nil {
+            whisk.Debug(whisk.DbgError, "json.Marshal(%#v) error: %s\n", keyValue, err)
+            errStr := wski18n.T("Invalid parameter argument '{{.param}}': {{.err}}",
+                map[string]interface{}{"param": keyValue.Key, "err": err})
+            return nil, whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG,
+                whisk.NO_DISPLAY_USAGE)
+        }
+        args = append(args, string(jsonBytes))
+    }
+
+    return args, nil
+}
+
+// getManifestRuleStatus returns the status a rule of the manifest is deployed with: active, unless it is inactive
+func getManifestRuleStatus(rule *whisk.Rule) string {
+    if status := strings.ToLower(rule.Status); len(status) > 0 {
+        return status
+    }
+
+    return "active"
+}
+
+// undeployEntity deletes the deployed entity named by d. The feed of a trigger is removed first, as trigger delete
+// does.
+func undeployEntity(d *deployment) error {
+    var err error
+
+    client.Namespace = d.qualifiedName.namespace
+    entityName := d.qualifiedName.entityName
+
+    switch d.collection {
+    case "package":
+        _, err = client.Packages.Delete(entityName)
+    case "action":
+        _, err = client.Actions.Delete(entityName)
+    case "trigger":
+        var trigger *whisk.Trigger
+        if trigger, _, err = client.Triggers.Get(entityName); err == nil {
+            if err = deleteTriggerFeed(d.qualifiedName, trigger, false); err == nil {
+                _, _, err = client.Triggers.Delete(entityName)
+            }
+        }
+    case "rule":
+        _, err = client.Rules.Delete(entityName)
+    }
+
+    return err
+}
+
+func init() {
+    deployCmd.Flags().StringVar(&flags.deploy.manifest, "manifest", DEFAULT_MANIFEST_FILE, wski18n.T("read the manifest from `FILE`, or from standard input when FILE is -"))
+    deployCmd.Flags().BoolVar(&flags.deploy.dryRun, "dry-run", false, wski18n.T("print the entities that would be deployed without calling the API"))
+    deployCmd.Flags().BoolVar(&flags.deploy.diff, "diff", false, wski18n.T("print the entities that differ from the manifest without deploying them"))
+
+    undeployCmd.Flags().StringVar(&flags.deploy.manifest, "manifest", DEFAULT_MANIFEST_FILE, wski18n.T("read the manifest from `FILE`, or from standard input when FILE is -"))
+    undeployCmd.Flags().BoolVar(&flags.deploy.dryRun, "dry-run", false, wski18n.T("print the entities that would be deleted without calling the API"))
+}
@@ -0,0 +1,613 @@
+/*
+ * Copyright 2015-2016 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+
+package commands
+
+import (
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "os"
+    "path/filepath"
+    "reflect"
+    "strings"
+    "sync"
+    "testing"
+
+    "github.com/spf13/cobra"
+
+    "../../go-whisk/whisk"
+)
+
+// entityStore is a namespace ns of entities that answers gets, puts, rule state changes and deletes like the server:
+// it adds defaults to the entities it stores, sends rule triggers and actions as objects, creates rules active and
+// makes the rules it replaces inactive. Invocations of actions in other namespaces, such as feeds, succeed. The
+// requests in failures get the given error status.
+type entityStore struct {
+    mutex       sync.Mutex
+    entities    map[string]map[string]interface{}     // by path below the namespace, such as actions/pkg/a
+    failures    map[string]int                        // by METHOD PATH, such as DELETE actions/b
+}
+
+func newEntityStore() *entityStore {
+    return &entityStore{entities: make(map[string]map[string]interface{})}
+}
+
+func (store *entityStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    store.mutex.Lock()
+    defer store.mutex.Unlock()
+
+    if !strings.HasPrefix(r.URL.Path, "/api/v1/namespaces/ns/") {
+        writeJSON(w, http.StatusOK, map[string]interface{}{})
+        return
+    }
+
+    path := strings.TrimPrefix(r.URL.Path, "/api/v1/namespaces/ns/")
+    if status, found := store.failures[r.Method + " " + path]; found {
+        writeJSON(w, status, map[string]interface{}{"error": "request failed", "code": 1})
+        return
+    }
+
+    parts := strings.SplitN(path, "/", 2)
+    entity, exists := store.entities[path]
+
+    switch {
+    case r.Method == "PUT":
+        var body map[string]interface{}
+        json.NewDecoder(r.Body).Decode(&body)
+        body["namespace"], body["name"], body["version"] = "ns", parts[1][strings.LastIndex(parts[1], "/") + 1:], "0.0.1"
+        if _, found := body["annotations"]; !found {
+            body["annotations"] = []interface{}{}
+        }
+
+        switch parts[0] {
+        case "actions":
+            body["annotations"] = append(body["annotations"].([]interface{}),
+                map[string]interface{}{"key": "exec", "value": "nodejs:6"})
+            body["limits"] = map[string]interface{}{"timeout": 60000.0, "memory": 256.0, "logs": 10.0}
+        case "rules":
+            body["status"] = "active"
+            if exists {
+                body["status"] = "inactive"
+            }
+            for _, key := range []string{"trigger", "action"} {
+                name := body[key].(string)
+                separator := strings.LastIndex(name, "/")
+                body[key] = map[string]interface{}{"path": strings.TrimPrefix(name[:separator], "/"),
+                    "name": name[separator + 1:]}
+            }
+        }
+
+        store.entities[path] = body
+        writeJSON(w, http.StatusOK, body)
+    case !exists:
+        writeJSON(w, http.StatusNotFound, map[string]interface{}{"error": "The requested resource does not exist.", "code": 1})
+    case r.Method == "POST":
+        var state map[string]interface{}
+        json.NewDecoder(r.Body).Decode(&state)
+        if entity["status"] == state["status"] {
+            writeJSON(w, http.StatusConflict, map[string]interface{}{"error": "Rule is already in that state.", "code": 1})
+            return
+        }
+        entity["status"] = state["status"]
+        writeJSON(w, http.StatusOK, map[string]interface{}{})
+    case r.Method == "DELETE":
+        delete(store.entities, path)
+        writeJSON(w, http.StatusOK, entity)
+    default:
+        writeJSON(w, http.StatusOK, entity)
+    }
+}
+
+// testManifest lists its entities in the reverse of the order they are deployed
+const testManifest = `
+rules:
+- name: r
+  trigger: t
+  action: pkg/a
+triggers:
+- name: t
+  parameters:
+  - key: day
+    value: monday
+actions:
+- name: pkg/a
+  exec:
+    kind: nodejs:6
+    code: function main() {}
+  annotations:
+  - key: web-export
+    value: true
+  - key: owner
+    value: me
+- name: b
+  exec:
+    kind: nodejs:6
+    code: function main() { return {}; }
+packages:
+- name: pkg
+  parameters:
+  - key: p
+    value: 1
+`
+
+// runDeploy runs cmd on a manifest file with the given content, and returns its output and error
+func runDeploy(t *testing.T, cmd *cobra.Command, server *testServer, manifest string, setFlags func()) (string, error) {
+    file := writeTestFile(t, "manifest.yaml", manifest)
+    defer os.RemoveAll(filepath.Dir(file))
+
+    server.mutex.Lock()
+    server.requests = nil
+    server.mutex.Unlock()
+    flags = Flags{}
+    flags.deploy.manifest = file
+    if setFlags != nil {
+        setFlags()
+    }
+
+    var err error
+    output := captureOutput(t, func() { err = cmd.RunE(cmd, []string{}) })
+
+    return output, err
+}
+
+// setDeployTestNamespace makes ns the namespace of unqualified names, and returns a function that restores it
+func setDeployTestNamespace() func() {
+    origNamespace := Properties.Namespace
+    Properties.Namespace = "ns"
+
+    return func() { Properties.Namespace = origNamespace }
+}
+
+func TestDeployOrder(t *testing.T) {
+    defer setDeployTestNamespace()()
+    store := newEntityStore()
+    server := newTestServer(t, store.ServeHTTP)
+    defer server.Close()
+
+    output, err := runDeploy(t, deployCmd, server, testManifest, nil)
+    if err != nil {
+        t.Fatalf("deploy failed: %s", err)
+    }
+
+    // Every entity is looked up before any is created, and each is created after the entities it refers to
+    checkRequests(t, server,
+        "GET ns/packages/pkg", "GET ns/actions/pkg/a", "GET ns/actions/b", "GET ns/triggers/t", "GET ns/rules/r",
+        "PUT ns/packages/pkg", "PUT ns/actions/pkg/a", "PUT ns/actions/b", "PUT ns/triggers/t", "PUT ns/rules/r")
+
+    want := "ok: created package pkg\n" +
+        "ok: created action pkg/a\n" +
+        "ok: created action b\n" +
+        "ok: created trigger t\n" +
+        "ok: created rule r\n" +
+        "ok: deployed 5 entities, 0 unchanged\n"
+    if output != want {
+        t.Errorf("deploy printed:\n%s\nwant:\n%s", output, want)
+    }
+
+    rule := server.getRequest("PUT", "ns/rules/r")
+    var body map[string]interface{}
+    if json.Unmarshal([]byte(rule.Body), &body); body["trigger"] != "/ns/t" || body["action"] != "/ns/pkg/a" {
+        t.Errorf("deploy created the rule %s, want the trigger /ns/t and the action /ns/pkg/a", rule.Body)
+    }
+}
+
+func TestDeployIdempotent(t *testing.T) {
+    defer setDeployTestNamespace()()
+    store := newEntityStore()
+    server := newTestServer(t, store.ServeHTTP)
+    defer server.Close()
+
+    if _, err := runDeploy(t, deployCmd, server, testManifest, nil); err != nil {
+        t.Fatalf("deploy failed: %s", err)
+    }
+
+    // The defaults the server adds, such as limits and the exec annotation, are not changes
+    output, err := runDeploy(t, deployCmd, server, testManifest, nil)
+    if err != nil {
+        t.Fatalf("deploying the manifest again failed: %s", err)
+    }
+    checkRequests(t, server,
+        "GET ns/packages/pkg", "GET ns/actions/pkg/a", "GET ns/actions/b", "GET ns/triggers/t", "GET ns/rules/r")
+    if want := "ok: deployed 0 entities, 5 unchanged\n"; output != want {
+        t.Errorf("deploying the manifest again printed %q, want %q", output, want)
+    }
+
+    // Only the changed entities are replaced, and the replaced rule is kept active
+    changed := strings.Replace(testManifest, "return {};", "return null;", 1)
+    changed = strings.Replace(changed, "action: pkg/a", "action: b", 1)
+    output, err = runDeploy(t, deployCmd, server, changed, nil)
+    if err != nil {
+        t.Fatalf("deploying a changed manifest failed: %s", err)
+    }
+    checkRequests(t, server,
+        "GET ns/packages/pkg", "GET ns/actions/pkg/a", "GET ns/actions/b", "GET ns/triggers/t", "GET ns/rules/r",
+        "PUT ns/actions/b", "PUT ns/rules/r", "POST ns/rules/r")
+    if want := "ok: updated action b\nok: updated rule r\nok: deployed 2 entities, 3 unchanged\n"; output != want {
+        t.Errorf("deploying a changed manifest printed %q, want %q", output, want)
+    }
+    if status := store.entities["rules/r"]["status"]; status != "active" {
+        t.Errorf("deploy left the replaced rule %v, want it active", status)
+    }
+}
+
+func TestDeployInactiveRule(t *testing.T) {
+    defer setDeployTestNamespace()()
+    store := newEntityStore()
+    server := newTestServer(t, store.ServeHTTP)
+    defer server.Close()
+
+    manifest := strings.Replace(testManifest, "  action: pkg/a\n", "  action: pkg/a\n  status: inactive\n", 1)
+    for i := 0; i < 2; i++ {
+        if _, err := runDeploy(t, deployCmd, server, manifest, nil); err != nil {
+            t.Fatalf("deploy of an inactive rule failed: %s", err)
+        }
+        if status := store.entities["rules/r"]["status"]; status != "inactive" {
+            t.Errorf("deploy %d left the rule %v, want it inactive", i + 1, status)
+        }
+    }
+}
+
+func TestDeployDryRun(t *testing.T) {
+    defer setDeployTestNamespace()()
+    store := newEntityStore()
+    server := newTestServer(t, store.ServeHTTP)
+    defer server.Close()
+
+    output, err := runDeploy(t, deployCmd, server, testManifest, func() { flags.deploy.dryRun = true })
+    if err != nil {
+        t.Fatalf("deploy --dry-run failed: %s", err)
+    }
+    checkRequests(t, server)
+
+    want := "would deploy package pkg\n" +
+        "would deploy action pkg/a\n" +
+        "would deploy action b\n" +
+        "would deploy trigger t\n" +
+        "would deploy rule r\n"
+    if output != want {
+        t.Errorf("deploy --dry-run printed:\n%s\nwant:\n%s", output, want)
+    }
+}
+
+func TestDeployDiff(t *testing.T) {
+    defer setDeployTestNamespace()()
+    store := newEntityStore()
+    server := newTestServer(t, store.ServeHTTP)
+    defer server.Close()
+
+    if _, err := runDeploy(t, deployCmd, server, testManifest, nil); err != nil {
+        t.Fatalf("deploy failed: %s", err)
+    }
+
+    output, err := runDeploy(t, deployCmd, server, testManifest, func() { flags.deploy.diff = true })
+    if err != nil {
+        t.Fatalf("deploy --diff failed: %s", err)
+    }
+    if want := "The deployed entities match the manifest\n"; output != want {
+        t.Errorf("deploy --diff of a deployed manifest printed %q, want %q", output, want)
+    }
+
+    // Removed parameters and annotations are changes too
+    changed := strings.Replace(testManifest, "    value: monday\n", "    value: tuesday\n", 1)
+    changed = strings.Replace(changed, "  - key: web-export\n    value: true\n", "", 1)
+    changed += "- name: pkg2\n"
+    output, err = runDeploy(t, deployCmd, server, changed, func() { flags.deploy.diff = true })
+    if err != nil {
+        t.Fatalf("deploy --diff failed: %s", err)
+    }
+    for _, request := range server.getRequests() {
+        if !strings.HasPrefix(request, "GET ") {
+            t.Errorf("deploy --diff sent %s, want only gets", request)
+        }
+    }
+
+    want := "+ package pkg2\n" +
+        "~ action pkg/a (annotations)\n" +
+        "~ trigger t (parameters)\n"
+    if output != want {
+        t.Errorf("deploy --diff printed:\n%s\nwant:\n%s", output, want)
+    }
+}
+
+// getFeedEvents returns the lifecycle event and feed of each feed invocation received by server, and checks that
+// the invocations are for trigger /ns/t
+func getFeedEvents(t *testing.T, server *testServer) []string {
+    var events []string
+
+    for _, request := range server.requests {
+        if request.Method != "POST" || !strings.HasPrefix(request.Path, "whisk.system/") {
+            continue
+        }
+
+        var parameters map[string]interface{}
+        json.Unmarshal([]byte(request.Body), &parameters)
+        if parameters[FEED_TRIGGER_NAME] != "/ns/t" || parameters[FEED_AUTH_KEY] != "user:key" {
+            t.Errorf("feed %s was invoked with %s, want it invoked for trigger /ns/t", request.Path, request.Body)
+        }
+        events = append(events, fmt.Sprintf("%s %s cron=%v", parameters[FEED_LIFECYCLE_EVENT], request.Path,
+            parameters["cron"]))
+    }
+
+    return events
+}
+
+func TestDeployTriggerFeed(t *testing.T) {
+    defer setDeployTestNamespace()()
+    store := newEntityStore()
+    server := newTestServer(t, store.ServeHTTP)
+    defer server.Close()
+
+    manifest := "triggers:\n" +
+        "- name: t\n" +
+        "  annotations:\n" +
+        "  - key: feed\n" +
+        "    value: /whisk.system/alarms/alarm\n" +
+        "  parameters:\n" +
+        "  - key: cron\n" +
+        "    value: '* * * * *'\n"
+    tests := []struct {
+        manifest    string
+        events      []string
+    }{
+        // The parameters go to the feed action rather than to the trigger
+        {manifest, []string{"CREATE whisk.system/actions/alarms/alarm cron=* * * * *"}},
+        // An unchanged trigger is left alone
+        {manifest, nil},
+        // A trigger with a new feed has its previous feed removed
+        {strings.Replace(manifest, "alarms/alarm", "alarms/once", 1),
+            []string{"DELETE whisk.system/actions/alarms/alarm cron=<nil>",
+                "CREATE whisk.system/actions/alarms/once cron=* * * * *"}},
+    }
+
+    for i, test := range tests {
+        if _, err := runDeploy(t, deployCmd, server, test.manifest, nil); err != nil {
+            t.Fatalf("deploy %d of a trigger with a feed failed: %s", i + 1, err)
+        }
+
+        if events := getFeedEvents(t, server); !reflect.DeepEqual(events, test.events) {
+            t.Errorf("deploy %d invoked the feeds %q, want %q", i + 1, events, test.events)
+        }
+        if request := server.getRequest("PUT", "ns/triggers/t"); request != nil && strings.Contains(request.Body, "cron") {
+            t.Errorf("deploy %d created the trigger %s, want it without the feed parameters", i + 1, request.Body)
+        }
+    }
+}
+
+func TestDeployErrors(t *testing.T) {
+    defer setDeployTestNamespace()()
+
+    tests := []struct {
+        manifest    string
+        setFlags    func()
+        want        string
+    }{
+        {testManifest, func() { flags.deploy.dryRun, flags.deploy.diff = true, true },
+            "The --dry-run and --diff options cannot be used together."},
+        {"actions:\n- exec:\n    kind: nodejs:6\n", nil, "The manifest contains a action without a name"},
+        {"rules:\n- name: r\n  trigger: t\n", nil,
+            "Rule 'r' in the manifest needs a trigger, an action and a status of active or inactive"},
+        {"rules:\n- name: r\n  trigger: t\n  action: a\n  status: paused\n", nil,
+            "Rule 'r' in the manifest needs a trigger, an action and a status of active or inactive"},
+    }
+
+    for _, test := range tests {
+        store := newEntityStore()
+        server := newTestServer(t, store.ServeHTTP)
+        _, err := runDeploy(t, deployCmd, server, test.manifest, test.setFlags)
+        checkRequests(t, server)
+        server.Close()
+
+        if err == nil || err.Error() != test.want {
+            t.Errorf("deploy of %q error = %v, want %q", test.manifest, err, test.want)
+        }
+    }
+}
+
+func TestContainsJSON(t *testing.T) {
+    tests := []struct {
+        want    string
+        have    string
+        result  bool
+    }{
+        {`1`, `1`, true},
+        {`"a"`, `"b"`, false},
+        {`null`, `null`, true},
+        {`1`, `null`, false},
+        // Objects only need to contain the fields of want, as the server adds defaults
+        {`{"a": 1}`, `{"a": 1, "b": 2}`, true},
+        {`{"a": 1, "b": 2}`, `{"a": 1}`, false},
+        {`{"a": {"b": 1}}`, `{"a": {"b": 1, "c": 2}}`, true},
+        {`{"a": 1}`, `[1]`, false},
+        // Arrays must have the same items, in order
+        {`[1, 2]`, `[1, 2]`, true},
+        {`[1, 2]`, `[2, 1]`, false},
+        {`[1]`, `[1, 2]`, false},
+        {`[{"a": 1}]`, `[{"a": 1, "b": 2}]`, true},
+        // Key value arrays must have the same keys, in any order
+        {`[{"key": "a", "value": 1}, {"key": "b", "value": 2}]`, `[{"key": "b", "value": 2}, {"key": "a", "value": 1}]`, true},
+        {`[{"key": "a", "value": 1}]`, `[{"key": "a", "value": 2}]`, false},
+        {`[{"key": "a", "value": 1}]`, `[{"key": "a", "value": 1}, {"key": "b", "value": 2}]`, false},
+        {`[{"key": "a", "value": 1}, {"key": "b", "value": 2}]`, `[{"key": "a", "value": 1}]`, false},
+        {`[{"key": "a", "value": {"x": 1}}]`, `[{"key": "a", "value": {"x": 1, "y": 2}}]`, false},
+        {`[]`, `[]`, true},
+    }
+
+    for _, test := range tests {
+        var want, have interface{}
+        json.Unmarshal([]byte(test.want), &want)
+        json.Unmarshal([]byte(test.have), &have)
+
+        if result := containsJSON(want, have); result != test.result {
+            t.Errorf("containsJSON(%s, %s) = %t, want %t", test.want, test.have, result, test.result)
+        }
+    }
+}
+
+func TestGetChangedFields(t *testing.T) {
+    want := map[string]interface{}{"name": "a", "namespace": "ns", "version": "0.0.1", "publish": false,
+        "parameters": []interface{}{map[string]interface{}{"key": "p", "value": 1}}, "limits": map[string]interface{}{"timeout": 1}}
+    have := map[string]interface{}{"name": "b", "namespace": "other", "version": "0.0.2", "publish": true,
+        "parameters": []interface{}{map[string]interface{}{"key": "p", "value": 1}},
+        "limits": map[string]interface{}{"timeout": 2, "memory": 256}}
+
+    if changes := getChangedFields(want, have); !reflect.DeepEqual(changes, []string{"limits", "publish"}) {
+        t.Errorf("getChangedFields() = %q, want the limits and publish fields", changes)
+    }
+}
+
+func TestUndeployOrder(t *testing.T) {
+    defer setDeployTestNamespace()()
+    store := newEntityStore()
+    server := newTestServer(t, store.ServeHTTP)
+    defer server.Close()
+
+    if _, err := runDeploy(t, deployCmd, server, testManifest, nil); err != nil {
+        t.Fatalf("deploy failed: %s", err)
+    }
+
+    output, err := runDeploy(t, undeployCmd, server, testManifest, nil)
+    if err != nil {
+        t.Fatalf("undeploy failed: %s", err)
+    }
+
+    // Entities are deleted before the entities they refer to; the trigger is fetched for its feed
+    checkRequests(t, server, "DELETE ns/rules/r", "GET ns/triggers/t", "DELETE ns/triggers/t", "DELETE ns/actions/b",
+        "DELETE ns/actions/pkg/a", "DELETE ns/packages/pkg")
+
+    want := "ok: deleted rule r\n" +
+        "ok: deleted trigger t\n" +
+        "ok: deleted action b\n" +
+        "ok: deleted action pkg/a\n" +
+        "ok: deleted package pkg\n" +
+        "ok: undeployed 5 entities, 0 not found, failed 0\n"
+    if output != want {
+        t.Errorf("undeploy printed:\n%s\nwant:\n%s", output, want)
+    }
+    if len(store.entities) > 0 {
+        t.Errorf("undeploy left %v", store.entities)
+    }
+}
+
+func TestUndeployMissing(t *testing.T) {
+    defer setDeployTestNamespace()()
+    store := newEntityStore()
+    server := newTestServer(t, store.ServeHTTP)
+    defer server.Close()
+
+    if _, err := runDeploy(t, deployCmd, server, testManifest, nil); err != nil {
+        t.Fatalf("deploy failed: %s", err)
+    }
+    delete(store.entities, "rules/r")
+    delete(store.entities, "actions/b")
+
+    var output string
+    var err error
+    stderr := captureStderr(t, func() { output, err = runDeploy(t, undeployCmd, server, testManifest, nil) })
+    if err != nil {
+        t.Fatalf("undeploy with missing entities failed: %s", err)
+    }
+
+    if want := "warning: rule r does not exist\nwarning: action b does not exist\n"; stderr != want {
+        t.Errorf("undeploy with missing entities warned %q, want %q", stderr, want)
+    }
+    if want := "ok: undeployed 3 entities, 2 not found, failed 0\n"; !strings.HasSuffix(output, want) {
+        t.Errorf("undeploy with missing entities printed:\n%s\nwant it to end with %q", output, want)
+    }
+    if len(store.entities) > 0 {
+        t.Errorf("undeploy left %v", store.entities)
+    }
+}
+
+func TestUndeployFailure(t *testing.T) {
+    defer setDeployTestNamespace()()
+    store := newEntityStore()
+    server := newTestServer(t, store.ServeHTTP)
+    defer server.Close()
+
+    if _, err := runDeploy(t, deployCmd, server, testManifest, nil); err != nil {
+        t.Fatalf("deploy failed: %s", err)
+    }
+    delete(store.entities, "rules/r")
+    store.failures = map[string]int{"DELETE actions/b": http.StatusInternalServerError}
+
+    var output string
+    var err error
+    stderr := captureStderr(t, func() { output, err = runDeploy(t, undeployCmd, server, testManifest, nil) })
+
+    // The other entities are still deleted
+    checkRequests(t, server, "DELETE ns/rules/r", "GET ns/triggers/t", "DELETE ns/triggers/t", "DELETE ns/actions/b",
+        "DELETE ns/actions/pkg/a", "DELETE ns/packages/pkg")
+    if whiskErr, ok := err.(*whisk.WskError); !ok || whiskErr.Error() != "Unable to undeploy 1 of 5 entities" ||
+            whiskErr.ExitCode != whisk.EXITCODE_ERR_GENERAL {
+        t.Errorf("undeploy with a failed deletion error = %#v, want a general error", err)
+    }
+    if want := "warning: rule r does not exist\nerror: Unable to delete action 'b': request failed (code 1)\n"; stderr != want {
+        t.Errorf("undeploy with a failed deletion printed the errors %q, want %q", stderr, want)
+    }
+    if want := "ok: undeployed 3 entities, 1 not found, failed 1\n"; !strings.HasSuffix(output, want) {
+        t.Errorf("undeploy with a failed deletion printed:\n%s\nwant it to end with %q", output, want)
+    }
+}
+
+func TestUndeployDryRun(t *testing.T) {
+    defer setDeployTestNamespace()()
+    store := newEntityStore()
+    server := newTestServer(t, store.ServeHTTP)
+    defer server.Close()
+
+    output, err := runDeploy(t, undeployCmd, server, testManifest, func() { flags.deploy.dryRun = true })
+    if err != nil {
+        t.Fatalf("undeploy --dry-run failed: %s", err)
+    }
+    checkRequests(t, server)
+
+    want := "would undeploy rule r\n" +
+        "would undeploy trigger t\n" +
+        "would undeploy action b\n" +
+        "would undeploy action pkg/a\n" +
+        "would undeploy package pkg\n"
+    if output != want {
+        t.Errorf("undeploy --dry-run printed:\n%s\nwant:\n%s", output, want)
+    }
+}
+
+func TestUndeployTriggerFeed(t *testing.T) {
+    defer setDeployTestNamespace()()
+    store := newEntityStore()
+    server := newTestServer(t, store.ServeHTTP)
+    defer server.Close()
+
+    manifest := "triggers:\n" +
+        "- name: t\n" +
+        "  annotations:\n" +
+        "  - key: feed\n" +
+        "    value: /whisk.system/alarms/alarm\n"
+    if _, err := runDeploy(t, deployCmd, server, manifest, nil); err != nil {
+        t.Fatalf("deploy of a trigger with a feed failed: %s", err)
+    }
+
+    if _, err := runDeploy(t, undeployCmd, server, manifest, nil); err != nil {
+        t.Fatalf("undeploy of a trigger with a feed failed: %s", err)
+    }
+
+    // The feed is removed before the trigger
+    checkRequests(t, server, "GET ns/triggers/t", "POST whisk.system/actions/alarms/alarm", "DELETE ns/triggers/t")
+    if events := getFeedEvents(t, server); !reflect.DeepEqual(events, []string{"DELETE whisk.system/actions/alarms/alarm cron=<nil>"}) {
+        t.Errorf("undeploy invoked the feeds %q, want a DELETE event for the alarm feed", events)
+    }
+}
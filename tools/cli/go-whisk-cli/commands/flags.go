@@ -38,10 +38,20 @@ type Flags struct {
     global struct {
         verbose     bool
         debug       bool
+        debugFile   string
         auth        string
         apihost     string
         apiversion  string
         insecure    bool
+        retries     int
+        output      string
+        profile     string
+        connectTimeout  int
+        requestTimeout  int
+        color       string
+        tlsCert     string
+        tlsKey      string
+        tlsCA       string
     }
 
     common struct {
@@ -58,6 +68,9 @@ type Flags struct {
         feed        string  // name of feed
         detail      bool
         format      string
+        all         bool    // fetch every page of a collection
+        delParam    []string    // update: keys of the existing parameters to remove
+        delAnnotation []string  // update: keys of the existing annotations to remove
     }
 
     property struct {
@@ -73,31 +86,77 @@ type Flags struct {
         apihostSet      string
         apiversionSet   string
         namespaceSet    string
+        proxy           bool
+        cert            bool
+        proxySet        string
+        certSet         string
+        profiles        bool
     }
 
     action ActionFlags
 
     activation struct {
         action          string // retrieve results for this action
-        upto            int64  // retrieve results up to certain time
-        since           int64  // retrieve results after certain time
+        upto            string // retrieve results up to certain time
+        since           string // retrieve results after certain time
         seconds         int    // stop polling for activation upda
         sinceSeconds    int
         sinceMinutes    int
         sinceHours      int
         sinceDays       int
+        pollSince       string // start polling since a time or duration ago
+        last            bool   // use the most recent activation
         exit            int
+        exitAfter       int    // stop watching after this many activations
+        follow          bool   // keep printing logs until the activation ends
+        interval        int    // seconds between polls for new logs
+        timeout         int    // seconds to follow logs before giving up
     }
 
     // rule
     rule struct {
-        disable bool
-        summary bool
+        disable      bool
+        summary      bool
+        nameSort     bool
+        status       string
+        payload      string
+        timeout      int
+        strict       bool
+        stateTimeout int    // seconds to wait for rule enable or disable
+        concurrency  int    // concurrent requests of bulk-enable and bulk-disable
+        json         bool   // list rules as JSON; the same as --output json
+        force        bool   // delete: treat a missing rule as deleted; export: replace the file
+        format       string // export: manifest format, json or yaml
+        exportFile   string // export: file to write the manifest to
+        overwrite    bool   // import: replace an existing rule
+        trigger      string // list only the rules of this trigger
+        action       string // list only the rules of this action
+    }
+
+    // package
+    xPackage struct {
+        exportFile  string
+        force       bool
+        overwrite   bool
+        update      bool    // bind: refresh an existing binding from its package
+        nameSort    bool
+    }
+
+    // namespace
+    namespace struct {
+        entities    string  // comma separated entity collections to list
+        count       bool    // list: show the entity counts of each namespace
+        concurrency int     // list: concurrent count requests per namespace
     }
 
     // trigger
     trigger struct {
-        summary bool
+        summary     bool
+        follow      bool
+        timeout     int
+        forceFeed   bool
+        result      bool
+        nameSort    bool
     }
 
     // api
@@ -110,21 +169,54 @@ type Flags struct {
         configfile string
         resptype   string
     }
+
+    // deploy and undeploy
+    deploy struct {
+        manifest string
+        dryRun   bool
+        diff     bool
+    }
 }
 
 
 type ActionFlags struct {
-    docker      string
-    native      bool
-    copy        bool
-    web         string
-    sequence    bool
-    timeout     int
-    memory      int
-    logsize     int
-    result      bool
-    kind        string
-    main        string
+    docker        string
+    native        bool
+    copy          bool
+    web           string
+    sequence      bool
+    timeout       int
+    memory        int
+    logsize       int
+    result        bool
+    kind          string
+    main          string
+    filterKind    string
+    overwrite     bool
+    update        bool      // import: the same as --overwrite
+    name          string
+    save          bool
+    saveAs        string
+    raw           bool
+    exportFile    string
+    force         bool
+    showVersion   bool
+    skipNamespace bool
+    position      int
+    wait          int
+    data          string
+    paramJSON     string
+    mergeParams   bool
+    mergeAnnots   bool
+    deleteParam   []string
+    namedParam    []string
+    invokeTimeout int
+    invokeAfter   bool
+    webInvoke     bool      // invoke: call the web action URL instead of the invoke API
+    webMethod     string    // invoke --web: HTTP method
+    webAuthToken  string    // invoke --web: secret of a web action that requires authentication
+    streamResult  bool      // invoke: print the activation logs while waiting for the result
+    ifMatch       string    // update: ETag the action must still have
 }
 
 func IsVerbose() bool {
@@ -0,0 +1,192 @@
+/*
+ * Copyright 2015-2016 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+    "bytes"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "os"
+    "strings"
+
+    "../../go-whisk/whisk"
+    "../wski18n"
+
+    "github.com/ghodss/yaml"
+)
+
+// RuleManifest is the file format written by rule export and read by rule import, as YAML or JSON. The trigger and
+// action names are relative to the namespace of the rule, unless they belong to another namespace; those names are
+// fully qualified.
+type RuleManifest struct {
+    Name        string              `json:"name"`
+    Trigger     string              `json:"trigger"`
+    Action      string              `json:"action"`
+    Status      string              `json:"status,omitempty"`
+    Annotations whisk.KeyValueArr   `json:"annotations,omitempty"`
+}
+
+// Manifest is the file format read by deploy, as YAML or JSON. It lists the packages, actions, triggers and rules to
+// create; entity names may be qualified with a namespace, and otherwise belong to the current namespace.
+type Manifest struct {
+    Packages    []*whisk.Package    `json:"packages,omitempty"`
+    Actions     []*whisk.Action     `json:"actions,omitempty"`
+    Triggers    []*whisk.Trigger    `json:"triggers,omitempty"`
+    Rules       []*whisk.Rule       `json:"rules,omitempty"`
+}
+
+// newRuleManifest returns the manifest of a rule fetched from the server
+func newRuleManifest(rule *whisk.Rule) *RuleManifest {
+    return &RuleManifest{
+        Name:           rule.Name,
+        Trigger:        getRelativeEntityName(whisk.GetRuleEntityName(rule.Trigger), rule.Namespace),
+        Action:         getRelativeEntityName(whisk.GetRuleEntityName(rule.Action), rule.Namespace),
+        Status:         rule.Status,
+        Annotations:    rule.Annotations,
+    }
+}
+
+// getRule returns the rule described by the manifest, named ruleName. Relative trigger and action names are resolved
+// against namespace.
+func (manifest *RuleManifest) getRule(ruleName string, namespace string) *whisk.Rule {
+    return &whisk.Rule{
+        Name:           ruleName,
+        Trigger:        getQualifiedName(manifest.Trigger, namespace),
+        Action:         getQualifiedName(manifest.Action, namespace),
+        Annotations:    manifest.Annotations,
+    }
+}
+
+// getRelativeEntityName strips namespace from a fully qualified entity name. Names in other namespaces are returned
+// unchanged.
+func getRelativeEntityName(name string, namespace string) string {
+    prefix := fmt.Sprintf("/%s/", namespace)
+    if len(namespace) > 0 && strings.HasPrefix(name, prefix) {
+        return strings.TrimPrefix(name, prefix)
+    }
+
+    return name
+}
+
+// writeManifest writes v to filename, or to standard output when filename is empty, in the given format: json or
+// yaml. An existing file is only replaced when force is set.
+func writeManifest(v interface{}, format string, filename string, force bool) error {
+    var output bytes.Buffer
+
+    if format == formatOptionYaml {
+        jsonBytes, err := json.Marshal(v)
+        if err == nil {
+            var yamlBytes []byte
+            yamlBytes, err = yaml.JSONToYAML(jsonBytes)
+            output.Write(yamlBytes)
+        }
+        if err != nil {
+            whisk.Debug(whisk.DbgError, "Unable to convert %#v to YAML: %s\n", v, err)
+            errMsg := wski18n.T("Unable to format output as YAML: {{.err}}", map[string]interface{}{"err": err})
+            whiskErr := whisk.MakeWskError(errors.New(errMsg), whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG,
+                whisk.NO_DISPLAY_USAGE)
+            return whiskErr
+        }
+    } else {
+        printJsonNoColor(v, &output)
+    }
+
+    if len(filename) == 0 {
+        os.Stdout.Write(output.Bytes())
+        return nil
+    }
+
+    file, err := createOutputFile(filename, force)
+    if err != nil {
+        return err
+    }
+    defer file.Close()
+
+    if _, err = file.Write(output.Bytes()); err != nil {
+        whisk.Debug(whisk.DbgError, "file.Write(%s) error: %s\n", filename, err)
+        errMsg := wski18n.T("Unable to write '{{.name}}': {{.err}}", map[string]interface{}{"name": filename, "err": err})
+        whiskErr := whisk.MakeWskErrorFromWskError(errors.New(errMsg), err, whisk.EXITCODE_ERR_GENERAL,
+            whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+        return whiskErr
+    }
+
+    return nil
+}
+
+// readManifest reads a deployment manifest, in YAML or JSON, from filename or from standard input when filename is "-"
+func readManifest(filename string) (*Manifest, error) {
+    var content string
+    var err error
+    manifest := new(Manifest)
+
+    if filename == "-" {
+        content, err = readStdin()
+    } else {
+        content, err = readFile(filename)
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    jsonBytes, err := yaml.YAMLToJSON([]byte(content))
+    if err == nil {
+        err = json.Unmarshal(jsonBytes, manifest)
+    }
+    if err != nil {
+        whisk.Debug(whisk.DbgError, "Unable to read a manifest from '%s': %s\n", filename, err)
+        errMsg := wski18n.T("File '{{.name}}' is not a valid manifest: {{.err}}",
+            map[string]interface{}{"name": filename, "err": err})
+        whiskErr := whisk.MakeWskErrorFromWskError(errors.New(errMsg), err, whisk.EXITCODE_ERR_GENERAL,
+            whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+        return nil, whiskErr
+    }
+
+    return manifest, nil
+}
+
+// readRuleManifest reads a rule manifest, in YAML or JSON, from filename or from standard input when filename is "-"
+func readRuleManifest(filename string) (*RuleManifest, error) {
+    var content string
+    var err error
+    manifest := new(RuleManifest)
+
+    if filename == "-" {
+        content, err = readStdin()
+    } else {
+        content, err = readFile(filename)
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    // YAML is a superset of JSON, so both formats are converted the same way
+    jsonBytes, err := yaml.YAMLToJSON([]byte(content))
+    if err == nil {
+        err = json.Unmarshal(jsonBytes, manifest)
+    }
+    if err != nil || len(manifest.Trigger) == 0 || len(manifest.Action) == 0 {
+        whisk.Debug(whisk.DbgError, "Unable to read a rule manifest from '%s': %v\n", filename, err)
+        errMsg := wski18n.T("File '{{.name}}' does not contain a rule manifest with a trigger and an action",
+            map[string]interface{}{"name": filename})
+        whiskErr := whisk.MakeWskError(errors.New(errMsg), whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG,
+            whisk.NO_DISPLAY_USAGE)
+        return nil, whiskErr
+    }
+
+    return manifest, nil
+}
@@ -19,6 +19,9 @@ package commands
 import (
     "fmt"
     "errors"
+    "strconv"
+    "strings"
+    "sync"
 
     "github.com/spf13/cobra"
     "github.com/fatih/color"
@@ -46,6 +49,13 @@ var namespaceListCmd = &cobra.Command{
             return whiskErr
         }
 
+        if flags.namespace.count && flags.namespace.concurrency < 1 {
+            errStr := wski18n.T("The concurrency must be at least 1, not {{.concurrency}}",
+                map[string]interface{}{"concurrency": flags.namespace.concurrency})
+            return whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_USAGE, whisk.DISPLAY_MSG,
+                whisk.DISPLAY_USAGE)
+        }
+
         namespaces, _, err := client.Namespaces.List()
         if err != nil {
             whisk.Debug(whisk.DbgError, "client.Namespaces.List() error: %s\n", err)
@@ -54,8 +64,12 @@ var namespaceListCmd = &cobra.Command{
             werr := whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_NETWORK, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
             return werr
         }
-        printList(namespaces)
-        return nil
+
+        if flags.namespace.count {
+            return printNamespaceCounts(namespaces)
+        }
+
+        return printList(namespaces)
     },
 }
 
@@ -85,7 +99,17 @@ var namespaceGetCmd = &cobra.Command{
             }
         }
 
-        namespace, _, err := client.Namespaces.Get(qualifiedName.namespace)
+        collections, err := parseEntityCollections(flags.namespace.entities)
+        if err != nil {
+            return err
+        }
+
+        var namespace *whisk.Namespace
+        if len(flags.namespace.entities) > 0 {
+            namespace, _, err = client.Namespaces.GetEntities(qualifiedName.namespace, collections)
+        } else {
+            namespace, _, err = client.Namespaces.Get(qualifiedName.namespace)
+        }
 
         if err != nil {
             whisk.Debug(whisk.DbgError, "client.Namespaces.Get(%s) error: %s\n", getClientNamespace(), err)
@@ -96,20 +120,227 @@ var namespaceGetCmd = &cobra.Command{
             return werr
         }
 
+        if len(flags.global.output) > 0 {
+            return printFormatted(namespace, flags.global.output)
+        }
+
         fmt.Fprintf(color.Output, wski18n.T("Entities in namespace: {{.namespace}}\n",
             map[string]interface{}{"namespace": boldString(getClientNamespace())}))
-        printList(namespace.Contents.Packages)
-        printList(namespace.Contents.Actions)
-        printList(namespace.Contents.Triggers)
-        printList(namespace.Contents.Rules)
+        printNamespaceContents(namespace.Contents, collections)
+
+        return nil
+    },
+}
+
+var namespaceStatsCmd = &cobra.Command{
+    Use:   "stats [NAMESPACE]",
+    Short: wski18n.T("count the actions, packages, triggers, and rules in a namespace"),
+    SilenceUsage:   true,
+    SilenceErrors:  true,
+    PreRunE: setupClientConfig,
+    RunE: func(cmd *cobra.Command, args []string) error {
+        var qualifiedName QualifiedName
+        var err error
+
+        if whiskErr := checkArgs(args, 0, 1, "Namespace stats",
+                wski18n.T("An optional namespace is the only valid argument.")); whiskErr != nil {
+            return whiskErr
+        }
+
+        // Namespace argument is optional; defaults to the namespace of the authorization key
+        namespace := "_"
+        if len(args) == 1 {
+            if qualifiedName, err = parseQualifiedName(args[0]); err != nil {
+                return parseQualifiedNameError(args[0], err)
+            }
+
+            if len(qualifiedName.entityName) > 0 {
+                return entityNameError(qualifiedName.entityName)
+            }
+
+            namespace = qualifiedName.namespace
+        }
+
+        stats, _, err := client.Namespaces.Stats(namespace)
+        if err != nil {
+            whisk.Debug(whisk.DbgError, "client.Namespaces.Stats(%s) error: %s\n", namespace, err)
+            errStr := wski18n.T("Unable to obtain the entity counts for namespace '{{.namespace}}': {{.err}}",
+                    map[string]interface{}{"namespace": namespace, "err": err})
+            werr := whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_NETWORK,
+                whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+            return werr
+        }
+
+        if len(flags.global.output) > 0 {
+            return printFormatted(stats, flags.global.output)
+        }
+
+        fmt.Fprintf(color.Output, wski18n.T("Entity counts for namespace: {{.namespace}}\n",
+            map[string]interface{}{"namespace": boldString(namespace)}))
+        fmt.Printf("%-10s %d\n", "actions", stats.Actions)
+        fmt.Printf("%-10s %d\n", "packages", stats.Packages)
+        fmt.Printf("%-10s %d\n", "triggers", stats.Triggers)
+        fmt.Printf("%-10s %d\n", "rules", stats.Rules)
 
         return nil
     },
 }
 
+// namespaceCounts is the number of entities of each type in a namespace, as listed by namespace list --count
+type namespaceCounts struct {
+    Namespace   string  `json:"namespace"`
+    Actions     int     `json:"actions"`
+    Triggers    int     `json:"triggers"`
+    Rules       int     `json:"rules"`
+    Packages    int     `json:"packages"`
+}
+
+// printNamespaceCounts prints a table with the number of actions, triggers, rules and packages in each namespace.
+// The namespaces are counted one after the other because the client namespace is shared by all requests.
+func printNamespaceCounts(namespaces []whisk.Namespace) error {
+    var allCounts []namespaceCounts
+
+    for _, namespace := range namespaces {
+        client.Namespace = namespace.Name
+        counts, err := countNamespaceEntities(namespace.Name)
+        if err != nil {
+            return err
+        }
+
+        allCounts = append(allCounts, counts)
+    }
+
+    if len(flags.global.output) > 0 {
+        return printFormatted(allCounts, flags.global.output)
+    }
+
+    rows := [][]string{{"namespace", "actions", "triggers", "rules", "packages"}}
+    for _, counts := range allCounts {
+        rows = append(rows, []string{counts.Namespace, strconv.Itoa(counts.Actions), strconv.Itoa(counts.Triggers),
+            strconv.Itoa(counts.Rules), strconv.Itoa(counts.Packages)})
+    }
+    printRows(rows, nil)
+
+    return nil
+}
+
+// countNamespaceEntities counts the entities of each type in the client namespace, issuing up to --concurrency
+// requests at a time
+func countNamespaceEntities(namespace string) (namespaceCounts, error) {
+    var wg sync.WaitGroup
+    collections := []string{"actions", "triggers", "rules", "packages"}
+    totals := make([]int, len(collections))
+    errs := make([]error, len(collections))
+    indexes := make(chan int)
+
+    for i := 0; i < flags.namespace.concurrency && i < len(collections); i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for index := range indexes {
+                totals[index], _, errs[index] = client.Namespaces.Count(collections[index])
+            }
+        }()
+    }
+
+    for index := range collections {
+        indexes <- index
+    }
+    close(indexes)
+    wg.Wait()
+
+    for index, err := range errs {
+        if err != nil {
+            whisk.Debug(whisk.DbgError, "client.Namespaces.Count(%s) in namespace %s error: %s\n", collections[index],
+                namespace, err)
+            errStr := wski18n.T("Unable to obtain the entity counts for namespace '{{.namespace}}': {{.err}}",
+                    map[string]interface{}{"namespace": namespace, "err": err})
+            werr := whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_NETWORK,
+                whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+            return namespaceCounts{}, werr
+        }
+    }
+
+    return namespaceCounts{Namespace: namespace, Actions: totals[0], Triggers: totals[1], Rules: totals[2],
+        Packages: totals[3]}, nil
+}
+
+// parseEntityCollections splits the comma separated --entities value into entity collection names. Every
+// collection is returned when entities is empty.
+func parseEntityCollections(entities string) ([]string, error) {
+    var collections []string
+
+    if len(entities) == 0 {
+        return whisk.NamespaceCollections, nil
+    }
+
+    for _, collection := range strings.Split(entities, ",") {
+        collection = strings.ToLower(strings.TrimSpace(collection))
+        if !whisk.IsNamespaceCollection(collection) {
+            return nil, entityCollectionError(collection)
+        }
+        collections = append(collections, collection)
+    }
+
+    return collections, nil
+}
+
+// printNamespaceContents prints a section headed by the entity count for each requested collection, in the order
+// the collections are listed by the namespace
+func printNamespaceContents(contents whisk.Contents, collections []string) {
+    requested := make(map[string]bool)
+    for _, collection := range collections {
+        requested[collection] = true
+    }
+
+    if requested["packages"] {
+        printSectionHeader("packages", len(contents.Packages))
+        printPackageRows(contents.Packages)
+    }
+
+    if requested["actions"] {
+        printSectionHeader("actions", len(contents.Actions))
+        printActionRows(contents.Actions)
+    }
+
+    if requested["triggers"] {
+        printSectionHeader("triggers", len(contents.Triggers))
+        printTriggerRows(contents.Triggers)
+    }
+
+    if requested["rules"] {
+        printSectionHeader("rules", len(contents.Rules))
+        printRuleRows(contents.Rules)
+    }
+}
+
+func printSectionHeader(collection string, count int) {
+    fmt.Fprintf(color.Output, "%s\n", boldString(fmt.Sprintf("%s (%d)", collection, count)))
+}
+
+func entityCollectionError(collection string) (error) {
+    errMsg := wski18n.T(
+        "Unknown entity type '{{.entity}}'; valid types are {{.valid}}",
+        map[string]interface{}{
+            "entity": collection,
+            "valid": strings.Join(whisk.NamespaceCollections, ", "),
+        })
+
+    return whisk.MakeWskError(errors.New(errMsg), whisk.EXITCODE_ERR_USAGE, whisk.DISPLAY_MSG, whisk.DISPLAY_USAGE)
+}
+
 func init() {
+    namespaceListCmd.Flags().BoolVar(&flags.namespace.count, "count", false,
+        wski18n.T("show the number of actions, triggers, rules and packages in each namespace"))
+    namespaceListCmd.Flags().IntVar(&flags.namespace.concurrency, "concurrency", 4,
+        wski18n.T("the maximum `NUMBER` of concurrent requests per namespace when counting entities"))
+
+    namespaceGetCmd.Flags().StringVar(&flags.namespace.entities, "entities", "",
+        wski18n.T("only get the comma separated entity `TYPES` (packages, actions, triggers, rules)"))
+
     namespaceCmd.AddCommand(
         namespaceListCmd,
         namespaceGetCmd,
+        namespaceStatsCmd,
     )
 }
@@ -0,0 +1,251 @@
+/*
+ * Copyright 2015-2016 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+    "net/http"
+    "strconv"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+
+    "../../go-whisk/whisk"
+)
+
+// namespaceHandler answers the list of each entity collection of the namespace ns, and the get of the whole namespace
+func namespaceHandler(w http.ResponseWriter, r *http.Request) {
+    switch strings.TrimPrefix(r.URL.Path, "/api/v1/namespaces/") {
+    case "ns/actions":
+        writeJSON(w, http.StatusOK, getTestActions(2))
+    case "ns/packages":
+        writeJSON(w, http.StatusOK, []whisk.Package{{Namespace: "ns", Name: "pkg"}})
+    case "ns/triggers":
+        writeJSON(w, http.StatusOK, []whisk.Trigger{})
+    case "ns/rules":
+        writeJSON(w, http.StatusOK, []whisk.Rule{{Namespace: "ns", Name: "r"}})
+    case "ns/":
+        writeJSON(w, http.StatusOK, whisk.Contents{Actions: getTestActions(2)})
+    default:
+        writeJSON(w, http.StatusNotFound, map[string]interface{}{"error": "The requested resource does not exist.", "code": 1})
+    }
+}
+
+func TestNamespaceGetEntities(t *testing.T) {
+    tests := []struct {
+        entities    string
+        requests    []string
+        sections    []string
+    }{
+        {"actions", []string{"GET ns/actions"}, []string{"actions (2)"}},
+        {"rules, Actions", []string{"GET ns/actions", "GET ns/rules"}, []string{"actions (2)", "rules (1)"}},
+        {"triggers,packages", []string{"GET ns/packages", "GET ns/triggers"}, []string{"packages (1)", "triggers (0)"}},
+        // Without --entities, the namespace is fetched with a single request
+        {"", []string{"GET ns/"}, []string{"packages (0)", "actions (2)", "triggers (0)", "rules (0)"}},
+    }
+
+    for _, test := range tests {
+        server := newTestServer(t, namespaceHandler)
+        flags.namespace.entities = test.entities
+
+        var err error
+        output := captureOutput(t, func() { err = namespaceGetCmd.RunE(namespaceGetCmd, []string{"/ns"}) })
+        checkRequests(t, server, test.requests...)
+        server.Close()
+
+        if err != nil {
+            t.Errorf("namespace get --entities %q failed: %s", test.entities, err)
+            continue
+        }
+
+        var sections []string
+        for _, line := range strings.Split(output, "\n") {
+            if strings.HasSuffix(line, ")") {
+                sections = append(sections, line)
+            }
+        }
+        if strings.Join(sections, ", ") != strings.Join(test.sections, ", ") {
+            t.Errorf("namespace get --entities %q printed the sections %q, want %q", test.entities, sections,
+                test.sections)
+        }
+    }
+}
+
+func TestNamespaceGetUnknownEntities(t *testing.T) {
+    for _, entities := range []string{"actions,functions", "action", "actions,"} {
+        server := newTestServer(t, namespaceHandler)
+        flags.namespace.entities = entities
+
+        err := namespaceGetCmd.RunE(namespaceGetCmd, []string{"/ns"})
+        checkRequests(t, server)
+        server.Close()
+
+        if whiskErr, ok := err.(*whisk.WskError); !ok || whiskErr.ExitCode != whisk.EXITCODE_ERR_USAGE ||
+                !strings.HasPrefix(whiskErr.Error(), "Unknown entity type") {
+            t.Errorf("namespace get --entities %q error = %#v, want an unknown entity type usage error", entities, err)
+        }
+    }
+}
+
+// countHandler answers the namespace list with the namespaces in counts, and each entity collection list with a page
+// of the given number of entities, with the collection size in the X-Total-Count header unless noTotalCount is set. It
+// records the most collection lists it was answering at the same time.
+type countHandler struct {
+    counts          map[string]map[string]int
+    namespaces      []string
+    noTotalCount    bool
+    mutex           sync.Mutex
+    active          int
+    maxActive       int
+}
+
+func (h *countHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/namespaces"), "/")
+    if len(path) == 0 {
+        writeJSON(w, http.StatusOK, h.namespaces)
+        return
+    }
+
+    parts := strings.Split(path, "/")
+    total, found := h.counts[parts[0]][parts[len(parts) - 1]]
+    if !found {
+        writeJSON(w, http.StatusInternalServerError, map[string]interface{}{"error": "request failed", "code": 1})
+        return
+    }
+
+    h.mutex.Lock()
+    h.active++
+    if h.active > h.maxActive {
+        h.maxActive = h.active
+    }
+    h.mutex.Unlock()
+
+    time.Sleep(10 * time.Millisecond)
+
+    h.mutex.Lock()
+    h.active--
+    h.mutex.Unlock()
+
+    limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+    skip, _ := strconv.Atoi(r.URL.Query().Get("skip"))
+    entities := []map[string]interface{}{}
+    for i := skip; i < total && i < skip + limit; i++ {
+        entities = append(entities, map[string]interface{}{"namespace": parts[0], "name": "e" + strconv.Itoa(i)})
+    }
+
+    if !h.noTotalCount {
+        w.Header().Set(whisk.TotalCountHeader, strconv.Itoa(total))
+    }
+    writeJSON(w, http.StatusOK, entities)
+}
+
+func TestNamespaceListCount(t *testing.T) {
+    handler := &countHandler{
+        namespaces: []string{"ns", "other"},
+        counts: map[string]map[string]int{
+            "ns": {"actions": 2, "triggers": 0, "rules": 1, "packages": 3},
+            // A collection larger than a page is counted across its pages
+            "other": {"actions": whisk.MaxListPageSize + 5, "triggers": 4, "rules": 0, "packages": 0},
+        },
+    }
+
+    tests := []struct {
+        concurrency  int
+        noTotalCount bool
+        requests     int
+    }{
+        // One namespace list, and a request for each of the four collections in ns and in other
+        {4, false, 9},
+        {2, false, 9},
+        {1, false, 9},
+        // Without X-Total-Count the collections are counted page by page, and the actions of other span two pages
+        {4, true, 10},
+        {1, true, 10},
+    }
+
+    for _, test := range tests {
+        handler.noTotalCount = test.noTotalCount
+        server := newTestServer(t, handler.ServeHTTP)
+        flags.namespace.count = true
+        flags.namespace.concurrency = test.concurrency
+        handler.maxActive = 0
+
+        var err error
+        output := captureOutput(t, func() { err = namespaceListCmd.RunE(namespaceListCmd, []string{}) })
+        requests := len(server.getRequests())
+        server.Close()
+
+        if err != nil {
+            t.Errorf("namespace list --count --concurrency %d failed: %s", test.concurrency, err)
+            continue
+        }
+
+        want := "namespace actions triggers rules packages\n" +
+            "ns        2       0        1     3\n" +
+            "other     205     4        0     0\n"
+        if output != want {
+            t.Errorf("namespace list --count --concurrency %d printed:\n%s\nwant:\n%s", test.concurrency, output, want)
+        }
+        if requests != test.requests {
+            t.Errorf("namespace list --count --concurrency %d without X-Total-Count %t sent %d requests, want %d",
+                test.concurrency, test.noTotalCount, requests, test.requests)
+        }
+        if handler.maxActive > test.concurrency {
+            t.Errorf("namespace list --count --concurrency %d sent %d requests at the same time", test.concurrency,
+                handler.maxActive)
+        }
+    }
+
+    if flag := namespaceListCmd.Flags().Lookup("concurrency"); flag == nil || flag.DefValue != "4" {
+        t.Errorf("namespace list --concurrency = %#v, want a flag with the default 4", flag)
+    }
+}
+
+func TestNamespaceListCountErrors(t *testing.T) {
+    handler := &countHandler{
+        namespaces: []string{"ns"},
+        counts: map[string]map[string]int{"ns": {"actions": 2, "triggers": 0, "packages": 3}},
+    }
+    tests := []struct {
+        concurrency int
+        want        string
+        exitCode    int
+    }{
+        {0, "The concurrency must be at least 1, not 0", whisk.EXITCODE_ERR_USAGE},
+        {4, "Unable to obtain the entity counts for namespace 'ns': request failed (code 1)",
+            http.StatusInternalServerError - 256},
+    }
+
+    for _, test := range tests {
+        server := newTestServer(t, handler.ServeHTTP)
+        flags.namespace.count = true
+        flags.namespace.concurrency = test.concurrency
+
+        var err error
+        output := captureOutput(t, func() { err = namespaceListCmd.RunE(namespaceListCmd, []string{}) })
+        server.Close()
+
+        if whiskErr, ok := err.(*whisk.WskError); !ok || whiskErr.Error() != test.want ||
+                whiskErr.ExitCode != test.exitCode {
+            t.Errorf("namespace list --count --concurrency %d error = %#v, want %q with exit code %d",
+                test.concurrency, err, test.want, test.exitCode)
+        }
+        if len(output) > 0 {
+            t.Errorf("namespace list --count --concurrency %d printed %q, want no table", test.concurrency, output)
+        }
+    }
+}
@@ -17,9 +17,11 @@
 package commands
 
 import (
+  "encoding/json"
   "errors"
   "fmt"
   "net/http"
+  "path"
 
   "../../go-whisk/whisk"
   "../wski18n"
@@ -28,6 +30,12 @@ import (
   "github.com/spf13/cobra"
 )
 
+// packageExport is the file format written by package export and read by package import
+type packageExport struct {
+  Package *whisk.Package  `json:"package"`
+  Actions []*whisk.Action `json:"actions,omitempty"`
+}
+
 var packageCmd = &cobra.Command{
   Use:   "package",
   Short: wski18n.T("work with packages"),
@@ -58,6 +66,14 @@ var packageBindCmd = &cobra.Command{
       return parseQualifiedNameError(bindingName, err)
     }
 
+    // Verify the package exists, so the binding does not fail when it is used; a refresh gets the package anyway
+    if !flags.xPackage.force && !flags.xPackage.update {
+      client.Namespace = pkgQualifiedName.namespace
+      if _, resp, err := client.Packages.Get(pkgQualifiedName.entityName); err != nil {
+        return bindPackageGetError(pkgQualifiedName, resp, err)
+      }
+    }
+
     client.Namespace = bindQualifiedName.namespace
 
     // Convert the binding's list of default parameters from a string into []KeyValue
@@ -89,6 +105,11 @@ var packageBindCmd = &cobra.Command{
       return werr
     }
 
+    if flags.xPackage.update {
+      return refreshPackageBinding(pkgQualifiedName, bindQualifiedName, parameters.(whisk.KeyValueArr),
+        annotations.(whisk.KeyValueArr))
+    }
+
     binding := whisk.Binding{
       Name:      pkgQualifiedName.entityName,
       Namespace: pkgQualifiedName.namespace,
@@ -101,10 +122,14 @@ var packageBindCmd = &cobra.Command{
       Binding:     binding,
     }
 
-    _, _, err = client.Packages.Insert(p, false)
+    _, resp, err := client.Packages.Insert(p, false)
     if err != nil {
       whisk.Debug(whisk.DbgError, "client.Packages.Insert(%#v, false) failed: %s\n", p, err)
       errStr := wski18n.T("Binding creation failed: {{.err}}", map[string]interface{}{"err":err})
+      if resp != nil && resp.StatusCode == http.StatusConflict {
+        errStr = wski18n.T("Binding creation failed: a package named '{{.name}}' already exists; use update",
+          map[string]interface{}{"name": bindingName})
+      }
       werr := whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
       return werr
     }
@@ -212,6 +237,14 @@ var packageUpdateCmd = &cobra.Command{
       return parseQualifiedNameError(args[0], err)
     }
 
+    if err = checkDeletedKeys(flags.common.param, flags.common.delParam, "--del-param"); err != nil {
+      return err
+    }
+
+    if err = checkDeletedKeys(flags.common.annotation, flags.common.delAnnotation, "--del-annotation"); err != nil {
+      return err
+    }
+
     client.Namespace = qualifiedName.namespace
 
     if shared, sharedSet, err = parseShared(flags.common.shared); err != nil {
@@ -251,6 +284,12 @@ var packageUpdateCmd = &cobra.Command{
       p.Publish = &shared
     }
 
+    if len(flags.common.delParam) > 0 || len(flags.common.delAnnotation) > 0 {
+      if err = deletePackageKeyValues(p); err != nil {
+        return err
+      }
+    }
+
     p, _, err = client.Packages.Insert(p, true)
     if err != nil {
       whisk.Debug(whisk.DbgError, "client.Packages.Insert(%#v, true) failed: %s\n", p, err)
@@ -265,6 +304,50 @@ var packageUpdateCmd = &cobra.Command{
   },
 }
 
+// deletePackageKeyValues merges the parameters and annotations of p over those of the existing package, without the
+// keys given to --del-param and --del-annotation. The server replaces all parameters or annotations of a package with
+// any that are sent, so the existing ones are kept by sending them along. A package that does not exist has nothing to
+// delete.
+func deletePackageKeyValues(p *whisk.Package) (error) {
+  existingPackage, resp, err := client.Packages.Get(p.Name)
+  if resp != nil && resp.StatusCode == http.StatusNotFound {
+    whisk.Debug(whisk.DbgInfo, "Package '%s' does not exist; there is nothing to delete\n", p.Name)
+    return nil
+  } else if err != nil {
+    whisk.Debug(whisk.DbgError, "client.Packages.Get(%s) failed: %s\n", p.Name, err)
+    errStr := wski18n.T("Unable to get package '{{.name}}': {{.err}}", map[string]interface{}{"name": p.Name, "err": err})
+    werr := whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+    return werr
+  }
+
+  if len(flags.common.delParam) > 0 {
+    existingParameters := existingPackage.Parameters
+
+    // The parameters of a binding are read with those of the package it is bound to merged in, and only its own are kept
+    if existingPackage.IsBinding() {
+      client.Namespace = existingPackage.Binding.Namespace
+      source, resp, err := client.Packages.Get(existingPackage.Binding.Name)
+      client.Namespace = p.Namespace
+      if err != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+        return bindPackageGetError(QualifiedName{namespace: existingPackage.Binding.Namespace,
+          entityName: existingPackage.Binding.Name}, resp, err)
+      } else if err != nil {
+        source = nil
+      }
+      existingParameters = whisk.GetBindingParameters(existingPackage, source)
+    }
+
+    p.Parameters = mergeAndDeleteKeyValues(existingParameters, p.Parameters, flags.common.delParam, "parameter", p.Name)
+  }
+
+  if len(flags.common.delAnnotation) > 0 {
+    p.Annotations = mergeAndDeleteKeyValues(existingPackage.Annotations, p.Annotations, flags.common.delAnnotation,
+      "annotation", p.Name)
+  }
+
+  return nil
+}
+
 var packageGetCmd = &cobra.Command{
   Use:           "get PACKAGE_NAME [FIELD_FILTER]",
   Short:         wski18n.T("get package"),
@@ -309,6 +392,10 @@ var packageGetCmd = &cobra.Command{
       return werr
     }
 
+    if len(flags.global.output) > 0 {
+      return printFormatted(xPackage, flags.global.output)
+    }
+
     if flags.common.summary {
       printSummary(xPackage)
     } else {
@@ -329,6 +416,197 @@ var packageGetCmd = &cobra.Command{
   },
 }
 
+var packageContentsCmd = &cobra.Command{
+  Use:           "contents PACKAGE_NAME",
+  Short:         wski18n.T("list the actions in a package with their kind and version"),
+  SilenceUsage:  true,
+  SilenceErrors: true,
+  PreRunE:       setupClientConfig,
+  RunE: func(cmd *cobra.Command, args []string) error {
+    var err error
+    var qualifiedName QualifiedName
+
+    if whiskErr := checkArgs(args, 1, 1, "Package contents", wski18n.T("A package name is required.")); whiskErr != nil {
+      return whiskErr
+    }
+
+    if qualifiedName, err = parseQualifiedName(args[0]); err != nil {
+      return parseQualifiedNameError(args[0], err)
+    }
+    client.Namespace = qualifiedName.namespace
+
+    xPackage, _, err := client.Packages.Get(qualifiedName.entityName)
+    if err != nil {
+      whisk.Debug(whisk.DbgError, "client.Packages.Get(%s) failed: %s\n", qualifiedName.entityName, err)
+      errStr := wski18n.T("Unable to get package '{{.name}}': {{.err}}",
+        map[string]interface{}{"name": qualifiedName.entityName, "err": err})
+      werr := whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG,
+        whisk.NO_DISPLAY_USAGE)
+      return werr
+    }
+
+    if len(flags.global.output) > 0 {
+      return printList(xPackage.Actions)
+    }
+
+    if len(xPackage.Actions) == 0 {
+      fmt.Printf(wski18n.T("package {{.name}} has no actions\n", map[string]interface{}{"name": qualifiedName.entityName}))
+      return nil
+    }
+
+    printPackageContents(qualifiedName.entityName, xPackage.Actions)
+
+    return nil
+  },
+}
+
+// printPackageContents prints a table of the name, kind and version of the actions in a package
+func printPackageContents(packageName string, actions []whisk.Action) {
+  var rows [][]string
+
+  for _, action := range actions {
+    rows = append(rows, []string{action.Name, action.GetKind(), action.Version})
+  }
+
+  fmt.Fprintf(color.Output, "%s\n", boldString(wski18n.T("actions in package {{.name}}",
+    map[string]interface{}{"name": packageName})))
+  printRows(rows, nil)
+}
+
+var packageExportCmd = &cobra.Command{
+  Use:           "export PACKAGE_NAME",
+  Short:         wski18n.T("export a package and the actions it contains as JSON"),
+  SilenceUsage:  true,
+  SilenceErrors: true,
+  PreRunE:       setupClientConfig,
+  RunE: func(cmd *cobra.Command, args []string) error {
+    var err error
+    var qualifiedName QualifiedName
+
+    if whiskErr := checkArgs(args, 1, 1, "Package export", wski18n.T("A package name is required.")); whiskErr != nil {
+      return whiskErr
+    }
+
+    if qualifiedName, err = parseQualifiedName(args[0]); err != nil {
+      return parseQualifiedNameError(args[0], err)
+    }
+    client.Namespace = qualifiedName.namespace
+
+    xPackage, _, err := client.Packages.Get(qualifiedName.entityName)
+    if err != nil {
+      whisk.Debug(whisk.DbgError, "client.Packages.Get(%s) failed: %s\n", qualifiedName.entityName, err)
+      errStr := wski18n.T(
+        "Unable to get package '{{.name}}': {{.err}}",
+        map[string]interface{}{
+          "name": qualifiedName.entityName,
+          "err":err,
+        })
+      werr := whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+      return werr
+    }
+
+    // The package only lists the names of its actions; fetch their full definitions
+    export := packageExport{Package: xPackage}
+    for _, listedAction := range xPackage.Actions {
+      actionName := fmt.Sprintf("%s/%s", qualifiedName.entityName, listedAction.Name)
+      action, _, err := client.Actions.Get(actionName)
+      if err != nil {
+        return actionGetError(actionName, err)
+      }
+
+      export.Actions = append(export.Actions, action)
+    }
+
+    if len(flags.xPackage.exportFile) == 0 {
+      printJSON(export)
+      return nil
+    }
+
+    if err = writeJSONFile(export, flags.xPackage.exportFile, flags.xPackage.force); err != nil {
+      return err
+    }
+
+    fmt.Fprintf(color.Output,
+      wski18n.T("{{.ok}} exported package {{.name}} to {{.file}}\n",
+        map[string]interface{}{"ok": color.GreenString("ok:"), "name": boldString(qualifiedName.entityName),
+          "file": flags.xPackage.exportFile}))
+    return nil
+  },
+}
+
+var packageImportCmd = &cobra.Command{
+  Use:           "import FILE [PACKAGE_NAME]",
+  Short:         wski18n.T("create a package and its actions from a JSON file written by package export"),
+  SilenceUsage:  true,
+  SilenceErrors: true,
+  PreRunE:       setupClientConfig,
+  RunE: func(cmd *cobra.Command, args []string) error {
+    var err error
+    var content string
+    var export packageExport
+    var qualifiedName QualifiedName
+
+    if whiskErr := checkArgs(args, 1, 2, "Package import",
+      wski18n.T("A package definition file is required. A package name is optional.")); whiskErr != nil {
+      return whiskErr
+    }
+
+    if content, err = readJSONObjectFile(args[0]); err != nil {
+      return err
+    }
+
+    if err = json.Unmarshal([]byte(content), &export); err != nil || export.Package == nil {
+      whisk.Debug(whisk.DbgError, "json.Unmarshal() of '%s' into a package export failed: %v\n", args[0], err)
+      errStr := wski18n.T("File '{{.name}}' does not contain a package exported with package export",
+        map[string]interface{}{"name": args[0]})
+      werr := whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+      return werr
+    }
+
+    // The package is imported under its exported name in the current namespace unless a name is given
+    packageName := export.Package.Name
+    if len(args) == 2 {
+      packageName = args[1]
+    }
+
+    if qualifiedName, err = parseQualifiedName(packageName); err != nil {
+      return parseQualifiedNameError(packageName, err)
+    }
+    client.Namespace = qualifiedName.namespace
+
+    xPackage := export.Package
+    xPackage.Namespace = ""
+    xPackage.Name = qualifiedName.entityName
+    xPackage.Version = ""
+    xPackage.Actions = nil
+    xPackage.Feeds = nil
+
+    if _, _, err = client.Packages.Insert(xPackage, flags.xPackage.overwrite); err != nil {
+      whisk.Debug(whisk.DbgError, "client.Packages.Insert(%#v, %t) failed: %s\n", xPackage, flags.xPackage.overwrite, err)
+      errStr := wski18n.T("Unable to create package '{{.name}}': {{.err}}",
+        map[string]interface{}{"name": xPackage.Name, "err": err})
+      werr := whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+      return werr
+    }
+
+    for _, action := range export.Actions {
+      action.Namespace = ""
+      action.Version = ""
+      action.Name = fmt.Sprintf("%s/%s", xPackage.Name, path.Base(action.Name))
+
+      if _, _, err = client.Actions.Insert(action, flags.xPackage.overwrite); err != nil {
+        return actionInsertError(action, err)
+      }
+    }
+
+    fmt.Fprintf(color.Output,
+      wski18n.T("{{.ok}} imported package {{.name}} with {{.count}} actions from {{.file}}\n",
+        map[string]interface{}{"ok": color.GreenString("ok:"), "name": boldString(xPackage.Name),
+          "count": len(export.Actions), "file": args[0]}))
+    return nil
+  },
+}
+
 var packageDeleteCmd = &cobra.Command{
   Use:           "delete PACKAGE_NAME",
   Short:         wski18n.T("delete package"),
@@ -409,7 +687,18 @@ var packageListCmd = &cobra.Command{
       Public: shared,
     }
 
-    packages, _, err := client.Packages.List(options)
+    var packages []whisk.Package
+    if flags.common.all {
+      err = listAllPages(func(limit int, skip int) (int, *http.Response, error) {
+        options.Limit, options.Skip = limit, skip
+        page, resp, err := client.Packages.List(options)
+        packages = append(packages, page...)
+        return len(page), resp, err
+      })
+    } else {
+      packages, _, err = client.Packages.List(options)
+    }
+
     if err != nil {
       whisk.Debug(whisk.DbgError, "client.Packages.List(%+v) failed: %s\n", options, err)
       errStr := wski18n.T("Unable to obtain the list of packages for namespace '{{.name}}': {{.err}}",
@@ -418,8 +707,11 @@ var packageListCmd = &cobra.Command{
       return werr
     }
 
-    printList(packages)
-    return nil
+    if flags.xPackage.nameSort {
+      sortPackages(packages)
+    }
+
+    return printList(packages)
   },
 }
 
@@ -507,16 +799,109 @@ var packageRefreshCmd = &cobra.Command{
   },
 }
 
+// refreshPackageBinding binds an existing binding to a package, keeping only the parameters set on the binding itself,
+// and prints the changes to the parameters seen through the binding. The given parameters and annotations are set on
+// the binding.
+func refreshPackageBinding(pkgQualifiedName QualifiedName, bindQualifiedName QualifiedName,
+  parameters whisk.KeyValueArr, annotations whisk.KeyValueArr) (error) {
+  var xPackage, binding, previousPackage *whisk.Package
+  var resp *http.Response
+  var err error
+
+  client.Namespace = pkgQualifiedName.namespace
+  if xPackage, resp, err = client.Packages.Get(pkgQualifiedName.entityName); err != nil {
+    return bindPackageGetError(pkgQualifiedName, resp, err)
+  }
+
+  bindingName := fmt.Sprintf("/%s/%s", bindQualifiedName.namespace, bindQualifiedName.entityName)
+  client.Namespace = bindQualifiedName.namespace
+  if binding, _, err = client.Packages.Get(bindQualifiedName.entityName); err != nil {
+    whisk.Debug(whisk.DbgError, "client.Packages.Get(%s) failed: %s\n", bindQualifiedName.entityName, err)
+    errStr := wski18n.T("Unable to get binding '{{.name}}': {{.err}}", map[string]interface{}{"name": bindingName, "err": err})
+    return whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG,
+      whisk.NO_DISPLAY_USAGE)
+  }
+
+  if binding.Binding == nil || len(binding.Binding.Name) == 0 {
+    errStr := wski18n.T("Package '{{.name}}' is not a binding", map[string]interface{}{"name": bindingName})
+    return whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+  }
+
+  // Packages are returned with the namespace they are in, which is needed to bind to a package in the default namespace
+  if len(xPackage.Namespace) == 0 {
+    xPackage.Namespace = pkgQualifiedName.namespace
+  }
+
+  // The parameters of the package the binding was bound to are merged into those of the binding when it is read, so
+  // they tell apart the parameters set on the binding itself
+  previousPackage = xPackage
+  if binding.Binding.Namespace != xPackage.Namespace || binding.Binding.Name != xPackage.Name {
+    client.Namespace = binding.Binding.Namespace
+    if previousPackage, resp, err = client.Packages.Get(binding.Binding.Name); err != nil {
+      if resp == nil || resp.StatusCode != http.StatusNotFound {
+        previousName := QualifiedName{namespace: binding.Binding.Namespace, entityName: binding.Binding.Name}
+        return bindPackageGetError(previousName, resp, err)
+      }
+      whisk.Debug(whisk.DbgInfo, "Package '/%s/%s' no longer exists\n", binding.Binding.Namespace, binding.Binding.Name)
+      previousPackage = nil
+    }
+    client.Namespace = bindQualifiedName.namespace
+  }
+
+  refreshed, updates := whisk.RefreshBinding(binding, previousPackage, xPackage, parameters)
+  for _, keyValue := range annotations {
+    refreshed.Annotations = refreshed.Annotations.AddOrReplace(keyValue)
+  }
+
+  if _, _, err = client.Packages.Insert(refreshed, true); err != nil {
+    whisk.Debug(whisk.DbgError, "client.Packages.Insert(%#v, true) failed: %s\n", refreshed, err)
+    errStr := wski18n.T("Unable to update binding '{{.name}}': {{.err}}", map[string]interface{}{"name": bindingName, "err": err})
+    return whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG,
+      whisk.NO_DISPLAY_USAGE)
+  }
+
+  fmt.Fprintf(color.Output, wski18n.T("{{.ok}} updated binding {{.name}}\n",
+    map[string]interface{}{"ok": color.GreenString(wski18n.T("ok:")), "name": boldString(bindQualifiedName.entityName)}))
+
+  fmt.Println(wski18n.T("added parameters:"))
+  printArrayContents(updates.Added)
+  fmt.Println(wski18n.T("updated parameters:"))
+  printArrayContents(updates.Updated)
+  fmt.Println(wski18n.T("deleted parameters:"))
+  printArrayContents(updates.Deleted)
+
+  return nil
+}
+
+func bindPackageGetError(qualifiedName QualifiedName, resp *http.Response, err error) (error) {
+  whisk.Debug(whisk.DbgError, "client.Packages.Get(%s) failed: %s\n", qualifiedName.entityName, err)
+
+  name := fmt.Sprintf("/%s/%s", qualifiedName.namespace, qualifiedName.entityName)
+  errStr := wski18n.T("Unable to get package '{{.name}}': {{.err}}", map[string]interface{}{"name": name, "err": err})
+  if resp != nil && resp.StatusCode == http.StatusNotFound && flags.xPackage.update {
+    errStr = wski18n.T("Binding update failed: package {{.name}} does not exist", map[string]interface{}{"name": name})
+  } else if resp != nil && resp.StatusCode == http.StatusNotFound {
+    errStr = wski18n.T("Binding creation failed: package {{.name}} does not exist", map[string]interface{}{"name": name})
+  }
+
+  return whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG,
+    whisk.NO_DISPLAY_USAGE)
+}
+
 func init() {
   packageCreateCmd.Flags().StringSliceVarP(&flags.common.annotation, "annotation", "a", []string{}, wski18n.T("annotation values in `KEY VALUE` format"))
   packageCreateCmd.Flags().StringVarP(&flags.common.annotFile, "annotation-file", "A", "", wski18n.T("`FILE` containing annotation values in JSON format"))
   packageCreateCmd.Flags().StringSliceVarP(&flags.common.param, "param", "p", []string{}, wski18n.T("parameter values in `KEY VALUE` format"))
+  packageCreateCmd.Flags().StringSliceVar(&flags.common.param, "param-string", []string{}, wski18n.T("parameter values in `KEY VALUE` format, sent as strings even when they are valid JSON"))
   packageCreateCmd.Flags().StringVarP(&flags.common.paramFile, "param-file", "P", "", wski18n.T("`FILE` containing parameter values in JSON format"))
   packageCreateCmd.Flags().StringVar(&flags.common.shared, "shared", "", wski18n.T("package visibility `SCOPE`; yes = shared, no = private"))
 
   packageUpdateCmd.Flags().StringSliceVarP(&flags.common.annotation, "annotation", "a", []string{}, wski18n.T("annotation values in `KEY VALUE` format"))
   packageUpdateCmd.Flags().StringVarP(&flags.common.annotFile, "annotation-file", "A", "", wski18n.T("`FILE` containing annotation values in JSON format"))
   packageUpdateCmd.Flags().StringSliceVarP(&flags.common.param, "param", "p", []string{}, wski18n.T("parameter values in `KEY VALUE` format"))
+  packageUpdateCmd.Flags().StringSliceVar(&flags.common.param, "param-string", []string{}, wski18n.T("parameter values in `KEY VALUE` format, sent as strings even when they are valid JSON"))
+  packageUpdateCmd.Flags().StringSliceVar(&flags.common.delParam, "del-param", []string{}, wski18n.T("remove the parameter `KEY` from the existing parameters of the package"))
+  packageUpdateCmd.Flags().StringSliceVar(&flags.common.delAnnotation, "del-annotation", []string{}, wski18n.T("remove the annotation `KEY` from the existing annotations of the package"))
   packageUpdateCmd.Flags().StringVarP(&flags.common.paramFile, "param-file", "P", "", wski18n.T("`FILE` containing parameter values in JSON format"))
   packageUpdateCmd.Flags().StringVar(&flags.common.shared, "shared", "", wski18n.T("package visibility `SCOPE`; yes = shared, no = private"))
 
@@ -525,17 +910,31 @@ func init() {
   packageBindCmd.Flags().StringSliceVarP(&flags.common.annotation, "annotation", "a", []string{}, wski18n.T("annotation values in `KEY VALUE` format"))
   packageBindCmd.Flags().StringVarP(&flags.common.annotFile, "annotation-file", "A", "", wski18n.T("`FILE` containing annotation values in JSON format"))
   packageBindCmd.Flags().StringSliceVarP(&flags.common.param, "param", "p", []string{}, wski18n.T("parameter values in `KEY VALUE` format"))
+  packageBindCmd.Flags().StringSliceVar(&flags.common.param, "param-string", []string{}, wski18n.T("parameter values in `KEY VALUE` format, sent as strings even when they are valid JSON"))
   packageBindCmd.Flags().StringVarP(&flags.common.paramFile, "param-file", "P", "", wski18n.T("`FILE` containing parameter values in JSON format"))
 
+  packageBindCmd.Flags().BoolVar(&flags.xPackage.force, "force", false, wski18n.T("create the binding without verifying that the package exists"))
+  packageBindCmd.Flags().BoolVar(&flags.xPackage.update, "update", false, wski18n.T("refresh an existing binding from the current parameters of the package, keeping the parameters set on the binding"))
+
+  packageExportCmd.Flags().StringVar(&flags.xPackage.exportFile, "file", "", wski18n.T("write the package definition to `FILE` instead of standard output"))
+  packageExportCmd.Flags().BoolVar(&flags.xPackage.force, "force", false, wski18n.T("replace the output file if it already exists"))
+
+  packageImportCmd.Flags().BoolVar(&flags.xPackage.overwrite, "overwrite", false, wski18n.T("replace the package and its actions if they already exist"))
+
   packageListCmd.Flags().StringVar(&flags.common.shared, "shared", "", wski18n.T("include publicly shared entities in the result"))
   packageListCmd.Flags().IntVarP(&flags.common.skip, "skip", "s", 0, wski18n.T("exclude the first `SKIP` number of packages from the result"))
   packageListCmd.Flags().IntVarP(&flags.common.limit, "limit", "l", 30, wski18n.T("only return `LIMIT` number of packages from the collection"))
+  packageListCmd.Flags().BoolVar(&flags.common.all, "all", false, wski18n.T("fetch every page of packages, ignoring --limit"))
+  packageListCmd.Flags().BoolVarP(&flags.xPackage.nameSort, "name-sort", "n", false, wski18n.T("sorts a list alphabetically by entity name; only applicable within the limit/skip returned entity block"))
 
   packageCmd.AddCommand(
     packageBindCmd,
     packageCreateCmd,
     packageUpdateCmd,
     packageGetCmd,
+    packageContentsCmd,
+    packageExportCmd,
+    packageImportCmd,
     packageDeleteCmd,
     packageListCmd,
     packageRefreshCmd,
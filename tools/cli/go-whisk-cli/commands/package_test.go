@@ -0,0 +1,499 @@
+/*
+ * Copyright 2015-2016 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+    "encoding/json"
+    "io"
+    "net/http"
+    "os"
+    "path/filepath"
+    "reflect"
+    "strings"
+    "testing"
+
+    "../../go-whisk/whisk"
+)
+
+// packageExportHandler serves the package ns/pkg, which contains the actions a and b, and answers puts with their body
+func packageExportHandler(w http.ResponseWriter, r *http.Request) {
+    code := "function main() {}"
+    entities := map[string]interface{}{
+        "ns/packages/pkg": whisk.Package{Namespace: "ns", Name: "pkg", Version: "0.0.4",
+            Parameters: whisk.KeyValueArr{{Key: "host", Value: "example.com"}},
+            Annotations: whisk.KeyValueArr{{Key: "description", Value: "a package"}},
+            Actions: []whisk.Action{{Name: "a", Version: "0.0.1"}, {Name: "b", Version: "0.0.2"}}},
+        "ns/actions/pkg/a": whisk.Action{Namespace: "ns/pkg", Name: "a", Version: "0.0.1",
+            Exec: &whisk.Exec{Kind: "nodejs:6", Code: &code}},
+        "ns/actions/pkg/b": whisk.Action{Namespace: "ns/pkg", Name: "b", Version: "0.0.2",
+            Exec: &whisk.Exec{Kind: "python:3", Code: &code}, Parameters: whisk.KeyValueArr{{Key: "n", Value: 1}}},
+    }
+
+    if r.Method == "PUT" {
+        w.Header().Set("Content-Type", "application/json")
+        io.Copy(w, r.Body)
+        return
+    }
+
+    if entity, found := entities[strings.TrimPrefix(r.URL.Path, "/api/v1/namespaces/")]; found {
+        writeJSON(w, http.StatusOK, entity)
+    } else {
+        writeJSON(w, http.StatusNotFound, map[string]interface{}{"error": "The requested resource does not exist.", "code": 1})
+    }
+}
+
+// getTestJSON decodes a JSON document into generic values, for comparisons that ignore formatting
+func getTestJSON(t *testing.T, data string) interface{} {
+    var v interface{}
+    if err := json.Unmarshal([]byte(data), &v); err != nil {
+        t.Fatalf("json.Unmarshal(%q) failed: %s", data, err)
+    }
+
+    return v
+}
+
+func TestPackageExport(t *testing.T) {
+    server := newTestServer(t, packageExportHandler)
+    defer server.Close()
+
+    var err error
+    output := captureOutput(t, func() { err = packageExportCmd.RunE(packageExportCmd, []string{"/ns/pkg"}) })
+    if err != nil {
+        t.Fatalf("package export failed: %s", err)
+    }
+
+    checkRequests(t, server, "GET ns/packages/pkg", "GET ns/actions/pkg/a", "GET ns/actions/pkg/b")
+
+    want := getTestJSON(t, `{
+        "package": {"namespace": "ns", "name": "pkg", "version": "0.0.4",
+            "parameters": [{"key": "host", "value": "example.com"}],
+            "annotations": [{"key": "description", "value": "a package"}],
+            "actions": [{"name": "a", "version": "0.0.1"}, {"name": "b", "version": "0.0.2"}]},
+        "actions": [
+            {"namespace": "ns/pkg", "name": "a", "version": "0.0.1",
+                "exec": {"kind": "nodejs:6", "code": "function main() {}"}},
+            {"namespace": "ns/pkg", "name": "b", "version": "0.0.2",
+                "exec": {"kind": "python:3", "code": "function main() {}"}, "parameters": [{"key": "n", "value": 1}]}
+        ]}`)
+    if exported := getTestJSON(t, output); !reflect.DeepEqual(exported, want) {
+        t.Errorf("package export printed:\n%s", output)
+    }
+}
+
+func TestPackageExportImportRoundTrip(t *testing.T) {
+    server := newTestServer(t, packageExportHandler)
+    defer server.Close()
+
+    inTempDir(t, func(dir string) {
+        flags.xPackage.exportFile = "pkg.json"
+        var err error
+        captureOutput(t, func() { err = packageExportCmd.RunE(packageExportCmd, []string{"/ns/pkg"}) })
+        if err != nil {
+            t.Fatalf("package export failed: %s", err)
+        }
+
+        flags.xPackage.overwrite = true
+        output := captureOutput(t, func() { err = packageImportCmd.RunE(packageImportCmd, []string{"pkg.json", "/other/copy"}) })
+        if err != nil {
+            t.Fatalf("package import failed: %s", err)
+        }
+        if !strings.Contains(output, "imported package copy with 2 actions from pkg.json") {
+            t.Errorf("package import printed %q", output)
+        }
+    })
+
+    checkRequests(t, server, "GET ns/packages/pkg", "GET ns/actions/pkg/a", "GET ns/actions/pkg/b",
+        "PUT other/packages/copy", "PUT other/actions/copy/a", "PUT other/actions/copy/b")
+
+    // The package is created without its action list, and the actions without the namespace and version of the
+    // exported ones
+    want := map[string]string{
+        "other/packages/copy": `{"name": "copy", "parameters": [{"key": "host", "value": "example.com"}],
+            "annotations": [{"key": "description", "value": "a package"}]}`,
+        "other/actions/copy/a": `{"name": "copy/a", "exec": {"kind": "nodejs:6", "code": "function main() {}"}}`,
+        "other/actions/copy/b": `{"name": "copy/b", "exec": {"kind": "python:3", "code": "function main() {}"},
+            "parameters": [{"key": "n", "value": 1}]}`,
+    }
+    for path, body := range want {
+        request := server.getRequest("PUT", path)
+        if request.Query.Get("overwrite") != "true" {
+            t.Errorf("PUT %s with package import --overwrite sent the query %s", path, request.Query.Encode())
+        }
+        if !reflect.DeepEqual(getTestJSON(t, request.Body), getTestJSON(t, body)) {
+            t.Errorf("PUT %s body = %s, want %s", path, request.Body, body)
+        }
+    }
+}
+
+func TestPackageImportInvalidFile(t *testing.T) {
+    server := newTestServer(t, packageExportHandler)
+    defer server.Close()
+
+    for _, content := range []string{`{}`, `{"actions": []}`, `{"package": "pkg"}`} {
+        file := writeTestFile(t, "pkg.json", content)
+        err := packageImportCmd.RunE(packageImportCmd, []string{file})
+        os.RemoveAll(filepath.Dir(file))
+
+        if err == nil || !strings.Contains(err.Error(), "does not contain a package exported with package export") {
+            t.Errorf("package import of %s error = %v, want an invalid file error", content, err)
+        }
+    }
+
+    checkRequests(t, server)
+}
+
+// bindHandler serves the package ns/pkg like packageExportHandler, and answers puts with a 409 when conflict is set
+func bindHandler(conflict bool) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        if r.Method == "PUT" && conflict {
+            writeJSON(w, http.StatusConflict, map[string]interface{}{"error": "resource already exists", "code": 1})
+            return
+        }
+        packageExportHandler(w, r)
+    }
+}
+
+func TestPackageBindParamFile(t *testing.T) {
+    file := writeTestFile(t, "params.json", `{"host": "file.example.com", "port": 8080}`)
+    defer os.RemoveAll(filepath.Dir(file))
+
+    server := newTestServer(t, bindHandler(false))
+    defer server.Close()
+
+    args, params, _, err := parseArgs([]string{"/ns/pkg", "/ns/binding", "-P", file, "-p", "host", "cli.example.com"})
+    if err != nil {
+        t.Fatalf("parseArgs() failed: %s", err)
+    }
+    flags.common.param = params
+
+    captureOutput(t, func() { err = packageBindCmd.RunE(packageBindCmd, args) })
+    if err != nil {
+        t.Fatalf("package bind failed: %s", err)
+    }
+
+    // The source package is checked before the binding is created
+    checkRequests(t, server, "GET ns/packages/pkg", "PUT ns/packages/binding")
+
+    want := getTestJSON(t, `{
+        "binding": {"namespace": "ns", "name": "pkg"},
+        "parameters": [{"key": "host", "value": "cli.example.com"}, {"key": "port", "value": 8080}]
+    }`).(map[string]interface{})
+    have := getTestJSON(t, server.getRequest("PUT", "ns/packages/binding").Body).(map[string]interface{})
+
+    // The parameters come from a map, so their order is not significant
+    for _, body := range []map[string]interface{}{want, have} {
+        if parameters, ok := body["parameters"].([]interface{}); ok && len(parameters) == 2 &&
+                parameters[0].(map[string]interface{})["key"] == "port" {
+            parameters[0], parameters[1] = parameters[1], parameters[0]
+        }
+    }
+    for _, key := range []string{"binding", "parameters"} {
+        if !reflect.DeepEqual(have[key], want[key]) {
+            t.Errorf("binding %s = %#v, want %#v", key, have[key], want[key])
+        }
+    }
+}
+
+func TestPackageBindErrors(t *testing.T) {
+    tests := []struct {
+        force       bool
+        conflict    bool
+        pkg         string
+        want        string
+        requests    []string
+    }{
+        {false, false, "/ns/missing", "Binding creation failed: package /ns/missing does not exist",
+            []string{"GET ns/packages/missing"}},
+        // --force creates the binding without checking the source package
+        {true, false, "/ns/missing", "", []string{"PUT ns/packages/binding"}},
+        {false, true, "/ns/pkg", "Binding creation failed: a package named '/ns/binding' already exists; use update",
+            []string{"GET ns/packages/pkg", "PUT ns/packages/binding"}},
+    }
+
+    for _, test := range tests {
+        server := newTestServer(t, bindHandler(test.conflict))
+        flags.xPackage.force = test.force
+
+        var err error
+        captureOutput(t, func() { err = packageBindCmd.RunE(packageBindCmd, []string{test.pkg, "/ns/binding"}) })
+        checkRequests(t, server, test.requests...)
+        server.Close()
+
+        if len(test.want) == 0 && err != nil {
+            t.Errorf("package bind --force=%t %s failed: %s", test.force, test.pkg, err)
+        } else if len(test.want) > 0 && (err == nil || err.Error() != test.want) {
+            t.Errorf("package bind --force=%t %s error = %v, want %q", test.force, test.pkg, err, test.want)
+        }
+    }
+}
+
+// refreshHandler serves the package ns/pkg after its parameters changed, and the binding ns/binding of the package
+// previous, as the server returns it with the parameters of the package merged in. It answers puts with their body.
+func refreshHandler(previous string) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        entities := map[string]interface{}{
+            "ns/packages/pkg": whisk.Package{Namespace: "ns", Name: "pkg", Parameters: whisk.KeyValueArr{
+                {Key: "host", Value: "new.example.com"}, {Key: "port", Value: 80}, {Key: "timeout", Value: 5}}},
+            "ns/packages/old": whisk.Package{Namespace: "ns", Name: "old", Parameters: whisk.KeyValueArr{
+                {Key: "host", Value: "old.example.com"}, {Key: "port", Value: 80}, {Key: "region", Value: "eu"}}},
+            "ns/packages/binding": whisk.Package{Namespace: "ns", Name: "binding",
+                Binding: &whisk.Binding{Namespace: "ns", Name: previous}, Parameters: whisk.KeyValueArr{
+                    {Key: "host", Value: "old.example.com"}, {Key: "port", Value: 8080}, {Key: "region", Value: "eu"},
+                    {Key: "user", Value: "me"}}},
+            "ns/packages/plain": whisk.Package{Namespace: "ns", Name: "plain"},
+        }
+
+        if r.Method == "PUT" {
+            w.Header().Set("Content-Type", "application/json")
+            io.Copy(w, r.Body)
+        } else if entity, found := entities[strings.TrimPrefix(r.URL.Path, "/api/v1/namespaces/")]; found {
+            writeJSON(w, http.StatusOK, entity)
+        } else {
+            writeJSON(w, http.StatusNotFound, map[string]interface{}{"error": "The requested resource does not exist.", "code": 1})
+        }
+    }
+}
+
+func TestPackageBindUpdate(t *testing.T) {
+    tests := []struct {
+        previous    string
+        args        []string
+        requests    []string
+        parameters  string
+        stdout      string
+    }{
+        // Only the current parameters of pkg are known, so those that differ from them are kept as set on the binding
+        {"pkg", nil, []string{"GET ns/packages/pkg", "GET ns/packages/binding", "PUT ns/packages/binding"},
+            `[{"key":"host","value":"old.example.com"},{"key":"port","value":8080},{"key":"region","value":"eu"},{"key":"user","value":"me"}]`,
+            "ok: updated binding binding\nadded parameters:\ntimeout\nupdated parameters:\ndeleted parameters:\n"},
+        // The binding moves from old to pkg, and the parameters of old are dropped
+        {"old", []string{"-p", "user", "you"},
+            []string{"GET ns/packages/pkg", "GET ns/packages/binding", "GET ns/packages/old", "PUT ns/packages/binding"},
+            `[{"key":"port","value":8080},{"key":"user","value":"you"}]`,
+            "ok: updated binding binding\nadded parameters:\ntimeout\nupdated parameters:\nhost\nuser\ndeleted parameters:\nregion\n"},
+        // Without old, its parameters cannot be told apart from those of the binding
+        {"deleted", nil,
+            []string{"GET ns/packages/pkg", "GET ns/packages/binding", "GET ns/packages/deleted", "PUT ns/packages/binding"},
+            `[{"key":"host","value":"old.example.com"},{"key":"port","value":8080},{"key":"region","value":"eu"},{"key":"user","value":"me"}]`,
+            "ok: updated binding binding\nadded parameters:\ntimeout\nupdated parameters:\ndeleted parameters:\n"},
+    }
+
+    for _, test := range tests {
+        server := newTestServer(t, refreshHandler(test.previous))
+        flags.xPackage.update = true
+
+        args, params, _, err := parseArgs(append([]string{"/ns/pkg", "/ns/binding"}, test.args...))
+        if err != nil {
+            t.Fatalf("parseArgs(%q) failed: %s", test.args, err)
+        }
+        flags.common.param = params
+
+        stdout := captureOutput(t, func() { err = packageBindCmd.RunE(packageBindCmd, args) })
+        checkRequests(t, server, test.requests...)
+        request := server.getRequest("PUT", "ns/packages/binding")
+        server.Close()
+
+        if err != nil {
+            t.Errorf("package bind --update of a binding of %s failed: %s", test.previous, err)
+            continue
+        }
+        if stdout != test.stdout {
+            t.Errorf("package bind --update of a binding of %s printed %q, want %q", test.previous, stdout, test.stdout)
+        }
+
+        var sent struct {
+            Parameters  json.RawMessage     `json:"parameters"`
+            Binding     whisk.Binding       `json:"binding"`
+        }
+        json.Unmarshal([]byte(request.Body), &sent)
+        if string(sent.Parameters) != test.parameters || sent.Binding != (whisk.Binding{Namespace: "ns", Name: "pkg"}) {
+            t.Errorf("package bind --update of a binding of %s sent %s, want the binding of /ns/pkg with the parameters %s",
+                test.previous, request.Body, test.parameters)
+        }
+    }
+}
+
+func TestPackageBindUpdateErrors(t *testing.T) {
+    tests := []struct {
+        pkg     string
+        binding string
+        want    string
+    }{
+        {"/ns/missing", "/ns/binding", "Binding update failed: package /ns/missing does not exist"},
+        {"/ns/pkg", "/ns/missing", "Unable to get binding '/ns/missing': "},
+        {"/ns/pkg", "/ns/plain", "Package '/ns/plain' is not a binding"},
+    }
+
+    for _, test := range tests {
+        server := newTestServer(t, refreshHandler("pkg"))
+        flags.xPackage.update = true
+
+        var err error
+        captureOutput(t, func() { err = packageBindCmd.RunE(packageBindCmd, []string{test.pkg, test.binding}) })
+        if request := server.getRequest("PUT", strings.TrimPrefix(test.binding, "/ns/")); request != nil {
+            t.Errorf("package bind --update %s %s updated the binding", test.pkg, test.binding)
+        }
+        server.Close()
+
+        if err == nil || !strings.HasPrefix(err.Error(), test.want) {
+            t.Errorf("package bind --update %s %s error = %v, want %q", test.pkg, test.binding, err, test.want)
+        }
+    }
+}
+
+// contentsHandler serves the package ns/pkg with three actions and the package ns/empty without any
+func contentsHandler(w http.ResponseWriter, r *http.Request) {
+    packages := map[string]whisk.Package{
+        "ns/packages/pkg": {Namespace: "ns", Name: "pkg", Actions: []whisk.Action{
+            {Name: "hello", Version: "0.0.3", Annotations: whisk.KeyValueArr{{Key: "exec", Value: "nodejs:6"}}},
+            {Name: "a-much-longer-name", Version: "0.0.12", Annotations: whisk.KeyValueArr{{Key: "exec", Value: "python:3"}}},
+            {Name: "seq", Version: "0.0.1", Annotations: whisk.KeyValueArr{{Key: "exec", Value: "sequence"}}},
+        }},
+        "ns/packages/empty": {Namespace: "ns", Name: "empty"},
+    }
+
+    if xPackage, found := packages[strings.TrimPrefix(r.URL.Path, "/api/v1/namespaces/")]; found {
+        writeJSON(w, http.StatusOK, xPackage)
+    } else {
+        writeJSON(w, http.StatusNotFound, map[string]interface{}{"error": "The requested resource does not exist.", "code": 1})
+    }
+}
+
+func TestPackageContents(t *testing.T) {
+    tests := []struct {
+        pkg     string
+        output  string
+        want    string
+    }{
+        {"/ns/pkg", "",
+            "actions in package pkg\n" +
+            "hello              nodejs:6 0.0.3\n" +
+            "a-much-longer-name python:3 0.0.12\n" +
+            "seq                sequence 0.0.1\n"},
+        // A package without actions prints a message rather than an empty table
+        {"/ns/empty", "", "package empty has no actions\n"},
+        {"/ns/pkg", "json", ""},
+        {"/ns/empty", "json", "[]\n"},
+    }
+
+    for _, test := range tests {
+        server := newTestServer(t, contentsHandler)
+        flags.global.output = test.output
+
+        var err error
+        stdout := captureOutput(t, func() { err = packageContentsCmd.RunE(packageContentsCmd, []string{test.pkg}) })
+        checkRequests(t, server, "GET " + strings.Replace(strings.TrimPrefix(test.pkg, "/"), "/", "/packages/", 1))
+        server.Close()
+
+        if err != nil {
+            t.Errorf("package contents %s --output %q failed: %s", test.pkg, test.output, err)
+        } else if test.output == "json" && test.pkg == "/ns/pkg" {
+            // The action stubs are printed as they are
+            var actions []whisk.Action
+            if err = json.Unmarshal([]byte(stdout), &actions); err != nil || len(actions) != 3 || actions[1].Name != "a-much-longer-name" ||
+                    actions[1].Version != "0.0.12" || actions[1].GetKind() != "python:3" {
+                t.Errorf("package contents %s --output json printed %q, want the action stubs", test.pkg, stdout)
+            }
+        } else if stdout != test.want {
+            t.Errorf("package contents %s --output %q printed:\n%s\nwant:\n%s", test.pkg, test.output, stdout, test.want)
+        }
+    }
+}
+
+func TestPackageContentsNotFound(t *testing.T) {
+    server := newTestServer(t, contentsHandler)
+    defer server.Close()
+
+    err := packageContentsCmd.RunE(packageContentsCmd, []string{"/ns/missing"})
+    if whiskErr, ok := err.(*whisk.WskError); !ok || whiskErr.ExitCode != whisk.EXITCODE_ERR_NOT_FOUND ||
+            !strings.HasPrefix(whiskErr.Error(), "Unable to get package 'missing': ") {
+        t.Errorf("package contents of a missing package error = %#v, want a not found WskError", err)
+    }
+}
+
+func TestPackageUpdateDeleteKeys(t *testing.T) {
+    entities := map[string]interface{}{
+        "ns/packages/pkg": whisk.Package{Namespace: "ns", Name: "pkg",
+            Parameters: whisk.KeyValueArr{{Key: "a", Value: 1}, {Key: "b", Value: "old"}},
+            Annotations: whisk.KeyValueArr{{Key: "owner", Value: "me"}}},
+        "ns/packages/src": whisk.Package{Namespace: "ns", Name: "src", Parameters: whisk.KeyValueArr{{Key: "a", Value: 1}}},
+        // The parameters of a binding are read with those of its package merged in
+        "ns/packages/binding": whisk.Package{Namespace: "ns", Name: "binding", Binding: &whisk.Binding{Namespace: "ns", Name: "src"},
+            Parameters: whisk.KeyValueArr{{Key: "a", Value: 1}, {Key: "b", Value: "own"}}},
+        "ns/packages/orphan": whisk.Package{Namespace: "ns", Name: "orphan", Binding: &whisk.Binding{Namespace: "ns", Name: "gone"},
+            Parameters: whisk.KeyValueArr{{Key: "a", Value: 1}, {Key: "b", Value: "own"}}},
+    }
+
+    tests := []struct {
+        name            string
+        args            []string
+        delParams       []string
+        delAnnotations  []string
+        requests        []string
+        parameters      string  // the parameters sent, as JSON, or empty when they are not sent
+        annotations     string
+    }{
+        {"pkg", []string{"-p", "c", "3"}, []string{"a"}, nil, []string{"GET ns/packages/pkg", "PUT ns/packages/pkg"},
+            `[{"key":"b","value":"old"},{"key":"c","value":3}]`, ``},
+        {"pkg", nil, []string{"missing"}, []string{"owner"}, []string{"GET ns/packages/pkg", "PUT ns/packages/pkg"},
+            `[{"key":"a","value":1},{"key":"b","value":"old"}]`, `[]`},
+        // Only the parameters of a binding itself are kept, and its package's are left out
+        {"binding", nil, []string{"b"}, nil,
+            []string{"GET ns/packages/binding", "GET ns/packages/src", "PUT ns/packages/binding"}, `[]`, ``},
+        {"binding", []string{"-p", "c", "3"}, []string{"missing"}, nil,
+            []string{"GET ns/packages/binding", "GET ns/packages/src", "PUT ns/packages/binding"},
+            `[{"key":"b","value":"own"},{"key":"c","value":3}]`, ``},
+        // Without its package, all the parameters read are kept as those of the binding
+        {"orphan", nil, []string{"b"}, nil,
+            []string{"GET ns/packages/orphan", "GET ns/packages/gone", "PUT ns/packages/orphan"}, `[{"key":"a","value":1}]`, ``},
+        // A package that does not exist has nothing to delete
+        {"new", []string{"-p", "c", "3"}, []string{"a"}, nil, []string{"GET ns/packages/new", "PUT ns/packages/new"},
+            `[{"key":"c","value":3}]`, ``},
+    }
+
+    for _, test := range tests {
+        server := newTestServer(t, updateTestHandler(entities))
+
+        var err error
+        _, flags.common.param, flags.common.annotation, err = parseArgs(append([]string{"wsk", "package", "update", "/ns/" + test.name}, test.args...))
+        if err != nil {
+            t.Fatalf("parseArgs(%q) failed: %s", test.args, err)
+        }
+        flags.common.delParam, flags.common.delAnnotation = test.delParams, test.delAnnotations
+
+        captureOutput(t, func() { err = packageUpdateCmd.RunE(packageUpdateCmd, []string{"/ns/" + test.name}) })
+        checkRequests(t, server, test.requests...)
+        request := server.getRequest("PUT", "ns/packages/" + test.name)
+        server.Close()
+
+        if err != nil {
+            t.Errorf("package update %s %q --del-param %q --del-annotation %q failed: %s", test.name, test.args,
+                test.delParams, test.delAnnotations, err)
+            continue
+        }
+
+        var sent struct {
+            Parameters  json.RawMessage     `json:"parameters"`
+            Annotations json.RawMessage     `json:"annotations"`
+        }
+        json.Unmarshal([]byte(request.Body), &sent)
+        if string(sent.Parameters) != test.parameters || string(sent.Annotations) != test.annotations {
+            t.Errorf("package update %s %q --del-param %q --del-annotation %q sent %s, want the parameters %s and the annotations %s",
+                test.name, test.args, test.delParams, test.delAnnotations, request.Body, test.parameters, test.annotations)
+        }
+    }
+}
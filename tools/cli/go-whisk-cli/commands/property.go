@@ -19,6 +19,12 @@ package commands
 import (
     "errors"
     "fmt"
+    "io/ioutil"
+    "net/url"
+    "path/filepath"
+    "regexp"
+    "sort"
+    "strings"
     "os"
 
     "github.com/mitchellh/go-homedir"
@@ -38,6 +44,9 @@ var Properties struct {
     CLIVersion string
     Namespace  string
     PropsFile  string
+    Profile    string
+    Proxy      string
+    Cert       string
 }
 
 const DefaultAuth       string = ""
@@ -47,6 +56,12 @@ const DefaultAPIBuild   string = ""
 const DefaultAPIBuildNo string = ""
 const DefaultNamespace  string = "_"
 const DefaultPropsFile  string = "~/.wskprops"
+const DefaultProfile    string = "default"
+
+// Profile names are made of letters, digits, '-' and '_', so that backup and swap files next to a properties file,
+// such as .wskprops.dev~ or .wskprops.dev.swp, are not taken for profiles. The extensions of such files are reserved.
+var profileNameRegexp = regexp.MustCompile("^[A-Za-z0-9_-]+$")
+var reservedProfileNames = []string{"bak", "old", "orig", "swo", "swp", "tmp"}
 
 var propertyCmd = &cobra.Command{
     Use:   "property",
@@ -145,6 +160,36 @@ var propertySetCmd = &cobra.Command{
             }
         }
 
+        if proxy := flags.property.proxySet; len(proxy) > 0 {
+            if _, err := url.Parse(proxy); err != nil {
+                whisk.Debug(whisk.DbgError, "url.Parse(%s) error: %s\n", proxy, err)
+                errStr := wski18n.T("Unable to set proxy value; the proxy URL '{{.proxy}}' is invalid: {{.err}}",
+                        map[string]interface{}{"proxy": proxy, "err": err})
+                werr = whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL,
+                    whisk.DISPLAY_MSG, whisk.DISPLAY_USAGE)
+            } else {
+                props["PROXY"] = proxy
+                okMsg += fmt.Sprintf(
+                    wski18n.T("{{.ok}} whisk proxy set to {{.proxy}}\n",
+                        map[string]interface{}{"ok": color.GreenString("ok:"), "proxy": boldString(proxy)}))
+            }
+        }
+
+        if cert := flags.property.certSet; len(cert) > 0 {
+            if _, err := os.Stat(cert); err != nil {
+                whisk.Debug(whisk.DbgError, "os.Stat(%s) error: %s\n", cert, err)
+                errStr := wski18n.T("Unable to set CA certificate file; '{{.file}}' is not a valid file: {{.err}}",
+                        map[string]interface{}{"file": cert, "err": err})
+                werr = whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL,
+                    whisk.DISPLAY_MSG, whisk.DISPLAY_USAGE)
+            } else {
+                props["CERT"] = cert
+                okMsg += fmt.Sprintf(
+                    wski18n.T("{{.ok}} whisk CA certificate file set to {{.file}}\n",
+                        map[string]interface{}{"ok": color.GreenString("ok:"), "file": boldString(cert)}))
+            }
+        }
+
         err = writeProps(Properties.PropsFile, props)
         if err != nil {
             whisk.Debug(whisk.DbgError, "writeProps(%s, %#v) failed: %s\n", Properties.PropsFile, props, err)
@@ -227,6 +272,20 @@ var propertyUnsetCmd = &cobra.Command{
             }
         }
 
+        if flags.property.proxy {
+            delete(props, "PROXY")
+            okMsg += fmt.Sprintf(
+                wski18n.T("{{.ok}} whisk proxy unset.\n",
+                    map[string]interface{}{"ok": color.GreenString("ok:")}))
+        }
+
+        if flags.property.cert {
+            delete(props, "CERT")
+            okMsg += fmt.Sprintf(
+                wski18n.T("{{.ok}} whisk CA certificate file unset.\n",
+                    map[string]interface{}{"ok": color.GreenString("ok:")}))
+        }
+
         err = writeProps(Properties.PropsFile, props)
         if err != nil {
             whisk.Debug(whisk.DbgError, "writeProps(%s, %#v) failed: %s\n", Properties.PropsFile, props, err)
@@ -253,32 +312,48 @@ var propertyGetCmd = &cobra.Command{
     PreRunE: setupClientConfig,
     RunE: func(cmd *cobra.Command, args []string) error {
 
+        selected := 0
+        for _, field := range []bool{flags.property.auth, flags.property.apihost, flags.property.apiversion,
+            flags.property.namespace, flags.property.cliversion, flags.property.apibuild, flags.property.apibuildno} {
+            if field {
+                selected++
+            }
+        }
+
+        if flags.property.profiles {
+            return printProfiles()
+        }
+
         // If no property is explicitly specified, default to all properties
-        if !(flags.property.all || flags.property.auth ||
-             flags.property.apiversion || flags.property.cliversion ||
-             flags.property.namespace || flags.property.apibuild ||
-             flags.property.apihost || flags.property.apibuildno) {
+        if selected == 0 {
             flags.property.all = true
         }
 
+        // A single property is printed without a label so that it can be used in command substitution
+        bare := selected == 1 && !flags.property.all
+
         if flags.property.all || flags.property.auth {
-            fmt.Fprintf(color.Output, "%s\t\t%s\n", wski18n.T("whisk auth"), boldString(Properties.Auth))
+            auth := Properties.Auth
+            if !flags.property.auth {
+                auth = maskAuthKey(auth)
+            }
+            printProperty(wski18n.T("whisk auth"), "\t\t", auth, bare)
         }
 
         if flags.property.all || flags.property.apihost {
-            fmt.Fprintf(color.Output, "%s\t\t%s\n", wski18n.T("whisk API host"), boldString(Properties.APIHost))
+            printProperty(wski18n.T("whisk API host"), "\t\t", Properties.APIHost, bare)
         }
 
         if flags.property.all || flags.property.apiversion {
-            fmt.Fprintf(color.Output, "%s\t%s\n", wski18n.T("whisk API version"), boldString(Properties.APIVersion))
+            printProperty(wski18n.T("whisk API version"), "\t", Properties.APIVersion, bare)
         }
 
         if flags.property.all || flags.property.namespace {
-            fmt.Fprintf(color.Output, "%s\t\t%s\n", wski18n.T("whisk namespace"), boldString(Properties.Namespace))
+            printProperty(wski18n.T("whisk namespace"), "\t\t", Properties.Namespace, bare)
         }
 
         if flags.property.all || flags.property.cliversion {
-            fmt.Fprintf(color.Output, "%s\t%s\n", wski18n.T("whisk CLI version"), boldString(Properties.CLIVersion))
+            printProperty(wski18n.T("whisk CLI version"), "\t", Properties.CLIVersion, bare)
         }
 
         if flags.property.all || flags.property.apibuild || flags.property.apibuildno {
@@ -290,10 +365,10 @@ var propertyGetCmd = &cobra.Command{
                 info.BuildNo = wski18n.T("Unknown")
             }
             if flags.property.all || flags.property.apibuild {
-                fmt.Fprintf(color.Output, "%s\t\t%s\n", wski18n.T("whisk API build"), boldString(info.Build))
+                printProperty(wski18n.T("whisk API build"), "\t\t", info.Build, bare)
             }
             if flags.property.all || flags.property.apibuildno {
-                fmt.Fprintf(color.Output, "%s\t%s\n", wski18n.T("whisk API build number"), boldString(info.BuildNo))
+                printProperty(wski18n.T("whisk API build number"), "\t", info.BuildNo, bare)
             }
             if err != nil {
                 errStr := fmt.Sprintf(
@@ -308,6 +383,25 @@ var propertyGetCmd = &cobra.Command{
     },
 }
 
+// printProperty prints a property value after its label, or only the value, uncolored, when bare is set
+func printProperty(label string, separator string, value string, bare bool) {
+    if bare {
+        fmt.Println(value)
+        return
+    }
+
+    fmt.Fprintf(color.Output, "%s%s%s\n", label, separator, boldString(value))
+}
+
+// maskAuthKey hides all but the last four characters of an authorization key
+func maskAuthKey(auth string) string {
+    if len(auth) <= 4 {
+        return strings.Repeat("x", len(auth))
+    }
+
+    return "xxxx…" + auth[len(auth)-4:]
+}
+
 func init() {
     propertyCmd.AddCommand(
         propertySetCmd,
@@ -316,7 +410,7 @@ func init() {
     )
 
     // need to set property flags as booleans instead of strings... perhaps with boolApihost...
-    propertyGetCmd.Flags().BoolVar(&flags.property.auth, "auth", false, wski18n.T("authorization key"))
+    propertyGetCmd.Flags().BoolVar(&flags.property.auth, "auth", false, wski18n.T("authorization key; shown unmasked only when this flag is given"))
     propertyGetCmd.Flags().BoolVar(&flags.property.apihost, "apihost", false, wski18n.T("whisk API host"))
     propertyGetCmd.Flags().BoolVar(&flags.property.apiversion, "apiversion", false, wski18n.T("whisk API version"))
     propertyGetCmd.Flags().BoolVar(&flags.property.apibuild, "apibuild", false, wski18n.T("whisk API build version"))
@@ -324,16 +418,21 @@ func init() {
     propertyGetCmd.Flags().BoolVar(&flags.property.cliversion, "cliversion", false, wski18n.T("whisk CLI version"))
     propertyGetCmd.Flags().BoolVar(&flags.property.namespace, "namespace", false, wski18n.T("whisk namespace"))
     propertyGetCmd.Flags().BoolVar(&flags.property.all, "all", false, wski18n.T("all properties"))
+    propertyGetCmd.Flags().BoolVar(&flags.property.profiles, "profiles", false, wski18n.T("list the profiles and mark the one in use"))
 
     propertySetCmd.Flags().StringVarP(&flags.global.auth, "auth", "u", "", wski18n.T("authorization `KEY`"))
     propertySetCmd.Flags().StringVar(&flags.property.apihostSet, "apihost", "", wski18n.T("whisk API `HOST`"))
     propertySetCmd.Flags().StringVar(&flags.property.apiversionSet, "apiversion", "", wski18n.T("whisk API `VERSION`"))
     propertySetCmd.Flags().StringVar(&flags.property.namespaceSet, "namespace", "", wski18n.T("whisk `NAMESPACE`"))
+    propertySetCmd.Flags().StringVar(&flags.property.proxySet, "proxy", "", wski18n.T("HTTP(S) proxy `URL` used to reach the whisk API host"))
+    propertySetCmd.Flags().StringVar(&flags.property.certSet, "cert", "", wski18n.T("PEM `FILE` of additional certificate authorities to trust"))
 
     propertyUnsetCmd.Flags().BoolVar(&flags.property.auth, "auth", false, wski18n.T("authorization key"))
     propertyUnsetCmd.Flags().BoolVar(&flags.property.apihost, "apihost", false, wski18n.T("whisk API host"))
     propertyUnsetCmd.Flags().BoolVar(&flags.property.apiversion, "apiversion", false, wski18n.T("whisk API version"))
     propertyUnsetCmd.Flags().BoolVar(&flags.property.namespace, "namespace", false, wski18n.T("whisk namespace"))
+    propertyUnsetCmd.Flags().BoolVar(&flags.property.proxy, "proxy", false, wski18n.T("whisk proxy"))
+    propertyUnsetCmd.Flags().BoolVar(&flags.property.cert, "cert", false, wski18n.T("whisk CA certificate file"))
 
 }
 
@@ -345,6 +444,9 @@ func setDefaultProperties() {
     Properties.APIBuildNo = DefaultAPIBuildNo
     Properties.APIVersion = DefaultAPIVersion
     Properties.PropsFile = DefaultPropsFile
+    Properties.Profile = ""
+    Properties.Proxy = ""
+    Properties.Cert = ""
     // Properties.CLIVersion value is set from main's init()
 }
 
@@ -389,6 +491,13 @@ func loadProperties() error {
         //return werr
     }
 
+    // The properties of profile NAME are kept next to the default properties file, in a file suffixed with .NAME
+    if profile := getProfileName(); len(profile) > 0 {
+        Properties.Profile = profile
+        Properties.PropsFile += "." + profile
+        whisk.Debug(whisk.DbgInfo, "Using properties file '%s' of profile '%s'\n", Properties.PropsFile, profile)
+    }
+
     props, err := readProps(Properties.PropsFile)
     if err != nil {
         whisk.Debug(whisk.DbgError, "readProps(%s) failed: %s\n", Properties.PropsFile, err)
@@ -430,10 +539,154 @@ func loadProperties() error {
         Properties.Namespace = namespace
     }
 
+    if proxy, hasProp := props["PROXY"]; hasProp {
+        Properties.Proxy = proxy
+    }
+
+    if cert, hasProp := props["CERT"]; hasProp {
+        Properties.Cert = cert
+    }
+
+    return nil
+}
+
+// getProfileName returns the profile selected by --profile, or else by WSK_PROFILE. The default profile, which
+// uses the properties file itself, is returned as an empty name.
+func getProfileName() string {
+    profile := flags.global.profile
+    if len(profile) == 0 {
+        profile = os.Getenv("WSK_PROFILE")
+    }
+
+    if profile == DefaultProfile {
+        return ""
+    }
+
+    return profile
+}
+
+// getProfileNames returns the sorted names of the profiles that have a properties file, including the default
+// profile
+func getProfileNames() ([]string, error) {
+    propsFilePath := strings.TrimSuffix(Properties.PropsFile, "." + Properties.Profile)
+
+    files, err := ioutil.ReadDir(filepath.Dir(propsFilePath))
+    if err != nil && !os.IsNotExist(err) {
+        whisk.Debug(whisk.DbgError, "ioutil.ReadDir(%s) failed: %s\n", filepath.Dir(propsFilePath), err)
+        errStr := wski18n.T("Unable to list the profiles: {{.err}}", map[string]interface{}{"err": err})
+        werr := whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+        return nil, werr
+    }
+
+    profiles := []string{}
+    prefix := filepath.Base(propsFilePath) + "."
+    for _, file := range files {
+        if !file.IsDir() && strings.HasPrefix(file.Name(), prefix) {
+            if profile := strings.TrimPrefix(file.Name(), prefix); isValidProfileName(profile) {
+                profiles = append(profiles, profile)
+            }
+        }
+    }
+    sort.Strings(profiles)
+
+    return append([]string{DefaultProfile}, profiles...), nil
+}
+
+// isValidProfileName reports whether name can be the name of a profile
+func isValidProfileName(name string) bool {
+    for _, reserved := range reservedProfileNames {
+        if name == reserved {
+            return false
+        }
+    }
+
+    return profileNameRegexp.MatchString(name)
+}
+
+// checkProfile returns an error when the selected profile has an invalid name, or has no properties file. Only
+// property set, which creates profiles, and property get --profiles may name a profile that does not exist yet.
+func checkProfile(cmd *cobra.Command) error {
+    profile := Properties.Profile
+    if len(profile) == 0 {
+        return nil
+    }
+
+    if !isValidProfileName(profile) {
+        whisk.Debug(whisk.DbgError, "Invalid profile name '%s'\n", profile)
+        errStr := wski18n.T("Invalid profile name '{{.name}}'. A profile name may only contain letters, digits, '-' and '_', and cannot be one of {{.reserved}}.",
+                map[string]interface{}{"name": profile, "reserved": strings.Join(reservedProfileNames, ", ")})
+        return whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_USAGE, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+    }
+
+    if cmd.HasParent() && cmd.Parent().Name() == "property" && (cmd.Name() == "set" || (cmd.Name() == "get" && flags.property.profiles)) {
+        return nil
+    }
+
+    if _, err := os.Stat(Properties.PropsFile); err == nil {
+        return nil
+    }
+
+    profiles, err := getProfileNames()
+    if err != nil {
+        return err
+    }
+
+    whisk.Debug(whisk.DbgError, "Properties file '%s' of profile '%s' does not exist\n", Properties.PropsFile, profile)
+    errStr := wski18n.T("Profile '{{.name}}' does not exist. Known profiles: {{.profiles}}. Create it with 'wsk property set --profile {{.name}}'.",
+            map[string]interface{}{"name": profile, "profiles": strings.Join(profiles, ", ")})
+    return whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+}
+
+// printProfiles lists the profiles, marking the one in use with an asterisk
+func printProfiles() error {
+    profiles, err := getProfileNames()
+    if err != nil {
+        return err
+    }
+
+    active := Properties.Profile
+    if len(active) == 0 {
+        active = DefaultProfile
+    }
+
+    found := false
+    for _, profile := range profiles {
+        if profile == active {
+            found = true
+        }
+    }
+
+    // A profile that property set has not created yet is still the one in use
+    if !found {
+        profiles = append(profiles, active)
+    }
+
+    for _, profile := range profiles {
+        if profile == active {
+            fmt.Fprintf(color.Output, "* %s\n", boldString(profile))
+        } else {
+            fmt.Printf("  %s\n", profile)
+        }
+    }
+
     return nil
 }
 
 func parseConfigFlags(cmd *cobra.Command, args []string) error {
+    if err := setColorMode(flags.global.color); err != nil {
+        return err
+    }
+
+    // The properties were loaded before the flags were parsed, so they are loaded again for the named profile
+    if len(flags.global.profile) > 0 {
+        if err := loadProperties(); err != nil {
+            return err
+        }
+    }
+
+    if err := checkProfile(cmd); err != nil {
+        return err
+    }
 
     if auth := flags.global.auth; len(auth) > 0 {
         Properties.Auth = auth
@@ -481,6 +734,34 @@ func parseConfigFlags(cmd *cobra.Command, args []string) error {
         whisk.SetVerbose(true)
     }
 
+    if level := os.Getenv("WSK_DEBUG_LEVEL"); len(level) > 0 {
+        logLevel, err := whisk.ParseLogLevel(level)
+        if err != nil {
+            return err
+        }
+        whisk.SetLogLevel(logLevel)
+    }
+
+    // A debug file is only useful with debug output, so naming one turns debug output on
+    debugFile := flags.global.debugFile
+    if len(debugFile) == 0 {
+        debugFile = os.Getenv("WSK_DEBUG_FILE")
+    }
+    if len(debugFile) > 0 {
+        if err := whisk.SetDebugFile(debugFile); err != nil {
+            return err
+        }
+        whisk.SetDebug(true)
+    }
+
+    if output := flags.global.output; len(output) > 0 && output != formatOptionJson && output != formatOptionYaml {
+        whisk.Debug(whisk.DbgError, "Invalid output format '%s'\n", output)
+        errStr := wski18n.T("Invalid output format '{{.format}}'. Valid formats are 'json' and 'yaml'.",
+                map[string]interface{}{"format": output})
+        werr := whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_USAGE, whisk.DISPLAY_MSG, whisk.DISPLAY_USAGE)
+        return werr
+    }
+
     return nil
 }
 
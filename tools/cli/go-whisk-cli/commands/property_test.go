@@ -0,0 +1,302 @@
+/*
+ * Copyright 2015-2016 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+    "io/ioutil"
+    "net/http"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+
+    "../../go-whisk/whisk"
+)
+
+// runPropertyGet runs property get with the given flags against a server that reports build 2017-01-23 number 42
+func runPropertyGet(t *testing.T, args ...string) (string, error) {
+    server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+        writeJSON(w, http.StatusOK, map[string]interface{}{"build": "2017-01-23", "buildno": "42"})
+    })
+    defer server.Close()
+
+    origProperties := Properties
+    defer func() { Properties = origProperties }()
+    Properties.Auth = "user:secretkey1234"
+    Properties.APIHost = "openwhisk.example.com"
+    Properties.APIVersion = "v1"
+    Properties.Namespace = "ns"
+    Properties.CLIVersion = "2017-01-20T10:00:00+00:00"
+
+    if err := propertyGetCmd.ParseFlags(args); err != nil {
+        t.Fatalf("propertyGetCmd.ParseFlags(%q) failed: %s", args, err)
+    }
+
+    var err error
+    output := captureOutput(t, func() { err = propertyGetCmd.RunE(propertyGetCmd, []string{}) })
+
+    return output, err
+}
+
+func TestPropertyGetSingleField(t *testing.T) {
+    tests := []struct {
+        flag    string
+        want    string
+    }{
+        {"--auth", "user:secretkey1234"},
+        {"--apihost", "openwhisk.example.com"},
+        {"--apiversion", "v1"},
+        {"--namespace", "ns"},
+        {"--cliversion", "2017-01-20T10:00:00+00:00"},
+        {"--apibuild", "2017-01-23"},
+        {"--apibuildno", "42"},
+    }
+
+    // A single field is printed bare, so that it can be used in command substitution
+    for _, test := range tests {
+        output, err := runPropertyGet(t, test.flag)
+        if err != nil {
+            t.Errorf("property get %s failed: %s", test.flag, err)
+        } else if output != test.want + "\n" {
+            t.Errorf("property get %s printed %q, want %q", test.flag, output, test.want + "\n")
+        }
+    }
+}
+
+func TestPropertyGetMultipleFields(t *testing.T) {
+    allLines := []string{
+        "whisk auth\t\txxxx…1234",
+        "whisk API host\t\topenwhisk.example.com",
+        "whisk API version\tv1",
+        "whisk namespace\t\tns",
+        "whisk CLI version\t2017-01-20T10:00:00+00:00",
+        "whisk API build\t\t2017-01-23",
+        "whisk API build number\t42",
+    }
+
+    tests := []struct {
+        args    []string
+        want    []string
+    }{
+        {[]string{}, allLines},
+        {[]string{"--all"}, allLines},
+        // The key is only shown unmasked when it is asked for
+        {[]string{"--all", "--auth"}, append([]string{"whisk auth\t\tuser:secretkey1234"}, allLines[1:]...)},
+        {[]string{"--apihost", "--namespace"}, []string{allLines[1], allLines[3]}},
+        {[]string{"--auth", "--apibuildno"}, []string{"whisk auth\t\tuser:secretkey1234", allLines[6]}},
+        {[]string{"--namespace", "--all"}, allLines},
+    }
+
+    for _, test := range tests {
+        output, err := runPropertyGet(t, test.args...)
+        if err != nil {
+            t.Errorf("property get %q failed: %s", test.args, err)
+        } else if want := strings.Join(test.want, "\n") + "\n"; output != want {
+            t.Errorf("property get %q printed:\n%q\nwant:\n%q", test.args, output, want)
+        }
+    }
+}
+
+func TestMaskAuthKey(t *testing.T) {
+    for auth, want := range map[string]string{
+        "user:secretkey1234": "xxxx…1234",
+        "12345": "xxxx…2345",
+        "1234": "xxxx",
+        "": "",
+    } {
+        if masked := maskAuthKey(auth); masked != want {
+            t.Errorf("maskAuthKey(%q) = %q, want %q", auth, masked, want)
+        }
+        if len(auth) > 4 && strings.Contains(maskAuthKey(auth), auth[:len(auth) - 4]) {
+            t.Errorf("maskAuthKey(%q) shows more than the last four characters", auth)
+        }
+    }
+}
+
+// withProfileDir runs f with the default properties file at path .wskprops in a new temporary directory, and
+// WSK_PROFILE set to envProfile. The properties, and the environment, are restored afterwards.
+func withProfileDir(t *testing.T, envProfile string, f func(path string)) {
+    dir, err := ioutil.TempDir("", "wsk")
+    if err != nil {
+        t.Fatalf("ioutil.TempDir() failed: %s", err)
+    }
+    defer os.RemoveAll(dir)
+
+    origProperties := Properties
+    defer func() { Properties = origProperties }()
+
+    path := filepath.Join(dir, ".wskprops")
+    for key, value := range map[string]string{"WSK_CONFIG_FILE": path, "WSK_PROFILE": envProfile} {
+        origValue, hadValue := os.LookupEnv(key)
+        os.Setenv(key, value)
+        defer func(key string) {
+            if hadValue {
+                os.Setenv(key, origValue)
+            } else {
+                os.Unsetenv(key)
+            }
+        }(key)
+    }
+
+    f(path)
+}
+
+// setTestProfileProperties runs property set --auth auth --apihost apiHost with the given profile
+func setTestProfileProperties(t *testing.T, profile string, auth string, apiHost string) {
+    server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {})
+    defer server.Close()
+    flags.global.profile, flags.global.auth, flags.property.apihostSet = profile, auth, apiHost
+
+    if err := loadProperties(); err != nil {
+        t.Fatalf("loadProperties() with profile %q failed: %s", profile, err)
+    }
+    if err := checkProfile(propertySetCmd); err != nil {
+        t.Fatalf("checkProfile() of property set with profile %q failed: %s", profile, err)
+    }
+
+    var err error
+    captureOutput(t, func() { err = propertySetCmd.RunE(propertySetCmd, []string{}) })
+    if err != nil {
+        t.Fatalf("property set --profile %q failed: %s", profile, err)
+    }
+}
+
+func TestPropertyProfileRoundTrip(t *testing.T) {
+    withProfileDir(t, "", func(path string) {
+        setTestProfileProperties(t, "", "user:default", "default.example.com")
+        setTestProfileProperties(t, "staging", "user:staging", "staging.example.com")
+        setTestProfileProperties(t, "prod", "user:prod", "prod.example.com")
+
+        // Each profile has its own file next to the default one
+        files := map[string]string{
+            path: "default.example.com",
+            path + ".staging": "staging.example.com",
+            path + ".prod": "prod.example.com",
+        }
+        for file, apiHost := range files {
+            if data, err := ioutil.ReadFile(file); err != nil || !strings.Contains(string(data), "APIHOST=" + apiHost + "\n") {
+                t.Errorf("properties file %s holds %q, %v; want APIHOST=%s", file, data, err, apiHost)
+            }
+        }
+
+        origFlags := flags
+        defer func() { flags = origFlags }()
+
+        tests := []struct {
+            flag    string
+            env     string
+            auth    string
+            apiHost string
+        }{
+            {"", "", "user:default", "default.example.com"},
+            {"staging", "", "user:staging", "staging.example.com"},
+            {"default", "prod", "user:default", "default.example.com"},
+            // WSK_PROFILE selects a profile, and --profile overrides it
+            {"", "prod", "user:prod", "prod.example.com"},
+            {"staging", "prod", "user:staging", "staging.example.com"},
+        }
+
+        for _, test := range tests {
+            flags = Flags{}
+            flags.global.profile = test.flag
+            os.Setenv("WSK_PROFILE", test.env)
+
+            if err := loadProperties(); err != nil {
+                t.Errorf("loadProperties() with --profile %q and WSK_PROFILE %q failed: %s", test.flag, test.env, err)
+            } else if err = checkProfile(actionListCmd); err != nil {
+                t.Errorf("checkProfile() with --profile %q and WSK_PROFILE %q failed: %s", test.flag, test.env, err)
+            } else if Properties.Auth != test.auth || Properties.APIHost != test.apiHost {
+                t.Errorf("with --profile %q and WSK_PROFILE %q, the auth is %s and the API host %s; want %s and %s",
+                    test.flag, test.env, Properties.Auth, Properties.APIHost, test.auth, test.apiHost)
+            }
+        }
+    })
+}
+
+func TestPropertyProfileErrors(t *testing.T) {
+    withProfileDir(t, "", func(path string) {
+        setTestProfileProperties(t, "staging", "user:staging", "staging.example.com")
+        // Backup and swap files are not profiles
+        for _, name := range []string{path + ".staging~", path + ".staging.swp", path + ".bak"} {
+            if err := ioutil.WriteFile(name, []byte("AUTH=x\n"), 0644); err != nil {
+                t.Fatalf("ioutil.WriteFile(%s) failed: %s", name, err)
+            }
+        }
+
+        origFlags := flags
+        defer func() { flags = origFlags }()
+
+        tests := []struct {
+            profile     string
+            want        string
+            exitCode    int
+        }{
+            {"dev", "Profile 'dev' does not exist. Known profiles: default, staging.", whisk.EXITCODE_ERR_GENERAL},
+            {"bak", "Invalid profile name 'bak'", whisk.EXITCODE_ERR_USAGE},
+            {"a.b", "Invalid profile name 'a.b'", whisk.EXITCODE_ERR_USAGE},
+        }
+
+        for _, test := range tests {
+            flags = Flags{}
+            flags.global.profile = test.profile
+            loadProperties()
+
+            err := checkProfile(actionListCmd)
+            if whiskErr, ok := err.(*whisk.WskError); !ok || !strings.Contains(err.Error(), test.want) ||
+                    whiskErr.ExitCode != test.exitCode {
+                t.Errorf("checkProfile() with --profile %q error = %#v, want %q with exit code %d", test.profile, err,
+                    test.want, test.exitCode)
+            }
+        }
+    })
+}
+
+func TestPropertyGetProfiles(t *testing.T) {
+    withProfileDir(t, "", func(path string) {
+        setTestProfileProperties(t, "", "user:default", "default.example.com")
+        setTestProfileProperties(t, "staging", "user:staging", "staging.example.com")
+        setTestProfileProperties(t, "prod", "user:prod", "prod.example.com")
+
+        origFlags := flags
+        defer func() { flags = origFlags }()
+
+        tests := []struct {
+            profile string
+            want    string
+        }{
+            {"", "* default\n  prod\n  staging\n"},
+            {"staging", "  default\n  prod\n* staging\n"},
+            // A profile that does not exist yet is listed as the one in use
+            {"new", "  default\n  prod\n  staging\n* new\n"},
+        }
+
+        for _, test := range tests {
+            flags = Flags{}
+            flags.global.profile = test.profile
+            flags.property.profiles = true
+            loadProperties()
+
+            var err error
+            output := captureOutput(t, func() { err = propertyGetCmd.RunE(propertyGetCmd, []string{}) })
+            if err != nil {
+                t.Errorf("property get --profiles with profile %q failed: %s", test.profile, err)
+            } else if output != test.want {
+                t.Errorf("property get --profiles with profile %q printed %q, want %q", test.profile, output, test.want)
+            }
+        }
+    })
+}
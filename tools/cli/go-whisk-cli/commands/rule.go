@@ -17,14 +17,20 @@
 package commands
 
 import (
+    "context"
     "errors"
     "fmt"
+    "net/http"
+    "strings"
+    "sync"
+    "time"
 
     "../../go-whisk/whisk"
     "../wski18n"
 
     "github.com/fatih/color"
     "github.com/spf13/cobra"
+    "github.com/mattn/go-colorable"
 )
 
 // ruleCmd represents the rule command
@@ -54,9 +60,12 @@ var ruleEnableCmd = &cobra.Command{
         client.Namespace = qualifiedName.namespace
         ruleName := qualifiedName.entityName
 
-        _, _, err = client.Rules.SetState(ruleName, "active")
+        ctx, cancel := getRuleStateContext()
+        defer cancel()
+
+        _, _, err = client.Rules.SetStateWithContext(ctx, ruleName, "active")
         if err != nil {
-            whisk.Debug(whisk.DbgError, "client.Rules.SetState(%s, active) failed: %s\n", ruleName, err)
+            whisk.Debug(whisk.DbgError, "client.Rules.SetStateWithContext(%s, active) failed: %s\n", ruleName, err)
             errStr := wski18n.T("Unable to enable rule '{{.name}}': {{.err}}",
                     map[string]interface{}{"name": ruleName, "err": err})
             werr := whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
@@ -91,9 +100,12 @@ var ruleDisableCmd = &cobra.Command{
         client.Namespace = qualifiedName.namespace
         ruleName := qualifiedName.entityName
 
-        _, _, err = client.Rules.SetState(ruleName, "inactive")
+        ctx, cancel := getRuleStateContext()
+        defer cancel()
+
+        _, _, err = client.Rules.SetStateWithContext(ctx, ruleName, "inactive")
         if err != nil {
-            whisk.Debug(whisk.DbgError, "client.Rules.SetState(%s, inactive) failed: %s\n", ruleName, err)
+            whisk.Debug(whisk.DbgError, "client.Rules.SetStateWithContext(%s, inactive) failed: %s\n", ruleName, err)
             errStr := wski18n.T("Unable to disable rule '{{.name}}': {{.err}}",
                     map[string]interface{}{"name": ruleName, "err": err})
             werr := whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
@@ -107,6 +119,38 @@ var ruleDisableCmd = &cobra.Command{
     },
 }
 
+var ruleBulkEnableCmd = &cobra.Command{
+    Use:   "bulk-enable RULE_NAME [RULE_NAME ...]",
+    Short: wski18n.T("enable several rules at once"),
+    SilenceUsage:   true,
+    SilenceErrors:  true,
+    PreRunE: setupClientConfig,
+    RunE: func(cmd *cobra.Command, args []string) error {
+        if whiskErr := checkArgs(args, 1, len(args), "Rule bulk-enable",
+                wski18n.T("At least one rule name is required.")); whiskErr != nil {
+            return whiskErr
+        }
+
+        return setRulesState(args, "active")
+    },
+}
+
+var ruleBulkDisableCmd = &cobra.Command{
+    Use:   "bulk-disable RULE_NAME [RULE_NAME ...]",
+    Short: wski18n.T("disable several rules at once"),
+    SilenceUsage:   true,
+    SilenceErrors:  true,
+    PreRunE: setupClientConfig,
+    RunE: func(cmd *cobra.Command, args []string) error {
+        if whiskErr := checkArgs(args, 1, len(args), "Rule bulk-disable",
+                wski18n.T("At least one rule name is required.")); whiskErr != nil {
+            return whiskErr
+        }
+
+        return setRulesState(args, "inactive")
+    },
+}
+
 var ruleStatusCmd = &cobra.Command{
     Use:   "status RULE_NAME",
     Short: wski18n.T("get rule status"),
@@ -174,6 +218,16 @@ var ruleCreateCmd = &cobra.Command{
             Action:  actionName,
         }
 
+        if err = parseRuleKeyValues(rule); err != nil {
+            return err
+        }
+
+        if flags.rule.strict {
+            if err = verifyRuleEntities(triggerName, actionName); err != nil {
+                return err
+            }
+        }
+
         whisk.Debug(whisk.DbgInfo, "Inserting rule:\n%+v\n", rule)
         var retRule *whisk.Rule
         retRule, _, err = client.Rules.Insert(rule, false)
@@ -194,7 +248,7 @@ var ruleCreateCmd = &cobra.Command{
 }
 
 var ruleUpdateCmd = &cobra.Command{
-    Use:   "update RULE_NAME TRIGGER_NAME ACTION_NAME",
+    Use:   "update RULE_NAME [TRIGGER_NAME [ACTION_NAME]]",
     Short: wski18n.T("update an existing rule, or create a rule if it does not exist"),
     SilenceUsage:   true,
     SilenceErrors:  true,
@@ -202,9 +256,11 @@ var ruleUpdateCmd = &cobra.Command{
     RunE: func(cmd *cobra.Command, args []string) error {
         var err error
         var qualifiedName QualifiedName
+        var triggerName string
+        var actionName string
 
-        if whiskErr := checkArgs(args, 3, 3, "Rule update",
-                wski18n.T("A rule, trigger and action name are required.")); whiskErr != nil {
+        if whiskErr := checkArgs(args, 1, 3, "Rule update",
+                wski18n.T("A rule name is required. A trigger and action name are also required when creating a rule.")); whiskErr != nil {
             return whiskErr
         }
 
@@ -214,8 +270,34 @@ var ruleUpdateCmd = &cobra.Command{
 
         client.Namespace = qualifiedName.namespace
         ruleName := qualifiedName.entityName
-        triggerName := getQualifiedName(args[1], Properties.Namespace)
-        actionName := getQualifiedName(args[2], Properties.Namespace)
+
+        // Reuse the trigger and/or action of the existing rule when they are not specified
+        if len(args) < 3 {
+            existingRule, _, err := client.Rules.Get(ruleName)
+            if err != nil {
+                whisk.Debug(whisk.DbgError, "client.Rules.Get(%s) failed: %s\n", ruleName, err)
+                // Only a rule that does not exist needs the trigger and action to be created
+                errStr := wski18n.T("Unable to get rule '{{.name}}': {{.err}}",
+                        map[string]interface{}{"name": ruleName, "err": err})
+                if getHttpErrorStatus(err) == http.StatusNotFound {
+                    errStr = wski18n.T("Unable to update rule '{{.name}}': a trigger and action name are required to create a rule: {{.err}}",
+                            map[string]interface{}{"name": ruleName, "err": err})
+                }
+                werr := whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+                return werr
+            }
+
+            triggerName = whisk.GetRuleEntityName(existingRule.Trigger)
+            actionName = whisk.GetRuleEntityName(existingRule.Action)
+        }
+
+        if len(args) > 1 {
+            triggerName = getQualifiedName(args[1], Properties.Namespace)
+        }
+
+        if len(args) > 2 {
+            actionName = getQualifiedName(args[2], Properties.Namespace)
+        }
 
         rule := &whisk.Rule{
             Name:    ruleName,
@@ -223,6 +305,16 @@ var ruleUpdateCmd = &cobra.Command{
             Action:  actionName,
         }
 
+        if err = parseRuleKeyValues(rule); err != nil {
+            return err
+        }
+
+        if flags.rule.strict {
+            if err = verifyRuleEntities(triggerName, actionName); err != nil {
+                return err
+            }
+        }
+
         _, _, err = client.Rules.Insert(rule, true)
         if err != nil {
             whisk.Debug(whisk.DbgError, "client.Rules.Insert(%#v) failed: %s\n", rule, err)
@@ -281,6 +373,10 @@ var ruleGetCmd = &cobra.Command{
             return werr
         }
 
+        if len(flags.global.output) > 0 {
+            return printFormatted(rule, flags.global.output)
+        }
+
         if (flags.rule.summary) {
             printRuleSummary(rule)
         } else {
@@ -300,6 +396,120 @@ var ruleGetCmd = &cobra.Command{
     },
 }
 
+var ruleExportCmd = &cobra.Command{
+    Use:   "export RULE_NAME",
+    Short: wski18n.T("export a rule as a manifest that rule import can read"),
+    SilenceUsage:   true,
+    SilenceErrors:  true,
+    PreRunE: setupClientConfig,
+    RunE: func(cmd *cobra.Command, args []string) error {
+        var err error
+        var qualifiedName QualifiedName
+
+        if whiskErr := checkArgs(args, 1, 1, "Rule export", wski18n.T("A rule name is required.")); whiskErr != nil {
+            return whiskErr
+        }
+
+        format := strings.ToLower(flags.rule.format)
+        if format != formatOptionYaml && format != formatOptionJson {
+            errMsg := wski18n.T("Invalid format type: {{.type}}", map[string]interface{}{"type": flags.rule.format})
+            whiskErr := whisk.MakeWskError(errors.New(errMsg), whisk.EXITCODE_ERR_USAGE,
+                whisk.DISPLAY_MSG, whisk.DISPLAY_USAGE)
+            return whiskErr
+        }
+
+        if qualifiedName, err = parseQualifiedName(args[0]); err != nil {
+            return parseQualifiedNameError(args[0], err)
+        }
+
+        client.Namespace = qualifiedName.namespace
+        ruleName := qualifiedName.entityName
+
+        rule, _, err := client.Rules.Get(ruleName)
+        if err != nil {
+            whisk.Debug(whisk.DbgError, "client.Rules.Get(%s) failed: %s\n", ruleName, err)
+            errStr := wski18n.T("Unable to get rule '{{.name}}': {{.err}}",
+                    map[string]interface{}{"name": ruleName, "err": err})
+            werr := whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+            return werr
+        }
+
+        if err = writeManifest(newRuleManifest(rule), format, flags.rule.exportFile, flags.rule.force); err != nil {
+            return err
+        }
+
+        if len(flags.rule.exportFile) > 0 {
+            fmt.Fprintf(color.Output,
+                wski18n.T("{{.ok}} exported rule {{.name}} to {{.file}}\n",
+                    map[string]interface{}{"ok": color.GreenString("ok:"), "name": boldString(ruleName),
+                        "file": flags.rule.exportFile}))
+        }
+
+        return nil
+    },
+}
+
+var ruleImportCmd = &cobra.Command{
+    Use:   "import FILE [RULE_NAME]",
+    Short: wski18n.T("create a rule from a manifest written by rule export"),
+    SilenceUsage:   true,
+    SilenceErrors:  true,
+    PreRunE: setupClientConfig,
+    RunE: func(cmd *cobra.Command, args []string) error {
+        var err error
+        var qualifiedName QualifiedName
+        var manifest *RuleManifest
+
+        if whiskErr := checkArgs(args, 1, 2, "Rule import",
+                wski18n.T("A rule manifest file is required. A rule name is optional.")); whiskErr != nil {
+            return whiskErr
+        }
+
+        if manifest, err = readRuleManifest(args[0]); err != nil {
+            return err
+        }
+
+        // The rule is imported under its exported name in the current namespace unless a name is given
+        ruleName := manifest.Name
+        if len(args) == 2 {
+            ruleName = args[1]
+        }
+
+        if qualifiedName, err = parseQualifiedName(ruleName); err != nil {
+            return parseQualifiedNameError(ruleName, err)
+        }
+
+        client.Namespace = qualifiedName.namespace
+        rule := manifest.getRule(qualifiedName.entityName, qualifiedName.namespace)
+
+        whisk.Debug(whisk.DbgInfo, "Inserting rule:\n%+v\n", rule)
+        if _, _, err = client.Rules.Insert(rule, flags.rule.overwrite); err != nil {
+            whisk.Debug(whisk.DbgError, "client.Rules.Insert(%#v, %t) failed: %s\n", rule, flags.rule.overwrite, err)
+            errStr := wski18n.T("Unable to create rule '{{.name}}': {{.err}}",
+                    map[string]interface{}{"name": rule.Name, "err": err})
+            werr := whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+            return werr
+        }
+
+        // Rules are created active, so only an inactive rule needs its state set
+        if strings.ToLower(manifest.Status) == "inactive" {
+            if _, _, err = client.Rules.SetState(rule.Name, "inactive"); err != nil {
+                whisk.Debug(whisk.DbgError, "client.Rules.SetState(%s, inactive) failed: %s\n", rule.Name, err)
+                errStr := wski18n.T("Unable to disable rule '{{.name}}': {{.err}}",
+                        map[string]interface{}{"name": rule.Name, "err": err})
+                werr := whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+                return werr
+            }
+        }
+
+        fmt.Fprintf(color.Output,
+            wski18n.T("{{.ok}} imported rule {{.name}} from {{.file}}\n",
+                map[string]interface{}{"ok": color.GreenString("ok:"), "name": boldString(rule.Name),
+                    "file": args[0]}))
+        return nil
+    },
+}
+
 var ruleDeleteCmd = &cobra.Command{
     Use:   "delete RULE_NAME",
     Short: wski18n.T("delete rule"),
@@ -334,11 +544,11 @@ var ruleDeleteCmd = &cobra.Command{
 
         _, err = client.Rules.Delete(ruleName)
         if err != nil {
-            whisk.Debug(whisk.DbgError, "client.Rules.Delete(%s) error: %s\n", ruleName, err)
-            errStr := wski18n.T("Unable to delete rule '{{.name}}': {{.err}}",
-                    map[string]interface{}{"name": ruleName, "err": err})
-            werr := whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
-            return werr
+            if flags.rule.force && getHttpErrorStatus(err) == http.StatusNotFound {
+                whisk.Debug(whisk.DbgInfo, "client.Rules.Delete(%s) found no rule\n", ruleName)
+                return printRuleNotFound(ruleName)
+            }
+            return ruleDeleteError(ruleName, err)
         }
 
         fmt.Fprintf(color.Output,
@@ -348,6 +558,61 @@ var ruleDeleteCmd = &cobra.Command{
     },
 }
 
+var ruleRenameCmd = &cobra.Command{
+    Use:   "rename OLD_NAME NEW_NAME",
+    Short: wski18n.T("rename a rule"),
+    SilenceUsage:   true,
+    SilenceErrors:  true,
+    PreRunE: setupClientConfig,
+    RunE: func(cmd *cobra.Command, args []string) error {
+        var err error
+        var oldName QualifiedName
+        var newName QualifiedName
+        var oldRule *whisk.Rule
+
+        if whiskErr := checkArgs(args, 2, 2, "Rule rename",
+                wski18n.T("The current and new rule names are required.")); whiskErr != nil {
+            return whiskErr
+        }
+
+        if oldName, err = parseQualifiedName(args[0]); err != nil {
+            return parseQualifiedNameError(args[0], err)
+        }
+
+        if newName, err = parseQualifiedName(args[1]); err != nil {
+            return parseQualifiedNameError(args[1], err)
+        }
+
+        if oldName.namespace != newName.namespace {
+            whisk.Debug(whisk.DbgError, "Rule rename from namespace %s to %s\n", oldName.namespace, newName.namespace)
+            errStr := wski18n.T("A rule can only be renamed within its namespace.")
+            return whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_USAGE, whisk.DISPLAY_MSG, whisk.DISPLAY_USAGE)
+        }
+
+        client.Namespace = oldName.namespace
+
+        if oldRule, _, err = client.Rules.Get(oldName.entityName); err != nil {
+            whisk.Debug(whisk.DbgError, "client.Rules.Get(%s) failed: %s\n", oldName.entityName, err)
+            errStr := wski18n.T("Unable to get rule '{{.name}}': {{.err}}",
+                    map[string]interface{}{"name": oldName.entityName, "err": err})
+            return whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+        }
+
+        if err = renameRule(oldRule, newName.entityName); err != nil {
+            return err
+        }
+
+        fmt.Fprintf(color.Output,
+            wski18n.T("{{.ok}} renamed rule {{.old}} to {{.new}}\n",
+                map[string]interface{}{
+                    "ok": color.GreenString("ok:"),
+                    "old": boldString(oldName.entityName),
+                    "new": boldString(newName.entityName),
+                }))
+        return nil
+    },
+}
+
 var ruleListCmd = &cobra.Command{
     Use:   "list [NAMESPACE]",
     Short: wski18n.T("list all rules"),
@@ -375,12 +640,55 @@ var ruleListCmd = &cobra.Command{
             client.Namespace = qualifiedName.namespace
         }
 
+        if flags.rule.json {
+            if len(flags.global.output) > 0 && flags.global.output != formatOptionJson {
+                errStr := wski18n.T("The --json flag cannot be used with --output {{.format}}.",
+                        map[string]interface{}{"format": flags.global.output})
+                werr := whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_USAGE, whisk.DISPLAY_MSG, whisk.DISPLAY_USAGE)
+                return werr
+            }
+            flags.global.output = formatOptionJson
+        }
+
+        if len(flags.rule.trigger) > 0 && len(flags.rule.status) > 0 {
+            errStr := wski18n.T("The --trigger and --status flags cannot be used together.")
+            werr := whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_USAGE, whisk.DISPLAY_MSG, whisk.DISPLAY_USAGE)
+            return werr
+        }
+
+        if len(flags.rule.action) > 0 && len(flags.rule.status) > 0 {
+            errStr := wski18n.T("The --action and --status flags cannot be used together.")
+            werr := whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_USAGE, whisk.DISPLAY_MSG, whisk.DISPLAY_USAGE)
+            return werr
+        }
+
+        if flags.rule.status != "" && flags.rule.status != "active" && flags.rule.status != "inactive" {
+            errStr := wski18n.T("Invalid rule status '{{.status}}'. Valid values are 'active' and 'inactive'.",
+                    map[string]interface{}{"status": flags.rule.status})
+            werr := whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+            return werr
+        }
+
         ruleListOptions := &whisk.RuleListOptions{
             Skip:  flags.common.skip,
             Limit: flags.common.limit,
+            Docs:  flags.common.full,
+        }
+
+        // The server cannot filter rules by trigger or action, so every rule is fetched and filtered here
+        var rules []whisk.Rule
+        var total int
+        if flags.common.all || len(flags.rule.trigger) > 0 || len(flags.rule.action) > 0 {
+            err = listAllPages(func(limit int, skip int) (int, *http.Response, error) {
+                ruleListOptions.Limit, ruleListOptions.Skip = limit, skip
+                page, _, resp, err := client.Rules.List(ruleListOptions)
+                rules = append(rules, page...)
+                return len(page), resp, err
+            })
+        } else {
+            rules, total, _, err = client.Rules.List(ruleListOptions)
         }
 
-        rules, _, err := client.Rules.List(ruleListOptions)
         if err != nil {
             whisk.Debug(whisk.DbgError, "client.Rules.List(%#v) error: %s\n", ruleListOptions, err)
             errStr := wski18n.T("Unable to obtain the list of rules for namespace '{{.name}}': {{.err}}",
@@ -388,28 +696,767 @@ var ruleListCmd = &cobra.Command{
             werr := whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
             return werr
         }
-        printList(rules)
+
+        // List rows are summaries without a status, trigger or action, so every listed rule is fetched before it is
+        // filtered
+        if len(flags.rule.status) > 0 || len(flags.rule.trigger) > 0 || len(flags.rule.action) > 0 {
+            if rules, err = getRuleDetails(rules); err != nil {
+                return err
+            }
+        }
+
+        if len(flags.rule.status) > 0 {
+            rules = filterRulesByStatus(rules, flags.rule.status)
+        }
+
+        if len(flags.rule.trigger) > 0 {
+            rules = filterRulesByTrigger(rules, flags.rule.trigger)
+        }
+
+        if len(flags.rule.action) > 0 {
+            rules = filterRulesByAction(rules, flags.rule.action)
+        }
+
+        if flags.rule.nameSort {
+            sortRules(rules)
+        }
+
+        if flags.common.full && len(flags.global.output) == 0 {
+            printRuleListFull(rules)
+        } else if err = printList(rules); err != nil {
+            return err
+        }
+
+        printListTotal(len(rules), total, "rules")
+
+        if len(flags.global.output) == 0 {
+            if len(flags.rule.trigger) > 0 && len(flags.rule.action) > 0 {
+                fmt.Printf(wski18n.T("found {{.count}} rule(s) for trigger {{.trigger}} and action {{.action}}\n",
+                    map[string]interface{}{"count": len(rules), "trigger": flags.rule.trigger, "action": flags.rule.action}))
+            } else if len(flags.rule.trigger) > 0 {
+                fmt.Printf(wski18n.T("found {{.count}} rule(s) for trigger {{.name}}\n",
+                    map[string]interface{}{"count": len(rules), "name": flags.rule.trigger}))
+            } else if len(flags.rule.action) > 0 {
+                fmt.Printf(wski18n.T("found {{.count}} rule(s) for action {{.name}}\n",
+                    map[string]interface{}{"count": len(rules), "name": flags.rule.action}))
+            }
+        }
+
         return nil
     },
 }
 
+var ruleTestCmd = &cobra.Command{
+    Use:   "test RULE_NAME",
+    Short: wski18n.T("fire the trigger of a rule and wait for the resulting activation"),
+    SilenceUsage:   true,
+    SilenceErrors:  true,
+    PreRunE: setupClientConfig,
+    RunE: func(cmd *cobra.Command, args []string) error {
+        var err error
+        var qualifiedName QualifiedName
+        var triggerName QualifiedName
+        var parameters interface{}
+
+        if whiskErr := checkArgs(args, 1, 1, "Rule test", wski18n.T("A rule name is required.")); whiskErr != nil {
+            return whiskErr
+        }
+
+        if qualifiedName, err = parseQualifiedName(args[0]); err != nil {
+            return parseQualifiedNameError(args[0], err)
+        }
+
+        client.Namespace = qualifiedName.namespace
+        ruleName := qualifiedName.entityName
+
+        if len(flags.rule.payload) > 0 {
+            payload, err := readFile(flags.rule.payload)
+            if err != nil {
+                return err
+            }
+
+            if parameters, err = getJSONFromStrings([]string{payload}, false); err != nil {
+                whisk.Debug(whisk.DbgError, "getJSONFromStrings(%s, false) failed: %s\n", payload, err)
+                errStr := wski18n.T("Invalid payload file '{{.name}}': {{.err}}",
+                        map[string]interface{}{"name": flags.rule.payload, "err": err})
+                werr := whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+                return werr
+            }
+        }
+
+        rule, _, err := client.Rules.Get(ruleName)
+        if err != nil {
+            whisk.Debug(whisk.DbgError, "client.Rules.Get(%s) failed: %s\n", ruleName, err)
+            errStr := wski18n.T("Unable to get rule '{{.name}}': {{.err}}",
+                    map[string]interface{}{"name": ruleName, "err": err})
+            werr := whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+            return werr
+        }
+
+        if rule.Status == "inactive" {
+            fmt.Fprintf(colorable.NewColorableStderr(), wski18n.T("{{.warning}} rule {{.name}} is inactive; its action will not be invoked\n",
+                map[string]interface{}{"warning": color.YellowString("warning:"), "name": boldString(ruleName)}))
+        }
+
+        if triggerName, err = parseQualifiedName(whisk.GetRuleEntityName(rule.Trigger)); err != nil {
+            return parseQualifiedNameError(whisk.GetRuleEntityName(rule.Trigger), err)
+        }
+
+        client.Namespace = triggerName.namespace
+
+        trigResp, _, err := client.Triggers.Fire(triggerName.entityName, parameters)
+        if err != nil {
+            whisk.Debug(whisk.DbgError, "client.Triggers.Fire(%s, %#v) failed: %s\n", triggerName.entityName, parameters, err)
+            errStr := wski18n.T("Unable to fire trigger '{{.name}}': {{.err}}",
+                    map[string]interface{}{"name": triggerName.entityName, "err": err})
+            werr := whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+            return werr
+        }
+
+        fmt.Fprintf(color.Output,
+            wski18n.T("{{.ok}} triggered /{{.namespace}}/{{.name}} with id {{.id}}\n",
+                map[string]interface{}{
+                    "ok": color.GreenString("ok:"),
+                    "namespace": boldString(triggerName.namespace),
+                    "name": boldString(triggerName.entityName),
+                    "id": boldString(trigResp.ActivationID)}))
+
+        timeout := time.Duration(flags.rule.timeout) * time.Second
+        activation, err := pollRuleActivation(ruleName, whisk.GetRuleEntityName(rule.Action), trigResp.ActivationID,
+            time.Now().Add(timeout), timeout)
+        if err != nil {
+            return err
+        }
+
+        fmt.Fprintf(color.Output,
+            wski18n.T("{{.ok}} rule {{.name}} produced activation {{.id}}\n",
+                map[string]interface{}{"ok": color.GreenString("ok:"), "name": boldString(ruleName),
+                    "id": boldString(activation.ActivationID)}))
+
+        return printActivationOutcome(activation)
+    },
+}
+
+// pollRuleActivation waits for the activation of the action of a rule that was started by the trigger activation
+// with the given id. When a trigger fires, the controller records an activation of each enabled rule, caused by the
+// trigger activation, and then invokes the rule's action. The action activation is therefore the first one that
+// started after the rule activation; both times come from the server's clock. Polling starts every 500ms and backs
+// off exponentially up to 5s between attempts, until the deadline; timeout is only reported in errors.
+func pollRuleActivation(ruleName string, fullActionName string, triggerActivationID string, deadline time.Time,
+        timeout time.Duration) (*whisk.Activation, error) {
+    ruleActivation := pollCausedActivation(ruleName, triggerActivationID, deadline)
+    if ruleActivation == nil {
+        errStr := wski18n.T("Rule '{{.name}}' was not activated by trigger activation {{.id}} within {{.timeout}}",
+                map[string]interface{}{"name": ruleName, "id": triggerActivationID, "timeout": timeout})
+        werr := whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+        return nil, werr
+    }
+
+    // Activations are listed by the action name, without its package
+    actionName := fullActionName[strings.LastIndex(fullActionName, "/") + 1:]
+    activation := pollFirstActivation([]string{actionName}, ruleActivation.Start, deadline)
+    if activation == nil {
+        errStr := wski18n.T("No activation of action '{{.name}}' was found for rule '{{.rule}}' within {{.timeout}}",
+                map[string]interface{}{"name": fullActionName, "rule": ruleName, "timeout": timeout})
+        werr := whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+        return nil, werr
+    }
+
+    return activation, nil
+}
+
+// printActivationOutcome prints the result of a completed activation. A failed activation is reported as an
+// application error, which has the exit code of a failed blocking invocation.
+func printActivationOutcome(activation *whisk.Activation) error {
+    printJSON(activation.Response.Result)
+
+    if !activation.Response.Success {
+        var errResult interface{} = activation.Response.Status
+        if activation.Response.Result != nil {
+            if resultErr, found := (*activation.Response.Result)["error"]; found {
+                errResult = resultErr
+            }
+        }
+        return errorResultError(activation.Response.Status, errResult)
+    }
+
+    return nil
+}
+
+// pollCausedActivation returns the activation of the named entity that was caused by the activation with the given
+// id, or nil when none is found before the deadline
+func pollCausedActivation(name string, causeID string, deadline time.Time) (*whisk.Activation) {
+    options := &whisk.ActivationListOptions{
+        Name:  name,
+        Docs:  true,
+    }
+
+    return pollActivations(deadline, func() *whisk.Activation {
+        activations, _, err := client.Activations.List(options)
+        if err != nil {
+            whisk.Debug(whisk.DbgWarn, "client.Activations.List(%#v) error: %s\n", options, err)
+            return nil
+        }
+
+        for i := range activations {
+            if activations[i].Cause == causeID {
+                return &activations[i]
+            }
+        }
+
+        return nil
+    })
+}
+
+// pollFirstActivation returns the first activation of any of the named entities that started at or after the given
+// time, or nil when none is found before the deadline
+func pollFirstActivation(names []string, since int64, deadline time.Time) (*whisk.Activation) {
+    return pollActivations(deadline, func() *whisk.Activation {
+        for _, name := range names {
+            options := &whisk.ActivationListOptions{
+                Name:  name,
+                Since: since,
+                Docs:  true,
+            }
+
+            activations, _, err := client.Activations.List(options)
+            if err != nil {
+                whisk.Debug(whisk.DbgWarn, "client.Activations.List(%#v) error: %s\n", options, err)
+                continue
+            }
+
+            // Activations are listed newest first
+            for i := len(activations) - 1; i >= 0; i-- {
+                if activations[i].Start >= since {
+                    return &activations[i]
+                }
+            }
+        }
+
+        return nil
+    })
+}
+
+// pollActivations calls find until it returns an activation or the deadline passes, in which case it returns nil.
+// Polling starts every 500ms and backs off exponentially up to 5s between attempts.
+func pollActivations(deadline time.Time, find func() *whisk.Activation) (*whisk.Activation) {
+    interval := 500 * time.Millisecond
+    maxInterval := 5 * time.Second
+
+    for {
+        if activation := find(); activation != nil {
+            return activation
+        }
+
+        if time.Now().Add(interval).After(deadline) {
+            return nil
+        }
+
+        time.Sleep(interval)
+        if interval *= 2; interval > maxInterval {
+            interval = maxInterval
+        }
+    }
+}
+
+// getTriggerRules returns the enabled rules of the client namespace whose trigger is the given fully qualified
+// trigger
+func getTriggerRules(fullTriggerName string) ([]whisk.Rule, error) {
+    var listed []whisk.Rule
+    var rules []whisk.Rule
+
+    options := &whisk.RuleListOptions{Docs: true}
+    _, err := whisk.ListAllPages(0, func(limit int, skip int) (int, *http.Response, error) {
+        options.Limit, options.Skip = limit, skip
+        page, _, resp, err := client.Rules.List(options)
+        listed = append(listed, page...)
+        return len(page), resp, err
+    })
+
+    if err != nil {
+        whisk.Debug(whisk.DbgError, "client.Rules.List(%#v) error: %s\n", options, err)
+        errStr := wski18n.T("Unable to obtain the list of rules for namespace '{{.name}}': {{.err}}",
+                map[string]interface{}{"name": getClientNamespace(), "err": err})
+        werr := whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG,
+            whisk.NO_DISPLAY_USAGE)
+        return nil, werr
+    }
+
+    // Listed rules have neither their status nor their trigger
+    if listed, err = getRuleDetails(listed); err != nil {
+        return nil, err
+    }
+
+    for _, rule := range listed {
+        if rule.Status != "inactive" && isSameComponent(whisk.GetRuleEntityName(rule.Trigger), fullTriggerName) {
+            rules = append(rules, rule)
+        }
+    }
+
+    return rules, nil
+}
+
+// getRuleStateContext returns the context for enabling or disabling a rule, which expires after the --timeout
+// number of seconds when one is given
+func getRuleStateContext() (context.Context, context.CancelFunc) {
+    if flags.rule.stateTimeout > 0 {
+        return context.WithTimeout(context.Background(), time.Duration(flags.rule.stateTimeout) * time.Second)
+    }
+
+    return context.WithCancel(context.Background())
+}
+
+type ruleStateResult struct {
+    name    string
+    err     error
+}
+
+// setRulesState sets the state of every named rule and reports each failure. It fails when any rule could not be
+// set.
+func setRulesState(names []string, state string) error {
+    var errFmt, okFmt, failedFmt string  // Message IDs, translated once their arguments are known
+
+    if state == "active" {
+        errFmt = "{{.error}} Unable to enable rule '{{.name}}': {{.err}}\n"
+        okFmt = "{{.ok}} enabled {{.count}} rules, failed {{.failed}}\n"
+        failedFmt = "Unable to enable {{.failed}} of {{.total}} rules"
+    } else {
+        errFmt = "{{.error}} Unable to disable rule '{{.name}}': {{.err}}\n"
+        okFmt = "{{.ok}} disabled {{.count}} rules, failed {{.failed}}\n"
+        failedFmt = "Unable to disable {{.failed}} of {{.total}} rules"
+    }
+
+    results, err := setEachRuleState(names, state)
+    if err != nil {
+        return err
+    }
+
+    failed := 0
+    for _, result := range results {
+        if result.err != nil {
+            failed++
+            fmt.Fprintf(colorable.NewColorableStderr(), wski18n.T(errFmt,
+                map[string]interface{}{"error": color.RedString("error:"), "name": result.name, "err": result.err}))
+        }
+    }
+
+    fmt.Fprintf(color.Output, wski18n.T(okFmt,
+        map[string]interface{}{"ok": color.GreenString("ok:"), "count": len(results) - failed, "failed": failed}))
+
+    if failed > 0 {
+        errStr := wski18n.T(failedFmt, map[string]interface{}{"failed": failed, "total": len(results)})
+        return whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG,
+            whisk.NO_DISPLAY_USAGE)
+    }
+
+    return nil
+}
+
+// setEachRuleState sets the state of every named rule, issuing up to --concurrency requests at a time. The rules of
+// one namespace are finished before the next namespace is started because the client namespace is shared by all
+// requests. All names are validated before any request is made.
+func setEachRuleState(args []string, state string) ([]ruleStateResult, error) {
+    var namespaces []string
+    var results []ruleStateResult
+    rulesByNamespace := make(map[string][]string)
+
+    if flags.rule.concurrency < 1 {
+        errStr := wski18n.T("The concurrency must be at least 1, not {{.concurrency}}",
+            map[string]interface{}{"concurrency": flags.rule.concurrency})
+        return nil, whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_USAGE, whisk.DISPLAY_MSG,
+            whisk.DISPLAY_USAGE)
+    }
+
+    for _, arg := range args {
+        qualifiedName, err := parseQualifiedName(arg)
+        if err != nil {
+            return nil, parseQualifiedNameError(arg, err)
+        }
+
+        if _, ok := rulesByNamespace[qualifiedName.namespace]; !ok {
+            namespaces = append(namespaces, qualifiedName.namespace)
+        }
+        rulesByNamespace[qualifiedName.namespace] = append(rulesByNamespace[qualifiedName.namespace],
+            qualifiedName.entityName)
+    }
+
+    for _, namespace := range namespaces {
+        client.Namespace = namespace
+        results = append(results, setRuleStatesInNamespace(rulesByNamespace[namespace], state)...)
+    }
+
+    return results, nil
+}
+
+func setRuleStatesInNamespace(ruleNames []string, state string) []ruleStateResult {
+    var wg sync.WaitGroup
+    results := make([]ruleStateResult, len(ruleNames))
+    indexes := make(chan int)
+
+    for i := 0; i < flags.rule.concurrency && i < len(ruleNames); i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for index := range indexes {
+                _, _, err := client.Rules.SetState(ruleNames[index], state)
+                if err != nil {
+                    whisk.Debug(whisk.DbgError, "client.Rules.SetState(%s, %s) failed: %s\n", ruleNames[index], state,
+                        err)
+                }
+                results[index] = ruleStateResult{name: ruleNames[index], err: err}
+            }
+        }()
+    }
+
+    for index := range ruleNames {
+        indexes <- index
+    }
+    close(indexes)
+    wg.Wait()
+
+    return results
+}
+
+// ruleDeleteError explains why a rule could not be deleted. A rule that is not found and an active rule, which
+// the server refuses to delete with a conflict, get specific messages.
+func ruleDeleteError(ruleName string, err error) error {
+    var errStr string
+
+    whisk.Debug(whisk.DbgError, "client.Rules.Delete(%s) error: %s\n", ruleName, err)
+
+    errorResponse := whisk.GetHttpErrorResponse(err)
+    if errorResponse != nil && errorResponse.StatusCode == http.StatusNotFound {
+        errStr = wski18n.T("Unable to delete rule '{{.name}}': not found",
+            map[string]interface{}{"name": ruleName})
+    } else if errorResponse != nil && errorResponse.StatusCode == http.StatusConflict {
+        errStr = wski18n.T("Unable to delete rule '{{.name}}' because it is active: {{.err}}. Disable the rule first, or delete it with --disable",
+            map[string]interface{}{"name": ruleName, "err": errorResponse.ErrorMessage()})
+    } else {
+        errStr = wski18n.T("Unable to delete rule '{{.name}}': {{.err}}",
+            map[string]interface{}{"name": ruleName, "err": err})
+    }
+
+    return whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG,
+        whisk.NO_DISPLAY_USAGE)
+}
+
+// getHttpErrorStatus returns the HTTP status code of the error response in err, or 0 when err did not come from an
+// error response
+func getHttpErrorStatus(err error) int {
+    if errorResponse := whisk.GetHttpErrorResponse(err); errorResponse != nil {
+        return errorResponse.StatusCode
+    }
+
+    return 0
+}
+
+// printRuleNotFound reports a rule that delete --force found already deleted
+func printRuleNotFound(ruleName string) error {
+    fmt.Fprintf(color.Output,
+        wski18n.T("{{.ok}} rule {{.name}} does not exist\n",
+            map[string]interface{}{"ok": color.GreenString("ok:"), "name": boldString(ruleName)}))
+    return nil
+}
+
+// renameRule creates a copy of rule named newName and then deletes rule. The copy is kept inactive until rule has
+// been disabled, so that a trigger never fires both rules; the server creates rules active, so the copy is disabled
+// as soon as it exists. Nothing is deleted when the copy cannot be created. When a later step fails, the copy is
+// deleted and rule is re-enabled if it was active; a failure to do so is reported along with the original error.
+func renameRule(rule *whisk.Rule, newName string) error {
+    newRule := &whisk.Rule{
+        Name:        newName,
+        Trigger:     whisk.GetRuleEntityName(rule.Trigger),
+        Action:      whisk.GetRuleEntityName(rule.Action),
+        Annotations: rule.Annotations,
+    }
+
+    whisk.Debug(whisk.DbgInfo, "Inserting rule:\n%+v\n", newRule)
+    retRule, _, err := client.Rules.Insert(newRule, false)
+    if err != nil {
+        whisk.Debug(whisk.DbgError, "client.Rules.Insert(%#v) failed: %s\n", newRule, err)
+        var errStr string
+        errorResponse := whisk.GetHttpErrorResponse(err)
+        if errorResponse != nil && errorResponse.StatusCode == http.StatusConflict {
+            errStr = wski18n.T("Unable to rename rule '{{.name}}': a rule named '{{.new}}' already exists",
+                map[string]interface{}{"name": rule.Name, "new": newName})
+            return whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+        }
+        errStr = wski18n.T("Unable to rename rule '{{.name}}': {{.err}}",
+            map[string]interface{}{"name": rule.Name, "err": err})
+        return whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+    }
+
+    wasActive := rule.Status != "inactive"
+    newActive := retRule.Status != "inactive"
+    oldActive := wasActive
+
+    if newActive {
+        if _, _, err = client.Rules.SetState(newName, "inactive"); err == nil {
+            newActive = false
+        }
+    }
+    if err == nil && oldActive {
+        if _, _, err = client.Rules.SetState(rule.Name, "inactive"); err == nil {
+            oldActive = false
+        }
+    }
+    if err == nil && wasActive {
+        if _, _, err = client.Rules.SetState(newName, "active"); err == nil {
+            newActive = true
+        }
+    }
+    if err == nil {
+        _, err = client.Rules.Delete(rule.Name)
+    }
+    if err == nil {
+        return nil
+    }
+
+    whisk.Debug(whisk.DbgError, "Replacing rule %s failed: %s; removing rule %s\n", rule.Name, err, newName)
+    errStr := wski18n.T("Unable to rename rule '{{.name}}': {{.err}}",
+        map[string]interface{}{"name": rule.Name, "err": err})
+
+    if rollbackErr := rollbackRuleRename(rule.Name, newName, wasActive && !oldActive, newActive); rollbackErr != nil {
+        errStr = wski18n.T("Unable to rename rule '{{.name}}': {{.err}}; the rename could not be undone: {{.rollbackErr}}",
+            map[string]interface{}{"name": rule.Name, "err": err, "rollbackErr": rollbackErr})
+    }
+
+    return whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+}
+
+// rollbackRuleRename deletes the copy made by renameRule, disabling it first when it is active, and re-enables the
+// original rule when renameRule disabled it. Every step is attempted; the first error is returned.
+func rollbackRuleRename(ruleName string, newName string, enableRule bool, newActive bool) error {
+    var firstErr error
+
+    if newActive {
+        if _, _, err := client.Rules.SetState(newName, "inactive"); err != nil {
+            firstErr = err
+        }
+    }
+
+    if _, err := client.Rules.Delete(newName); err != nil && firstErr == nil {
+        firstErr = err
+    }
+
+    if enableRule {
+        if _, _, err := client.Rules.SetState(ruleName, "active"); err != nil && firstErr == nil {
+            firstErr = err
+        }
+    }
+
+    return firstErr
+}
+
+// getRuleDetails fetches each of the listed rules, whose list rows carry no status, trigger or action. Rules deleted
+// since they were listed are left out.
+func getRuleDetails(rules []whisk.Rule) ([]whisk.Rule, error) {
+    var details []whisk.Rule
+
+    for _, rule := range rules {
+        fullRule, _, err := client.Rules.Get(rule.Name)
+        if getHttpErrorStatus(err) == http.StatusNotFound {
+            whisk.Debug(whisk.DbgInfo, "Rule '%s' was deleted after it was listed\n", rule.Name)
+            continue
+        } else if err != nil {
+            whisk.Debug(whisk.DbgError, "client.Rules.Get(%s) failed: %s\n", rule.Name, err)
+            errStr := wski18n.T("Unable to get rule '{{.name}}': {{.err}}",
+                    map[string]interface{}{"name": rule.Name, "err": err})
+            werr := whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+            return nil, werr
+        }
+
+        details = append(details, *fullRule)
+    }
+
+    return details, nil
+}
+
+// filterRulesByStatus keeps only the rules in the given state
+func filterRulesByStatus(rules []whisk.Rule, status string) []whisk.Rule {
+    var filtered []whisk.Rule
+
+    for _, rule := range rules {
+        if rule.Status == status {
+            filtered = append(filtered, rule)
+        }
+    }
+
+    return filtered
+}
+
+// filterRulesByTrigger returns the rules whose trigger is named triggerName. An unqualified name matches the trigger
+// of that name in any namespace or package.
+func filterRulesByTrigger(rules []whisk.Rule, triggerName string) []whisk.Rule {
+    var filtered []whisk.Rule
+
+    for _, rule := range rules {
+        if ruleEntityNameMatches(whisk.GetRuleEntityName(rule.Trigger), triggerName) {
+            filtered = append(filtered, rule)
+        }
+    }
+
+    return filtered
+}
+
+// filterRulesByAction returns the rules whose action is named actionName, which may include a package. An unqualified
+// name matches the action of that name in any namespace.
+func filterRulesByAction(rules []whisk.Rule, actionName string) []whisk.Rule {
+    var filtered []whisk.Rule
+
+    for _, rule := range rules {
+        if ruleEntityNameMatches(whisk.GetRuleEntityName(rule.Action), actionName) {
+            filtered = append(filtered, rule)
+        }
+    }
+
+    return filtered
+}
+
+// ruleEntityNameMatches reports whether the trigger or action name referenced by a rule ends with name. Both names are
+// compared with a single leading slash, so that /myNS/myAction and myNS/myAction are the same, and only whole path
+// segments match: myAction matches /myNS/myAction but not /myNS/otherAction.
+func ruleEntityNameMatches(entityName string, name string) bool {
+    entityName = "/" + strings.TrimPrefix(entityName, "/")
+    suffix := "/" + strings.TrimPrefix(name, "/")
+
+    return entityName == suffix || strings.HasSuffix(entityName, suffix)
+}
+
+// verifyRuleEntities checks that the trigger and action referenced by a rule exist. Both names are fully qualified;
+// the action name may include a package.
+func verifyRuleEntities(triggerName string, actionName string) error {
+    var qualifiedName QualifiedName
+    var resp *http.Response
+    var err error
+
+    namespace := client.Namespace
+    defer func() { client.Namespace = namespace }()
+
+    if qualifiedName, err = parseQualifiedName(triggerName); err != nil {
+        return parseQualifiedNameError(triggerName, err)
+    }
+
+    client.Namespace = qualifiedName.namespace
+    if _, resp, err = client.Triggers.Get(qualifiedName.entityName); err != nil {
+        return ruleEntityGetError("trigger", triggerName, resp, err)
+    }
+
+    if qualifiedName, err = parseQualifiedName(actionName); err != nil {
+        return parseQualifiedNameError(actionName, err)
+    }
+
+    client.Namespace = qualifiedName.namespace
+    if _, resp, err = client.Actions.Get(qualifiedName.entityName); err != nil {
+        return ruleEntityGetError("action", actionName, resp, err)
+    }
+
+    return nil
+}
+
+func ruleEntityGetError(entityType string, entityName string, resp *http.Response, err error) error {
+    var errStr string
+
+    whisk.Debug(whisk.DbgError, "Unable to get %s '%s': %s\n", entityType, entityName, err)
+
+    if resp != nil && resp.StatusCode == http.StatusNotFound {
+        if entityType == "trigger" {
+            errStr = wski18n.T("trigger '{{.name}}' does not exist", map[string]interface{}{"name": entityName})
+        } else {
+            errStr = wski18n.T("action '{{.name}}' does not exist", map[string]interface{}{"name": entityName})
+        }
+    } else {
+        if entityType == "trigger" {
+            errStr = wski18n.T("Unable to verify trigger '{{.name}}': {{.err}}",
+                map[string]interface{}{"name": entityName, "err": err})
+        } else {
+            errStr = wski18n.T("Unable to verify action '{{.name}}': {{.err}}",
+                map[string]interface{}{"name": entityName, "err": err})
+        }
+    }
+
+    return whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG,
+        whisk.NO_DISPLAY_USAGE)
+}
+
+// parseRuleKeyValues sets the rule annotations from the --annotation arguments. Annotations that are not specified
+// are omitted from the request so an update leaves the existing values intact. Rules have no parameters, so any
+// --param arguments are rejected rather than silently dropped.
+func parseRuleKeyValues(rule *whisk.Rule) error {
+    if len(flags.common.param) > 0 {
+        errStr := wski18n.T("Rules do not have parameters; set them on the trigger or the action instead.")
+        return whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_USAGE, whisk.DISPLAY_MSG, whisk.DISPLAY_USAGE)
+    }
+
+    if len(flags.common.annotation) > 0 {
+        whisk.Debug(whisk.DbgInfo, "Parsing annotations: %#v\n", flags.common.annotation)
+        annotations, err := getJSONFromStrings(flags.common.annotation, true)
+        if err != nil {
+            return getJSONFromStringsAnnotError(flags.common.annotation, true, err)
+        }
+
+        rule.Annotations = annotations.(whisk.KeyValueArr)
+    }
+
+    return nil
+}
+
 func init() {
+    ruleCreateCmd.Flags().StringSliceVarP(&flags.common.annotation, "annotation", "a", nil, wski18n.T("annotation values in `KEY VALUE` format"))
+    ruleCreateCmd.Flags().StringVarP(&flags.common.annotFile, "annotation-file", "A", "", wski18n.T("`FILE` containing annotation values in JSON format"))
+    ruleCreateCmd.Flags().BoolVar(&flags.rule.strict, "strict", false, wski18n.T("verify that the trigger and action exist before creating the rule"))
+
+    ruleUpdateCmd.Flags().StringSliceVarP(&flags.common.annotation, "annotation", "a", []string{}, wski18n.T("annotation values in `KEY VALUE` format; existing annotations are kept when none are given"))
+    ruleUpdateCmd.Flags().StringVarP(&flags.common.annotFile, "annotation-file", "A", "", wski18n.T("`FILE` containing annotation values in JSON format"))
+    ruleUpdateCmd.Flags().BoolVar(&flags.rule.strict, "strict", false, wski18n.T("verify that the trigger and action exist before updating the rule"))
+
+    ruleEnableCmd.Flags().IntVar(&flags.rule.stateTimeout, "timeout", 0, wski18n.T("give up after `SECONDS` if the rule has not been enabled; 0 waits indefinitely"))
+
+    ruleDisableCmd.Flags().IntVar(&flags.rule.stateTimeout, "timeout", 0, wski18n.T("give up after `SECONDS` if the rule has not been disabled; 0 waits indefinitely"))
+
+    ruleBulkEnableCmd.Flags().IntVar(&flags.rule.concurrency, "concurrency", 5, wski18n.T("the maximum `NUMBER` of rules to enable concurrently"))
+
+    ruleBulkDisableCmd.Flags().IntVar(&flags.rule.concurrency, "concurrency", 5, wski18n.T("the maximum `NUMBER` of rules to disable concurrently"))
+
     ruleDeleteCmd.Flags().BoolVar(&flags.rule.disable, "disable", false, wski18n.T("automatically disable rule before deleting it"))
+    ruleDeleteCmd.Flags().BoolVar(&flags.rule.force, "force", false, wski18n.T("succeed when the rule does not exist"))
+
+    ruleTestCmd.Flags().StringVar(&flags.rule.payload, "payload", "", wski18n.T("`FILE` containing the JSON payload used to fire the trigger"))
+    ruleTestCmd.Flags().IntVar(&flags.rule.timeout, "timeout", 30, wski18n.T("the number of `SECONDS` to wait for the resulting activation"))
 
     ruleGetCmd.Flags().BoolVarP(&flags.rule.summary, "summary", "s", false, wski18n.T("summarize rule details"))
 
+    ruleExportCmd.Flags().StringVar(&flags.rule.format, "format", formatOptionYaml, wski18n.T("write the rule manifest in `FORMAT`; json | yaml"))
+    ruleExportCmd.Flags().StringVar(&flags.rule.exportFile, "file", "", wski18n.T("write the rule manifest to `FILE` instead of standard output"))
+    ruleExportCmd.Flags().BoolVar(&flags.rule.force, "force", false, wski18n.T("replace the output file if it already exists"))
+
+    ruleImportCmd.Flags().BoolVar(&flags.rule.overwrite, "overwrite", false, wski18n.T("replace the rule if it already exists"))
+
     ruleListCmd.Flags().IntVarP(&flags.common.skip, "skip", "s", 0, wski18n.T("exclude the first `SKIP` number of rules from the result"))
     ruleListCmd.Flags().IntVarP(&flags.common.limit, "limit", "l", 30, wski18n.T("only return `LIMIT` number of rules from the collection"))
+    ruleListCmd.Flags().BoolVar(&flags.common.all, "all", false, wski18n.T("fetch every page of rules, ignoring --limit"))
+    ruleListCmd.Flags().BoolVarP(&flags.common.full, "full", "f", false, wski18n.T("include the trigger and action of each rule"))
+    ruleListCmd.Flags().StringVar(&flags.rule.status, "status", "", wski18n.T("only list rules with the given `STATUS`; active | inactive"))
+    ruleListCmd.Flags().StringVar(&flags.rule.trigger, "trigger", "", wski18n.T("only list the rules of trigger `TRIGGER_NAME`; every page of rules is fetched"))
+    ruleListCmd.Flags().StringVar(&flags.rule.action, "action", "", wski18n.T("only list the rules of action `ACTION_NAME`; every page of rules is fetched"))
+    ruleListCmd.Flags().BoolVar(&flags.rule.json, "json", false, wski18n.T("print the listed rules as a JSON array, the same as --output json; list rows do not include the status, trigger and action of the rules"))
+    ruleListCmd.Flags().BoolVarP(&flags.rule.nameSort, "name-sort", "n", false, wski18n.T("sorts a list alphabetically by entity name; only applicable within the limit/skip returned entity block"))
 
     ruleCmd.AddCommand(
         ruleCreateCmd,
         ruleEnableCmd,
         ruleDisableCmd,
+        ruleBulkEnableCmd,
+        ruleBulkDisableCmd,
         ruleStatusCmd,
         ruleUpdateCmd,
         ruleGetCmd,
+        ruleExportCmd,
+        ruleImportCmd,
         ruleDeleteCmd,
+        ruleRenameCmd,
         ruleListCmd,
+        ruleTestCmd,
     )
 
 }
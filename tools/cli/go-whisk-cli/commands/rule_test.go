@@ -0,0 +1,1298 @@
+/*
+ * Copyright 2015-2016 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+    "encoding/json"
+    "fmt"
+    "io/ioutil"
+    "net/http"
+    "os"
+    "path/filepath"
+    "reflect"
+    "sort"
+    "strconv"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/spf13/cobra"
+
+    "../../go-whisk/whisk"
+)
+
+// ruleListHandler answers rule list requests with rows of rules that carry no status, trigger or action, as the
+// server does unless docs=true is asked for, and rule get requests with the whole rule
+func ruleListHandler(rules []whisk.Rule) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        path := strings.TrimPrefix(r.URL.Path, "/api/v1/namespaces/ns/rules")
+        if len(path) > 1 {
+            for _, rule := range rules {
+                if rule.Name == path[1:] {
+                    writeJSON(w, http.StatusOK, rule)
+                    return
+                }
+            }
+            writeJSON(w, http.StatusNotFound, map[string]interface{}{"error": "The requested resource does not exist.", "code": 1})
+            return
+        }
+
+        limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+        skip, _ := strconv.Atoi(r.URL.Query().Get("skip"))
+        rows := []interface{}{}
+        for i := skip; i < len(rules) && (limit == 0 || i < skip + limit); i++ {
+            if r.URL.Query().Get("docs") == "true" {
+                rows = append(rows, rules[i])
+            } else {
+                rows = append(rows, map[string]interface{}{"namespace": rules[i].Namespace, "name": rules[i].Name})
+            }
+        }
+        writeJSON(w, http.StatusOK, rows)
+    }
+}
+
+// getTestRules returns rules in both states, for triggers and actions in other namespaces and packages
+func getTestRules() []whisk.Rule {
+    return []whisk.Rule{
+        {Namespace: "ns", Name: "r1", Status: "active", Trigger: "/ns/t1", Action: "/ns/a1"},
+        {Namespace: "ns", Name: "r2", Status: "inactive", Trigger: "/ns/t1", Action: "/ns/pkg/a2"},
+        {Namespace: "ns", Name: "r3", Status: "active", Trigger: "/other/t2", Action: "/ns/a1"},
+        {Namespace: "ns", Name: "r4", Status: "inactive", Trigger: "/ns/t2", Action: "/other/pkg/a1"},
+    }
+}
+
+// runRuleList runs rule list against rules, and returns the names of the listed rules and the error
+func runRuleList(t *testing.T, rules []whisk.Rule, setFlags func()) ([]string, error) {
+    server := newTestServer(t, ruleListHandler(rules))
+    defer server.Close()
+    setFlags()
+
+    var err error
+    output := captureOutput(t, func() { err = ruleListCmd.RunE(ruleListCmd, []string{}) })
+
+    var names []string
+    for _, line := range strings.Split(output, "\n") {
+        if strings.HasPrefix(line, "/ns/") {
+            names = append(names, strings.TrimPrefix(strings.Fields(line)[0], "/ns/"))
+        }
+    }
+
+    return names, err
+}
+
+func TestRuleListStatus(t *testing.T) {
+    tests := []struct {
+        status  string
+        want    string
+    }{
+        {"", "r1 r2 r3 r4"},
+        {"active", "r1 r3"},
+        {"inactive", "r2 r4"},
+    }
+
+    for _, test := range tests {
+        names, err := runRuleList(t, getTestRules(), func() { flags.rule.status = test.status })
+        if err != nil {
+            t.Errorf("rule list --status %q failed: %s", test.status, err)
+        } else if strings.Join(names, " ") != test.want {
+            t.Errorf("rule list --status %q listed %q, want %s", test.status, names, test.want)
+        }
+    }
+}
+
+func TestRuleListInvalidStatus(t *testing.T) {
+    for _, status := range []string{"Active", "enabled", "active,inactive"} {
+        names, err := runRuleList(t, getTestRules(), func() { flags.rule.status = status })
+        if whiskErr, ok := err.(*whisk.WskError); !ok || whiskErr.ExitCode != whisk.EXITCODE_ERR_GENERAL {
+            t.Errorf("rule list --status %q error = %#v, want an error with exit code %d", status, err,
+                whisk.EXITCODE_ERR_GENERAL)
+        }
+        if len(names) > 0 {
+            t.Errorf("rule list --status %q listed %q", status, names)
+        }
+    }
+}
+
+func TestRuleListFull(t *testing.T) {
+    rules := append(getTestRules()[:2],
+        whisk.Rule{Namespace: "ns", Name: "r5", Status: "active", Action: "/ns/a1"},
+        whisk.Rule{Namespace: "ns", Name: "r6", Status: "active", Trigger: map[string]interface{}{"path": "ns", "name": "t3"}},
+    )
+    server := newTestServer(t, ruleListHandler(rules))
+    defer server.Close()
+    flags.common.full = true
+
+    var err error
+    output := captureOutput(t, func() { err = ruleListCmd.RunE(ruleListCmd, []string{}) })
+    if err != nil {
+        t.Fatalf("rule list --full failed: %s", err)
+    }
+
+    if request := server.getRequest("GET", "ns/rules"); request == nil || request.Query.Get("docs") != "true" {
+        t.Errorf("rule list --full did not ask for docs; requests: %q", server.getRequests())
+    }
+
+    // A missing trigger or action is an empty column
+    want := "rules\n" +
+        "/ns/r1\t/ns/t1\t/ns/a1\n" +
+        "/ns/r2\t/ns/t1\t/ns/pkg/a2\n" +
+        "/ns/r5\t\t/ns/a1\n" +
+        "/ns/r6\t/ns/t3\t\n"
+    if !strings.HasPrefix(output, want) {
+        t.Errorf("rule list --full printed:\n%q\nwant:\n%q", output, want)
+    }
+}
+
+// entityHandler answers gets of the given paths below /api/v1/namespaces/ with an empty entity, other gets with 404,
+// and puts with their body
+func entityHandler(paths ...string) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        if r.Method == "PUT" {
+            body, _ := ioutil.ReadAll(r.Body)
+            w.Header().Set("Content-Type", "application/json")
+            w.Write(body)
+            return
+        }
+
+        path := strings.TrimPrefix(r.URL.Path, "/api/v1/namespaces/")
+        for _, existing := range paths {
+            if path == existing {
+                writeJSON(w, http.StatusOK, map[string]interface{}{})
+                return
+            }
+        }
+        writeJSON(w, http.StatusNotFound, map[string]interface{}{"error": "The requested resource does not exist.", "code": 1})
+    }
+}
+
+func TestRuleInsertStrict(t *testing.T) {
+    tests := []struct {
+        strict      bool
+        trigger     string
+        action      string
+        want        string
+        requests    []string
+    }{
+        {true, "/ns/t", "/other/pkg/a", "",
+            []string{"GET ns/triggers/t", "GET other/actions/pkg/a", "PUT ns/rules/r"}},
+        {true, "/ns/missing", "/other/pkg/a", "trigger '/ns/missing' does not exist",
+            []string{"GET ns/triggers/missing"}},
+        {true, "/ns/t", "/other/pkg/missing", "action '/other/pkg/missing' does not exist",
+            []string{"GET ns/triggers/t", "GET other/actions/pkg/missing"}},
+        // The action is looked up in its package, not as an action named pkg of namespace other
+        {true, "/ns/t", "/other/a", "action '/other/a' does not exist",
+            []string{"GET ns/triggers/t", "GET other/actions/a"}},
+        {false, "/ns/missing", "/other/pkg/missing", "", []string{"PUT ns/rules/r"}},
+    }
+
+    for _, test := range tests {
+        for _, cmd := range []*cobra.Command{ruleCreateCmd, ruleUpdateCmd} {
+            server := newTestServer(t, entityHandler("ns/triggers/t", "other/actions/pkg/a"))
+            flags.rule.strict = test.strict
+
+            var err error
+            captureOutput(t, func() { err = cmd.RunE(cmd, []string{"/ns/r", test.trigger, test.action}) })
+            checkRequests(t, server, test.requests...)
+            server.Close()
+
+            if len(test.want) == 0 && err != nil {
+                t.Errorf("rule %s --strict=%t %s %s failed: %s", cmd.Name(), test.strict, test.trigger, test.action, err)
+            } else if len(test.want) > 0 && (err == nil || err.Error() != test.want) {
+                t.Errorf("rule %s --strict=%t %s %s error = %v, want %q", cmd.Name(), test.strict, test.trigger,
+                    test.action, err, test.want)
+            }
+        }
+    }
+}
+
+// getRuleAnnotations returns the annotations in the body of a rule PUT request, as a map
+func getRuleAnnotations(t *testing.T, request *testRequest) map[string]interface{} {
+    var body struct {
+        Annotations []struct {
+            Key     string
+            Value   interface{}
+        }
+    }
+    if err := json.Unmarshal([]byte(request.Body), &body); err != nil {
+        t.Fatalf("json.Unmarshal(%s) failed: %s", request.Body, err)
+    }
+
+    var annotations map[string]interface{}
+    for _, annotation := range body.Annotations {
+        if annotations == nil {
+            annotations = map[string]interface{}{}
+        }
+        annotations[annotation.Key] = annotation.Value
+    }
+
+    return annotations
+}
+
+func TestRuleInsertAnnotations(t *testing.T) {
+    file := writeTestFile(t, "annotations.json", `{"from": "file", "k": "file"}`)
+    defer os.RemoveAll(filepath.Dir(file))
+
+    tests := []struct {
+        args    []string
+        want    map[string]interface{}
+    }{
+        {[]string{"-a", "k", "v", "--annotation", "n", "5", "-a", "obj", `{"b":true}`},
+            map[string]interface{}{"k": "v", "n": float64(5), "obj": map[string]interface{}{"b": true}}},
+        // Annotations given on the command line override the keys of the file
+        {[]string{"-A", file, "-a", "k", "cli"}, map[string]interface{}{"from": "file", "k": "cli"}},
+        // Without annotations the request has none, so that an update keeps the existing ones
+        {nil, nil},
+    }
+
+    for _, test := range tests {
+        for _, cmd := range []*cobra.Command{ruleCreateCmd, ruleUpdateCmd} {
+            server := newTestServer(t, entityHandler())
+            args, _, annotations, err := parseArgs(append([]string{"/ns/r", "/ns/t", "/ns/a"}, test.args...))
+            if err != nil {
+                server.Close()
+                t.Fatalf("parseArgs(%q) failed: %s", test.args, err)
+            }
+            flags.common.annotation = annotations
+
+            captureOutput(t, func() { err = cmd.RunE(cmd, args) })
+            request := server.getRequest("PUT", "ns/rules/r")
+            server.Close()
+
+            if err != nil {
+                t.Errorf("rule %s %q failed: %s", cmd.Name(), test.args, err)
+            } else if request == nil {
+                t.Errorf("rule %s %q sent no PUT request", cmd.Name(), test.args)
+            } else if have := getRuleAnnotations(t, request); !reflect.DeepEqual(have, test.want) {
+                t.Errorf("rule %s %q sent annotations %#v, want %#v", cmd.Name(), test.args, have, test.want)
+            }
+        }
+    }
+}
+
+func TestRuleInsertInvalidAnnotations(t *testing.T) {
+    // A key with no value is rejected while the arguments are parsed
+    _, _, _, err := parseArgs([]string{"rule", "create", "/ns/r", "/ns/t", "/ns/a", "-a", "k"})
+    if whiskErr, ok := err.(*whisk.WskError); !ok || !whiskErr.DisplayUsage {
+        t.Errorf("parseArgs(-a k) error = %#v, want an error that displays the usage", err)
+    }
+
+    for _, cmd := range []*cobra.Command{ruleCreateCmd, ruleUpdateCmd} {
+        server := newTestServer(t, entityHandler())
+        flags.common.annotation = []string{`{"k": `}
+
+        err := cmd.RunE(cmd, []string{"/ns/r", "/ns/t", "/ns/a"})
+        checkRequests(t, server)
+        server.Close()
+
+        if whiskErr, ok := err.(*whisk.WskError); !ok || !whiskErr.DisplayUsage ||
+                !strings.Contains(whiskErr.Error(), "Invalid annotation argument") {
+            t.Errorf("rule %s with invalid annotations error = %#v, want an error that displays the usage", cmd.Name(), err)
+        }
+    }
+}
+
+func TestRuleUpdateGetErrors(t *testing.T) {
+    tests := []struct {
+        status      int
+        message     string
+        want        string
+        exitCode    int
+    }{
+        // Only a missing rule needs the trigger and action to be created
+        {http.StatusNotFound, "The requested resource does not exist.",
+            "Unable to update rule 'r': a trigger and action name are required to create a rule: ",
+            whisk.EXITCODE_ERR_NOT_FOUND},
+        {http.StatusUnauthorized, "The supplied authentication is invalid", "Unable to get rule 'r': ",
+            http.StatusUnauthorized - 256},
+        {http.StatusInternalServerError, "database unavailable", "Unable to get rule 'r': ",
+            http.StatusInternalServerError - 256},
+    }
+
+    for _, test := range tests {
+        server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+            writeJSON(w, test.status, map[string]interface{}{"error": test.message, "code": 1})
+        })
+
+        err := ruleUpdateCmd.RunE(ruleUpdateCmd, []string{"/ns/r"})
+        checkRequests(t, server, "GET ns/rules/r")
+        server.Close()
+
+        whiskErr, ok := err.(*whisk.WskError)
+        if !ok || !strings.HasPrefix(whiskErr.Error(), test.want) || whiskErr.ExitCode != test.exitCode {
+            t.Errorf("rule update of a rule answered with %d error = %#v, want %q with the exit code %d", test.status,
+                err, test.want, test.exitCode)
+        }
+    }
+}
+
+// deleteErrorHandler answers DELETE requests with status and a controller error body with message, and any other
+// request with an empty entity
+func deleteErrorHandler(status int, message string) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        if r.Method == "DELETE" {
+            writeJSON(w, status, map[string]interface{}{"error": message, "code": 1})
+        } else {
+            writeJSON(w, http.StatusOK, map[string]interface{}{})
+        }
+    }
+}
+
+func TestRuleDeleteErrors(t *testing.T) {
+    tests := []struct {
+        status      int
+        message     string
+        want        string
+        exitCode    int
+    }{
+        {http.StatusNotFound, "The requested resource does not exist.", "Unable to delete rule 'r': not found",
+            whisk.EXITCODE_ERR_NOT_FOUND},
+        {http.StatusConflict, "rule 'r' is active",
+            "Unable to delete rule 'r' because it is active: rule 'r' is active. Disable the rule first, or delete it with --disable",
+            http.StatusConflict - 256},
+        {http.StatusInternalServerError, "boom", "Unable to delete rule 'r': boom (code 1)",
+            http.StatusInternalServerError - 256},
+    }
+
+    for _, test := range tests {
+        server := newTestServer(t, deleteErrorHandler(test.status, test.message))
+        err := ruleDeleteCmd.RunE(ruleDeleteCmd, []string{"/ns/r"})
+        checkRequests(t, server, "DELETE ns/rules/r")
+        server.Close()
+
+        if whiskErr, ok := err.(*whisk.WskError); !ok || whiskErr.Error() != test.want ||
+                whiskErr.ExitCode != test.exitCode {
+            t.Errorf("rule delete with a %d response error = %#v, want %q with exit code %d", test.status, err,
+                test.want, test.exitCode)
+        }
+    }
+}
+
+// ruleStore is a namespace ns of rules that answers rule gets, puts, state changes and deletes like the server,
+// except that the requests in failures get the given error status
+type ruleStore struct {
+    mutex       sync.Mutex
+    rules       map[string]whisk.Rule
+    failures    map[string]int
+}
+
+func (store *ruleStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    store.mutex.Lock()
+    defer store.mutex.Unlock()
+
+    name := strings.TrimPrefix(r.URL.Path, "/api/v1/namespaces/ns/rules/")
+    if status, found := store.failures[r.Method + " " + name]; found {
+        writeJSON(w, status, map[string]interface{}{"error": "request failed", "code": 1})
+        return
+    }
+
+    rule, exists := store.rules[name]
+    switch {
+    case r.Method == "PUT" && exists:
+        writeJSON(w, http.StatusConflict, map[string]interface{}{"error": "resource already exists", "code": 1})
+    case r.Method == "PUT":
+        // Rules are created active
+        json.NewDecoder(r.Body).Decode(&rule)
+        rule.Namespace, rule.Name, rule.Status = "ns", name, "active"
+        store.rules[name] = rule
+        writeJSON(w, http.StatusOK, rule)
+    case !exists:
+        writeJSON(w, http.StatusNotFound, map[string]interface{}{"error": "The requested resource does not exist.", "code": 1})
+    case r.Method == "POST":
+        var state whisk.Rule
+        json.NewDecoder(r.Body).Decode(&state)
+        rule.Status = state.Status
+        store.rules[name] = rule
+        writeJSON(w, http.StatusOK, map[string]interface{}{})
+    case r.Method == "DELETE":
+        delete(store.rules, name)
+        writeJSON(w, http.StatusOK, rule)
+    default:
+        writeJSON(w, http.StatusOK, rule)
+    }
+}
+
+// getStatuses returns the names of the rules in the store with their status
+func (store *ruleStore) getStatuses() string {
+    store.mutex.Lock()
+    defer store.mutex.Unlock()
+
+    var statuses []string
+    for name, rule := range store.rules {
+        statuses = append(statuses, name + "=" + rule.Status)
+    }
+    sort.Strings(statuses)
+
+    return strings.Join(statuses, " ")
+}
+
+func TestRuleRename(t *testing.T) {
+    tests := []struct {
+        status      string
+        existing    bool        // whether a rule already has the new name
+        failures    map[string]int
+        want        string      // the error, or empty when the rename succeeds
+        requests    []string
+        statuses    string
+    }{
+        // The copy is disabled at once, and only enabled after the rule is disabled
+        {"active", false, nil, "",
+            []string{"GET ns/rules/r", "PUT ns/rules/r2", "POST ns/rules/r2", "POST ns/rules/r", "POST ns/rules/r2",
+                "DELETE ns/rules/r"},
+            "r2=active"},
+        {"inactive", false, nil, "",
+            []string{"GET ns/rules/r", "PUT ns/rules/r2", "POST ns/rules/r2", "DELETE ns/rules/r"},
+            "r2=inactive"},
+        // Nothing is deleted when the copy cannot be created
+        {"active", true, nil, "Unable to rename rule 'r': a rule named 'r2' already exists",
+            []string{"GET ns/rules/r", "PUT ns/rules/r2"},
+            "r2=inactive r=active"},
+        {"active", false, map[string]int{"PUT r2": http.StatusInternalServerError},
+            "Unable to rename rule 'r': request failed (code 1)",
+            []string{"GET ns/rules/r", "PUT ns/rules/r2"},
+            "r=active"},
+        {"active", false, map[string]int{"GET r": http.StatusNotFound},
+            "Unable to get rule 'r': request failed (code 1)",
+            []string{"GET ns/rules/r"},
+            "r=active"},
+        // When the rule cannot be deleted, the copy is removed and the rule is enabled again
+        {"active", false, map[string]int{"DELETE r": http.StatusInternalServerError},
+            "Unable to rename rule 'r': request failed (code 1)",
+            []string{"GET ns/rules/r", "PUT ns/rules/r2", "POST ns/rules/r2", "POST ns/rules/r", "POST ns/rules/r2",
+                "DELETE ns/rules/r", "POST ns/rules/r2", "DELETE ns/rules/r2", "POST ns/rules/r"},
+            "r=active"},
+        {"active", false, map[string]int{"DELETE r": http.StatusInternalServerError, "DELETE r2": http.StatusInternalServerError},
+            "Unable to rename rule 'r': request failed (code 1); the rename could not be undone: request failed (code 1)",
+            []string{"GET ns/rules/r", "PUT ns/rules/r2", "POST ns/rules/r2", "POST ns/rules/r", "POST ns/rules/r2",
+                "DELETE ns/rules/r", "POST ns/rules/r2", "DELETE ns/rules/r2", "POST ns/rules/r"},
+            "r2=inactive r=active"},
+    }
+
+    for i, test := range tests {
+        store := &ruleStore{
+            rules: map[string]whisk.Rule{"r": {Namespace: "ns", Name: "r", Status: test.status, Trigger: "/ns/t",
+                Action: "/ns/a"}},
+            failures: test.failures,
+        }
+        if test.existing {
+            store.rules["r2"] = whisk.Rule{Namespace: "ns", Name: "r2", Status: "inactive"}
+        }
+        server := newTestServer(t, store.ServeHTTP)
+
+        var err error
+        output := captureOutput(t, func() { err = ruleRenameCmd.RunE(ruleRenameCmd, []string{"/ns/r", "/ns/r2"}) })
+        checkRequests(t, server, test.requests...)
+        server.Close()
+
+        if len(test.want) == 0 && err != nil {
+            t.Errorf("test %d: rule rename failed: %s", i, err)
+        } else if len(test.want) == 0 && output != "ok: renamed rule r to r2\n" {
+            t.Errorf("test %d: rule rename printed %q", i, output)
+        } else if len(test.want) > 0 && (err == nil || err.Error() != test.want) {
+            t.Errorf("test %d: rule rename error = %v, want %q", i, err, test.want)
+        }
+
+        if statuses := store.getStatuses(); statuses != test.statuses {
+            t.Errorf("test %d: rule rename left the rules %s, want %s", i, statuses, test.statuses)
+        }
+        if rule, found := store.rules["r2"]; found && !test.existing && (rule.Trigger != "/ns/t" || rule.Action != "/ns/a") {
+            t.Errorf("test %d: rule rename created r2 with the trigger %v and action %v", i, rule.Trigger, rule.Action)
+        }
+    }
+}
+
+func TestRuleRenameOtherNamespace(t *testing.T) {
+    server := newTestServer(t, entityHandler())
+    defer server.Close()
+
+    err := ruleRenameCmd.RunE(ruleRenameCmd, []string{"/ns/r", "/other/r2"})
+    if whiskErr, ok := err.(*whisk.WskError); !ok || whiskErr.ExitCode != whisk.EXITCODE_ERR_USAGE {
+        t.Errorf("rule rename to another namespace error = %#v, want a usage error", err)
+    }
+    checkRequests(t, server)
+}
+
+func TestPrintRuleSummary(t *testing.T) {
+    tests := []struct {
+        rule    whisk.Rule
+        want    string
+    }{
+        {whisk.Rule{Namespace: "ns", Name: "r", Status: "active", Trigger: "/ns/t", Action: "/ns/pkg/a",
+            Annotations: whisk.KeyValueArr{
+                {Key: "description", Value: "runs a on t"},
+                {Key: "owner", Value: "me"},
+                {Key: "retries", Value: 3},
+                {Key: "tags", Value: []interface{}{"x", "y"}},
+            }}, `rule /ns/r: runs a on t
+  trigger: /ns/t
+  action: /ns/pkg/a
+  status: active
+  annotations:
+    owner=me
+    retries=3
+    tags=["x","y"]
+`},
+        {whisk.Rule{Namespace: "ns", Name: "r", Status: "inactive",
+            Trigger: map[string]interface{}{"path": "other", "name": "t"},
+            Action: map[string]interface{}{"path": "ns/pkg", "name": "a"}}, `rule /ns/r
+  trigger: /other/t
+  action: /ns/pkg/a
+  status: inactive
+`},
+    }
+
+    for _, test := range tests {
+        if output := captureOutput(t, func() { printRuleSummary(&test.rule) }); output != test.want {
+            t.Errorf("printRuleSummary(%#v) printed:\n%s\nwant:\n%s", test.rule, output, test.want)
+        }
+    }
+}
+
+func TestRuleGetSummary(t *testing.T) {
+    rule := whisk.Rule{Namespace: "ns", Name: "r", Status: "active", Trigger: "/ns/t", Action: "/ns/a"}
+
+    for _, summary := range []bool{true, false} {
+        server := newTestServer(t, ruleListHandler([]whisk.Rule{rule}))
+        flags.rule.summary = summary
+
+        var err error
+        output := captureOutput(t, func() { err = ruleGetCmd.RunE(ruleGetCmd, []string{"/ns/r"}) })
+        server.Close()
+
+        if err != nil {
+            t.Errorf("rule get --summary=%t failed: %s", summary, err)
+        } else if summary && output != "rule /ns/r\n  trigger: /ns/t\n  action: /ns/a\n  status: active\n" {
+            t.Errorf("rule get --summary printed:\n%s", output)
+        } else if !summary && (!strings.HasPrefix(output, "ok: got rule r\n{") || !strings.Contains(output, `"status": "active"`)) {
+            t.Errorf("rule get printed:\n%s\nwant the rule as JSON", output)
+        }
+    }
+
+    if ruleGetCmd.Flags().Lookup("summary") == nil {
+        t.Errorf("rule get has no --summary flag")
+    }
+}
+
+// concurrencyHandler answers every request after a short delay, and records the most requests it was answering at
+// the same time. Requests for rules named bad... fail.
+type concurrencyHandler struct {
+    mutex       sync.Mutex
+    active      int
+    maxActive   int
+}
+
+func (h *concurrencyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    h.mutex.Lock()
+    h.active++
+    if h.active > h.maxActive {
+        h.maxActive = h.active
+    }
+    h.mutex.Unlock()
+
+    time.Sleep(20 * time.Millisecond)
+
+    h.mutex.Lock()
+    h.active--
+    h.mutex.Unlock()
+
+    if strings.Contains(r.URL.Path, "/rules/bad") {
+        writeJSON(w, http.StatusNotFound, map[string]interface{}{"error": "The requested resource does not exist.", "code": 1})
+    } else {
+        writeJSON(w, http.StatusOK, map[string]interface{}{})
+    }
+}
+
+// runBulkRuleCmd runs a bulk rule command against a concurrencyHandler, with standard error discarded, and returns
+// its output
+func runBulkRuleCmd(t *testing.T, cmd *cobra.Command, concurrency int, handler *concurrencyHandler,
+        args ...string) (*testServer, string, error) {
+    server := newTestServer(t, handler.ServeHTTP)
+    defer server.Close()
+    flags.rule.concurrency = concurrency
+
+    devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+    if err != nil {
+        t.Fatalf("os.OpenFile(%s) failed: %s", os.DevNull, err)
+    }
+    defer devNull.Close()
+
+    origStderr := os.Stderr
+    os.Stderr = devNull
+    defer func() { os.Stderr = origStderr }()
+
+    output := captureOutput(t, func() { err = cmd.RunE(cmd, args) })
+
+    return server, output, err
+}
+
+func TestRuleBulkEnableConcurrency(t *testing.T) {
+    var names []string
+    for i := 0; i < 12; i++ {
+        names = append(names, fmt.Sprintf("/ns/r%d", i))
+    }
+
+    for _, concurrency := range []int{1, 3, 20} {
+        handler := &concurrencyHandler{}
+        server, output, err := runBulkRuleCmd(t, ruleBulkEnableCmd, concurrency, handler, names...)
+        if err != nil {
+            t.Errorf("rule bulk-enable --concurrency %d failed: %s", concurrency, err)
+        }
+        if output != "ok: enabled 12 rules, failed 0\n" {
+            t.Errorf("rule bulk-enable --concurrency %d printed %q", concurrency, output)
+        }
+
+        want := concurrency
+        if want > len(names) {
+            want = len(names)
+        }
+        if handler.maxActive > want || (concurrency > 1 && handler.maxActive < 2) {
+            t.Errorf("rule bulk-enable --concurrency %d made %d requests at the same time, want at most %d",
+                concurrency, handler.maxActive, want)
+        }
+
+        if requests := server.getRequests(); len(requests) != len(names) {
+            t.Errorf("rule bulk-enable --concurrency %d made the requests %q", concurrency, requests)
+        }
+        for _, request := range server.requests {
+            if !strings.Contains(request.Body, `"active"`) {
+                t.Errorf("rule bulk-enable sent %s %s", request.Path, request.Body)
+            }
+        }
+    }
+}
+
+func TestRuleBulkDisablePartialFailure(t *testing.T) {
+    _, output, err := runBulkRuleCmd(t, ruleBulkDisableCmd, 2, &concurrencyHandler{},
+        "/ns/r1", "/ns/bad1", "/ns/r2", "/ns/bad2", "/ns/r3")
+
+    if whiskErr, ok := err.(*whisk.WskError); !ok || whiskErr.ExitCode != whisk.EXITCODE_ERR_GENERAL ||
+            whiskErr.Error() != "Unable to disable 2 of 5 rules" {
+        t.Errorf("rule bulk-disable with failures error = %#v, want a general error for 2 of 5 rules", err)
+    }
+    if output != "ok: disabled 3 rules, failed 2\n" {
+        t.Errorf("rule bulk-disable with failures printed %q", output)
+    }
+}
+
+func TestRuleBulkEnableInvalidConcurrency(t *testing.T) {
+    server, _, err := runBulkRuleCmd(t, ruleBulkEnableCmd, 0, &concurrencyHandler{}, "/ns/r1")
+    if whiskErr, ok := err.(*whisk.WskError); !ok || whiskErr.ExitCode != whisk.EXITCODE_ERR_USAGE {
+        t.Errorf("rule bulk-enable --concurrency 0 error = %#v, want a usage error", err)
+    }
+    checkRequests(t, server)
+}
+
+// runRuleListJSON runs rule list with the flags set by setFlags against rules, and returns the output and the error
+func runRuleListJSON(t *testing.T, rules []whisk.Rule, setFlags func()) (string, error) {
+    server := newTestServer(t, ruleListHandler(rules))
+    defer server.Close()
+    flags.common.limit = 30
+    setFlags()
+
+    var err error
+    output := captureOutput(t, func() { err = ruleListCmd.RunE(ruleListCmd, []string{}) })
+
+    return output, err
+}
+
+func TestRuleListJSON(t *testing.T) {
+    publish := false
+    rules := getTestRules()
+    for i := range rules {
+        rules[i].Version = "0.0.1"
+        rules[i].Publish = &publish
+    }
+    rules[0].Annotations = whisk.KeyValueArr{{Key: "owner", Value: "me"}}
+
+    output, err := runRuleListJSON(t, rules, func() {
+        flags.rule.json = true
+        flags.common.full = true
+    })
+    if err != nil {
+        t.Fatalf("rule list --json --full failed: %s", err)
+    }
+
+    var decoded []whisk.Rule
+    if err = json.Unmarshal([]byte(output), &decoded); err != nil {
+        t.Fatalf("rule list --json --full printed %q, which does not decode as []whisk.Rule: %s", output, err)
+    }
+    if !reflect.DeepEqual(decoded, rules) {
+        t.Errorf("rule list --json --full printed rules that decode as:\n%#v\nwant:\n%#v", decoded, rules)
+    }
+
+    // The output is the same as that of --output json, which action list also takes
+    same, err := runRuleListJSON(t, rules, func() {
+        flags.global.output = formatOptionJson
+        flags.common.full = true
+    })
+    if err != nil || same != output {
+        t.Errorf("rule list --output json printed:\n%s\nwant the --json output:\n%s", same, output)
+    }
+}
+
+func TestRuleListJSONSummaries(t *testing.T) {
+    tests := []struct {
+        rules   []whisk.Rule
+        trigger string
+        want    []string
+    }{
+        // List rows only hold the namespace and name
+        {getTestRules(), "", []string{"r1", "r2", "r3", "r4"}},
+        // Nothing but the JSON array is printed when the rules are filtered
+        {getTestRules(), "t1", []string{"r1", "r2"}},
+        {nil, "", []string{}},
+    }
+
+    for _, test := range tests {
+        output, err := runRuleListJSON(t, test.rules, func() {
+            flags.rule.json = true
+            flags.rule.trigger = test.trigger
+        })
+        if err != nil {
+            t.Errorf("rule list --json --trigger %q failed: %s", test.trigger, err)
+            continue
+        }
+
+        var decoded []whisk.Rule
+        if err = json.Unmarshal([]byte(output), &decoded); err != nil || decoded == nil {
+            t.Errorf("rule list --json --trigger %q printed %q, which does not decode as an array: %v", test.trigger,
+                output, err)
+            continue
+        }
+
+        names := []string{}
+        for _, rule := range decoded {
+            if rule.Namespace != "ns" || rule.Status != "" && len(test.trigger) == 0 {
+                t.Errorf("rule list --json printed rule %#v, want a list row", rule)
+            }
+            names = append(names, rule.Name)
+        }
+        if !reflect.DeepEqual(names, test.want) {
+            t.Errorf("rule list --json --trigger %q printed rules %q, want %q", test.trigger, names, test.want)
+        }
+    }
+}
+
+func TestRuleListJSONOutputConflict(t *testing.T) {
+    _, err := runRuleListJSON(t, getTestRules(), func() {
+        flags.rule.json = true
+        flags.global.output = "yaml"
+    })
+
+    if whiskErr, ok := err.(*whisk.WskError); !ok || whiskErr.ExitCode != whisk.EXITCODE_ERR_USAGE {
+        t.Errorf("rule list --json --output yaml error = %#v, want a usage error", err)
+    }
+}
+
+func TestRuleDeleteDisableForce(t *testing.T) {
+    tests := []struct {
+        disable     bool
+        force       bool
+        exists      bool
+        failures    map[string]int
+        requests    []string
+        output      string
+        exitCode    int     // 0 when the delete succeeds
+    }{
+        // The rule is deleted without a state change unless --disable asks for one
+        {false, false, true, nil, []string{"DELETE ns/rules/r"}, "ok: deleted rule r\n", 0},
+        {true, false, true, nil, []string{"POST ns/rules/r", "DELETE ns/rules/r"}, "ok: deleted rule r\n", 0},
+        {false, true, true, nil, []string{"DELETE ns/rules/r"}, "ok: deleted rule r\n", 0},
+        // A conflict is a document conflict, not an inactive rule, so it is an error
+        {true, false, true, map[string]int{"POST r": http.StatusConflict}, []string{"POST ns/rules/r"}, "",
+            http.StatusConflict - 256},
+        {true, true, true, map[string]int{"POST r": http.StatusConflict}, []string{"POST ns/rules/r"}, "",
+            http.StatusConflict - 256},
+        // With --force, a missing rule is not an error
+        {false, true, false, nil, []string{"DELETE ns/rules/r"}, "ok: rule r does not exist\n", 0},
+        {false, false, false, nil, []string{"DELETE ns/rules/r"}, "", whisk.EXITCODE_ERR_NOT_FOUND},
+        {true, false, false, nil, []string{"POST ns/rules/r"}, "", whisk.EXITCODE_ERR_NOT_FOUND},
+        // Other failures are errors, even with --force
+        {true, true, true, map[string]int{"POST r": http.StatusUnauthorized}, []string{"POST ns/rules/r"}, "",
+            http.StatusUnauthorized - 256},
+        {false, true, true, map[string]int{"DELETE r": http.StatusUnauthorized}, []string{"DELETE ns/rules/r"}, "",
+            http.StatusUnauthorized - 256},
+        {false, true, true, map[string]int{"DELETE r": http.StatusServiceUnavailable}, []string{"DELETE ns/rules/r"}, "",
+            http.StatusServiceUnavailable - 256},
+    }
+
+    for _, test := range tests {
+        store := &ruleStore{rules: map[string]whisk.Rule{}, failures: test.failures}
+        if test.exists {
+            store.rules["r"] = whisk.Rule{Namespace: "ns", Name: "r", Status: "inactive"}
+        }
+        server := newTestServer(t, store.ServeHTTP)
+        flags.rule.disable, flags.rule.force = test.disable, test.force
+
+        var err error
+        output := captureOutput(t, func() { err = ruleDeleteCmd.RunE(ruleDeleteCmd, []string{"/ns/r"}) })
+        checkRequests(t, server, test.requests...)
+        server.Close()
+
+        if test.exitCode == 0 && err != nil {
+            t.Errorf("rule delete --disable=%t --force=%t with failures %v failed: %s", test.disable, test.force,
+                test.failures, err)
+        } else if whiskErr, ok := err.(*whisk.WskError); test.exitCode != 0 && (!ok || whiskErr.ExitCode != test.exitCode) {
+            t.Errorf("rule delete --disable=%t --force=%t with failures %v error = %#v, want exit code %d",
+                test.disable, test.force, test.failures, err, test.exitCode)
+        }
+        if output != test.output {
+            t.Errorf("rule delete --disable=%t --force=%t with failures %v printed %q, want %q", test.disable,
+                test.force, test.failures, output, test.output)
+        }
+    }
+}
+
+func TestRuleListTrigger(t *testing.T) {
+    tests := []struct {
+        trigger string
+        want    string
+    }{
+        // Exact and qualified names match, with or without a leading slash
+        {"/ns/t1", "r1 r2"},
+        {"ns/t1", "r1 r2"},
+        {"/other/t2", "r3"},
+        // An unqualified name matches the trigger of that name in any namespace
+        {"t1", "r1 r2"},
+        {"t2", "r3 r4"},
+        // Only whole names match
+        {"t", ""},
+        {"s/t1", ""},
+        {"/ns/t3", ""},
+    }
+
+    for _, test := range tests {
+        server := newTestServer(t, ruleListHandler(getTestRules()))
+        flags.rule.trigger = test.trigger
+
+        var err error
+        output := captureOutput(t, func() { err = ruleListCmd.RunE(ruleListCmd, []string{}) })
+        server.Close()
+
+        if err != nil {
+            t.Errorf("rule list --trigger %s failed: %s", test.trigger, err)
+            continue
+        }
+
+        var names []string
+        for _, line := range strings.Split(output, "\n") {
+            if strings.HasPrefix(line, "/ns/") {
+                names = append(names, strings.TrimPrefix(strings.Fields(line)[0], "/ns/"))
+            }
+        }
+        if strings.Join(names, " ") != test.want {
+            t.Errorf("rule list --trigger %s listed %q, want %s", test.trigger, names, test.want)
+        }
+
+        count := fmt.Sprintf("found %d rule(s) for trigger %s\n", len(names), test.trigger)
+        if !strings.HasSuffix(output, count) {
+            t.Errorf("rule list --trigger %s printed:\n%s\nwant it to end with %q", test.trigger, output, count)
+        }
+    }
+}
+
+func TestRuleListTriggerPages(t *testing.T) {
+    var rules []whisk.Rule
+    for i := 0; i < 450; i++ {
+        rules = append(rules, whisk.Rule{Namespace: "ns", Name: fmt.Sprintf("r%d", i), Status: "active",
+            Trigger: fmt.Sprintf("/ns/t%d", i % 2), Action: "/ns/a"})
+    }
+
+    server := newTestServer(t, ruleListHandler(rules))
+    defer server.Close()
+    flags.common.limit = 30
+    flags.rule.trigger = "t1"
+
+    var err error
+    output := captureOutput(t, func() { err = ruleListCmd.RunE(ruleListCmd, []string{}) })
+    if err != nil {
+        t.Fatalf("rule list --trigger failed: %s", err)
+    }
+
+    // Every rule is listed, past the --limit, before the rules are filtered
+    var skips []string
+    for _, request := range server.requests {
+        if request.Path == "ns/rules" {
+            skips = append(skips, request.Query.Get("skip"))
+        }
+    }
+    if want := "0 200 400"; strings.Join(skips, " ") != want {
+        t.Errorf("rule list --trigger listed the rules with skip %q, want %s", skips, want)
+    }
+    if !strings.HasSuffix(output, "found 225 rule(s) for trigger t1\n") || !strings.Contains(output, "/ns/r449 ") {
+        t.Errorf("rule list --trigger did not find the 225 rules of t1:\n%s", output)
+    }
+}
+
+func TestRuleListTriggerWithStatus(t *testing.T) {
+    server := newTestServer(t, ruleListHandler(getTestRules()))
+    defer server.Close()
+    flags.rule.trigger, flags.rule.status = "t1", "active"
+
+    err := ruleListCmd.RunE(ruleListCmd, []string{})
+    if whiskErr, ok := err.(*whisk.WskError); !ok || whiskErr.ExitCode != whisk.EXITCODE_ERR_USAGE || !whiskErr.DisplayUsage {
+        t.Errorf("rule list --trigger --status error = %#v, want a usage error", err)
+    }
+    checkRequests(t, server)
+}
+
+// runRuleListFilter runs rule list with the --trigger and --action filters against the test rules, and returns the
+// names of the listed rules and the output
+func runRuleListFilter(t *testing.T, trigger string, action string) ([]string, string) {
+    server := newTestServer(t, ruleListHandler(getTestRules()))
+    defer server.Close()
+    flags.rule.trigger, flags.rule.action = trigger, action
+
+    var err error
+    output := captureOutput(t, func() { err = ruleListCmd.RunE(ruleListCmd, []string{}) })
+    if err != nil {
+        t.Errorf("rule list --trigger %q --action %q failed: %s", trigger, action, err)
+    }
+
+    var names []string
+    for _, line := range strings.Split(output, "\n") {
+        if strings.HasPrefix(line, "/ns/") {
+            names = append(names, strings.TrimPrefix(strings.Fields(line)[0], "/ns/"))
+        }
+    }
+
+    return names, output
+}
+
+func TestRuleListAction(t *testing.T) {
+    tests := []struct {
+        action  string
+        want    string
+    }{
+        // Exact and qualified names match, with or without a leading slash
+        {"/ns/a1", "r1 r3"},
+        {"ns/a1", "r1 r3"},
+        {"/other/pkg/a1", "r4"},
+        // Shorter names match the action of that name in any namespace or package
+        {"a1", "r1 r3 r4"},
+        {"pkg/a1", "r4"},
+        {"a2", "r2"},
+        // Only whole names match
+        {"a", ""},
+        {"/ns/a2", ""},
+        {"kg/a2", ""},
+    }
+
+    for _, test := range tests {
+        names, output := runRuleListFilter(t, "", test.action)
+        if strings.Join(names, " ") != test.want {
+            t.Errorf("rule list --action %s listed %q, want %s", test.action, names, test.want)
+        }
+
+        count := fmt.Sprintf("found %d rule(s) for action %s\n", len(names), test.action)
+        if !strings.HasSuffix(output, count) {
+            t.Errorf("rule list --action %s printed:\n%s\nwant it to end with %q", test.action, output, count)
+        }
+    }
+}
+
+func TestRuleListTriggerAndAction(t *testing.T) {
+    tests := []struct {
+        trigger string
+        action  string
+        want    string
+    }{
+        // Rules must match both the trigger and the action
+        {"t1", "a1", "r1"},
+        {"t2", "a1", "r3 r4"},
+        {"t2", "/ns/a1", "r3"},
+        {"/ns/t1", "pkg/a2", "r2"},
+        {"t1", "/other/pkg/a1", ""},
+    }
+
+    for _, test := range tests {
+        names, output := runRuleListFilter(t, test.trigger, test.action)
+        if strings.Join(names, " ") != test.want {
+            t.Errorf("rule list --trigger %s --action %s listed %q, want %s", test.trigger, test.action, names, test.want)
+        }
+
+        count := fmt.Sprintf("found %d rule(s) for trigger %s and action %s\n", len(names), test.trigger, test.action)
+        if !strings.HasSuffix(output, count) {
+            t.Errorf("rule list --trigger %s --action %s printed:\n%s\nwant it to end with %q", test.trigger,
+                test.action, output, count)
+        }
+    }
+}
+
+func TestRuleListActionWithStatus(t *testing.T) {
+    server := newTestServer(t, ruleListHandler(getTestRules()))
+    defer server.Close()
+    flags.rule.action, flags.rule.status = "a1", "active"
+
+    err := ruleListCmd.RunE(ruleListCmd, []string{})
+    if whiskErr, ok := err.(*whisk.WskError); !ok || whiskErr.ExitCode != whisk.EXITCODE_ERR_USAGE {
+        t.Errorf("rule list --action --status error = %#v, want a usage error", err)
+    }
+    checkRequests(t, server)
+}
+
+func TestRuleManifestNames(t *testing.T) {
+    tests := []struct {
+        namespace   string
+        trigger     interface{}
+        action      interface{}
+        manifest    RuleManifest
+    }{
+        {"ns", "/ns/t", "/ns/pkg/a", RuleManifest{Name: "r", Trigger: "t", Action: "pkg/a"}},
+        // Entities of other namespaces keep their namespace
+        {"ns", "/other/t", "/nsx/a", RuleManifest{Name: "r", Trigger: "/other/t", Action: "/nsx/a"}},
+        {"ns", map[string]interface{}{"path": "ns", "name": "t"}, map[string]interface{}{"path": "other/pkg", "name": "a"},
+            RuleManifest{Name: "r", Trigger: "t", Action: "/other/pkg/a"}},
+        {"", "/ns/t", "/ns/a", RuleManifest{Name: "r", Trigger: "/ns/t", Action: "/ns/a"}},
+    }
+
+    for _, test := range tests {
+        rule := &whisk.Rule{Namespace: test.namespace, Name: "r", Trigger: test.trigger, Action: test.action}
+        manifest := newRuleManifest(rule)
+        if !reflect.DeepEqual(*manifest, test.manifest) {
+            t.Errorf("newRuleManifest(%#v) = %#v, want %#v", rule, *manifest, test.manifest)
+        }
+
+        // Relative names move with the rule to another namespace; qualified names do not
+        imported := manifest.getRule("r2", "ns2")
+        wantTrigger := getQualifiedName(test.manifest.Trigger, "ns2")
+        wantAction := getQualifiedName(test.manifest.Action, "ns2")
+        if imported.Name != "r2" || imported.Trigger != wantTrigger || imported.Action != wantAction {
+            t.Errorf("getRule(r2, ns2) of %#v = %#v, want trigger %s and action %s", test.manifest, imported,
+                wantTrigger, wantAction)
+        }
+    }
+}
+
+func TestRuleExportImportRoundTrip(t *testing.T) {
+    dir, err := ioutil.TempDir("", "rule")
+    if err != nil {
+        t.Fatalf("ioutil.TempDir() failed: %s", err)
+    }
+    defer os.RemoveAll(dir)
+
+    annotations := whisk.KeyValueArr{{Key: "owner", Value: "me"}, {Key: "limits", Value: map[string]interface{}{"max": 2.0}}}
+    exported := whisk.Rule{Namespace: "ns", Name: "r", Status: "inactive", Annotations: annotations,
+        Trigger: map[string]interface{}{"path": "ns", "name": "t"},
+        Action: map[string]interface{}{"path": "other/pkg", "name": "a"}}
+
+    wantYaml := "action: /other/pkg/a\n" +
+        "annotations:\n" +
+        "- key: owner\n" +
+        "  value: me\n" +
+        "- key: limits\n" +
+        "  value:\n" +
+        "    max: 2\n" +
+        "name: r\n" +
+        "status: inactive\n" +
+        "trigger: t\n"
+
+    for _, format := range []string{formatOptionYaml, formatOptionJson} {
+        file := filepath.Join(dir, "r." + format)
+        store := &ruleStore{rules: map[string]whisk.Rule{"r": exported}}
+        server := newTestServer(t, store.ServeHTTP)
+        flags.rule.format = format
+        flags.rule.exportFile = file
+
+        output := captureOutput(t, func() { err = ruleExportCmd.RunE(ruleExportCmd, []string{"/ns/r"}) })
+        if err != nil {
+            t.Errorf("rule export --format %s failed: %s", format, err)
+        } else if want := "ok: exported rule r to " + file + "\n"; output != want {
+            t.Errorf("rule export --format %s printed %q, want %q", format, output, want)
+        }
+        if content, _ := ioutil.ReadFile(file); format == formatOptionYaml && string(content) != wantYaml {
+            t.Errorf("rule export --format yaml wrote:\n%s\nwant:\n%s", content, wantYaml)
+        }
+
+        // The rule comes back with the same trigger, action, annotations and status
+        delete(store.rules, "r")
+        flags.rule.exportFile = ""
+        output = captureOutput(t, func() { err = ruleImportCmd.RunE(ruleImportCmd, []string{file, "/ns/r"}) })
+        checkRequests(t, server, "GET ns/rules/r", "PUT ns/rules/r", "POST ns/rules/r")
+        server.Close()
+
+        if err != nil {
+            t.Errorf("rule import of a %s manifest failed: %s", format, err)
+            continue
+        } else if want := "ok: imported rule r from " + file + "\n"; output != want {
+            t.Errorf("rule import printed %q, want %q", output, want)
+        }
+
+        imported := store.rules["r"]
+        if imported.Trigger != "/ns/t" || imported.Action != "/other/pkg/a" || imported.Status != "inactive" ||
+                !reflect.DeepEqual(imported.Annotations, annotations) {
+            t.Errorf("rule import of a %s manifest created %#v, want the trigger /ns/t, the action /other/pkg/a, " +
+                "the annotations %v and an inactive status", format, imported, annotations)
+        }
+    }
+}
+
+func TestRuleImportNamespace(t *testing.T) {
+    file := writeTestFile(t, "r.yaml", "name: r\ntrigger: t\naction: /other/pkg/a\nstatus: active\n")
+    defer os.RemoveAll(filepath.Dir(file))
+
+    tests := []struct {
+        args        []string
+        path        string
+        trigger     string
+    }{
+        {[]string{file, "/ns2/r2"}, "ns2/rules/r2", "/ns2/t"},
+        // Without a name, the rule keeps its exported name
+        {[]string{file}, "_/rules/r", "/_/t"},
+    }
+
+    for _, test := range tests {
+        server := newTestServer(t, entityHandler())
+
+        var err error
+        captureOutput(t, func() { err = ruleImportCmd.RunE(ruleImportCmd, test.args) })
+        checkRequests(t, server, "PUT " + test.path)
+        request := server.getRequest("PUT", test.path)
+        server.Close()
+
+        if err != nil {
+            t.Errorf("rule import %v failed: %s", test.args, err)
+            continue
+        }
+
+        var rule whisk.Rule
+        json.Unmarshal([]byte(request.Body), &rule)
+        if rule.Trigger != test.trigger || rule.Action != "/other/pkg/a" {
+            t.Errorf("rule import %v sent %s, want the trigger %s and the action /other/pkg/a", test.args,
+                request.Body, test.trigger)
+        }
+    }
+}
+
+func TestRuleImportErrors(t *testing.T) {
+    invalid := writeTestFile(t, "invalid.yaml", "name: [r\n")
+    defer os.RemoveAll(filepath.Dir(invalid))
+
+    tests := []struct {
+        file        string
+        exitCode    int
+    }{
+        {invalid, whisk.EXITCODE_ERR_GENERAL},
+        {filepath.Join(filepath.Dir(invalid), "missing.yaml"), whisk.EXITCODE_ERR_USAGE},
+    }
+
+    for _, test := range tests {
+        server := newTestServer(t, entityHandler())
+        err := ruleImportCmd.RunE(ruleImportCmd, []string{test.file})
+        checkRequests(t, server)
+        server.Close()
+
+        if whiskErr, ok := err.(*whisk.WskError); !ok || whiskErr.ExitCode != test.exitCode {
+            t.Errorf("rule import %s error = %#v, want a WskError with exit code %d", test.file, err, test.exitCode)
+        }
+    }
+}
+
+// ruleTestHandler answers the requests of rule test for the rule r of trigger t and action pkg/a. Firing t returns the
+// trigger activation trig1; the activation of r caused by it is listed when ruleActivated is set, followed by the
+// given activation of the action.
+func ruleTestHandler(rule *whisk.Rule, ruleActivated bool, actionActivation whisk.Activation) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        path := strings.TrimPrefix(r.URL.Path, "/api/v1/namespaces/")
+        switch {
+        case path == "ns/rules/r" && rule == nil:
+            writeJSON(w, http.StatusNotFound, map[string]interface{}{"error": "The requested resource does not exist.", "code": 1})
+        case path == "ns/rules/r":
+            writeJSON(w, http.StatusOK, rule)
+        case path == "ns/triggers/t":
+            writeJSON(w, http.StatusOK, map[string]interface{}{"activationId": "trig1"})
+        case strings.HasSuffix(path, "/activations") && r.URL.Query().Get("name") == "r" && ruleActivated:
+            writeJSON(w, http.StatusOK, []whisk.Activation{{Name: "r", ActivationID: "rule1", Cause: "trig1", Start: 1000}})
+        case strings.HasSuffix(path, "/activations") && r.URL.Query().Get("name") == "a" && r.URL.Query().Get("since") == "1000":
+            writeJSON(w, http.StatusOK, []whisk.Activation{actionActivation})
+        default:
+            writeJSON(w, http.StatusOK, []interface{}{})
+        }
+    }
+}
+
+func TestRuleTest(t *testing.T) {
+    active := &whisk.Rule{Namespace: "ns", Name: "r", Status: "active", Trigger: "/ns/t", Action: "/ns/pkg/a"}
+    inactive := &whisk.Rule{Namespace: "ns", Name: "r", Status: "inactive", Trigger: "/ns/t", Action: "/ns/pkg/a"}
+    succeeded := whisk.Activation{Name: "a", ActivationID: "act1", Start: 1001,
+        Response: whisk.Response{Status: "success", Success: true, Result: &whisk.Result{"done": true}}}
+    failed := whisk.Activation{Name: "a", ActivationID: "act1", Start: 1001,
+        Response: whisk.Response{Status: "application error", Result: &whisk.Result{"error": "boom"}}}
+
+    tests := []struct {
+        rule            *whisk.Rule
+        ruleActivated   bool
+        activation      whisk.Activation
+        payload         string      // the content of the --payload file, when there is one
+        output          string
+        warning         string
+        err             string      // the start of the error message, when rule test fails
+    }{
+        {active, true, succeeded, `{"x": 1}`,
+            "ok: triggered /ns/t with id trig1\nok: rule r produced activation act1\n{\n    \"done\": true\n}\n", "", ""},
+        // An inactive rule is only a warning
+        {inactive, true, succeeded, "",
+            "ok: triggered /ns/t with id trig1\nok: rule r produced activation act1\n{\n    \"done\": true\n}\n",
+            "warning: rule r is inactive", ""},
+        // A failed activation is printed and reported as an application error
+        {active, true, failed, "",
+            "ok: triggered /ns/t with id trig1\nok: rule r produced activation act1\n{\n    \"error\": \"boom\"\n}\n", "", "boom"},
+        {active, false, succeeded, "", "ok: triggered /ns/t with id trig1\n", "",
+            "Rule 'r' was not activated by trigger activation trig1 within 0s"},
+        {nil, false, succeeded, "", "", "", "Unable to get rule 'r'"},
+        {active, true, succeeded, `{"x": `, "", "", "Invalid payload file"},
+    }
+
+    for i, test := range tests {
+        server := newTestServer(t, ruleTestHandler(test.rule, test.ruleActivated, test.activation))
+        flags.rule.timeout = 0
+        if len(test.payload) > 0 {
+            flags.rule.payload = writeTestFile(t, "payload.json", test.payload)
+        }
+
+        var err error
+        var output string
+        stderr := captureStderr(t, func() {
+            output = captureOutput(t, func() { err = ruleTestCmd.RunE(ruleTestCmd, []string{"/ns/r"}) })
+        })
+        fired := server.getRequest("POST", "ns/triggers/t")
+        server.Close()
+        if len(flags.rule.payload) > 0 {
+            os.RemoveAll(filepath.Dir(flags.rule.payload))
+        }
+
+        if len(test.err) == 0 && err != nil {
+            t.Errorf("test %d: rule test failed: %s", i, err)
+        } else if len(test.err) > 0 && (err == nil || !strings.Contains(err.Error(), test.err)) {
+            t.Errorf("test %d: rule test error = %v, want %q", i, err, test.err)
+        }
+        if output != test.output {
+            t.Errorf("test %d: rule test printed %q, want %q", i, output, test.output)
+        }
+        if !strings.Contains(stderr, test.warning) || (len(test.warning) == 0 && len(stderr) > 0) {
+            t.Errorf("test %d: rule test warned %q, want %q", i, stderr, test.warning)
+        }
+
+        // The trigger is fired with the payload of the file
+        if fired != nil && len(test.payload) > 0 && strings.TrimSpace(fired.Body) != `{"x":1}` {
+            t.Errorf("test %d: rule test fired the trigger with %s, want the payload of the file", i, fired.Body)
+        }
+        if fired == nil && len(test.output) > 0 {
+            t.Errorf("test %d: rule test did not fire the trigger; requests: %q", i, server.getRequests())
+        }
+    }
+}
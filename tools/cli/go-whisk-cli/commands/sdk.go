@@ -17,13 +17,19 @@
 package commands
 
 import (
+    "crypto/sha256"
+    "encoding/hex"
     "errors"
     "fmt"
     "io"
+    "io/ioutil"
+    "net/http"
     "os"
     "strings"
+    "time"
 
     "github.com/spf13/cobra"
+    "github.com/mattn/go-isatty"
 
     "../../go-whisk/whisk"
     "../wski18n"
@@ -51,6 +57,22 @@ const SDK_DOCKER_COMPONENT_NAME string = "docker"
 const SDK_IOS_COMPONENT_NAME string = "ios"
 const BASH_AUTOCOMPLETE_FILENAME string = "wsk_cli_bash_completion.sh"
 
+// Number of requests made for an SDK artifact before its download fails; each retry resumes where the last stopped
+const SDK_DOWNLOAD_ATTEMPTS = 3
+
+// Time between updates of the download progress on a terminal
+const SDK_PROGRESS_INTERVAL = 250 * time.Millisecond
+
+// downloadProgress reports the progress of a download on standard error. On a terminal the number of bytes, and the
+// percentage when the size is known, is updated in place; otherwise only the completed download is reported.
+type downloadProgress struct {
+    name        string
+    size        int64       // size of the download in bytes, or -1 when unknown
+    written     int64
+    reported    time.Time   // time of the last update on the terminal
+    terminal    bool
+}
+
 var sdkInstallCmd = &cobra.Command{
     Use:   "install COMPONENT",
     Short: wski18n.T("install SDK artifacts"),
@@ -149,46 +171,10 @@ func sdkInstall(componentName string) error {
         return werr
     }
 
-    resp, err := client.Sdks.Install(sdkMap[componentName].UrlPath)
-    if err != nil {
-        whisk.Debug(whisk.DbgError, "client.Sdks.Install(%s) failed: %s\n", sdkMap[componentName].UrlPath, err)
-        errStr := wski18n.T("Unable to retrieve '{{.urlpath}}' SDK: {{.err}}",
-                map[string]interface{}{"urlpath": sdkMap[componentName].UrlPath, "err": err})
-        werr := whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
-        return werr
-    }
-
-    if resp.Body == nil {
-        whisk.Debug(whisk.DbgError, "SDK Install HTTP response has no body\n")
-        errStr := wski18n.T("Server failed to send the '{{.component}}' SDK: {{.err}}",
-                map[string]interface{}{"name": componentName, "err": err})
-        werr := whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_NETWORK, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
-        return werr
-    }
-
-    // Create the SDK file
-    sdkfile, err := os.Create(targetFile)
-    if err != nil {
-        whisk.Debug(whisk.DbgError, "os.Create(%s) failure: %s\n", targetFile, err)
-        errStr := wski18n.T("Error creating SDK file {{.name}}: {{.err}}",
-                map[string]interface{}{"name": targetFile, "err": err})
-        werr := whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
-        return werr
-    }
-
-    // Read the HTTP response body and write it to the SDK file
-    whisk.Debug(whisk.DbgInfo, "Reading SDK file from HTTP response body\n")
-    _, err = io.Copy(sdkfile, resp.Body)
+    err := downloadSdk(sdkMap[componentName].UrlPath, targetFile)
     if err != nil {
-        whisk.Debug(whisk.DbgError, "io.Copy() of resp.Body into sdkfile failure: %s\n", err)
-        errStr := wski18n.T("Error copying server response into file: {{.err}}",
-                map[string]interface{}{"err": err})
-        werr := whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
-        sdkfile.Close()
-        return werr
-
+        return err
     }
-    sdkfile.Close()     // Don't use 'defer' since this file might need to be deleted after unpack
 
     // At this point, the entire file is downloaded from the server
     // Check if there is any special post-download processing (i.e. unpack)
@@ -236,6 +222,261 @@ func sdkInstall(componentName string) error {
     return nil
 }
 
+// downloadSdk downloads an SDK artifact into targetFile. An interrupted download is resumed, up to
+// SDK_DOWNLOAD_ATTEMPTS requests in all. When the API host publishes a SHA-256 checksum of the artifact, the download
+// must match it. targetFile is removed when the download fails.
+func downloadSdk(urlPath string, targetFile string) error {
+    checksum := getSdkChecksum(urlPath)
+
+    sdkfile, err := os.Create(targetFile)
+    if err != nil {
+        whisk.Debug(whisk.DbgError, "os.Create(%s) failure: %s\n", targetFile, err)
+        errStr := wski18n.T("Error creating SDK file {{.name}}: {{.err}}",
+                map[string]interface{}{"name": targetFile, "err": err})
+        werr := whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+        return werr
+    }
+
+    err = downloadSdkFile(urlPath, sdkfile)
+    sdkfile.Close()
+
+    if err == nil && len(checksum) > 0 {
+        err = verifySdkChecksum(targetFile, checksum)
+    }
+
+    if err != nil {
+        whisk.Debug(whisk.DbgInfo, "Removing incomplete SDK file '%s'\n", targetFile)
+        os.Remove(targetFile)
+        return err
+    }
+
+    return nil
+}
+
+// downloadSdkFile writes an SDK artifact to sdkfile. A failed request is retried from the bytes already written.
+func downloadSdkFile(urlPath string, sdkfile *os.File) error {
+    var written int64
+    progress := &downloadProgress{name: sdkfile.Name(), size: -1, terminal: isatty.IsTerminal(os.Stderr.Fd())}
+
+    for attempt := 1; ; attempt++ {
+        var retry bool
+        var err error
+
+        if written, retry, err = downloadSdkPart(urlPath, sdkfile, written, progress); err == nil {
+            progress.done()
+            return nil
+        }
+
+        progress.interrupt()
+        if !retry || attempt >= SDK_DOWNLOAD_ATTEMPTS {
+            return err
+        }
+
+        whisk.Debug(whisk.DbgInfo, "Resuming the download of '%s' after %d bytes: %s\n", urlPath, written, err)
+        time.Sleep(time.Duration(attempt) * time.Second)
+    }
+}
+
+// downloadSdkPart requests an SDK artifact from offset on and writes it to sdkfile, which holds the first offset
+// bytes. It returns the number of bytes of the artifact in sdkfile, and whether a failure may be retried.
+func downloadSdkPart(urlPath string, sdkfile *os.File, offset int64, progress *downloadProgress) (int64, bool, error) {
+    resp, err := client.Sdks.Download(urlPath, offset)
+    if err != nil {
+        whisk.Debug(whisk.DbgError, "client.Sdks.Download(%s, %d) failed: %s\n", urlPath, offset, err)
+        errStr := wski18n.T("Unable to retrieve '{{.urlpath}}' SDK: {{.err}}",
+                map[string]interface{}{"urlpath": urlPath, "err": err})
+        werr := whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_NETWORK, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+        return offset, true, werr
+    }
+    defer resp.Body.Close()
+
+    switch resp.StatusCode {
+    case http.StatusPartialContent:
+        // Only a part that starts where the file ends can be appended; otherwise the download starts over
+        if start, ok := getContentRangeStart(resp.Header.Get("Content-Range")); !ok || start != offset {
+            whisk.Debug(whisk.DbgError, "Content-Range '%s' of '%s' does not start at %d\n",
+                resp.Header.Get("Content-Range"), urlPath, offset)
+            if err = truncateSdkFile(sdkfile); err != nil {
+                return offset, false, err
+            }
+            errStr := wski18n.T("Unable to retrieve '{{.urlpath}}' SDK: {{.err}}",
+                    map[string]interface{}{"urlpath": urlPath, "err": wski18n.T("The server did not resume the download at byte {{.offset}}",
+                        map[string]interface{}{"offset": offset})})
+            werr := whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_NETWORK, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+            return 0, true, werr
+        }
+    case http.StatusOK:
+        // The server ignored the range and sent the whole artifact, so the file is written again
+        if offset > 0 {
+            whisk.Debug(whisk.DbgInfo, "The server does not resume downloads; restarting '%s'\n", urlPath)
+            if err = truncateSdkFile(sdkfile); err != nil {
+                return offset, false, err
+            }
+            offset = 0
+        }
+    default:
+        whisk.Debug(whisk.DbgError, "SDK download of '%s' failed with status %s\n", urlPath, resp.Status)
+        errStr := wski18n.T("Unable to retrieve '{{.urlpath}}' SDK: {{.err}}",
+                map[string]interface{}{"urlpath": urlPath, "err": resp.Status})
+        werr := whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_NETWORK, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+        return offset, resp.StatusCode >= http.StatusInternalServerError, werr
+    }
+
+    // The length of a partial response is the length of the rest of the artifact
+    progress.written, progress.size = offset, -1
+    if resp.ContentLength >= 0 {
+        progress.size = offset + resp.ContentLength
+    }
+
+    whisk.Debug(whisk.DbgInfo, "Reading SDK file from HTTP response body\n")
+    written, err := io.Copy(io.MultiWriter(sdkfile, progress), resp.Body)
+    offset += written
+    if err != nil {
+        whisk.Debug(whisk.DbgError, "io.Copy() of resp.Body into sdkfile failure: %s\n", err)
+        errStr := wski18n.T("Error copying server response into file: {{.err}}",
+                map[string]interface{}{"err": err})
+        werr := whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+        return offset, true, werr
+    }
+
+    return offset, false, nil
+}
+
+// getContentRangeStart returns the first byte position of a Content-Range header of the form "bytes first-last/length"
+func getContentRangeStart(contentRange string) (int64, bool) {
+    var first, last int64
+    var length string
+
+    if n, err := fmt.Sscanf(contentRange, "bytes %d-%d/%s", &first, &last, &length); err != nil || n != 3 {
+        return 0, false
+    }
+
+    return first, true
+}
+
+// truncateSdkFile empties sdkfile so that an SDK artifact is written again from its first byte
+func truncateSdkFile(sdkfile *os.File) error {
+    _, err := sdkfile.Seek(0, io.SeekStart)
+    if err == nil {
+        err = sdkfile.Truncate(0)
+    }
+    if err != nil {
+        whisk.Debug(whisk.DbgError, "Unable to truncate '%s': %s\n", sdkfile.Name(), err)
+        errStr := wski18n.T("Error copying server response into file: {{.err}}",
+                map[string]interface{}{"err": err})
+        werr := whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+        return werr
+    }
+
+    return nil
+}
+
+// getSdkChecksum returns the SHA-256 checksum that the API host publishes for an SDK artifact at its URL plus
+// ".sha256", in the format written by sha256sum, or "" when there is none
+func getSdkChecksum(urlPath string) string {
+    checksumPath := urlPath + ".sha256"
+
+    resp, err := client.Sdks.Download(checksumPath, 0)
+    if err != nil {
+        whisk.Debug(whisk.DbgInfo, "No checksum for '%s': %s\n", urlPath, err)
+        return ""
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode != http.StatusOK {
+        whisk.Debug(whisk.DbgInfo, "No checksum for '%s': %s\n", urlPath, resp.Status)
+        return ""
+    }
+
+    // Only the first field, the hexadecimal checksum, is needed
+    data, err := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+    fields := strings.Fields(string(data))
+    if err != nil || len(fields) == 0 || len(fields[0]) != hex.EncodedLen(sha256.Size) {
+        whisk.Debug(whisk.DbgInfo, "'%s' does not contain a SHA-256 checksum: %v\n", checksumPath, err)
+        return ""
+    }
+
+    if _, err = hex.DecodeString(fields[0]); err != nil {
+        whisk.Debug(whisk.DbgInfo, "'%s' does not contain a SHA-256 checksum: %s\n", checksumPath, err)
+        return ""
+    }
+
+    return strings.ToLower(fields[0])
+}
+
+// verifySdkChecksum checks that the SHA-256 checksum of a downloaded SDK file is checksum
+func verifySdkChecksum(filename string, checksum string) error {
+    sdkfile, err := os.Open(filename)
+    if err != nil {
+        whisk.Debug(whisk.DbgError, "os.Open(%s) failed: %s\n", filename, err)
+        errStr := wski18n.T("Unable to verify the checksum of {{.name}}: {{.err}}",
+                map[string]interface{}{"name": filename, "err": err})
+        werr := whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+        return werr
+    }
+    defer sdkfile.Close()
+
+    hash := sha256.New()
+    if _, err = io.Copy(hash, sdkfile); err != nil {
+        whisk.Debug(whisk.DbgError, "Reading '%s' failed: %s\n", filename, err)
+        errStr := wski18n.T("Unable to verify the checksum of {{.name}}: {{.err}}",
+                map[string]interface{}{"name": filename, "err": err})
+        werr := whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+        return werr
+    }
+
+    if actual := hex.EncodeToString(hash.Sum(nil)); actual != checksum {
+        whisk.Debug(whisk.DbgError, "SHA-256 checksum of '%s' is %s, expected %s\n", filename, actual, checksum)
+        errStr := wski18n.T("The SHA-256 checksum of {{.name}} is {{.actual}} instead of the published {{.expected}}",
+                map[string]interface{}{"name": filename, "actual": actual, "expected": checksum})
+        werr := whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+        return werr
+    }
+
+    whisk.Debug(whisk.DbgInfo, "SHA-256 checksum of '%s' verified\n", filename)
+    return nil
+}
+
+// Write counts the bytes written to the download, and updates the progress on a terminal
+func (p *downloadProgress) Write(data []byte) (int, error) {
+    p.written += int64(len(data))
+
+    if p.terminal && time.Since(p.reported) >= SDK_PROGRESS_INTERVAL {
+        p.reported = time.Now()
+        fmt.Fprintf(os.Stderr, "\r%s", p.String())
+    }
+
+    return len(data), nil
+}
+
+// done reports the completed download
+func (p *downloadProgress) done() {
+    if p.terminal {
+        fmt.Fprintf(os.Stderr, "\r%s\n", p.String())
+    } else {
+        fmt.Fprintln(os.Stderr, p.String())
+    }
+}
+
+// interrupt ends the progress line on a terminal, so that later messages start on a line of their own
+func (p *downloadProgress) interrupt() {
+    if p.terminal && !p.reported.IsZero() {
+        fmt.Fprintln(os.Stderr)
+        p.reported = time.Time{}
+    }
+}
+
+func (p *downloadProgress) String() string {
+    if p.size > 0 {
+        return wski18n.T("Downloading {{.name}}: {{.written}} of {{.size}} bytes ({{.percent}}%)",
+            map[string]interface{}{"name": p.name, "written": p.written, "size": p.size,
+                "percent": p.written * 100 / p.size})
+    }
+
+    return wski18n.T("Downloading {{.name}}: {{.written}} bytes",
+        map[string]interface{}{"name": p.name, "written": p.written})
+}
+
 func init() {
     sdkCmd.AddCommand(sdkInstallCmd)
 
@@ -0,0 +1,274 @@
+/*
+ * Copyright 2015-2016 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+    "archive/tar"
+    "crypto/sha256"
+    "encoding/hex"
+    "fmt"
+    "io/ioutil"
+    "net/http"
+    "os"
+    "path/filepath"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+)
+
+const testSdkPath = "sdk/test.tar.gz"
+
+var testSdkContent = strings.Repeat("OpenWhisk SDK ", 1000)
+
+// sdkTestHandler serves testSdkContent at testSdkPath, with checksum at its ".sha256" path when it is not empty. The
+// first stalled requests send only half of the artifact and then stop sending data. Requests with a Range header are
+// answered with the rest of the artifact and a Content-Range that starts at rangeStart(offset).
+type sdkTestHandler struct {
+    checksum    string
+    stalled     int
+    rangeStart  func(offset int64) int64
+    mutex       sync.Mutex
+    ranges      []string
+}
+
+func (h *sdkTestHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    switch r.URL.Path {
+    case "/" + testSdkPath + ".sha256":
+        if len(h.checksum) == 0 {
+            http.NotFound(w, r)
+            return
+        }
+        fmt.Fprintf(w, "%s  test.tar.gz\n", h.checksum)
+    case "/" + testSdkPath:
+        h.mutex.Lock()
+        h.ranges = append(h.ranges, r.Header.Get("Range"))
+        stall := h.stalled > 0
+        h.stalled--
+        h.mutex.Unlock()
+
+        var offset int64
+        if _, err := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-", &offset); err == nil {
+            start := h.rangeStart(offset)
+            w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(testSdkContent) - 1, len(testSdkContent)))
+            w.WriteHeader(http.StatusPartialContent)
+            fmt.Fprint(w, testSdkContent[start:])
+            return
+        }
+
+        w.Header().Set("Content-Length", fmt.Sprint(len(testSdkContent)))
+        if !stall {
+            fmt.Fprint(w, testSdkContent)
+            return
+        }
+
+        fmt.Fprint(w, testSdkContent[:len(testSdkContent) / 2])
+        w.(http.Flusher).Flush()
+        select {
+        case <-r.Context().Done():
+        case <-time.After(5 * time.Second):
+        }
+    default:
+        http.NotFound(w, r)
+    }
+}
+
+func (h *sdkTestHandler) getRanges() []string {
+    h.mutex.Lock()
+    defer h.mutex.Unlock()
+
+    return append([]string(nil), h.ranges...)
+}
+
+// downloadTestSdk downloads the SDK artifact served by handler into a temporary directory, and returns the path of
+// the file and the error of downloadSdk
+func downloadTestSdk(t *testing.T, handler *sdkTestHandler) (string, error) {
+    server := newTLSTestServer(t, handler.ServeHTTP)
+    defer server.Close()
+    client.Config.DownloadIdleTimeout = 100 * time.Millisecond
+
+    dir, err := ioutil.TempDir("", "sdk")
+    if err != nil {
+        t.Fatalf("ioutil.TempDir() failed: %s", err)
+    }
+
+    targetFile := filepath.Join(dir, "test.tar.gz")
+    return targetFile, downloadSdk(testSdkPath, targetFile)
+}
+
+func getTestSdkChecksum() string {
+    sum := sha256.Sum256([]byte(testSdkContent))
+    return hex.EncodeToString(sum[:])
+}
+
+func checkSdkFile(t *testing.T, targetFile string) {
+    if data, err := ioutil.ReadFile(targetFile); err != nil {
+        t.Errorf("ioutil.ReadFile(%s) failed: %s", targetFile, err)
+    } else if string(data) != testSdkContent {
+        t.Errorf("downloaded %d bytes that differ from the %d bytes of the SDK", len(data), len(testSdkContent))
+    }
+}
+
+func TestDownloadSdkChecksum(t *testing.T) {
+    handler := &sdkTestHandler{checksum: getTestSdkChecksum()}
+    targetFile, err := downloadTestSdk(t, handler)
+    defer os.RemoveAll(filepath.Dir(targetFile))
+
+    if err != nil {
+        t.Fatalf("downloadSdk() failed: %s", err)
+    }
+    checkSdkFile(t, targetFile)
+}
+
+func TestDownloadSdkChecksumMismatch(t *testing.T) {
+    handler := &sdkTestHandler{checksum: strings.Repeat("0", 64)}
+    targetFile, err := downloadTestSdk(t, handler)
+    defer os.RemoveAll(filepath.Dir(targetFile))
+
+    if err == nil || !strings.Contains(err.Error(), "checksum") {
+        t.Errorf("downloadSdk() error = %v, want a checksum mismatch", err)
+    }
+    if _, err = os.Stat(targetFile); !os.IsNotExist(err) {
+        t.Errorf("%s was not removed after the checksum mismatch", targetFile)
+    }
+}
+
+func TestDownloadSdkResumesStalledDownload(t *testing.T) {
+    handler := &sdkTestHandler{
+        checksum: getTestSdkChecksum(),
+        stalled: 1,
+        rangeStart: func(offset int64) int64 { return offset },
+    }
+    targetFile, err := downloadTestSdk(t, handler)
+    defer os.RemoveAll(filepath.Dir(targetFile))
+
+    if err != nil {
+        t.Fatalf("downloadSdk() failed: %s", err)
+    }
+    checkSdkFile(t, targetFile)
+
+    want := []string{"", fmt.Sprintf("bytes=%d-", len(testSdkContent) / 2)}
+    if ranges := handler.getRanges(); fmt.Sprint(ranges) != fmt.Sprint(want) {
+        t.Errorf("Range headers = %q, want %q", ranges, want)
+    }
+}
+
+func TestDownloadSdkRestartsOnContentRangeMismatch(t *testing.T) {
+    handler := &sdkTestHandler{
+        checksum: getTestSdkChecksum(),
+        stalled: 1,
+        rangeStart: func(offset int64) int64 { return offset - 10 },
+    }
+    targetFile, err := downloadTestSdk(t, handler)
+    defer os.RemoveAll(filepath.Dir(targetFile))
+
+    if err != nil {
+        t.Fatalf("downloadSdk() failed: %s", err)
+    }
+    checkSdkFile(t, targetFile)
+
+    want := []string{"", fmt.Sprintf("bytes=%d-", len(testSdkContent) / 2), ""}
+    if ranges := handler.getRanges(); fmt.Sprint(ranges) != fmt.Sprint(want) {
+        t.Errorf("Range headers = %q, want %q", ranges, want)
+    }
+}
+
+func TestIsSafeArchivePath(t *testing.T) {
+    tests := []struct {
+        name string
+        safe bool
+    }{
+        {"file", true},
+        {"dir/file", true},
+        {"./dir/file", true},
+        {"dir/../file", false},
+        {"..", false},
+        {"../file", false},
+        {"dir/../../file", false},
+        {"..\\file", false},
+        {"/etc/passwd", false},
+        {"\\file", false},
+        {"..file", true},
+    }
+
+    for _, test := range tests {
+        if safe := isSafeArchivePath(test.name); safe != test.safe {
+            t.Errorf("isSafeArchivePath(%q) = %t, want %t", test.name, safe, test.safe)
+        }
+    }
+}
+
+func TestUnpackTarRejectsPathTraversal(t *testing.T) {
+    dir, err := ioutil.TempDir("", "sdk")
+    if err != nil {
+        t.Fatalf("ioutil.TempDir() failed: %s", err)
+    }
+    defer os.RemoveAll(dir)
+
+    unpackDir := filepath.Join(dir, "unpack")
+    if err = os.Mkdir(unpackDir, 0755); err != nil {
+        t.Fatalf("os.Mkdir() failed: %s", err)
+    }
+
+    // The safe item comes first, to check that nothing is unpacked when any item is unsafe
+    tarFile := filepath.Join(dir, "test.tar")
+    writeTestTar(t, tarFile, "safe.txt", "../outside.txt")
+
+    origDir, err := os.Getwd()
+    if err != nil {
+        t.Fatalf("os.Getwd() failed: %s", err)
+    }
+    if err = os.Chdir(unpackDir); err != nil {
+        t.Fatalf("os.Chdir() failed: %s", err)
+    }
+    defer os.Chdir(origDir)
+
+    if err = unpackTar(tarFile); err == nil || !strings.Contains(err.Error(), "../outside.txt") {
+        t.Errorf("unpackTar() error = %v, want an error naming ../outside.txt", err)
+    }
+
+    for _, name := range []string{filepath.Join(dir, "outside.txt"), filepath.Join(unpackDir, "safe.txt")} {
+        if _, err = os.Stat(name); !os.IsNotExist(err) {
+            t.Errorf("unpackTar() created %s", name)
+        }
+    }
+}
+
+// writeTestTar writes a tar file with a small file item for each name
+func writeTestTar(t *testing.T, path string, names ...string) {
+    file, err := os.Create(path)
+    if err != nil {
+        t.Fatalf("os.Create(%s) failed: %s", path, err)
+    }
+    defer file.Close()
+
+    writer := tar.NewWriter(file)
+    for _, name := range names {
+        content := "content of " + name
+        header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+        if err = writer.WriteHeader(header); err != nil {
+            t.Fatalf("writer.WriteHeader(%s) failed: %s", name, err)
+        }
+        if _, err = writer.Write([]byte(content)); err != nil {
+            t.Fatalf("writer.Write(%s) failed: %s", name, err)
+        }
+    }
+
+    if err = writer.Close(); err != nil {
+        t.Fatalf("writer.Close() failed: %s", err)
+    }
+}
@@ -0,0 +1,43 @@
+// +build !windows
+
+/*
+ * Copyright 2015-2016 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+    "os"
+    "syscall"
+    "unsafe"
+)
+
+// getTTYWidth returns the number of columns of the terminal on standard output, or 0 when it is not a terminal
+func getTTYWidth() int {
+    var size struct {
+        rows    uint16
+        cols    uint16
+        xpixel  uint16
+        ypixel  uint16
+    }
+
+    _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, os.Stdout.Fd(), uintptr(syscall.TIOCGWINSZ),
+        uintptr(unsafe.Pointer(&size)))
+    if errno != 0 {
+        return 0
+    }
+
+    return int(size.cols)
+}
@@ -19,12 +19,15 @@ package commands
 import (
     "errors"
     "fmt"
+    "net/http"
+    "time"
 
     "../../go-whisk/whisk"
     "../wski18n"
 
     "github.com/spf13/cobra"
     "github.com/fatih/color"
+    "github.com/mattn/go-colorable"
 )
 
 const FEED_LIFECYCLE_EVENT  = "lifecycleEvent"
@@ -32,6 +35,7 @@ const FEED_TRIGGER_NAME     = "triggerName"
 const FEED_AUTH_KEY         = "authKey"
 const FEED_CREATE           = "CREATE"
 const FEED_DELETE           = "DELETE"
+const FEED_UPDATE           = "UPDATE"
 
 // triggerCmd represents the trigger command
 var triggerCmd = &cobra.Command{
@@ -79,7 +83,8 @@ var triggerFireCmd = &cobra.Command{
             }
         }
 
-        trigResp, _, err := client.Triggers.Fire(qualifiedName.entityName, parameters)
+        fireTime := time.Now().UnixNano() / int64(time.Millisecond)
+        fireResp, _, err := client.Triggers.Fire(qualifiedName.entityName, parameters)
         if err != nil {
             whisk.Debug(whisk.DbgError, "client.Triggers.Fire(%s, %#v) failed: %s\n", qualifiedName.entityName, parameters, err)
             errStr := wski18n.T("Unable to fire trigger '{{.name}}': {{.err}}",
@@ -95,11 +100,104 @@ var triggerFireCmd = &cobra.Command{
                     "ok": color.GreenString("ok:"),
                     "namespace": boldString(qualifiedName.namespace),
                     "name": boldString(qualifiedName.entityName),
-                    "id": boldString(trigResp.ActivationId)}))
+                    "id": boldString(fireResp.ActivationID)}))
+
+        if flags.trigger.follow {
+            return followTriggerActivation(qualifiedName, fireResp.ActivationID,
+                time.Duration(flags.trigger.timeout) * time.Second)
+        }
+
+        if flags.trigger.result {
+            return waitForTriggerResult(qualifiedName, fireTime, time.Duration(flags.trigger.timeout) * time.Second)
+        }
+
         return nil
     },
 }
 
+// waitForTriggerResult prints the result of the first activation of an action that is started by one of the rules
+// of a trigger after the trigger was fired
+func waitForTriggerResult(qualifiedName QualifiedName, since int64, timeout time.Duration) error {
+    var actionNames []string
+
+    deadline := time.Now().Add(timeout)
+    fullTriggerName := fmt.Sprintf("/%s/%s", qualifiedName.namespace, qualifiedName.entityName)
+
+    rules, err := getTriggerRules(fullTriggerName)
+    if err != nil {
+        return err
+    }
+
+    if len(rules) == 0 {
+        fmt.Fprintf(colorable.NewColorableStderr(),
+            wski18n.T("{{.warning}} No enabled rule is associated with trigger {{.name}}\n",
+                map[string]interface{}{"warning": color.YellowString("warning:"), "name": fullTriggerName}))
+        return nil
+    }
+
+    for _, rule := range rules {
+        actionQualifiedName, err := parseQualifiedName(whisk.GetRuleEntityName(rule.Action))
+        if err != nil {
+            whisk.Debug(whisk.DbgWarn, "Ignoring rule '%s' with invalid action: %s\n", rule.Name, err)
+            continue
+        }
+        actionNames = append(actionNames, actionQualifiedName.entityName)
+    }
+
+    activation := pollFirstActivation(actionNames, since, deadline)
+    if activation == nil {
+        errStr := wski18n.T("No action activation of trigger '{{.name}}' was found within {{.timeout}}",
+                map[string]interface{}{"name": fullTriggerName, "timeout": timeout})
+        werr := whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG,
+            whisk.NO_DISPLAY_USAGE)
+        return werr
+    }
+
+    printJSON(activation.Response.Result)
+
+    return nil
+}
+
+// followTriggerActivation waits for the action activation started by each enabled rule of a trigger when the
+// trigger was fired, printing every action activation once it completes. The server records an activation of each
+// rule caused by the trigger activation; the action activation is the first one that started after it.
+func followTriggerActivation(qualifiedName QualifiedName, activationID string, timeout time.Duration) error {
+    deadline := time.Now().Add(timeout)
+    fullTriggerName := fmt.Sprintf("/%s/%s", qualifiedName.namespace, qualifiedName.entityName)
+
+    rules, err := getTriggerRules(fullTriggerName)
+    if err != nil {
+        return err
+    }
+
+    if len(rules) == 0 {
+        fmt.Fprintf(colorable.NewColorableStderr(),
+            wski18n.T("{{.warning}} No enabled rule is associated with trigger {{.name}}\n",
+                map[string]interface{}{"warning": color.YellowString("warning:"), "name": fullTriggerName}))
+        return nil
+    }
+
+    for _, rule := range rules {
+        fullActionName := whisk.GetRuleEntityName(rule.Action)
+
+        activation, err := pollRuleActivation(rule.Name, fullActionName, activationID, deadline, timeout)
+        if err != nil {
+            return err
+        }
+
+        fmt.Fprintf(color.Output,
+            wski18n.T("{{.ok}} rule {{.rule}} invoked {{.action}} with id {{.id}}\n",
+                map[string]interface{}{
+                    "ok": color.GreenString("ok:"),
+                    "rule": boldString(rule.Name),
+                    "action": boldString(fullActionName),
+                    "id": boldString(activation.ActivationID)}))
+        printJSON(activation.Response)
+    }
+
+    return nil
+}
+
 var triggerCreateCmd = &cobra.Command{
     Use:   "create TRIGGER_NAME",
     Short: wski18n.T("create new trigger"),
@@ -123,21 +221,13 @@ var triggerCreateCmd = &cobra.Command{
 
         client.Namespace = qualifiedName.namespace
 
-        var fullTriggerName string
         var fullFeedName string
-        var feedQualifiedName QualifiedName
         if feedArgPassed {
             whisk.Debug(whisk.DbgInfo, "Trigger has a feed\n")
 
-            if feedQualifiedName, err = parseQualifiedName(flags.common.feed); err != nil {
+            if fullFeedName, err = getFullFeedName(flags.common.feed); err != nil {
                 return parseQualifiedNameError(flags.common.feed, err)
             }
-
-            fullFeedName = fmt.Sprintf("/%s/%s", feedQualifiedName.namespace, feedQualifiedName.entityName)
-            fullTriggerName = fmt.Sprintf("/%s/%s", qualifiedName.namespace, qualifiedName.entityName)
-            flags.common.param = append(flags.common.param, getFormattedJSON(FEED_LIFECYCLE_EVENT, FEED_CREATE))
-            flags.common.param = append(flags.common.param, getFormattedJSON(FEED_TRIGGER_NAME, fullTriggerName))
-            flags.common.param = append(flags.common.param, getFormattedJSON(FEED_AUTH_KEY, client.Config.AuthToken))
         }
 
 
@@ -191,21 +281,16 @@ var triggerCreateCmd = &cobra.Command{
 
         // Invoke the specified feed action to configure the trigger feed
         if feedArgPassed {
-            err := configureFeed(trigger.Name, fullFeedName)
+            result, err := createTriggerFeed(qualifiedName, fullFeedName, flags.common.param)
             if err != nil {
-                whisk.Debug(whisk.DbgError, "configureFeed(%s, %s) failed: %s\n", trigger.Name, flags.common.feed,
-                    err)
-                errStr := wski18n.T("Unable to create trigger '{{.name}}': {{.err}}",
-                        map[string]interface{}{"name": trigger.Name, "err": err})
-                werr := whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
-
-                // Delete trigger that was created for this feed
-                delerr := deleteTrigger(args[0])
-                if delerr != nil {
-                    whisk.Debug(whisk.DbgWarn, "Ignoring deleteTrigger(%s) failure: %s\n", args[0], delerr)
-                }
-                return werr
+                return err
             }
+
+            fmt.Fprintf(color.Output,
+                wski18n.T("{{.ok}} created trigger feed {{.name}}\n",
+                    map[string]interface{}{"ok": color.GreenString("ok:"), "name": boldString(trigger.Name)}))
+            printJSON(result)
+            return nil
         }
 
         fmt.Fprintf(color.Output,
@@ -223,6 +308,7 @@ var triggerUpdateCmd = &cobra.Command{
     PreRunE: setupClientConfig,
     RunE: func(cmd *cobra.Command, args []string) error {
         var err error
+        var feedArgPassed bool = (flags.common.feed != "")
         var qualifiedName QualifiedName
 
         if whiskErr := checkArgs(args, 1, 1, "Trigger update",
@@ -234,14 +320,42 @@ var triggerUpdateCmd = &cobra.Command{
             return parseQualifiedNameError(args[0], err)
         }
 
+        if feedArgPassed && len(flags.common.delParam) > 0 {
+            return nonNestedError(wski18n.T("The --del-param option cannot be used with --feed, whose parameters are passed to the feed action."))
+        }
+
+        if err = checkDeletedKeys(flags.common.param, flags.common.delParam, "--del-param"); err != nil {
+            return err
+        }
+
+        if err = checkDeletedKeys(flags.common.annotation, flags.common.delAnnotation, "--del-annotation"); err != nil {
+            return err
+        }
+
         client.Namespace = qualifiedName.namespace
 
+        var fullFeedName string
+        var previousFeedName string
+        if feedArgPassed {
+            if fullFeedName, err = getFullFeedName(flags.common.feed); err != nil {
+                return parseQualifiedNameError(flags.common.feed, err)
+            }
+
+            // The feed of the existing trigger decides whether the feed is updated or replaced
+            if existingTrigger, _, err := client.Triggers.Get(qualifiedName.entityName); err == nil &&
+                    existingTrigger.Annotations != nil {
+                previousFeedName = getValueString(existingTrigger.Annotations, "feed")
+            }
+
+            flags.common.annotation = append(flags.common.annotation, getFormattedJSON("feed", flags.common.feed))
+        }
+
         // Convert the trigger's list of default parameters from a string into []KeyValue
         // The 1 or more --param arguments have all been combined into a single []string
         // e.g.   --p arg1,arg2 --p arg3,arg4   ->  [arg1, arg2, arg3, arg4]
 
         whisk.Debug(whisk.DbgInfo, "Parsing parameters: %#v\n", flags.common.param)
-        parameters, err := getJSONFromStrings(flags.common.param, true)
+        parameters, err := getJSONFromStrings(flags.common.param, !feedArgPassed)
 
         if err != nil {
             whisk.Debug(whisk.DbgError, "getJSONFromStrings(%#v, true) failed: %s\n", flags.common.param, err)
@@ -264,10 +378,20 @@ var triggerUpdateCmd = &cobra.Command{
 
         trigger := &whisk.Trigger{
             Name:        qualifiedName.entityName,
-            Parameters:  parameters.(whisk.KeyValueArr),
             Annotations: annotations.(whisk.KeyValueArr),
         }
 
+        // Parameters of a trigger with a feed are passed to the feed action instead
+        if !feedArgPassed {
+            trigger.Parameters = parameters.(whisk.KeyValueArr)
+        }
+
+        if len(flags.common.delParam) > 0 || len(flags.common.delAnnotation) > 0 {
+            if err = deleteTriggerKeyValues(trigger); err != nil {
+                return err
+            }
+        }
+
         _, _, err = client.Triggers.Insert(trigger, true)
         if err != nil {
             whisk.Debug(whisk.DbgError, "client.Triggers.Insert(%+v,true) failed: %s\n", trigger, err)
@@ -277,6 +401,17 @@ var triggerUpdateCmd = &cobra.Command{
             return werr
         }
 
+        if feedArgPassed {
+            if err = updateTriggerFeed(qualifiedName, previousFeedName, fullFeedName, flags.common.param); err != nil {
+                whisk.Debug(whisk.DbgError, "updateTriggerFeed(%s, %s, %s) failed: %s\n", trigger.Name, previousFeedName,
+                    fullFeedName, err)
+                errStr := wski18n.T("Unable to update trigger '{{.name}}': {{.err}}",
+                        map[string]interface{}{"name": trigger.Name, "err": err})
+                werr := whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+                return werr
+            }
+        }
+
         fmt.Fprintf(color.Output,
             wski18n.T("{{.ok}} updated trigger {{.name}}\n",
                 map[string]interface{}{"ok": color.GreenString("ok:"), "name": boldString(trigger.Name)}))
@@ -284,6 +419,36 @@ var triggerUpdateCmd = &cobra.Command{
     },
 }
 
+// deleteTriggerKeyValues merges the parameters and annotations of trigger over those of the existing trigger, without
+// the keys given to --del-param and --del-annotation. The server replaces all parameters or annotations of a trigger
+// with any that are sent, so the existing ones are kept by sending them along. A trigger that does not exist has
+// nothing to delete.
+func deleteTriggerKeyValues(trigger *whisk.Trigger) (error) {
+    existingTrigger, resp, err := client.Triggers.Get(trigger.Name)
+    if resp != nil && resp.StatusCode == http.StatusNotFound {
+        whisk.Debug(whisk.DbgInfo, "Trigger '%s' does not exist; there is nothing to delete\n", trigger.Name)
+        return nil
+    } else if err != nil {
+        whisk.Debug(whisk.DbgError, "client.Triggers.Get(%s) failed: %s\n", trigger.Name, err)
+        errStr := wski18n.T("Unable to get trigger '{{.name}}': {{.err}}",
+                map[string]interface{}{"name": trigger.Name, "err": err})
+        werr := whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+        return werr
+    }
+
+    if len(flags.common.delParam) > 0 {
+        trigger.Parameters = mergeAndDeleteKeyValues(existingTrigger.Parameters, trigger.Parameters,
+            flags.common.delParam, "parameter", trigger.Name)
+    }
+
+    if len(flags.common.delAnnotation) > 0 {
+        trigger.Annotations = mergeAndDeleteKeyValues(existingTrigger.Annotations, trigger.Annotations,
+            flags.common.delAnnotation, "annotation", trigger.Name)
+    }
+
+    return nil
+}
+
 var triggerGetCmd = &cobra.Command{
     Use:   "get TRIGGER_NAME [FIELD_FILTER]",
     Short: wski18n.T("get trigger"),
@@ -325,6 +490,10 @@ var triggerGetCmd = &cobra.Command{
             return werr
         }
 
+        if len(flags.global.output) > 0 {
+            return printFormatted(retTrigger, flags.global.output)
+        }
+
         if (flags.trigger.summary) {
             printSummary(retTrigger)
         } else {
@@ -353,8 +522,6 @@ var triggerDeleteCmd = &cobra.Command{
     RunE: func(cmd *cobra.Command, args []string) error {
         var err error
         var retTrigger *whisk.Trigger
-        var fullFeedName string
-        var origParams []string
         var qualifiedName QualifiedName
 
         if whiskErr := checkArgs(args, 1, 1, "Trigger delete",
@@ -377,26 +544,8 @@ var triggerDeleteCmd = &cobra.Command{
             return werr
         }
 
-        // Get full feed name from trigger delete request as it is needed to delete the feed
-        if retTrigger != nil && retTrigger.Annotations != nil {
-            fullFeedName = getValueString(retTrigger.Annotations, "feed")
-
-            if len(fullFeedName) > 0 {
-                origParams = flags.common.param
-                fullTriggerName := fmt.Sprintf("/%s/%s", qualifiedName.namespace, qualifiedName.entityName)
-                flags.common.param = append(flags.common.param, getFormattedJSON(FEED_LIFECYCLE_EVENT, FEED_DELETE))
-                flags.common.param = append(flags.common.param, getFormattedJSON(FEED_TRIGGER_NAME, fullTriggerName))
-                flags.common.param = append(flags.common.param, getFormattedJSON(FEED_AUTH_KEY, client.Config.AuthToken))
-
-                err = configureFeed(qualifiedName.entityName, fullFeedName)
-                if err != nil {
-                    whisk.Debug(whisk.DbgError, "configureFeed(%s, %s) failed: %s\n", qualifiedName.entityName, fullFeedName, err)
-                }
-
-                flags.common.param = origParams
-                client.Namespace = qualifiedName.namespace
-            }
-
+        if err = deleteTriggerFeed(qualifiedName, retTrigger, flags.trigger.forceFeed); err != nil {
+            return err
         }
 
         retTrigger, _, err = client.Triggers.Delete(qualifiedName.entityName)
@@ -404,6 +553,11 @@ var triggerDeleteCmd = &cobra.Command{
             whisk.Debug(whisk.DbgError, "client.Triggers.Delete(%s) failed: %s\n", qualifiedName.entityName, err)
             errStr := wski18n.T("Unable to delete trigger '{{.name}}': {{.err}}",
                 map[string]interface{}{"name": qualifiedName.entityName, "err": err})
+            if errorResponse := whisk.GetHttpErrorResponse(err); errorResponse != nil &&
+                    errorResponse.StatusCode == http.StatusNotFound {
+                errStr = wski18n.T("Unable to delete trigger '{{.name}}': not found",
+                    map[string]interface{}{"name": qualifiedName.entityName})
+            }
             werr := whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
             return werr
         }
@@ -447,7 +601,20 @@ var triggerListCmd = &cobra.Command{
             Skip:  flags.common.skip,
             Limit: flags.common.limit,
         }
-        triggers, _, err := client.Triggers.List(options)
+
+        var triggers []whisk.Trigger
+        var total int
+        if flags.common.all {
+            err = listAllPages(func(limit int, skip int) (int, *http.Response, error) {
+                options.Limit, options.Skip = limit, skip
+                page, _, resp, err := client.Triggers.List(options)
+                triggers = append(triggers, page...)
+                return len(page), resp, err
+            })
+        } else {
+            triggers, total, _, err = client.Triggers.List(options)
+        }
+
         if err != nil {
             whisk.Debug(whisk.DbgError, "client.Triggers.List(%#v) for namespace '%s' failed: %s\n", options,
                 client.Namespace, err)
@@ -456,37 +623,160 @@ var triggerListCmd = &cobra.Command{
             werr := whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
             return werr
         }
-        printList(triggers)
+
+        if flags.trigger.nameSort {
+            sortTriggers(triggers)
+        }
+
+        if err = printList(triggers); err != nil {
+            return err
+        }
+
+        printListTotal(len(triggers), total, "triggers")
+
         return nil
     },
 }
 
-func configureFeed(triggerName string, FullFeedName string) error {
-    feedArgs := []string {FullFeedName}
-    flags.common.blocking = true
-    err := actionInvokeCmd.RunE(nil, feedArgs)
+// invokeFeed invokes the feed action of a trigger for a lifecycle event: CREATE, UPDATE or DELETE. params are the
+// --param style JSON objects passed to the feed action, along with the event, the trigger name and the auth key. The
+// call blocks until the feed action completes and returns its result, which may describe what the feed provisioned
+// for the trigger.
+func invokeFeed(qualifiedName QualifiedName, fullFeedName string, lifecycleEvent string,
+        params []string) (map[string]interface{}, error) {
+    var feedQualifiedName QualifiedName
+    var err error
+
+    if feedQualifiedName, err = parseQualifiedName(fullFeedName); err != nil {
+        return nil, parseQualifiedNameError(fullFeedName, err)
+    }
+
+    parameters := make(map[string]interface{})
+    if len(params) > 0 {
+        var values interface{}
+        if values, err = getJSONFromStrings(params, false); err != nil {
+            return nil, getJSONFromStringsParamError(params, false, err)
+        }
+        for key, value := range values.(map[string]interface{}) {
+            parameters[key] = value
+        }
+    }
+
+    parameters[FEED_LIFECYCLE_EVENT] = lifecycleEvent
+    parameters[FEED_TRIGGER_NAME] = fmt.Sprintf("/%s/%s", qualifiedName.namespace, qualifiedName.entityName)
+    parameters[FEED_AUTH_KEY] = client.Config.AuthToken
+
+    client.Namespace = feedQualifiedName.namespace
+    result, _, _, err := client.Actions.Invoke(feedQualifiedName.entityName, parameters, true, true)
+    client.Namespace = qualifiedName.namespace
+
     if err != nil {
-        whisk.Debug(whisk.DbgError, "Invoke of action '%s' failed: %s\n", FullFeedName, err)
+        whisk.Debug(whisk.DbgError, "client.Actions.Invoke(%s) for %s failed: %s\n", fullFeedName, lifecycleEvent, err)
         errStr := wski18n.T("Unable to invoke trigger '{{.trigname}}' feed action '{{.feedname}}'; feed is not configured: {{.err}}",
-                map[string]interface{}{"trigname": triggerName, "feedname": FullFeedName, "err": err})
-        err = whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.DISPLAY_USAGE)
-    } else {
-        whisk.Debug(whisk.DbgInfo, "Successfully configured trigger feed via feed action '%s'\n", FullFeedName)
+                map[string]interface{}{"trigname": qualifiedName.entityName, "feedname": fullFeedName, "err": err})
+        // The application error of a failed feed action is not displayed, so this error is not nested in it
+        werr := whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+        return nil, werr
     }
 
-    return err
+    whisk.Debug(whisk.DbgInfo, "Successfully invoked trigger feed action '%s' for %s\n", fullFeedName, lifecycleEvent)
+
+    return result, nil
+}
+
+// createTriggerFeed configures the feed of a trigger that was just created, and returns the result of the feed action.
+// When the feed action fails, the trigger is deleted so that it is not left without its feed; the error reports both
+// failures when the trigger cannot be deleted either.
+func createTriggerFeed(qualifiedName QualifiedName, fullFeedName string,
+        params []string) (map[string]interface{}, error) {
+    result, err := invokeFeed(qualifiedName, fullFeedName, FEED_CREATE, params)
+    if err == nil {
+        return result, nil
+    }
+
+    if _, _, delerr := client.Triggers.Delete(qualifiedName.entityName); delerr != nil {
+        whisk.Debug(whisk.DbgError, "client.Triggers.Delete(%s) failed: %s\n", qualifiedName.entityName, delerr)
+        errStr := wski18n.T("Unable to create trigger '{{.name}}': {{.err}}; the trigger was created without a feed and could not be removed: {{.delerr}}",
+                map[string]interface{}{"name": qualifiedName.entityName, "err": err, "delerr": delerr})
+        werr := whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+        return nil, werr
+    }
+
+    errStr := wski18n.T("Unable to create trigger '{{.name}}': {{.err}}",
+            map[string]interface{}{"name": qualifiedName.entityName, "err": err})
+    werr := whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+    return nil, werr
+}
+
+// deleteTriggerFeed removes the feed of a trigger that is about to be deleted, if it has one. When the feed cannot be
+// removed, the trigger is only to be deleted with force, which turns the error into a warning.
+func deleteTriggerFeed(qualifiedName QualifiedName, trigger *whisk.Trigger, force bool) error {
+    if trigger == nil || trigger.Annotations == nil {
+        return nil
+    }
+
+    fullFeedName := getValueString(trigger.Annotations, "feed")
+    if len(fullFeedName) == 0 {
+        return nil
+    }
+
+    _, err := invokeFeed(qualifiedName, fullFeedName, FEED_DELETE, nil)
+    if err == nil {
+        return nil
+    }
+
+    whisk.Debug(whisk.DbgError, "invokeFeed(%s, %s, %s) failed: %s\n", qualifiedName.entityName, fullFeedName, FEED_DELETE, err)
+    if !force {
+        errStr := wski18n.T("Unable to delete trigger '{{.name}}' because its feed '{{.feed}}' could not be removed; use --force-feed to delete the trigger anyway: {{.err}}",
+            map[string]interface{}{"name": qualifiedName.entityName, "feed": fullFeedName, "err": err})
+        werr := whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+        return werr
+    }
+
+    fmt.Fprintf(colorable.NewColorableStderr(),
+        wski18n.T("{{.warning}} Unable to remove feed '{{.feed}}' of trigger '{{.name}}'; deleting the trigger anyway: {{.err}}\n",
+            map[string]interface{}{"warning": color.YellowString("warning:"), "feed": fullFeedName,
+                "name": qualifiedName.entityName, "err": err}))
+    return nil
 }
 
-func deleteTrigger(triggerName string) error {
-    args := []string {triggerName}
-    err := triggerDeleteCmd.RunE(nil, args)
+// getFullFeedName returns the feed action name in /NAMESPACE/[PACKAGE/]ACTION form
+func getFullFeedName(feedName string) (string, error) {
+    feedQualifiedName, err := parseQualifiedName(feedName)
     if err != nil {
-        whisk.Debug(whisk.DbgError, "Trigger '%s' delete failed: %s\n", triggerName, err)
-        errStr := wski18n.T("Unable to delete trigger '{{.name}}': {{.err}}",
-                map[string]interface{}{"name": triggerName, "err": err})
-        err = whisk.MakeWskErrorFromWskError(errors.New(errStr), err, whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.DISPLAY_USAGE)
+        return "", err
+    }
+
+    return fmt.Sprintf("/%s/%s", feedQualifiedName.namespace, feedQualifiedName.entityName), nil
+}
+
+// updateTriggerFeed invokes the feed actions of a trigger whose feed was given to trigger update. An unchanged feed
+// handles an UPDATE event with params, the --param style JSON objects for the feed. Otherwise the previous feed, if
+// any, is removed with a DELETE event, which only warns on failure, and the new feed handles a CREATE event.
+func updateTriggerFeed(qualifiedName QualifiedName, previousFeedName string, fullFeedName string, params []string) error {
+    var err error
+
+    if len(previousFeedName) > 0 {
+        if previousFeedName, err = getFullFeedName(previousFeedName); err != nil {
+            whisk.Debug(whisk.DbgWarn, "Ignoring invalid feed annotation '%s': %s\n", previousFeedName, err)
+        }
+    }
+
+    if previousFeedName == fullFeedName {
+        _, err = invokeFeed(qualifiedName, fullFeedName, FEED_UPDATE, params)
+        return err
     }
 
+    if len(previousFeedName) > 0 {
+        if _, err = invokeFeed(qualifiedName, previousFeedName, FEED_DELETE, nil); err != nil {
+            fmt.Fprintf(colorable.NewColorableStderr(),
+                wski18n.T("{{.warning}} Unable to remove the previous feed '{{.feed}}' of trigger '{{.name}}': {{.err}}\n",
+                    map[string]interface{}{"warning": color.YellowString("warning:"), "feed": previousFeedName,
+                        "name": qualifiedName.entityName, "err": err}))
+        }
+    }
+
+    _, err = invokeFeed(qualifiedName, fullFeedName, FEED_CREATE, params)
     return err
 }
 
@@ -494,21 +784,34 @@ func init() {
     triggerCreateCmd.Flags().StringSliceVarP(&flags.common.annotation, "annotation", "a", []string{}, wski18n.T("annotation values in `KEY VALUE` format"))
     triggerCreateCmd.Flags().StringVarP(&flags.common.annotFile, "annotation-file", "A", "", wski18n.T("`FILE` containing annotation values in JSON format"))
     triggerCreateCmd.Flags().StringSliceVarP(&flags.common.param, "param", "p", []string{}, wski18n.T("parameter values in `KEY VALUE` format"))
+    triggerCreateCmd.Flags().StringSliceVar(&flags.common.param, "param-string", []string{}, wski18n.T("parameter values in `KEY VALUE` format, sent as strings even when they are valid JSON"))
     triggerCreateCmd.Flags().StringVarP(&flags.common.paramFile, "param-file", "P", "", wski18n.T("`FILE` containing parameter values in JSON format"))
     triggerCreateCmd.Flags().StringVarP(&flags.common.feed, "feed", "f", "", wski18n.T("trigger feed `ACTION_NAME`"))
 
     triggerUpdateCmd.Flags().StringSliceVarP(&flags.common.annotation, "annotation", "a", []string{}, wski18n.T("annotation values in `KEY VALUE` format"))
     triggerUpdateCmd.Flags().StringVarP(&flags.common.annotFile, "annotation-file", "A", "", wski18n.T("`FILE` containing annotation values in JSON format"))
     triggerUpdateCmd.Flags().StringSliceVarP(&flags.common.param, "param", "p", []string{}, wski18n.T("parameter values in `KEY VALUE` format"))
+    triggerUpdateCmd.Flags().StringSliceVar(&flags.common.param, "param-string", []string{}, wski18n.T("parameter values in `KEY VALUE` format, sent as strings even when they are valid JSON"))
+    triggerUpdateCmd.Flags().StringSliceVar(&flags.common.delParam, "del-param", []string{}, wski18n.T("remove the parameter `KEY` from the existing parameters of the trigger"))
+    triggerUpdateCmd.Flags().StringSliceVar(&flags.common.delAnnotation, "del-annotation", []string{}, wski18n.T("remove the annotation `KEY` from the existing annotations of the trigger"))
     triggerUpdateCmd.Flags().StringVarP(&flags.common.paramFile, "param-file", "P", "", wski18n.T("`FILE` containing parameter values in JSON format"))
+    triggerUpdateCmd.Flags().StringVarP(&flags.common.feed, "feed", "f", "", wski18n.T("trigger feed `ACTION_NAME`"))
+
+    triggerDeleteCmd.Flags().BoolVar(&flags.trigger.forceFeed, "force-feed", false, wski18n.T("delete the trigger even if its feed cannot be removed"))
 
     triggerGetCmd.Flags().BoolVarP(&flags.trigger.summary, "summary", "s", false, wski18n.T("summarize trigger details"))
 
     triggerFireCmd.Flags().StringSliceVarP(&flags.common.param, "param", "p", []string{}, wski18n.T("parameter values in `KEY VALUE` format"))
+    triggerFireCmd.Flags().StringSliceVar(&flags.common.param, "param-string", []string{}, wski18n.T("parameter values in `KEY VALUE` format, sent as strings even when they are valid JSON"))
     triggerFireCmd.Flags().StringVarP(&flags.common.paramFile, "param-file", "P", "", wski18n.T("`FILE` containing parameter values in JSON format"))
+    triggerFireCmd.Flags().BoolVarP(&flags.trigger.result, "result", "r", false, wski18n.T("wait for the first action started by the trigger's rules and show its result"))
+    triggerFireCmd.Flags().BoolVar(&flags.trigger.follow, "follow", false, wski18n.T("wait for the actions invoked by the trigger's rules and show their results"))
+    triggerFireCmd.Flags().IntVar(&flags.trigger.timeout, "timeout", 60, wski18n.T("with --follow or --result, the number of `SECONDS` to wait for the activations"))
 
     triggerListCmd.Flags().IntVarP(&flags.common.skip, "skip", "s", 0, wski18n.T("exclude the first `SKIP` number of triggers from the result"))
     triggerListCmd.Flags().IntVarP(&flags.common.limit, "limit", "l", 30, wski18n.T("only return `LIMIT` number of triggers from the collection"))
+    triggerListCmd.Flags().BoolVar(&flags.common.all, "all", false, wski18n.T("fetch every page of triggers, ignoring --limit"))
+    triggerListCmd.Flags().BoolVarP(&flags.trigger.nameSort, "name-sort", "n", false, wski18n.T("sorts a list alphabetically by entity name; only applicable within the limit/skip returned entity block"))
 
     triggerCmd.AddCommand(
         triggerFireCmd,
@@ -0,0 +1,512 @@
+/*
+ * Copyright 2015-2016 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+    "bytes"
+    "encoding/json"
+    "io"
+    "io/ioutil"
+    "net/http"
+    "os"
+    "strings"
+    "testing"
+    "time"
+
+    "../../go-whisk/whisk"
+)
+
+const testFeedPath = "whisk.system/actions/alarms/alarm"
+
+// newFeedServer answers feed action invocations with the lifecycle event they were given, or with an application
+// error when failFeed is set, and trigger deletions with deleteStatus
+func newFeedServer(t *testing.T, failFeed bool, deleteStatus int) *testServer {
+    return newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+        switch {
+        case r.Method == "POST" && strings.HasSuffix(r.URL.Path, testFeedPath):
+            var parameters map[string]interface{}
+            json.NewDecoder(r.Body).Decode(&parameters)
+            if failFeed {
+                writeJSON(w, http.StatusBadGateway, map[string]interface{}{"error": "The action did not produce a valid response"})
+            } else {
+                writeJSON(w, http.StatusOK, map[string]interface{}{"url": "https://hook", "event": parameters[FEED_LIFECYCLE_EVENT]})
+            }
+        case r.Method == "DELETE":
+            writeJSON(w, deleteStatus, map[string]interface{}{"name": "t"})
+        default:
+            writeJSON(w, http.StatusNotFound, map[string]interface{}{"error": "not found"})
+        }
+    })
+}
+
+func TestInvokeFeedParameters(t *testing.T) {
+    server := newFeedServer(t, false, http.StatusOK)
+    defer server.Close()
+
+    qualifiedName := QualifiedName{namespace: "ns", entityName: "t"}
+    result, err := invokeFeed(qualifiedName, "/whisk.system/alarms/alarm", FEED_UPDATE, []string{`{"cron": "* * * * *"}`})
+    if err != nil {
+        t.Fatalf("invokeFeed() failed: %s", err)
+    }
+
+    if result["event"] != FEED_UPDATE {
+        t.Errorf("result = %v, want the event %s", result, FEED_UPDATE)
+    }
+
+    var parameters map[string]interface{}
+    request := server.getRequest("POST", testFeedPath)
+    if request == nil || json.Unmarshal([]byte(request.Body), &parameters) != nil {
+        t.Fatalf("the feed action was not invoked: %v", server.getRequests())
+    }
+
+    want := map[string]interface{}{
+        "cron": "* * * * *", FEED_LIFECYCLE_EVENT: FEED_UPDATE, FEED_TRIGGER_NAME: "/ns/t", FEED_AUTH_KEY: "user:key",
+    }
+    for key, value := range want {
+        if parameters[key] != value {
+            t.Errorf("feed parameter %s = %v, want %v", key, parameters[key], value)
+        }
+    }
+
+    if request.Query.Get("blocking") != "true" || request.Query.Get("result") != "true" {
+        t.Errorf("feed invoke query = %v, want a blocking result", request.Query)
+    }
+
+    if client.Namespace != "ns" {
+        t.Errorf("client.Namespace = %s after the feed invoke, want ns", client.Namespace)
+    }
+}
+
+func TestCreateTriggerFeedSuccess(t *testing.T) {
+    server := newFeedServer(t, false, http.StatusOK)
+    defer server.Close()
+
+    result, err := createTriggerFeed(QualifiedName{namespace: "ns", entityName: "t"}, "/whisk.system/alarms/alarm", nil)
+    if err != nil {
+        t.Fatalf("createTriggerFeed() failed: %s", err)
+    }
+
+    if result["url"] != "https://hook" {
+        t.Errorf("result = %v, want the feed result", result)
+    }
+
+    checkRequests(t, server, "POST " + testFeedPath)
+}
+
+func TestCreateTriggerFeedRollback(t *testing.T) {
+    server := newFeedServer(t, true, http.StatusOK)
+    defer server.Close()
+
+    _, err := createTriggerFeed(QualifiedName{namespace: "ns", entityName: "t"}, "/whisk.system/alarms/alarm", nil)
+    if err == nil {
+        t.Fatal("createTriggerFeed() succeeded with a failing feed action")
+    }
+
+    // The trigger is deleted without a DELETE event, since its feed was never configured
+    checkRequests(t, server, "POST " + testFeedPath, "DELETE ns/triggers/t")
+
+    if message := err.Error(); !strings.Contains(message, "Unable to create trigger 't'") ||
+            !strings.Contains(message, "feed is not configured") || strings.Contains(message, "could not be removed") {
+        t.Errorf("error = %q, want the feed failure only", message)
+    }
+}
+
+func TestCreateTriggerFeedRollbackFailure(t *testing.T) {
+    server := newFeedServer(t, true, http.StatusInternalServerError)
+    defer server.Close()
+
+    _, err := createTriggerFeed(QualifiedName{namespace: "ns", entityName: "t"}, "/whisk.system/alarms/alarm", nil)
+    if err == nil {
+        t.Fatal("createTriggerFeed() succeeded with a failing feed action")
+    }
+
+    checkRequests(t, server, "POST " + testFeedPath, "DELETE ns/triggers/t")
+
+    if message := err.Error(); !strings.Contains(message, "feed is not configured") ||
+            !strings.Contains(message, "the trigger was created without a feed and could not be removed") {
+        t.Errorf("error = %q, want both the feed and the delete failures", message)
+    }
+}
+
+func TestTriggerDeleteNotFound(t *testing.T) {
+    server := newTestServer(t, deleteErrorHandler(http.StatusNotFound, "The requested resource does not exist."))
+    defer server.Close()
+
+    err := triggerDeleteCmd.RunE(triggerDeleteCmd, []string{"/ns/t"})
+    checkRequests(t, server, "GET ns/triggers/t", "DELETE ns/triggers/t")
+
+    want := "Unable to delete trigger 't': not found"
+    if whiskErr, ok := err.(*whisk.WskError); !ok || whiskErr.Error() != want ||
+            whiskErr.ExitCode != whisk.EXITCODE_ERR_NOT_FOUND {
+        t.Errorf("trigger delete error = %#v, want %q with exit code %d", err, want, whisk.EXITCODE_ERR_NOT_FOUND)
+    }
+}
+
+// captureStderr runs f and returns what it wrote to standard error
+func captureStderr(t *testing.T, f func()) string {
+    reader, writer, err := os.Pipe()
+    if err != nil {
+        t.Fatalf("os.Pipe() failed: %s", err)
+    }
+
+    origStderr := os.Stderr
+    os.Stderr = writer
+    defer func() { os.Stderr = origStderr }()
+
+    output := make(chan string)
+    go func() {
+        var buffer bytes.Buffer
+        io.Copy(&buffer, reader)
+        output <- buffer.String()
+    }()
+
+    f()
+    writer.Close()
+
+    return <-output
+}
+
+// getFeedEvent returns the lifecycle event of the feed invocation received by server, or an empty string
+func getFeedEvent(server *testServer, path string) string {
+    var parameters map[string]interface{}
+    if request := server.getRequest("POST", path); request != nil && json.Unmarshal([]byte(request.Body), &parameters) == nil {
+        event, _ := parameters[FEED_LIFECYCLE_EVENT].(string)
+        return event
+    }
+
+    return ""
+}
+
+func TestDeleteTriggerFeed(t *testing.T) {
+    feedTrigger := &whisk.Trigger{Name: "t",
+        Annotations: whisk.KeyValueArr{{Key: "feed", Value: "/whisk.system/alarms/alarm"}}}
+
+    tests := []struct {
+        trigger     *whisk.Trigger
+        failFeed    bool
+        force       bool
+        err         string
+        warning     string
+    }{
+        {feedTrigger, false, false, "", ""},
+        {feedTrigger, true, false, "could not be removed; use --force-feed to delete the trigger anyway", ""},
+        {feedTrigger, true, true, "", "Unable to remove feed '/whisk.system/alarms/alarm' of trigger 't'; deleting the trigger anyway"},
+        // Triggers without a feed have nothing to remove
+        {&whisk.Trigger{Name: "t"}, true, false, "", ""},
+    }
+
+    for i, test := range tests {
+        server := newFeedServer(t, test.failFeed, http.StatusOK)
+
+        var err error
+        stderr := captureStderr(t, func() {
+            err = deleteTriggerFeed(QualifiedName{namespace: "ns", entityName: "t"}, test.trigger, test.force)
+        })
+
+        if test.trigger.Annotations == nil {
+            checkRequests(t, server)
+        } else if event := getFeedEvent(server, testFeedPath); event != FEED_DELETE {
+            t.Errorf("test %d: feed lifecycle event = %q, want %s", i, event, FEED_DELETE)
+        }
+        server.Close()
+
+        if len(test.err) == 0 && err != nil {
+            t.Errorf("test %d: deleteTriggerFeed() failed: %s", i, err)
+        } else if len(test.err) > 0 && (err == nil || !strings.Contains(err.Error(), test.err)) {
+            t.Errorf("test %d: deleteTriggerFeed() error = %v, want an error containing %q", i, err, test.err)
+        }
+        if !strings.Contains(stderr, test.warning) || (len(test.warning) == 0 && len(stderr) > 0) {
+            t.Errorf("test %d: deleteTriggerFeed() warned %q, want %q", i, stderr, test.warning)
+        }
+    }
+}
+
+func TestUpdateTriggerFeed(t *testing.T) {
+    const otherFeedPath = "whisk.system/actions/other/feed"
+
+    tests := []struct {
+        previous    string
+        failFeed    bool
+        events      map[string]string
+        err         bool
+        warning     bool
+    }{
+        // An unchanged feed is updated
+        {"/whisk.system/alarms/alarm", false, map[string]string{testFeedPath: FEED_UPDATE}, false, false},
+        {"/whisk.system/alarms/alarm", true, map[string]string{testFeedPath: FEED_UPDATE}, true, false},
+        {"", false, map[string]string{testFeedPath: FEED_CREATE}, false, false},
+        // A previous feed that cannot be removed only warns, and the new feed is still created
+        {"/whisk.system/other/feed", false,
+            map[string]string{otherFeedPath: FEED_DELETE, testFeedPath: FEED_CREATE}, false, true},
+        {"/whisk.system/other/feed", true,
+            map[string]string{otherFeedPath: FEED_DELETE, testFeedPath: FEED_CREATE}, true, true},
+    }
+
+    for _, test := range tests {
+        server := newFeedServer(t, test.failFeed, http.StatusOK)
+
+        var err error
+        stderr := captureStderr(t, func() {
+            err = updateTriggerFeed(QualifiedName{namespace: "ns", entityName: "t"}, test.previous,
+                "/whisk.system/alarms/alarm", nil)
+        })
+
+        for path, want := range test.events {
+            if event := getFeedEvent(server, path); event != want {
+                t.Errorf("previous feed %q: lifecycle event of %s = %q, want %s", test.previous, path, event, want)
+            }
+        }
+        if requests := server.getRequests(); len(requests) != len(test.events) {
+            t.Errorf("previous feed %q: requests %q, want %d feed invocations", test.previous, requests, len(test.events))
+        }
+        server.Close()
+
+        if (err != nil) != test.err {
+            t.Errorf("previous feed %q: updateTriggerFeed() error = %v, want an error: %t", test.previous, err, test.err)
+        }
+        if warned := strings.Contains(stderr, "Unable to remove the previous feed"); warned != test.warning {
+            t.Errorf("previous feed %q: updateTriggerFeed() warned %q, want a warning: %t", test.previous, stderr, test.warning)
+        }
+    }
+}
+
+// triggerResultHandler answers the fire of a trigger, the rules of ruleListHandler, and activation lists with the
+// activations of the action named by the name query parameter
+func triggerResultHandler(rules []whisk.Rule, activations map[string][]whisk.Activation) http.HandlerFunc {
+    listRules := ruleListHandler(rules)
+
+    return func(w http.ResponseWriter, r *http.Request) {
+        path := strings.TrimPrefix(r.URL.Path, "/api/v1/namespaces/")
+        switch {
+        case r.Method == "POST":
+            writeJSON(w, http.StatusAccepted, map[string]interface{}{"activationId": "11111"})
+        case strings.HasPrefix(path, "ns/rules"):
+            listRules(w, r)
+        case path == "_/activations":
+            found := activations[r.URL.Query().Get("name")]
+            if found == nil {
+                found = []whisk.Activation{}
+            }
+            writeJSON(w, http.StatusOK, found)
+        default:
+            writeJSON(w, http.StatusNotFound, map[string]interface{}{"error": "The requested resource does not exist.", "code": 1})
+        }
+    }
+}
+
+// getTestTriggerActivation returns an activation of action that started after the trigger was fired, with result
+func getTestTriggerActivation(action string, result string) whisk.Activation {
+    return whisk.Activation{Name: action, ActivationID: action + "-id", Start: time.Now().Add(time.Minute).UnixNano() / int64(time.Millisecond),
+        Response: whisk.Response{Status: "success", Success: true, Result: &whisk.Result{"from": result}}}
+}
+
+func TestTriggerFireResult(t *testing.T) {
+    rules := []whisk.Rule{
+        {Namespace: "ns", Name: "r1", Status: "active", Trigger: "/ns/t", Action: "/ns/a1"},
+        {Namespace: "ns", Name: "r2", Status: "inactive", Trigger: "/ns/t", Action: "/ns/a2"},
+        {Namespace: "ns", Name: "r3", Status: "active", Trigger: "/ns/t", Action: "/ns/pkg/a3"},
+        {Namespace: "ns", Name: "r4", Status: "active", Trigger: "/ns/other", Action: "/ns/a4"},
+    }
+
+    tests := []struct {
+        rules       []whisk.Rule
+        activations map[string][]whisk.Activation
+        want        string
+        warning     string
+        names       []string    // the actions whose activations are listed
+    }{
+        // No enabled rule of the trigger: the inactive rule and the rule of another trigger are ignored
+        {[]whisk.Rule{rules[1], rules[3]}, map[string][]whisk.Activation{"a2": {getTestTriggerActivation("a2", "a2")}},
+            "", "No enabled rule is associated with trigger /ns/t", nil},
+        {rules[:1], map[string][]whisk.Activation{"a1": {getTestTriggerActivation("a1", "a1")}},
+            "{\n    \"from\": \"a1\"\n}\n", "", []string{"a1"}},
+        // Only the rule whose action ran has an activation, which is found after the rules without one
+        {rules, map[string][]whisk.Activation{"pkg/a3": {getTestTriggerActivation("pkg/a3", "a3")}},
+            "{\n    \"from\": \"a3\"\n}\n", "", []string{"a1", "pkg/a3"}},
+    }
+
+    for i, test := range tests {
+        server := newTestServer(t, triggerResultHandler(test.rules, test.activations))
+        flags.trigger.result = true
+        flags.trigger.timeout = 5
+
+        var err error
+        var output string
+        stderr := captureStderr(t, func() {
+            output = captureOutput(t, func() { err = triggerFireCmd.RunE(triggerFireCmd, []string{"/ns/t"}) })
+        })
+
+        server.Close()
+        var names []string
+        for _, request := range server.requests {
+            if request.Path == "_/activations" {
+                names = append(names, request.Query.Get("name"))
+            }
+        }
+
+        if err != nil {
+            t.Errorf("test %d: trigger fire --result failed: %s", i, err)
+            continue
+        }
+        if want := "ok: triggered /ns/t with id 11111\n" + test.want; output != want {
+            t.Errorf("test %d: trigger fire --result printed %q, want %q", i, output, want)
+        }
+        if !strings.Contains(stderr, test.warning) || (len(test.warning) == 0 && len(stderr) > 0) {
+            t.Errorf("test %d: trigger fire --result warned %q, want %q", i, stderr, test.warning)
+        }
+        if strings.Join(names, " ") != strings.Join(test.names, " ") {
+            t.Errorf("test %d: trigger fire --result listed the activations of %q, want %q", i, names, test.names)
+        }
+    }
+}
+
+func TestTriggerFireResultTimeout(t *testing.T) {
+    rules := []whisk.Rule{{Namespace: "ns", Name: "r1", Status: "active", Trigger: "/ns/t", Action: "/ns/a1"}}
+    // The activation started before the trigger was fired
+    old := getTestTriggerActivation("a1", "a1")
+    old.Start = 1
+
+    server := newTestServer(t, triggerResultHandler(rules, map[string][]whisk.Activation{"a1": {old}}))
+    defer server.Close()
+    flags.trigger.result = true
+    flags.trigger.timeout = 1
+
+    var err error
+    captureOutput(t, func() { err = triggerFireCmd.RunE(triggerFireCmd, []string{"/ns/t"}) })
+
+    want := "No action activation of trigger '/ns/t' was found within 1s"
+    if err == nil || err.Error() != want {
+        t.Errorf("trigger fire --result error = %v, want %q", err, want)
+    }
+}
+
+// updateTestHandler answers the GET of each entity in entities, by path below the namespaces, with the entity, and of
+// any other path with a 404. It answers a PUT with the entity sent.
+func updateTestHandler(entities map[string]interface{}) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        if r.Method == "PUT" {
+            body, _ := ioutil.ReadAll(r.Body)
+            w.Header().Set("Content-Type", "application/json")
+            w.Write(body)
+        } else if entity, found := entities[strings.TrimPrefix(r.URL.Path, "/api/v1/namespaces/")]; found {
+            writeJSON(w, http.StatusOK, entity)
+        } else {
+            writeJSON(w, http.StatusNotFound, map[string]interface{}{"error": "The requested resource does not exist.", "code": 1})
+        }
+    }
+}
+
+func TestTriggerUpdateDeleteKeys(t *testing.T) {
+    existing := map[string]interface{}{"ns/triggers/t": whisk.Trigger{Namespace: "ns", Name: "t",
+        Parameters: whisk.KeyValueArr{{Key: "a", Value: 1}, {Key: "b", Value: "old"}},
+        Annotations: whisk.KeyValueArr{{Key: "owner", Value: "me"}, {Key: "team", Value: "x"}}}}
+
+    tests := []struct {
+        entities        map[string]interface{}
+        args            []string
+        delParams       []string
+        delAnnotations  []string
+        requests        []string
+        parameters      string  // the parameters sent, as JSON, or empty when they are not sent
+        annotations     string
+    }{
+        {existing, []string{"-p", "c", "3"}, []string{"a"}, nil,
+            []string{"GET ns/triggers/t", "PUT ns/triggers/t"},
+            `[{"key":"b","value":"old"},{"key":"c","value":3}]`, ``},
+        {existing, nil, nil, []string{"owner", "missing"},
+            []string{"GET ns/triggers/t", "PUT ns/triggers/t"}, ``, `[{"key":"team","value":"x"}]`},
+        // Deleting every key sends an empty array, so that the server removes them
+        {existing, []string{"-a", "team", "y"}, []string{"a", "b"}, []string{"owner"},
+            []string{"GET ns/triggers/t", "PUT ns/triggers/t"}, `[]`, `[{"key":"team","value":"y"}]`},
+        // A trigger that does not exist has nothing to delete
+        {nil, []string{"-p", "c", "3"}, []string{"a"}, nil,
+            []string{"GET ns/triggers/t", "PUT ns/triggers/t"}, `[{"key":"c","value":3}]`, ``},
+        // Without keys to delete, the existing trigger is not fetched
+        {existing, []string{"-p", "c", "3"}, nil, nil, []string{"PUT ns/triggers/t"}, `[{"key":"c","value":3}]`, ``},
+    }
+
+    for _, test := range tests {
+        server := newTestServer(t, updateTestHandler(test.entities))
+
+        var err error
+        _, flags.common.param, flags.common.annotation, err = parseArgs(append([]string{"wsk", "trigger", "update", "/ns/t"}, test.args...))
+        if err != nil {
+            t.Fatalf("parseArgs(%q) failed: %s", test.args, err)
+        }
+        flags.common.delParam, flags.common.delAnnotation = test.delParams, test.delAnnotations
+
+        captureOutput(t, func() { err = triggerUpdateCmd.RunE(triggerUpdateCmd, []string{"/ns/t"}) })
+        checkRequests(t, server, test.requests...)
+        request := server.getRequest("PUT", "ns/triggers/t")
+        server.Close()
+
+        if err != nil {
+            t.Errorf("trigger update %q --del-param %q --del-annotation %q failed: %s", test.args, test.delParams,
+                test.delAnnotations, err)
+            continue
+        }
+
+        var sent struct {
+            Parameters  json.RawMessage     `json:"parameters"`
+            Annotations json.RawMessage     `json:"annotations"`
+        }
+        json.Unmarshal([]byte(request.Body), &sent)
+        if string(sent.Parameters) != test.parameters || string(sent.Annotations) != test.annotations {
+            t.Errorf("trigger update %q --del-param %q --del-annotation %q sent %s, want the parameters %s and the annotations %s",
+                test.args, test.delParams, test.delAnnotations, request.Body, test.parameters, test.annotations)
+        }
+        if request.Query.Get("overwrite") != "true" {
+            t.Errorf("trigger update %q --del-param %q did not overwrite the trigger", test.args, test.delParams)
+        }
+    }
+}
+
+func TestTriggerUpdateDeleteKeysErrors(t *testing.T) {
+    tests := []struct {
+        args            []string
+        feed            string
+        delParams       []string
+        delAnnotations  []string
+        want            string
+    }{
+        {[]string{"-p", "a", "1"}, "", []string{"a"}, nil, "The key 'a' cannot be both set and deleted with --del-param"},
+        {[]string{"-a", "owner", "you"}, "", nil, []string{"owner"}, "The key 'owner' cannot be both set and deleted with --del-annotation"},
+        {nil, "/whisk.system/alarms/alarm", []string{"a"}, nil, "The --del-param option cannot be used with --feed"},
+    }
+
+    for _, test := range tests {
+        server := newTestServer(t, updateTestHandler(nil))
+
+        var err error
+        _, flags.common.param, flags.common.annotation, err = parseArgs(append([]string{"wsk", "trigger", "update", "/ns/t"}, test.args...))
+        if err != nil {
+            t.Fatalf("parseArgs(%q) failed: %s", test.args, err)
+        }
+        flags.common.feed = test.feed
+        flags.common.delParam, flags.common.delAnnotation = test.delParams, test.delAnnotations
+
+        err = triggerUpdateCmd.RunE(triggerUpdateCmd, []string{"/ns/t"})
+        // Conflicts are found before any request
+        checkRequests(t, server)
+        server.Close()
+
+        if err == nil || !strings.HasPrefix(err.Error(), test.want) {
+            t.Errorf("trigger update %q --feed %q --del-param %q --del-annotation %q error = %v, want %q", test.args,
+                test.feed, test.delParams, test.delAnnotations, err, test.want)
+        }
+    }
+}
@@ -26,6 +26,8 @@ import (
     "../wski18n"
 
     "github.com/fatih/color"
+    "github.com/ghodss/yaml"
+    "github.com/mattn/go-colorable"
     //prettyjson "github.com/hokaccha/go-prettyjson"  // See prettyjson comment below
     "archive/tar"
     "io"
@@ -33,11 +35,16 @@ import (
     "compress/gzip"
     "archive/zip"
     "encoding/json"
+    "net/http"
     "net/url"
     "io/ioutil"
+    "path"
+    "path/filepath"
     "sort"
     "reflect"
     "bytes"
+    "strconv"
+    "unicode/utf8"
 )
 
 type QualifiedName struct {
@@ -162,14 +169,24 @@ func getQualifiedName(name string, namespace string) (string) {
     }
 }
 
-func csvToQualifiedActions(artifacts string) ([]string) {
+func csvToQualifiedActions(artifacts string) ([]string, error) {
     var res []string
     actions := strings.Split(artifacts, ",")
     for i := 0; i < len(actions); i++ {
-        res = append(res, getQualifiedName(actions[i], Properties.Namespace))
+        action := strings.TrimSpace(actions[i])
+        if len(action) == 0 {
+            whisk.Debug(whisk.DbgError, "Empty component at position %d in '%s'\n", i, artifacts)
+            return nil, emptySequenceComponentError(artifacts)
+        }
+
+        if _, err := parseQualifiedName(action); err != nil {
+            return nil, parseQualifiedNameError(action, err)
+        }
+
+        res = append(res, getQualifiedName(action, Properties.Namespace))
     }
 
-    return res
+    return res, nil
 }
 
 func getJSONFromStrings(content []string, keyValueFormat bool) (interface{}, error) {
@@ -215,28 +232,83 @@ func getKeyValueFormattedJSON(data map[string]interface{}) (whisk.KeyValueArr) {
 }
 
 func getFormattedJSON(key string, value string) (string) {
-    var res string
+    return getKeyValueJSON(key, value, true)
+}
 
-    key = getEscapedJSON(key)
+// getKeyValueJSON returns the JSON object {key: value} for a KEY VALUE argument. When inferType is set, a value that
+// is valid JSON, such as a number, boolean, null, object or array, keeps its JSON type, so that 5 is a number and
+// "5" a string. Any other value, and every value when inferType is not set, is a string.
+func getKeyValueJSON(key string, value string, inferType bool) (string) {
+    var jsonValue interface{} = value
 
-    if isValidJSON(value) {
+    if inferType && isValidJSON(value) {
         whisk.Debug(whisk.DbgInfo, "Value '%s' is valid JSON.\n", value)
-        res = fmt.Sprintf("{\"%s\": %s}", key, value)
+        jsonValue = json.RawMessage(value)
     } else {
-        whisk.Debug(whisk.DbgInfo, "Converting value '%s' to a string as it is not valid JSON.\n", value)
-        res = fmt.Sprintf("{\"%s\": \"%s\"}", key, value)
+        whisk.Debug(whisk.DbgInfo, "Converting value '%s' to a string.\n", value)
     }
 
+    res, _ := json.Marshal(map[string]interface{}{key: jsonValue})
     whisk.Debug(whisk.DbgInfo, "Formatted JSON '%s'\n", res)
 
-    return res
+    return string(res)
+}
+
+// parseNamedParam splits a KEY=VALUE parameter at its first '='
+func parseNamedParam(s string) (string, string, error) {
+    i := strings.Index(s, "=")
+    if i < 0 {
+        whisk.Debug(whisk.DbgError, "Named parameter '%s' has no '='\n", s)
+        errMsg := wski18n.T("Invalid named parameter '{{.param}}'; the format is KEY=VALUE",
+            map[string]interface{}{"param": s})
+        return "", "", errors.New(errMsg)
+    }
+
+    return s[:i], s[i + 1:], nil
+}
+
+// deleteKeyValues returns keyValues without the given keys. A key that is not present is only noted in the debug
+// output. kind and name describe the key values, e.g. "parameter" and the name of their entity.
+func deleteKeyValues(keyValues whisk.KeyValueArr, keys []string, kind string, name string) (whisk.KeyValueArr) {
+    for _, key := range keys {
+        if keyValues.FindKeyValue(key) < 0 {
+            whisk.Debug(whisk.DbgInfo, "'%s' has no %s '%s' to delete\n", name, kind, key)
+        }
+        keyValues = keyValues.Delete(key)
+    }
+
+    return keyValues
 }
 
-func getEscapedJSON(value string) (string) {
-    value = strings.Replace(value, "\\", "\\\\", -1)
-    value = strings.Replace(value, "\"", "\\\"", -1)
+// mergeAndDeleteKeyValues returns the existing key values of an entity with changed replacing those of the same key,
+// and without the deleted keys. The result is empty rather than nil when every key value is deleted, so that an update
+// sends it and the server removes the existing key values.
+func mergeAndDeleteKeyValues(existing whisk.KeyValueArr, changed whisk.KeyValueArr, deletedKeys []string, kind string,
+        name string) (whisk.KeyValueArr) {
+    return deleteKeyValues(mergeKeyValues(existing, changed), deletedKeys, kind, name)
+}
+
+// checkDeletedKeys returns an error when a key is both set by keyValueArgs, the JSON objects of the -p or -a
+// arguments, and deleted by deleteFlag. Arguments that are not valid JSON are reported when they are parsed.
+func checkDeletedKeys(keyValueArgs []string, deletedKeys []string, deleteFlag string) (error) {
+    if len(keyValueArgs) == 0 || len(deletedKeys) == 0 {
+        return nil
+    }
+
+    keyValues, err := getJSONFromStrings(keyValueArgs, false)
+    if err != nil {
+        return nil
+    }
+
+    for _, key := range deletedKeys {
+        if _, found := keyValues.(map[string]interface{})[key]; found {
+            errMsg := wski18n.T("The key '{{.key}}' cannot be both set and deleted with {{.flag}}",
+                map[string]interface{}{"key": key, "flag": deleteFlag})
+            return nonNestedError(errMsg)
+        }
+    }
 
-    return value
+    return nil
 }
 
 func isValidJSON(value string) (bool) {
@@ -247,7 +319,46 @@ func isValidJSON(value string) (bool) {
 
 var boldString = color.New(color.Bold).SprintFunc()
 
-func printList(collection interface{}) {
+// Values of the --color flag
+const (
+    colorModeAuto   = "auto"
+    colorModeAlways = "always"
+    colorModeNever  = "never"
+)
+
+// Whether color is off in the auto mode; the color package turns it off when standard output is not a terminal
+var autoNoColor = color.NoColor
+
+// setColorMode turns colored output on or off for all commands, as the color package is used for all of it. In the
+// auto mode, color is off when standard output is not a terminal or the NO_COLOR environment variable is set.
+func setColorMode(mode string) error {
+    switch mode {
+    case colorModeAlways:
+        color.NoColor = false
+    case colorModeNever:
+        color.NoColor = true
+    case colorModeAuto:
+        color.NoColor = autoNoColor || len(os.Getenv("NO_COLOR")) > 0
+    default:
+        whisk.Debug(whisk.DbgError, "Invalid color mode '%s'\n", mode)
+        errStr := wski18n.T("Invalid color mode '{{.mode}}'. Valid modes are 'auto', 'always' and 'never'.",
+            map[string]interface{}{"mode": mode})
+        return whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_USAGE, whisk.DISPLAY_MSG, whisk.DISPLAY_USAGE)
+    }
+
+    return nil
+}
+
+// printList prints collection as a table, or as a JSON or YAML array when the --output flag is set
+func printList(collection interface{}) error {
+    if len(flags.global.output) > 0 {
+        // Print an empty array rather than null for empty lists
+        if value := reflect.ValueOf(collection); value.Kind() == reflect.Slice && value.IsNil() {
+            collection = reflect.MakeSlice(value.Type(), 0, 0).Interface()
+        }
+        return printFormatted(collection, flags.global.output)
+    }
+
     switch collection := collection.(type) {
     case []whisk.Action:
         printActionList(collection)
@@ -264,6 +375,46 @@ func printList(collection interface{}) {
     case []whisk.Api:
         printApiList(collection)
     }
+
+    return nil
+}
+
+// printListTotal tells how many entities of a collection were listed, when the server reports that the collection
+// holds more entities than --limit allows. Nothing is printed with --all or a machine readable --output format.
+func printListTotal(count int, total int, collection string) {
+    if flags.common.all || len(flags.global.output) > 0 || total <= flags.common.limit {
+        return
+    }
+
+    fmt.Print(wski18n.T("showing {{.count}} of {{.total}} {{.collection}}\n",
+        map[string]interface{}{"count": count, "total": total, "collection": collection}))
+}
+
+// listAllPages fetches every page of a collection with list, beginning at the --skip offset. When a page cannot be
+// fetched after earlier pages succeeded, a warning is printed and the entities already fetched are kept.
+func listAllPages(list func(limit int, skip int) (int, *http.Response, error)) error {
+    var total int
+
+    _, err := whisk.ListAllPages(flags.common.skip, func(limit int, skip int) (int, *http.Response, error) {
+        count, resp, err := list(limit, skip)
+        total += count
+        return count, resp, err
+    })
+
+    return getListAllPagesError(total, err)
+}
+
+// getListAllPagesError returns the error of a listing that stopped after count entities. When some entities were
+// fetched, a warning is printed instead and no error is returned, so that they are kept.
+func getListAllPagesError(count int, err error) error {
+    if err != nil && count > 0 {
+        whisk.Debug(whisk.DbgError, "Listing stopped after %d entities: %s\n", count, err)
+        fmt.Fprintf(colorable.NewColorableStderr(), wski18n.T("{{.warning}} listing stopped after {{.count}} entities: {{.err}}\n",
+            map[string]interface{}{"warning": color.YellowString("warning:"), "count": count, "err": err}))
+        return nil
+    }
+
+    return err
 }
 
 func printFullList(collection interface{}) {
@@ -299,39 +450,140 @@ func printSummary(collection interface{}) {
     }
 }
 
+// Minimum widths of list columns, which keep lists of short names aligned as they always were
+const (
+    listNameWidth           = 70
+    listVersionWidth        = 10
+    listActivationNameWidth = 20
+)
+
 func printActionList(actions []whisk.Action) {
     fmt.Fprintf(color.Output, "%s\n", boldString("actions"))
+    printActionRows(actions)
+}
+
+func printActionRows(actions []whisk.Action) {
+    var rows [][]string
+    minWidths := []int{listNameWidth, 0, 0}
+
+    if flags.action.showVersion {
+        minWidths = []int{listNameWidth, listVersionWidth, 0, 0}
+    }
+
     for _, action := range actions {
-        publishState := wski18n.T("private")
-        kind := getValueString(action.Annotations, "exec")
-        fmt.Printf("%-70s %s %s\n", fmt.Sprintf("/%s/%s", action.Namespace, action.Name), publishState, kind)
+        columns := action.Columns()
+        if flags.action.skipNamespace {
+            columns[0] = action.Name
+        }
+        if flags.action.showVersion {
+            columns = append([]string{columns[0], action.Version}, columns[1:]...)
+        }
+        rows = append(rows, columns)
     }
+
+    printRows(rows, minWidths)
 }
 
 func printTriggerList(triggers []whisk.Trigger) {
     fmt.Fprintf(color.Output, "%s\n", boldString("triggers"))
+    printTriggerRows(triggers)
+}
+
+func printTriggerRows(triggers []whisk.Trigger) {
+    var entities []interface{}
+
     for _, trigger := range triggers {
-        publishState := wski18n.T("private")
-        fmt.Printf("%-70s %s\n", fmt.Sprintf("/%s/%s", trigger.Namespace, trigger.Name), publishState)
+        entities = append(entities, trigger)
     }
+
+    printEntityRows(entities, []int{listNameWidth, 0})
 }
 
 func printPackageList(packages []whisk.Package) {
     fmt.Fprintf(color.Output, "%s\n", boldString("packages"))
+    printPackageRows(packages)
+}
+
+func printPackageRows(packages []whisk.Package) {
+    var entities []interface{}
+
     for _, xPackage := range packages {
-        publishState := wski18n.T("private")
-        if xPackage.Publish != nil && *xPackage.Publish {
-            publishState = wski18n.T("shared")
-        }
-        fmt.Printf("%-70s %s\n", fmt.Sprintf("/%s/%s", xPackage.Namespace, xPackage.Name), publishState)
+        entities = append(entities, xPackage)
     }
+
+    printEntityRows(entities, []int{listNameWidth, 0})
 }
 
 func printRuleList(rules []whisk.Rule) {
+    fmt.Fprintf(color.Output, "%s\n", boldString("rules"))
+    printRuleRows(rules)
+}
+
+func printRuleRows(rules []whisk.Rule) {
+    var entities []interface{}
+
+    for _, rule := range rules {
+        entities = append(entities, rule)
+    }
+
+    printEntityRows(entities, []int{listNameWidth, 0})
+}
+
+func printRuleListFull(rules []whisk.Rule) {
     fmt.Fprintf(color.Output, "%s\n", boldString("rules"))
     for _, rule := range rules {
-        publishState := wski18n.T("private")
-        fmt.Printf("%-70s %s\n", fmt.Sprintf("/%s/%s", rule.Namespace, rule.Name), publishState)
+        fmt.Printf("%s\t%s\t%s\n", fmt.Sprintf("/%s/%s", rule.Namespace, rule.Name), whisk.GetRuleEntityName(rule.Trigger),
+            whisk.GetRuleEntityName(rule.Action))
+    }
+}
+
+type sortableList []whisk.Sortable
+
+func (list sortableList) Len() int           { return len(list) }
+func (list sortableList) Swap(i, j int)      { list[i], list[j] = list[j], list[i] }
+func (list sortableList) Less(i, j int) bool { return list[i].Compare(list[j]) }
+
+// sortByName orders entities using their Compare method
+func sortByName(toSort []whisk.Sortable) {
+    sort.Stable(sortableList(toSort))
+}
+
+func sortRules(rules []whisk.Rule) {
+    var toSort []whisk.Sortable
+
+    for _, rule := range rules {
+        toSort = append(toSort, rule)
+    }
+    sortByName(toSort)
+
+    for i := range toSort {
+        rules[i] = toSort[i].(whisk.Rule)
+    }
+}
+
+func sortTriggers(triggers []whisk.Trigger) {
+    var toSort []whisk.Sortable
+
+    for _, trigger := range triggers {
+        toSort = append(toSort, trigger)
+    }
+    sortByName(toSort)
+
+    for i := range toSort {
+        triggers[i] = toSort[i].(whisk.Trigger)
+    }
+}
+
+func sortPackages(packages []whisk.Package) {
+    var toSort []whisk.Sortable
+
+    for _, xPackage := range packages {
+        toSort = append(toSort, xPackage)
+    }
+    sortByName(toSort)
+
+    for i := range toSort {
+        packages[i] = toSort[i].(whisk.Package)
     }
 }
 
@@ -343,12 +595,90 @@ func printNamespaceList(namespaces []whisk.Namespace) {
 }
 
 func printActivationList(activations []whisk.Activation) {
+    var entities []interface{}
+
     fmt.Fprintf(color.Output, "%s\n", boldString("activations"))
     for _, activation := range activations {
-        fmt.Printf("%s %-20s\n", activation.ActivationID, activation.Name)
+        entities = append(entities, activation)
+    }
+
+    printEntityRows(entities, []int{0, listActivationNameWidth})
+}
+
+// printEntityRows prints one list row per entity, using the entity's columns when it implements whisk.Columnar and
+// its ListString otherwise
+func printEntityRows(entities []interface{}, minWidths []int) {
+    var rows [][]string
+
+    for _, entity := range entities {
+        if columnar, ok := entity.(whisk.Columnar); ok {
+            rows = append(rows, columnar.Columns())
+        } else if sortable, ok := entity.(whisk.Sortable); ok {
+            rows = append(rows, []string{strings.TrimSuffix(sortable.ListString(), "\n")})
+        }
+    }
+
+    printRows(rows, minWidths)
+}
+
+// printRows prints rows as left aligned columns separated by a space. Each column is padded to the width of its
+// longest value, and to its minimum width while the rows still fit the terminal. Values are never truncated.
+func printRows(rows [][]string, minWidths []int) {
+    var widths []int
+
+    for _, row := range rows {
+        for i, value := range row {
+            if i == len(widths) {
+                widths = append(widths, 0)
+            }
+            // Trailing values are not padded unless the column has a minimum width
+            if i < len(row) - 1 {
+                widths[i] = max(widths[i], utf8.RuneCountInString(value))
+            }
+        }
+    }
+
+    padded := make([]int, len(widths))
+    total := len(widths) - 1
+    for i := range widths {
+        padded[i] = widths[i]
+        if i < len(minWidths) {
+            padded[i] = max(padded[i], minWidths[i])
+        }
+        total += padded[i]
+    }
+
+    if terminalWidth := getTerminalWidth(); terminalWidth <= 0 || total <= terminalWidth {
+        widths = padded
+    }
+
+    for _, row := range rows {
+        line := ""
+        for i, value := range row {
+            if i > 0 {
+                line += " "
+            }
+            line += value + strings.Repeat(" ", max(widths[i] - utf8.RuneCountInString(value), 0))
+        }
+        fmt.Println(line)
     }
 }
 
+// getTerminalWidth returns the width of the terminal on standard output, or else the COLUMNS environment variable, or
+// 0 when it is not known
+func getTerminalWidth() int {
+    if width := getTTYWidth(); width > 0 {
+        return width
+    }
+
+    width, err := strconv.Atoi(os.Getenv("COLUMNS"))
+    if err != nil {
+        return 0
+    }
+
+    return width
+}
+
 func printFullActivationList(activations []whisk.Activation) {
     fmt.Fprintf(color.Output, "%s\n", boldString("activations"))
     for _, activation := range activations {
@@ -356,6 +686,38 @@ func printFullActivationList(activations []whisk.Activation) {
     }
 }
 
+// printActivationSummaryList prints one row per activation with its duration and result status
+func printActivationSummaryList(activations []whisk.Activation) {
+    fmt.Fprintf(color.Output, "%s\n", boldString("activations"))
+    for _, activation := range activations {
+        fmt.Printf("%s %-20s %10s %s\n", activation.ActivationID, activation.Name,
+            getActivationDuration(activation), getActivationStatus(activation))
+    }
+}
+
+// getActivationDuration returns how long an activation ran, preferring the duration reported by the server over the
+// difference between its end and start times
+func getActivationDuration(activation whisk.Activation) string {
+    duration := activation.Duration
+    if duration == 0 && activation.End > activation.Start {
+        duration = activation.End - activation.Start
+    }
+
+    return fmt.Sprintf("%dms", duration)
+}
+
+func getActivationStatus(activation whisk.Activation) string {
+    if len(activation.Response.Status) > 0 {
+        return activation.Response.Status
+    }
+
+    if activation.Response.Success {
+        return "success"
+    }
+
+    return "error"
+}
+
 func printApiList(apis []whisk.Api) {
     fmt.Fprintf(color.Output, "%s\n", boldString("apis"))
     for _, api := range apis {
@@ -410,6 +772,10 @@ func printActionSummary(action *whisk.Action) {
         getFullName(action.Namespace, "", action.Name),
         getValueString(action.Annotations, "description"),
         strings.Join(getChildValueStrings(action.Annotations, "parameters", "name"), ", "))
+
+    if action.Exec != nil && action.Exec.Kind == "sequence" {
+        printSequenceComponents(action.Exec.Components, flags.common.full)
+    }
 }
 
 func printTriggerSummary(trigger *whisk.Trigger) {
@@ -420,9 +786,44 @@ func printTriggerSummary(trigger *whisk.Trigger) {
 }
 
 func printRuleSummary(rule *whisk.Rule) {
-    fmt.Fprintf(color.Output, "%s %s\n", boldString(fmt.Sprintf("%4s", "rule")),
-        getFullName(rule.Namespace, "", rule.Name))
-    fmt.Fprintf(color.Output, "   (%s: %s)\n", boldString(wski18n.T("status")), rule.Status)
+    fullName := getFullName(rule.Namespace, "", rule.Name)
+
+    if description := getValueString(rule.Annotations, "description"); len(description) > 0 {
+        fmt.Fprintf(color.Output, "%s %s: %s\n", boldString("rule"), fullName, description)
+    } else {
+        fmt.Fprintf(color.Output, "%s %s\n", boldString("rule"), fullName)
+    }
+
+    fmt.Fprintf(color.Output, "  %s: %s\n", boldString(wski18n.T("trigger")), whisk.GetRuleEntityName(rule.Trigger))
+    fmt.Fprintf(color.Output, "  %s: %s\n", boldString(wski18n.T("action")), whisk.GetRuleEntityName(rule.Action))
+    fmt.Fprintf(color.Output, "  %s: %s\n", boldString(wski18n.T("status")), rule.Status)
+
+    var annotations whisk.KeyValueArr
+    for _, annotation := range rule.Annotations {
+        if annotation.Key != "description" {
+            annotations = append(annotations, annotation)
+        }
+    }
+
+    if len(annotations) > 0 {
+        fmt.Fprintf(color.Output, "  %s:\n", boldString(wski18n.T("annotations")))
+        for _, annotation := range annotations {
+            fmt.Printf("    %s=%s\n", annotation.Key, getSummaryValueString(annotation.Value))
+        }
+    }
+}
+
+// getSummaryValueString formats a key value for a summary; strings are shown as is and other values as JSON
+func getSummaryValueString(value interface{}) string {
+    if str, ok := value.(string); ok {
+        return str
+    }
+
+    if data, err := json.Marshal(value); err == nil {
+        return string(data)
+    }
+
+    return fmt.Sprintf("%v", value)
 }
 
 func printEntitySummary(entityType string, fullName string, description string, params string) {
@@ -453,26 +854,6 @@ func getFullName(namespace string, packageName string, entityName string) (strin
     return fullName
 }
 
-func deleteKey(key string, keyValueArr whisk.KeyValueArr) (whisk.KeyValueArr) {
-    for i := 0; i < len(keyValueArr); i++ {
-        if keyValueArr[i].Key == key {
-            keyValueArr = append(keyValueArr[:i], keyValueArr[i + 1:]...)
-            break
-        }
-    }
-
-    return keyValueArr
-}
-
-func addKeyValue(key string, value interface{}, keyValueArr whisk.KeyValueArr) (whisk.KeyValueArr) {
-    keyValue := whisk.KeyValue{
-        Key:  key,
-        Value: value,
-    }
-
-    return append(keyValueArr, keyValue)
-}
-
 func getKeys(keyValueArr whisk.KeyValueArr) ([]string) {
     var res []string
 
@@ -487,14 +868,7 @@ func getKeys(keyValueArr whisk.KeyValueArr) ([]string) {
 }
 
 func getValue(keyValueArr whisk.KeyValueArr, key string) (interface{}) {
-    var res interface{}
-
-    for i := 0; i < len(keyValueArr); i++ {
-        if keyValueArr[i].Key == key {
-            res = keyValueArr[i].Value
-            break;
-        }
-    }
+    res, _ := keyValueArr.GetValue(key)
 
     whisk.Debug(whisk.DbgInfo, "Got value '%v' from '%v' for key '%s'\n", res, keyValueArr, key)
 
@@ -589,6 +963,29 @@ func logoText() string {
     return logo
 }
 
+// printFormatted prints v as JSON or YAML, as selected with the --output flag
+func printFormatted(v interface{}, format string) error {
+    if format != formatOptionYaml {
+        printJSON(v)
+        return nil
+    }
+
+    jsonBytes, err := json.Marshal(v)
+    if err == nil {
+        var yamlBytes []byte
+        if yamlBytes, err = yaml.JSONToYAML(jsonBytes); err == nil {
+            fmt.Print(string(yamlBytes))
+            return nil
+        }
+    }
+
+    whisk.Debug(whisk.DbgError, "Unable to convert %#v to YAML: %s\n", v, err)
+    errMsg := wski18n.T("Unable to format output as YAML: {{.err}}", map[string]interface{}{"err": err})
+    whiskErr := whisk.MakeWskError(errors.New(errMsg), whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG,
+        whisk.NO_DISPLAY_USAGE)
+    return whiskErr
+}
+
 func printJSON(v interface{}, stream ...io.Writer) {
     // Can't use prettyjson util issue  https://github.com/hokaccha/go-prettyjson/issues/1 is fixed
     //output, _ := prettyjson.Marshal(v)
@@ -601,6 +998,45 @@ func printJSON(v interface{}, stream ...io.Writer) {
     printJsonNoColor(v, stream...)
 }
 
+// writeJSONFile writes v as indented JSON to filename. An existing file is only replaced when force is set.
+func writeJSONFile(v interface{}, filename string, force bool) error {
+    file, err := createOutputFile(filename, force)
+    if err != nil {
+        return err
+    }
+    defer file.Close()
+
+    printJsonNoColor(v, file)
+
+    return nil
+}
+
+// createOutputFile opens filename for writing, truncating it. An existing file is only replaced when force is set.
+func createOutputFile(filename string, force bool) (*os.File, error) {
+    fileFlags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+    if !force {
+        fileFlags |= os.O_EXCL
+    }
+
+    file, err := os.OpenFile(filename, fileFlags, 0644)
+    if err != nil {
+        whisk.Debug(whisk.DbgError, "os.OpenFile(%s) error: %s\n", filename, err)
+        var errMsg string
+        if os.IsExist(err) {
+            errMsg = wski18n.T("The file '{{.name}}' already exists; use --force to replace it",
+                map[string]interface{}{"name": filename})
+        } else {
+            errMsg = wski18n.T("Unable to write '{{.name}}': {{.err}}",
+                map[string]interface{}{"name": filename, "err": err})
+        }
+        whiskErr := whisk.MakeWskErrorFromWskError(errors.New(errMsg), err, whisk.EXITCODE_ERR_GENERAL,
+            whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+        return nil, whiskErr
+    }
+
+    return file, nil
+}
+
 func printJsonNoColor(decoded interface{}, stream ...io.Writer) {
     var output bytes.Buffer
 
@@ -676,6 +1112,186 @@ func unpackGzip(inpath string, outpath string) error {
     return nil
 }
 
+// Name of the file listing the paths that zipDirectory leaves out of an archive
+const WSK_IGNORE_FILENAME = ".wskignore"
+
+// zipDirectory returns a zip archive of the files in dir, with paths relative to dir. Symbolic links are followed,
+// empty directories are left out, and so are .git and the paths matching a pattern in the .wskignore file of dir.
+func zipDirectory(dir string) ([]byte, error) {
+    var buffer bytes.Buffer
+    var ignored []string
+    var err error
+
+    if ignored, err = readIgnorePatterns(filepath.Join(dir, WSK_IGNORE_FILENAME)); err != nil {
+        return nil, err
+    }
+
+    zipWriter := zip.NewWriter(&buffer)
+    if err = zipDirectoryFiles(zipWriter, dir, "", ignored, map[string]bool{}); err != nil {
+        return nil, err
+    }
+
+    if err = zipWriter.Close(); err != nil {
+        whisk.Debug(whisk.DbgError, "zipWriter.Close() failed: %s\n", err)
+        return nil, zipDirectoryError(dir, err)
+    }
+
+    return buffer.Bytes(), nil
+}
+
+// zipDirectoryFiles adds the files of dir to zipWriter under the path prefix. ancestors holds the resolved paths
+// of the directories being walked, so that a symbolic link to one of them is reported as a cycle.
+func zipDirectoryFiles(zipWriter *zip.Writer, dir string, prefix string, ignored []string,
+    ancestors map[string]bool) (error) {
+    realDir, err := filepath.EvalSymlinks(dir)
+    if err != nil {
+        whisk.Debug(whisk.DbgError, "filepath.EvalSymlinks(%s) failed: %s\n", dir, err)
+        return zipDirectoryError(dir, err)
+    }
+
+    if ancestors[realDir] {
+        whisk.Debug(whisk.DbgError, "Symbolic link cycle at '%s'\n", dir)
+        errStr := wski18n.T("Unable to zip '{{.name}}': symbolic link cycle", map[string]interface{}{"name": dir})
+        return whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG,
+            whisk.NO_DISPLAY_USAGE)
+    }
+    ancestors[realDir] = true
+    defer delete(ancestors, realDir)
+
+    entries, err := ioutil.ReadDir(dir)
+    if err != nil {
+        whisk.Debug(whisk.DbgError, "ioutil.ReadDir(%s) failed: %s\n", dir, err)
+        return zipDirectoryError(dir, err)
+    }
+
+    for _, entry := range entries {
+        name := path.Join(prefix, entry.Name())
+        file := filepath.Join(dir, entry.Name())
+
+        // Ignored paths are checked before they are followed, so that a dangling symbolic link can be ignored
+        if entry.Name() == ".git" || (len(prefix) == 0 && entry.Name() == WSK_IGNORE_FILENAME) ||
+                isIgnoredPath(name, entry.IsDir(), ignored) {
+            whisk.Debug(whisk.DbgInfo, "Leaving '%s' out of the archive\n", name)
+            continue
+        }
+
+        // Stat follows symbolic links, unlike the entries returned by ReadDir
+        info, err := os.Stat(file)
+        if err != nil {
+            whisk.Debug(whisk.DbgError, "os.Stat(%s) failed: %s\n", file, err)
+            return zipDirectoryError(file, err)
+        }
+
+        // A symbolic link to a directory is only known to be a directory once it is followed
+        if info.IsDir() && !entry.IsDir() && isIgnoredPath(name, true, ignored) {
+            whisk.Debug(whisk.DbgInfo, "Leaving '%s' out of the archive\n", name)
+            continue
+        }
+
+        if info.IsDir() {
+            err = zipDirectoryFiles(zipWriter, file, name, ignored, ancestors)
+        } else if info.Mode().IsRegular() {
+            err = zipFile(zipWriter, file, name, info)
+        }
+
+        if err != nil {
+            return err
+        }
+    }
+
+    return nil
+}
+
+func zipFile(zipWriter *zip.Writer, file string, name string, info os.FileInfo) (error) {
+    header, err := zip.FileInfoHeader(info)
+    if err != nil {
+        whisk.Debug(whisk.DbgError, "zip.FileInfoHeader(%s) failed: %s\n", file, err)
+        return zipDirectoryError(file, err)
+    }
+    header.Name = name
+    header.Method = zip.Deflate
+
+    writer, err := zipWriter.CreateHeader(header)
+    if err != nil {
+        whisk.Debug(whisk.DbgError, "zipWriter.CreateHeader(%s) failed: %s\n", name, err)
+        return zipDirectoryError(file, err)
+    }
+
+    reader, err := os.Open(file)
+    if err != nil {
+        whisk.Debug(whisk.DbgError, "os.Open(%s) failed: %s\n", file, err)
+        return zipDirectoryError(file, err)
+    }
+    defer reader.Close()
+
+    if _, err = io.Copy(writer, reader); err != nil {
+        whisk.Debug(whisk.DbgError, "io.Copy() of '%s' failed: %s\n", file, err)
+        return zipDirectoryError(file, err)
+    }
+
+    return nil
+}
+
+// readIgnorePatterns returns the patterns in an ignore file, one per line, skipping blank lines and lines starting
+// with #. A missing file has no patterns.
+func readIgnorePatterns(filename string) ([]string, error) {
+    var patterns []string
+
+    data, err := ioutil.ReadFile(filename)
+    if os.IsNotExist(err) {
+        return nil, nil
+    } else if err != nil {
+        whisk.Debug(whisk.DbgError, "ioutil.ReadFile(%s) failed: %s\n", filename, err)
+        errStr := wski18n.T("Unable to read '{{.name}}': {{.err}}", map[string]interface{}{"name": filename, "err": err})
+        return nil, whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG,
+            whisk.NO_DISPLAY_USAGE)
+    }
+
+    for _, line := range strings.Split(string(data), "\n") {
+        line = strings.TrimSpace(line)
+        if len(line) > 0 && !strings.HasPrefix(line, "#") {
+            patterns = append(patterns, line)
+        }
+    }
+
+    return patterns, nil
+}
+
+// isIgnoredPath reports whether name, a slash separated path relative to the archived directory, matches one of
+// patterns. A pattern matches the whole path or its last element; a pattern starting with / only matches the whole
+// path, and a pattern ending with / only matches directories.
+func isIgnoredPath(name string, isDir bool, patterns []string) (bool) {
+    for _, pattern := range patterns {
+        if strings.HasSuffix(pattern, "/") {
+            if !isDir {
+                continue
+            }
+            pattern = strings.TrimSuffix(pattern, "/")
+        }
+
+        if strings.HasPrefix(pattern, "/") {
+            if matched, _ := path.Match(strings.TrimPrefix(pattern, "/"), name); matched {
+                return true
+            }
+            continue
+        }
+
+        if matched, _ := path.Match(pattern, name); matched {
+            return true
+        }
+        if matched, _ := path.Match(pattern, path.Base(name)); matched {
+            return true
+        }
+    }
+
+    return false
+}
+
+func zipDirectoryError(name string, err error) (error) {
+    errStr := wski18n.T("Unable to zip '{{.name}}': {{.err}}", map[string]interface{}{"name": name, "err": err})
+    return whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+}
+
 func unpackZip(inpath string) error {
     // Make sure the input file exists
     if _, err := os.Stat(inpath); err != nil {
@@ -743,6 +1359,49 @@ func unpackZip(inpath string) error {
     return nil
 }
 
+// isSafeArchivePath reports whether an archive entry name is relative and stays inside the directory the archive is
+// unpacked in, so that unpacking cannot overwrite other files
+func isSafeArchivePath(name string) bool {
+    if filepath.IsAbs(name) || strings.HasPrefix(name, "/") || strings.HasPrefix(name, "\\") {
+        return false
+    }
+
+    for _, element := range strings.FieldsFunc(name, func(c rune) bool { return c == '/' || c == '\\' }) {
+        if element == ".." {
+            return false
+        }
+    }
+
+    return true
+}
+
+// checkTarPaths reads the tar file inpath from tarFile and checks that none of its items would be unpacked outside the
+// current directory
+func checkTarPaths(tarFile io.Reader, inpath string) error {
+    tReader := tar.NewReader(tarFile)
+    for {
+        item, err := tReader.Next()
+        if err == io.EOF {
+            return nil
+        }
+        if err != nil {
+            whisk.Debug(whisk.DbgError, "tReader.Next() failed: %s\n", err)
+            errStr := wski18n.T("Error reading tar file '{{.name}}': {{.err}}",
+                    map[string]interface{}{"name": inpath, "err": err})
+            werr := whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+            return werr
+        }
+
+        if !isSafeArchivePath(item.Name) {
+            whisk.Debug(whisk.DbgError, "tar file item '%s' is outside the current directory\n", item.Name)
+            errStr := wski18n.T("The tar file '{{.name}}' contains '{{.file}}', which is outside the current directory",
+                    map[string]interface{}{"name": inpath, "file": item.Name})
+            werr := whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+            return werr
+        }
+    }
+}
+
 func unpackTar(inpath string) error {
 
     // Make sure the input file exists
@@ -763,6 +1422,18 @@ func unpackTar(inpath string) error {
     }
     defer tarFileReader.Close()
 
+    // Nothing is unpacked unless every item stays inside the current directory
+    if err = checkTarPaths(tarFileReader, inpath); err != nil {
+        return err
+    }
+    if _, err = tarFileReader.Seek(0, io.SeekStart); err != nil {
+        whisk.Debug(whisk.DbgError, "Seek(0) of '%s' failed: %s\n", inpath, err)
+        errStr := wski18n.T("Error reading tar file '{{.name}}': {{.err}}",
+                map[string]interface{}{"name": inpath, "err": err})
+        werr := whisk.MakeWskError(errors.New(errStr), whisk.EXITCODE_ERR_GENERAL, whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+        return werr
+    }
+
     // Loop through the files in the tarfile
     tReader := tar.NewReader(tarFileReader)
     for {
@@ -903,25 +1574,110 @@ func readFile(filename string) (string, error) {
     return string(file), nil
 }
 
+func readStdin() (string, error) {
+    data, err := ioutil.ReadAll(os.Stdin)
+    if err != nil {
+        whisk.Debug(whisk.DbgError, "ioutil.ReadAll(os.Stdin) error: %s\n", err)
+        errMsg := wski18n.T("Unable to read from standard input: {{.err}}",
+                map[string]interface{}{"err": err})
+        whiskErr := whisk.MakeWskErrorFromWskError(errors.New(errMsg), err, whisk.EXITCODE_ERR_GENERAL,
+            whisk.DISPLAY_MSG, whisk.NO_DISPLAY_USAGE)
+        return "", whiskErr
+    }
+
+    return string(data), nil
+}
+
+// readJSONObjectFile reads a parameter or annotation file, or standard input when the file name is "-",
+// and verifies that it contains a JSON object
+func readJSONObjectFile(filename string) (string, error) {
+    var data interface{}
+    var content string
+    var err error
+
+    if filename == "-" {
+        content, err = readStdin()
+    } else {
+        content, err = readFile(filename)
+    }
+    if err != nil {
+        return "", err
+    }
+
+    if err = json.Unmarshal([]byte(content), &data); err != nil {
+        whisk.Debug(whisk.DbgError, "json.Unmarshal(%s) error: %s\n", content, err)
+        errMsg := wski18n.T("File '{{.name}}' is not valid JSON: {{.err}}",
+                map[string]interface{}{"name": filename, "err": err})
+        whiskErr := whisk.MakeWskErrorFromWskError(errors.New(errMsg), err, whisk.EXITCODE_ERR_GENERAL,
+            whisk.DISPLAY_MSG, whisk.DISPLAY_USAGE)
+        return "", whiskErr
+    }
+
+    if _, ok := data.(map[string]interface{}); !ok {
+        whisk.Debug(whisk.DbgError, "File '%s' does not contain a JSON object: %s\n", filename, content)
+        errMsg := wski18n.T("The top level of file '{{.name}}' must be a JSON object",
+                map[string]interface{}{"name": filename})
+        whiskErr := whisk.MakeWskError(errors.New(errMsg), whisk.EXITCODE_ERR_GENERAL,
+            whisk.DISPLAY_MSG, whisk.DISPLAY_USAGE)
+        return "", whiskErr
+    }
+
+    return content, nil
+}
+
+// fieldExists reports whether field names a field of the struct pointed to by value. The field may be a dot
+// separated path, such as exec.code, into nested structs.
 func fieldExists(value interface{}, field string) (bool) {
-    element := reflect.ValueOf(value).Elem()
+    return len(getMissingField(value, field)) == 0
+}
 
-    for i := 0; i < element.NumField(); i++ {
-        if strings.ToLower(element.Type().Field(i).Name) == strings.ToLower(field) {
-            return true
+// getMissingField returns the part of a dot separated field path that does not name a field of the struct it
+// refers to, or "" when the whole path exists. Field names are matched ignoring case.
+func getMissingField(value interface{}, field string) (string) {
+    fieldType := reflect.TypeOf(value)
+
+    for _, name := range strings.Split(field, ".") {
+        for fieldType.Kind() == reflect.Ptr {
+            fieldType = fieldType.Elem()
+        }
+
+        if fieldType.Kind() != reflect.Struct {
+            return name
+        }
+
+        structField, found := fieldType.FieldByNameFunc(func(structField string) bool {
+            return strings.ToLower(structField) == strings.ToLower(name)
+        })
+        if !found {
+            return name
         }
+
+        fieldType = structField.Type
     }
 
-    return false
+    return ""
 }
 
+// printField prints the value of a field, which may be a dot separated path into nested structs. A path through
+// a nil value prints null.
 func printField(value interface{}, field string) {
-    var matchFunc = func(structField string) bool {
-        return strings.ToLower(structField) == strings.ToLower(field)
+    fieldValue := reflect.ValueOf(value)
+
+    for _, name := range strings.Split(field, ".") {
+        var matchFunc = func(structField string) bool {
+            return strings.ToLower(structField) == strings.ToLower(name)
+        }
+
+        if fieldValue = reflect.Indirect(fieldValue); !fieldValue.IsValid() {
+            break
+        }
+        fieldValue = fieldValue.FieldByNameFunc(matchFunc)
     }
 
-    structValue := reflect.ValueOf(value)
-    fieldValue := reflect.Indirect(structValue).FieldByNameFunc(matchFunc)
+    if !fieldValue.IsValid() {
+        printJSON(nil)
+        return
+    }
 
     printJSON(fieldValue.Interface())
 }
@@ -1067,3 +1823,226 @@ func isApplicationError(err error) (bool) {
 
     return applicationError
 }
+
+// Number of unchanged lines shown around each change by printUnifiedDiff
+const diffContextLines = 3
+
+type diffLine struct {
+    op      byte    // ' ' for an unchanged line, '-' for a removed line or '+' for an added line
+    text    string
+}
+
+// splitLines splits text into lines, ignoring a final newline
+func splitLines(text string) []string {
+    if len(text) == 0 {
+        return nil
+    }
+
+    return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// getLineDiff returns the lines of oldLines and newLines in order, each marked as unchanged, removed or added. The
+// lines the two have in common at their start and end are unchanged; the lines in between are compared with
+// getMyersDiff.
+func getLineDiff(oldLines []string, newLines []string) []diffLine {
+    var diff []diffLine
+
+    prefix := 0
+    for prefix < len(oldLines) && prefix < len(newLines) && oldLines[prefix] == newLines[prefix] {
+        prefix++
+    }
+
+    suffix := 0
+    for suffix < len(oldLines) - prefix && suffix < len(newLines) - prefix &&
+            oldLines[len(oldLines) - 1 - suffix] == newLines[len(newLines) - 1 - suffix] {
+        suffix++
+    }
+
+    for _, line := range oldLines[:prefix] {
+        diff = append(diff, diffLine{' ', line})
+    }
+
+    diff = append(diff, getMyersDiff(oldLines[prefix:len(oldLines) - suffix], newLines[prefix:len(newLines) - suffix])...)
+
+    for _, line := range oldLines[len(oldLines) - suffix:] {
+        diff = append(diff, diffLine{' ', line})
+    }
+
+    return diff
+}
+
+// Number of removed and added lines up to which getMyersDiff finds the shortest diff. Its time and memory grow with
+// the square of this number.
+const maxDiffEdits = 2000
+
+// getMyersDiff returns the shortest diff of oldLines and newLines found by Myers' O(ND) algorithm, where D is the
+// number of removed and added lines. When D exceeds maxDiffEdits, the files are mostly different and every old line
+// is shown as removed and every new line as added.
+func getMyersDiff(oldLines []string, newLines []string) []diffLine {
+    var diff []diffLine
+    var trace [][]int   // trace[d][k + d] is the furthest x reached on diagonal k = x - y with d edits
+
+    n, m := len(oldLines), len(newLines)
+    limit := min(n + m, maxDiffEdits)
+
+    for d := 0; d <= limit; d++ {
+        furthest := make([]int, 2 * d + 1)
+
+        for k := -d; k <= d; k += 2 {
+            var x int
+            if d == 0 {
+                x = 0
+            } else if previous := trace[d - 1]; k == -d || (k != d && previous[k - 1 + d - 1] < previous[k + 1 + d - 1]) {
+                x = previous[k + 1 + d - 1]     // An added line, down from diagonal k + 1
+            } else {
+                x = previous[k - 1 + d - 1] + 1 // A removed line, right from diagonal k - 1
+            }
+
+            y := x - k
+            for x < n && y < m && oldLines[x] == newLines[y] {
+                x++
+                y++
+            }
+            furthest[k + d] = x
+
+            if x >= n && y >= m {
+                return getMyersDiffPath(append(trace, furthest), oldLines, newLines)
+            }
+        }
+
+        trace = append(trace, furthest)
+    }
+
+    for _, line := range oldLines {
+        diff = append(diff, diffLine{'-', line})
+    }
+    for _, line := range newLines {
+        diff = append(diff, diffLine{'+', line})
+    }
+
+    return diff
+}
+
+// getMyersDiffPath follows the furthest points in trace back from the end of both files to their start
+func getMyersDiffPath(trace [][]int, oldLines []string, newLines []string) []diffLine {
+    var reversed []diffLine
+
+    x, y := len(oldLines), len(newLines)
+
+    for d := len(trace) - 1; d > 0; d-- {
+        previous := trace[d - 1]
+        k := x - y
+
+        previousK := k - 1
+        if k == -d || (k != d && previous[k - 1 + d - 1] < previous[k + 1 + d - 1]) {
+            previousK = k + 1
+        }
+        previousX := previous[previousK + d - 1]
+        previousY := previousX - previousK
+
+        for x > previousX && y > previousY {
+            reversed = append(reversed, diffLine{' ', oldLines[x - 1]})
+            x--
+            y--
+        }
+
+        if x == previousX {
+            reversed = append(reversed, diffLine{'+', newLines[y - 1]})
+            y--
+        } else {
+            reversed = append(reversed, diffLine{'-', oldLines[x - 1]})
+            x--
+        }
+    }
+
+    for ; x > 0; x-- {
+        reversed = append(reversed, diffLine{' ', oldLines[x - 1]})
+    }
+
+    diff := make([]diffLine, len(reversed))
+    for i, line := range reversed {
+        diff[len(reversed) - 1 - i] = line
+    }
+
+    return diff
+}
+
+// printUnifiedDiff prints the changes in diff in the unified format, with removed lines in red and added lines in
+// green. It returns false when there are no changes, in which case nothing is printed.
+func printUnifiedDiff(oldName string, newName string, diff []diffLine) bool {
+    var changes []int
+
+    for index, line := range diff {
+        if line.op != ' ' {
+            changes = append(changes, index)
+        }
+    }
+
+    if len(changes) == 0 {
+        return false
+    }
+
+    fmt.Fprintf(color.Output, "%s\n%s\n", boldString("--- " + oldName), boldString("+++ " + newName))
+
+    for first := 0; first < len(changes); {
+        // Changes separated by no more than twice the context are shown in the same hunk
+        last := first
+        for last + 1 < len(changes) && changes[last + 1] - changes[last] <= 2 * diffContextLines + 1 {
+            last++
+        }
+
+        start := changes[first] - diffContextLines
+        if start < 0 {
+            start = 0
+        }
+        end := changes[last] + diffContextLines + 1
+        if end > len(diff) {
+            end = len(diff)
+        }
+
+        oldStart, newStart := 1, 1
+        for _, line := range diff[:start] {
+            if line.op != '+' {
+                oldStart++
+            }
+            if line.op != '-' {
+                newStart++
+            }
+        }
+
+        oldCount, newCount := 0, 0
+        for _, line := range diff[start:end] {
+            if line.op != '+' {
+                oldCount++
+            }
+            if line.op != '-' {
+                newCount++
+            }
+        }
+
+        // An empty range is numbered after the line that precedes it
+        if oldCount == 0 {
+            oldStart--
+        }
+        if newCount == 0 {
+            newStart--
+        }
+
+        fmt.Fprintln(color.Output, color.CyanString("@@ -%d,%d +%d,%d @@", oldStart, oldCount, newStart, newCount))
+
+        for _, line := range diff[start:end] {
+            switch line.op {
+            case '-':
+                fmt.Fprintln(color.Output, color.RedString("-%s", line.text))
+            case '+':
+                fmt.Fprintln(color.Output, color.GreenString("+%s", line.text))
+            default:
+                fmt.Fprintf(color.Output, " %s\n", line.text)
+            }
+        }
+
+        first = last + 1
+    }
+
+    return true
+}
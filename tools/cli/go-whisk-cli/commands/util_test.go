@@ -0,0 +1,811 @@
+/*
+ * Copyright 2015-2016 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+    "archive/zip"
+    "bytes"
+    "encoding/json"
+    "flag"
+    "fmt"
+    "io/ioutil"
+    "net/http"
+    "os"
+    "path/filepath"
+    "reflect"
+    "regexp"
+    "strconv"
+    "strings"
+    "testing"
+
+    "github.com/fatih/color"
+    "github.com/ghodss/yaml"
+    "github.com/spf13/cobra"
+
+    "../../go-whisk/whisk"
+)
+
+func TestGetKeyValueJSON(t *testing.T) {
+    tests := []struct {
+        key         string
+        value       string
+        inferType   bool
+        want        string
+    }{
+        {"n", "5", true, `{"n":5}`},
+        {"n", "5", false, `{"n":"5"}`},
+        {"flag", "true", true, `{"flag":true}`},
+        {"flag", "true", false, `{"flag":"true"}`},
+        {"none", "null", true, `{"none":null}`},
+        {"obj", `{"a": [1, "b"]}`, true, `{"obj":{"a":[1,"b"]}}`},
+        {"obj", `{"a": 1}`, false, `{"obj":"{\"a\": 1}"}`},
+        {"s", "hello world", true, `{"s":"hello world"}`},
+        {"s", `"quoted"`, true, `{"s":"quoted"}`},
+        {"s", "{not json", true, `{"s":"{not json"}`},
+        {"s", "", true, `{"s":""}`},
+        {`k"ey`, "v", true, `{"k\"ey":"v"}`},
+        {`back\slash`, "v", true, `{"back\\slash":"v"}`},
+        {"tab\tkey", "v", true, `{"tab\tkey":"v"}`},
+        {"line\nkey", "line\nvalue", true, `{"line\nkey":"line\nvalue"}`},
+    }
+
+    for _, test := range tests {
+        res := getKeyValueJSON(test.key, test.value, test.inferType)
+        if res != test.want {
+            t.Errorf("getKeyValueJSON(%q, %q, %t) = %s, want %s", test.key, test.value, test.inferType, res, test.want)
+        }
+        if !json.Valid([]byte(res)) {
+            t.Errorf("getKeyValueJSON(%q, %q, %t) = %s is not valid JSON", test.key, test.value, test.inferType, res)
+        }
+    }
+}
+
+func TestPrintListOutputFormats(t *testing.T) {
+    origOutput := flags.global.output
+    defer func() { flags.global.output = origOutput }()
+
+    publish := true
+    lists := []interface{}{
+        []whisk.Action{{Namespace: "ns", Name: "a", Version: "0.0.1", Publish: &publish,
+            Annotations: whisk.KeyValueArr{{Key: "exec", Value: "nodejs:6"}}}},
+        []whisk.Rule{{Namespace: "ns", Name: "r", Status: "active", Trigger: "/ns/t", Action: "/ns/a"}},
+        []whisk.Trigger{},
+        []whisk.Package(nil),
+    }
+
+    for _, format := range []string{formatOptionJson, formatOptionYaml} {
+        flags.global.output = format
+        for _, list := range lists {
+            var err error
+            output := captureOutput(t, func() { err = printList(list) })
+            if err != nil {
+                t.Fatalf("printList(%#v) with --output %s failed: %s", list, format, err)
+            }
+
+            data := []byte(output)
+            if format == formatOptionYaml {
+                if data, err = yaml.YAMLToJSON(data); err != nil {
+                    t.Fatalf("--output yaml printed invalid YAML %q: %s", output, err)
+                }
+            }
+
+            // Lists are printed as arrays, also when they are empty, and decode as the listed entities
+            decoded := reflect.New(reflect.TypeOf(list))
+            if err = json.Unmarshal(data, decoded.Interface()); err != nil {
+                t.Errorf("--output %s printed %q, which does not decode as %T: %s", format, output, list, err)
+            } else if value := reflect.ValueOf(list); value.Len() > 0 && !reflect.DeepEqual(decoded.Elem().Interface(), list) {
+                t.Errorf("--output %s printed %q, which decodes as %#v", format, output, decoded.Elem().Interface())
+            } else if value.Len() == 0 && (decoded.Elem().IsNil() || decoded.Elem().Len() != 0) {
+                t.Errorf("--output %s printed %q for an empty list, want an empty array", format, output)
+            }
+        }
+    }
+}
+
+var updateGolden = flag.Bool("update", false, "update the golden files in testdata")
+
+// getGoldenLists returns a list of each entity type, keyed by the name of its golden file in testdata
+func getGoldenLists() map[string]interface{} {
+    publish := false
+    return map[string]interface{}{
+        "actions": []whisk.Action{
+            {Namespace: "ns", Name: "hello", Version: "0.0.1", Publish: &publish,
+                Annotations: whisk.KeyValueArr{{Key: "exec", Value: "nodejs:6"}}},
+            {Namespace: "ns/pkg", Name: "seq", Version: "0.0.2", Publish: &publish,
+                Annotations: whisk.KeyValueArr{{Key: "exec", Value: "sequence"}}},
+        },
+        "rules": []whisk.Rule{
+            {Namespace: "ns", Name: "r", Version: "0.0.1", Status: "active", Trigger: "/ns/t", Action: "/ns/hello"},
+        },
+        "triggers": []whisk.Trigger{
+            {Namespace: "ns", Name: "t", Version: "0.0.1", Publish: &publish},
+        },
+        "packages": []whisk.Package{
+            {Namespace: "ns", Name: "pkg", Version: "0.0.1", Publish: &publish,
+                Binding: &whisk.Binding{Namespace: "whisk.system", Name: "utils"}},
+        },
+        "activations": []whisk.Activation{
+            {Namespace: "ns", Name: "hello", Version: "0.0.1", Subject: "user", ActivationID: "12345",
+                Start: 1485212345678, End: 1485212345700, Duration: 22,
+                Response: whisk.Response{Status: "success", Success: true}, Logs: []string{"log line"}},
+        },
+        "namespaces": []whisk.Namespace{{Name: "ns"}, {Name: "other"}},
+    }
+}
+
+func TestPrintListJSONGolden(t *testing.T) {
+    origOutput := flags.global.output
+    defer func() { flags.global.output = origOutput }()
+    flags.global.output = formatOptionJson
+
+    for name, list := range getGoldenLists() {
+        var err error
+        output := captureOutput(t, func() {
+            // Colors are on, as on a terminal, but must not get into the JSON
+            color.NoColor = false
+            err = printList(list)
+        })
+        if err != nil {
+            t.Errorf("printList(%s) failed: %s", name, err)
+            continue
+        }
+
+        golden := filepath.Join("testdata", "list-" + name + ".json")
+        if *updateGolden {
+            if err = ioutil.WriteFile(golden, []byte(output), 0644); err != nil {
+                t.Fatalf("ioutil.WriteFile(%s) failed: %s", golden, err)
+            }
+        }
+
+        want, err := ioutil.ReadFile(golden)
+        if err != nil {
+            t.Errorf("ioutil.ReadFile(%s) failed: %s", golden, err)
+        } else if output != string(want) {
+            t.Errorf("printList(%s) printed:\n%s\nwant the content of %s:\n%s", name, output, golden, want)
+        }
+        if strings.Contains(output, "\x1b[") {
+            t.Errorf("printList(%s) printed color codes: %q", name, output)
+        }
+    }
+
+    // Empty lists are empty arrays
+    for _, list := range []interface{}{[]whisk.Action{}, []whisk.Rule(nil), []whisk.Activation(nil), []whisk.Namespace{}} {
+        if output := captureOutput(t, func() { printList(list) }); output != "[]\n" {
+            t.Errorf("printList(%#v) printed %q, want []", list, output)
+        }
+    }
+}
+
+func TestPrintListTextGolden(t *testing.T) {
+    origOutput := flags.global.output
+    defer func() { flags.global.output = origOutput }()
+    flags.global.output = ""
+
+    // The lists hold short names, which keep the minimum column widths
+    for name, list := range getGoldenLists() {
+        output := captureOutput(t, func() { printList(list) })
+
+        golden := filepath.Join("testdata", "list-" + name + ".txt")
+        if *updateGolden {
+            if err := ioutil.WriteFile(golden, []byte(output), 0644); err != nil {
+                t.Fatalf("ioutil.WriteFile(%s) failed: %s", golden, err)
+            }
+        }
+
+        want, err := ioutil.ReadFile(golden)
+        if err != nil {
+            t.Errorf("ioutil.ReadFile(%s) failed: %s", golden, err)
+        } else if output != string(want) {
+            t.Errorf("printList(%s) printed:\n%s\nwant the content of %s:\n%s", name, output, golden, want)
+        }
+    }
+}
+
+func TestPrintRows(t *testing.T) {
+    origColumns, hadColumns := os.LookupEnv("COLUMNS")
+    defer func() {
+        if hadColumns {
+            os.Setenv("COLUMNS", origColumns)
+        } else {
+            os.Unsetenv("COLUMNS")
+        }
+    }()
+
+    long := "/ns/" + strings.Repeat("p", 40) + "/" + strings.Repeat("a", 40)
+    tests := []struct {
+        columns     string
+        rows        [][]string
+        minWidths   []int
+        want        string
+    }{
+        // Columns are as wide as their longest value, or their minimum width
+        {"", [][]string{{"/ns/a", "private", "nodejs:6"}, {"/ns/pkg/b", "shared", "sequence"}}, []int{12, 0, 0},
+            "/ns/a        private nodejs:6\n/ns/pkg/b    shared  sequence\n"},
+        // Long names widen the column rather than being truncated
+        {"", [][]string{{long, "private"}, {"/ns/a", "shared"}}, []int{70, 0},
+            long + " private\n/ns/a" + strings.Repeat(" ", len(long) - 5) + " shared\n"},
+        // Minimum widths are dropped when they would not fit the terminal, but values are still aligned
+        {"40", [][]string{{"/ns/a", "private"}, {"/ns/bb", "shared"}}, []int{70, 0},
+            "/ns/a  private\n/ns/bb shared\n"},
+        {"200", [][]string{{"/ns/a", "private"}}, []int{10, 0}, "/ns/a      private\n"},
+        // Values wider than the terminal are kept whole
+        {"20", [][]string{{long, "private"}}, []int{70, 0}, long + " private\n"},
+        // Multibyte values are aligned by their characters
+        {"", [][]string{{"/ns/é", "x"}, {"/ns/ab", "y"}}, nil, "/ns/é  x\n/ns/ab y\n"},
+        {"", nil, []int{70}, ""},
+    }
+
+    for _, test := range tests {
+        os.Setenv("COLUMNS", test.columns)
+        if output := captureOutput(t, func() { printRows(test.rows, test.minWidths) }); output != test.want {
+            t.Errorf("printRows(%q, %v) with COLUMNS=%s printed:\n%q\nwant:\n%q", test.rows, test.minWidths,
+                test.columns, output, test.want)
+        }
+    }
+}
+
+func TestParseNamedParam(t *testing.T) {
+    tests := []struct {
+        param   string
+        key     string
+        value   string
+    }{
+        {"n=5", "n", "5"},
+        {"s=a=b", "s", "a=b"},
+        {`obj={"a":1}`, "obj", `{"a":1}`},
+        {"empty=", "empty", ""},
+        {"=v", "", "v"},
+    }
+
+    for _, test := range tests {
+        key, value, err := parseNamedParam(test.param)
+        if err != nil || key != test.key || value != test.value {
+            t.Errorf("parseNamedParam(%q) = %q, %q, %v; want %q, %q", test.param, key, value, err, test.key, test.value)
+        }
+    }
+
+    for _, param := range []string{"novalue", ""} {
+        if _, _, err := parseNamedParam(param); err == nil || !strings.Contains(err.Error(), "the format is KEY=VALUE") {
+            t.Errorf("parseNamedParam(%q) error = %v, want an invalid named parameter error", param, err)
+        }
+    }
+}
+
+// formatTestDiff returns diff as lines of its operation followed by the text
+func formatTestDiff(diff []diffLine) string {
+    var lines []string
+    for _, line := range diff {
+        lines = append(lines, string(line.op) + line.text)
+    }
+
+    return strings.Join(lines, "\n")
+}
+
+func TestGetLineDiff(t *testing.T) {
+    tests := []struct {
+        old     string
+        new     string
+        want    string
+    }{
+        {"", "", ""},
+        {"a\nb\n", "a\nb\n", " a\n b"},
+        {"", "a\nb", "+a\n+b"},
+        {"a\nb", "", "-a\n-b"},
+        {"a\nb\nc", "a\nx\nc", " a\n-b\n+x\n c"},
+        {"a\nb\nc", "a\nc", " a\n-b\n c"},
+        {"a\nc", "a\nb\nc", " a\n+b\n c"},
+        {"a\nb\nc", "b\nc\nd", "-a\n b\n c\n+d"},
+        // Lines that moved are removed and added, around the longest common subsequence
+        {"a\nb\nc\nd", "c\nd\na\nb", "-a\n-b\n c\n d\n+a\n+b"},
+        {"x\na\ny\nb\nz", "a\nb", "-x\n a\n-y\n b\n-z"},
+        // A final newline is not a line of its own
+        {"a\n", "a", " a"},
+        // Blank and repeated lines
+        {"a\n\nb\na", "a\nb\n\na", " a\n-\n b\n+\n a"},
+    }
+
+    for _, test := range tests {
+        diff := formatTestDiff(getLineDiff(splitLines(test.old), splitLines(test.new)))
+        if diff != test.want {
+            t.Errorf("getLineDiff(%q, %q) =\n%s\nwant:\n%s", test.old, test.new, diff, test.want)
+        }
+    }
+}
+
+func TestGetLineDiffShortest(t *testing.T) {
+    oldLines := strings.Split("a b c a b b a", " ")
+    newLines := strings.Split("c b a b a c", " ")
+
+    diff := getLineDiff(oldLines, newLines)
+
+    // The diff restores both files, with the 5 edits of the shortest edit script
+    var restoredOld, restoredNew []string
+    edits := 0
+    for _, line := range diff {
+        if line.op != '+' {
+            restoredOld = append(restoredOld, line.text)
+        }
+        if line.op != '-' {
+            restoredNew = append(restoredNew, line.text)
+        }
+        if line.op != ' ' {
+            edits++
+        }
+    }
+    if !reflect.DeepEqual(restoredOld, oldLines) || !reflect.DeepEqual(restoredNew, newLines) {
+        t.Errorf("getLineDiff(%q, %q) =\n%s\ndoes not restore both files", oldLines, newLines, formatTestDiff(diff))
+    }
+    if edits != 5 {
+        t.Errorf("getLineDiff(%q, %q) has %d edits, want 5", oldLines, newLines, edits)
+    }
+}
+
+func TestGetLineDiffMostlyDifferent(t *testing.T) {
+    var oldLines, newLines []string
+    for i := 0; i < maxDiffEdits; i++ {
+        oldLines = append(oldLines, fmt.Sprintf("old %d", i))
+        newLines = append(newLines, fmt.Sprintf("new %d", i))
+    }
+    oldLines = append([]string{"first"}, append(oldLines, "same", "last")...)
+    newLines = append([]string{"first"}, append(newLines, "same", "last")...)
+
+    // Beyond maxDiffEdits, the lines between the common start and end are all removed and then all added
+    diff := getLineDiff(oldLines, newLines)
+    if len(diff) != 2 * maxDiffEdits + 3 || diff[0] != (diffLine{' ', "first"}) || diff[1] != (diffLine{'-', "old 0"}) ||
+            diff[maxDiffEdits + 1] != (diffLine{'+', "new 0"}) || diff[len(diff) - 2] != (diffLine{' ', "same"}) {
+        t.Errorf("getLineDiff() of files with %d different lines has %d lines, want %d", maxDiffEdits, len(diff),
+            2 * maxDiffEdits + 3)
+    }
+}
+
+func TestPrintUnifiedDiff(t *testing.T) {
+    var oldLines []string
+    for i := 1; i <= 20; i++ {
+        oldLines = append(oldLines, fmt.Sprint(i))
+    }
+    newLines := append([]string{}, oldLines...)
+    newLines[1] = "two"
+    newLines = append(newLines[:15], newLines[16:]...)
+
+    var differ bool
+    stdout := captureOutput(t, func() { differ = printUnifiedDiff("a", "a.js", getLineDiff(oldLines, newLines)) })
+
+    want := "--- a\n+++ a.js\n" +
+        "@@ -1,5 +1,5 @@\n 1\n-2\n+two\n 3\n 4\n 5\n" +
+        "@@ -13,7 +13,6 @@\n 13\n 14\n 15\n-16\n 17\n 18\n 19\n"
+    if !differ || stdout != want {
+        t.Errorf("printUnifiedDiff() = %t, printed:\n%s\nwant:\n%s", differ, stdout, want)
+    }
+
+    stdout = captureOutput(t, func() { differ = printUnifiedDiff("a", "a.js", getLineDiff(oldLines, oldLines)) })
+    if differ || len(stdout) > 0 {
+        t.Errorf("printUnifiedDiff() of no changes = %t, printed %q; want false and nothing", differ, stdout)
+    }
+
+    // Lines added to an empty file are numbered from 0
+    stdout = captureOutput(t, func() { printUnifiedDiff("a", "a.js", getLineDiff(nil, []string{"x"})) })
+    if want = "--- a\n+++ a.js\n@@ -0,0 +1,1 @@\n+x\n"; stdout != want {
+        t.Errorf("printUnifiedDiff() of an empty file printed %q, want %q", stdout, want)
+    }
+}
+
+// makeTestTree creates the files in a new temporary directory, creating their directories, and returns its path.
+// An empty content makes a directory.
+func makeTestTree(t *testing.T, files map[string]string) string {
+    dir, err := ioutil.TempDir("", "wsk")
+    if err != nil {
+        t.Fatalf("ioutil.TempDir() failed: %s", err)
+    }
+
+    for name, content := range files {
+        file := filepath.Join(dir, filepath.FromSlash(name))
+        if len(content) == 0 {
+            err = os.MkdirAll(file, 0755)
+        } else if err = os.MkdirAll(filepath.Dir(file), 0755); err == nil {
+            err = ioutil.WriteFile(file, []byte(content), 0644)
+        }
+        if err != nil {
+            os.RemoveAll(dir)
+            t.Fatalf("creating %s failed: %s", file, err)
+        }
+    }
+
+    return dir
+}
+
+// getTestZipEntries returns the names and contents of the files in a zip archive
+func getTestZipEntries(t *testing.T, archive []byte) ([]string, map[string]string) {
+    reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+    if err != nil {
+        t.Fatalf("zip.NewReader() failed: %s", err)
+    }
+
+    var names []string
+    contents := map[string]string{}
+    for _, file := range reader.File {
+        names = append(names, file.Name)
+        if data, err := file.Open(); err == nil {
+            content, _ := ioutil.ReadAll(data)
+            contents[file.Name] = string(content)
+            data.Close()
+        }
+    }
+
+    return names, contents
+}
+
+func TestZipDirectory(t *testing.T) {
+    dir := makeTestTree(t, map[string]string{
+        "index.js": "exports.main = require('./lib/util').main;\n",
+        "package.json": `{"main": "index.js"}`,
+        "lib/util.js": "exports.main = () => ({});\n",
+        "node_modules/dep/index.js": "module.exports = {};\n",
+        ".git/HEAD": "ref: refs/heads/master\n",
+        ".wskignore": "# left out of the archive\n*.env\nlogs/\n/build\n\ndangling\n",
+        "secret.env": "KEY=value\n",
+        "logs/out.log": "started\n",
+        "build/out.js": "built\n",
+        "sub/build/keep.js": "kept\n",
+        "empty": "",
+        "sub/empty": "",
+    })
+    defer os.RemoveAll(dir)
+
+    // A symbolic link to a directory is followed, and an ignored one is not
+    os.Symlink(filepath.Join(dir, "lib"), filepath.Join(dir, "linked"))
+    os.Symlink(filepath.Join(dir, "missing"), filepath.Join(dir, "dangling"))
+
+    archive, err := zipDirectory(dir)
+    if err != nil {
+        t.Fatalf("zipDirectory() failed: %s", err)
+    }
+
+    names, contents := getTestZipEntries(t, archive)
+    want := []string{"index.js", "lib/util.js", "linked/util.js", "node_modules/dep/index.js", "package.json",
+        "sub/build/keep.js"}
+    if !reflect.DeepEqual(names, want) {
+        t.Errorf("zipDirectory() has the entries %q, want %q", names, want)
+    }
+    if contents["linked/util.js"] != "exports.main = () => ({});\n" || contents["package.json"] != `{"main": "index.js"}` {
+        t.Errorf("zipDirectory() has the contents %q", contents)
+    }
+}
+
+func TestZipDirectoryErrors(t *testing.T) {
+    dir := makeTestTree(t, map[string]string{"a/index.js": "x\n"})
+    defer os.RemoveAll(dir)
+
+    os.Symlink(filepath.Join(dir, "a"), filepath.Join(dir, "a", "loop"))
+    if _, err := zipDirectory(dir); err == nil || !strings.Contains(err.Error(), "symbolic link cycle") {
+        t.Errorf("zipDirectory() of a directory with a link cycle error = %v, want a cycle error", err)
+    }
+
+    os.Remove(filepath.Join(dir, "a", "loop"))
+    os.Symlink(filepath.Join(dir, "missing"), filepath.Join(dir, "dangling"))
+    if _, err := zipDirectory(dir); err == nil || !strings.Contains(err.Error(), "dangling") {
+        t.Errorf("zipDirectory() of a directory with a dangling link error = %v, want an error about it", err)
+    }
+}
+
+func TestIsIgnoredPath(t *testing.T) {
+    tests := []struct {
+        name    string
+        isDir   bool
+        pattern string
+        want    bool
+    }{
+        {"secret.env", false, "*.env", true},
+        {"config/secret.env", false, "*.env", true},
+        {"config/secret.env", false, "config/*.env", true},
+        {"secret.envrc", false, "*.env", false},
+        {"logs", true, "logs/", true},
+        {"logs", false, "logs/", false},
+        {"a/logs", true, "logs/", true},
+        {"build", true, "/build", true},
+        {"sub/build", true, "/build", false},
+        {"sub/build", true, "build", true},
+        {"README.md", false, "*.env", false},
+    }
+
+    for _, test := range tests {
+        if ignored := isIgnoredPath(test.name, test.isDir, []string{test.pattern}); ignored != test.want {
+            t.Errorf("isIgnoredPath(%q, %t, %q) = %t, want %t", test.name, test.isDir, test.pattern, ignored, test.want)
+        }
+    }
+}
+
+func TestSetColorMode(t *testing.T) {
+    origNoColor, origAutoNoColor := color.NoColor, autoNoColor
+    origNoColorEnv, hasNoColorEnv := os.LookupEnv("NO_COLOR")
+    defer func() {
+        color.NoColor, autoNoColor = origNoColor, origAutoNoColor
+        if hasNoColorEnv {
+            os.Setenv("NO_COLOR", origNoColorEnv)
+        } else {
+            os.Unsetenv("NO_COLOR")
+        }
+    }()
+
+    tests := []struct {
+        mode        string
+        terminal    bool    // whether standard output is a terminal
+        noColorEnv  string
+        want        bool    // whether color is off
+    }{
+        {"auto", true, "", false},
+        {"auto", false, "", true},
+        {"auto", true, "1", true},
+        {"always", false, "1", false},
+        {"always", true, "", false},
+        {"never", true, "", true},
+        {"never", false, "", true},
+    }
+
+    for _, test := range tests {
+        autoNoColor = !test.terminal
+        os.Setenv("NO_COLOR", test.noColorEnv)
+        color.NoColor = !test.want
+
+        if err := setColorMode(test.mode); err != nil {
+            t.Errorf("setColorMode(%q) failed: %s", test.mode, err)
+        } else if color.NoColor != test.want {
+            t.Errorf("setColorMode(%q) with a terminal %t and NO_COLOR=%q turned color off: %t, want %t", test.mode,
+                test.terminal, test.noColorEnv, color.NoColor, test.want)
+        }
+    }
+
+    for _, mode := range []string{"", "on", "Always"} {
+        err := setColorMode(mode)
+        if whiskErr, ok := err.(*whisk.WskError); !ok || whiskErr.ExitCode != whisk.EXITCODE_ERR_USAGE ||
+                !strings.Contains(whiskErr.Error(), "Invalid color mode") {
+            t.Errorf("setColorMode(%q) error = %#v, want a usage error", mode, err)
+        }
+    }
+}
+
+func TestColorModeOutput(t *testing.T) {
+    origAutoNoColor := autoNoColor
+    defer func() { autoNoColor = origAutoNoColor }()
+    autoNoColor = true
+
+    for mode, colored := range map[string]bool{"always": true, "never": false, "auto": false} {
+        server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+            writeJSON(w, http.StatusOK, map[string]interface{}{})
+        })
+
+        var err error
+        stdout := captureOutput(t, func() {
+            if err = setColorMode(mode); err == nil {
+                err = actionDeleteCmd.RunE(actionDeleteCmd, []string{"/ns/a"})
+            }
+        })
+        server.Close()
+
+        // Both the green ok: and the bold name are colored through the color package
+        if err != nil {
+            t.Errorf("action delete with --color %s failed: %s", mode, err)
+        } else if hasEscapes := strings.Contains(stdout, "\x1b["); hasEscapes != colored {
+            t.Errorf("action delete with --color %s printed %q; want escape sequences: %t", mode, stdout, colored)
+        } else if colored && (!strings.Contains(stdout, "\x1b[32mok:") || !strings.Contains(stdout, "\x1b[1ma")) {
+            t.Errorf("action delete with --color %s printed %q, want a green ok: and a bold name", mode, stdout)
+        }
+    }
+}
+
+func TestListNameSort(t *testing.T) {
+    binding := &whisk.Binding{Namespace: "ns", Name: "Alpha"}
+    triggers := []whisk.Trigger{{Namespace: "ns", Name: "beta"}, {Namespace: "ns", Name: "Alpha"}, {Namespace: "ns", Name: "alpha2"}}
+    packages := []whisk.Package{{Namespace: "ns", Name: "beta", Binding: binding}, {Namespace: "ns", Name: "Alpha"},
+        {Namespace: "ns", Name: "alpha2"}}
+    // Rules of other namespaces sort after those of ns, whatever the case of their names
+    rules := []whisk.Rule{{Namespace: "ns2", Name: "a"}, {Namespace: "ns", Name: "myrule2"}, {Namespace: "ns", Name: "zeta"},
+        {Namespace: "ns", Name: "MyRule"}, {Namespace: "ns", Name: "beta"}}
+
+    tests := []struct {
+        cmd      *cobra.Command
+        entities interface{}
+        nameSort *bool
+        sorted   bool
+        want     []string
+    }{
+        {triggerListCmd, triggers, &flags.trigger.nameSort, true, []string{"/ns/Alpha", "/ns/alpha2", "/ns/beta"}},
+        {triggerListCmd, triggers, &flags.trigger.nameSort, false, []string{"/ns/beta", "/ns/Alpha", "/ns/alpha2"}},
+        {packageListCmd, packages, &flags.xPackage.nameSort, true, []string{"/ns/Alpha", "/ns/alpha2", "/ns/beta"}},
+        {packageListCmd, packages, &flags.xPackage.nameSort, false, []string{"/ns/beta", "/ns/Alpha", "/ns/alpha2"}},
+        {ruleListCmd, rules, &flags.rule.nameSort, true, []string{"/ns/beta", "/ns/MyRule", "/ns/myrule2", "/ns/zeta", "/ns2/a"}},
+        {ruleListCmd, rules, &flags.rule.nameSort, false, []string{"/ns2/a", "/ns/myrule2", "/ns/zeta", "/ns/MyRule", "/ns/beta"}},
+    }
+
+    for _, test := range tests {
+        server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+            writeJSON(w, http.StatusOK, test.entities)
+        })
+        flags.common.limit = 30
+        *test.nameSort = test.sorted
+
+        var err error
+        stdout := captureOutput(t, func() { err = test.cmd.RunE(test.cmd, []string{}) })
+        server.Close()
+
+        if err != nil {
+            t.Errorf("%s --name-sort=%t failed: %s", test.cmd.CommandPath(), test.sorted, err)
+            continue
+        }
+
+        var names []string
+        for _, line := range strings.Split(strings.TrimSpace(stdout), "\n")[1:] {
+            names = append(names, strings.Fields(line)[0])
+        }
+        if !reflect.DeepEqual(names, test.want) {
+            t.Errorf("%s --name-sort=%t listed %q, want %q", test.cmd.CommandPath(), test.sorted, names, test.want)
+        }
+        if test.cmd == packageListCmd && !regexp.MustCompile(`/ns/beta +private +binding\n`).MatchString(stdout) {
+            t.Errorf("%s printed:\n%s\nwant the binding /ns/beta marked", test.cmd.CommandPath(), stdout)
+        }
+    }
+}
+
+// pagedListHandler answers list requests of any collection with count entities named e0, e1, ..., paged by the limit
+// and skip query parameters. Pages that start at failSkip fail.
+func pagedListHandler(count int, failSkip int) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+        skip, _ := strconv.Atoi(r.URL.Query().Get("skip"))
+        if skip == failSkip {
+            writeJSON(w, http.StatusInternalServerError, map[string]interface{}{"error": "list failed", "code": 1})
+            return
+        }
+
+        page := []interface{}{}
+        for i := skip; i < count && i < skip + limit; i++ {
+            page = append(page, map[string]interface{}{"namespace": "ns", "name": fmt.Sprintf("e%d", i)})
+        }
+        writeJSON(w, http.StatusOK, page)
+    }
+}
+
+func TestListAll(t *testing.T) {
+    tests := []struct {
+        cmd         *cobra.Command
+        nameSort    *bool
+        sorted      bool
+        failSkip    int
+        requests    int
+        first       string  // the first and last listed entities
+        last        string
+        warning     bool    // whether a warning that the listing stopped is printed
+    }{
+        {ruleListCmd, &flags.rule.nameSort, false, -1, 3, "/ns/e0", "/ns/e449", false},
+        {triggerListCmd, &flags.trigger.nameSort, false, -1, 3, "/ns/e0", "/ns/e449", false},
+        {packageListCmd, &flags.xPackage.nameSort, false, -1, 3, "/ns/e0", "/ns/e449", false},
+        // The entities of every page are sorted together
+        {ruleListCmd, &flags.rule.nameSort, true, -1, 3, "/ns/e0", "/ns/e99", false},
+        {triggerListCmd, &flags.trigger.nameSort, true, -1, 3, "/ns/e0", "/ns/e99", false},
+        {packageListCmd, &flags.xPackage.nameSort, true, -1, 3, "/ns/e0", "/ns/e99", false},
+        // A failed page keeps the pages before it
+        {ruleListCmd, &flags.rule.nameSort, false, 400, 3, "/ns/e0", "/ns/e399", true},
+        {triggerListCmd, &flags.trigger.nameSort, false, 200, 2, "/ns/e0", "/ns/e199", true},
+        {packageListCmd, &flags.xPackage.nameSort, true, 200, 2, "/ns/e0", "/ns/e99", true},
+    }
+
+    for _, test := range tests {
+        server := newTestServer(t, pagedListHandler(450, test.failSkip))
+        flags.common.all = true
+        flags.common.limit = 30
+        *test.nameSort = test.sorted
+
+        var err error
+        var stdout string
+        stderr := captureStderr(t, func() {
+            stdout = captureOutput(t, func() { err = test.cmd.RunE(test.cmd, []string{}) })
+        })
+        requests := server.getRequests()
+        server.Close()
+
+        description := fmt.Sprintf("%s --all --name-sort=%t with pages failing at %d", test.cmd.CommandPath(),
+            test.sorted, test.failSkip)
+        if err != nil {
+            t.Errorf("%s failed: %s", description, err)
+            continue
+        }
+
+        // --all pages by the largest page size instead of --limit
+        if len(requests) != test.requests {
+            t.Errorf("%s sent %d requests, want %d: %q", description, len(requests), test.requests, requests)
+        }
+        for i, request := range server.requests {
+            if skip, limit := request.Query.Get("skip"), request.Query.Get("limit"); skip != strconv.Itoa(i * 200) || limit != "200" {
+                t.Errorf("%s request %d has skip %s and limit %s", description, i, skip, limit)
+            }
+        }
+
+        var names []string
+        for _, line := range strings.Split(stdout, "\n") {
+            if strings.HasPrefix(line, "/ns/") {
+                names = append(names, strings.Fields(line)[0])
+            }
+        }
+        if len(names) == 0 || names[0] != test.first || names[len(names) - 1] != test.last {
+            t.Errorf("%s listed %d entities, want %s to %s", description, len(names), test.first, test.last)
+        }
+        if warned := strings.Contains(stderr, "warning: listing stopped after"); warned != test.warning {
+            t.Errorf("%s printed the warning %q; want a warning: %t", description, stderr, test.warning)
+        }
+    }
+}
+
+func TestMergeAndDeleteKeyValues(t *testing.T) {
+    existing := whisk.KeyValueArr{{Key: "a", Value: 1}, {Key: "b", Value: "old"}, {Key: "c", Value: true}}
+
+    tests := []struct {
+        existing    whisk.KeyValueArr
+        changed     whisk.KeyValueArr
+        deleted     []string
+        want        whisk.KeyValueArr
+    }{
+        {existing, nil, nil, existing},
+        // Changed values replace existing ones in place, and new ones are appended
+        {existing, whisk.KeyValueArr{{Key: "d", Value: 4}, {Key: "b", Value: "new"}}, nil,
+            whisk.KeyValueArr{{Key: "a", Value: 1}, {Key: "b", Value: "new"}, {Key: "c", Value: true}, {Key: "d", Value: 4}}},
+        {existing, whisk.KeyValueArr{{Key: "d", Value: 4}}, []string{"a", "c"},
+            whisk.KeyValueArr{{Key: "b", Value: "old"}, {Key: "d", Value: 4}}},
+        // Deleting a key that does not exist changes nothing
+        {existing, nil, []string{"missing"}, existing},
+        // Deleting every key leaves an empty array rather than nil
+        {existing, nil, []string{"a", "b", "c"}, whisk.KeyValueArr{}},
+        {nil, nil, []string{"a"}, whisk.KeyValueArr{}},
+        {nil, whisk.KeyValueArr{{Key: "a", Value: 1}}, nil, whisk.KeyValueArr{{Key: "a", Value: 1}}},
+    }
+
+    for _, test := range tests {
+        orig := append(whisk.KeyValueArr(nil), test.existing...)
+        res := mergeAndDeleteKeyValues(test.existing, test.changed, test.deleted, "parameter", "a")
+        if !reflect.DeepEqual(res, test.want) {
+            t.Errorf("mergeAndDeleteKeyValues(%v, %v, %q) = %#v, want %#v", test.existing, test.changed, test.deleted,
+                res, test.want)
+        }
+        if !reflect.DeepEqual(test.existing, orig) {
+            t.Errorf("mergeAndDeleteKeyValues(%v, %v, %q) changed the existing key values", orig, test.changed, test.deleted)
+        }
+    }
+}
+
+func TestCheckDeletedKeys(t *testing.T) {
+    tests := []struct {
+        keyValueArgs    []string
+        deleted         []string
+        conflict        string
+    }{
+        {nil, []string{"a"}, ""},
+        {[]string{`{"a": 1}`}, nil, ""},
+        {[]string{`{"a": 1}`, `{"b": "x"}`}, []string{"c"}, ""},
+        {[]string{`{"a": 1}`, `{"b": "x"}`}, []string{"c", "b"}, "b"},
+        {[]string{`{"a": null}`}, []string{"a"}, "a"},
+        // Invalid arguments are reported when they are parsed
+        {[]string{`{"a": `}, []string{"a"}, ""},
+    }
+
+    for _, test := range tests {
+        err := checkDeletedKeys(test.keyValueArgs, test.deleted, "--del-param")
+        if len(test.conflict) == 0 && err != nil {
+            t.Errorf("checkDeletedKeys(%q, %q) failed: %s", test.keyValueArgs, test.deleted, err)
+        } else if want := "The key '" + test.conflict + "' cannot be both set and deleted with --del-param";
+                len(test.conflict) > 0 && (err == nil || err.Error() != want) {
+            t.Errorf("checkDeletedKeys(%q, %q) error = %v, want %q", test.keyValueArgs, test.deleted, err, want)
+        }
+    }
+}
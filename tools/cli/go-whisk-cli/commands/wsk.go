@@ -17,7 +17,11 @@
 package commands
 
 import (
+    "time"
+
     "github.com/spf13/cobra"
+
+    "../../go-whisk/whisk"
     "../wski18n"
 )
 
@@ -51,16 +55,30 @@ func init() {
         triggerCmd,
         sdkCmd,
         propertyCmd,
+        configCmd,
+        completionCmd,
         namespaceCmd,
         listCmd,
         apiExperimentalCmd,
         apiCmd,
+        deployCmd,
+        undeployCmd,
     )
 
     WskCmd.PersistentFlags().BoolVarP(&flags.global.verbose, "verbose", "v", false, wski18n.T("verbose output"))
     WskCmd.PersistentFlags().BoolVarP(&flags.global.debug, "debug", "d", false, wski18n.T("debug level output"))
+    WskCmd.PersistentFlags().StringVar(&flags.global.debugFile, "debug-file", "", wski18n.T("write debug output to `FILE` instead of standard output"))
     WskCmd.PersistentFlags().StringVarP(&flags.global.auth, "auth", "u", "", wski18n.T("authorization `KEY`"))
     WskCmd.PersistentFlags().StringVar(&flags.global.apihost, "apihost", "", wski18n.T("whisk API `HOST`"))
     WskCmd.PersistentFlags().StringVar(&flags.global.apiversion, "apiversion", "", wski18n.T("whisk API `VERSION`"))
     WskCmd.PersistentFlags().BoolVarP(&flags.global.insecure, "insecure", "i", false, wski18n.T("bypass certificate checking"))
+    WskCmd.PersistentFlags().StringVar(&flags.global.tlsCert, "tls-cert", "", wski18n.T("PEM `FILE` with the client certificate presented to the API host; requires --tls-key"))
+    WskCmd.PersistentFlags().StringVar(&flags.global.tlsKey, "tls-key", "", wski18n.T("PEM `FILE` with the private key of the client certificate"))
+    WskCmd.PersistentFlags().StringVar(&flags.global.tlsCA, "tls-ca", "", wski18n.T("PEM `FILE` of certificate authorities trusted to identify the API host along with the system ones; used instead of the CERT property"))
+    WskCmd.PersistentFlags().StringVarP(&flags.global.output, "output", "o", "", wski18n.T("print command output in the given `FORMAT`; json | yaml"))
+    WskCmd.PersistentFlags().StringVar(&flags.global.profile, "profile", "", wski18n.T("use the properties of profile `NAME`, kept in ~/.wskprops.NAME; also set by WSK_PROFILE"))
+    WskCmd.PersistentFlags().IntVar(&flags.global.connectTimeout, "connect-timeout", int(whisk.DefaultDialTimeout / time.Second), wski18n.T("the number of `SECONDS` allowed to connect to the API host; 0 for no limit"))
+    WskCmd.PersistentFlags().IntVar(&flags.global.requestTimeout, "request-timeout", int(whisk.DefaultRequestTimeout / time.Second), wski18n.T("the number of `SECONDS` allowed for each request, other than a blocking invocation; 0 for no limit"))
+    WskCmd.PersistentFlags().StringVar(&flags.global.color, "color", colorModeAuto, wski18n.T("when to color the output; `MODE` is auto, always or never. In auto mode, color is used when standard output is a terminal and NO_COLOR is not set"))
+    WskCmd.PersistentFlags().IntVar(&flags.global.retries, "retries", 0, wski18n.T("retry requests up to `COUNT` times when the server is busy or unavailable, or on transient network failures"))
 }
@@ -17,13 +17,24 @@
 package whisk
 
 import (
+    "context"
+    "encoding/json"
     "fmt"
+    "io/ioutil"
     "net/http"
     "errors"
     "net/url"
+    "strings"
+    "time"
     "../wski18n"
 )
 
+// Longest time limit that an action may have
+const MaxActionTimeout = 5 * time.Minute
+
+// Time allowed beyond the time limit of an action for a blocking invocation to return
+const BlockingInvokeMargin = 30 * time.Second
+
 type ActionService struct {
     client *Client
 }
@@ -54,13 +65,74 @@ type ActionListOptions struct {
     Limit       int         `url:"limit"`
     Skip        int         `url:"skip"`
     Docs        bool        `url:"docs,omitempty"`
+    Kind        string      `url:"kind,omitempty"`
+    Name        string      `url:"name,omitempty"`
+}
+
+type InvokeOptions struct {
+    Blocking    bool
+    Result      bool
+    Timeout     int     // milliseconds; the server default is used when zero
+    ActionTimeout time.Duration // time limit of the action, which bounds how long a blocking invocation is waited
+                                // for; MaxActionTimeout when zero
+}
+
+// Header in which a web action that requires authentication receives its secret
+const RequireWhiskAuthHeader = "X-Require-Whisk-Auth"
+
+// WebInvokeOptions are the options of a web action invocation
+type WebInvokeOptions struct {
+    Method      string      // HTTP method of the request; GET when empty
+    Body        interface{} // request body, encoded as JSON; none when nil
+    Query       url.Values  // query parameters of the request
+    AuthToken   string      // secret sent in the RequireWhiskAuthHeader; not sent when empty
+}
+
+// Compare orders actions by namespace and then name, ignoring case.
+// The sortable argument must also be an Action.
+func (action Action) Compare(sortable Sortable) bool {
+    actionToCompare := sortable.(Action)
+    actionString := strings.ToLower(fmt.Sprintf("%s/%s", action.Namespace, action.Name))
+    compareString := strings.ToLower(fmt.Sprintf("%s/%s", actionToCompare.Namespace, actionToCompare.Name))
+
+    return actionString < compareString
+}
+
+// SupportedRuntimes lists the action kinds accepted by Insert. It can be replaced by LoadSupportedRuntimes to match
+// the runtimes deployed on a particular server; when it is empty, any kind is accepted.
+var SupportedRuntimes = []string{
+    "nodejs", "nodejs:6", "nodejs:default",
+    "python", "python:2", "python:3", "python:default",
+    "swift", "swift:3", "swift:3.1.1", "swift:default",
+    "java", "java:default",
+}
+
+// ListString returns the action formatted as a row of the action list
+func (action Action) ListString() string {
+    return fmt.Sprintf("%-70s %s %s\n", fmt.Sprintf("/%s/%s", action.Namespace, action.Name), wski18n.T("private"),
+        action.GetKind())
+}
+
+// Columns returns the action as the columns of an action list row: its fully qualified name, publish state and kind
+func (action Action) Columns() []string {
+    return []string{fmt.Sprintf("/%s/%s", action.Namespace, action.Name), wski18n.T("private"), action.GetKind()}
+}
+
+// GetKind returns the runtime kind of a listed action, which is reported in its "exec" annotation
+func (action Action) GetKind() string {
+    value, _ := action.Annotations.GetValue("exec")
+    kind, _ := value.(string)
+
+    return kind
 }
 
 ////////////////////
 // Action Methods //
 ////////////////////
 
-func (s *ActionService) List(packageName string, options *ActionListOptions) ([]Action, *http.Response, error) {
+// List lists the actions in the namespace or in a package. It also returns the number of actions in the collection,
+// from the X-Total-Count response header, or -1 when the server does not report it.
+func (s *ActionService) List(packageName string, options *ActionListOptions) ([]Action, int, *http.Response, error) {
     var route string
     var actions []Action
 
@@ -80,7 +152,7 @@ func (s *ActionService) List(packageName string, options *ActionListOptions) ([]
             map[string]interface{}{"options": options})
         whiskErr := MakeWskErrorFromWskError(errors.New(errMsg), err, EXITCODE_ERR_GENERAL, DISPLAY_MSG,
             NO_DISPLAY_USAGE)
-        return nil, nil, whiskErr
+        return nil, -1, nil, whiskErr
     }
     Debug(DbgError, "Action list route with options: %s\n", route)
 
@@ -91,26 +163,202 @@ func (s *ActionService) List(packageName string, options *ActionListOptions) ([]
             map[string]interface{}{"route": routeUrl, "err": err})
         whiskErr := MakeWskErrorFromWskError(errors.New(errMsg), err, EXITCODE_ERR_NETWORK, DISPLAY_MSG,
             NO_DISPLAY_USAGE)
-        return nil, nil, whiskErr
+        return nil, -1, nil, whiskErr
     }
 
     resp, err := s.client.Do(req, &actions, ExitWithSuccessOnTimeout)
     if err != nil {
         Debug(DbgError, "s.client.Do() error - HTTP req %s; error '%s'\n", req.URL.String(), err)
-        return nil, resp, err
+        return nil, -1, resp, err
     }
 
-    return actions, resp, err
+    return actions, getTotalCount(resp), resp, err
+}
+
+// ListAll lists every action in the namespace or in a package, fetching MaxListPageSize actions at a time with
+// ListAllPages. The result is allocated at the size the X-Total-Count header reports, up to MaxListCapacity, when it is
+// available. When a page cannot be fetched, the actions of the earlier pages are returned with the error.
+func (s *ActionService) ListAll(packageName string) ([]Action, error) {
+    return s.ListAllWithOptions(packageName, nil)
+}
+
+// ListAllWithOptions lists every action like ListAll, sending the other options, such as Kind and Name, with the request of each
+// page. The Limit and Skip of options are ignored. Servers may ignore some options, so callers that need their filter
+// applied must still check the actions returned.
+func (s *ActionService) ListAllWithOptions(packageName string, options *ActionListOptions) ([]Action, error) {
+    var actions []Action
+    pageOptions := ActionListOptions{}
+    if options != nil {
+        pageOptions = *options
+    }
+
+    _, err := ListAllPages(0, func(limit int, skip int) (int, *http.Response, error) {
+        pageOptions.Limit, pageOptions.Skip = limit, skip
+        page, total, resp, err := s.List(packageName, &pageOptions)
+        if actions == nil && total > 0 {
+            actions = make([]Action, 0, getListCapacity(total))
+        }
+        actions = append(actions, page...)
+        return len(page), resp, err
+    })
+
+    if err != nil {
+        Debug(DbgError, "Listing all actions of '%s' failed after %d actions: %s\n", packageName, len(actions), err)
+    }
+
+    return actions, err
 }
 
 func (s *ActionService) Insert(action *Action, overwrite bool) (*Action, *http.Response, error) {
+    return s.insert(context.Background(), action, overwrite, "", true)
+}
+
+// InsertWithContext inserts an action; the request is abandoned when ctx is cancelled or its deadline passes
+func (s *ActionService) InsertWithContext(ctx context.Context, action *Action, overwrite bool) (*Action, *http.Response, error) {
+    return s.insert(ctx, action, overwrite, "", true)
+}
+
+// InsertIfMatch inserts an action. When ifMatch is not empty, the request carries it as an If-Match header so the
+// server rejects the update with 412 Precondition Failed if the action has changed since it was fetched.
+func (s *ActionService) InsertIfMatch(action *Action, overwrite bool, ifMatch string) (*Action, *http.Response, error) {
+    return s.insert(context.Background(), action, overwrite, ifMatch, true)
+}
+
+// InsertAnyKind inserts an action without checking its kind against SupportedRuntimes, so that the server decides
+// whether the kind is available; the rest of the exec is still validated
+func (s *ActionService) InsertAnyKind(action *Action, overwrite bool) (*Action, *http.Response, error) {
+    return s.insert(context.Background(), action, overwrite, "", false)
+}
+
+// LoadSupportedRuntimes replaces SupportedRuntimes with the kinds listed in a JSON file. The file holds either an array
+// of kinds or a runtimes manifest, an object whose "runtimes" member maps each runtime family to a list of objects
+// with a "kind" member.
+func LoadSupportedRuntimes(filename string) error {
+    var content interface{}
+    var runtimes []string
+
+    data, err := ioutil.ReadFile(filename)
+    if err == nil {
+        err = json.Unmarshal(data, &content)
+    }
+    if err != nil {
+        Debug(DbgError, "Unable to read runtimes file '%s': %s\n", filename, err)
+        errMsg := wski18n.T("Unable to read the runtimes file '{{.name}}': {{.err}}",
+            map[string]interface{}{"name": filename, "err": err})
+        return MakeWskError(errors.New(errMsg), EXITCODE_ERR_GENERAL, DISPLAY_MSG, NO_DISPLAY_USAGE)
+    }
+
+    if manifest, ok := content.(map[string]interface{}); ok {
+        content = manifest["runtimes"]
+    }
+
+    switch content := content.(type) {
+    case []interface{}:
+        for _, kind := range content {
+            if kind, ok := kind.(string); ok {
+                runtimes = append(runtimes, kind)
+            }
+        }
+    case map[string]interface{}:
+        for _, family := range content {
+            family, _ := family.([]interface{})
+            for _, runtime := range family {
+                runtime, _ := runtime.(map[string]interface{})
+                if kind, ok := runtime["kind"].(string); ok {
+                    runtimes = append(runtimes, kind)
+                }
+            }
+        }
+    }
+
+    if len(runtimes) == 0 {
+        Debug(DbgError, "No runtime kinds found in '%s'\n", filename)
+        errMsg := wski18n.T("The runtimes file '{{.name}}' does not list any runtime kinds",
+            map[string]interface{}{"name": filename})
+        return MakeWskError(errors.New(errMsg), EXITCODE_ERR_GENERAL, DISPLAY_MSG, NO_DISPLAY_USAGE)
+    }
+
+    Debug(DbgInfo, "Supported runtimes loaded from '%s': %v\n", filename, runtimes)
+    SupportedRuntimes = runtimes
+
+    return nil
+}
+
+// validateAction checks the exec of an action before it is sent, so that a missing or unknown kind is reported
+// clearly rather than by a server error. Blackbox actions need an image, sequences need components and every other
+// kind needs code. The kind is checked against SupportedRuntimes only when checkKind is set.
+func validateAction(action *Action, checkKind bool) error {
+    var errMsg string
+
+    if action.Exec == nil {
+        errMsg = wski18n.T("The action '{{.name}}' has no exec.", map[string]interface{}{"name": action.Name})
+    } else if action.Exec.Kind == "blackbox" {
+        if len(action.Exec.Image) == 0 {
+            errMsg = wski18n.T("The blackbox action '{{.name}}' requires an image.",
+                map[string]interface{}{"name": action.Name})
+        }
+    } else if len(action.Exec.Image) > 0 {
+        errMsg = wski18n.T("The action '{{.name}}' has an image, so its kind must be blackbox instead of '{{.kind}}'.",
+            map[string]interface{}{"name": action.Name, "kind": action.Exec.Kind})
+    } else if action.Exec.Kind == "sequence" {
+        if len(action.Exec.Components) == 0 {
+            errMsg = wski18n.T("The sequence action '{{.name}}' requires at least one component.",
+                map[string]interface{}{"name": action.Name})
+        }
+    } else if len(action.Exec.Kind) == 0 {
+        errMsg = wski18n.T("The action '{{.name}}' has no kind.", map[string]interface{}{"name": action.Name})
+    } else if checkKind && !IsSupportedRuntime(action.Exec.Kind) {
+        errMsg = wski18n.T("The kind '{{.kind}}' of action '{{.name}}' is not supported; supported kinds are: {{.kinds}}",
+            map[string]interface{}{
+                "kind": action.Exec.Kind,
+                "name": action.Name,
+                "kinds": strings.Join(append(SupportedRuntimes, "blackbox", "sequence"), ", "),
+            })
+    } else if action.Exec.Code == nil {
+        errMsg = wski18n.T("The action '{{.name}}' of kind '{{.kind}}' requires code.",
+            map[string]interface{}{"name": action.Name, "kind": action.Exec.Kind})
+    }
+
+    if len(errMsg) > 0 {
+        Debug(DbgError, "Invalid action %#v: %s\n", action, errMsg)
+        return MakeWskError(errors.New(errMsg), EXITCODE_ERR_GENERAL, DISPLAY_MSG, NO_DISPLAY_USAGE)
+    }
+
+    return nil
+}
+
+// IsSupportedRuntime reports whether actions of the given kind can be inserted: the kind is blackbox, sequence or one
+// of SupportedRuntimes
+func IsSupportedRuntime(kind string) bool {
+    if len(SupportedRuntimes) == 0 || kind == "blackbox" || kind == "sequence" {
+        return true
+    }
+
+    for _, runtime := range SupportedRuntimes {
+        if kind == runtime {
+            return true
+        }
+    }
+
+    return false
+}
+
+func (s *ActionService) insert(ctx context.Context, action *Action, overwrite bool, ifMatch string, checkKind bool) (*Action, *http.Response, error) {
+    // An update may leave out the exec to keep the existing code, so only a given exec is validated then
+    if action.Exec != nil || !overwrite {
+        if err := validateAction(action, checkKind); err != nil {
+            return nil, nil, err
+        }
+    }
+
     // Encode resource name as a path (with no query params) before inserting it into the URI
     // This way any '?' chars in the name won't be treated as the beginning of the query params
     actionName := (&url.URL{Path:  action.Name}).String()
     route := fmt.Sprintf("actions/%s?overwrite=%t", actionName, overwrite)
     Debug(DbgInfo, "Action insert route: %s\n", route)
 
-    req, err := s.client.NewRequest("PUT", route, action, IncludeNamespaceInUrl)
+    body := getInsertBody(action, action.Parameters, action.Annotations)
+    req, err := s.client.NewRequestWithContext(ctx, "PUT", route, body, IncludeNamespaceInUrl)
     if err != nil {
         Debug(DbgError, "http.NewRequest(PUT, %s, %#v) error: '%s'\n", route, err, action)
         errMsg := wski18n.T("Unable to create HTTP request for PUT '{{.route}}': {{.err}}",
@@ -120,6 +368,10 @@ func (s *ActionService) Insert(action *Action, overwrite bool) (*Action, *http.R
         return nil, nil, whiskErr
     }
 
+    if len(ifMatch) > 0 {
+        req.Header.Set("If-Match", ifMatch)
+    }
+
     a := new(Action)
     resp, err := s.client.Do(req, &a, ExitWithSuccessOnTimeout)
     if err != nil {
@@ -131,29 +383,79 @@ func (s *ActionService) Insert(action *Action, overwrite bool) (*Action, *http.R
 }
 
 func (s *ActionService) Get(actionName string) (*Action, *http.Response, error) {
+    action, _, resp, err := s.get(context.Background(), actionName)
+    return action, resp, err
+}
+
+// GetWithContext gets an action; the request is abandoned when ctx is cancelled or its deadline passes
+func (s *ActionService) GetWithContext(ctx context.Context, actionName string) (*Action, *http.Response, error) {
+    action, _, resp, err := s.get(ctx, actionName)
+    return action, resp, err
+}
+
+// GetWithETag gets an action along with the ETag response header, which can be passed to InsertIfMatch
+func (s *ActionService) GetWithETag(actionName string) (*Action, string, *http.Response, error) {
+    return s.get(context.Background(), actionName)
+}
+
+func (s *ActionService) get(ctx context.Context, actionName string) (*Action, string, *http.Response, error) {
     // Encode resource name as a path (with no query params) before inserting it into the URI
     // This way any '?' chars in the name won't be treated as the beginning of the query params
     actionName = (&url.URL{Path: actionName}).String()
     route := fmt.Sprintf("actions/%s", actionName)
 
-    req, err := s.client.NewRequest("GET", route, nil, IncludeNamespaceInUrl)
+    req, err := s.client.NewRequestWithContext(ctx, "GET", route, nil, IncludeNamespaceInUrl)
     if err != nil {
         Debug(DbgError, "http.NewRequest(GET, %s, nil) error: '%s'\n", route, err)
         errMsg := wski18n.T("Unable to create HTTP request for GET '{{.route}}': {{.err}}",
             map[string]interface{}{"route": route, "err": err})
         whiskErr := MakeWskErrorFromWskError(errors.New(errMsg), err, EXITCODE_ERR_NETWORK, DISPLAY_MSG,
             NO_DISPLAY_USAGE)
-        return nil, nil, whiskErr
+        return nil, "", nil, whiskErr
     }
 
     a := new(Action)
     resp, err := s.client.Do(req, &a, ExitWithSuccessOnTimeout)
+    if err != nil {
+        Debug(DbgError, "s.client.Do() error - HTTP req %s; error '%s'\n", req.URL.String(), err)
+        return nil, "", resp, err
+    }
+
+    return a, resp.Header.Get("ETag"), resp, nil
+}
+
+// GetRaw gets an action and returns the response body exactly as the server sent it, without decoding it
+func (s *ActionService) GetRaw(actionName string) ([]byte, *http.Response, error) {
+    // Encode resource name as a path (with no query params) before inserting it into the URI
+    // This way any '?' chars in the name won't be treated as the beginning of the query params
+    actionName = (&url.URL{Path: actionName}).String()
+    route := fmt.Sprintf("actions/%s", actionName)
+
+    req, err := s.client.NewRequest("GET", route, nil, IncludeNamespaceInUrl)
+    if err != nil {
+        Debug(DbgError, "http.NewRequest(GET, %s, nil) error: '%s'\n", route, err)
+        errMsg := wski18n.T("Unable to create HTTP request for GET '{{.route}}': {{.err}}",
+            map[string]interface{}{"route": route, "err": err})
+        whiskErr := MakeWskErrorFromWskError(errors.New(errMsg), err, EXITCODE_ERR_NETWORK, DISPLAY_MSG,
+            NO_DISPLAY_USAGE)
+        return nil, nil, whiskErr
+    }
+
+    // Without a value to decode into, Do only checks the response status and leaves the body readable
+    resp, err := s.client.Do(req, nil, ExitWithSuccessOnTimeout)
     if err != nil {
         Debug(DbgError, "s.client.Do() error - HTTP req %s; error '%s'\n", req.URL.String(), err)
         return nil, resp, err
     }
 
-    return a, resp, nil
+    data, err := ioutil.ReadAll(resp.Body)
+    if err != nil {
+        Debug(DbgError, "ioutil.ReadAll(resp.Body) error: %s\n", err)
+        whiskErr := MakeWskError(err, EXITCODE_ERR_NETWORK, DISPLAY_MSG, NO_DISPLAY_USAGE)
+        return nil, resp, whiskErr
+    }
+
+    return data, resp, nil
 }
 
 func (s *ActionService) Delete(actionName string) (*http.Response, error) {
@@ -183,16 +485,93 @@ func (s *ActionService) Delete(actionName string) (*http.Response, error) {
     return resp, nil
 }
 
-func (s *ActionService) Invoke(actionName string, payload interface{}, blocking bool, result bool) (map[string]interface {}, *http.Response, error) {
+// Invoke invokes an action and returns its response and the id of the resulting activation. The id is taken from
+// the activation id response header, so it is also returned for blocking invocations that only requested the result,
+// or else from the activationId field of the response.
+func (s *ActionService) Invoke(actionName string, payload interface{}, blocking bool, result bool) (map[string]interface {}, string, *http.Response, error) {
+    options := &InvokeOptions{
+        Blocking: blocking,
+        Result:   result,
+    }
+
+    res, resp, err := s.InvokeWithOptions(actionName, payload, options)
+
+    return res, GetActivationID(res, resp), resp, err
+}
+
+// InvokeWeb invokes a web action through its web URL, without the Authorization header. The namespace may end with
+// the package of the action; actions outside a package are served from the "default" package. The raw response body
+// is returned.
+func (s *ActionService) InvokeWeb(namespace string, actionName string, options *WebInvokeOptions) ([]byte, *http.Response, error) {
+    packageName := "default"
+    if parts := strings.SplitN(namespace, "/", 2); len(parts) == 2 {
+        namespace, packageName = parts[0], parts[1]
+    }
+
+    method := options.Method
+    if len(method) == 0 {
+        method = "GET"
+    }
+
+    // Encode resource names as a path (with no query params) before inserting them into the URI
+    // This way any '?' chars in the names won't be treated as the beginning of the query params
+    routeUrl := &url.URL{Path: fmt.Sprintf("web/%s/%s/%s", namespace, packageName, actionName),
+        RawQuery: options.Query.Encode()}
+
+    req, err := s.client.NewRequestUrl(method, routeUrl, options.Body, DoNotIncludeNamespaceInUrl,
+        AppendOpenWhiskPathPrefix, EncodeBodyAsJson, NoAuth)
+    if err != nil {
+        Debug(DbgError, "http.NewRequestUrl(%s, %s, %#v, DoNotIncludeNamespaceInUrl, AppendOpenWhiskPathPrefix, EncodeBodyAsJson, NoAuth) error: '%s'\n", method, routeUrl, options.Body, err)
+        errStr := wski18n.T("Unable to create HTTP request for {{.method}} '{{.route}}': {{.err}}",
+            map[string]interface{}{"method": method, "route": routeUrl, "err": err})
+        werr := MakeWskErrorFromWskError(errors.New(errStr), err, EXITCODE_ERR_GENERAL, DISPLAY_MSG, NO_DISPLAY_USAGE)
+        return nil, nil, werr
+    }
+
+    if len(options.AuthToken) > 0 {
+        req.Header.Set(RequireWhiskAuthHeader, options.AuthToken)
+    }
+
+    resp, err := s.client.Do(req, nil, ExitWithSuccessOnTimeout)
+    if err != nil {
+        Debug(DbgError, "s.client.Do() error - HTTP req %s; error '%s'\n", req.URL.String(), err)
+        return nil, resp, err
+    }
+
+    data, err := ioutil.ReadAll(resp.Body)
+    if err != nil {
+        Debug(DbgError, "ioutil.ReadAll(resp.Body) error: %s\n", err)
+        werr := MakeWskError(err, EXITCODE_ERR_NETWORK, DISPLAY_MSG, NO_DISPLAY_USAGE)
+        return nil, resp, werr
+    }
+
+    return data, resp, nil
+}
+
+func (s *ActionService) InvokeWithOptions(actionName string, payload interface{}, options *InvokeOptions) (map[string]interface {}, *http.Response, error) {
     var res map[string]interface {}
 
+    if options == nil {
+        options = &InvokeOptions{}
+    }
+
     // Encode resource name as a path (with no query params) before inserting it into the URI
     // This way any '?' chars in the name won't be treated as the beginning of the query params
     actionName = (&url.URL{Path: actionName}).String()
-    route := fmt.Sprintf("actions/%s?blocking=%t&result=%t", actionName, blocking, result)
+    route := fmt.Sprintf("actions/%s?blocking=%t&result=%t", actionName, options.Blocking, options.Result)
+    if options.Timeout > 0 {
+        route = fmt.Sprintf("%s&timeout=%d", route, options.Timeout)
+    }
     Debug(DbgInfo, "HTTP route: %s\n", route)
 
-    req, err := s.client.NewRequest("POST", route, payload, IncludeNamespaceInUrl)
+    ctx := context.Background()
+    if timeout := getBlockingInvokeTimeout(s.client.Config, options); timeout > 0 {
+        var cancel context.CancelFunc
+        ctx, cancel = context.WithTimeout(ctx, timeout)
+        defer cancel()
+    }
+
+    req, err := s.client.NewRequestWithContext(ctx, "POST", route, payload, IncludeNamespaceInUrl)
     if err != nil {
         Debug(DbgError, "http.NewRequest(POST, %s, %#v) error: '%s'\n", route, payload, err)
         errMsg := wski18n.T("Unable to create HTTP request for POST '{{.route}}': {{.err}}",
@@ -202,7 +581,7 @@ func (s *ActionService) Invoke(actionName string, payload interface{}, blocking
         return nil, nil, whiskErr
     }
 
-    resp, err := s.client.Do(req, &res, blocking)
+    resp, err := s.client.Do(req, &res, options.Blocking)
 
     if err != nil {
       Debug(DbgError, "s.client.Do() error - HTTP req %s; error '%s'\n", req.URL.String(), err)
@@ -211,3 +590,54 @@ func (s *ActionService) Invoke(actionName string, payload interface{}, blocking
 
     return res, resp, nil
 }
+
+// InvokeWithActivation invokes an action like InvokeWithOptions and also returns the resulting activation, with only
+// its ActivationID set, or nil when the response holds no activation id. The id is known for non-blocking invocations,
+// for blocking invocations that timed out with a 202 response before the action finished, and for every response that
+// carries the activation id header.
+func (s *ActionService) InvokeWithActivation(actionName string, payload interface{}, options *InvokeOptions) (*Activation, map[string]interface {}, *http.Response, error) {
+    var activation *Activation
+
+    res, resp, err := s.InvokeWithOptions(actionName, payload, options)
+
+    if id := GetActivationID(res, resp); len(id) > 0 {
+        activation = &Activation{ActivationID: id}
+    }
+
+    return activation, res, resp, err
+}
+
+// getBlockingInvokeTimeout returns how long a blocking invocation is waited for, which is the time the server may
+// wait for the action plus a margin, or zero for a non-blocking invocation or when requests have no time limit
+func getBlockingInvokeTimeout(config *Config, options *InvokeOptions) time.Duration {
+    if !options.Blocking || getTimeout(config.RequestTimeout, DefaultRequestTimeout) == 0 {
+        return 0
+    }
+
+    wait := options.ActionTimeout
+    if wait <= 0 {
+        wait = MaxActionTimeout
+    }
+
+    if options.Timeout > 0 && time.Duration(options.Timeout) * time.Millisecond < wait {
+        wait = time.Duration(options.Timeout) * time.Millisecond
+    }
+
+    return wait + BlockingInvokeMargin
+}
+
+// GetActivationID returns the activation id from the response header of an invocation, or else from the activationId
+// field of its response body. It is empty when neither holds an id.
+func GetActivationID(res map[string]interface{}, resp *http.Response) string {
+    if resp != nil {
+        if id := resp.Header.Get(ActivationIDHeader); len(id) > 0 {
+            return id
+        }
+    }
+
+    if id, ok := res["activationId"].(string); ok {
+        return id
+    }
+
+    return ""
+}
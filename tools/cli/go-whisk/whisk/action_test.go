@@ -0,0 +1,703 @@
+/*
+ * Copyright 2015-2016 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package whisk
+
+import (
+    "encoding/json"
+    "fmt"
+    "io/ioutil"
+    "net/http"
+    "os"
+    "path/filepath"
+    "reflect"
+    "sort"
+    "strings"
+    "testing"
+    "time"
+)
+
+func TestInvokeWithOptionsURL(t *testing.T) {
+    tests := []struct {
+        options     *InvokeOptions
+        want        string
+    }{
+        {nil, "blocking=false&result=false"},
+        {&InvokeOptions{}, "blocking=false&result=false"},
+        {&InvokeOptions{Blocking: true, Result: true}, "blocking=true&result=true"},
+        {&InvokeOptions{Blocking: true, Timeout: 5000}, "blocking=true&result=false&timeout=5000"},
+        {&InvokeOptions{Timeout: 1}, "blocking=false&result=false&timeout=1"},
+        {&InvokeOptions{Timeout: -1}, "blocking=false&result=false"},
+    }
+
+    for _, test := range tests {
+        var path, query string
+        client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+            path, query = r.URL.EscapedPath(), r.URL.RawQuery
+            writeTestJSON(w, http.StatusOK, map[string]interface{}{"activationId": "12345"})
+        })
+
+        _, _, err := client.Actions.InvokeWithOptions("pkg/a b", nil, test.options)
+        server.Close()
+
+        if err != nil {
+            t.Errorf("InvokeWithOptions(%#v) failed: %s", test.options, err)
+        }
+        if path != "/api/v1/namespaces/ns/actions/pkg/a%20b" {
+            t.Errorf("InvokeWithOptions(%#v) path = %s", test.options, path)
+        }
+        if query != test.want {
+            t.Errorf("InvokeWithOptions(%#v) query = %s, want %s", test.options, query, test.want)
+        }
+    }
+}
+
+func TestInvokePayloadBody(t *testing.T) {
+    tests := []struct {
+        payload     interface{}
+        want        string
+    }{
+        {map[string]interface{}{"a": []interface{}{1, 2}}, "{\"a\":[1,2]}\n"},
+        {[]interface{}{1, "two", true}, "[1,\"two\",true]\n"},
+        {"text", "\"text\"\n"},
+        {json.Number("1.50"), "1.50\n"},
+        {42, "42\n"},
+        {false, "false\n"},
+        // Without a payload there is no body
+        {nil, ""},
+    }
+
+    for _, test := range tests {
+        var body []byte
+        client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+            body, _ = ioutil.ReadAll(r.Body)
+            writeTestJSON(w, http.StatusOK, map[string]interface{}{"activationId": "12345"})
+        })
+
+        _, _, err := client.Actions.InvokeWithOptions("a", test.payload, nil)
+        server.Close()
+
+        // The payload is sent as is, without being wrapped in an object
+        if err != nil {
+            t.Errorf("InvokeWithOptions(%#v) failed: %s", test.payload, err)
+        } else if string(body) != test.want {
+            t.Errorf("InvokeWithOptions(%#v) sent %q, want %q", test.payload, body, test.want)
+        }
+    }
+}
+
+func TestActionJSONRoundTrip(t *testing.T) {
+    code := "function main() {}"
+    timeout, memory := 60000, 256
+    publish := false
+    actions := []Action{
+        {Namespace: "ns", Name: "a", Version: "0.0.2", Exec: &Exec{Kind: "nodejs:6", Code: &code, Main: "main"},
+            Annotations: KeyValueArr{{Key: "exec", Value: "nodejs:6"}},
+            Parameters: KeyValueArr{{Key: "s", Value: "v"}, {Key: "b", Value: true}, {Key: "n", Value: nil}},
+            Limits: &Limits{Timeout: &timeout, Memory: &memory}, Publish: &publish},
+        {Namespace: "ns/pkg", Name: "seq", Exec: &Exec{Kind: "sequence", Components: []string{"/ns/a", "/ns/b"}}},
+        {Namespace: "ns", Name: "row"},
+    }
+
+    for _, action := range actions {
+        data, err := json.Marshal(action)
+        if err != nil {
+            t.Fatalf("json.Marshal(%#v) failed: %s", action, err)
+        }
+
+        var decoded Action
+        if err = json.Unmarshal(data, &decoded); err != nil {
+            t.Fatalf("json.Unmarshal(%s) failed: %s", data, err)
+        }
+        if !reflect.DeepEqual(decoded, action) {
+            t.Errorf("action %s decoded as %#v, want %#v", data, decoded, action)
+        }
+    }
+}
+
+// etagHandler serves an action whose ETag is etag, and accepts puts whose If-Match header, if any, matches it
+func etagHandler(etag string, ifMatches *[]string) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        w.Header().Set("ETag", etag)
+        if r.Method == "PUT" {
+            *ifMatches = append(*ifMatches, r.Header.Get("If-Match"))
+            if ifMatch := r.Header.Get("If-Match"); len(ifMatch) > 0 && ifMatch != etag {
+                writeTestJSON(w, http.StatusPreconditionFailed,
+                    map[string]interface{}{"error": "The resource has been modified.", "code": 1})
+                return
+            }
+        }
+        writeTestJSON(w, http.StatusOK, Action{Namespace: "ns", Name: "a"})
+    }
+}
+
+func TestGetWithETag(t *testing.T) {
+    var ifMatches []string
+    client, server := newTestClient(t, etagHandler(`"v2"`, &ifMatches))
+    defer server.Close()
+
+    action, etag, _, err := client.Actions.GetWithETag("a")
+    if err != nil {
+        t.Fatalf("GetWithETag() failed: %s", err)
+    }
+    if action.Name != "a" || etag != `"v2"` {
+        t.Errorf("GetWithETag() = %#v, %s, want action a with the ETag \"v2\"", action, etag)
+    }
+}
+
+func TestInsertIfMatch(t *testing.T) {
+    tests := []struct {
+        ifMatch     string
+        status      int
+    }{
+        {"", http.StatusOK},
+        {`"v2"`, http.StatusOK},
+        {`"v1"`, http.StatusPreconditionFailed},
+    }
+
+    for _, test := range tests {
+        var ifMatches []string
+        client, server := newTestClient(t, etagHandler(`"v2"`, &ifMatches))
+        _, resp, err := client.Actions.InsertIfMatch(&Action{Name: "a"}, true, test.ifMatch)
+        server.Close()
+
+        if len(ifMatches) != 1 || ifMatches[0] != test.ifMatch {
+            t.Errorf("InsertIfMatch(%q) sent the If-Match headers %q", test.ifMatch, ifMatches)
+        }
+        if resp == nil || resp.StatusCode != test.status {
+            t.Errorf("InsertIfMatch(%q) response = %v, want the status %d", test.ifMatch, resp, test.status)
+        }
+
+        if test.status == http.StatusOK {
+            if err != nil {
+                t.Errorf("InsertIfMatch(%q) failed: %s", test.ifMatch, err)
+            }
+        } else if whiskErr, ok := err.(*WskError); !ok || whiskErr.ExitCode != getHttpExitCode(test.status) {
+            t.Errorf("InsertIfMatch(%q) error = %#v, want a WskError for the status %d", test.ifMatch, err, test.status)
+        }
+    }
+}
+
+func TestActionListString(t *testing.T) {
+    action := Action{Namespace: "ns/pkg", Name: "a", Version: "0.0.3",
+        Annotations: KeyValueArr{{Key: "exec", Value: "nodejs:6"}}}
+
+    if want := fmt.Sprintf("%-70s private nodejs:6\n", "/ns/pkg/a"); action.ListString() != want {
+        t.Errorf("ListString() = %q, want %q", action.ListString(), want)
+    }
+    if columns := action.Columns(); !reflect.DeepEqual(columns, []string{"/ns/pkg/a", "private", "nodejs:6"}) {
+        t.Errorf("Columns() = %q, want the name, publish state and kind", columns)
+    }
+}
+
+// pagedActionsHandler answers action list requests with count actions named a0, a1, ..., paged by the limit and skip
+// query parameters, and records the skip and limit of each request. The page at failSkip fails.
+func pagedActionsHandler(count int, failSkip int, pages *[]string) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        query := r.URL.Query()
+        *pages = append(*pages, query.Get("skip") + "/" + query.Get("limit"))
+
+        var limit, skip int
+        fmt.Sscan(query.Get("limit"), &limit)
+        fmt.Sscan(query.Get("skip"), &skip)
+        if skip == failSkip {
+            writeTestJSON(w, http.StatusInternalServerError, map[string]interface{}{"error": "list failed", "code": 1})
+            return
+        }
+
+        page := []Action{}
+        for i := skip; i < count && i < skip + limit; i++ {
+            page = append(page, Action{Namespace: "ns", Name: fmt.Sprintf("a%d", i)})
+        }
+        writeTestJSON(w, http.StatusOK, page)
+    }
+}
+
+func TestActionListAll(t *testing.T) {
+    var pages []string
+    client, server := newTestClient(t, pagedActionsHandler(MaxListPageSize + 50, -1, &pages))
+    defer server.Close()
+
+    actions, err := client.Actions.ListAll("")
+    if err != nil {
+        t.Fatalf("ListAll() failed: %s", err)
+    }
+
+    // The second page is short, so it is the last
+    if want := "0/200 200/200"; strings.Join(pages, " ") != want {
+        t.Errorf("ListAll() requested the pages %s, want %s", strings.Join(pages, " "), want)
+    }
+    if len(actions) != MaxListPageSize + 50 || actions[0].Name != "a0" || actions[len(actions) - 1].Name != "a249" {
+        t.Errorf("ListAll() returned %d actions, want a0 to a249", len(actions))
+    }
+}
+
+func TestActionListAllError(t *testing.T) {
+    var pages []string
+    client, server := newTestClient(t, pagedActionsHandler(3 * MaxListPageSize, MaxListPageSize, &pages))
+    defer server.Close()
+
+    // The actions of the pages before the failed one are returned with the error
+    actions, err := client.Actions.ListAll("")
+    if err == nil {
+        t.Errorf("ListAll() with a failed page succeeded")
+    }
+    if len(actions) != MaxListPageSize {
+        t.Errorf("ListAll() with a failed second page returned %d actions, want %d", len(actions), MaxListPageSize)
+    }
+}
+
+func TestActionListOptionsQuery(t *testing.T) {
+    tests := []struct {
+        options ActionListOptions
+        want    string
+    }{
+        {ActionListOptions{Limit: 30}, "limit=30&skip=0"},
+        {ActionListOptions{Limit: 30, Skip: 10, Name: "hello"}, "limit=30&name=hello&skip=10"},
+        {ActionListOptions{Limit: 30, Kind: "python:3"}, "kind=python%3A3&limit=30&skip=0"},
+        {ActionListOptions{Limit: 5, Kind: "nodejs,sequence", Name: "a"}, "kind=nodejs%2Csequence&limit=5&name=a&skip=0"},
+    }
+
+    for _, test := range tests {
+        var query string
+        client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+            query = r.URL.RawQuery
+            writeTestJSON(w, http.StatusOK, []Action{})
+        })
+
+        _, _, _, err := client.Actions.List("", &test.options)
+        server.Close()
+
+        if err != nil {
+            t.Errorf("List(%+v) failed: %s", test.options, err)
+        } else if query != test.want {
+            t.Errorf("List(%+v) sent the query %q, want %q", test.options, query, test.want)
+        }
+    }
+}
+
+func TestActionListAllWithOptions(t *testing.T) {
+    var queries []string
+    client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+        queries = append(queries, r.URL.RawQuery)
+        writeTestJSON(w, http.StatusOK, []Action{})
+    })
+    defer server.Close()
+
+    // The kind and name are sent with every page, while the paging replaces the limit and skip of the options
+    options := &ActionListOptions{Kind: "python", Name: "a", Limit: 5, Skip: 3}
+    if _, err := client.Actions.ListAllWithOptions("", options); err != nil {
+        t.Fatalf("ListAllWithOptions() failed: %s", err)
+    }
+    if want := "kind=python&limit=200&name=a&skip=0"; strings.Join(queries, " ") != want {
+        t.Errorf("ListAllWithOptions() sent the queries %q, want %q", queries, want)
+    }
+}
+
+func TestValidateAction(t *testing.T) {
+    code := "function main() {}"
+
+    tests := []struct {
+        exec    *Exec
+        want    string      // the start of the error message, or empty when the action is valid
+    }{
+        {&Exec{Kind: "nodejs:6", Code: &code}, ""},
+        {&Exec{Kind: "blackbox", Image: "me/image"}, ""},
+        {&Exec{Kind: "blackbox", Image: "me/image", Code: &code}, ""},
+        {&Exec{Kind: "sequence", Components: []string{"/ns/a"}}, ""},
+        {nil, "The action 'a' has no exec."},
+        {&Exec{Code: &code}, "The action 'a' has no kind."},
+        {&Exec{Kind: "cobol:1", Code: &code}, "The kind 'cobol:1' of action 'a' is not supported"},
+        {&Exec{Kind: "nodejs:6"}, "The action 'a' of kind 'nodejs:6' requires code."},
+        {&Exec{Kind: "blackbox"}, "The blackbox action 'a' requires an image."},
+        {&Exec{Kind: "blackbox", Code: &code}, "The blackbox action 'a' requires an image."},
+        {&Exec{Kind: "nodejs:6", Image: "me/image", Code: &code},
+            "The action 'a' has an image, so its kind must be blackbox instead of 'nodejs:6'."},
+        {&Exec{Image: "me/image"}, "The action 'a' has an image, so its kind must be blackbox instead of ''."},
+        {&Exec{Kind: "sequence"}, "The sequence action 'a' requires at least one component."},
+        {&Exec{Kind: "sequence", Image: "me/image", Components: []string{"/ns/a"}},
+            "The action 'a' has an image, so its kind must be blackbox instead of 'sequence'."},
+    }
+
+    for _, test := range tests {
+        err := validateAction(&Action{Name: "a", Exec: test.exec}, true)
+        if len(test.want) == 0 && err != nil {
+            t.Errorf("validateAction(%#v) failed: %s", test.exec, err)
+        } else if len(test.want) > 0 && (err == nil || !strings.HasPrefix(err.Error(), test.want)) {
+            t.Errorf("validateAction(%#v) error = %v, want %q", test.exec, err, test.want)
+        }
+    }
+}
+
+func TestInsertValidatesAction(t *testing.T) {
+    var requests int
+    client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+        requests++
+        writeTestJSON(w, http.StatusOK, map[string]interface{}{})
+    })
+    defer server.Close()
+
+    if _, _, err := client.Actions.Insert(&Action{Name: "a", Exec: &Exec{Kind: "cobol:1"}}, false); err == nil {
+        t.Error("Insert() of an action with an unknown kind succeeded")
+    }
+    if _, _, err := client.Actions.Insert(&Action{Name: "a"}, false); err == nil {
+        t.Error("Insert() of an action without an exec succeeded")
+    }
+    if requests > 0 {
+        t.Errorf("Insert() of invalid actions sent %d requests", requests)
+    }
+
+    // An update without an exec keeps the existing code, so it is sent
+    if _, _, err := client.Actions.Insert(&Action{Name: "a"}, true); err != nil {
+        t.Errorf("Insert() of an update without an exec failed: %s", err)
+    }
+    if requests != 1 {
+        t.Errorf("Insert() of an update without an exec sent %d requests, want 1", requests)
+    }
+}
+
+func TestInsertAnyKind(t *testing.T) {
+    code := "x"
+    var requests int
+    client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+        requests++
+        writeTestJSON(w, http.StatusOK, map[string]interface{}{})
+    })
+    defer server.Close()
+
+    // The server decides whether an unknown kind is available, but the rest of the exec is still checked
+    if _, _, err := client.Actions.InsertAnyKind(&Action{Name: "a", Exec: &Exec{Kind: "cobol:1", Code: &code}}, false); err != nil {
+        t.Errorf("InsertAnyKind() of an action with an unknown kind failed: %s", err)
+    }
+    if _, _, err := client.Actions.InsertAnyKind(&Action{Name: "a", Exec: &Exec{Kind: "cobol:1"}}, false); err == nil {
+        t.Error("InsertAnyKind() of an action without code succeeded")
+    }
+    if requests != 1 {
+        t.Errorf("InsertAnyKind() sent %d requests, want 1", requests)
+    }
+}
+
+func TestIsSupportedRuntime(t *testing.T) {
+    origRuntimes := SupportedRuntimes
+    defer func() { SupportedRuntimes = origRuntimes }()
+
+    SupportedRuntimes = []string{"nodejs:6"}
+    for kind, want := range map[string]bool{"nodejs:6": true, "blackbox": true, "sequence": true, "cobol:1": false} {
+        if got := IsSupportedRuntime(kind); got != want {
+            t.Errorf("IsSupportedRuntime(%s) = %t, want %t", kind, got, want)
+        }
+    }
+
+    // Without a list of runtimes, any kind is accepted
+    SupportedRuntimes = nil
+    if !IsSupportedRuntime("cobol:1") {
+        t.Error("IsSupportedRuntime(cobol:1) = false without any supported runtimes")
+    }
+}
+
+func TestLoadSupportedRuntimes(t *testing.T) {
+    origRuntimes := SupportedRuntimes
+    defer func() { SupportedRuntimes = origRuntimes }()
+
+    dir, err := ioutil.TempDir("", "runtimes")
+    if err != nil {
+        t.Fatalf("ioutil.TempDir() failed: %s", err)
+    }
+    defer os.RemoveAll(dir)
+
+    tests := []struct {
+        content string
+        want    []string    // the loaded kinds in sorted order, or nil when the file is rejected
+    }{
+        {`["nodejs:8", "cobol:1"]`, []string{"cobol:1", "nodejs:8"}},
+        {`{"runtimes": {"nodejs": [{"kind": "nodejs:8"}], "cobol": [{"kind": "cobol:1", "default": true}]}}`,
+            []string{"cobol:1", "nodejs:8"}},
+        {`{"runtimes": {"cobol": [{"kind": "cobol:1"}, {"image": "no kind"}]}}`, []string{"cobol:1"}},
+        {`[]`, nil},
+        {`{"runtimes": {}}`, nil},
+        {`["nodejs:8"`, nil},
+    }
+
+    for i, test := range tests {
+        filename := filepath.Join(dir, fmt.Sprintf("runtimes%d.json", i))
+        if err = ioutil.WriteFile(filename, []byte(test.content), 0644); err != nil {
+            t.Fatalf("ioutil.WriteFile(%s) failed: %s", filename, err)
+        }
+
+        SupportedRuntimes = origRuntimes
+        err = LoadSupportedRuntimes(filename)
+        if test.want == nil {
+            if err == nil {
+                t.Errorf("LoadSupportedRuntimes(%s) loaded %q, want an error", test.content, SupportedRuntimes)
+            }
+            continue
+        }
+
+        if err != nil {
+            t.Errorf("LoadSupportedRuntimes(%s) failed: %s", test.content, err)
+            continue
+        }
+
+        // The families of a manifest are a map, so the order of the kinds is not significant
+        have := append([]string(nil), SupportedRuntimes...)
+        sort.Strings(have)
+        if !reflect.DeepEqual(have, test.want) {
+            t.Errorf("LoadSupportedRuntimes(%s) loaded %q, want %q", test.content, have, test.want)
+        }
+
+        // The loaded kinds replace the built in ones
+        if err = validateAction(&Action{Name: "a", Exec: &Exec{Kind: "python:3", Code: new(string)}}, true); err == nil {
+            t.Errorf("validateAction() accepted python:3 after LoadSupportedRuntimes(%s)", test.content)
+        }
+    }
+
+    if err = LoadSupportedRuntimes(filepath.Join(dir, "missing.json")); err == nil {
+        t.Error("LoadSupportedRuntimes() of a missing file succeeded")
+    }
+}
+
+func TestGetRaw(t *testing.T) {
+    // Formatting, key order and escapes that decoding and encoding again would change
+    body := "{\"name\":\"hello\",  \"namespace\":\"ns\",\n\"exec\":{\"kind\":\"nodejs:6\",\"code\":\"h\\u00e9llo\"},\"extra\":1.50}\n"
+
+    client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+        if r.Method != "GET" || r.URL.Path != "/api/v1/namespaces/ns/actions/pkg/hello" {
+            t.Errorf("GetRaw() sent %s %s, want GET /api/v1/namespaces/ns/actions/pkg/hello", r.Method, r.URL.Path)
+        }
+        w.Header().Set("Content-Type", "application/json")
+        w.Write([]byte(body))
+    })
+    defer server.Close()
+
+    data, resp, err := client.Actions.GetRaw("pkg/hello")
+    if err != nil {
+        t.Fatalf("GetRaw() failed: %s", err)
+    }
+    if string(data) != body {
+        t.Errorf("GetRaw() = %q, want %q", data, body)
+    }
+    if resp == nil || resp.StatusCode != http.StatusOK {
+        t.Errorf("GetRaw() response = %#v, want a 200 response", resp)
+    }
+}
+
+func TestInvokeActivationID(t *testing.T) {
+    activation := map[string]interface{}{"activationId": "12345",
+        "response": map[string]interface{}{"status": "success", "success": true, "result": map[string]interface{}{}}}
+    tests := []struct {
+        blocking    bool
+        result      bool
+        header      string
+        status      int
+        body        map[string]interface{}
+        want        string
+    }{
+        // The header holds the id, even when only the result is returned
+        {false, false, "header-id", http.StatusAccepted, map[string]interface{}{"activationId": "12345"}, "header-id"},
+        {true, false, "header-id", http.StatusOK, activation, "header-id"},
+        {true, true, "header-id", http.StatusOK, map[string]interface{}{"msg": "hi"}, "header-id"},
+        // Without the header, the id is taken from the body, if it has one
+        {false, false, "", http.StatusAccepted, map[string]interface{}{"activationId": "12345"}, "12345"},
+        {true, false, "", http.StatusOK, activation, "12345"},
+        {true, true, "", http.StatusOK, map[string]interface{}{"msg": "hi"}, ""},
+    }
+
+    for _, test := range tests {
+        client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+            if len(test.header) > 0 {
+                w.Header().Set(ActivationIDHeader, test.header)
+            }
+            writeTestJSON(w, test.status, test.body)
+        })
+
+        res, activationID, _, err := client.Actions.Invoke("a", nil, test.blocking, test.result)
+        server.Close()
+
+        if err != nil {
+            t.Errorf("Invoke(blocking %t, result %t) failed: %s", test.blocking, test.result, err)
+            continue
+        }
+        if activationID != test.want {
+            t.Errorf("Invoke(blocking %t, result %t) with the header %q returned the id %q, want %q", test.blocking,
+                test.result, test.header, activationID, test.want)
+        }
+        if !reflect.DeepEqual(res, test.body) {
+            t.Errorf("Invoke(blocking %t, result %t) = %#v, want the response body", test.blocking, test.result, res)
+        }
+    }
+}
+
+func TestInvokeWithActivation(t *testing.T) {
+    tests := []struct {
+        blocking    bool
+        result      bool
+        status      int
+        body        map[string]interface{}
+        want        string      // the activation id, or empty when there is no activation
+        timedOut    bool
+    }{
+        {false, false, http.StatusAccepted, map[string]interface{}{"activationId": "12345"}, "12345", false},
+        // A blocking invocation that times out is accepted with the activation id
+        {true, false, http.StatusAccepted, map[string]interface{}{"activationId": "12345"}, "12345", true},
+        {true, true, http.StatusAccepted, map[string]interface{}{"activationId": "12345"}, "12345", true},
+        {true, false, http.StatusOK, map[string]interface{}{"activationId": "12345",
+            "response": map[string]interface{}{"status": "success", "success": true}},
+            "12345", false},
+        // Only the result holds no activation id
+        {true, true, http.StatusOK, map[string]interface{}{"msg": "hi"}, "", false},
+    }
+
+    for _, test := range tests {
+        client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+            writeTestJSON(w, test.status, test.body)
+        })
+
+        options := &InvokeOptions{Blocking: test.blocking, Result: test.result}
+        activation, res, _, err := client.Actions.InvokeWithActivation("a", nil, options)
+        server.Close()
+
+        if whiskErr, ok := err.(*WskError); test.timedOut != (ok && whiskErr.TimedOut) || !test.timedOut && err != nil {
+            t.Errorf("InvokeWithActivation(%+v) with status %d error = %#v, want timed out %t", *options, test.status,
+                err, test.timedOut)
+        }
+        if len(test.want) == 0 && activation != nil ||
+                len(test.want) > 0 && (activation == nil || activation.ActivationID != test.want) {
+            t.Errorf("InvokeWithActivation(%+v) with status %d returned the activation %#v, want the id %q", *options,
+                test.status, activation, test.want)
+        }
+        if !reflect.DeepEqual(res, test.body) {
+            t.Errorf("InvokeWithActivation(%+v) = %#v, want the response body", *options, res)
+        }
+    }
+}
+
+func TestGetBlockingInvokeTimeout(t *testing.T) {
+    tests := []struct {
+        requestTimeout  time.Duration
+        options         InvokeOptions
+        want            time.Duration
+    }{
+        // Non-blocking invocations are limited to the request timeout
+        {0, InvokeOptions{}, 0},
+        {0, InvokeOptions{Blocking: true}, MaxActionTimeout + BlockingInvokeMargin},
+        {0, InvokeOptions{Blocking: true, ActionTimeout: time.Minute}, time.Minute + BlockingInvokeMargin},
+        {time.Second, InvokeOptions{Blocking: true, ActionTimeout: time.Minute}, time.Minute + BlockingInvokeMargin},
+        // The server waits no longer than the timeout of the invocation
+        {0, InvokeOptions{Blocking: true, ActionTimeout: time.Minute, Timeout: 5000}, 5 * time.Second + BlockingInvokeMargin},
+        {0, InvokeOptions{Blocking: true, ActionTimeout: time.Second, Timeout: 5000}, time.Second + BlockingInvokeMargin},
+        // Without a request time limit, blocking invocations have none either
+        {-1, InvokeOptions{Blocking: true, ActionTimeout: time.Minute}, 0},
+    }
+
+    for _, test := range tests {
+        timeout := getBlockingInvokeTimeout(&Config{RequestTimeout: test.requestTimeout}, &test.options)
+        if timeout != test.want {
+            t.Errorf("getBlockingInvokeTimeout(RequestTimeout %s, %+v) = %s, want %s", test.requestTimeout,
+                test.options, timeout, test.want)
+        }
+    }
+}
+
+func TestBlockingInvokeOutlastsRequestTimeout(t *testing.T) {
+    client, server := newSlowTestClient(t, 200 * time.Millisecond, 50 * time.Millisecond)
+    defer server.Close()
+
+    // A blocking invocation waits for the action rather than the request timeout
+    res, _, err := client.Actions.InvokeWithOptions("a", nil, &InvokeOptions{Blocking: true, ActionTimeout: time.Second})
+    if err != nil {
+        t.Errorf("blocking InvokeWithOptions() of a slow server failed: %s", err)
+    } else if res["activationId"] != "12345" {
+        t.Errorf("blocking InvokeWithOptions() = %#v, want the activation 12345", res)
+    }
+
+    // A non-blocking one does not
+    _, _, err = client.Actions.InvokeWithOptions("a", nil, &InvokeOptions{})
+    if whiskErr, ok := err.(*WskError); !ok || !strings.Contains(whiskErr.Error(), "timed out") {
+        t.Errorf("non-blocking InvokeWithOptions() of a slow server error = %#v, want a timed out WskError", err)
+    }
+}
+
+func TestInvokeWeb(t *testing.T) {
+    tests := []struct {
+        namespace   string
+        options     WebInvokeOptions
+        path        string
+        query       string
+        body        string
+    }{
+        {"ns", WebInvokeOptions{}, "/api/v1/web/ns/default/a", "", ""},
+        {"ns/pkg", WebInvokeOptions{Method: "GET", Query: map[string][]string{"name": {"x y"}}},
+            "/api/v1/web/ns/pkg/a", "name=x+y", ""},
+        {"ns", WebInvokeOptions{Method: "DELETE", Query: map[string][]string{"id": {"1"}}, AuthToken: "secret"},
+            "/api/v1/web/ns/default/a", "id=1", ""},
+        {"ns/pkg", WebInvokeOptions{Method: "POST", Body: map[string]interface{}{"n": 1}, AuthToken: "secret"},
+            "/api/v1/web/ns/pkg/a", "", `{"n":1}`},
+        {"ns", WebInvokeOptions{Method: "PUT", Body: map[string]interface{}{"s": "x"}},
+            "/api/v1/web/ns/default/a", "", `{"s":"x"}`},
+    }
+
+    for _, test := range tests {
+        var method, path, query, body, authorization, authToken string
+        var headers http.Header
+        client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+            data, _ := ioutil.ReadAll(r.Body)
+            method, path, query, body, headers = r.Method, r.URL.Path, r.URL.RawQuery, string(data), r.Header
+            authorization, authToken = r.Header.Get("Authorization"), r.Header.Get(RequireWhiskAuthHeader)
+            w.Header().Set("Content-Type", "text/html")
+            w.Write([]byte("<html>ok</html>"))
+        })
+
+        data, resp, err := client.Actions.InvokeWeb(test.namespace, "a", &test.options)
+        server.Close()
+
+        if err != nil {
+            t.Errorf("InvokeWeb(%s, a, %#v) failed: %s", test.namespace, test.options, err)
+            continue
+        }
+
+        wantMethod := test.options.Method
+        if len(wantMethod) == 0 {
+            wantMethod = "GET"
+        }
+        if method != wantMethod || path != test.path || query != test.query || strings.TrimSpace(body) != test.body {
+            t.Errorf("InvokeWeb(%s, a, %#v) sent %s %s?%s with the body %q, want %s %s?%s with the body %q",
+                test.namespace, test.options, method, path, query, body, wantMethod, test.path, test.query, test.body)
+        }
+        // The authorization key is never sent to a web action
+        if len(authorization) > 0 || authToken != test.options.AuthToken {
+            t.Errorf("InvokeWeb(%s, a, %#v) sent the headers %v", test.namespace, test.options, headers)
+        }
+        if string(data) != "<html>ok</html>" || resp.Header.Get("Content-Type") != "text/html" {
+            t.Errorf("InvokeWeb(%s, a, %#v) = %q with the content type %s, want the raw response body",
+                test.namespace, test.options, data, resp.Header.Get("Content-Type"))
+        }
+    }
+}
+
+func TestInvokeWebError(t *testing.T) {
+    client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+        writeTestJSON(w, http.StatusUnauthorized, map[string]interface{}{"error": "Authentication is possible but has failed or not yet been provided.", "code": 1})
+    })
+    defer server.Close()
+
+    _, resp, err := client.Actions.InvokeWeb("ns", "a", &WebInvokeOptions{AuthToken: "wrong"})
+    if whiskErr, ok := err.(*WskError); !ok || !strings.Contains(whiskErr.Error(), "Authentication is possible but has failed") {
+        t.Errorf("InvokeWeb() with a wrong secret error = %#v, want the server error", err)
+    }
+    if resp == nil || resp.StatusCode != http.StatusUnauthorized {
+        t.Errorf("InvokeWeb() with a wrong secret returned the response %#v, want the 401 response", resp)
+    }
+}
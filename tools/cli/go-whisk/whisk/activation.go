@@ -21,9 +21,32 @@ import (
     "net/http"
     "errors"
     "net/url"
+    "path"
+    "sort"
+    "time"
     "../wski18n"
 )
 
+// Default time between requests for new activations while watching
+const DefaultWatchInterval = time.Second
+
+// Default time between log requests of a LogsPoller
+const DefaultLogsPollInterval = 500 * time.Millisecond
+
+// Number of activations requested per page while watching; the server lists at most 200 activations per request
+const watchPageSize = 200
+
+// Default time before the newest reported start that watching keeps listing. Activations are only recorded when they
+// end, so one that started before the newest reported activation is still found if it ends within this time.
+const DefaultWatchLookback = time.Minute
+
+// Number of consecutive list requests that may fail with a transient error before watching stops
+const maxWatchFailures = 5
+
+// Route of the activations collection. Activations are only found in the "_" namespace, which is built into the route
+// rather than set on the client, so that activations can be requested while other requests of the client are made.
+const activationsRoute = "namespaces/_/activations"
+
 type ActivationService struct {
     client *Client
 }
@@ -54,6 +77,11 @@ type Response struct {
 
 type Result map[string]interface{}
 
+// Columns returns the activation as the columns of an activation list row: its ID and the name of its action
+func (activation Activation) Columns() []string {
+    return []string{activation.ActivationID, activation.Name}
+}
+
 type ActivationListOptions struct {
     Name  string `url:"name,omitempty"`
     Limit int    `url:"limit"`
@@ -63,6 +91,83 @@ type ActivationListOptions struct {
     Docs  bool   `url:"docs,omitempty"`
 }
 
+type ActivationWatchOptions struct {
+    Name     string         // Only report activations of this action
+    Since    int64          // Report activations started at or after this time (in milliseconds since January 1, 1970 UTC)
+    Interval time.Duration  // Time between requests for new activations; DefaultWatchInterval when zero
+    Lookback time.Duration  // Time before the newest reported start that is listed again; DefaultWatchLookback when zero
+}
+
+// activationsByStart sorts activations by their start time, oldest first
+type activationsByStart []Activation
+
+func (a activationsByStart) Len() int           { return len(a) }
+func (a activationsByStart) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a activationsByStart) Less(i, j int) bool { return a[i].Start < a[j].Start }
+
+// LogsPoller polls the logs of a running activation and sends each new log line once, in order. The logs cannot be
+// found until the server has recorded them, which some servers only do when the activation completes, so failed
+// requests are only traced and polling goes on until Stop is called.
+type LogsPoller struct {
+    Service     *ActivationService
+    Interval    time.Duration       // Time between log requests; DefaultLogsPollInterval when zero
+    stop        chan struct{}
+    done        chan struct{}
+}
+
+// NewLogsPoller returns a poller of the logs of this service's activations
+func (s *ActivationService) NewLogsPoller() *LogsPoller {
+    return &LogsPoller{Service: s, Interval: DefaultLogsPollInterval}
+}
+
+// Start polls the logs of an activation in a new goroutine and returns the channel on which new log lines are sent.
+// The channel is closed when Stop is called. A poller can only be started once.
+func (p *LogsPoller) Start(activationID string) <-chan string {
+    interval := p.Interval
+    if interval <= 0 {
+        interval = DefaultLogsPollInterval
+    }
+
+    lines := make(chan string)
+    p.stop = make(chan struct{})
+    p.done = make(chan struct{})
+
+    go func() {
+        defer close(p.done)
+        defer close(lines)
+        sent := 0
+
+        for {
+            activation, _, err := p.Service.Logs(activationID)
+            if err != nil {
+                Debug(DbgInfo, "Logs of activation '%s' are not available: %s\n", activationID, err)
+            } else {
+                for ; sent < len(activation.Logs); sent++ {
+                    select {
+                    case lines <- activation.Logs[sent]:
+                    case <-p.stop:
+                        return
+                    }
+                }
+            }
+
+            select {
+            case <-p.stop:
+                return
+            case <-time.After(interval):
+            }
+        }
+    }()
+
+    return lines
+}
+
+// Stop stops polling and returns once the channel returned by Start has been closed
+func (p *LogsPoller) Stop() {
+    close(p.stop)
+    <-p.done
+}
+
 //MWD - This structure may no longer be needed as the log format is now a string and not JSON
 type Log struct {
     Log    string `json:"log,omitempty"`
@@ -71,9 +176,7 @@ type Log struct {
 }
 
 func (s *ActivationService) List(options *ActivationListOptions) ([]Activation, *http.Response, error) {
-    // TODO :: for some reason /activations only works with "_" as namespace
-    s.client.Namespace = "_"
-    route := "activations"
+    route := activationsRoute
     routeUrl, err := addRouteOptions(route, options)
     if err != nil {
         Debug(DbgError, "addRouteOptions(%s, %#v) error: '%s'\n", route, options, err)
@@ -83,9 +186,9 @@ func (s *ActivationService) List(options *ActivationListOptions) ([]Activation,
         return nil, nil, werr
     }
 
-    req, err := s.client.NewRequestUrl("GET", routeUrl, nil, IncludeNamespaceInUrl, AppendOpenWhiskPathPrefix, EncodeBodyAsJson, AuthRequired)
+    req, err := s.client.NewRequestUrl("GET", routeUrl, nil, DoNotIncludeNamespaceInUrl, AppendOpenWhiskPathPrefix, EncodeBodyAsJson, AuthRequired)
     if err != nil {
-        Debug(DbgError, "http.NewRequestUrl(GET, %s, nil, IncludeNamespaceInUrl, AppendOpenWhiskPathPrefix, EncodeBodyAsJson, AuthRequired) error: '%s'\n", route, err)
+        Debug(DbgError, "http.NewRequestUrl(GET, %s, nil, DoNotIncludeNamespaceInUrl, AppendOpenWhiskPathPrefix, EncodeBodyAsJson, AuthRequired) error: '%s'\n", route, err)
         errStr := wski18n.T("Unable to create HTTP request for GET '{{.route}}': {{.err}}",
             map[string]interface{}{"route": route, "err": err})
         werr := MakeWskErrorFromWskError(errors.New(errStr), err, EXITCODE_ERR_GENERAL, DISPLAY_MSG, NO_DISPLAY_USAGE)
@@ -106,15 +209,12 @@ func (s *ActivationService) List(options *ActivationListOptions) ([]Activation,
 }
 
 func (s *ActivationService) Get(activationID string) (*Activation, *http.Response, error) {
-    // TODO :: for some reason /activations/:id only works with "_" as namespace
-    s.client.Namespace = "_"
-
     // Encode resource name as a path (with no query params) before inserting it into the URI
     // This way any '?' chars in the name won't be treated as the beginning of the query params
     activationID = (&url.URL{Path: activationID}).String()
-    route := fmt.Sprintf("activations/%s", activationID)
+    route := fmt.Sprintf("%s/%s", activationsRoute, activationID)
 
-    req, err := s.client.NewRequest("GET", route, nil, IncludeNamespaceInUrl)
+    req, err := s.client.NewRequest("GET", route, nil, DoNotIncludeNamespaceInUrl)
     if err != nil {
         Debug(DbgError, "http.NewRequest(GET, %s) error: '%s'\n", route, err)
         errStr := wski18n.T("Unable to create HTTP request for GET '{{.route}}': {{.err}}",
@@ -135,15 +235,24 @@ func (s *ActivationService) Get(activationID string) (*Activation, *http.Respons
     return a, resp, nil
 }
 
+// GetResult gets the response of an activation from its activation record. The record is only found once the
+// activation has completed, so a 404 response means the result is not available yet.
+func (s *ActivationService) GetResult(activationID string) (*Response, *http.Response, error) {
+    activation, resp, err := s.Get(activationID)
+    if err != nil {
+        return nil, resp, err
+    }
+
+    return &activation.Response, resp, nil
+}
+
 func (s *ActivationService) Logs(activationID string) (*Activation, *http.Response, error) {
-    // TODO :: for some reason /activations/:id/logs only works with "_" as namespace
-    s.client.Namespace = "_"
     // Encode resource name as a path (with no query params) before inserting it into the URI
     // This way any '?' chars in the name won't be treated as the beginning of the query params
     activationID = (&url.URL{Path: activationID}).String()
-    route := fmt.Sprintf("activations/%s/logs", activationID)
+    route := fmt.Sprintf("%s/%s/logs", activationsRoute, activationID)
 
-    req, err := s.client.NewRequest("GET", route, nil, IncludeNamespaceInUrl)
+    req, err := s.client.NewRequest("GET", route, nil, DoNotIncludeNamespaceInUrl)
     if err != nil {
         Debug(DbgError, "http.NewRequest(GET, %s) error: '%s'\n", route, err)
         errStr := wski18n.T("Unable to create HTTP request for GET '{{.route}}': {{.err}}",
@@ -165,14 +274,12 @@ func (s *ActivationService) Logs(activationID string) (*Activation, *http.Respon
 }
 
 func (s *ActivationService) Result(activationID string) (*Response, *http.Response, error) {
-    // TODO :: for some reason /activations only works with "_" as namespace
-    s.client.Namespace = "_"
     // Encode resource name as a path (with no query params) before inserting it into the URI
     // This way any '?' chars in the name won't be treated as the beginning of the query params
     activationID = (&url.URL{Path: activationID}).String()
-    route := fmt.Sprintf("activations/%s/result", activationID)
+    route := fmt.Sprintf("%s/%s/result", activationsRoute, activationID)
 
-    req, err := s.client.NewRequest("GET", route, nil, IncludeNamespaceInUrl)
+    req, err := s.client.NewRequest("GET", route, nil, DoNotIncludeNamespaceInUrl)
     if err != nil {
         Debug(DbgError, "http.NewRequest(GET, %s) error: '%s'\n", route, err)
         errStr := wski18n.T("Unable to create HTTP request for GET '{{.route}}': {{.err}}",
@@ -193,3 +300,187 @@ func (s *ActivationService) Result(activationID string) (*Response, *http.Respon
     return r, resp, nil
 
 }
+
+// Watch repeatedly lists the activations started since options.Since and calls handler once for each new activation,
+// oldest first. The server does not support long polling, so new activations are requested every options.Interval.
+// Activations are only recorded when they end, so each request lists the activations started up to options.Lookback
+// before the newest one reported, and activations are reported once by ID. Each request pages through every listed
+// activation, so that bursts of more activations than fit in one page are not missed.
+// Watching stops when handler returns false, when a list request fails with an error that is not transient, or when
+// maxWatchFailures list requests in a row fail.
+func (s *ActivationService) Watch(options *ActivationWatchOptions, handler func(activation Activation) bool) (*http.Response, error) {
+    interval := options.Interval
+    if interval <= 0 {
+        interval = DefaultWatchInterval
+    }
+
+    lookback := int64(options.Lookback / time.Millisecond)
+    if lookback <= 0 {
+        lookback = int64(DefaultWatchLookback / time.Millisecond)
+    }
+
+    since := options.Since
+    newest := options.Since             // Newest start time reported
+    reported := make(map[string]int64)  // Start times of the activations already passed to handler, by ID
+    failures := 0
+
+    for {
+        activations, resp, err := s.listNewActivations(options.Name, since)
+        if err != nil {
+            if failures++; failures >= maxWatchFailures || !isTransientWatchFailure(resp) {
+                return resp, err
+            }
+
+            Debug(DbgWarn, "Listing new activations failed %d times: %s; retrying in %s\n", failures, err, interval)
+            time.Sleep(interval)
+            continue
+        }
+        failures = 0
+
+        // Activations are listed newest first
+        sort.Stable(activationsByStart(activations))
+
+        for _, activation := range activations {
+            if _, ok := reported[activation.ActivationID]; ok {
+                continue
+            }
+
+            if len(options.Name) > 0 && activation.Name != path.Base(options.Name) {
+                continue
+            }
+
+            reported[activation.ActivationID] = activation.Start
+            if activation.Start > newest {
+                newest = activation.Start
+            }
+
+            if !handler(activation) {
+                return resp, nil
+            }
+        }
+
+        if newest - lookback > since {
+            since = newest - lookback
+        }
+
+        // Activations that started before the next request's since time cannot be listed again
+        for id, start := range reported {
+            if start < since {
+                delete(reported, id)
+            }
+        }
+
+        time.Sleep(interval)
+    }
+}
+
+// isTransientWatchFailure reports whether a failed list request may succeed when repeated: the server could not be
+// reached, was unavailable, or throttled the request
+func isTransientWatchFailure(resp *http.Response) bool {
+    return resp == nil || resp.StatusCode >= http.StatusInternalServerError ||
+        resp.StatusCode == http.StatusTooManyRequests
+}
+
+// listNewActivations lists the activations of name started since the given time, one page after the other, until a
+// page is not full
+func (s *ActivationService) listNewActivations(name string, since int64) ([]Activation, *http.Response, error) {
+    var activations []Activation
+
+    for skip := 0; ; skip += watchPageSize {
+        listOptions := &ActivationListOptions{
+            Name:  name,
+            Since: since,
+            Limit: watchPageSize,
+            Skip:  skip,
+            Docs:  true,
+        }
+
+        page, resp, err := s.List(listOptions)
+        if err != nil {
+            Debug(DbgError, "s.List(%#v) error: %s\n", listOptions, err)
+            return nil, resp, err
+        }
+        activations = append(activations, page...)
+
+        if len(page) < watchPageSize {
+            return activations, resp, nil
+        }
+    }
+}
+
+// FollowActivationLogs gets the activation every interval and sends each new log line on the returned channel, in
+// order. The logs route does not report when an activation ends, so the whole activation is fetched. The activation
+// is not found until it has been recorded, which may only happen when it ends, so 404 responses are retried until
+// stop is closed.
+// The first request that does not fail with a 404 is made before returning, and its error is returned. The channel
+// of lines is closed once the activation has ended, when stop is closed, or when a later request fails. The error of
+// a later request is then sent on the error channel, which is closed after the channel of lines.
+func (s *ActivationService) FollowActivationLogs(activationID string, interval time.Duration, stop <-chan struct{}) (<-chan string, <-chan error, error) {
+    if interval <= 0 {
+        interval = DefaultWatchInterval
+    }
+
+    activation, err := s.getFollowedActivation(activationID, interval, stop)
+    if err != nil {
+        return nil, nil, err
+    }
+
+    lines := make(chan string)
+    errs := make(chan error, 1)
+
+    go func() {
+        defer close(errs)
+        defer close(lines)
+        sent := 0
+
+        for activation != nil {
+            for ; sent < len(activation.Logs); sent++ {
+                select {
+                case lines <- activation.Logs[sent]:
+                case <-stop:
+                    return
+                }
+            }
+
+            if activation.End > 0 {
+                return
+            }
+
+            select {
+            case <-stop:
+                return
+            case <-time.After(interval):
+            }
+
+            if activation, err = s.getFollowedActivation(activationID, interval, stop); err != nil {
+                Debug(DbgError, "Following the logs of activation '%s' failed: %s\n", activationID, err)
+                errs <- err
+                return
+            }
+        }
+    }()
+
+    return lines, errs, nil
+}
+
+// getFollowedActivation gets an activation, retrying 404 responses every interval. The activation is nil, without an
+// error, when stop is closed while waiting to retry.
+func (s *ActivationService) getFollowedActivation(activationID string, interval time.Duration, stop <-chan struct{}) (*Activation, error) {
+    for {
+        activation, resp, err := s.Get(activationID)
+        if err == nil {
+            return activation, nil
+        }
+
+        if resp == nil || resp.StatusCode != http.StatusNotFound {
+            Debug(DbgError, "s.Get(%s) error: %s\n", activationID, err)
+            return nil, err
+        }
+
+        select {
+        case <-stop:
+            return nil, nil
+        case <-time.After(interval):
+        }
+    }
+}
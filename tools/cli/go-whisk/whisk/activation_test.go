@@ -0,0 +1,402 @@
+/*
+ * Copyright 2015-2016 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package whisk
+
+import (
+    "fmt"
+    "net/http"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+)
+
+// failedTestStage is a stage of a stagedActivationServer that fails with a 500 response
+var failedTestStage = &Activation{}
+
+// stagedActivationServer answers each get of activation 12345 with the next of its stages, and keeps repeating the
+// last one. A nil stage is a 404 response.
+type stagedActivationServer struct {
+    mutex       sync.Mutex
+    stages      []*Activation
+    requests    int
+}
+
+func (s *stagedActivationServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    s.mutex.Lock()
+    stage := s.stages[len(s.stages) - 1]
+    if s.requests < len(s.stages) {
+        stage = s.stages[s.requests]
+    }
+    s.requests++
+    s.mutex.Unlock()
+
+    if stage == nil {
+        writeTestJSON(w, http.StatusNotFound, map[string]interface{}{"error": "The requested resource does not exist.", "code": 1})
+    } else if stage == failedTestStage {
+        writeTestJSON(w, http.StatusInternalServerError, map[string]interface{}{"error": "get failed", "code": 1})
+    } else {
+        writeTestJSON(w, http.StatusOK, stage)
+    }
+}
+
+func (s *stagedActivationServer) getRequests() int {
+    s.mutex.Lock()
+    defer s.mutex.Unlock()
+
+    return s.requests
+}
+
+func getStagedActivation(end int64, logs ...string) *Activation {
+    return &Activation{Namespace: "ns", Name: "a", ActivationID: "12345", Start: 1, End: end, Logs: logs}
+}
+
+// followTestLogs follows the logs of activation 12345 from the stages, and returns the lines received and the error
+// of the first request or, after the lines, of a later request
+func followTestLogs(t *testing.T, staged *stagedActivationServer, stop chan struct{}) ([]string, error) {
+    client, server := newTestClient(t, staged.ServeHTTP)
+    defer server.Close()
+
+    lines, errs, err := client.Activations.FollowActivationLogs("12345", 10 * time.Millisecond, stop)
+    if err != nil {
+        return nil, err
+    }
+
+    var received []string
+    for line := range lines {
+        received = append(received, line)
+    }
+
+    return received, <-errs
+}
+
+func TestFollowActivationLogs(t *testing.T) {
+    staged := &stagedActivationServer{stages: []*Activation{
+        nil,
+        nil,
+        getStagedActivation(0),
+        getStagedActivation(0, "l1"),
+        getStagedActivation(0, "l1", "l2", "l3"),
+        getStagedActivation(0, "l1", "l2", "l3"),
+        getStagedActivation(5, "l1", "l2", "l3", "l4"),
+    }}
+
+    // The 404 responses before the activation is recorded are retried, and each line is received once
+    lines, err := followTestLogs(t, staged, make(chan struct{}))
+    if err != nil {
+        t.Fatalf("FollowActivationLogs() failed: %s", err)
+    }
+    if strings.Join(lines, " ") != "l1 l2 l3 l4" {
+        t.Errorf("FollowActivationLogs() received the lines %q, want l1 to l4", lines)
+    }
+    if requests := staged.getRequests(); requests != len(staged.stages) {
+        t.Errorf("FollowActivationLogs() made %d requests, want %d", requests, len(staged.stages))
+    }
+}
+
+func TestFollowActivationLogsNotFound(t *testing.T) {
+    // Activations may only be recorded when they end, so a long run of 404 responses is retried
+    var stages []*Activation
+    for i := 0; i < 20; i++ {
+        stages = append(stages, nil)
+    }
+    staged := &stagedActivationServer{stages: append(stages, getStagedActivation(5, "l1"))}
+
+    lines, err := followTestLogs(t, staged, make(chan struct{}))
+    if err != nil {
+        t.Fatalf("FollowActivationLogs() after 20 404 responses failed: %s", err)
+    }
+    if strings.Join(lines, " ") != "l1" {
+        t.Errorf("FollowActivationLogs() received the lines %q, want l1", lines)
+    }
+}
+
+func TestFollowActivationLogsErrors(t *testing.T) {
+    tests := []struct {
+        stages  []*Activation
+        want    string
+    }{
+        // The first request fails
+        {[]*Activation{nil, failedTestStage}, ""},
+        // A later request fails after some lines were received
+        {[]*Activation{getStagedActivation(0, "l1"), failedTestStage}, "l1"},
+    }
+
+    for _, test := range tests {
+        lines, err := followTestLogs(t, &stagedActivationServer{stages: test.stages}, make(chan struct{}))
+        if whiskErr, ok := err.(*WskError); !ok || whiskErr.ExitCode != http.StatusInternalServerError - 256 {
+            t.Errorf("FollowActivationLogs() of the lines %q error = %#v, want the server error", test.want, err)
+        }
+        if strings.Join(lines, " ") != test.want {
+            t.Errorf("FollowActivationLogs() received the lines %q, want %q", lines, test.want)
+        }
+    }
+}
+
+func TestFollowActivationLogsStop(t *testing.T) {
+    staged := &stagedActivationServer{stages: []*Activation{getStagedActivation(0, "l1")}}
+    stop := make(chan struct{})
+    time.AfterFunc(50 * time.Millisecond, func() { close(stop) })
+
+    // The activation never ends, so only stopping closes the channel
+    lines, err := followTestLogs(t, staged, stop)
+    if err != nil {
+        t.Fatalf("FollowActivationLogs() failed: %s", err)
+    }
+    if strings.Join(lines, " ") != "l1" {
+        t.Errorf("FollowActivationLogs() received the lines %q, want l1", lines)
+    }
+}
+
+// receiveTestLines receives count lines from lines, or fails the test when they are not sent in time
+func receiveTestLines(t *testing.T, lines <-chan string, count int) []string {
+    var received []string
+    for len(received) < count {
+        select {
+        case line, ok := <-lines:
+            if !ok {
+                t.Fatalf("log channel closed after the lines %q, want %d lines", received, count)
+            }
+            received = append(received, line)
+        case <-time.After(5 * time.Second):
+            t.Fatalf("received the lines %q, want %d lines", received, count)
+        }
+    }
+
+    return received
+}
+
+func TestLogsPoller(t *testing.T) {
+    staged := &stagedActivationServer{stages: []*Activation{
+        nil,
+        getStagedActivation(0),
+        getStagedActivation(0, "l1"),
+        getStagedActivation(0, "l1"),
+        getStagedActivation(0, "l1", "l2", "l3"),
+        getStagedActivation(5, "l1", "l2", "l3", "l4"),
+    }}
+    client, server := newTestClient(t, staged.ServeHTTP)
+    defer server.Close()
+
+    poller := client.Activations.NewLogsPoller()
+    poller.Interval = 10 * time.Millisecond
+    lines := poller.Start("12345")
+
+    // The logs accumulate over the polls, and each line is sent once, in order
+    received := receiveTestLines(t, lines, 4)
+    if strings.Join(received, " ") != "l1 l2 l3 l4" {
+        t.Errorf("LogsPoller sent the lines %q, want l1 to l4", received)
+    }
+
+    // Polling goes on after the activation has ended, but sends no more lines
+    time.Sleep(50 * time.Millisecond)
+    poller.Stop()
+    for line := range lines {
+        t.Errorf("LogsPoller sent the line %q again", line)
+    }
+    if requests := staged.getRequests(); requests <= len(staged.stages) {
+        t.Errorf("LogsPoller made %d requests, want it to poll until it is stopped", requests)
+    }
+}
+
+func TestLogsPollerStop(t *testing.T) {
+    staged := &stagedActivationServer{stages: []*Activation{getStagedActivation(0, "l1", "l2")}}
+    client, server := newTestClient(t, staged.ServeHTTP)
+    defer server.Close()
+
+    poller := client.Activations.NewLogsPoller()
+    poller.Interval = 10 * time.Millisecond
+    lines := poller.Start("12345")
+    receiveTestLines(t, lines, 1)
+
+    // Stop returns even though the poller is waiting to send l2, and closes the channel
+    stopped := make(chan struct{})
+    go func() {
+        poller.Stop()
+        close(stopped)
+    }()
+    select {
+    case <-stopped:
+    case <-time.After(5 * time.Second):
+        t.Fatalf("LogsPoller.Stop() did not return while a line was waiting to be received")
+    }
+    if _, ok := <-lines; ok {
+        t.Errorf("LogsPoller sent a line after it was stopped")
+    }
+}
+
+func TestActivationRequestsKeepNamespace(t *testing.T) {
+    var mutex sync.Mutex
+    var paths []string
+    client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+        mutex.Lock()
+        paths = append(paths, r.URL.Path)
+        mutex.Unlock()
+        if strings.HasSuffix(r.URL.Path, "/activations") {
+            writeTestJSON(w, http.StatusOK, []Activation{})
+        } else {
+            writeTestJSON(w, http.StatusOK, getStagedActivation(5, "l1"))
+        }
+    })
+    defer server.Close()
+
+    // Activations are requested in the "_" namespace while other requests use the namespace of the client
+    var wait sync.WaitGroup
+    calls := []func(){
+        func() { client.Activations.Get("12345") },
+        func() { client.Activations.Logs("12345") },
+        func() { client.Activations.Result("12345") },
+        func() { client.Activations.List(&ActivationListOptions{}) },
+        func() { client.NewRequest("GET", "actions/a", nil, IncludeNamespaceInUrl) },
+    }
+    for _, call := range calls {
+        wait.Add(1)
+        go func(call func()) {
+            defer wait.Done()
+            call()
+        }(call)
+    }
+    wait.Wait()
+
+    if client.Namespace != "ns" {
+        t.Errorf("activation requests changed the namespace of the client to %q", client.Namespace)
+    }
+    want := map[string]bool{
+        "/api/v1/namespaces/_/activations/12345": true,
+        "/api/v1/namespaces/_/activations/12345/logs": true,
+        "/api/v1/namespaces/_/activations/12345/result": true,
+        "/api/v1/namespaces/_/activations": true,
+    }
+    for _, path := range paths {
+        if !want[path] {
+            t.Errorf("activation request of %s, want a path below /api/v1/namespaces/_/activations", path)
+        }
+        delete(want, path)
+    }
+    if len(want) > 0 {
+        t.Errorf("no activation requests of %v", want)
+    }
+}
+
+// watchTestServer answers each activation list request with the next of its polls, filtered by the since query
+// parameter, and an empty list once the polls are used up. A nil poll is answered with the status failStatus. It
+// records the since parameter of each request.
+type watchTestServer struct {
+    mutex       sync.Mutex
+    polls       [][]Activation
+    failStatus  int
+    sinces      []string
+}
+
+func (s *watchTestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    s.mutex.Lock()
+    defer s.mutex.Unlock()
+
+    s.sinces = append(s.sinces, r.URL.Query().Get("since"))
+    if len(s.sinces) > len(s.polls) {
+        writeTestJSON(w, http.StatusOK, []Activation{})
+        return
+    }
+
+    poll := s.polls[len(s.sinces) - 1]
+    if poll == nil {
+        writeTestJSON(w, s.failStatus, map[string]interface{}{"error": "list failed", "code": 1})
+        return
+    }
+
+    var since int64
+    fmt.Sscan(r.URL.Query().Get("since"), &since)
+    listed := []Activation{}
+    for _, activation := range poll {
+        if activation.Start >= since {
+            listed = append(listed, activation)
+        }
+    }
+    writeTestJSON(w, http.StatusOK, listed)
+}
+
+func getWatchedActivation(id string, start int64) Activation {
+    return Activation{Namespace: "ns", Name: "a", ActivationID: id, Start: start, End: start + 1}
+}
+
+// watchTestActivations watches the activations of the server until count are reported, and returns their IDs
+func watchTestActivations(t *testing.T, server *watchTestServer, count int) ([]string, error) {
+    client, testServer := newTestClient(t, server.ServeHTTP)
+    defer testServer.Close()
+
+    var ids []string
+    options := &ActivationWatchOptions{Since: 1000, Interval: time.Millisecond, Lookback: 100 * time.Millisecond}
+    _, err := client.Activations.Watch(options, func(activation Activation) bool {
+        ids = append(ids, activation.ActivationID)
+        return len(ids) < count
+    })
+
+    return ids, err
+}
+
+func TestWatch(t *testing.T) {
+    server := &watchTestServer{failStatus: http.StatusBadGateway, polls: [][]Activation{
+        {getWatchedActivation("b", 1050), getWatchedActivation("a", 1020)},
+        // c started before b but ended after it was reported, and b is listed again
+        {getWatchedActivation("b", 1050), getWatchedActivation("c", 1030), getWatchedActivation("a", 1020)},
+        // A transient failure is retried
+        nil,
+        {getWatchedActivation("d", 1500), getWatchedActivation("b", 1050)},
+        {getWatchedActivation("e", 1600), getWatchedActivation("d", 1500)},
+    }}
+
+    ids, err := watchTestActivations(t, server, 5)
+    if err != nil {
+        t.Fatalf("Watch() failed: %s", err)
+    }
+
+    // Each activation is reported once, and the since time trails the newest start by the lookback, but never goes back
+    // before the requested since time
+    if strings.Join(ids, " ") != "a b c d e" {
+        t.Errorf("Watch() reported the activations %q, want a to e", ids)
+    }
+    if want := "1000 1000 1000 1000 1400"; strings.Join(server.sinces, " ") != want {
+        t.Errorf("Watch() listed since %s, want %s", strings.Join(server.sinces, " "), want)
+    }
+}
+
+func TestWatchErrors(t *testing.T) {
+    tests := []struct {
+        status      int
+        failures    int
+        requests    int
+    }{
+        // An error that is not transient stops watching at once
+        {http.StatusForbidden, 1, 1},
+        // Transient errors stop watching once maxWatchFailures requests in a row failed
+        {http.StatusServiceUnavailable, maxWatchFailures, maxWatchFailures},
+        {http.StatusTooManyRequests, maxWatchFailures, maxWatchFailures},
+    }
+
+    for _, test := range tests {
+        server := &watchTestServer{failStatus: test.status, polls: make([][]Activation, test.failures)}
+
+        ids, err := watchTestActivations(t, server, 1)
+        if whiskErr, ok := err.(*WskError); !ok || whiskErr.ExitCode != test.status - 256 {
+            t.Errorf("Watch() with %d responses error = %#v, want the server error", test.status, err)
+        }
+        if len(ids) > 0 || len(server.sinces) != test.requests {
+            t.Errorf("Watch() with %d responses reported %q after %d requests, want none after %d", test.status,
+                ids, len(server.sinces), test.requests)
+        }
+    }
+}
@@ -0,0 +1,257 @@
+/*
+ * Copyright 2015-2016 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+
+package whisk
+
+import (
+    "encoding/json"
+    "io/ioutil"
+    "net/http"
+    "net/url"
+    "reflect"
+    "strings"
+    "testing"
+)
+
+// apiTestRequest is a request received by an apiTestHandler
+type apiTestRequest struct {
+    method  string
+    path    string
+    query   url.Values
+    body    string
+}
+
+// apiTestHandler records the last request it receives in request, and answers it with the given status and response
+func apiTestHandler(request *apiTestRequest, status int, response interface{}) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        body, _ := ioutil.ReadAll(r.Body)
+        *request = apiTestRequest{method: r.Method, path: r.URL.Path, query: r.URL.Query(), body: string(body)}
+        writeTestJSON(w, status, response)
+    }
+}
+
+// getTestApi returns an API at base path /base with a get and a post operation on /path, and a get on /other
+func getTestApi() *RetApiV2 {
+    operation := func(id string, action string) *ApiSwaggerOperationV2 {
+        return &ApiSwaggerOperationV2{OperationId: id,
+            XOpenWhisk: &ApiSwaggerOpXOpenWhiskV2{ActionName: action, Namespace: "ns", Package: "pkg",
+                ApiUrl: "https://host/api/v1/web/ns/pkg/" + action + ".http"}}
+    }
+
+    return &RetApiV2{
+        Namespace: "ns",
+        BaseUrl:   "https://gw/api/guid/base",
+        Swagger: &ApiSwaggerV2{
+            SwaggerName: "2.0",
+            BasePath:    "/base",
+            Info:        &ApiSwaggerInfo{Title: "base", Version: "1.0"},
+            Paths: map[string]map[string]*ApiSwaggerOperationV2{
+                "/path":  {"get": operation("getPath", "a"), "post": operation("postPath", "b")},
+                "/other": {"get": operation("getOther", "c")},
+            },
+        },
+    }
+}
+
+func TestApiListV2(t *testing.T) {
+    var request apiTestRequest
+    apis := RetApiArrayV2{Apis: []ApiItemV2{{ApiId: "API:ns:/base", ApiValue: getTestApi()}}}
+    client, server := newTestClient(t, apiTestHandler(&request, http.StatusOK, apis))
+    defer server.Close()
+
+    options := &ApiListRequestOptions{Limit: 5, Skip: 2}
+    options.SpaceGuid, options.AccessToken = "guid", "token"
+    list, _, err := client.Apis.ListV2(options)
+    if err != nil {
+        t.Fatalf("ListV2() failed: %s", err)
+    }
+
+    wantQuery := url.Values{"limit": {"5"}, "skip": {"2"}, "spaceguid": {"guid"}, "accesstoken": {"token"}}
+    if request.method != "GET" || request.path != "/api/v1/web/whisk.system/apimgmt/getApi.http" ||
+            !reflect.DeepEqual(request.query, wantQuery) {
+        t.Errorf("ListV2() sent %s %s?%s, want GET /api/v1/web/whisk.system/apimgmt/getApi.http?%s", request.method,
+            request.path, request.query.Encode(), wantQuery.Encode())
+    }
+    if !reflect.DeepEqual(*list, ApiListResponseV2(apis)) {
+        t.Errorf("ListV2() = %#v, want %#v", *list, apis)
+    }
+}
+
+func TestApiListV2InvalidResponse(t *testing.T) {
+    withoutDoc := getTestApi()
+    withoutDoc.Swagger = nil
+    withoutOperationId := getTestApi()
+    withoutOperationId.Swagger.Paths["/path"]["get"].OperationId = ""
+    withoutExtension := getTestApi()
+    withoutExtension.Swagger.Paths["/other"]["get"].XOpenWhisk = nil
+    withoutUrl := getTestApi()
+    withoutUrl.Swagger.Paths["/path"]["post"].XOpenWhisk.ApiUrl = ""
+
+    tests := []struct {
+        apis    RetApiArrayV2
+        want    string
+    }{
+        {RetApiArrayV2{Apis: []ApiItemV2{{ApiId: "API:ns:/base"}}},
+            "Internal error. Missing value stanza in API configuration response"},
+        {RetApiArrayV2{Apis: []ApiItemV2{{ApiValue: withoutDoc}}},
+            "Internal error. Missing apidoc stanza in API configuration"},
+        {RetApiArrayV2{Apis: []ApiItemV2{{ApiValue: withoutOperationId}}},
+            "Missing operationId field in API configuration for operation"},
+        {RetApiArrayV2{Apis: []ApiItemV2{{ApiValue: withoutExtension}}},
+            "Missing x-openwhisk stanza in API configuration for operation"},
+        {RetApiArrayV2{Apis: []ApiItemV2{{ApiValue: withoutUrl}}},
+            "Missing x-openwhisk.url field in API configuration for operation"},
+    }
+
+    for _, test := range tests {
+        var request apiTestRequest
+        client, server := newTestClient(t, apiTestHandler(&request, http.StatusOK, test.apis))
+        _, _, err := client.Apis.ListV2(&ApiListRequestOptions{})
+        server.Close()
+
+        if whiskErr, ok := err.(*WskError); !ok || !strings.HasPrefix(whiskErr.Error(), test.want) ||
+                whiskErr.ExitCode != EXITCODE_ERR_NETWORK {
+            t.Errorf("ListV2() of an invalid API error = %#v, want %q with exit code %d", err, test.want,
+                EXITCODE_ERR_NETWORK)
+        }
+    }
+}
+
+func TestApiInsertV2(t *testing.T) {
+    var request apiTestRequest
+    created := getTestApi()
+    client, server := newTestClient(t, apiTestHandler(&request, http.StatusOK, created))
+    defer server.Close()
+
+    api := &Api{
+        Namespace:       "ns",
+        GatewayBasePath: "/base",
+        GatewayRelPath:  "/path",
+        GatewayMethod:   "GET",
+        Action:          &ApiAction{Name: "pkg/a", Namespace: "ns", BackendMethod: "GET"},
+    }
+    options := &ApiCreateRequestOptions{SpaceGuid: "guid", AccessToken: "token", ResponseType: "http"}
+    result, _, err := client.Apis.InsertV2(&ApiCreateRequest{ApiDoc: api}, options, DoNotOverwrite)
+    if err != nil {
+        t.Fatalf("InsertV2() failed: %s", err)
+    }
+
+    wantQuery := url.Values{"spaceguid": {"guid"}, "accesstoken": {"token"}, "responsetype": {"http"}}
+    if request.method != "POST" || request.path != "/api/v1/web/whisk.system/apimgmt/createApi.http" ||
+            !reflect.DeepEqual(request.query, wantQuery) {
+        t.Errorf("InsertV2() sent %s %s?%s, want POST /api/v1/web/whisk.system/apimgmt/createApi.http?%s",
+            request.method, request.path, request.query.Encode(), wantQuery.Encode())
+    }
+
+    var body ApiCreateRequest
+    if err = json.Unmarshal([]byte(request.body), &body); err != nil || !reflect.DeepEqual(body.ApiDoc, api) {
+        t.Errorf("InsertV2() sent the body %s, want the API %#v", request.body, api)
+    }
+    if !reflect.DeepEqual(*result, ApiCreateResponseV2(*created)) {
+        t.Errorf("InsertV2() = %#v, want %#v", *result, *created)
+    }
+}
+
+func TestApiInsertV2Errors(t *testing.T) {
+    withoutOperationId := getTestApi()
+    withoutOperationId.Swagger.Paths["/other"]["get"].OperationId = ""
+
+    tests := []struct {
+        status      int
+        response    interface{}
+        want        string
+    }{
+        {http.StatusOK, withoutOperationId, "Missing operationId field in API configuration for operation"},
+        {http.StatusOK, RetApiV2{Namespace: "ns"}, "Internal error. Missing apidoc stanza in API configuration"},
+        {http.StatusBadRequest, map[string]interface{}{"error": "action is not a web action", "code": 1},
+            "action is not a web action (code 1)"},
+    }
+
+    for _, test := range tests {
+        var request apiTestRequest
+        client, server := newTestClient(t, apiTestHandler(&request, test.status, test.response))
+        _, _, err := client.Apis.InsertV2(&ApiCreateRequest{ApiDoc: &Api{}}, &ApiCreateRequestOptions{}, DoNotOverwrite)
+        server.Close()
+
+        if err == nil || !strings.Contains(err.Error(), test.want) {
+            t.Errorf("InsertV2() answered with %d %#v error = %v, want %q", test.status, test.response, err, test.want)
+        }
+    }
+}
+
+func TestApiGetV2(t *testing.T) {
+    var request apiTestRequest
+    apis := RetApiArrayV2{Apis: []ApiItemV2{{ApiId: "API:ns:/base", ApiValue: getTestApi()}}}
+    client, server := newTestClient(t, apiTestHandler(&request, http.StatusOK, apis))
+    defer server.Close()
+
+    options := &ApiGetRequestOptions{ApiBasePath: "/base", ApiRelPath: "/path", ApiVerb: "GET", SpaceGuid: "guid",
+        AccessToken: "token"}
+    result, _, err := client.Apis.GetV2(&ApiGetRequest{}, options)
+    if err != nil {
+        t.Fatalf("GetV2() failed: %s", err)
+    }
+
+    wantQuery := url.Values{"basepath": {"/base"}, "relpath": {"/path"}, "operation": {"GET"},
+        "spaceguid": {"guid"}, "accesstoken": {"token"}}
+    if request.method != "GET" || request.path != "/api/v1/web/whisk.system/apimgmt/getApi.http" ||
+            !reflect.DeepEqual(request.query, wantQuery) {
+        t.Errorf("GetV2() sent %s %s?%s, want GET /api/v1/web/whisk.system/apimgmt/getApi.http?%s", request.method,
+            request.path, request.query.Encode(), wantQuery.Encode())
+    }
+    if !reflect.DeepEqual(*result, ApiGetResponseV2(apis)) {
+        t.Errorf("GetV2() = %#v, want %#v", *result, apis)
+    }
+}
+
+func TestApiDeleteV2(t *testing.T) {
+    tests := []struct {
+        options     ApiDeleteRequestOptions
+        query       url.Values
+    }{
+        {ApiDeleteRequestOptions{ApiBasePath: "/base"}, url.Values{"basepath": {"/base"}}},
+        {ApiDeleteRequestOptions{ApiBasePath: "/base", ApiRelPath: "/path"},
+            url.Values{"basepath": {"/base"}, "relpath": {"/path"}}},
+        {ApiDeleteRequestOptions{ApiBasePath: "base", ApiRelPath: "/path", ApiVerb: "POST"},
+            url.Values{"basepath": {"base"}, "relpath": {"/path"}, "operation": {"POST"}}},
+    }
+
+    for _, test := range tests {
+        var request apiTestRequest
+        client, server := newTestClient(t, apiTestHandler(&request, http.StatusOK, map[string]interface{}{}))
+        _, err := client.Apis.DeleteV2(&ApiDeleteRequest{}, &test.options)
+        server.Close()
+
+        if err != nil {
+            t.Errorf("DeleteV2(%#v) failed: %s", test.options, err)
+        }
+        if request.method != "DELETE" || request.path != "/api/v1/web/whisk.system/apimgmt/deleteApi.http" ||
+                !reflect.DeepEqual(request.query, test.query) {
+            t.Errorf("DeleteV2(%#v) sent %s %s?%s, want DELETE /api/v1/web/whisk.system/apimgmt/deleteApi.http?%s",
+                test.options, request.method, request.path, request.query.Encode(), test.query.Encode())
+        }
+    }
+
+    var request apiTestRequest
+    client, server := newTestClient(t, apiTestHandler(&request, http.StatusNotFound,
+        map[string]interface{}{"error": "API '/base' does not exist", "code": 1}))
+    defer server.Close()
+
+    if _, err := client.Apis.DeleteV2(&ApiDeleteRequest{}, &ApiDeleteRequestOptions{ApiBasePath: "/base"}); err == nil {
+        t.Errorf("DeleteV2() of a missing API succeeded, want an error")
+    }
+}
@@ -17,7 +17,9 @@
 package whisk
 
 import (
+    "context"
     "bytes"
+    "compress/gzip"
     "encoding/base64"
     "encoding/json"
     "fmt"
@@ -26,10 +28,14 @@ import (
     "net/http"
     "net/url"
     "crypto/tls"
+    "crypto/x509"
     "errors"
     "reflect"
     "../wski18n"
     "strings"
+    "net"
+    "strconv"
+    "time"
 )
 
 const (
@@ -46,6 +52,11 @@ const (
     DoNotProcessTimeOut = false
     ExitWithErrorOnTimeout = true
     ExitWithSuccessOnTimeout = false
+    DefaultRetryDelay = 500 * time.Millisecond
+    DefaultMaxRetryDelay = 30 * time.Second
+    DefaultDialTimeout = 10 * time.Second
+    DefaultRequestTimeout = 30 * time.Second
+    DefaultDownloadIdleTimeout = 30 * time.Second
 )
 
 type Client struct {
@@ -64,6 +75,14 @@ type Client struct {
     Apis        *ApiService
 }
 
+// RetryOptions control how requests that fail with a transient error are retried.
+// Requests are not retried when MaxRetries is zero.
+type RetryOptions struct {
+    MaxRetries      int             // Number of times a request is retried
+    InitialDelay    time.Duration   // Delay before the first retry; doubled on each further attempt
+    MaxDelay        time.Duration   // Upper bound of the delay between attempts, including Retry-After delays
+}
+
 type Config struct {
     Namespace 	string // NOTE :: Default is "_"
     AuthToken 	string
@@ -73,28 +92,40 @@ type Config struct {
     Verbose   	bool
     Debug       bool     // For detailed tracing
     Insecure    bool
+    RetryOptions
+    ProxyURL    string   // NOTE :: HTTPS_PROXY/HTTP_PROXY are used when not set
+    CAFile      string   // PEM bundle of additional trusted certificate authorities
+    TLSConfig   *tls.Config      // Base TLS settings, such as client certificates and trusted CAs; Insecure and CAFile
+                                 // are applied on top of them
+    DialTimeout time.Duration    // Time allowed to connect to the API host; DefaultDialTimeout when zero, none when negative
+    RequestTimeout time.Duration // Time allowed for a request and its retries; DefaultRequestTimeout when zero, none when
+                                 // negative. Blocking invocations are allowed the time limit of the action instead.
+    DownloadIdleTimeout time.Duration // Time an SDK download may go without receiving data before it is abandoned;
+                                      // DefaultDownloadIdleTimeout when zero, none when negative
 }
 
 func NewClient(httpClient *http.Client, config *Config) (*Client, error) {
+    var transport *http.Transport
+    var err error
 
-    // Disable certificate checking in the dev environment if in insecure mode
-    if config.Insecure {
-        Debug(DbgInfo, "Disabling certificate checking.\n")
-
-        tlsConfig := &tls.Config{
-            InsecureSkipVerify: true,
-        }
+    // The client and transport of the caller are not changed, as they may be shared, such as http.DefaultClient; the
+    // settings below are applied to copies of them
+    if httpClient == nil {
+        httpClient = &http.Client{}
+    } else {
+        clientCopy := *httpClient
+        httpClient = &clientCopy
+    }
 
-        http.DefaultClient.Transport = &http.Transport{
-            TLSClientConfig: tlsConfig,
+    if httpClient.Transport == nil || config.Insecure || len(config.ProxyURL) > 0 || len(config.CAFile) > 0 ||
+            config.TLSConfig != nil {
+        if transport, err = newTransport(httpClient.Transport, config); err != nil {
+            return nil, err
         }
-    }
 
-    if httpClient == nil {
-        httpClient = http.DefaultClient
+        httpClient.Transport = transport
     }
 
-    var err error
     if config.BaseURL == nil {
         config.BaseURL, err = url.Parse(defaultBaseURL)
         if err != nil {
@@ -117,6 +148,7 @@ func NewClient(httpClient *http.Client, config *Config) (*Client, error) {
     c := &Client{
         client: httpClient,
         Config: config,
+        Transport: transport,
     }
 
     c.Sdks = &SdkService{client: c}
@@ -132,11 +164,117 @@ func NewClient(httpClient *http.Client, config *Config) (*Client, error) {
     return c, nil
 }
 
+// newTransport creates an HTTP transport honoring the proxy, TLS settings, CA bundle, certificate checking and dial
+// timeout configuration. When the caller supplied a transport as base, a clone of it is returned with the proxy, TLS
+// and certificate settings of the configuration applied, and its own dialer kept. Other kinds of round tripper cannot
+// take these settings.
+func newTransport(base http.RoundTripper, config *Config) (*http.Transport, error) {
+    var transport *http.Transport
+
+    switch base := base.(type) {
+    case nil:
+        dialTimeout := getTimeout(config.DialTimeout, DefaultDialTimeout)
+        dialer := &net.Dialer{
+            Timeout: dialTimeout,
+            KeepAlive: 30 * time.Second,
+        }
+
+        transport = &http.Transport{
+            Proxy: http.ProxyFromEnvironment,
+            DialContext: dialer.DialContext,
+            TLSHandshakeTimeout: dialTimeout,
+            IdleConnTimeout: 90 * time.Second,
+        }
+    case *http.Transport:
+        transport = base.Clone()
+    default:
+        Debug(DbgError, "Unable to apply the proxy and TLS settings to the transport %T\n", base)
+        errStr := wski18n.T("The proxy, CA file and TLS settings require an *http.Transport, not {{.type}}",
+            map[string]interface{}{"type": fmt.Sprintf("%T", base)})
+        werr := MakeWskError(errors.New(errStr), EXITCODE_ERR_GENERAL, DISPLAY_MSG, NO_DISPLAY_USAGE)
+        return nil, werr
+    }
+
+    tlsConfig := &tls.Config{}
+    if config.TLSConfig != nil {
+        tlsConfig = config.TLSConfig.Clone()
+    } else if transport.TLSClientConfig != nil {
+        tlsConfig = transport.TLSClientConfig.Clone()
+    }
+    transport.TLSClientConfig = tlsConfig
+
+    // Disable certificate checking in the dev environment if in insecure mode
+    if config.Insecure {
+        Debug(DbgInfo, "Disabling certificate checking.\n")
+        tlsConfig.InsecureSkipVerify = true
+    }
+
+    if len(config.ProxyURL) > 0 {
+        proxyURL, err := url.Parse(config.ProxyURL)
+        if err != nil {
+            Debug(DbgError, "url.Parse(%s) error: %s\n", config.ProxyURL, err)
+            errStr := wski18n.T("Invalid proxy URL '{{.url}}': {{.err}}",
+                map[string]interface{}{"url": config.ProxyURL, "err": err})
+            werr := MakeWskError(errors.New(errStr), EXITCODE_ERR_GENERAL, DISPLAY_MSG, NO_DISPLAY_USAGE)
+            return nil, werr
+        }
+        Debug(DbgInfo, "Using proxy %s\n", proxyURL)
+        transport.Proxy = http.ProxyURL(proxyURL)
+    }
+
+    if len(config.CAFile) > 0 {
+        pem, err := ioutil.ReadFile(config.CAFile)
+        if err != nil {
+            Debug(DbgError, "ioutil.ReadFile(%s) error: %s\n", config.CAFile, err)
+            errStr := wski18n.T("Unable to read the CA certificate file '{{.file}}': {{.err}}",
+                map[string]interface{}{"file": config.CAFile, "err": err})
+            werr := MakeWskError(errors.New(errStr), EXITCODE_ERR_GENERAL, DISPLAY_MSG, NO_DISPLAY_USAGE)
+            return nil, werr
+        }
+
+        certPool, err := x509.SystemCertPool()
+        if err != nil {
+            Debug(DbgWarn, "x509.SystemCertPool() error: %s; only the CA file will be trusted\n", err)
+            certPool = x509.NewCertPool()
+        }
+
+        if !certPool.AppendCertsFromPEM(pem) {
+            Debug(DbgError, "No PEM encoded certificates found in %s\n", config.CAFile)
+            errStr := wski18n.T("The CA certificate file '{{.file}}' does not contain any PEM encoded certificates",
+                map[string]interface{}{"file": config.CAFile})
+            werr := MakeWskError(errors.New(errStr), EXITCODE_ERR_GENERAL, DISPLAY_MSG, NO_DISPLAY_USAGE)
+            return nil, werr
+        }
+        tlsConfig.RootCAs = certPool
+    }
+
+    return transport, nil
+}
+
+// getTimeout returns timeout, or defaultTimeout when timeout is zero. A negative timeout, which means there is no time
+// limit, is returned as zero.
+func getTimeout(timeout time.Duration, defaultTimeout time.Duration) time.Duration {
+    if timeout == 0 {
+        return defaultTimeout
+    } else if timeout < 0 {
+        return 0
+    }
+
+    return timeout
+}
+
 ///////////////////////////////
 // Request/Utility Functions //
 ///////////////////////////////
 
 func (c *Client) NewRequest(method, urlStr string, body interface{}, includeNamespaceInUrl bool) (*http.Request, error) {
+    return c.NewRequestWithContext(context.Background(), method, urlStr, body, includeNamespaceInUrl)
+}
+
+// NewRequestWithContext creates a request like NewRequest that is bound to ctx; the request, including any retries,
+// is abandoned when ctx is cancelled or its deadline passes
+func (c *Client) NewRequestWithContext(ctx context.Context, method, urlStr string, body interface{},
+    includeNamespaceInUrl bool) (*http.Request, error) {
     if (includeNamespaceInUrl) {
         if c.Config.Namespace != "" {
             urlStr = fmt.Sprintf("%s/namespaces/%s/%s", c.Config.Version, c.Config.Namespace, urlStr)
@@ -179,6 +317,8 @@ func (c *Client) NewRequest(method, urlStr string, body interface{}, includeName
         werr := MakeWskError(errors.New(errStr), EXITCODE_ERR_GENERAL, DISPLAY_MSG, NO_DISPLAY_USAGE)
         return nil, werr
     }
+    req = req.WithContext(ctx)
+
     if req.Body != nil {
         req.Header.Add("Content-Type", "application/json")
     }
@@ -211,6 +351,135 @@ func (c *Client) addAuthHeader(req *http.Request, authRequired bool) error {
     return nil
 }
 
+// doWithRetries issues the request and retries it up to MaxRetries times with an exponential backoff when
+// an idempotent request fails with a transient network error or a 429, 502, 503 or 504 response. Other requests,
+// such as invocations and trigger fires, are never retried, since such a response does not prove that the server
+// did not process them.
+func (c *Client) doWithRetries(req *http.Request) (*http.Response, error) {
+    delay := c.Config.InitialDelay
+    if delay <= 0 {
+        delay = DefaultRetryDelay
+    }
+
+    maxDelay := c.Config.MaxDelay
+    if maxDelay <= 0 {
+        maxDelay = DefaultMaxRetryDelay
+    }
+
+    for attempt := 0; ; attempt++ {
+        resp, err := c.client.Do(req)
+
+        if attempt >= c.Config.MaxRetries || !isRetryable(req, resp, err) {
+            return resp, err
+        }
+
+        wait := delay
+        if err != nil {
+            Debug(DbgWarn, "Attempt %d of %d for %s %s failed: %s; retrying in %s\n", attempt+1, c.Config.MaxRetries+1,
+                req.Method, req.URL.String(), err, wait)
+        } else {
+            if retryAfter, ok := getRetryAfter(resp); ok {
+                wait = retryAfter
+            }
+            if wait > maxDelay {
+                wait = maxDelay
+            }
+            Debug(DbgWarn, "Attempt %d of %d for %s %s failed with HTTP status %d; retrying in %s\n", attempt+1,
+                c.Config.MaxRetries+1, req.Method, req.URL.String(), resp.StatusCode, wait)
+            resp.Body.Close()
+        }
+
+        if req.Body != nil {
+            if req.GetBody == nil {
+                return resp, err
+            }
+            if req.Body, err = req.GetBody(); err != nil {
+                Debug(DbgError, "req.GetBody() error: %s\n", err)
+                return nil, err
+            }
+        }
+
+        select {
+        case <-time.After(wait):
+        case <-req.Context().Done():
+            Debug(DbgError, "Giving up on %s %s: %s\n", req.Method, req.URL.String(), req.Context().Err())
+            return nil, req.Context().Err()
+        }
+        if delay *= 2; delay > maxDelay {
+            delay = maxDelay
+        }
+    }
+}
+
+// getRetryAfter returns the delay requested by the Retry-After header of the response, if any
+func getRetryAfter(resp *http.Response) (time.Duration, bool) {
+    retryAfter := resp.Header.Get("Retry-After")
+    if len(retryAfter) == 0 {
+        return 0, false
+    }
+
+    if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+        return time.Duration(seconds) * time.Second, true
+    }
+
+    if date, err := http.ParseTime(retryAfter); err == nil {
+        if wait := date.Sub(time.Now()); wait > 0 {
+            return wait, true
+        }
+        return 0, true
+    }
+
+    Debug(DbgWarn, "Ignoring invalid Retry-After header '%s'\n", retryAfter)
+    return 0, false
+}
+
+// isIdempotentRequest reports whether the request can safely be issued more than once
+func isIdempotentRequest(req *http.Request) bool {
+    switch req.Method {
+    case "GET", "HEAD", "DELETE":
+        return true
+    case "PUT":
+        return req.URL.Query().Get("overwrite") == "true"
+    }
+
+    return false
+}
+
+// isRetryable reports whether the outcome of a request indicates a transient failure that is safe to retry
+func isRetryable(req *http.Request, resp *http.Response, err error) bool {
+    if err != nil {
+        return isIdempotentRequest(req) && isTransientNetworkError(err)
+    }
+
+    // A proxy may answer 502, 503 or 504, or throttle with 429, after the request reached the server, so only requests
+    // that can safely run twice are retried
+    switch resp.StatusCode {
+    case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+        return isIdempotentRequest(req)
+    }
+
+    return false
+}
+
+func isTransientNetworkError(err error) bool {
+    if urlErr, ok := err.(*url.Error); ok {
+        err = urlErr.Err
+    }
+
+    if opErr, ok := err.(*net.OpError); ok {
+        if _, ok := opErr.Err.(*net.DNSError); ok {
+            return true
+        }
+    }
+
+    if _, ok := err.(*net.DNSError); ok {
+        return true
+    }
+
+    errStr := err.Error()
+    return strings.Contains(errStr, "connection refused") || strings.Contains(errStr, "connection reset")
+}
+
 // Do sends an API request and returns the API response.  The API response is
 // JSON decoded and stored in the value pointed to by v, or returned as an
 // error if an API error has occurred.  If v implements the io.Writer
@@ -219,50 +488,46 @@ func (c *Client) addAuthHeader(req *http.Request, authRequired bool) error {
 func (c *Client) Do(req *http.Request, v interface{}, ExitWithErrorOnTimeout bool) (*http.Response, error) {
     var err error
 
-    if IsVerbose() {
-        fmt.Println("REQUEST:")
-        fmt.Printf("[%s]\t%s\n", req.Method, req.URL)
-        if len(req.Header) > 0 {
-            fmt.Println("Req Headers")
-            printJSON(req.Header)
-        }
-        if req.Body != nil {
-            fmt.Println("Req Body")
-            fmt.Println(req.Body)
-            Debug(DbgInfo, "Req Body (ASCII quoted string):\n%+q\n", req.Body)
+    // Ask for a compressed response; it is decompressed below
+    if len(req.Header.Get("Accept-Encoding")) == 0 {
+        req.Header.Set("Accept-Encoding", "gzip")
+    }
+
+    // A request without a deadline of its own, which blocking invocations set, is limited to the request timeout.
+    // The response body is read before Do returns, so the deadline does not cut it short.
+    if timeout := getTimeout(c.Config.RequestTimeout, DefaultRequestTimeout); timeout > 0 {
+        if _, hasDeadline := req.Context().Deadline(); !hasDeadline {
+            ctx, cancel := context.WithTimeout(req.Context(), timeout)
+            defer cancel()
+            req = req.WithContext(ctx)
         }
     }
 
+    traceRequest(req)
+    if req.Body != nil {
+        Debug(DbgInfo, "Req Body (ASCII quoted string):\n%+q\n", req.Body)
+    }
+
     // Issue the request to the Whisk server endpoint
-    resp, err := c.client.Do(req)
+    resp, err := c.doWithRetries(req)
     if err != nil {
         Debug(DbgError, "HTTP Do() [req %s] error: %s\n", req.URL.String(), err)
+        if req.Context().Err() == context.DeadlineExceeded {
+            err = errors.New(wski18n.T("The {{.method}} request to {{.url}} timed out",
+                map[string]interface{}{"method": req.Method, "url": req.URL.String()}))
+        }
         werr := MakeWskError(err, EXITCODE_ERR_NETWORK, DISPLAY_MSG, NO_DISPLAY_USAGE)
         return nil, werr
     }
     // Don't "defer resp.Body.Close()" here because the body is reloaded to allow caller to
     // do custom body parsing, such as handling per-route error responses.
-    Verbose("RESPONSE:")
-    Verbose("Got response with code %d\n", resp.StatusCode)
-    if (IsVerbose() && len(resp.Header) > 0) {
-        fmt.Println("Resp Headers")
-        printJSON(resp.Header)
-    }
+    traceResponse(resp)
 
-    // Read the response body
-    data, err := ioutil.ReadAll(resp.Body)
-    if err != nil {
-        Debug(DbgError, "ioutil.ReadAll(resp.Body) error: %s\n", err)
+    if err = decompressResponse(resp); err != nil {
+        Debug(DbgError, "gzip.NewReader(resp.Body) error: %s\n", err)
         werr := MakeWskError(err, EXITCODE_ERR_NETWORK, DISPLAY_MSG, NO_DISPLAY_USAGE)
         return resp, werr
     }
-    Verbose("Response body size is %d bytes\n", len(data))
-    Verbose("Response body received:\n%s\n", string(data))
-    Debug(DbgInfo, "Response body received (ASCII quoted string):\n%+q\n", string(data))
-
-    // Reload the response body to allow caller access to the body; otherwise,
-    // the caller will have any empty body to read
-    resp.Body = ioutil.NopCloser(bytes.NewBuffer(data))
 
     // With the HTTP response status code and the HTTP body contents,
     // the possible response scenarios are:
@@ -275,11 +540,32 @@ func (c *Client) Do(req *http.Request, v interface{}, ExitWithErrorOnTimeout boo
     // 5. HTTP Failure + Body matching error format expectation
     // 6. HTTP Failure + Body NOT matching error format expectation
 
+    // Handle 0. HTTP Success + Body indicating a whisk failure result
+    // Handle 1. HTTP Success + Valid body matching request expectations
+    // Handle 3. HTTP Success + Body does NOT match request expectations
+    // The body is decoded as it is read, so that a large result is not also held in memory as raw bytes
+    if IsHttpRespSuccess(resp) && v != nil {
+        return decodeSuccessResponse(resp, v, ExitWithErrorOnTimeout)
+    }
+
+    // Read the response body
+    data, err := ioutil.ReadAll(resp.Body)
+    if err != nil {
+        Debug(DbgError, "ioutil.ReadAll(resp.Body) error: %s\n", err)
+        werr := MakeWskError(err, EXITCODE_ERR_NETWORK, DISPLAY_MSG, NO_DISPLAY_USAGE)
+        return resp, werr
+    }
+    traceResponseBody(data, int64(len(data)))
+
+    // Reload the response body to allow caller access to the body; otherwise,
+    // the caller will have any empty body to read
+    resp.Body = ioutil.NopCloser(bytes.NewBuffer(data))
+
     // Handle 4. HTTP Failure + No body
     // If this happens, just return no data and an error
     if !IsHttpRespSuccess(resp) && data == nil {
         Debug(DbgError, "HTTP failure %d + no body\n", resp.StatusCode)
-        werr := MakeWskError(errors.New(wski18n.T("Command failed due to an HTTP failure")), resp.StatusCode-256,
+        werr := MakeWskError(errors.New(wski18n.T("Command failed due to an HTTP failure")), getHttpExitCode(resp.StatusCode),
             DISPLAY_MSG, NO_DISPLAY_USAGE)
         return resp, werr
     }
@@ -290,59 +576,158 @@ func (c *Client) Do(req *http.Request, v interface{}, ExitWithErrorOnTimeout boo
         return parseErrorResponse(resp, data, v)
     }
 
-    // Handle 0. HTTP Success + Body indicating a whisk failure result
-    //   NOTE: Need to ignore activation records send in response to 'wsk get activation NNN` as
-    //         these will report the same original error giving the appearance that the command failed.
-    if (IsHttpRespSuccess(resp) &&                                      // HTTP Status == 200
-        data!=nil &&                                                    // HTTP response body exists
-        v != nil &&
-        !strings.Contains(reflect.TypeOf(v).String(), "Activation") &&  // Request is not `wsk activation get`
-        !IsResponseResultSuccess(data)) {                               // HTTP response body has Whisk error result
-        Debug(DbgInfo, "Got successful HTTP; but activation response reports an error\n")
-        return parseErrorResponse(resp, data, v)
-    }
-
     // Handle 2. HTTP Success + No body expected
     if IsHttpRespSuccess(resp) && v == nil {
         Debug(DbgInfo, "No interface provided; no HTTP response body expected\n")
         return resp, nil
     }
 
-    // Handle 1. HTTP Success + Valid body matching request expectations
-    // Handle 3. HTTP Success + Body does NOT match request expectations
-    if IsHttpRespSuccess(resp) && v != nil {
+    // We should never get here, but just in case return failure to keep the compiler happy
+    werr := MakeWskError(errors.New(wski18n.T("Command failed due to an internal failure")), EXITCODE_ERR_GENERAL,
+        DISPLAY_MSG, NO_DISPLAY_USAGE)
+    return resp, werr
+}
+
+// decodeSuccessResponse decodes the body of a successful response into v while reading it. Only the beginning of the
+// body is kept for the verbose trace.
+func decodeSuccessResponse(resp *http.Response, v interface{}, ExitWithErrorOnTimeout bool) (*http.Response, error) {
+    var err error
+
+    traced := &traceBuffer{limit: maxTracedBodySize}
+    body := io.TeeReader(resp.Body, traced)
 
-        // If a timeout occurs, 202 HTTP status code is returned, and the caller wishes to handle such an event, return
-        // an error corresponding with the timeout
-        if ExitWithErrorOnTimeout && resp.StatusCode == EXITCODE_TIMED_OUT {
-            errMsg :=  wski18n.T("Request accepted, but processing not completed yet.")
-            err = MakeWskError(errors.New(errMsg), EXITCODE_TIMED_OUT, NO_DISPLAY_MSG, NO_DISPLAY_USAGE,
-                NO_MSG_DISPLAYED, NO_DISPLAY_PREFIX, NO_APPLICATION_ERR, TIMED_OUT)
+    Debug(DbgInfo, "Parsing HTTP response into struct type: %s\n", reflect.TypeOf(v))
+    dc := json.NewDecoder(body)
+    dc.UseNumber()
+    decodeErr := dc.Decode(v)
+
+    // Read what the decoder left, so that the body size is known and the connection can be reused
+    if _, err = io.Copy(ioutil.Discard, body); err != nil {
+        Debug(DbgError, "Reading the rest of the response body failed: %s\n", err)
+        werr := MakeWskError(err, EXITCODE_ERR_NETWORK, DISPLAY_MSG, NO_DISPLAY_USAGE)
+        return resp, werr
+    }
+    resp.Body.Close()
+    resp.Body = ioutil.NopCloser(bytes.NewReader(nil))
+    traceResponseBody(traced.Bytes(), traced.size)
+
+    // If the decode did not work, the server response was unexpected (#3), but the request was successful
+    if decodeErr != nil {
+        Debug(DbgWarn, "Unsuccessful parse of HTTP response into struct type: %s; parse error '%v'\n", reflect.TypeOf(v), decodeErr)
+        Debug(DbgWarn, "Request was successful, so ignoring the following unexpected response body that could not be parsed: %s\n", traced.Bytes())
+    } else {
+        Debug(DbgInfo, "Successful parse of HTTP response into struct type: %s\n", reflect.TypeOf(v))
+
+        // Handle 0. HTTP Success + Body indicating a whisk failure result
+        //   NOTE: Need to ignore activation records send in response to 'wsk get activation NNN` as
+        //         these will report the same original error giving the appearance that the command failed.
+        if !strings.Contains(reflect.TypeOf(v).String(), "Activation") && !isDecodedResultSuccess(v) {
+            Debug(DbgInfo, "Got successful HTTP; but activation response reports an error\n")
+            data, _ := json.Marshal(v)
+            return parseApplicationError(resp, data, v, EXITCODE_ERR_GENERAL)
         }
+    }
 
-        return parseSuccessResponse(resp, data, v), err
+    // If a timeout occurs, 202 HTTP status code is returned, and the caller wishes to handle such an event, return
+    // an error corresponding with the timeout
+    if ExitWithErrorOnTimeout && resp.StatusCode == EXITCODE_TIMED_OUT {
+        errMsg :=  wski18n.T("Request accepted, but processing not completed yet.")
+        err = MakeWskError(errors.New(errMsg), EXITCODE_TIMED_OUT, NO_DISPLAY_MSG, NO_DISPLAY_USAGE,
+            NO_MSG_DISPLAYED, NO_DISPLAY_PREFIX, NO_APPLICATION_ERR, TIMED_OUT)
+        return resp, err
     }
 
-    // We should never get here, but just in case return failure to keep the compiler happy
-    werr := MakeWskError(errors.New(wski18n.T("Command failed due to an internal failure")), EXITCODE_ERR_GENERAL,
-        DISPLAY_MSG, NO_DISPLAY_USAGE)
-    return resp, werr
+    return resp, nil
+}
+
+// isDecodedResultSuccess is IsResponseResultSuccess for a decoded response body. Only a JSON object can hold the
+// "response" of an activation, so any other decoded value is a success.
+func isDecodedResultSuccess(v interface{}) bool {
+    value := reflect.ValueOf(v)
+    for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+        if value.IsNil() {
+            return true
+        }
+        value = value.Elem()
+    }
+
+    if !value.CanInterface() {
+        return true
+    }
+
+    body, ok := value.Interface().(map[string]interface{})
+    if !ok {
+        return true
+    }
+
+    response, ok := body["response"].(map[string]interface{})
+    if !ok {
+        return true
+    }
+
+    success, _ := response["success"].(bool)
+    return success
+}
+
+// decompressResponse replaces the body of a gzip encoded response with its decompressed contents
+func decompressResponse(resp *http.Response) error {
+    if !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+        return nil
+    }
+
+    reader, err := gzip.NewReader(resp.Body)
+    if err == io.EOF {
+        // An empty body has nothing to decompress
+        return nil
+    }
+    if err != nil {
+        return err
+    }
+
+    resp.Body = &gzipBody{Reader: reader, body: resp.Body}
+    resp.Header.Del("Content-Encoding")
+    resp.Header.Del("Content-Length")
+    resp.ContentLength = -1
+    resp.Uncompressed = true
+
+    return nil
+}
+
+// gzipBody reads a decompressed response body and closes the underlying body
+type gzipBody struct {
+    *gzip.Reader
+    body io.ReadCloser
+}
+
+func (b *gzipBody) Close() error {
+    b.Reader.Close()
+    return b.body.Close()
+}
+
+// getHttpExitCode returns the exit code for an HTTP error status. A missing entity has its own exit code, so that
+// scripts can tell it apart from other failures.
+func getHttpExitCode(statusCode int) int {
+    if statusCode == http.StatusNotFound {
+        return EXITCODE_ERR_NOT_FOUND
+    }
+
+    return statusCode - 256
 }
 
 func parseErrorResponse(resp *http.Response, data []byte, v interface{}) (*http.Response, error) {
     Debug(DbgInfo, "HTTP failure %d + body\n", resp.StatusCode)
 
     // Determine if an application error was received (#5)
-    errorResponse := &ErrorResponse{Response: resp}
+    errorResponse := &ErrorResponse{Response: resp, StatusCode: resp.StatusCode}
     err := json.Unmarshal(data, errorResponse)
 
     // Determine if error is an application error or an error generated by API
     if err == nil {
         if errorResponse.Code == nil /*&& errorResponse.ErrMsg != nil */&& resp.StatusCode == 502 {
-            return parseApplicationError(resp, data, v)
+            return parseApplicationError(resp, data, v, getHttpExitCode(resp.StatusCode))
         } else if errorResponse.Code != nil && errorResponse.ErrMsg != nil {
             Debug(DbgInfo, "HTTP failure %d; server error %s\n", resp.StatusCode, errorResponse)
-            werr := MakeWskError(errorResponse, resp.StatusCode - 256, DISPLAY_MSG, NO_DISPLAY_USAGE)
+            werr := MakeWskError(errorResponse, getHttpExitCode(resp.StatusCode), DISPLAY_MSG, NO_DISPLAY_USAGE)
             return resp, werr
         }
     }
@@ -351,11 +736,14 @@ func parseErrorResponse(resp *http.Response, data []byte, v interface{}) (*http.
     Debug(DbgError, "HTTP response with unexpected body failed due to contents parsing error: '%v'\n", err)
     errMsg := wski18n.T("The connection failed, or timed out. (HTTP status code {{.code}})",
         map[string]interface{}{"code": resp.StatusCode})
-    whiskErr := MakeWskError(errors.New(errMsg), resp.StatusCode - 256, DISPLAY_MSG, NO_DISPLAY_USAGE)
+    whiskErr := MakeWskError(errors.New(errMsg), getHttpExitCode(resp.StatusCode), DISPLAY_MSG, NO_DISPLAY_USAGE)
     return resp, whiskErr
 }
 
-func parseApplicationError(resp *http.Response, data []byte, v interface{}) (*http.Response, error) {
+// parseApplicationError returns the error for an activation that failed, which exits with exitCode. The server
+// reports most failed blocking invocations with a 502 status, but an activation response with a 200 status may also
+// report a failure.
+func parseApplicationError(resp *http.Response, data []byte, v interface{}, exitCode int) (*http.Response, error) {
     Debug(DbgInfo, "Parsing application error\n")
 
     whiskErrorResponse := &WhiskErrorResponse{}
@@ -365,9 +753,8 @@ func parseApplicationError(resp *http.Response, data []byte, v interface{}) (*ht
     if err == nil && whiskErrorResponse != nil && whiskErrorResponse.Response != nil && whiskErrorResponse.Response.Status != nil {
         Debug(DbgInfo, "Detected response status `%s` that a whisk.error(\"%#v\") was returned\n",
             *whiskErrorResponse.Response.Status, *whiskErrorResponse.Response.Result)
-        errMsg := wski18n.T("The following application error was received: {{.err}}",
-            map[string]interface{}{"err": *whiskErrorResponse.Response.Result})
-        whiskErr := MakeWskError(errors.New(errMsg), resp.StatusCode - 256, NO_DISPLAY_MSG, NO_DISPLAY_USAGE,
+        errMsg := GetApplicationErrorMessage(*whiskErrorResponse.Response.Status, *whiskErrorResponse.Response.Result)
+        whiskErr := MakeWskError(errors.New(errMsg), exitCode, NO_DISPLAY_MSG, NO_DISPLAY_USAGE,
             NO_MSG_DISPLAYED, DISPLAY_PREFIX, APPLICATION_ERR)
         return parseSuccessResponse(resp, data, v), whiskErr
     }
@@ -381,7 +768,7 @@ func parseApplicationError(resp *http.Response, data []byte, v interface{}) (*ht
         errMsg := fmt.Sprintf("%v", *appErrResult.Error)
         Debug(DbgInfo, "Application error received: %s\n", errMsg)
 
-        whiskErr := MakeWskError(errors.New(errMsg), resp.StatusCode - 256, NO_DISPLAY_MSG, NO_DISPLAY_USAGE,
+        whiskErr := MakeWskError(errors.New(errMsg), exitCode, NO_DISPLAY_MSG, NO_DISPLAY_USAGE,
             NO_MSG_DISPLAYED, DISPLAY_PREFIX, APPLICATION_ERR)
         return parseSuccessResponse(resp, data, v), whiskErr
     }
@@ -390,10 +777,23 @@ func parseApplicationError(resp *http.Response, data []byte, v interface{}) (*ht
     Debug(DbgError, "HTTP response with unexpected body failed due to contents parsing error: '%v'\n", err)
     errMsg := wski18n.T("The connection failed, or timed out. (HTTP status code {{.code}})",
         map[string]interface{}{"code": resp.StatusCode})
-    whiskErr := MakeWskError(errors.New(errMsg), resp.StatusCode - 256, DISPLAY_MSG, NO_DISPLAY_USAGE)
+    whiskErr := MakeWskError(errors.New(errMsg), getHttpExitCode(resp.StatusCode), DISPLAY_MSG, NO_DISPLAY_USAGE)
     return resp, whiskErr
 }
 
+// GetApplicationErrorMessage describes a failed activation by its status, telling an error returned by the action
+// apart from a failure of the action code and from a failure of the system
+func GetApplicationErrorMessage(status interface{}, result interface{}) string {
+    switch status {
+    case "action developer error":
+        return wski18n.T("The action failed with a developer error: {{.err}}", map[string]interface{}{"err": result})
+    case "whisk internal error":
+        return wski18n.T("The action failed with a whisk internal error: {{.err}}", map[string]interface{}{"err": result})
+    }
+
+    return wski18n.T("The following application error was received: {{.err}}", map[string]interface{}{"err": result})
+}
+
 func parseSuccessResponse(resp *http.Response, data []byte, v interface{}) (*http.Response) {
     Debug(DbgInfo, "Parsing HTTP response into struct type: %s\n", reflect.TypeOf(v))
 
@@ -424,9 +824,11 @@ func parseSuccessResponse(resp *http.Response, data []byte, v interface{}) (*htt
 //     "code": 1422870
 // }
 type ErrorResponse struct {
-    Response *http.Response                 // HTTP response that caused this error
-    ErrMsg   *interface{}   `json:"error"`  // error message string
-    Code     *int64         `json:"code"`   // validation error code
+    Response     *http.Response                             // HTTP response that caused this error
+    StatusCode   int            `json:"-"`                  // HTTP status code of the response
+    ErrMsg       *interface{}   `json:"error"`              // error message string
+    Code         *int64         `json:"code"`               // validation error code
+    ActivationID *string        `json:"activationId"`       // activation that failed, when the error concerns one
 }
 
 type AppErrorResult struct {
@@ -453,7 +855,16 @@ type WhiskError struct {
 
 func (r ErrorResponse) Error() string {
     return wski18n.T("{{.msg}} (code {{.code}})",
-        map[string]interface{}{"msg": fmt.Sprintf("%v", *r.ErrMsg), "code": r.Code})
+        map[string]interface{}{"msg": r.ErrorMessage(), "code": r.Code})
+}
+
+// ErrorMessage returns the "error" field of the server response
+func (r ErrorResponse) ErrorMessage() string {
+    if r.ErrMsg == nil {
+        return ""
+    }
+
+    return fmt.Sprintf("%v", *r.ErrMsg)
 }
 
 ////////////////////////////
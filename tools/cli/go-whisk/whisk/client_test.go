@@ -0,0 +1,879 @@
+/*
+ * Copyright 2015-2016 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package whisk
+
+import (
+    "bytes"
+    "compress/gzip"
+    "crypto/ecdsa"
+    "crypto/elliptic"
+    "crypto/rand"
+    "crypto/tls"
+    "crypto/x509"
+    "crypto/x509/pkix"
+    "encoding/json"
+    "encoding/pem"
+    "errors"
+    "fmt"
+    "io/ioutil"
+    "log"
+    "math/big"
+    "net"
+    "net/http"
+    "net/http/httptest"
+    "net/url"
+    "os"
+    "path/filepath"
+    "strings"
+    "sync"
+    "sync/atomic"
+    "testing"
+    "time"
+)
+
+// newTestClient returns a client of an API host that answers requests with handler, using the namespace ns. The
+// server must be closed by the caller.
+func newTestClient(t *testing.T, handler http.HandlerFunc) (*Client, *httptest.Server) {
+    server := httptest.NewServer(handler)
+
+    baseURL, _ := url.Parse(server.URL + "/api")
+    client, err := NewClient(server.Client(), &Config{
+        Namespace:  "ns",
+        AuthToken:  "user:key",
+        BaseURL:    baseURL,
+        Version:    "v1",
+    })
+    if err != nil {
+        server.Close()
+        t.Fatalf("NewClient() failed: %s", err)
+    }
+
+    return client, server
+}
+
+// writeTestJSON answers a test request with the given status and v encoded as JSON
+func writeTestJSON(w http.ResponseWriter, status int, v interface{}) {
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(status)
+    json.NewEncoder(w).Encode(v)
+}
+
+// getTestActivationBody returns an activation response whose result holds a string of size bytes
+func getTestActivationBody(size int) []byte {
+    data, _ := json.Marshal(map[string]interface{}{
+        "activationId": "12345",
+        "response": map[string]interface{}{
+            "status": "success",
+            "success": true,
+            "result": map[string]interface{}{"payload": strings.Repeat("x", size)},
+        },
+    })
+
+    return data
+}
+
+func newTestResponse(data []byte) *http.Response {
+    return &http.Response{
+        StatusCode: http.StatusOK,
+        Body: ioutil.NopCloser(bytes.NewReader(data)),
+    }
+}
+
+// bufferedDecodeResponse is how Client.Do decoded a successful response before decodeSuccessResponse: the body was
+// read in full, traced, reloaded, checked for a failed activation and then decoded from a copy
+func bufferedDecodeResponse(resp *http.Response, v interface{}) (*http.Response, error) {
+    data, err := ioutil.ReadAll(resp.Body)
+    if err != nil {
+        return resp, err
+    }
+    Verbose("Response body size is %d bytes\n", len(data))
+    Verbose("Response body received:\n%s\n", string(data))
+    Debug(DbgInfo, "Response body received (ASCII quoted string):\n%+q\n", string(data))
+    resp.Body = ioutil.NopCloser(bytes.NewBuffer(data))
+
+    if !IsResponseResultSuccess(data) {
+        return parseErrorResponse(resp, data, v)
+    }
+
+    return parseSuccessResponse(resp, data, v), nil
+}
+
+func TestDecodeSuccessResponse(t *testing.T) {
+    data := getTestActivationBody(1024)
+
+    var streamed, buffered map[string]interface{}
+    if _, err := decodeSuccessResponse(newTestResponse(data), &streamed, false); err != nil {
+        t.Fatalf("decodeSuccessResponse() failed: %s", err)
+    }
+    if _, err := bufferedDecodeResponse(newTestResponse(data), &buffered); err != nil {
+        t.Fatalf("bufferedDecodeResponse() failed: %s", err)
+    }
+
+    streamedJSON, _ := json.Marshal(streamed)
+    bufferedJSON, _ := json.Marshal(buffered)
+    if !bytes.Equal(streamedJSON, bufferedJSON) {
+        t.Errorf("decodeSuccessResponse() decoded %s, want %s", streamedJSON, bufferedJSON)
+    }
+}
+
+func TestDecodeSuccessResponseFailedActivation(t *testing.T) {
+    data := []byte(`{"activationId":"12345","response":{"status":"action developer error","success":false,"result":{"error":"boom"}}}`)
+
+    var v map[string]interface{}
+    _, err := decodeSuccessResponse(newTestResponse(data), &v, false)
+    if whiskErr, ok := err.(*WskError); !ok || !whiskErr.ApplicationError {
+        t.Errorf("decodeSuccessResponse() error = %v, want an application error", err)
+    }
+}
+
+func benchmarkDecode(b *testing.B, size int, decode func(*http.Response, interface{}) (*http.Response, error)) {
+    data := getTestActivationBody(size)
+    b.SetBytes(int64(len(data)))
+    b.ReportAllocs()
+    b.ResetTimer()
+
+    for i := 0; i < b.N; i++ {
+        var v map[string]interface{}
+        if _, err := decode(newTestResponse(data), &v); err != nil {
+            b.Fatalf("decode failed: %s", err)
+        }
+    }
+}
+
+func streamedDecodeResponse(resp *http.Response, v interface{}) (*http.Response, error) {
+    return decodeSuccessResponse(resp, v, false)
+}
+
+func BenchmarkDecodeResponseStreamed1KB(b *testing.B) { benchmarkDecode(b, 1 << 10, streamedDecodeResponse) }
+func BenchmarkDecodeResponseBuffered1KB(b *testing.B) { benchmarkDecode(b, 1 << 10, bufferedDecodeResponse) }
+func BenchmarkDecodeResponseStreamed5MB(b *testing.B) { benchmarkDecode(b, 5 << 20, streamedDecodeResponse) }
+func BenchmarkDecodeResponseBuffered5MB(b *testing.B) { benchmarkDecode(b, 5 << 20, bufferedDecodeResponse) }
+
+// resetConnection closes the connection of a test request with a TCP reset, as a failing network or server would
+func resetConnection(t *testing.T, w http.ResponseWriter) {
+    conn, _, err := w.(http.Hijacker).Hijack()
+    if err != nil {
+        t.Fatalf("Hijack() failed: %s", err)
+    }
+    if tcpConn, ok := conn.(*net.TCPConn); ok {
+        tcpConn.SetLinger(0)
+    }
+    conn.Close()
+}
+
+// newRetryTestClient returns a client whose requests to the test server fail failures times, by reset connections,
+// before they succeed, and a count of the requests received
+func newRetryTestClient(t *testing.T, failures int, maxRetries int) (*Client, *httptest.Server, *int32) {
+    var count int32
+    client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+        if int(atomic.AddInt32(&count, 1)) <= failures {
+            resetConnection(t, w)
+            return
+        }
+        writeTestJSON(w, http.StatusOK, map[string]interface{}{"namespace": "ns", "name": "a"})
+    })
+    client.Config.MaxRetries = maxRetries
+    client.Config.InitialDelay = time.Millisecond
+
+    return client, server, &count
+}
+
+func TestRetryTransientNetworkError(t *testing.T) {
+    client, server, count := newRetryTestClient(t, 2, 3)
+    defer server.Close()
+
+    action, _, err := client.Actions.Get("a")
+    if err != nil {
+        t.Fatalf("Actions.Get() failed after retries: %s", err)
+    }
+    if action.Name != "a" {
+        t.Errorf("Actions.Get() returned %#v", action)
+    }
+    if requests := atomic.LoadInt32(count); requests != 3 {
+        t.Errorf("server received %d requests, want 3", requests)
+    }
+}
+
+func TestRetryGivesUp(t *testing.T) {
+    client, server, count := newRetryTestClient(t, 5, 2)
+    defer server.Close()
+
+    if _, _, err := client.Actions.Get("a"); err == nil {
+        t.Errorf("Actions.Get() succeeded after the retries ran out")
+    }
+    if requests := atomic.LoadInt32(count); requests != 3 {
+        t.Errorf("server received %d requests, want 3", requests)
+    }
+}
+
+func TestRetryDisabled(t *testing.T) {
+    client, server, count := newRetryTestClient(t, 1, 0)
+    defer server.Close()
+
+    if _, _, err := client.Actions.Get("a"); err == nil {
+        t.Errorf("Actions.Get() succeeded without retries")
+    }
+    if requests := atomic.LoadInt32(count); requests != 1 {
+        t.Errorf("server received %d requests, want 1", requests)
+    }
+}
+
+func TestNoRetryOfInvocation(t *testing.T) {
+    client, server, count := newRetryTestClient(t, 1, 3)
+    defer server.Close()
+
+    if _, _, err := client.Actions.InvokeWithOptions("a", nil, &InvokeOptions{Blocking: true}); err == nil {
+        t.Errorf("InvokeWithOptions() succeeded, but invocations are not retried")
+    }
+    if requests := atomic.LoadInt32(count); requests != 1 {
+        t.Errorf("server received %d requests, want 1", requests)
+    }
+}
+
+func TestIsIdempotentRequest(t *testing.T) {
+    tests := []struct {
+        method  string
+        url     string
+        want    bool
+    }{
+        {"GET", "/api/v1/namespaces/ns/actions/a", true},
+        {"HEAD", "/api/v1/namespaces/ns/actions/a", true},
+        {"DELETE", "/api/v1/namespaces/ns/actions/a", true},
+        {"PUT", "/api/v1/namespaces/ns/actions/a?overwrite=true", true},
+        {"PUT", "/api/v1/namespaces/ns/actions/a?overwrite=false", false},
+        {"PUT", "/api/v1/namespaces/ns/actions/a", false},
+        {"POST", "/api/v1/namespaces/ns/actions/a?blocking=true", false},
+        {"POST", "/api/v1/namespaces/ns/rules/r", false},
+    }
+
+    for _, test := range tests {
+        req, _ := http.NewRequest(test.method, test.url, nil)
+        if idempotent := isIdempotentRequest(req); idempotent != test.want {
+            t.Errorf("isIdempotentRequest(%s %s) = %t, want %t", test.method, test.url, idempotent, test.want)
+        }
+    }
+}
+
+// newThrottledTestClient returns a client whose requests to the test server are answered with status and the
+// Retry-After header retryAfter failures times before they succeed, and the times the requests were received
+func newThrottledTestClient(t *testing.T, status int, retryAfter string, failures int) (*Client, *httptest.Server, *[]time.Time) {
+    var mutex sync.Mutex
+    var times []time.Time
+
+    client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+        mutex.Lock()
+        times = append(times, time.Now())
+        count := len(times)
+        mutex.Unlock()
+
+        if count <= failures {
+            if len(retryAfter) > 0 {
+                w.Header().Set("Retry-After", retryAfter)
+            }
+            writeTestJSON(w, status, map[string]interface{}{"error": http.StatusText(status), "code": 1})
+            return
+        }
+        writeTestJSON(w, http.StatusOK, map[string]interface{}{"namespace": "ns", "name": "a"})
+    })
+    client.Config.MaxRetries = 3
+    client.Config.InitialDelay = 50 * time.Millisecond
+    client.Config.MaxDelay = 200 * time.Millisecond
+
+    return client, server, &times
+}
+
+func TestRetryThrottledRequests(t *testing.T) {
+    tests := []struct {
+        status      int
+        retryAfter  string
+        minDelay    time.Duration
+        maxDelay    time.Duration
+    }{
+        // Without Retry-After the delay starts at InitialDelay and doubles
+        {http.StatusTooManyRequests, "", 50 * time.Millisecond, 200 * time.Millisecond},
+        {http.StatusServiceUnavailable, "", 50 * time.Millisecond, 200 * time.Millisecond},
+        {http.StatusBadGateway, "", 50 * time.Millisecond, 200 * time.Millisecond},
+        {http.StatusGatewayTimeout, "", 50 * time.Millisecond, 200 * time.Millisecond},
+        // Retry-After replaces the delay, up to MaxDelay
+        {http.StatusTooManyRequests, "0", 0, 40 * time.Millisecond},
+        {http.StatusServiceUnavailable, "3600", 200 * time.Millisecond, 400 * time.Millisecond},
+        {http.StatusTooManyRequests, time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0, 40 * time.Millisecond},
+        {http.StatusTooManyRequests, "soon", 50 * time.Millisecond, 200 * time.Millisecond},
+    }
+
+    for _, test := range tests {
+        client, server, times := newThrottledTestClient(t, test.status, test.retryAfter, 1)
+        _, _, err := client.Actions.Get("a")
+        server.Close()
+
+        if err != nil {
+            t.Errorf("Actions.Get() after a %d response with Retry-After %q failed: %s", test.status, test.retryAfter, err)
+            continue
+        }
+        if len(*times) != 2 {
+            t.Errorf("server received %d requests after a %d response, want 2", len(*times), test.status)
+            continue
+        }
+        if delay := (*times)[1].Sub((*times)[0]); delay < test.minDelay || delay > test.maxDelay {
+            t.Errorf("retry after a %d response with Retry-After %q came after %s, want %s to %s", test.status,
+                test.retryAfter, delay, test.minDelay, test.maxDelay)
+        }
+    }
+}
+
+func TestRetryThrottledRequestsGivesUp(t *testing.T) {
+    client, server, times := newThrottledTestClient(t, http.StatusTooManyRequests, "0", 10)
+    defer server.Close()
+
+    _, _, err := client.Actions.Get("a")
+    if err == nil {
+        t.Fatalf("Actions.Get() succeeded after the retries ran out")
+    }
+    if whiskErr, ok := err.(*WskError); !ok || whiskErr.ExitCode != http.StatusTooManyRequests - 256 {
+        t.Errorf("Actions.Get() error = %#v, want the 429 response", err)
+    }
+    if len(*times) != 4 {
+        t.Errorf("server received %d requests, want 4", len(*times))
+    }
+}
+
+func TestNoRetryOfThrottledInvocation(t *testing.T) {
+    client, server, times := newThrottledTestClient(t, http.StatusTooManyRequests, "0", 1)
+    defer server.Close()
+
+    if _, _, err := client.Actions.InvokeWithOptions("a", nil, &InvokeOptions{}); err == nil {
+        t.Errorf("InvokeWithOptions() succeeded, but invocations are not retried")
+    }
+    if len(*times) != 1 {
+        t.Errorf("server received %d requests, want 1", len(*times))
+    }
+}
+
+func TestGetHttpErrorResponse(t *testing.T) {
+    tests := []struct {
+        status          int
+        body            string
+        message         string      // the "error" field, or empty when there is no error response
+        activationID    string
+        exitCode        int
+    }{
+        {http.StatusNotFound, `{"error":"The requested resource does not exist.","code":2317}`,
+            "The requested resource does not exist.", "", EXITCODE_ERR_NOT_FOUND},
+        {http.StatusConflict, `{"error":"rule 'r' is active; it must be disabled first","code":5}`,
+            "rule 'r' is active; it must be disabled first", "", http.StatusConflict - 256},
+        {http.StatusBadGateway, `{"error":"The action did not produce a valid response.","code":3,"activationId":"12345"}`,
+            "The action did not produce a valid response.", "12345", http.StatusBadGateway - 256},
+        {http.StatusTooManyRequests, `{"error":{"reason":"too many"},"code":4}`,
+            "map[reason:too many]", "", http.StatusTooManyRequests - 256},
+        // Bodies that are not controller errors leave no error response to examine
+        {http.StatusInternalServerError, `<html>Internal Server Error</html>`, "", "", http.StatusInternalServerError - 256},
+        {http.StatusNotFound, `{"error":"no code"}`, "", "", EXITCODE_ERR_NOT_FOUND},
+    }
+
+    for _, test := range tests {
+        resp := &http.Response{StatusCode: test.status, Body: ioutil.NopCloser(strings.NewReader(test.body))}
+        _, err := parseErrorResponse(resp, []byte(test.body), nil)
+
+        whiskErr, ok := err.(*WskError)
+        if !ok {
+            t.Errorf("parseErrorResponse(%d, %s) error = %#v, want a WskError", test.status, test.body, err)
+            continue
+        }
+        if whiskErr.ExitCode != test.exitCode {
+            t.Errorf("parseErrorResponse(%d, %s) exit code = %d, want %d", test.status, test.body, whiskErr.ExitCode,
+                test.exitCode)
+        }
+
+        // The CLI wraps the error in its own message, which keeps the exit code
+        wrapped := MakeWskErrorFromWskError(errors.New("wrapped"), err, EXITCODE_ERR_GENERAL, DISPLAY_MSG, NO_DISPLAY_USAGE)
+        if wrapped.ExitCode != test.exitCode {
+            t.Errorf("wrapped parseErrorResponse(%d, %s) exit code = %d, want %d", test.status, test.body,
+                wrapped.ExitCode, test.exitCode)
+        }
+
+        for _, e := range []error{err, *whiskErr} {
+            errorResponse := GetHttpErrorResponse(e)
+            if len(test.message) == 0 {
+                if errorResponse != nil {
+                    t.Errorf("GetHttpErrorResponse(%d, %s) = %#v, want nil", test.status, test.body, errorResponse)
+                }
+                continue
+            }
+
+            if errorResponse == nil {
+                t.Errorf("GetHttpErrorResponse(%d, %s) = nil, want an error response", test.status, test.body)
+                continue
+            }
+            if errorResponse.StatusCode != test.status || errorResponse.ErrorMessage() != test.message {
+                t.Errorf("GetHttpErrorResponse(%d, %s) = %d %q, want %d %q", test.status, test.body,
+                    errorResponse.StatusCode, errorResponse.ErrorMessage(), test.status, test.message)
+            }
+
+            var activationID string
+            if errorResponse.ActivationID != nil {
+                activationID = *errorResponse.ActivationID
+            }
+            if activationID != test.activationID {
+                t.Errorf("GetHttpErrorResponse(%d, %s) activation ID = %q, want %q", test.status, test.body,
+                    activationID, test.activationID)
+            }
+        }
+    }
+}
+
+// newGzipTestClient returns a client of a server that answers with status and body, gzip compressed when compress is
+// set and the client accepts it
+func newGzipTestClient(t *testing.T, compress bool, status int, body string) (*Client, *httptest.Server) {
+    return newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+        w.Header().Set("Content-Type", "application/json")
+        if !compress || !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+            w.WriteHeader(status)
+            w.Write([]byte(body))
+            return
+        }
+
+        w.Header().Set("Content-Encoding", "gzip")
+        w.WriteHeader(status)
+        writer := gzip.NewWriter(w)
+        writer.Write([]byte(body))
+        writer.Close()
+    })
+}
+
+func TestDoGzipResponses(t *testing.T) {
+    body := `{"namespace":"ns","name":"a","version":"0.0.1","parameters":[{"key":"p","value":"` +
+        strings.Repeat("x", 10000) + `"}]}`
+
+    for _, compress := range []bool{true, false} {
+        client, server := newGzipTestClient(t, compress, http.StatusOK, body)
+
+        action, resp, err := client.Actions.Get("a")
+        if err != nil {
+            t.Errorf("Get() from a server with gzip %t failed: %s", compress, err)
+        } else if action.Name != "a" || len(action.Parameters) != 1 || action.Parameters[0].Value != strings.Repeat("x", 10000) {
+            t.Errorf("Get() from a server with gzip %t = %#v", compress, action)
+        } else if resp.Header.Get("Content-Encoding") != "" {
+            t.Errorf("Get() from a server with gzip %t left Content-Encoding %s", compress,
+                resp.Header.Get("Content-Encoding"))
+        }
+
+        // The body is decompressed for callers that read it themselves
+        if data, _, err := client.Actions.GetRaw("a"); err != nil || string(data) != body {
+            t.Errorf("GetRaw() from a server with gzip %t = %d bytes, %v; want the %d bytes sent", compress,
+                len(data), err, len(body))
+        }
+
+        server.Close()
+    }
+}
+
+func TestDoGzipErrorResponses(t *testing.T) {
+    for _, compress := range []bool{true, false} {
+        client, server := newGzipTestClient(t, compress, http.StatusNotFound,
+            `{"error":"The requested resource does not exist.","code":1}`)
+
+        _, _, err := client.Actions.Get("a")
+        whiskErr, ok := err.(*WskError)
+        if !ok || whiskErr.ExitCode != EXITCODE_ERR_NOT_FOUND ||
+                !strings.Contains(err.Error(), "The requested resource does not exist.") {
+            t.Errorf("Get() of a missing action from a server with gzip %t error = %#v", compress, err)
+        }
+
+        server.Close()
+    }
+}
+
+func TestDoGzipAcceptEncoding(t *testing.T) {
+    var acceptEncoding string
+    client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+        acceptEncoding = r.Header.Get("Accept-Encoding")
+        writeTestJSON(w, http.StatusOK, map[string]interface{}{"name": "a"})
+    })
+    defer server.Close()
+
+    if _, _, err := client.Actions.Get("a"); err != nil || acceptEncoding != "gzip" {
+        t.Errorf("Get() sent Accept-Encoding %q and failed with %v, want gzip", acceptEncoding, err)
+    }
+}
+
+func TestDoInvalidGzipResponse(t *testing.T) {
+    client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+        w.Header().Set("Content-Encoding", "gzip")
+        w.Write([]byte(`{"name":"a"}`))
+    })
+    defer server.Close()
+
+    if _, _, err := client.Actions.Get("a"); err == nil {
+        t.Errorf("Get() of a response with an invalid gzip body succeeded, want an error")
+    }
+}
+
+func TestDoVerboseBodyTruncated(t *testing.T) {
+    tests := []struct {
+        size        int
+        truncated   bool
+    }{
+        {1024, false},
+        {maxTracedBodySize + 1000, true},
+    }
+
+    for _, test := range tests {
+        body := getTestActivationBody(test.size)
+        client, server := newGzipTestClient(t, true, http.StatusOK, string(body))
+
+        var err error
+        var v map[string]interface{}
+        output := withTestLogger(&Logger{verbose: true, level: LogVerbose}, func() {
+            var req *http.Request
+            if req, err = client.NewRequest("GET", "activations/12345", nil, IncludeNamespaceInUrl); err == nil {
+                _, err = client.Do(req, &v, false)
+            }
+        })
+        server.Close()
+
+        if err != nil {
+            t.Errorf("Do() of a %d byte body failed: %s", len(body), err)
+            continue
+        }
+        if !strings.Contains(output, fmt.Sprintf("Response body size is %d bytes", len(body))) {
+            t.Errorf("the verbose trace of a %d byte body does not report its size", len(body))
+        }
+        note := fmt.Sprintf("... (truncated; %d more bytes not shown)", len(body) - maxTracedBodySize)
+        if strings.Contains(output, note) != test.truncated || len(output) > maxTracedBodySize + 4096 {
+            t.Errorf("the verbose trace of a %d byte body is %d bytes, truncated %t; want truncated %t", len(body),
+                len(output), strings.Contains(output, "(truncated;"), test.truncated)
+        }
+    }
+}
+
+// newSlowTestClient returns a client of a server that answers every request with an empty action after delay, whose
+// requests are limited to requestTimeout and are not retried
+func newSlowTestClient(t *testing.T, delay time.Duration, requestTimeout time.Duration) (*Client, *httptest.Server) {
+    client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+        select {
+        case <-time.After(delay):
+            writeTestJSON(w, http.StatusOK, map[string]interface{}{"name": "a", "activationId": "12345"})
+        case <-r.Context().Done():
+        }
+    })
+    client.Config.MaxRetries = 0
+    client.Config.RequestTimeout = requestTimeout
+
+    return client, server
+}
+
+func TestRequestTimeout(t *testing.T) {
+    client, server := newSlowTestClient(t, 2 * time.Second, 50 * time.Millisecond)
+    defer server.Close()
+
+    start := time.Now()
+    _, _, err := client.Actions.Get("a")
+    elapsed := time.Since(start)
+
+    if whiskErr, ok := err.(*WskError); !ok || whiskErr.ExitCode != EXITCODE_ERR_NETWORK ||
+            !strings.Contains(whiskErr.Error(), "The GET request to ") || !strings.Contains(whiskErr.Error(), "timed out") {
+        t.Errorf("Actions.Get() of a slow server error = %#v, want a timed out WskError with exit code %d", err,
+            EXITCODE_ERR_NETWORK)
+    }
+    if elapsed > time.Second {
+        t.Errorf("Actions.Get() took %s, want it to give up after the request timeout", elapsed)
+    }
+}
+
+func TestRequestTimeoutNotReached(t *testing.T) {
+    // A negative request timeout means there is no time limit
+    for _, requestTimeout := range []time.Duration{time.Second, -1} {
+        client, server := newSlowTestClient(t, 100 * time.Millisecond, requestTimeout)
+        _, _, err := client.Actions.Get("a")
+        server.Close()
+
+        if err != nil {
+            t.Errorf("Actions.Get() with the request timeout %s failed: %s", requestTimeout, err)
+        }
+    }
+}
+
+func TestGetTimeout(t *testing.T) {
+    tests := []struct {
+        timeout time.Duration
+        want    time.Duration
+    }{
+        {0, 10 * time.Second},
+        {time.Second, time.Second},
+        {-1, 0},
+        {-time.Minute, 0},
+    }
+
+    for _, test := range tests {
+        if timeout := getTimeout(test.timeout, 10 * time.Second); timeout != test.want {
+            t.Errorf("getTimeout(%s, 10s) = %s, want %s", test.timeout, timeout, test.want)
+        }
+    }
+}
+
+func TestTransportDialTimeout(t *testing.T) {
+    tests := []struct {
+        dialTimeout time.Duration
+        want        time.Duration
+    }{
+        {0, DefaultDialTimeout},
+        {2 * time.Second, 2 * time.Second},
+        {-1, 0},
+    }
+
+    for _, test := range tests {
+        transport, err := newTransport(nil, &Config{DialTimeout: test.dialTimeout})
+        if err != nil {
+            t.Errorf("newTransport(DialTimeout %s) failed: %s", test.dialTimeout, err)
+        } else if transport.TLSHandshakeTimeout != test.want {
+            t.Errorf("newTransport(DialTimeout %s) has the handshake timeout %s, want %s", test.dialTimeout,
+                transport.TLSHandshakeTimeout, test.want)
+        }
+    }
+}
+
+func TestGetHttpExitCode(t *testing.T) {
+    tests := []struct {
+        status  int
+        want    int
+    }{
+        {http.StatusNotFound, EXITCODE_ERR_NOT_FOUND},
+        {http.StatusBadRequest, 144},
+        {http.StatusUnauthorized, 145},
+        {http.StatusConflict, 153},
+        {http.StatusInternalServerError, 244},
+        {http.StatusBadGateway, 246},
+    }
+
+    for _, test := range tests {
+        if exitCode := getHttpExitCode(test.status); exitCode != test.want {
+            t.Errorf("getHttpExitCode(%d) = %d, want %d", test.status, exitCode, test.want)
+        }
+    }
+}
+
+// newTestCertificate returns a self-signed client certificate, and its certificate and key in PEM format
+func newTestCertificate(t *testing.T) (tls.Certificate, []byte, []byte) {
+    key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+    if err != nil {
+        t.Fatalf("ecdsa.GenerateKey() failed: %s", err)
+    }
+
+    template := &x509.Certificate{
+        SerialNumber: big.NewInt(1),
+        Subject: pkix.Name{CommonName: "wsk test client"},
+        NotBefore: time.Now().Add(-time.Hour),
+        NotAfter: time.Now().Add(time.Hour),
+        KeyUsage: x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+        ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+        BasicConstraintsValid: true,
+        IsCA: true,
+    }
+    der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+    if err != nil {
+        t.Fatalf("x509.CreateCertificate() failed: %s", err)
+    }
+    keyDER, err := x509.MarshalECPrivateKey(key)
+    if err != nil {
+        t.Fatalf("x509.MarshalECPrivateKey() failed: %s", err)
+    }
+
+    certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+    keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+    certificate, err := tls.X509KeyPair(certPEM, keyPEM)
+    if err != nil {
+        t.Fatalf("tls.X509KeyPair() failed: %s", err)
+    }
+
+    return certificate, certPEM, keyPEM
+}
+
+// getTLSTestCA returns the certificate of a TLS test server in PEM format
+func getTLSTestCA(server *httptest.Server) []byte {
+    return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+}
+
+// startTLSTestServer starts an HTTPS server with the TLS settings tlsConfig, which does not log the failed handshakes
+// that the tests cause
+func startTLSTestServer(handler http.HandlerFunc, tlsConfig *tls.Config) *httptest.Server {
+    server := httptest.NewUnstartedServer(handler)
+    server.TLS = tlsConfig
+    server.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+    server.StartTLS()
+
+    return server
+}
+
+// newTLSTestClient returns a client of a TLS server with the given settings, which does not retry requests
+func newTLSTestClient(t *testing.T, server *httptest.Server, config *Config) *Client {
+    config.BaseURL, _ = url.Parse(server.URL + "/api")
+    config.Namespace, config.AuthToken = "ns", "user:key"
+
+    client, err := NewClient(nil, config)
+    if err != nil {
+        t.Fatalf("NewClient() failed: %s", err)
+    }
+    client.Config.MaxRetries = 0
+
+    return client
+}
+
+func TestTLSCustomCA(t *testing.T) {
+    server := startTLSTestServer(func(w http.ResponseWriter, r *http.Request) {
+        writeTestJSON(w, http.StatusOK, map[string]interface{}{"name": "a"})
+    }, nil)
+    defer server.Close()
+
+    dir, err := ioutil.TempDir("", "wsk")
+    if err != nil {
+        t.Fatalf("ioutil.TempDir() failed: %s", err)
+    }
+    defer os.RemoveAll(dir)
+
+    caFile := filepath.Join(dir, "ca.pem")
+    if err := ioutil.WriteFile(caFile, getTLSTestCA(server), 0644); err != nil {
+        t.Fatalf("ioutil.WriteFile(%s) failed: %s", caFile, err)
+    }
+    certPool := x509.NewCertPool()
+    certPool.AppendCertsFromPEM(getTLSTestCA(server))
+    tlsConfig := &tls.Config{RootCAs: certPool}
+
+    tests := []struct {
+        description string
+        config      *Config
+        connects    bool
+    }{
+        {"the system certificate authorities", &Config{}, false},
+        {"the CA in TLSConfig", &Config{TLSConfig: tlsConfig}, true},
+        {"the CA file", &Config{CAFile: caFile}, true},
+        {"certificate checking disabled", &Config{Insecure: true}, true},
+        {"an empty TLSConfig", &Config{TLSConfig: &tls.Config{}}, false},
+    }
+
+    for _, test := range tests {
+        client := newTLSTestClient(t, server, test.config)
+        action, _, err := client.Actions.Get("a")
+
+        if test.connects && (err != nil || action.Name != "a") {
+            t.Errorf("Actions.Get() with %s = %#v, %v; want the action a", test.description, action, err)
+        } else if whiskErr, ok := err.(*WskError); !test.connects && (!ok || whiskErr.ExitCode != EXITCODE_ERR_NETWORK ||
+                !strings.Contains(whiskErr.Error(), "certificate")) {
+            t.Errorf("Actions.Get() with %s error = %#v, want a certificate error", test.description, err)
+        }
+    }
+
+    // The TLS settings of the caller are not changed
+    newTLSTestClient(t, server, &Config{TLSConfig: tlsConfig, Insecure: true})
+    if tlsConfig.InsecureSkipVerify {
+        t.Errorf("NewClient() with Insecure disabled certificate checking in the given TLSConfig")
+    }
+}
+
+func TestTLSClientCertificate(t *testing.T) {
+    certificate, certPEM, _ := newTestCertificate(t)
+    clientCAs := x509.NewCertPool()
+    clientCAs.AppendCertsFromPEM(certPEM)
+
+    server := startTLSTestServer(func(w http.ResponseWriter, r *http.Request) {
+        writeTestJSON(w, http.StatusOK, map[string]interface{}{"name": r.TLS.PeerCertificates[0].Subject.CommonName})
+    }, &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs})
+    defer server.Close()
+
+    rootCAs := x509.NewCertPool()
+    rootCAs.AppendCertsFromPEM(getTLSTestCA(server))
+
+    client := newTLSTestClient(t, server, &Config{TLSConfig: &tls.Config{RootCAs: rootCAs, Certificates: []tls.Certificate{certificate}}})
+    if action, _, err := client.Actions.Get("a"); err != nil || action.Name != "wsk test client" {
+        t.Errorf("Actions.Get() with a client certificate = %#v, %v; want the name of the certificate", action, err)
+    }
+
+    client = newTLSTestClient(t, server, &Config{TLSConfig: &tls.Config{RootCAs: rootCAs}})
+    if _, _, err := client.Actions.Get("a"); err == nil {
+        t.Errorf("Actions.Get() without a client certificate succeeded, want an error")
+    }
+}
+
+// roundTripperFunc is a round tripper that is not an *http.Transport
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+    return f(req)
+}
+
+func TestNewClientCallerTransport(t *testing.T) {
+    callerTransport := &http.Transport{MaxIdleConns: 7}
+    callerClient := &http.Client{Transport: callerTransport}
+
+    client, err := NewClient(callerClient, &Config{Insecure: true, ProxyURL: "http://proxy:3128"})
+    if err != nil {
+        t.Fatalf("NewClient() with a transport failed: %s", err)
+    }
+
+    // The settings are applied to a clone of the caller's transport, which is not changed. Cloning a transport may set
+    // up its HTTP/2 support, and so its TLSClientConfig, but not its certificate checking.
+    if callerClient.Transport != callerTransport || callerTransport.Proxy != nil ||
+            callerTransport.TLSClientConfig != nil && callerTransport.TLSClientConfig.InsecureSkipVerify {
+        t.Errorf("NewClient() changed the caller's client or transport")
+    }
+    if client.Transport == callerTransport || client.Transport.MaxIdleConns != 7 ||
+            !client.Transport.TLSClientConfig.InsecureSkipVerify || client.Transport.Proxy == nil {
+        t.Errorf("NewClient() has the transport %#v, want a clone of the caller's with the settings applied",
+            client.Transport)
+    }
+
+    // The default client is copied like any other
+    if _, err = NewClient(http.DefaultClient, &Config{Insecure: true}); err != nil || http.DefaultClient.Transport != nil {
+        t.Errorf("NewClient(http.DefaultClient) = %v and set the default transport %#v", err, http.DefaultClient.Transport)
+    }
+
+    // Any round tripper is used as is, but only an *http.Transport takes the settings
+    roundTripper := roundTripperFunc(func(req *http.Request) (*http.Response, error) { return nil, nil })
+    if _, err = NewClient(&http.Client{Transport: roundTripper}, &Config{}); err != nil {
+        t.Errorf("NewClient() with a round tripper failed: %s", err)
+    }
+    _, err = NewClient(&http.Client{Transport: roundTripper}, &Config{Insecure: true})
+    if whiskErr, ok := err.(*WskError); !ok || !strings.Contains(whiskErr.Error(), "require an *http.Transport") {
+        t.Errorf("NewClient() of a round tripper with Insecure error = %#v, want an unsupported transport error", err)
+    }
+}
+
+func TestNewTransportCAFileErrors(t *testing.T) {
+    dir, err := ioutil.TempDir("", "wsk")
+    if err != nil {
+        t.Fatalf("ioutil.TempDir() failed: %s", err)
+    }
+    defer os.RemoveAll(dir)
+
+    notPEM := filepath.Join(dir, "ca.txt")
+    ioutil.WriteFile(notPEM, []byte("not a certificate\n"), 0644)
+
+    tests := []struct {
+        caFile  string
+        want    string
+    }{
+        {filepath.Join(dir, "missing.pem"), "Unable to read the CA certificate file"},
+        {notPEM, "does not contain any PEM encoded certificates"},
+    }
+
+    for _, test := range tests {
+        _, err := newTransport(nil, &Config{CAFile: test.caFile})
+        if whiskErr, ok := err.(*WskError); !ok || whiskErr.ExitCode != EXITCODE_ERR_GENERAL ||
+                !strings.Contains(whiskErr.Error(), test.want) {
+            t.Errorf("newTransport(CAFile %s) error = %#v, want %q", test.caFile, err, test.want)
+        }
+    }
+}
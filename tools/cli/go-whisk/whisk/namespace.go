@@ -19,6 +19,8 @@ package whisk
 import (
     "net/http"
     "errors"
+    "fmt"
+    "strings"
     "../wski18n"
 )
 
@@ -34,6 +36,13 @@ type Contents struct {
     Rules    []Rule         `json:"rules"`
 }
 
+type NamespaceStats struct {
+    Actions  int    `json:"actions"`
+    Packages int    `json:"packages"`
+    Triggers int    `json:"triggers"`
+    Rules    int    `json:"rules"`
+}
+
 type NamespaceService struct {
     client *Client
 }
@@ -102,3 +111,174 @@ func (s *NamespaceService) Get(namespace string) (*Namespace, *http.Response, er
 
     return resNamespace, resp, nil
 }
+
+// NamespaceCollections are the entity collections of a namespace, in the order they are listed
+var NamespaceCollections = []string{"packages", "actions", "triggers", "rules"}
+
+// GetEntities returns the entities of a namespace that belong to the given collections. Only the requested
+// collections are fetched, and an unknown collection is rejected before any request is made.
+func (s *NamespaceService) GetEntities(namespace string, collections []string) (*Namespace, *http.Response, error) {
+    var resp *http.Response
+    var err error
+
+    requested := make(map[string]bool)
+    for _, collection := range collections {
+        if !IsNamespaceCollection(collection) {
+            Debug(DbgError, "Unknown entity collection '%s'\n", collection)
+            errStr := wski18n.T("Unknown entity type '{{.entity}}'; valid types are {{.valid}}",
+                map[string]interface{}{"entity": collection, "valid": strings.Join(NamespaceCollections, ", ")})
+            werr := MakeWskError(errors.New(errStr), EXITCODE_ERR_USAGE, DISPLAY_MSG, NO_DISPLAY_USAGE)
+            return nil, nil, werr
+        }
+        requested[collection] = true
+    }
+
+    if len(namespace) == 0 {
+        namespace = s.client.Config.Namespace
+    }
+
+    s.client.Namespace = namespace
+    resNamespace := &Namespace{
+        Name: namespace,
+    }
+
+    if requested["packages"] {
+        resp, err = ListAllPages(0, func(limit int, skip int) (int, *http.Response, error) {
+            packages, resp, err := s.client.Packages.List(&PackageListOptions{Limit: limit, Skip: skip})
+            resNamespace.Contents.Packages = append(resNamespace.Contents.Packages, packages...)
+            return len(packages), resp, err
+        })
+        if err != nil {
+            return resNamespace, resp, err
+        }
+    }
+
+    if requested["actions"] {
+        resp, err = ListAllPages(0, func(limit int, skip int) (int, *http.Response, error) {
+            actions, _, resp, err := s.client.Actions.List("", &ActionListOptions{Limit: limit, Skip: skip})
+            resNamespace.Contents.Actions = append(resNamespace.Contents.Actions, actions...)
+            return len(actions), resp, err
+        })
+        if err != nil {
+            return resNamespace, resp, err
+        }
+    }
+
+    if requested["triggers"] {
+        resp, err = ListAllPages(0, func(limit int, skip int) (int, *http.Response, error) {
+            triggers, _, resp, err := s.client.Triggers.List(&TriggerListOptions{Limit: limit, Skip: skip})
+            resNamespace.Contents.Triggers = append(resNamespace.Contents.Triggers, triggers...)
+            return len(triggers), resp, err
+        })
+        if err != nil {
+            return resNamespace, resp, err
+        }
+    }
+
+    if requested["rules"] {
+        resp, err = ListAllPages(0, func(limit int, skip int) (int, *http.Response, error) {
+            rules, _, resp, err := s.client.Rules.List(&RuleListOptions{Limit: limit, Skip: skip})
+            resNamespace.Contents.Rules = append(resNamespace.Contents.Rules, rules...)
+            return len(rules), resp, err
+        })
+        if err != nil {
+            return resNamespace, resp, err
+        }
+    }
+
+    Debug(DbgInfo, "Returning namespace: %#v\n", resNamespace)
+
+    return resNamespace, resp, nil
+}
+
+// IsNamespaceCollection reports whether name is one of the entity collections of a namespace
+func IsNamespaceCollection(name string) bool {
+    for _, collection := range NamespaceCollections {
+        if name == collection {
+            return true
+        }
+    }
+
+    return false
+}
+
+// Stats returns the number of actions, packages, triggers and rules in a namespace
+func (s *NamespaceService) Stats(namespace string) (*NamespaceStats, *http.Response, error) {
+    var resp *http.Response
+    var err error
+
+    if len(namespace) == 0 {
+        namespace = s.client.Config.Namespace
+    }
+
+    s.client.Namespace = namespace
+    stats := &NamespaceStats{}
+
+    if stats.Actions, resp, err = s.Count("actions"); err != nil {
+        return stats, resp, err
+    }
+
+    if stats.Packages, resp, err = s.Count("packages"); err != nil {
+        return stats, resp, err
+    }
+
+    if stats.Triggers, resp, err = s.Count("triggers"); err != nil {
+        return stats, resp, err
+    }
+
+    if stats.Rules, resp, err = s.Count("rules"); err != nil {
+        return stats, resp, err
+    }
+
+    Debug(DbgInfo, "Returning namespace stats: %#v\n", stats)
+
+    return stats, resp, nil
+}
+
+// Count returns the number of entities in a collection of the client namespace. The size the server reports in the
+// X-Total-Count header of the first page is used when it is available; otherwise the collection is listed page by page
+// and the entities are counted.
+func (s *NamespaceService) Count(collection string) (int, *http.Response, error) {
+    entities, resp, err := s.listPage(collection, MaxListPageSize, 0)
+    if err != nil {
+        return 0, resp, err
+    }
+
+    if total := getTotalCount(resp); total >= 0 {
+        return total, resp, nil
+    }
+
+    total := len(entities)
+    if total < MaxListPageSize {
+        return total, resp, nil
+    }
+
+    resp, err = ListAllPages(total, func(limit int, skip int) (int, *http.Response, error) {
+        entities, resp, err := s.listPage(collection, limit, skip)
+        total += len(entities)
+        return len(entities), resp, err
+    })
+
+    return total, resp, err
+}
+
+func (s *NamespaceService) listPage(collection string, limit int, skip int) ([]interface{}, *http.Response, error) {
+    var entities []interface{}
+
+    route := fmt.Sprintf("%s?limit=%d&skip=%d", collection, limit, skip)
+    req, err := s.client.NewRequest("GET", route, nil, IncludeNamespaceInUrl)
+    if err != nil {
+        Debug(DbgError, "s.client.NewRequest(GET) error: %s\n", err)
+        errStr := wski18n.T("Unable to create HTTP request for GET: {{.err}}", map[string]interface{}{"err": err})
+        werr := MakeWskErrorFromWskError(errors.New(errStr), err, EXITCODE_ERR_GENERAL, DISPLAY_MSG, NO_DISPLAY_USAGE)
+        return nil, nil, werr
+    }
+
+    resp, err := s.client.Do(req, &entities, ExitWithSuccessOnTimeout)
+    if err != nil {
+        Debug(DbgError, "s.client.Do() error - HTTP req %s; error '%s'\n", req.URL.String(), err)
+        return nil, resp, err
+    }
+
+    return entities, resp, nil
+}
@@ -17,10 +17,13 @@
 package whisk
 
 import (
+    "encoding/json"
     "fmt"
     "net/http"
     "net/url"
     "errors"
+    "sort"
+    "strings"
     "../wski18n"
 )
 
@@ -49,6 +52,44 @@ func (p *Package) GetName() string {
     return p.Name
 }
 
+// Compare orders packages by namespace and then name, ignoring case.
+// The sortable argument must also be a Package.
+func (p Package) Compare(sortable Sortable) bool {
+    packageToCompare := sortable.(Package)
+    packageString := strings.ToLower(fmt.Sprintf("%s/%s", p.Namespace, p.Name))
+    compareString := strings.ToLower(fmt.Sprintf("%s/%s", packageToCompare.Namespace, packageToCompare.Name))
+
+    return packageString < compareString
+}
+
+// ListString returns the package formatted as a row of the package list, followed by "binding" when the package is
+// a binding
+func (p Package) ListString() string {
+    row := fmt.Sprintf("%-70s %s", fmt.Sprintf("/%s/%s", p.Namespace, p.Name), getPublishState(p.Publish))
+    if p.IsBinding() {
+        row += " " + wski18n.T("binding")
+    }
+
+    return row + "\n"
+}
+
+// Columns returns the package as the columns of a package list row: its fully qualified name and publish state,
+// followed by "binding" when the package is a binding
+func (p Package) Columns() []string {
+    columns := []string{fmt.Sprintf("/%s/%s", p.Namespace, p.Name), getPublishState(p.Publish)}
+    if p.IsBinding() {
+        columns = append(columns, wski18n.T("binding"))
+    }
+
+    return columns
+}
+
+// IsBinding reports whether the package is a binding of another package. The server returns an empty binding for
+// packages that are not bindings.
+func (p Package) IsBinding() bool {
+    return p.Binding != nil && len(p.Binding.Name) > 0
+}
+
 // Use this struct when creating a binding
 // Publish is NOT optional; Binding is a namespace/name object, not a bool
 type BindingPackage struct {
@@ -57,7 +98,7 @@ type BindingPackage struct {
     Version     string              `json:"version,omitempty"`
     Publish     *bool               `json:"publish,omitempty"`
     Annotations KeyValueArr         `json:"annotations,omitempty"`
-    Parameters  KeyValueArr         `json:"parameters,omitempty"`
+    Parameters  KeyValueArr         `json:"parameters"`
     Binding                         `json:"binding"`
 }
 func (p *BindingPackage) GetName() string {
@@ -75,6 +116,82 @@ type BindingUpdates struct {
     Deleted     []string            `json:"deleted,omitempty"`
 }
 
+// RefreshBinding returns binding bound to source, given the package it was bound to before as previousSource, which
+// is nil when that package no longer exists. Since the server merges the parameters of the bound package into those of
+// a binding when the binding is read, only the parameters of binding that are not taken from previousSource are kept,
+// along with the given parameters, which override them. The changes to the parameters seen through the binding are
+// returned as BindingUpdates.
+func RefreshBinding(binding *Package, previousSource *Package, source *Package, parameters KeyValueArr) (*BindingPackage, *BindingUpdates) {
+    overrides := GetBindingParameters(binding, previousSource)
+    for _, keyValue := range parameters {
+        overrides = overrides.AddOrReplace(keyValue)
+    }
+
+    merged := append(KeyValueArr{}, source.Parameters...)
+    for _, keyValue := range overrides {
+        merged = merged.AddOrReplace(keyValue)
+    }
+
+    updates := &BindingUpdates{}
+    for _, keyValue := range merged {
+        if value, found := binding.Parameters.GetValue(keyValue.Key); !found {
+            updates.Added = append(updates.Added, keyValue.Key)
+        } else if !isSameJSONValue(value, keyValue.Value) {
+            updates.Updated = append(updates.Updated, keyValue.Key)
+        }
+    }
+    for _, keyValue := range binding.Parameters {
+        if merged.FindKeyValue(keyValue.Key) < 0 {
+            updates.Deleted = append(updates.Deleted, keyValue.Key)
+        }
+    }
+
+    sort.Strings(updates.Added)
+    sort.Strings(updates.Updated)
+    sort.Strings(updates.Deleted)
+
+    refreshed := &BindingPackage{
+        Namespace:   binding.Namespace,
+        Name:        binding.Name,
+        Publish:     binding.Publish,
+        Annotations: binding.Annotations,
+        Parameters:  overrides,
+        Binding:     Binding{Namespace: source.Namespace, Name: source.Name},
+    }
+
+    return refreshed, updates
+}
+
+// GetBindingParameters returns the parameters set on binding itself, as read from the server, which merges the
+// parameters of source, the package it is bound to, into those of the binding. Parameters with the same value as in
+// source cannot be told apart from those of source, and are left out. source is nil when the package no longer exists.
+// The result is not nil, so that an update sends it even when it is empty.
+func GetBindingParameters(binding *Package, source *Package) KeyValueArr {
+    parameters := KeyValueArr{}
+    for _, keyValue := range binding.Parameters {
+        if source != nil {
+            if value, found := source.Parameters.GetValue(keyValue.Key); found && isSameJSONValue(value, keyValue.Value) {
+                continue
+            }
+        }
+        parameters = append(parameters, keyValue)
+    }
+
+    return parameters
+}
+
+// isSameJSONValue reports whether two values have the same JSON encoding, so that numbers decoded as json.Number and
+// as float64 compare equal
+func isSameJSONValue(value interface{}, other interface{}) bool {
+    data, err := json.Marshal(value)
+    if err != nil {
+        return false
+    }
+
+    otherData, err := json.Marshal(other)
+    return err == nil && string(data) == string(otherData)
+}
+
 type PackageListOptions struct {
     Public      bool                `url:"public,omitempty"`
     Limit       int                 `url:"limit"`
@@ -145,7 +262,12 @@ func (s *PackageService) Insert(x_package PackageInterface, overwrite bool) (*Pa
     packageName := (&url.URL{Path: x_package.GetName()}).String()
     route := fmt.Sprintf("packages/%s?overwrite=%t", packageName, overwrite)
 
-    req, err := s.client.NewRequest("PUT", route, x_package, IncludeNamespaceInUrl)
+    var body interface{} = x_package
+    if p, ok := x_package.(*Package); ok {
+        body = getInsertBody(p, p.Parameters, p.Annotations)
+    }
+
+    req, err := s.client.NewRequest("PUT", route, body, IncludeNamespaceInUrl)
     if err != nil {
         Debug(DbgError, "http.NewRequest(PUT, %s); error: '%s'\n", route, err)
         errStr := wski18n.T("Unable to create PUT HTTP request for '{{.route}}': {{.err}}",
@@ -0,0 +1,101 @@
+/*
+ * Copyright 2015-2016 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+
+package whisk
+
+import (
+    "encoding/json"
+    "reflect"
+    "testing"
+)
+
+func TestRefreshBinding(t *testing.T) {
+    // The binding was bound to previous, and sets port and user itself; the server merged host into it
+    previous := &Package{Namespace: "ns", Name: "pkg",
+        Parameters: KeyValueArr{{Key: "host", Value: "a.example.com"}, {Key: "port", Value: 80}}}
+    binding := &Package{Namespace: "ns", Name: "binding", Annotations: KeyValueArr{{Key: "owner", Value: "me"}},
+        Binding: &Binding{Namespace: "ns", Name: "pkg"},
+        Parameters: KeyValueArr{{Key: "host", Value: "a.example.com"}, {Key: "port", Value: 8080}, {Key: "user", Value: "me"}}}
+
+    tests := []struct {
+        description     string
+        previousSource  *Package
+        source          *Package
+        parameters      KeyValueArr
+        want            KeyValueArr
+        updates         BindingUpdates
+    }{
+        {"an unchanged package", previous, previous, nil,
+            KeyValueArr{{Key: "port", Value: 8080}, {Key: "user", Value: "me"}}, BindingUpdates{}},
+        {"a package with a changed and an added parameter", previous,
+            &Package{Namespace: "ns", Name: "pkg", Parameters: KeyValueArr{{Key: "host", Value: "b.example.com"},
+                {Key: "port", Value: 80}, {Key: "timeout", Value: 5}}},
+            nil,
+            KeyValueArr{{Key: "port", Value: 8080}, {Key: "user", Value: "me"}},
+            BindingUpdates{Added: []string{"timeout"}, Updated: []string{"host"}}},
+        {"a package without host", previous,
+            &Package{Namespace: "ns", Name: "pkg", Parameters: KeyValueArr{{Key: "port", Value: 80}}},
+            nil,
+            KeyValueArr{{Key: "port", Value: 8080}, {Key: "user", Value: "me"}},
+            BindingUpdates{Deleted: []string{"host"}}},
+        // The given parameters override those of the binding and of the package
+        {"given parameters", previous, previous, KeyValueArr{{Key: "user", Value: "you"}, {Key: "host", Value: "c.example.com"}},
+            KeyValueArr{{Key: "port", Value: 8080}, {Key: "user", Value: "you"}, {Key: "host", Value: "c.example.com"}},
+            BindingUpdates{Updated: []string{"host", "user"}}},
+        // Without the previous package, every parameter of the binding is kept
+        {"a package that replaces one that was deleted", nil,
+            &Package{Namespace: "other", Name: "pkg2", Parameters: KeyValueArr{{Key: "host", Value: "d.example.com"},
+                {Key: "region", Value: "eu"}}},
+            nil,
+            KeyValueArr{{Key: "host", Value: "a.example.com"}, {Key: "port", Value: 8080}, {Key: "user", Value: "me"}},
+            BindingUpdates{Added: []string{"region"}}},
+    }
+
+    for _, test := range tests {
+        refreshed, updates := RefreshBinding(binding, test.previousSource, test.source, test.parameters)
+
+        if !reflect.DeepEqual(refreshed.Parameters, test.want) {
+            t.Errorf("RefreshBinding() with %s has the parameters %#v, want %#v", test.description, refreshed.Parameters,
+                test.want)
+        }
+        if !reflect.DeepEqual(*updates, test.updates) {
+            t.Errorf("RefreshBinding() with %s has the updates %#v, want %#v", test.description, *updates, test.updates)
+        }
+        if refreshed.Namespace != "ns" || refreshed.Name != "binding" ||
+                refreshed.Binding != (Binding{Namespace: test.source.Namespace, Name: test.source.Name}) ||
+                !reflect.DeepEqual(refreshed.Annotations, binding.Annotations) {
+            t.Errorf("RefreshBinding() with %s = %#v, want the binding bound to /%s/%s", test.description, refreshed,
+                test.source.Namespace, test.source.Name)
+        }
+    }
+}
+
+func TestGetBindingParameters(t *testing.T) {
+    // Numbers decoded as json.Number are the same as the float64 of the package
+    source := &Package{Parameters: KeyValueArr{{Key: "n", Value: float64(5)}, {Key: "obj", Value: map[string]interface{}{"a": true}}}}
+    binding := &Package{Parameters: KeyValueArr{{Key: "n", Value: json.Number("5")},
+        {Key: "obj", Value: map[string]interface{}{"a": true}}, {Key: "s", Value: "x"}}}
+
+    if parameters := GetBindingParameters(binding, source); !reflect.DeepEqual(parameters, KeyValueArr{{Key: "s", Value: "x"}}) {
+        t.Errorf("GetBindingParameters() = %#v, want only s", parameters)
+    }
+
+    // The result is empty rather than nil, so that an update removes the parameters of the binding
+    if parameters := GetBindingParameters(&Package{}, source); parameters == nil || len(parameters) != 0 {
+        t.Errorf("GetBindingParameters() of a binding without parameters = %#v, want an empty array", parameters)
+    }
+}
@@ -17,6 +17,8 @@
 package whisk
 
 import (
+    "context"
+    "encoding/json"
     "fmt"
     "net/http"
     "strings"
@@ -36,6 +38,7 @@ type Rule struct {
     Status  string      `json:"status"`
     Trigger interface{} `json:"trigger"`
     Action  interface{} `json:"action"`
+    Annotations KeyValueArr `json:"annotations,omitempty"`
     Publish *bool       `json:"publish,omitempty"`
 
 }
@@ -46,7 +49,71 @@ type RuleListOptions struct {
     Docs        bool    `url:"docs,omitempty"`
 }
 
-func (s *RuleService) List(options *RuleListOptions) ([]Rule, *http.Response, error) {
+// Compare orders rules by namespace and then name, ignoring case.
+// The sortable argument must also be a Rule.
+func (rule Rule) Compare(sortable Sortable) bool {
+    ruleToCompare := sortable.(Rule)
+    ruleString := strings.ToLower(fmt.Sprintf("%s/%s", rule.Namespace, rule.Name))
+    compareString := strings.ToLower(fmt.Sprintf("%s/%s", ruleToCompare.Namespace, ruleToCompare.Name))
+
+    return ruleString < compareString
+}
+
+// ListString returns the rule formatted as a row of the rule list
+func (rule Rule) ListString() string {
+    publishState := wski18n.T("private")
+
+    return fmt.Sprintf("%-70s %s\n", fmt.Sprintf("/%s/%s", rule.Namespace, rule.Name), publishState)
+}
+
+// Columns returns the rule as the columns of a rule list row: its fully qualified name and publish state
+func (rule Rule) Columns() []string {
+    return []string{fmt.Sprintf("/%s/%s", rule.Namespace, rule.Name), wski18n.T("private")}
+}
+
+// UnmarshalJSON decodes a rule. Newer API versions return the trigger and action as objects with a path and a name;
+// these are converted to fully qualified names, so Trigger and Action hold strings when they are present.
+func (rule *Rule) UnmarshalJSON(data []byte) error {
+    type ruleFields Rule    // Has no UnmarshalJSON method, so decoding it does not recurse
+    var fields ruleFields
+
+    if err := json.Unmarshal(data, &fields); err != nil {
+        return err
+    }
+
+    // Rule summaries in lists have neither
+    if fields.Trigger != nil {
+        fields.Trigger = GetRuleEntityName(fields.Trigger)
+    }
+    if fields.Action != nil {
+        fields.Action = GetRuleEntityName(fields.Action)
+    }
+    *rule = Rule(fields)
+
+    return nil
+}
+
+// GetRuleEntityName returns the fully qualified name of the trigger or action of a rule, which the server sends as an
+// object with a path and a name; names are returned as is, and anything else as ""
+func GetRuleEntityName(entity interface{}) string {
+    switch entity := entity.(type) {
+    case string:
+        return entity
+    case map[string]interface{}:
+        path, _ := entity["path"].(string)
+        if len(path) == 0 {
+            path, _ = entity["namespace"].(string)
+        }
+        name, _ := entity["name"].(string)
+        return fmt.Sprintf("/%s/%s", path, name)
+    }
+
+    return ""
+}
+
+// List lists rules. It also returns the number of rules in the collection, from the X-Total-Count response header,
+// or -1 when the server does not report it.
+func (s *RuleService) List(options *RuleListOptions) ([]Rule, int, *http.Response, error) {
     route := "rules"
     routeUrl, err := addRouteOptions(route, options)
     if err != nil {
@@ -54,7 +121,7 @@ func (s *RuleService) List(options *RuleListOptions) ([]Rule, *http.Response, er
         errStr := wski18n.T("Unable to append options '{{.options}}' to URL route '{{.route}}': {{.err}}",
             map[string]interface{}{"options": fmt.Sprintf("%#v", options), "route": route, "err": err})
         werr := MakeWskError(errors.New(errStr), EXITCODE_ERR_GENERAL, DISPLAY_MSG, NO_DISPLAY_USAGE)
-        return nil, nil, werr
+        return nil, -1, nil, werr
     }
 
     req, err := s.client.NewRequestUrl("GET", routeUrl, nil, IncludeNamespaceInUrl, AppendOpenWhiskPathPrefix, EncodeBodyAsJson, AuthRequired)
@@ -63,17 +130,17 @@ func (s *RuleService) List(options *RuleListOptions) ([]Rule, *http.Response, er
         errStr := wski18n.T("Unable to create HTTP request for GET '{{.route}}': {{.err}}",
             map[string]interface{}{"route": route, "err": err})
         werr := MakeWskErrorFromWskError(errors.New(errStr), err, EXITCODE_ERR_GENERAL, DISPLAY_MSG, NO_DISPLAY_USAGE)
-        return nil, nil, werr
+        return nil, -1, nil, werr
     }
 
     var rules []Rule
     resp, err := s.client.Do(req, &rules, ExitWithSuccessOnTimeout)
     if err != nil {
         Debug(DbgError, "s.client.Do() error - HTTP req %s; error: '%s'\n", req.URL.String(), err)
-        return nil, resp, err
+        return nil, -1, resp, err
     }
 
-    return rules, resp, err
+    return rules, getTotalCount(resp), resp, err
 }
 
 func (s *RuleService) Insert(rule *Rule, overwrite bool) (*Rule, *http.Response, error) {
@@ -151,6 +218,11 @@ func (s *RuleService) Delete(ruleName string) (*http.Response, error) {
 }
 
 func (s *RuleService) SetState(ruleName string, state string) (*Rule, *http.Response, error) {
+    return s.SetStateWithContext(context.Background(), ruleName, state)
+}
+
+// SetStateWithContext sets the state of a rule; the request is abandoned when ctx is cancelled or its deadline passes
+func (s *RuleService) SetStateWithContext(ctx context.Context, ruleName string, state string) (*Rule, *http.Response, error) {
     state = strings.ToLower(state)
     if state != "active" && state != "inactive" {
         errStr := wski18n.T("Internal error. Invalid state option '{{.state}}'. Valid options are \"active\" and \"inactive\".",
@@ -166,7 +238,7 @@ func (s *RuleService) SetState(ruleName string, state string) (*Rule, *http.Resp
 
     ruleState := &Rule{ Status: state }
 
-    req, err := s.client.NewRequest("POST", route, ruleState, IncludeNamespaceInUrl)
+    req, err := s.client.NewRequestWithContext(ctx, "POST", route, ruleState, IncludeNamespaceInUrl)
     if err != nil {
         Debug(DbgError, "http.NewRequest(POST, %s); error: '%s'\n", route, err)
         errStr := wski18n.T("Unable to create HTTP request for POST '{{.route}}': {{.err}}",
@@ -0,0 +1,155 @@
+/*
+ * Copyright 2015-2016 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package whisk
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "reflect"
+    "strings"
+    "testing"
+    "time"
+)
+
+func TestRuleJSONRoundTrip(t *testing.T) {
+    publish := true
+    rules := []Rule{
+        {Namespace: "ns", Name: "r", Version: "0.0.1", Status: "active", Trigger: "/ns/t", Action: "/ns/pkg/a",
+            Annotations: KeyValueArr{{Key: "owner", Value: "me"}}, Publish: &publish},
+        {Namespace: "ns", Name: "row"},
+    }
+
+    for _, rule := range rules {
+        data, err := json.Marshal(rule)
+        if err != nil {
+            t.Fatalf("json.Marshal(%#v) failed: %s", rule, err)
+        }
+
+        var decoded Rule
+        if err = json.Unmarshal(data, &decoded); err != nil {
+            t.Fatalf("json.Unmarshal(%s) failed: %s", data, err)
+        }
+        if !reflect.DeepEqual(decoded, rule) {
+            t.Errorf("rule %s decoded as %#v, want %#v", data, decoded, rule)
+        }
+    }
+}
+
+func TestRuleUnmarshalEntityForms(t *testing.T) {
+    tests := []struct {
+        data    string
+        trigger interface{}
+        action  interface{}
+    }{
+        // Older API versions send names
+        {`{"name":"r","trigger":"/ns/t","action":"/ns/pkg/a"}`, "/ns/t", "/ns/pkg/a"},
+        // Newer ones send objects with a path and a name
+        {`{"name":"r","trigger":{"path":"ns","name":"t"},"action":{"path":"ns/pkg","name":"a"}}`, "/ns/t", "/ns/pkg/a"},
+        {`{"name":"r","trigger":{"namespace":"ns","name":"t"},"action":"/ns/a"}`, "/ns/t", "/ns/a"},
+        // Rule summaries have neither
+        {`{"name":"r"}`, nil, nil},
+        {`{"name":"r","trigger":5,"action":["a"]}`, "", ""},
+    }
+
+    for _, test := range tests {
+        var rule Rule
+        if err := json.Unmarshal([]byte(test.data), &rule); err != nil {
+            t.Errorf("json.Unmarshal(%s) failed: %s", test.data, err)
+            continue
+        }
+        if rule.Name != "r" || rule.Trigger != test.trigger || rule.Action != test.action {
+            t.Errorf("rule %s decoded as name %q, trigger %#v, action %#v; want trigger %#v, action %#v",
+                test.data, rule.Name, rule.Trigger, rule.Action, test.trigger, test.action)
+        }
+    }
+}
+
+func TestRuleUnmarshalInvalid(t *testing.T) {
+    var rule Rule
+    if err := json.Unmarshal([]byte(`{"name":5}`), &rule); err == nil {
+        t.Errorf("json.Unmarshal() of a rule with a numeric name succeeded, want an error")
+    }
+}
+
+// newStalledTestClient returns a client of a server that does not answer until release is closed
+func newStalledTestClient(t *testing.T, release chan struct{}) (*Client, *httptest.Server) {
+    client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+        <-release
+    })
+    client.Config.MaxRetries = 0
+
+    return client, server
+}
+
+func TestContextDeadlineExceeded(t *testing.T) {
+    calls := map[string]func(ctx context.Context, client *Client) error{
+        "Rules.SetStateWithContext": func(ctx context.Context, client *Client) error {
+            _, _, err := client.Rules.SetStateWithContext(ctx, "r", "active")
+            return err
+        },
+        "Actions.GetWithContext": func(ctx context.Context, client *Client) error {
+            _, _, err := client.Actions.GetWithContext(ctx, "a")
+            return err
+        },
+        "Actions.InsertWithContext": func(ctx context.Context, client *Client) error {
+            _, _, err := client.Actions.InsertWithContext(ctx, &Action{Name: "a"}, true)
+            return err
+        },
+    }
+
+    for name, call := range calls {
+        release := make(chan struct{})
+        client, server := newStalledTestClient(t, release)
+        ctx, cancel := context.WithTimeout(context.Background(), 50 * time.Millisecond)
+
+        start := time.Now()
+        err := call(ctx, client)
+        elapsed := time.Since(start)
+        cancel()
+        close(release)
+        server.Close()
+
+        if ctx.Err() != context.DeadlineExceeded {
+            t.Errorf("%s() returned before the deadline: %v", name, err)
+        }
+        if whiskErr, ok := err.(*WskError); !ok || whiskErr.ExitCode != EXITCODE_ERR_NETWORK ||
+                !strings.Contains(whiskErr.Error(), "timed out") {
+            t.Errorf("%s() error = %#v, want a timed out WskError with exit code %d", name, err, EXITCODE_ERR_NETWORK)
+        }
+        if elapsed > 2 * time.Second {
+            t.Errorf("%s() took %s, want it to give up at the deadline", name, elapsed)
+        }
+    }
+}
+
+func TestContextCanceled(t *testing.T) {
+    release := make(chan struct{})
+    client, server := newStalledTestClient(t, release)
+    defer server.Close()
+    defer close(release)
+
+    ctx, cancel := context.WithCancel(context.Background())
+    time.AfterFunc(50 * time.Millisecond, cancel)
+
+    _, _, err := client.Rules.SetStateWithContext(ctx, "r", "inactive")
+    if whiskErr, ok := err.(*WskError); !ok || whiskErr.ExitCode != EXITCODE_ERR_NETWORK {
+        t.Errorf("SetStateWithContext() with a cancelled context error = %#v, want a WskError with exit code %d",
+            err, EXITCODE_ERR_NETWORK)
+    }
+}
@@ -17,9 +17,13 @@
 package whisk
 
 import (
+    "context"
     "fmt"
     "errors"
+    "io"
     "net/http"
+    "sync"
+    "time"
     "../wski18n"
 )
 
@@ -38,6 +42,14 @@ type SdkRequest struct {
 
 // Install artifact {component = docker || swift || iOS}
 func (s *SdkService) Install(relFileUrl string) (*http.Response, error) {
+    return s.Download(relFileUrl, 0)
+}
+
+// Download requests an SDK artifact from the API host. A positive offset requests only the bytes from offset on, to
+// resume an interrupted download; servers that do not support ranges respond with the whole artifact and status 200
+// instead of 206. The response body is left for the caller to read and close. Reading the body fails once no data
+// has arrived for the DownloadIdleTimeout of the client, so that a stalled download can be resumed with a new request.
+func (s *SdkService) Download(relFileUrl string, offset int64) (*http.Response, error) {
 
     urlStr := fmt.Sprintf("https://%s/%s", s.client.Config.BaseURL.Host, relFileUrl)
 
@@ -50,25 +62,90 @@ func (s *SdkService) Install(relFileUrl string) (*http.Response, error) {
         return nil, werr
     }
 
-    if IsVerbose() {
-        fmt.Println("REQUEST:")
-        fmt.Printf("[%s]\t%s\n", req.Method, req.URL)
-        if len(req.Header) > 0 {
-            fmt.Println("Req Headers")
-            printJSON(req.Header)
-        }
-        if req.Body != nil {
-            fmt.Println("Req Body")
-            fmt.Println(req.Body)
-        }
+    if offset > 0 {
+        req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
     }
 
+    ctx, cancel := context.WithCancel(context.Background())
+    req = req.WithContext(ctx)
+
+    traceRequest(req)
+
     // Directly use the HTTP client, not the Whisk CLI client, so that the response body is left alone
     resp, err := s.client.client.Do(req)
     if err != nil {
         Debug(DbgError, "s.client.Do() error - HTTP req %s; error: '%s'\n", req.URL.String(), err)
+        cancel()
         return resp, err
     }
 
+    if timeout := getTimeout(s.client.Config.DownloadIdleTimeout, DefaultDownloadIdleTimeout); timeout > 0 {
+        resp.Body = newIdleTimeoutBody(resp.Body, timeout, cancel)
+    } else {
+        resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+    }
+
     return resp, nil
 }
+
+// cancelBody is a response body that cancels its request when closed
+type cancelBody struct {
+    io.ReadCloser
+    cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+    err := b.ReadCloser.Close()
+    b.cancel()
+    return err
+}
+
+// idleTimeoutBody is a response body that cancels its request once no data has been read from it for timeout
+type idleTimeoutBody struct {
+    body    io.ReadCloser
+    timeout time.Duration
+    cancel  context.CancelFunc
+    timer   *time.Timer
+    mutex   sync.Mutex
+    idle    bool
+}
+
+func newIdleTimeoutBody(body io.ReadCloser, timeout time.Duration, cancel context.CancelFunc) *idleTimeoutBody {
+    b := &idleTimeoutBody{body: body, timeout: timeout, cancel: cancel}
+    b.timer = time.AfterFunc(timeout, func() {
+        b.mutex.Lock()
+        b.idle = true
+        b.mutex.Unlock()
+        b.cancel()
+    })
+
+    return b
+}
+
+func (b *idleTimeoutBody) Read(p []byte) (int, error) {
+    n, err := b.body.Read(p)
+
+    b.mutex.Lock()
+    idle := b.idle
+    b.mutex.Unlock()
+
+    if idle {
+        Debug(DbgError, "No data received for %s; abandoning the download\n", b.timeout)
+        errStr := wski18n.T("No data was received for {{.timeout}}",
+            map[string]interface{}{"timeout": b.timeout})
+        return n, MakeWskError(errors.New(errStr), EXITCODE_ERR_NETWORK, DISPLAY_MSG, NO_DISPLAY_USAGE)
+    }
+
+    if n > 0 {
+        b.timer.Reset(b.timeout)
+    }
+
+    return n, err
+}
+
+func (b *idleTimeoutBody) Close() error {
+    b.timer.Stop()
+    err := b.body.Close()
+    b.cancel()
+    return err
+}
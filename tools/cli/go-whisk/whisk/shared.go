@@ -16,7 +16,25 @@
 
 package whisk
 
-import "encoding/json"
+import (
+    "encoding/json"
+    "net/http"
+    "strconv"
+
+    "../wski18n"
+)
+
+// Largest number of entities the server returns for a single list request
+const MaxListPageSize = 200
+
+// Response header in which the server may report the number of entities in a listed collection
+const TotalCountHeader = "X-Total-Count"
+
+// Response header in which the server reports the id of the activation that an invocation created
+const ActivationIDHeader = "X-Openwhisk-Activation-Id"
+
+// Largest number of entities a listing preallocates room for, whatever collection size the server reports
+const MaxListCapacity = 50 * MaxListPageSize
 
 type KeyValue struct {
     Key  string         `json:"key"`
@@ -25,6 +43,80 @@ type KeyValue struct {
 
 type KeyValueArr []KeyValue
 
+// FindKeyValue returns the index of the first key value with the given key, or -1 if there is none
+func (keyValueArr KeyValueArr) FindKeyValue(key string) int {
+    for i := 0; i < len(keyValueArr); i++ {
+        if keyValueArr[i].Key == key {
+            return i
+        }
+    }
+
+    return -1
+}
+
+// GetValue returns the value of the first key value with the given key, and whether the key was found
+func (keyValueArr KeyValueArr) GetValue(key string) (interface{}, bool) {
+    if i := keyValueArr.FindKeyValue(key); i >= 0 {
+        return keyValueArr[i].Value, true
+    }
+
+    return nil, false
+}
+
+// AddOrReplace returns a copy of the array in which the value of the first key value with the same key is replaced
+// by keyValue, or with keyValue appended if the key is not present
+func (keyValueArr KeyValueArr) AddOrReplace(keyValue KeyValue) KeyValueArr {
+    res := append(KeyValueArr{}, keyValueArr...)
+
+    if i := res.FindKeyValue(keyValue.Key); i >= 0 {
+        res[i].Value = keyValue.Value
+        return res
+    }
+
+    return append(res, keyValue)
+}
+
+// Delete returns a copy of the array without the first key value with the given key
+func (keyValueArr KeyValueArr) Delete(key string) KeyValueArr {
+    i := keyValueArr.FindKeyValue(key)
+    if i < 0 {
+        return append(KeyValueArr{}, keyValueArr...)
+    }
+
+    res := append(KeyValueArr{}, keyValueArr[:i]...)
+    return append(res, keyValueArr[i + 1:]...)
+}
+
+// getInsertBody returns entity as the body of an insert request, in which the parameters or annotations that are empty
+// but not nil are sent as an empty array rather than dropped by omitempty, as the server keeps the existing parameters
+// or annotations of an entity when an update sends none
+func getInsertBody(entity interface{}, parameters KeyValueArr, annotations KeyValueArr) interface{} {
+    emptyParameters := parameters != nil && len(parameters) == 0
+    emptyAnnotations := annotations != nil && len(annotations) == 0
+    if !emptyParameters && !emptyAnnotations {
+        return entity
+    }
+
+    // An entity that cannot be encoded is sent as it is, so that the request reports the error
+    var body map[string]json.RawMessage
+    data, err := json.Marshal(entity)
+    if err == nil {
+        err = json.Unmarshal(data, &body)
+    }
+    if err != nil {
+        return entity
+    }
+
+    if emptyParameters {
+        body["parameters"] = json.RawMessage("[]")
+    }
+    if emptyAnnotations {
+        body["annotations"] = json.RawMessage("[]")
+    }
+
+    return body
+}
+
 type Annotations []map[string]interface{}
 
 type Parameters *json.RawMessage
@@ -34,3 +126,70 @@ type Limits struct {
     Memory  *int `json:"memory,omitempty"`
     Logsize *int `json:"logs,omitempty"`
 }
+
+// getPublishState returns the publish state shown in entity lists: shared when publish is set, private otherwise
+func getPublishState(publish *bool) string {
+    if publish != nil && *publish {
+        return wski18n.T("shared")
+    }
+
+    return wski18n.T("private")
+}
+
+// Sortable is implemented by entities that can be ordered and listed by the CLI
+type Sortable interface {
+    // Compare reports whether the entity sorts before the given entity of the same type
+    Compare(sortable Sortable) bool
+    // ListString returns the entity formatted as a single list row
+    ListString() string
+}
+
+// Columnar is implemented by entities that can be listed as a row of separate columns. The CLI prefers it over
+// ListString, so that the column widths can follow the listed values.
+type Columnar interface {
+    // Columns returns the values of the entity's list row, in display order
+    Columns() []string
+}
+
+// ListAllPages calls list with increasing skip values, beginning at skip, until list fails or the collection is
+// exhausted. list returns the number of entities in the page it fetched. The collection is exhausted when the
+// X-Total-Count header of a page shows that no entities remain or, for servers that do not send the header, when a page
+// contains fewer than MaxListPageSize entities.
+func ListAllPages(skip int, list func(limit int, skip int) (int, *http.Response, error)) (*http.Response, error) {
+    for {
+        count, resp, err := list(MaxListPageSize, skip)
+        if err != nil || count < MaxListPageSize {
+            return resp, err
+        }
+
+        skip += count
+        if total := getTotalCount(resp); total >= 0 && skip >= total {
+            return resp, nil
+        }
+    }
+}
+
+// getListCapacity returns the capacity to preallocate for a listing of a collection whose reported size is total,
+// which is at most MaxListCapacity
+func getListCapacity(total int) int {
+    if total > MaxListCapacity {
+        return MaxListCapacity
+    }
+
+    return total
+}
+
+// getTotalCount returns the collection size reported in the X-Total-Count header of a list response, or -1 when the
+// header is missing or invalid
+func getTotalCount(resp *http.Response) int {
+    if resp == nil {
+        return -1
+    }
+
+    total, err := strconv.Atoi(resp.Header.Get(TotalCountHeader))
+    if err != nil || total < 0 {
+        return -1
+    }
+
+    return total
+}
@@ -0,0 +1,312 @@
+/*
+ * Copyright 2015-2016 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package whisk
+
+import (
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "reflect"
+    "sort"
+    "strings"
+    "testing"
+)
+
+func TestLimitsJSON(t *testing.T) {
+    zero, timeout, memory := 0, 60000, 256
+
+    tests := []struct {
+        limits  Limits
+        want    string
+    }{
+        {Limits{}, `{}`},
+        {Limits{Timeout: &timeout}, `{"timeout":60000}`},
+        {Limits{Memory: &memory, Logsize: &zero}, `{"memory":256,"logs":0}`},
+        {Limits{Timeout: &zero, Memory: &zero, Logsize: &zero}, `{"timeout":0,"memory":0,"logs":0}`},
+    }
+
+    for _, test := range tests {
+        data, err := json.Marshal(test.limits)
+        if err != nil {
+            t.Fatalf("json.Marshal(%#v) failed: %s", test.limits, err)
+        }
+        if string(data) != test.want {
+            t.Errorf("json.Marshal(%#v) = %s, want %s", test.limits, data, test.want)
+        }
+
+        // Limits that are not in the JSON stay unset, and zeros stay set
+        var decoded Limits
+        if err = json.Unmarshal(data, &decoded); err != nil {
+            t.Fatalf("json.Unmarshal(%s) failed: %s", data, err)
+        }
+        if !reflect.DeepEqual(decoded, test.limits) {
+            t.Errorf("limits %s decoded as %#v, want %#v", data, decoded, test.limits)
+        }
+    }
+}
+
+func TestActionWithoutLimitsJSON(t *testing.T) {
+    data, err := json.Marshal(Action{Name: "a"})
+    if err != nil {
+        t.Fatalf("json.Marshal() failed: %s", err)
+    }
+
+    var fields map[string]interface{}
+    json.Unmarshal(data, &fields)
+    if _, found := fields["limits"]; found {
+        t.Errorf("an action without limits is marshaled as %s, want no limits", data)
+    }
+}
+
+func TestKeyValueArrFind(t *testing.T) {
+    keyValues := KeyValueArr{{Key: "a", Value: 1}, {Key: "b", Value: nil}, {Key: "a", Value: 2}}
+
+    tests := []struct {
+        keyValues   KeyValueArr
+        key         string
+        index       int
+        value       interface{}
+    }{
+        // The first of duplicate keys wins
+        {keyValues, "a", 0, 1},
+        {keyValues, "b", 1, nil},
+        {keyValues, "c", -1, nil},
+        {keyValues, "", -1, nil},
+        {KeyValueArr{}, "a", -1, nil},
+        {nil, "a", -1, nil},
+    }
+
+    for _, test := range tests {
+        if index := test.keyValues.FindKeyValue(test.key); index != test.index {
+            t.Errorf("%v.FindKeyValue(%q) = %d, want %d", test.keyValues, test.key, index, test.index)
+        }
+        value, found := test.keyValues.GetValue(test.key)
+        if value != test.value || found != (test.index >= 0) {
+            t.Errorf("%v.GetValue(%q) = %v, %t, want %v, %t", test.keyValues, test.key, value, found, test.value,
+                test.index >= 0)
+        }
+    }
+}
+
+func TestKeyValueArrAddOrReplace(t *testing.T) {
+    tests := []struct {
+        keyValues   KeyValueArr
+        keyValue    KeyValue
+        want        KeyValueArr
+    }{
+        {KeyValueArr{{Key: "a", Value: 1}, {Key: "a", Value: 2}}, KeyValue{Key: "a", Value: 3},
+            KeyValueArr{{Key: "a", Value: 3}, {Key: "a", Value: 2}}},
+        {KeyValueArr{{Key: "a", Value: 1}}, KeyValue{Key: "b", Value: "x"},
+            KeyValueArr{{Key: "a", Value: 1}, {Key: "b", Value: "x"}}},
+        {KeyValueArr{}, KeyValue{Key: "a", Value: 1}, KeyValueArr{{Key: "a", Value: 1}}},
+        {nil, KeyValue{Key: "a", Value: nil}, KeyValueArr{{Key: "a", Value: nil}}},
+    }
+
+    for _, test := range tests {
+        orig := append(KeyValueArr(nil), test.keyValues...)
+        if res := test.keyValues.AddOrReplace(test.keyValue); !reflect.DeepEqual(res, test.want) {
+            t.Errorf("%v.AddOrReplace(%v) = %v, want %v", test.keyValues, test.keyValue, res, test.want)
+        }
+        if len(test.keyValues) > 0 && !reflect.DeepEqual(test.keyValues, orig) {
+            t.Errorf("AddOrReplace(%v) changed the array to %v", test.keyValue, test.keyValues)
+        }
+    }
+}
+
+func TestKeyValueArrDelete(t *testing.T) {
+    tests := []struct {
+        keyValues   KeyValueArr
+        key         string
+        want        KeyValueArr
+    }{
+        {KeyValueArr{{Key: "a", Value: 1}, {Key: "b", Value: 2}, {Key: "a", Value: 3}}, "a",
+            KeyValueArr{{Key: "b", Value: 2}, {Key: "a", Value: 3}}},
+        {KeyValueArr{{Key: "a", Value: 1}, {Key: "b", Value: 2}}, "b", KeyValueArr{{Key: "a", Value: 1}}},
+        {KeyValueArr{{Key: "a", Value: 1}}, "c", KeyValueArr{{Key: "a", Value: 1}}},
+        {KeyValueArr{{Key: "a", Value: 1}}, "a", KeyValueArr{}},
+        {KeyValueArr{}, "a", KeyValueArr{}},
+        {nil, "a", KeyValueArr{}},
+    }
+
+    for _, test := range tests {
+        orig := append(KeyValueArr(nil), test.keyValues...)
+        if res := test.keyValues.Delete(test.key); !reflect.DeepEqual(res, test.want) {
+            t.Errorf("%v.Delete(%q) = %#v, want %#v", test.keyValues, test.key, res, test.want)
+        }
+        if len(test.keyValues) > 0 && !reflect.DeepEqual(test.keyValues, orig) {
+            t.Errorf("Delete(%q) changed the array to %v", test.key, test.keyValues)
+        }
+    }
+}
+
+func TestListTotalCount(t *testing.T) {
+    lists := map[string]func(client *Client) (int, error){
+        "actions": func(client *Client) (int, error) {
+            _, total, _, err := client.Actions.List("", &ActionListOptions{Limit: 2})
+            return total, err
+        },
+        "rules": func(client *Client) (int, error) {
+            _, total, _, err := client.Rules.List(&RuleListOptions{Limit: 2})
+            return total, err
+        },
+        "triggers": func(client *Client) (int, error) {
+            _, total, _, err := client.Triggers.List(&TriggerListOptions{Limit: 2})
+            return total, err
+        },
+    }
+
+    tests := []struct {
+        header  string
+        want    int
+    }{
+        {"42", 42},
+        {"0", 0},
+        // A missing or invalid header is reported as -1
+        {"", -1},
+        {"many", -1},
+        {"-5", -1},
+    }
+
+    for collection, list := range lists {
+        for _, test := range tests {
+            client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+                if len(test.header) > 0 {
+                    w.Header().Set(TotalCountHeader, test.header)
+                }
+                writeTestJSON(w, http.StatusOK, []interface{}{})
+            })
+
+            total, err := list(client)
+            server.Close()
+
+            if err != nil {
+                t.Errorf("listing %s with %s %q failed: %s", collection, TotalCountHeader, test.header, err)
+            } else if total != test.want {
+                t.Errorf("listing %s with %s %q returned a total of %d, want %d", collection, TotalCountHeader,
+                    test.header, total, test.want)
+            }
+        }
+    }
+}
+
+func TestListAllPages(t *testing.T) {
+    tests := []struct {
+        size    int
+        header  string
+        want    string
+    }{
+        // Without X-Total-Count, the listing stops at the first short page
+        {2 * MaxListPageSize + 1, "", "0 200 400"},
+        {2 * MaxListPageSize, "", "0 200 400"},
+        {0, "", "0"},
+        // With it, a full last page needs no further request
+        {2 * MaxListPageSize, "400", "0 200"},
+        {2 * MaxListPageSize + 1, "401", "0 200 400"},
+    }
+
+    for _, test := range tests {
+        var skips []string
+        _, err := ListAllPages(0, func(limit int, skip int) (int, *http.Response, error) {
+            skips = append(skips, fmt.Sprint(skip))
+            resp := &http.Response{Header: http.Header{}}
+            if len(test.header) > 0 {
+                resp.Header.Set(TotalCountHeader, test.header)
+            }
+
+            count := test.size - skip
+            if count > limit {
+                count = limit
+            }
+            return count, resp, nil
+        })
+
+        if err != nil {
+            t.Errorf("listing %d entities with %s %q failed: %s", test.size, TotalCountHeader, test.header, err)
+        } else if strings.Join(skips, " ") != test.want {
+            t.Errorf("listing %d entities with %s %q requested the skips %s, want %s", test.size, TotalCountHeader,
+                test.header, strings.Join(skips, " "), test.want)
+        }
+    }
+}
+
+func TestGetListCapacity(t *testing.T) {
+    // The capacity comes from a server header, so it is capped
+    for total, want := range map[int]int{0: 0, 450: 450, MaxListCapacity: MaxListCapacity, 1 << 30: MaxListCapacity} {
+        if capacity := getListCapacity(total); capacity != want {
+            t.Errorf("getListCapacity(%d) = %d, want %d", total, capacity, want)
+        }
+    }
+}
+
+func TestCompareIgnoresCase(t *testing.T) {
+    // Entities sort by namespace and then name, whatever their case
+    names := [][2]string{{"ns", "beta"}, {"other", "a"}, {"ns", "Alpha"}, {"NS", "gamma"}, {"ns", "alpha2"}, {"ns", "Beta1"}}
+    want := []string{"ns/Alpha", "ns/alpha2", "ns/beta", "ns/Beta1", "NS/gamma", "other/a"}
+
+    entities := map[string]func(namespace string, name string) Sortable{
+        "actions": func(namespace string, name string) Sortable { return Action{Namespace: namespace, Name: name} },
+        "packages": func(namespace string, name string) Sortable { return Package{Namespace: namespace, Name: name} },
+        "triggers": func(namespace string, name string) Sortable { return Trigger{Namespace: namespace, Name: name} },
+        "rules": func(namespace string, name string) Sortable { return Rule{Namespace: namespace, Name: name} },
+    }
+
+    for kind, newEntity := range entities {
+        var sortables []Sortable
+        for _, name := range names {
+            sortables = append(sortables, newEntity(name[0], name[1]))
+        }
+        sort.SliceStable(sortables, func(i, j int) bool { return sortables[i].Compare(sortables[j]) })
+
+        var sorted []string
+        for _, sortable := range sortables {
+            sorted = append(sorted, strings.TrimPrefix(strings.Fields(sortable.ListString())[0], "/"))
+        }
+        if !reflect.DeepEqual(sorted, want) {
+            t.Errorf("%s sorted as %q, want %q", kind, sorted, want)
+        }
+    }
+}
+
+func TestListStrings(t *testing.T) {
+    shared := true
+    tests := []struct {
+        sortable    Sortable
+        want        string
+    }{
+        {Trigger{Namespace: "ns", Name: "t"}, fmt.Sprintf("%-70s private\n", "/ns/t")},
+        {Trigger{Namespace: "ns", Name: "t", Publish: &shared}, fmt.Sprintf("%-70s shared\n", "/ns/t")},
+        {Package{Namespace: "ns", Name: "p"}, fmt.Sprintf("%-70s private\n", "/ns/p")},
+        {Package{Namespace: "ns", Name: "p", Publish: &shared}, fmt.Sprintf("%-70s shared\n", "/ns/p")},
+        // The server returns an empty binding for packages that are not bindings
+        {Package{Namespace: "ns", Name: "p", Binding: &Binding{}}, fmt.Sprintf("%-70s private\n", "/ns/p")},
+        {Package{Namespace: "ns", Name: "b", Binding: &Binding{Namespace: "ns", Name: "p"}},
+            fmt.Sprintf("%-70s private binding\n", "/ns/b")},
+        {Rule{Namespace: "ns", Name: "r"}, fmt.Sprintf("%-70s private\n", "/ns/r")},
+    }
+
+    for _, test := range tests {
+        if row := test.sortable.ListString(); row != test.want {
+            t.Errorf("ListString() of %#v = %q, want %q", test.sortable, row, test.want)
+        }
+    }
+
+    // The columns of a package list end with the binding marker too
+    binding := Package{Namespace: "ns", Name: "b", Publish: &shared, Binding: &Binding{Namespace: "ns", Name: "p"}}
+    if columns := binding.Columns(); !reflect.DeepEqual(columns, []string{"/ns/b", "shared", "binding"}) {
+        t.Errorf("Columns() of a binding = %q, want its name, publish state and binding", columns)
+    }
+}
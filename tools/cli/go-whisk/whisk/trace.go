@@ -17,10 +17,20 @@
 package whisk
 
 import (
+    "bytes"
+    "encoding/json"
+    "errors"
     "fmt"
+    "io"
+    "net/http"
+    "os"
     "runtime"
     "strings"
-    "os"
+    "sync"
+
+    "github.com/fatih/color"
+    "github.com/hokaccha/go-prettyjson"
+    "../wski18n"
 )
 
 type DebugLevel string
@@ -31,10 +41,31 @@ const (
     DbgFatal   DebugLevel = "Ftl"
 )
 
+// LogLevel selects which trace output is written once debugging is enabled. Each level includes the ones before it.
+type LogLevel int
+const (
+    LogError    LogLevel = iota     // Debug messages at DbgError or DbgFatal
+    LogInfo                         // every Debug message
+    LogVerbose                      // every Debug message and the HTTP request and response traces
+)
+
 const MaxNameLen int = 25
 
-var isVerbose bool
-var isDebug bool
+// Most bytes of a response body written to the verbose trace
+const maxTracedBodySize = 64 * 1024
+
+// Logger writes the debug and verbose trace output. Writes are serialized so that output from concurrent goroutines
+// is not interleaved. When the output is a file, the Authorization header is redacted from HTTP traces.
+type Logger struct {
+    mutex       sync.Mutex
+    out         io.Writer
+    isFile      bool
+    debug       bool
+    verbose     bool
+    level       LogLevel
+}
+
+var logger = &Logger{level: LogVerbose}
 
 func init() {
     if len(os.Getenv("WSK_CLI_DEBUG")) > 0 {    // Useful for tracing init() code, before parms are parsed
@@ -43,23 +74,125 @@ func init() {
 }
 
 func SetDebug(b bool) {
-    isDebug = b
+    logger.mutex.Lock()
+    defer logger.mutex.Unlock()
+    logger.debug = b
 }
 
 func SetVerbose (b bool) {
-    isVerbose = b
+    logger.mutex.Lock()
+    defer logger.mutex.Unlock()
+    logger.verbose = b
+}
+
+// SetLogLevel limits the debug output to messages at or below level
+func SetLogLevel(level LogLevel) {
+    logger.mutex.Lock()
+    defer logger.mutex.Unlock()
+    logger.level = level
+}
+
+// ParseLogLevel converts "error", "info" or "verbose" to a LogLevel
+func ParseLogLevel(name string) (LogLevel, error) {
+    switch strings.ToLower(name) {
+    case "error":
+        return LogError, nil
+    case "info":
+        return LogInfo, nil
+    case "verbose":
+        return LogVerbose, nil
+    }
+
+    errStr := wski18n.T("Invalid debug level '{{.level}}'. Valid levels are 'error', 'info' and 'verbose'.",
+        map[string]interface{}{"level": name})
+    return LogVerbose, MakeWskError(errors.New(errStr), EXITCODE_ERR_USAGE, DISPLAY_MSG, NO_DISPLAY_USAGE)
+}
+
+// SetDebugFile appends the debug and verbose output to the file at path instead of writing it to stdout
+func SetDebugFile(path string) error {
+    file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+    if err != nil {
+        errStr := wski18n.T("Unable to open debug file '{{.name}}': {{.err}}",
+            map[string]interface{}{"name": path, "err": err})
+        return MakeWskError(errors.New(errStr), EXITCODE_ERR_GENERAL, DISPLAY_MSG, NO_DISPLAY_USAGE)
+    }
+
+    logger.mutex.Lock()
+    defer logger.mutex.Unlock()
+    logger.out = file
+    logger.isFile = true
+
+    return nil
 }
 
 func IsVerbose() bool {
-    return isVerbose || isDebug
+    logger.mutex.Lock()
+    defer logger.mutex.Unlock()
+    return logger.isVerbose()
+}
+
+func (l *Logger) isVerbose() bool {
+    return l.verbose || (l.debug && l.level >= LogVerbose)
+}
+
+func (l *Logger) isLogged(dl DebugLevel) bool {
+    if !l.debug {
+        return false
+    }
+    if dl == DbgError || dl == DbgFatal {
+        return true
+    }
+    return l.level >= LogInfo
+}
+
+func (l *Logger) writer() io.Writer {
+    if l.out == nil {
+        return color.Output
+    }
+    return l.out
+}
+
+// write outputs msg in a single write while holding the lock
+func (l *Logger) write(msg string) {
+    fmt.Fprint(l.writer(), msg)
+}
+
+// formatJSON formats v for the trace; colors are only used on stdout
+func (l *Logger) formatJSON(v interface{}) string {
+    var output []byte
+
+    if l.isFile {
+        output, _ = json.MarshalIndent(v, "", "  ")
+    } else {
+        output, _ = prettyjson.Marshal(v)
+    }
+
+    return string(output) + "\n"
 }
 
-/* Function for tracing debug level messages to stdout
+// traceHeaders copies headers for tracing; the Authorization value is redacted when the trace goes to a file
+func (l *Logger) traceHeaders(headers http.Header) http.Header {
+    traced := make(http.Header, len(headers))
+
+    for name, values := range headers {
+        if l.isFile && strings.EqualFold(name, "Authorization") {
+            values = []string{"[REDACTED]"}
+        }
+        traced[name] = values
+    }
+
+    return traced
+}
+
+/* Function for tracing debug level messages to stdout, or the debug file when one is set
    Output format:
    [file-or-function-name]:line-#:[DebugLevel] The formated message without any appended \n
  */
 func Debug(dl DebugLevel, msgFormat string, args ...interface{}) {
-    if isDebug {
+    logger.mutex.Lock()
+    defer logger.mutex.Unlock()
+
+    if logger.isLogged(dl) {
         pc, file, line, _ := runtime.Caller(1)
         fcn := runtime.FuncForPC(pc)
         msg := fmt.Sprintf(msgFormat, args...)
@@ -75,19 +208,102 @@ func Debug(dl DebugLevel, msgFormat string, args ...interface{}) {
         if len(fcnName) > MaxNameLen {
             fcnName = fcnName[len(fcnName)-MaxNameLen:]
         }
-        fmt.Printf("[%-25s]:%03d:[%3s] %v", fcnName, line, dl, msg)
+        logger.write(fmt.Sprintf("[%-25s]:%03d:[%3s] %v", fcnName, line, dl, msg))
     }
 }
 
-/* Function for tracing debug level messages to stdout
+/* Function for tracing verbose messages to stdout, or the debug file when one is set
    Output format:
-   [file-or-function-name]:line-#:[DebugLevel] The formated message without any appended \n
+   The formated message without any appended \n
  */
 func Verbose(msgFormat string, args ...interface{}) {
-    if IsVerbose() {
-        msg := fmt.Sprintf(msgFormat, args...)
-        fmt.Printf("%v", msg)
+    logger.mutex.Lock()
+    defer logger.mutex.Unlock()
+
+    if logger.isVerbose() {
+        logger.write(fmt.Sprintf(msgFormat, args...))
+    }
+}
+
+// traceRequest writes the verbose trace of an HTTP request
+func traceRequest(req *http.Request) {
+    logger.mutex.Lock()
+    defer logger.mutex.Unlock()
+
+    if !logger.isVerbose() {
+        return
+    }
+
+    var buf bytes.Buffer
+    fmt.Fprintln(&buf, "REQUEST:")
+    fmt.Fprintf(&buf, "[%s]\t%s\n", req.Method, req.URL)
+    if len(req.Header) > 0 {
+        fmt.Fprintln(&buf, "Req Headers")
+        buf.WriteString(logger.formatJSON(logger.traceHeaders(req.Header)))
+    }
+    if req.Body != nil {
+        fmt.Fprintln(&buf, "Req Body")
+        fmt.Fprintln(&buf, req.Body)
+    }
+    logger.write(buf.String())
+}
+
+// traceResponse writes the verbose trace of an HTTP response status and headers
+func traceResponse(resp *http.Response) {
+    logger.mutex.Lock()
+    defer logger.mutex.Unlock()
+
+    if !logger.isVerbose() {
+        return
+    }
+
+    var buf bytes.Buffer
+    fmt.Fprint(&buf, "RESPONSE:")
+    fmt.Fprintf(&buf, "Got response with code %d\n", resp.StatusCode)
+    if len(resp.Header) > 0 {
+        fmt.Fprintln(&buf, "Resp Headers")
+        buf.WriteString(logger.formatJSON(logger.traceHeaders(resp.Header)))
+    }
+    logger.write(buf.String())
+}
+
+// traceResponseBody writes the verbose trace of a response body of size bytes, of which data holds the beginning
+func traceResponseBody(data []byte, size int64) {
+    logger.mutex.Lock()
+    defer logger.mutex.Unlock()
+
+    if !logger.isVerbose() {
+        return
     }
+
+    if len(data) > maxTracedBodySize {
+        data = data[:maxTracedBodySize]
+    }
+
+    var buf bytes.Buffer
+    fmt.Fprintf(&buf, "Response body size is %d bytes\n", size)
+    fmt.Fprintf(&buf, "Response body received:\n%s\n", data)
+    if size > int64(len(data)) {
+        fmt.Fprintf(&buf, "... (truncated; %d more bytes not shown)\n", size - int64(len(data)))
+    }
+    logger.write(buf.String())
 }
 
+// traceBuffer keeps the first limit bytes written to it for the verbose trace, and counts the bytes written
+type traceBuffer struct {
+    bytes.Buffer
+    limit   int
+    size    int64
+}
 
+func (b *traceBuffer) Write(p []byte) (int, error) {
+    if room := b.limit - b.Len(); room > 0 {
+        if room > len(p) {
+            room = len(p)
+        }
+        b.Buffer.Write(p[:room])
+    }
+    b.size += int64(len(p))
+
+    return len(p), nil
+}
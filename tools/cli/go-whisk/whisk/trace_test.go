@@ -0,0 +1,228 @@
+/*
+ * Copyright 2015-2016 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+
+package whisk
+
+import (
+    "bytes"
+    "fmt"
+    "io/ioutil"
+    "net/http"
+    "os"
+    "path/filepath"
+    "strings"
+    "sync"
+    "testing"
+)
+
+// withTestLogger runs f with the trace written to a buffer by a logger like l, and returns the output
+func withTestLogger(l *Logger, f func()) string {
+    var buf bytes.Buffer
+    l.out = &buf
+
+    origLogger := logger
+    logger = l
+    defer func() { logger = origLogger }()
+
+    f()
+
+    return buf.String()
+}
+
+func TestTraceRequestRedactsAuthorization(t *testing.T) {
+    req, _ := http.NewRequest("GET", "https://host/api/v1/namespaces/ns/actions", nil)
+    req.Header.Set("Authorization", "Basic c2VjcmV0")
+    req.Header.Set("User-Agent", "test")
+
+    // The header is only redacted in a debug file, which may be shared, and not on the terminal
+    for _, isFile := range []bool{true, false} {
+        output := withTestLogger(&Logger{isFile: isFile, debug: true, level: LogVerbose}, func() { traceRequest(req) })
+
+        if !strings.Contains(output, "[GET]\thttps://host/api/v1/namespaces/ns/actions") || !strings.Contains(output, "test") {
+            t.Errorf("traceRequest() to a file %t wrote %q, want the request and its headers", isFile, output)
+        }
+        if redacted := !strings.Contains(output, "c2VjcmV0"); redacted != isFile {
+            t.Errorf("traceRequest() to a file %t wrote %q, want the authorization redacted: %t", isFile, output, isFile)
+        }
+        if isFile && !strings.Contains(output, "[REDACTED]") {
+            t.Errorf("traceRequest() to a file wrote %q, want the authorization replaced by [REDACTED]", output)
+        }
+    }
+
+    // The request itself keeps its header
+    if req.Header.Get("Authorization") != "Basic c2VjcmV0" {
+        t.Errorf("traceRequest() changed the Authorization header to %q", req.Header.Get("Authorization"))
+    }
+}
+
+func TestTraceResponseRedactsAuthorization(t *testing.T) {
+    resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{"Authorization": {"Basic c2VjcmV0"}}}
+    output := withTestLogger(&Logger{isFile: true, debug: true, level: LogVerbose}, func() { traceResponse(resp) })
+
+    if !strings.Contains(output, "Got response with code 200") || strings.Contains(output, "c2VjcmV0") {
+        t.Errorf("traceResponse() to a file wrote %q, want the response with the authorization redacted", output)
+    }
+}
+
+func TestDebugLevelFiltering(t *testing.T) {
+    tests := []struct {
+        debug   bool
+        level   LogLevel
+        want    string      // the messages written, by their debug level
+    }{
+        {false, LogVerbose, ""},
+        {true, LogError, "Err Ftl"},
+        {true, LogInfo, "Inf Wrn Err Ftl"},
+        {true, LogVerbose, "Inf Wrn Err Ftl"},
+    }
+
+    for _, test := range tests {
+        output := withTestLogger(&Logger{debug: test.debug, level: test.level}, func() {
+            for _, level := range []DebugLevel{DbgInfo, DbgWarn, DbgError, DbgFatal} {
+                Debug(level, "message %s\n", level)
+            }
+        })
+
+        var written []string
+        for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+            if fields := strings.Fields(line); len(fields) > 0 {
+                written = append(written, fields[len(fields) - 1])
+            }
+        }
+        if strings.Join(written, " ") != test.want {
+            t.Errorf("Debug() with debug %t and level %d wrote %q, want the messages %q", test.debug, test.level,
+                output, test.want)
+        }
+    }
+}
+
+func TestVerboseLevelFiltering(t *testing.T) {
+    req, _ := http.NewRequest("GET", "https://host/api", nil)
+
+    tests := []struct {
+        logger  *Logger
+        want    bool
+    }{
+        {&Logger{debug: true, level: LogVerbose}, true},
+        {&Logger{debug: true, level: LogInfo}, false},
+        {&Logger{debug: true, level: LogError}, false},
+        {&Logger{debug: false, level: LogVerbose}, false},
+        // --verbose traces HTTP whatever the debug level
+        {&Logger{verbose: true, level: LogError}, true},
+    }
+
+    for _, test := range tests {
+        output := withTestLogger(test.logger, func() {
+            Verbose("verbose message\n")
+            traceRequest(req)
+        })
+
+        if written := strings.Contains(output, "verbose message") && strings.Contains(output, "REQUEST:"); written != test.want {
+            t.Errorf("verbose trace with debug %t, verbose %t and level %d wrote %q, want output: %t",
+                test.logger.debug, test.logger.verbose, test.logger.level, output, test.want)
+        }
+    }
+}
+
+func TestParseLogLevel(t *testing.T) {
+    tests := []struct {
+        name    string
+        want    LogLevel
+        valid   bool
+    }{
+        {"error", LogError, true},
+        {"INFO", LogInfo, true},
+        {"Verbose", LogVerbose, true},
+        {"debug", LogVerbose, false},
+        {"", LogVerbose, false},
+    }
+
+    for _, test := range tests {
+        level, err := ParseLogLevel(test.name)
+        if test.valid && (err != nil || level != test.want) {
+            t.Errorf("ParseLogLevel(%q) = %d, %v, want %d", test.name, level, err, test.want)
+        } else if whiskErr, ok := err.(*WskError); !test.valid && (!ok || whiskErr.ExitCode != EXITCODE_ERR_USAGE) {
+            t.Errorf("ParseLogLevel(%q) error = %#v, want a usage error", test.name, err)
+        }
+    }
+}
+
+func TestDebugFile(t *testing.T) {
+    dir, err := ioutil.TempDir("", "trace")
+    if err != nil {
+        t.Fatalf("ioutil.TempDir() failed: %s", err)
+    }
+    defer os.RemoveAll(dir)
+
+    path := filepath.Join(dir, "debug.log")
+    if err = ioutil.WriteFile(path, []byte("earlier\n"), 0600); err != nil {
+        t.Fatalf("ioutil.WriteFile(%s) failed: %s", path, err)
+    }
+
+    origLogger := logger
+    logger = &Logger{debug: true, level: LogVerbose}
+    defer func() { logger = origLogger }()
+
+    if err = SetDebugFile(path); err != nil {
+        t.Fatalf("SetDebugFile(%s) failed: %s", path, err)
+    }
+    defer logger.out.(*os.File).Close()
+    Debug(DbgInfo, "to the file\n")
+
+    // The trace is appended to the file
+    if data, _ := ioutil.ReadFile(path); !strings.HasPrefix(string(data), "earlier\n") ||
+            !strings.HasSuffix(string(data), "to the file\n") {
+        t.Errorf("the debug file holds %q, want the earlier content and the message", data)
+    }
+
+    if err = SetDebugFile(filepath.Join(dir, "missing", "debug.log")); err == nil {
+        t.Error("SetDebugFile() in a missing directory succeeded")
+    }
+}
+
+func TestDebugConcurrentWrites(t *testing.T) {
+    const writers, messages = 10, 100
+    message := strings.Repeat("x", 200)
+
+    output := withTestLogger(&Logger{debug: true, level: LogVerbose}, func() {
+        var wg sync.WaitGroup
+        for i := 0; i < writers; i++ {
+            wg.Add(1)
+            go func(i int) {
+                defer wg.Done()
+                for j := 0; j < messages; j++ {
+                    Debug(DbgInfo, "%d %s\n", i, message)
+                }
+            }(i)
+        }
+        wg.Wait()
+    })
+
+    // Every message is written whole, on its own line
+    lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+    if len(lines) != writers * messages {
+        t.Fatalf("Debug() wrote %d lines, want %d", len(lines), writers * messages)
+    }
+    for _, line := range lines {
+        var writer int
+        if _, err := fmt.Sscanf(line[strings.Index(line, "[Inf] ") + 6:], "%d", &writer); err != nil ||
+                !strings.HasSuffix(line, fmt.Sprintf("%d %s", writer, message)) {
+            t.Errorf("Debug() wrote the interleaved line %q", line)
+            break
+        }
+    }
+}
@@ -21,6 +21,7 @@ import (
     "net/http"
     "errors"
     "net/url"
+    "strings"
     "../wski18n"
 )
 
@@ -40,13 +41,41 @@ type Trigger struct {
 
 }
 
+// FireResponse is the server response to firing a trigger
+type FireResponse struct {
+    ActivationID    string          `json:"activationId"`
+}
+
+// Compare orders triggers by namespace and then name, ignoring case.
+// The sortable argument must also be a Trigger.
+func (trigger Trigger) Compare(sortable Sortable) bool {
+    triggerToCompare := sortable.(Trigger)
+    triggerString := strings.ToLower(fmt.Sprintf("%s/%s", trigger.Namespace, trigger.Name))
+    compareString := strings.ToLower(fmt.Sprintf("%s/%s", triggerToCompare.Namespace, triggerToCompare.Name))
+
+    return triggerString < compareString
+}
+
+// ListString returns the trigger formatted as a row of the trigger list
+func (trigger Trigger) ListString() string {
+    return fmt.Sprintf("%-70s %s\n", fmt.Sprintf("/%s/%s", trigger.Namespace, trigger.Name),
+        getPublishState(trigger.Publish))
+}
+
+// Columns returns the trigger as the columns of a trigger list row: its fully qualified name and publish state
+func (trigger Trigger) Columns() []string {
+    return []string{fmt.Sprintf("/%s/%s", trigger.Namespace, trigger.Name), getPublishState(trigger.Publish)}
+}
+
 type TriggerListOptions struct {
     Limit           int             `url:"limit"`
     Skip            int             `url:"skip"`
     Docs            bool            `url:"docs,omitempty"`
 }
 
-func (s *TriggerService) List(options *TriggerListOptions) ([]Trigger, *http.Response, error) {
+// List lists triggers. It also returns the number of triggers in the collection, from the X-Total-Count response
+// header, or -1 when the server does not report it.
+func (s *TriggerService) List(options *TriggerListOptions) ([]Trigger, int, *http.Response, error) {
     route := "triggers"
     routeUrl, err := addRouteOptions(route, options)
     if err != nil {
@@ -54,7 +83,7 @@ func (s *TriggerService) List(options *TriggerListOptions) ([]Trigger, *http.Res
         errStr := wski18n.T("Unable to append options '{{.options}}' to URL route '{{.route}}': {{.err}}",
             map[string]interface{}{"options": fmt.Sprintf("%#v", options), "route": route, "err": err})
         werr := MakeWskError(errors.New(errStr), EXITCODE_ERR_GENERAL, DISPLAY_MSG, NO_DISPLAY_USAGE)
-        return nil, nil, werr
+        return nil, -1, nil, werr
     }
 
     req, err := s.client.NewRequestUrl("GET", routeUrl, nil, IncludeNamespaceInUrl, AppendOpenWhiskPathPrefix, EncodeBodyAsJson, AuthRequired)
@@ -63,17 +92,17 @@ func (s *TriggerService) List(options *TriggerListOptions) ([]Trigger, *http.Res
         errStr := wski18n.T("Unable to create HTTP request for GET '{{.route}}': {{.err}}",
             map[string]interface{}{"route": route, "err": err})
         werr := MakeWskErrorFromWskError(errors.New(errStr), err, EXITCODE_ERR_GENERAL, DISPLAY_MSG, NO_DISPLAY_USAGE)
-        return nil, nil, werr
+        return nil, -1, nil, werr
     }
 
     var triggers []Trigger
     resp, err := s.client.Do(req, &triggers, ExitWithSuccessOnTimeout)
     if err != nil {
         Debug(DbgError, "s.client.Do() error - HTTP req %s; error: '%s'\n", req.URL.String(), err)
-        return nil, resp, err
+        return nil, -1, resp, err
     }
 
-    return triggers, resp, nil
+    return triggers, getTotalCount(resp), resp, nil
 }
 
 func (s *TriggerService) Insert(trigger *Trigger, overwrite bool) (*Trigger, *http.Response, error) {
@@ -91,7 +120,8 @@ func (s *TriggerService) Insert(trigger *Trigger, overwrite bool) (*Trigger, *ht
         return nil, nil, werr
     }
 
-    req, err := s.client.NewRequestUrl("PUT", routeUrl, trigger, IncludeNamespaceInUrl, AppendOpenWhiskPathPrefix, EncodeBodyAsJson, AuthRequired)
+    body := getInsertBody(trigger, trigger.Parameters, trigger.Annotations)
+    req, err := s.client.NewRequestUrl("PUT", routeUrl, body, IncludeNamespaceInUrl, AppendOpenWhiskPathPrefix, EncodeBodyAsJson, AuthRequired)
     if err != nil {
         Debug(DbgError, "http.NewRequestUrl(PUT, %s, %+v, IncludeNamespaceInUrl, AppendOpenWhiskPathPrefix, EncodeBodyAsJson, AuthRequired); error: '%s'\n", routeUrl, trigger, err)
         errStr := wski18n.T("Unable to create HTTP request for PUT '{{.route}}': {{.err}}",
@@ -162,7 +192,9 @@ func (s *TriggerService) Delete(triggerName string) (*Trigger, *http.Response, e
     return t, resp, nil
 }
 
-func (s *TriggerService) Fire(triggerName string, payload interface{}) (*Trigger, *http.Response, error) {
+// Fire fires a trigger and returns the ID of the trigger activation. The server records the trigger activation even
+// when no rule is enabled for the trigger.
+func (s *TriggerService) Fire(triggerName string, payload interface{}) (*FireResponse, *http.Response, error) {
     // Encode resource name as a path (with no query params) before inserting it into the URI
     // This way any '?' chars in the name won't be treated as the beginning of the query params
     triggerName = (&url.URL{Path: triggerName}).String()
@@ -177,12 +209,20 @@ func (s *TriggerService) Fire(triggerName string, payload interface{}) (*Trigger
         return nil, nil, werr
     }
 
-    t := new(Trigger)
-    resp, err := s.client.Do(req, &t, ExitWithSuccessOnTimeout)
+    fireResp := new(FireResponse)
+    resp, err := s.client.Do(req, fireResp, ExitWithSuccessOnTimeout)
     if err != nil {
         Debug(DbgError, "s.client.Do() error - HTTP req %s; error: '%s'\n", req.URL.String(), err)
         return nil, resp, err
     }
 
-    return t, resp, nil
+    if len(fireResp.ActivationID) == 0 {
+        Debug(DbgError, "Trigger fire response for HTTP req %s has no activation ID\n", req.URL.String())
+        errStr := wski18n.T("The response to firing trigger '{{.name}}' does not contain an activation ID",
+            map[string]interface{}{"name": triggerName})
+        werr := MakeWskError(errors.New(errStr), EXITCODE_ERR_GENERAL, DISPLAY_MSG, NO_DISPLAY_USAGE)
+        return nil, resp, werr
+    }
+
+    return fireResp, resp, nil
 }
@@ -0,0 +1,65 @@
+/*
+ * Copyright 2015-2016 IBM Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package whisk
+
+import (
+    "net/http"
+    "testing"
+)
+
+func TestFire(t *testing.T) {
+    client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+        writeTestJSON(w, http.StatusAccepted, map[string]interface{}{"activationId": "12345"})
+    })
+    defer server.Close()
+
+    fireResp, _, err := client.Triggers.Fire("t", map[string]interface{}{"a": 1})
+    if err != nil {
+        t.Fatalf("Fire() failed: %s", err)
+    }
+    if fireResp.ActivationID != "12345" {
+        t.Errorf("Fire() activation ID = %q, want 12345", fireResp.ActivationID)
+    }
+}
+
+func TestFireMalformedResponses(t *testing.T) {
+    for _, body := range []string{
+        `{}`,
+        `{"activationId": ""}`,
+        `{"activationId": 12345}`,
+        `{"id": "12345"}`,
+        `null`,
+        `[]`,
+        `not json`,
+        ``,
+    } {
+        client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+            w.Header().Set("Content-Type", "application/json")
+            w.WriteHeader(http.StatusAccepted)
+            w.Write([]byte(body))
+        })
+        fireResp, _, err := client.Triggers.Fire("t", nil)
+        server.Close()
+
+        if _, ok := err.(*WskError); !ok {
+            t.Errorf("Fire() with the response %q error = %#v, want a WskError", body, err)
+        }
+        if fireResp != nil {
+            t.Errorf("Fire() with the response %q = %#v, want no response", body, fireResp)
+        }
+    }
+}
@@ -22,9 +22,7 @@ import (
     "net/url"
     "reflect"
 
-    "github.com/fatih/color"
     "github.com/google/go-querystring/query"
-    "github.com/hokaccha/go-prettyjson"
     "../wski18n"
 )
 
@@ -59,8 +57,3 @@ func addRouteOptions(route string, options interface{}) (*url.URL, error) {
     Debug(DbgInfo,"Returning route options '%s' from input struct %+v\n", u.String(), options)
     return u, nil
 }
-
-func printJSON(v interface{}) {
-    output, _ := prettyjson.Marshal(v)
-    fmt.Fprintln(color.Output, string(output))
-}
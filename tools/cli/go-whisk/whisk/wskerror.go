@@ -20,6 +20,7 @@ const EXITCODE_ERR_GENERAL      int = 1
 const EXITCODE_ERR_USAGE        int = 2
 const EXITCODE_ERR_NETWORK      int = 3
 const EXITCODE_ERR_HTTP_RESP    int = 4
+const EXITCODE_ERR_NOT_FOUND    int = 148     // HTTP 404 response
 const NOT_ALLOWED               int = 149
 const EXITCODE_TIMED_OUT        int = 202
 
@@ -56,6 +57,37 @@ func (whiskError WskError) Error() string {
     return whiskError.RootErr.Error()
 }
 
+/*
+Returns the error response sent by the server when the error was caused by an HTTP error status, or nil otherwise.
+The status code and the "error" field of the response body can be examined without parsing the error message.
+ */
+func (whiskError WskError) HttpErrorResponse() *ErrorResponse {
+    if errorResponse, ok := whiskError.RootErr.(*ErrorResponse); ok {
+        return errorResponse
+    }
+
+    return nil
+}
+
+/*
+Returns the error response sent by the server when err is a WskError caused by an HTTP error status, or nil otherwise.
+
+Parameters:
+    err     - error returned by a client request
+ */
+func GetHttpErrorResponse(err error) *ErrorResponse {
+    switch errorType := err.(type) {
+        case *WskError:
+            return errorType.HttpErrorResponse()
+        case WskError:
+            return errorType.HttpErrorResponse()
+        case *ErrorResponse:
+            return errorType
+    }
+
+    return nil
+}
+
 /*
 Instantiate a WskError structure
 Parameters: